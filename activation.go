@@ -0,0 +1,130 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNotActivated is returned by ActivationListeners and RunActivated when the
+// process was not started by systemd socket activation.
+var ErrNotActivated = errors.New("gin: process not socket activated (LISTEN_PID/LISTEN_FDS not set)")
+
+// listenFdsStart is the first file descriptor passed by socket activation (SD_LISTEN_FDS_START).
+var listenFdsStart = 3
+
+// ActivatedListener is a socket passed by systemd socket activation.
+type ActivatedListener struct {
+	// Name is the FileDescriptorName= of the socket unit, from LISTEN_FDNAMES.
+	// It defaults to "unknown", as in sd_listen_fds_with_names(3).
+	Name     string
+	Listener net.Listener
+}
+
+// ActivationListeners returns the sockets passed by systemd socket activation
+// (LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES), in order. The environment
+// variables are unset so that child processes don't inherit them.
+func ActivationListeners() ([]ActivatedListener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, ErrNotActivated
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, ErrNotActivated
+	}
+	var names []string
+	if v := os.Getenv("LISTEN_FDNAMES"); v != "" {
+		names = strings.Split(v, ":")
+	}
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	listeners := make([]ActivatedListener, 0, n)
+	for i := 0; i < n; i++ {
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		fd := listenFdsStart + i
+		f := os.NewFile(uintptr(fd), name)
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			for _, al := range listeners {
+				al.Listener.Close()
+			}
+			return nil, fmt.Errorf("gin: activated socket %q (fd %d): %w", name, fd, err)
+		}
+		listeners = append(listeners, ActivatedListener{Name: name, Listener: l})
+	}
+	return listeners, nil
+}
+
+// ActivationConfig defines the config for RunActivatedWithConfig.
+type ActivationConfig struct {
+	// TLS maps socket names to the TLS config of the socket, e.g. to serve
+	// HTTPS on the socket named "https". The other sockets serve plain HTTP.
+	// Optional. Default value serves plain HTTP on every socket.
+	TLS map[string]*tls.Config
+}
+
+// RunActivated serves plain HTTP on the sockets passed by systemd socket
+// activation. See RunActivatedWithConfig.
+// Note: this method will block the calling goroutine indefinitely unless an error happens.
+func (engine *Engine) RunActivated() error {
+	return engine.RunActivatedWithConfig(ActivationConfig{})
+}
+
+// RunActivatedWithConfig serves on the sockets passed by systemd socket
+// activation, see ActivationListeners. When one of them fails, the others are
+// closed and the error is returned.
+// Note: this method will block the calling goroutine indefinitely unless an error happens.
+func (engine *Engine) RunActivatedWithConfig(config ActivationConfig) (err error) {
+	defer func() { debugPrintError(err) }()
+
+	if engine.isUnsafeTrustedProxies() {
+		debugPrintWarning("You trusted all proxies, this is NOT safe. We recommend you to set a value.\n" +
+			"Please check https://github.com/gin-gonic/gin/blob/master/docs/doc.md#dont-trust-all-proxies for details.")
+	}
+
+	listeners, err := ActivationListeners()
+	if err != nil {
+		return err
+	}
+	errc := make(chan error, len(listeners))
+	var wg sync.WaitGroup
+	for _, al := range listeners {
+		listener := al.Listener
+		if engine.ProxyProtocol {
+			listener = NewProxyProtocolListener(listener)
+		}
+		scheme := "HTTP"
+		if tlsConfig := config.TLS[al.Name]; tlsConfig != nil {
+			listener = tls.NewListener(listener, tlsConfig)
+			scheme = "HTTPS"
+		}
+		debugPrintInfo("Listening and serving %s on activated socket %s@%s", scheme, al.Name, al.Listener.Addr())
+		wg.Add(1)
+		go func(listener net.Listener) {
+			defer wg.Done()
+			errc <- engine.serve(listener, serveHTTP)
+		}(listener)
+	}
+	err = <-errc
+	for _, al := range listeners {
+		al.Listener.Close()
+	}
+	wg.Wait()
+	return err
+}
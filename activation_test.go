@@ -0,0 +1,89 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package gin
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// activate passes a new TCP listener as the first activated socket.
+func activate(t *testing.T, name string) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f, err := l.(*net.TCPListener).File()
+	require.NoError(t, err)
+	l.Close()
+	t.Cleanup(func() { f.Close() })
+
+	start := listenFdsStart
+	listenFdsStart = int(f.Fd())
+	t.Cleanup(func() { listenFdsStart = start })
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "1")
+	t.Setenv("LISTEN_FDNAMES", name)
+	return l.Addr().String()
+}
+
+func TestActivationListeners(t *testing.T) {
+	_, err := ActivationListeners()
+	assert.Equal(t, ErrNotActivated, err)
+
+	addr := activate(t, "web")
+	listeners, err := ActivationListeners()
+	require.NoError(t, err)
+	require.Len(t, listeners, 1)
+	defer listeners[0].Listener.Close()
+	assert.Equal(t, "web", listeners[0].Name)
+	assert.Equal(t, addr, listeners[0].Listener.Addr().String())
+	assert.Empty(t, os.Getenv("LISTEN_FDS"))
+
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "1")
+	_, err = ActivationListeners()
+	assert.Equal(t, ErrNotActivated, err)
+}
+
+func TestRunActivatedWithConfig(t *testing.T) {
+	cert, err := tls.LoadX509KeyPair("./testdata/certificate/cert.pem", "./testdata/certificate/key.pem")
+	require.NoError(t, err)
+	addr := activate(t, "https")
+
+	router := New()
+	router.GET("/example", func(c *Context) { c.String(http.StatusOK, c.Request.Proto) })
+	errc := make(chan error, 1)
+	go func() {
+		errc <- router.RunActivatedWithConfig(ActivationConfig{
+			TLS: map[string]*tls.Config{"https": {Certificates: []tls.Certificate{cert}}},
+		})
+	}()
+	assert.Eventually(t, func() bool { return router.State().Accepting }, time.Second, time.Millisecond)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}} //nolint: gosec
+	resp, err := client.Get("https://" + addr + "/example")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "HTTP/1.1", string(body))
+
+	assert.NoError(t, router.Shutdown(context.Background()))
+	assert.Equal(t, http.ErrServerClosed, <-errc)
+}
+
+func TestRunActivatedNotActivated(t *testing.T) {
+	assert.Equal(t, ErrNotActivated, New().RunActivated())
+}
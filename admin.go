@@ -0,0 +1,225 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// AdminConfig is the configuration of the engine reported by the admin endpoint.
+type AdminConfig struct {
+	Mode                   string   `json:"mode"`
+	LogLevel               LogLevel `json:"log_level"`
+	TrustedProxies         []string `json:"trusted_proxies"`
+	TrustedPlatform        string   `json:"trusted_platform,omitempty"`
+	ForwardedByClientIP    bool     `json:"forwarded_by_client_ip"`
+	RemoteIPHeaders        []string `json:"remote_ip_headers"`
+	RedirectTrailingSlash  bool     `json:"redirect_trailing_slash"`
+	RedirectFixedPath      bool     `json:"redirect_fixed_path"`
+	HandleMethodNotAllowed bool     `json:"handle_method_not_allowed"`
+	UseRawPath             bool     `json:"use_raw_path"`
+	RemoveExtraSlash       bool     `json:"remove_extra_slash"`
+	MaxMultipartMemory     int64    `json:"max_multipart_memory"`
+	RequestTimeout         string   `json:"request_timeout,omitempty"`
+	Maintenance            bool     `json:"maintenance"`
+}
+
+// AdminBuildInfo is the build information reported by the admin endpoint.
+type AdminBuildInfo struct {
+	GinVersion string            `json:"gin_version"`
+	GoVersion  string            `json:"go_version"`
+	Path       string            `json:"path,omitempty"`
+	Version    string            `json:"version,omitempty"`
+	Settings   map[string]string `json:"settings,omitempty"`
+}
+
+// EnableAdmin mounts operational endpoints under prefix, guarded by middleware
+// (at least one, typically an authentication middleware such as BasicAuth):
+//
+//	GET    {prefix}/health       liveness, with the maintenance and server state; 503 once draining
+//	GET    {prefix}/ready        readiness: 200 once serving and warmed up, see Engine.Warmup; 503 otherwise
+//	GET    {prefix}/routes       the routes, see RoutesSnapshot; ?format=table|json|dot, see DebugRoutes
+//	GET    {prefix}/config       the configuration of the engine, see AdminConfig
+//	GET    {prefix}/build        the build information of the binary, see AdminBuildInfo
+//	GET    {prefix}/stats        the runtime statistics of the engine, see AdminStats
+//	GET    {prefix}/mode         the gin mode
+//	PUT    {prefix}/mode         switches the gin mode: {"mode": "debug"}
+//	GET    {prefix}/log-level    the level of gin's own messages, see SetLogLevel
+//	PUT    {prefix}/log-level    switches the log level: {"level": "warn"}
+//	GET    {prefix}/maintenance  the maintenance state
+//	PUT    {prefix}/maintenance  enables the maintenance mode: {"retry_after": 60, "message": "..."}
+//	DELETE {prefix}/maintenance  disables the maintenance mode
+//
+// The admin routes stay available during maintenance. The returned group can be
+// used to add more endpoints.
+func (engine *Engine) EnableAdmin(prefix string, middleware ...HandlerFunc) *RouterGroup {
+	assert1(len(middleware) > 0, "admin endpoints must be guarded by a middleware")
+	admin := engine.Group(prefix, middleware...)
+	admin.SetMeta(adminMetaKey, true)
+
+	admin.GET("/health", func(c *Context) {
+		state := engine.State()
+		if state.Phase == ServerDraining || state.Phase == ServerStopped {
+			c.JSON(http.StatusServiceUnavailable, H{"status": state.Phase, "state": state})
+			return
+		}
+		c.JSON(http.StatusOK, H{"status": "ok", "maintenance": engine.InMaintenance(), "state": state})
+	})
+	admin.GET("/ready", func(c *Context) {
+		state := engine.State()
+		if !state.Ready {
+			c.JSON(http.StatusServiceUnavailable, H{"status": state.Phase, "state": state})
+			return
+		}
+		c.JSON(http.StatusOK, H{"status": "ready", "state": state})
+	})
+	admin.GET("/routes", func(c *Context) {
+		format := RoutesFormat(c.Query("format"))
+		if format == "" {
+			c.JSON(http.StatusOK, engine.RoutesSnapshot())
+			return
+		}
+		switch format {
+		case RoutesJSON:
+			c.Header("Content-Type", MIMEJSON)
+		case RoutesTable, RoutesDOT:
+			c.Header("Content-Type", MIMEPlain)
+		default:
+			c.JSON(http.StatusBadRequest, H{"error": "unknown format " + string(format)})
+			return
+		}
+		c.Status(http.StatusOK)
+		_ = engine.DebugRoutes(c.Writer, format)
+	})
+	admin.GET("/config", func(c *Context) {
+		c.JSON(http.StatusOK, engine.adminConfig())
+	})
+	admin.GET("/build", func(c *Context) {
+		c.JSON(http.StatusOK, adminBuildInfo())
+	})
+	admin.GET("/stats", func(c *Context) {
+		c.JSON(http.StatusOK, engine.adminStats())
+	})
+	admin.GET("/mode", func(c *Context) {
+		c.JSON(http.StatusOK, H{"mode": Mode()})
+	})
+	admin.PUT("/mode", func(c *Context) {
+		var req struct {
+			Mode string `json:"mode" binding:"required,oneof=debug release test"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, H{"error": err.Error()})
+			return
+		}
+		SetMode(req.Mode)
+		c.JSON(http.StatusOK, H{"mode": Mode()})
+	})
+	admin.GET("/log-level", func(c *Context) {
+		c.JSON(http.StatusOK, H{"level": CurrentLogLevel()})
+	})
+	admin.PUT("/log-level", func(c *Context) {
+		var req struct {
+			Level *LogLevel `json:"level" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, H{"error": err.Error()})
+			return
+		}
+		SetLogLevel(*req.Level)
+		c.JSON(http.StatusOK, H{"level": CurrentLogLevel()})
+	})
+	admin.GET("/maintenance", func(c *Context) {
+		c.JSON(http.StatusOK, H{"maintenance": engine.InMaintenance()})
+	})
+	admin.PUT("/maintenance", func(c *Context) {
+		var req struct {
+			RetryAfter int    `json:"retry_after"`
+			Message    string `json:"message"`
+		}
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, H{"error": err.Error()})
+				return
+			}
+		}
+		err := engine.SetMaintenance(true, MaintenanceOptions{
+			Allow:      func(c *Context) bool { _, ok := c.RouteMeta(adminMetaKey); return ok },
+			RetryAfter: time.Duration(req.RetryAfter) * time.Second,
+			Message:    req.Message,
+		})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, H{"maintenance": true})
+	})
+	admin.DELETE("/maintenance", func(c *Context) {
+		_ = engine.SetMaintenance(false, MaintenanceOptions{})
+		c.JSON(http.StatusOK, H{"maintenance": false})
+	})
+	return admin
+}
+
+// AdminStats is the runtime statistics of the engine reported by the admin endpoint.
+type AdminStats struct {
+	Server      ServerState      `json:"server"`
+	ContextPool ContextPoolStats `json:"context_pool"`
+	BufferPool  BufferPoolStats  `json:"buffer_pool"`
+	// Templates is only set when the templates are reloaded in debug mode.
+	Templates *TemplateCacheStats `json:"templates,omitempty"`
+}
+
+func (engine *Engine) adminStats() AdminStats {
+	stats := AdminStats{
+		Server:      engine.State(),
+		ContextPool: engine.ContextPoolStats(),
+		BufferPool:  GetBufferPoolStats(),
+	}
+	if templates, ok := engine.TemplateCacheStats(); ok {
+		stats.Templates = &templates
+	}
+	return stats
+}
+
+// adminMetaKey marks the admin routes.
+const adminMetaKey = "_gin-gonic/gin/admin"
+
+func (engine *Engine) adminConfig() AdminConfig {
+	config := AdminConfig{
+		Mode:                   Mode(),
+		LogLevel:               CurrentLogLevel(),
+		TrustedProxies:         engine.currentTrustedProxies(),
+		TrustedPlatform:        engine.TrustedPlatform,
+		ForwardedByClientIP:    engine.ForwardedByClientIP,
+		RemoteIPHeaders:        engine.RemoteIPHeaders,
+		RedirectTrailingSlash:  engine.RedirectTrailingSlash,
+		RedirectFixedPath:      engine.RedirectFixedPath,
+		HandleMethodNotAllowed: engine.HandleMethodNotAllowed,
+		UseRawPath:             engine.UseRawPath,
+		RemoveExtraSlash:       engine.RemoveExtraSlash,
+		MaxMultipartMemory:     engine.MaxMultipartMemory,
+		Maintenance:            engine.InMaintenance(),
+	}
+	if engine.RequestTimeout > 0 {
+		config.RequestTimeout = engine.RequestTimeout.String()
+	}
+	return config
+}
+
+func adminBuildInfo() AdminBuildInfo {
+	info := AdminBuildInfo{GinVersion: Version, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.Path = bi.Main.Path
+		info.Version = bi.Main.Version
+		info.Settings = make(map[string]string)
+		for _, s := range bi.Settings {
+			info.Settings[s.Key] = s.Value
+		}
+	}
+	return info
+}
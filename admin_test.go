@@ -0,0 +1,105 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineEnableAdmin(t *testing.T) {
+	defer SetMode(TestMode)
+	router := New()
+	router.GET("/users/:id", func(c *Context) { c.String(http.StatusOK, "user") })
+	router.EnableAdmin("/admin", BasicAuth(Accounts{"admin": "secret"}))
+	auth := header{Key: "Authorization", Value: authorizationHeader("admin", "secret")}
+
+	w := PerformRequest(router, http.MethodGet, "/admin/health")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = PerformRequest(router, http.MethodGet, "/admin/health", auth)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"ok"`)
+	assert.Contains(t, w.Body.String(), `"phase":"starting"`)
+
+	w = PerformRequest(router, http.MethodGet, "/admin/ready", auth)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"ready":false`)
+
+	w = PerformRequest(router, http.MethodGet, "/admin/routes", auth)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"/users/:id"`)
+
+	w = PerformRequest(router, http.MethodGet, "/admin/routes?format=dot", auth)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Body.String(), "digraph"))
+
+	w = PerformRequest(router, http.MethodGet, "/admin/routes?format=xml", auth)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = PerformRequest(router, http.MethodGet, "/admin/config", auth)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var config AdminConfig
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &config))
+	assert.Equal(t, TestMode, config.Mode)
+	assert.True(t, config.RedirectTrailingSlash)
+
+	w = PerformRequest(router, http.MethodGet, "/admin/stats", auth)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var stats AdminStats
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, uint64(1), stats.ContextPool.InUse)
+	assert.Equal(t, ServerStarting, stats.Server.Phase)
+
+	w = PerformRequest(router, http.MethodGet, "/admin/build", auth)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), Version)
+
+	req := func(method, path, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Authorization", auth.Value)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	w = req(http.MethodPut, "/admin/mode", `{"mode":"verbose"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = req(http.MethodPut, "/admin/mode", `{"mode":"release"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, ReleaseMode, Mode())
+
+	w = req(http.MethodPut, "/admin/log-level", `{"level":"verbose"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = req(http.MethodPut, "/admin/log-level", `{"level":"warn"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, LogLevelWarn, CurrentLogLevel())
+	w = PerformRequest(router, http.MethodGet, "/admin/log-level", auth)
+	assert.JSONEq(t, `{"level":"warn"}`, w.Body.String())
+
+	w = req(http.MethodPut, "/admin/maintenance", `{"retry_after":60}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, router.InMaintenance())
+
+	w = PerformRequest(router, http.MethodGet, "/users/1")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	w = PerformRequest(router, http.MethodGet, "/admin/maintenance", auth)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"maintenance":true}`, w.Body.String())
+
+	w = req(http.MethodDelete, "/admin/maintenance", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, router.InMaintenance())
+	w = PerformRequest(router, http.MethodGet, "/users/1")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Panics(t, func() { router.EnableAdmin("/other") })
+}
@@ -0,0 +1,298 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin/internal/json"
+)
+
+const defaultAssetsCacheControl = "public, max-age=31536000, immutable"
+
+// AssetsConfig defines the config of NewAssets.
+type AssetsConfig struct {
+	// Root is the directory of the assets, e.g. the output directory of Vite.
+	Root string
+
+	// URLPrefix is the path at which Root is served with Static, e.g. "/static"
+	// for router.Static("/static", "./dist").
+	// Optional. Default value is "/".
+	URLPrefix string
+
+	// Manifest is the path of the manifest written by the bundler, mapping the
+	// source files to the fingerprinted ones: the .vite/manifest.json of Vite,
+	// or the manifest.json of the webpack-manifest-plugin.
+	// Optional. Default value is "": the files of Root are fingerprinted by
+	// NewAssets with a hash of their content.
+	Manifest string
+
+	// CacheControl is the Cache-Control header of the fingerprinted files.
+	// Optional. Default value is "public, max-age=31536000, immutable".
+	CacheControl string
+}
+
+// Assets resolves the names of the assets, e.g. "src/main.ts" or "css/app.css",
+// to the URLs of their fingerprinted files, e.g. "/static/assets/main.4889e940.js",
+// which can be cached forever by the browsers since their URL changes with
+// their content. See Engine.SetAssets.
+type Assets struct {
+	prefix       string
+	cacheControl string
+	// urls are the URLs of the assets by name.
+	urls map[string]string
+	// css are the URLs of the stylesheets imported by the Vite entries.
+	css map[string][]string
+	// files are the URL paths of the files of the fingerprinted URL paths.
+	files map[string]string
+
+	mu sync.Mutex
+	// stamps are the hashes of the files fingerprinted by NewAssets, by
+	// fingerprinted URL path.
+	stamps map[string]assetStamp
+}
+
+// assetStamp is the hash of a file fingerprinted by NewAssets, with the size and
+// modification time of the file when it was hashed.
+type assetStamp struct {
+	hash    string
+	size    int64
+	modTime time.Time
+}
+
+// NewAssets returns the Assets of config, reading the manifest or hashing the
+// files of config.Root.
+func NewAssets(config AssetsConfig) (*Assets, error) {
+	a := &Assets{
+		prefix:       config.URLPrefix,
+		cacheControl: config.CacheControl,
+		urls:         make(map[string]string),
+		css:          make(map[string][]string),
+		files:        make(map[string]string),
+		stamps:       make(map[string]assetStamp),
+	}
+	if a.prefix == "" {
+		a.prefix = "/"
+	}
+	if a.cacheControl == "" {
+		a.cacheControl = defaultAssetsCacheControl
+	}
+	var err error
+	switch {
+	case config.Manifest != "":
+		err = a.readManifest(config.Manifest)
+	case config.Root != "":
+		err = a.fingerprint(config.Root)
+	default:
+		err = errors.New("gin: assets without root nor manifest")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// viteChunk is an entry of a Vite manifest.
+type viteChunk struct {
+	File   string   `json:"file"`
+	CSS    []string `json:"css"`
+	Assets []string `json:"assets"`
+}
+
+// readManifest reads a Vite manifest, whose entries are chunks, or a webpack
+// one, whose entries are file names.
+func (a *Assets) readManifest(name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	var manifest map[string]stdjson.RawMessage
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("gin: invalid assets manifest %s: %w", name, err)
+	}
+	for key, raw := range manifest {
+		var file string
+		if err := json.Unmarshal(raw, &file); err == nil {
+			a.urls[key] = a.addFile(file)
+			continue
+		}
+		var chunk viteChunk
+		if err := json.Unmarshal(raw, &chunk); err != nil || chunk.File == "" {
+			return fmt.Errorf("gin: invalid assets manifest %s: entry %q", name, key)
+		}
+		a.urls[key] = a.addFile(chunk.File)
+		for _, css := range chunk.CSS {
+			a.css[key] = append(a.css[key], a.addFile(css))
+		}
+		for _, asset := range chunk.Assets {
+			a.addFile(asset)
+		}
+	}
+	return nil
+}
+
+// addFile returns the URL of a fingerprinted file of a manifest, and registers
+// it for the immutable cache headers unless it is on another host.
+func (a *Assets) addFile(file string) string {
+	if strings.Contains(file, "://") || strings.HasPrefix(file, "//") {
+		return file
+	}
+	urlPath := file
+	if !strings.HasPrefix(file, "/") {
+		urlPath = path.Join(a.prefix, file)
+	}
+	a.files[urlPath] = urlPath
+	return urlPath
+}
+
+// fingerprint adds the hash of their content to the names of the files of root,
+// e.g. "css/app.css" is served at "css/app.1f2e3d4c.css".
+func (a *Assets) fingerprint(root string) error {
+	return filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		stamp, err := hashAsset(f)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, name)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		ext := path.Ext(rel)
+		hashed := strings.TrimSuffix(rel, ext) + "." + stamp.hash + ext
+		urlPath := path.Join(a.prefix, hashed)
+		a.urls[rel] = urlPath
+		a.files[urlPath] = path.Join(a.prefix, rel)
+		a.stamps[urlPath] = stamp
+		return nil
+	})
+}
+
+// hashAsset returns the stamp of f, whose hash is the first 8 hexadecimal
+// digits of the SHA-256 of its content.
+func hashAsset(f interface {
+	io.Reader
+	Stat() (fs.FileInfo, error)
+}) (assetStamp, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return assetStamp{}, err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return assetStamp{}, err
+	}
+	return assetStamp{hash: hex.EncodeToString(h.Sum(nil)[:4]), size: info.Size(), modTime: info.ModTime()}, nil
+}
+
+// URL returns the URL of the fingerprinted file of the asset name, e.g.
+// "/static/css/app.1f2e3d4c.css" for "css/app.css", or the URL of name under
+// URLPrefix when it is not a known asset.
+func (a *Assets) URL(name string) string {
+	if u, ok := a.urls[name]; ok {
+		return u
+	}
+	return path.Join(a.prefix, name)
+}
+
+// CSS returns the URLs of the stylesheets imported by the Vite entry name.
+func (a *Assets) CSS(name string) []string {
+	return a.css[name]
+}
+
+// FuncMap returns the template functions of the assets: asset, which is URL,
+// and assetCSS, which is CSS.
+//
+//	<script type="module" src="{{asset "src/main.ts"}}"></script>
+//	{{range assetCSS "src/main.ts"}}<link rel="stylesheet" href="{{.}}">{{end}}
+func (a *Assets) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"asset":    a.URL,
+		"assetCSS": a.CSS,
+	}
+}
+
+// file returns the URL path of the file served at the fingerprinted urlPath.
+func (a *Assets) file(urlPath string) (string, bool) {
+	file, ok := a.files[urlPath]
+	return file, ok
+}
+
+// unchanged reports whether the file name of fsys served at the fingerprinted
+// urlPath exists and, when it was fingerprinted by NewAssets, still has the
+// hash of urlPath. The file is hashed again only when its size or modification
+// time changed.
+func (a *Assets) unchanged(urlPath string, fsys http.FileSystem, name string) bool {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	a.mu.Lock()
+	stamp, ok := a.stamps[urlPath]
+	a.mu.Unlock()
+	if !ok || info.Size() == stamp.size && info.ModTime().Equal(stamp.modTime) {
+		return true
+	}
+	current, err := hashAsset(f)
+	if err != nil || current.hash != stamp.hash {
+		return false
+	}
+	a.mu.Lock()
+	a.stamps[urlPath] = current
+	a.mu.Unlock()
+	return true
+}
+
+// SetAssets adds the template functions of assets to FuncMap, see
+// Assets.FuncMap, and makes Static and StaticFS serve their fingerprinted
+// files with immutable cache headers. A file fingerprinted by NewAssets whose
+// content changed since is not found at its former URL, until the assets are
+// created again:
+//
+//	assets, err := gin.NewAssets(gin.AssetsConfig{Root: "./dist", URLPrefix: "/static", Manifest: "./dist/.vite/manifest.json"})
+//	router.SetAssets(assets)
+//	router.Static("/static", "./dist")
+//	router.LoadHTMLGlob("templates/*")
+//
+// Like SetFuncMap, it must be called before loading the templates.
+func (engine *Engine) SetAssets(assets *Assets) {
+	funcMap := make(template.FuncMap, len(engine.FuncMap)+2)
+	for name, fn := range engine.FuncMap {
+		funcMap[name] = fn
+	}
+	for name, fn := range assets.FuncMap() {
+		funcMap[name] = fn
+	}
+	engine.FuncMap = funcMap
+	engine.assets = assets
+}
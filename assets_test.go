@@ -0,0 +1,149 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetsViteManifest(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "assets"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "assets", "main.4889e940.js"), []byte("main()"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "assets", "main.b82dbe22.css"), []byte("body{}"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "robots.txt"), []byte("robots"), 0o600))
+	manifest := filepath.Join(dir, "manifest.json")
+	require.NoError(t, os.WriteFile(manifest, []byte(`{
+		"src/main.ts": {"file": "assets/main.4889e940.js", "src": "src/main.ts", "isEntry": true, "css": ["assets/main.b82dbe22.css"]},
+		"src/logo.svg": {"file": "assets/logo.12345678.svg"}
+	}`), 0o600))
+
+	assets, err := NewAssets(AssetsConfig{URLPrefix: "/static", Manifest: manifest})
+	require.NoError(t, err)
+	assert.Equal(t, "/static/assets/main.4889e940.js", assets.URL("src/main.ts"))
+	assert.Equal(t, []string{"/static/assets/main.b82dbe22.css"}, assets.CSS("src/main.ts"))
+	assert.Equal(t, "/static/robots.txt", assets.URL("robots.txt"))
+
+	router := New()
+	router.SetFuncMap(template.FuncMap{"noop": func() string { return "" }})
+	router.SetAssets(assets)
+	router.Static("/static", dir)
+	router.SetHTMLTemplate(template.Must(template.New("").Funcs(router.FuncMap).Parse(
+		`{{define "page"}}<script src="{{asset "src/main.ts"}}"></script>` +
+			`{{range assetCSS "src/main.ts"}}<link href="{{.}}">{{end}}{{noop}}{{end}}`)))
+	router.GET("/", func(c *Context) { c.HTML(http.StatusOK, "page", nil) })
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, `<script src="/static/assets/main.4889e940.js"></script><link href="/static/assets/main.b82dbe22.css">`, w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/static/assets/main.4889e940.js")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "main()", w.Body.String())
+	assert.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+
+	w = PerformRequest(router, http.MethodGet, "/static/robots.txt")
+	assert.Equal(t, "robots", w.Body.String())
+	assert.Empty(t, w.Header().Get("Cache-Control"))
+}
+
+func TestAssetsWebpackManifest(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "manifest.json")
+	require.NoError(t, os.WriteFile(manifest, []byte(`{
+		"main.js": "main.abc123.js",
+		"vendor.js": "/public/vendor.def456.js",
+		"cdn.js": "https://cdn.example/cdn.789.js"
+	}`), 0o600))
+
+	assets, err := NewAssets(AssetsConfig{Manifest: manifest, CacheControl: "public, max-age=600"})
+	require.NoError(t, err)
+	assert.Equal(t, "/main.abc123.js", assets.URL("main.js"))
+	assert.Equal(t, "/public/vendor.def456.js", assets.URL("vendor.js"))
+	assert.Equal(t, "https://cdn.example/cdn.789.js", assets.URL("cdn.js"))
+	assert.Equal(t, map[string]string{
+		"/main.abc123.js":          "/main.abc123.js",
+		"/public/vendor.def456.js": "/public/vendor.def456.js",
+	}, assets.files)
+	assert.Equal(t, "public, max-age=600", assets.cacheControl)
+}
+
+func TestAssetsFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "css"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "css", "app.css"), []byte("body{}"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.min.js"), []byte("app()"), 0o600))
+
+	assets, err := NewAssets(AssetsConfig{Root: dir, URLPrefix: "/assets"})
+	require.NoError(t, err)
+	// the first 8 hexadecimal digits of the SHA-256 of the contents.
+	assert.Equal(t, "/assets/css/app.7c98040a.css", assets.URL("css/app.css"))
+	assert.Equal(t, "/assets/app.min.ac04e36f.js", assets.URL("app.min.js"))
+
+	router := New()
+	router.SetAssets(assets)
+	group := router.Group("/assets")
+	group.Static("/", dir)
+
+	w := PerformRequest(router, http.MethodGet, "/assets/css/app.7c98040a.css")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "body{}", w.Body.String())
+	assert.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+
+	w = PerformRequest(router, http.MethodGet, "/assets/css/app.css")
+	assert.Equal(t, "body{}", w.Body.String())
+	assert.Empty(t, w.Header().Get("Cache-Control"))
+
+	w = PerformRequest(router, http.MethodGet, "/assets/css/app.00000000.css")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// a touched file keeps its URL, a changed one is not served at its former URL.
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "css", "app.css"), later, later))
+	w = PerformRequest(router, http.MethodGet, "/assets/css/app.7c98040a.css")
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "css", "app.css"), []byte("body{color:red}"), 0o600))
+	w = PerformRequest(router, http.MethodGet, "/assets/css/app.7c98040a.css")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get("Cache-Control"))
+}
+
+func TestAssetsMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "manifest.json")
+	require.NoError(t, os.WriteFile(manifest, []byte(`{"main.js": "main.abc123.js"}`), 0o600))
+	assets, err := NewAssets(AssetsConfig{Manifest: manifest, URLPrefix: "/static"})
+	require.NoError(t, err)
+
+	router := New()
+	router.SetAssets(assets)
+	router.Static("/static", dir)
+	w := PerformRequest(router, http.MethodGet, "/static/main.abc123.js")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get("Cache-Control"))
+}
+
+func TestNewAssetsErrors(t *testing.T) {
+	_, err := NewAssets(AssetsConfig{})
+	assert.Error(t, err)
+	_, err = NewAssets(AssetsConfig{Root: filepath.Join(t.TempDir(), "missing")})
+	assert.Error(t, err)
+	_, err = NewAssets(AssetsConfig{Manifest: filepath.Join(t.TempDir(), "missing.json")})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	manifest := filepath.Join(t.TempDir(), "manifest.json")
+	require.NoError(t, os.WriteFile(manifest, []byte(`{"main.js": {"css": []}}`), 0o600))
+	_, err = NewAssets(AssetsConfig{Manifest: manifest})
+	assert.ErrorContains(t, err, `entry "main.js"`)
+	require.NoError(t, os.WriteFile(manifest, []byte(`[]`), 0o600))
+	_, err = NewAssets(AssetsConfig{Manifest: manifest})
+	assert.ErrorContains(t, err, "invalid assets manifest")
+}
@@ -7,15 +7,24 @@ package gin
 import (
 	"crypto/subtle"
 	"encoding/base64"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin/internal/bytesconv"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // AuthUserKey is the cookie name for user credential in basic auth.
 const AuthUserKey = "user"
 
+// AuthInfoKey is the key of the value returned by the validator of BasicAuthWithConfig.
+const AuthInfoKey = "_gin-gonic/gin/authinfokey"
+
+const defaultAuthRealm = "Authorization Required"
+
 // Accounts defines a key/value for user/pass list of authorized logins.
 type Accounts map[string]string
 
@@ -44,7 +53,7 @@ func (a authPairs) searchCredential(authValue string) (string, bool) {
 // (see http://tools.ietf.org/html/rfc2617#section-1.2)
 func BasicAuthForRealm(accounts Accounts, realm string) HandlerFunc {
 	if realm == "" {
-		realm = "Authorization Required"
+		realm = defaultAuthRealm
 	}
 	realm = "Basic realm=" + strconv.Quote(realm)
 	pairs := processAccounts(accounts)
@@ -89,3 +98,136 @@ func authorizationHeader(user, password string) string {
 	base := user + ":" + password
 	return "Basic " + base64.StdEncoding.EncodeToString(bytesconv.StringToBytes(base))
 }
+
+// BasicAuthConfig defines the config of the BasicAuthWithConfig middleware.
+type BasicAuthConfig struct {
+	// Realm is the name of the protection space, see BasicAuthForRealm.
+	// Optional. Default value is "Authorization Required".
+	Realm string
+
+	// Validator checks the credentials of a request and returns a value
+	// describing the user, e.g. a record loaded from a database, which is then
+	// available with Context.AuthInfo. See HashedAccounts.Validator to check
+	// hashed passwords.
+	// Required.
+	Validator func(user, password string) (any, bool)
+}
+
+// BasicAuthWithConfig returns a Basic HTTP Authorization middleware whose
+// credentials are checked by a validator, instead of a static list of accounts.
+func BasicAuthWithConfig(config BasicAuthConfig) HandlerFunc {
+	assert1(config.Validator != nil, "BasicAuthWithConfig requires a Validator")
+	realm := config.Realm
+	if realm == "" {
+		realm = defaultAuthRealm
+	}
+	realm = "Basic realm=" + strconv.Quote(realm)
+	return func(c *Context) {
+		user, password, ok := c.Request.BasicAuth()
+		var info any
+		if ok {
+			info, ok = config.Validator(user, password)
+		}
+		if !ok {
+			c.Header("WWW-Authenticate", realm)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Set(AuthUserKey, user)
+		c.Set(AuthInfoKey, info)
+	}
+}
+
+// AuthUser returns the name of the user authenticated by the BasicAuth or
+// DigestAuth middlewares, or an empty string.
+func (c *Context) AuthUser() string {
+	return c.GetString(AuthUserKey)
+}
+
+// AuthInfo returns the value returned by the validator of BasicAuthWithConfig
+// for the authenticated user, or nil.
+func (c *Context) AuthInfo() any {
+	info, _ := c.Get(AuthInfoKey)
+	return info
+}
+
+// HashedAccounts defines a key/value for user/password hash list of authorized
+// logins. The hashes are either bcrypt hashes ($2a$, $2b$ or $2y$) or argon2
+// hashes in the PHC string format ($argon2id$v=19$m=65536,t=3,p=4$salt$hash),
+// see HashPassword.
+type HashedAccounts map[string]string
+
+// Validator returns a validator for BasicAuthConfig checking the passwords
+// against the hashes of the accounts. The user name is returned as the info of
+// the user.
+func (a HashedAccounts) Validator() func(user, password string) (any, bool) {
+	assert1(len(a) > 0, "Empty list of authorized credentials")
+	var decoy string
+	for _, hash := range a {
+		decoy = hash
+		break
+	}
+	return func(user, password string) (any, bool) {
+		hash, found := a[user]
+		if !found {
+			// compare anyway, so that unknown users take as long as known ones.
+			CheckPassword(decoy, password)
+			return nil, false
+		}
+		return user, CheckPassword(hash, password)
+	}
+}
+
+// HashPassword returns the bcrypt hash of a password, with the default cost.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// CheckPassword reports whether password matches a bcrypt or argon2 hash, see
+// HashedAccounts. The comparison takes constant time.
+func CheckPassword(hash, password string) bool {
+	switch {
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	case strings.HasPrefix(hash, "$argon2id$"), strings.HasPrefix(hash, "$argon2i$"):
+		return checkArgon2(hash, password)
+	}
+	return false
+}
+
+// checkArgon2 checks a password against a hash like $argon2id$v=19$m=65536,t=3,p=4$salt$key.
+func checkArgon2(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false
+	}
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false
+	}
+	if time == 0 || threads == 0 {
+		// argon2 panics on these parameters.
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false
+	}
+	var derived []byte
+	if parts[1] == "argon2id" {
+		derived = argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	} else {
+		derived = argon2.Key([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	}
+	return subtle.ConstantTimeCompare(derived, key) == 1
+}
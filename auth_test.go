@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestBasicAuth(t *testing.T) {
@@ -137,3 +138,75 @@ func TestBasicAuth401WithCustomRealm(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 	assert.Equal(t, "Basic realm=\"My Custom \\\"Realm\\\"\"", w.Header().Get("WWW-Authenticate"))
 }
+
+func TestBasicAuthWithConfig(t *testing.T) {
+	type user struct{ ID int }
+	router := New()
+	router.Use(BasicAuthWithConfig(BasicAuthConfig{
+		Realm: "admin",
+		Validator: func(name, password string) (any, bool) {
+			if name == "admin" && password == "secret" {
+				return &user{ID: 42}, true
+			}
+			return nil, false
+		},
+	}))
+	router.GET("/login", func(c *Context) {
+		c.String(http.StatusOK, "%s %d", c.AuthUser(), c.AuthInfo().(*user).ID)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/login", nil)
+	req.Header.Set("Authorization", authorizationHeader("admin", "secret"))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "admin 42", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req.Header.Set("Authorization", authorizationHeader("admin", "wrong"))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Basic realm="admin"`, w.Header().Get("WWW-Authenticate"))
+
+	w = httptest.NewRecorder()
+	req.Header.Del("Authorization")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	assert.Panics(t, func() { BasicAuthWithConfig(BasicAuthConfig{}) })
+}
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := HashPassword("secret")
+	assert.NoError(t, err)
+	assert.True(t, CheckPassword(hash, "secret"))
+	assert.False(t, CheckPassword(hash, "Secret"))
+
+	// argon2id of "password" with salt "somesalt".
+	argon2id := "$argon2id$v=19$m=16,t=2,p=1$c29tZXNhbHQ$97FcQ2XrXRGBu161IDNkhQ"
+	assert.True(t, CheckPassword(argon2id, "password"))
+	assert.False(t, CheckPassword(argon2id, "passw0rd"))
+
+	assert.False(t, CheckPassword("secret", "secret"))
+	assert.False(t, CheckPassword("$argon2id$v=19$m=16,t=2,p=1$!$!", "password"))
+	assert.False(t, CheckPassword("$argon2id$v=16$m=16,t=2,p=1$c29tZXNhbHQ$97FcQ2XrXRGBu161IDNkhQ", "password"))
+	assert.False(t, CheckPassword("$argon2id$v=19$m=16,t=0,p=1$c29tZXNhbHQ$97FcQ2XrXRGBu161IDNkhQ", "password"))
+	assert.False(t, CheckPassword("$argon2i$v=19$m=16,t=2,p=0$c29tZXNhbHQ$97FcQ2XrXRGBu161IDNkhQ", "password"))
+}
+
+func TestHashedAccounts(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	validate := HashedAccounts{"admin": string(hash)}.Validator()
+
+	info, ok := validate("admin", "secret")
+	assert.True(t, ok)
+	assert.Equal(t, "admin", info)
+
+	_, ok = validate("admin", "wrong")
+	assert.False(t, ok)
+	_, ok = validate("root", "secret")
+	assert.False(t, ok)
+
+	assert.Panics(t, func() { HashedAccounts{}.Validator() })
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import "net/http"
+
+// PermissionMetaKey is the route metadata key holding the permission required by
+// a route, see Authorize.
+const PermissionMetaKey = "perm"
+
+// PermissionChecker decides whether the user of a request, usually identified by
+// a previous authentication middleware, holds a permission.
+type PermissionChecker interface {
+	HasPermission(c *Context, permission string) bool
+}
+
+// PermissionCheckerFunc is an adapter to use a function as a PermissionChecker.
+type PermissionCheckerFunc func(c *Context, permission string) bool
+
+// HasPermission calls f(c, permission).
+func (f PermissionCheckerFunc) HasPermission(c *Context, permission string) bool {
+	return f(c, permission)
+}
+
+// AuthorizeConfig defines the config of the AuthorizeWithConfig middleware.
+type AuthorizeConfig struct {
+	// Checker checks the permissions required by the routes.
+	// Required.
+	Checker PermissionChecker
+
+	// MetaKey is the route metadata key holding the required permission, either a
+	// string or a []string of permissions which are all required.
+	// Optional. Default value is PermissionMetaKey.
+	MetaKey string
+
+	// DenyUnannotated denies the access to the routes which do not declare a
+	// permission, so that a forgotten annotation does not expose a route.
+	// Optional. Default value is false.
+	DenyUnannotated bool
+
+	// DeniedHandler is called when the access is denied.
+	// Optional. Default value aborts with 403 Forbidden.
+	DeniedHandler HandlerFunc
+}
+
+// Authorize returns a middleware which checks the permission declared by the
+// matched route with Meta against checker:
+//
+//	router.Use(gin.BasicAuthWithConfig(authConfig), gin.Authorize(checker))
+//	router.GET("/users", listUsers).(gin.RouteMetaSetter).Meta(gin.PermissionMetaKey, "users.read")
+//	router.DELETE("/users/:id", deleteUser).(gin.RouteMetaSetter).Meta(gin.PermissionMetaKey, "users.delete")
+func Authorize(checker PermissionChecker) HandlerFunc {
+	return AuthorizeWithConfig(AuthorizeConfig{Checker: checker})
+}
+
+// AuthorizeWithConfig returns an authorization middleware with config, see Authorize.
+func AuthorizeWithConfig(config AuthorizeConfig) HandlerFunc {
+	assert1(config.Checker != nil, "AuthorizeWithConfig requires a Checker")
+	if config.MetaKey == "" {
+		config.MetaKey = PermissionMetaKey
+	}
+	if config.DeniedHandler == nil {
+		config.DeniedHandler = func(c *Context) {
+			c.AbortWithStatus(http.StatusForbidden)
+		}
+	}
+	return func(c *Context) {
+		if !authorized(c, config) {
+			config.DeniedHandler(c)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func authorized(c *Context, config AuthorizeConfig) bool {
+	value, ok := c.RouteMeta(config.MetaKey)
+	if !ok {
+		return !config.DenyUnannotated
+	}
+	var permissions []string
+	switch v := value.(type) {
+	case string:
+		permissions = []string{v}
+	case []string:
+		permissions = v
+	default:
+		return false
+	}
+	for _, p := range permissions {
+		if !config.Checker.HasPermission(c, p) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorize(t *testing.T) {
+	permissions := map[string][]string{
+		"alice": {"users.read", "users.delete"},
+		"bob":   {"users.read"},
+	}
+	checker := PermissionCheckerFunc(func(c *Context, permission string) bool {
+		for _, p := range permissions[c.GetHeader("X-User")] {
+			if p == permission {
+				return true
+			}
+		}
+		return false
+	})
+
+	router := New()
+	router.Use(Authorize(checker))
+	ok := func(c *Context) { c.Status(http.StatusOK) }
+	router.GET("/users", ok).(RouteMetaSetter).Meta(PermissionMetaKey, "users.read")
+	router.DELETE("/users/:id", ok).(RouteMetaSetter).Meta(PermissionMetaKey, []string{"users.read", "users.delete"})
+	router.GET("/public", ok)
+	router.GET("/invalid", ok).(RouteMetaSetter).Meta(PermissionMetaKey, 42)
+
+	for _, tc := range []struct {
+		user, method, path string
+		code               int
+	}{
+		{"bob", http.MethodGet, "/users", http.StatusOK},
+		{"bob", http.MethodDelete, "/users/1", http.StatusForbidden},
+		{"alice", http.MethodDelete, "/users/1", http.StatusOK},
+		{"", http.MethodGet, "/users", http.StatusForbidden},
+		{"", http.MethodGet, "/public", http.StatusOK},
+		{"alice", http.MethodGet, "/invalid", http.StatusForbidden},
+	} {
+		w := PerformRequest(router, tc.method, tc.path, header{Key: "X-User", Value: tc.user})
+		assert.Equal(t, tc.code, w.Code, "%s %s %s", tc.user, tc.method, tc.path)
+	}
+}
+
+func TestAuthorizeWithConfig(t *testing.T) {
+	router := New()
+	router.Use(AuthorizeWithConfig(AuthorizeConfig{
+		Checker:         PermissionCheckerFunc(func(c *Context, permission string) bool { return true }),
+		MetaKey:         "scope",
+		DenyUnannotated: true,
+		DeniedHandler: func(c *Context) {
+			c.String(http.StatusUnauthorized, "denied")
+		},
+	}))
+	router.GET("/scoped", func(c *Context) {}).(RouteMetaSetter).Meta("scope", "read")
+	router.GET("/unannotated", func(c *Context) {})
+
+	w := PerformRequest(router, http.MethodGet, "/scoped")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = PerformRequest(router, http.MethodGet, "/unannotated")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "denied", w.Body.String())
+
+	assert.Panics(t, func() { AuthorizeWithConfig(AuthorizeConfig{}) })
+}
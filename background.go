@@ -0,0 +1,152 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+const (
+	defaultBackgroundWorkers = 4
+	backgroundQueueSize      = 1024
+)
+
+var errBackgroundQueueFull = errors.New("background job queue is full")
+
+// Defer schedules job to run in the background once the handlers of the request
+// have returned, on the pool of Engine.BackgroundWorkers goroutines. Unlike a
+// goroutine started by the handler, the jobs are waited for by Engine.Shutdown,
+// which cancels their context when its own is done. The request has ended when
+// job runs: job must not use c, but a copy, see Copy.
+//
+//	c.Defer(func(ctx context.Context) {
+//		_ = mailer.SendWelcome(ctx, user)
+//	})
+//
+// The response is flushed before the jobs are queued. When the queue of pending
+// jobs is full, the job is dropped and an error is written to DefaultErrorWriter,
+// so that the request is never held back.
+func (c *Context) Defer(job func(ctx context.Context)) {
+	c.deferred = append(c.deferred, job)
+}
+
+// runDeferred flushes the response and hands the jobs of c to the background pool.
+func (c *Context) runDeferred() {
+	if len(c.deferred) == 0 {
+		return
+	}
+	if c.writermem.CanFlush() {
+		c.writermem.Flush()
+	}
+	for _, job := range c.deferred {
+		if err := c.engine.background.enqueue(c.engine.BackgroundWorkers, job); err != nil {
+			fmt.Fprintf(DefaultErrorWriter, "[GIN] %s %s: deferred job dropped: %v\n", c.Request.Method, c.Request.URL.Path, err)
+		}
+	}
+	for i := range c.deferred {
+		c.deferred[i] = nil
+	}
+	c.deferred = c.deferred[:0]
+}
+
+// backgroundJobs is the pool of the jobs of Context.Defer. It is started by
+// the first job and stopped by Engine.Shutdown, which drains it.
+type backgroundJobs struct {
+	mu   sync.Mutex
+	pool *jobPool
+}
+
+type jobPool struct {
+	queue  chan func(context.Context)
+	stop   chan struct{}
+	jobs   sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// enqueue queues job, or returns errBackgroundQueueFull without waiting if
+// there is no room.
+func (b *backgroundJobs) enqueue(workers int, job func(context.Context)) error {
+	b.mu.Lock()
+	p := b.pool
+	if p == nil {
+		if workers <= 0 {
+			workers = defaultBackgroundWorkers
+		}
+		p = &jobPool{
+			queue: make(chan func(context.Context), backgroundQueueSize),
+			stop:  make(chan struct{}),
+		}
+		p.ctx, p.cancel = context.WithCancel(context.Background())
+		for i := 0; i < workers; i++ {
+			go p.work()
+		}
+		b.pool = p
+	}
+	p.jobs.Add(1)
+	b.mu.Unlock()
+	select {
+	case p.queue <- job:
+		return nil
+	default:
+		p.jobs.Done()
+		return errBackgroundQueueFull
+	}
+}
+
+func (p *jobPool) work() {
+	for {
+		select {
+		case job := <-p.queue:
+			p.run(job)
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *jobPool) run(job func(context.Context)) {
+	defer p.jobs.Done()
+	defer recoverJob("background job")
+	job(p.ctx)
+}
+
+// recoverJob logs the panic of a job run out of a request.
+func recoverJob(kind string) {
+	if err := recover(); err != nil {
+		fmt.Fprintf(DefaultErrorWriter, "[GIN] %s panic: %v\n%s", kind, err, debug.Stack())
+	}
+}
+
+// drain waits for the pending jobs until ctx is done, then cancels their
+// context. The jobs deferred afterwards start a new pool.
+func (b *backgroundJobs) drain(ctx context.Context) error {
+	b.mu.Lock()
+	p := b.pool
+	b.pool = nil
+	b.mu.Unlock()
+	if p == nil {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.jobs.Wait()
+		close(p.stop)
+		p.cancel()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
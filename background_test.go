@@ -0,0 +1,114 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeferRunsAfterResponse(t *testing.T) {
+	router := New()
+	router.BackgroundWorkers = 2
+	var written atomic.Bool
+	done := make(chan string, 1)
+	router.GET("/", func(c *Context) {
+		cp := c.Copy()
+		c.Defer(func(ctx context.Context) {
+			assert.True(t, written.Load())
+			done <- cp.Request.URL.Path
+		})
+		c.String(http.StatusOK, "ok")
+	}, func(c *Context) {
+		written.Store(c.Writer.Written())
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, "ok", w.Body.String())
+	select {
+	case path := <-done:
+		assert.Equal(t, "/", path)
+	case <-time.After(time.Second):
+		t.Fatal("deferred job not run")
+	}
+}
+
+func TestShutdownDrainsDeferredJobs(t *testing.T) {
+	var out bytes.Buffer
+	defer func(w io.Writer) { DefaultErrorWriter = w }(DefaultErrorWriter)
+	DefaultErrorWriter = &out
+
+	router := New()
+	var ran atomic.Int32
+	router.GET("/", func(c *Context) {
+		c.Defer(func(ctx context.Context) { panic("boom") })
+		c.Defer(func(ctx context.Context) {
+			time.Sleep(20 * time.Millisecond)
+			ran.Add(1)
+		})
+	})
+	PerformRequest(router, http.MethodGet, "/")
+
+	assert.NoError(t, router.Shutdown(context.Background()))
+	assert.Equal(t, int32(1), ran.Load())
+	assert.Contains(t, out.String(), "[GIN] background job panic: boom")
+}
+
+func TestShutdownCancelsDeferredJobs(t *testing.T) {
+	router := New()
+	canceled := make(chan struct{})
+	router.GET("/", func(c *Context) {
+		c.Defer(func(ctx context.Context) {
+			<-ctx.Done()
+			close(canceled)
+		})
+	})
+	PerformRequest(router, http.MethodGet, "/")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, router.Shutdown(ctx), context.DeadlineExceeded)
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("job context not canceled")
+	}
+}
+
+func TestDeferDropsJobsWhenQueueIsFull(t *testing.T) {
+	var out bytes.Buffer
+	defer func(w io.Writer) { DefaultErrorWriter = w }(DefaultErrorWriter)
+	DefaultErrorWriter = &out
+
+	router := New()
+	router.BackgroundWorkers = 1
+	release := make(chan struct{})
+	var ran atomic.Int32
+	router.GET("/", func(c *Context) {
+		for i := 0; i < backgroundQueueSize+10; i++ {
+			c.Defer(func(ctx context.Context) {
+				<-release
+				ran.Add(1)
+			})
+		}
+		c.String(http.StatusOK, "ok")
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "ok", w.Body.String())
+	assert.Contains(t, out.String(), "[GIN] GET /: deferred job dropped: background job queue is full")
+
+	close(release)
+	assert.NoError(t, router.Shutdown(context.Background()))
+	assert.Less(t, ran.Load(), int32(backgroundQueueSize+10))
+}
@@ -8,7 +8,10 @@ import (
 	"html/template"
 	"net/http"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func BenchmarkOneRoute(B *testing.B) {
@@ -121,6 +124,55 @@ func Benchmark404Many(B *testing.B) {
 	runRequest(B, router, "GET", "/viewfake")
 }
 
+// paramRoutes are routes with a growing number of parameters, up to more than
+// the parameters stored in the Context.
+var paramRoutes = []struct {
+	name, route, path string
+}{
+	{"1Param", "/user/:id", "/user/gordon"},
+	{"5Params", "/5/:a/:b/:c/:d/:e", "/5/1/2/3/4/5"},
+	{"InlineParams", "/8" + strings.Repeat("/:p", inlineParams), "/8" + strings.Repeat("/v", inlineParams)},
+	{"12Params", "/12" + strings.Repeat("/:p", 12), "/12" + strings.Repeat("/v", 12)},
+	{"CatchAll", "/static/*filepath", "/static/css/app.css"},
+}
+
+func BenchmarkParams(B *testing.B) {
+	for _, r := range paramRoutes {
+		B.Run(r.name, func(B *testing.B) {
+			router := New()
+			router.GET(r.route, func(c *Context) {})
+			runRequest(B, router, http.MethodGet, r.path)
+		})
+	}
+}
+
+// TestRouteParamsZeroAllocs is the allocation gate of BenchmarkParams: once the
+// pool is warm, extracting route parameters must not allocate.
+func TestRouteParamsZeroAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector defeats the context pool")
+	}
+	router := New()
+	var got Params
+	for _, r := range paramRoutes {
+		router.GET(r.route, func(c *Context) { got = c.Params })
+	}
+	w := newMockWriter()
+	for _, r := range paramRoutes {
+		req, _ := http.NewRequest(http.MethodGet, r.path, nil)
+		allocs := testing.AllocsPerRun(100, func() { router.ServeHTTP(w, req) })
+		assert.Zero(t, allocs, r.name)
+		assert.NotEmpty(t, got, r.name)
+	}
+
+	// routes added while the pool is in use grow the pooled contexts once
+	router.GET("/20"+strings.Repeat("/:p", 20), func(c *Context) { got = c.Params })
+	req, _ := http.NewRequest(http.MethodGet, "/20"+strings.Repeat("/v", 20), nil)
+	router.ServeHTTP(w, req)
+	assert.Len(t, got, 20)
+	assert.Zero(t, testing.AllocsPerRun(100, func() { router.ServeHTTP(w, req) }))
+}
+
 type mockWriter struct {
 	headers http.Header
 }
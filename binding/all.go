@@ -0,0 +1,180 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/textproto"
+	"reflect"
+
+	"github.com/pelletier/go-toml/v2"
+	"google.golang.org/protobuf/proto"
+)
+
+// BindingAll adds BindAll method to Binding. BindAll fills a single struct from
+// every part of the request: the URI params, the headers, the query (and form)
+// values and the body.
+type BindingAll interface {
+	Name() string
+	BindAll(*http.Request, map[string][]string, any) error
+}
+
+// All binds URI params, headers, query/form values and the body into one struct,
+// driven by the uri, header, form and body-specific (json, xml, yaml...) tags.
+//
+// When a field is present in several parts of the request, the precedence is:
+//
+//	uri > header > form (query and urlencoded/multipart body) > body (json, xml...)
+//
+// Default values only apply to fields that were not filled by any part of the
+// request, and validation runs once, after all the parts have been bound.
+var All BindingAll = allBinding{}
+
+type allBinding struct{}
+
+var bodyDecoders = map[string]func(io.Reader, any) error{
+	MIMEJSON:     decodeJSONBody,
+	MIMEXML:      decodeXMLBody,
+	MIMEXML2:     decodeXMLBody,
+	MIMEYAML:     decodeYAMLBody,
+	MIMETOML:     decodeTOMLBody,
+	MIMEPROTOBUF: decodeProtoBufBody,
+}
+
+func (allBinding) Name() string {
+	return "all"
+}
+
+func (allBinding) BindAll(req *http.Request, params map[string][]string, obj any) error {
+	form := req.URL.Query()
+	switch contentType := filterFlags(req.Header.Get("Content-Type")); contentType {
+	case MIMEPOSTForm, MIMEMultipartPOSTForm:
+		if err := req.ParseMultipartForm(defaultMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return err
+		}
+		form = req.Form
+	default:
+		if decode, ok := bodyDecoders[contentType]; ok && req.Body != nil && req.Body != http.NoBody {
+			if err := decode(req.Body, obj); err != nil && !errors.Is(err, io.EOF) {
+				return err
+			}
+		}
+	}
+
+	src := allSource{uri: params, header: req.Header, form: form}
+	if err := mappingByPtr(obj, src, "form"); err != nil {
+		return err
+	}
+	return validateCtx(req.Context(), obj)
+}
+
+type allSource struct {
+	uri    map[string][]string
+	header map[string][]string
+	form   map[string][]string
+}
+
+var _ setter = allSource{}
+
+// TrySet tries to set a value from the URI params, the headers and the form, in
+// this order, reading the key of each source from the field's own tag.
+func (s allSource) TrySet(value reflect.Value, field reflect.StructField, _ string, _ setOptions) (bool, error) {
+	sources := [...]struct {
+		tag    string
+		values map[string][]string
+	}{
+		{"uri", s.uri},
+		{"header", s.header},
+		{"form", s.form},
+	}
+
+	var opt setOptions
+	var requiredSource, requiredKey string
+	tagged := false
+	for _, src := range sources {
+		tagValue, ok := field.Tag.Lookup(src.tag)
+		if !ok {
+			if src.tag != "form" || tagged {
+				continue
+			}
+			tagValue = field.Name
+		}
+		tagged = true
+		key, opts := head(tagValue, ",")
+		if key == "" {
+			key = field.Name
+		}
+		if key == "-" {
+			continue
+		}
+		if src.tag == "header" {
+			key = textproto.CanonicalMIMEHeaderKey(key)
+		}
+
+		for len(opts) > 0 {
+			var o string
+			o, opts = head(opts, ",")
+			if k, v := head(o, "="); k == "default" && !opt.isDefaultExists {
+				opt.isDefaultExists = true
+				opt.defaultValue = v
+			} else if k == "required" && requiredSource == "" {
+				requiredSource, requiredKey = src.tag, key
+			}
+		}
+
+		isSet, err := setByForm(value, field, src.values, key, setOptions{})
+		if err != nil || isSet {
+			return isSet, err
+		}
+	}
+
+	if !isLeafType(value.Type()) {
+		return false, nil
+	}
+	if !value.IsZero() {
+		// already filled by the body
+		return true, nil
+	}
+	if !opt.isDefaultExists {
+		if defaultValue, ok := field.Tag.Lookup("default"); ok {
+			opt.isDefaultExists = true
+			opt.defaultValue = defaultValue
+		}
+	}
+	if opt.isDefaultExists {
+		return setByForm(value, field, nil, "", opt)
+	}
+	if requiredSource != "" {
+		return false, &BindError{Field: field.Name, Key: requiredKey, Source: requiredSource, Err: ErrFieldRequired}
+	}
+	return false, nil
+}
+
+func decodeTOMLBody(r io.Reader, obj any) error {
+	return toml.NewDecoder(r).Decode(obj)
+}
+
+func decodeProtoBufBody(r io.Reader, obj any) error {
+	msg, ok := obj.(proto.Message)
+	if !ok {
+		return errors.New("obj is not ProtoMessage")
+	}
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(buf, msg)
+}
+
+func filterFlags(content string) string {
+	for i, char := range content {
+		if char == ' ' || char == ';' {
+			return content[:i]
+		}
+	}
+	return content
+}
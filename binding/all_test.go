@@ -0,0 +1,93 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type allRequest struct {
+	ID      int    `uri:"id" json:"id"`
+	Token   string `header:"X-Token" json:"token"`
+	Page    int    `form:"page" json:"page" default:"1"`
+	Size    int    `form:"size,default=20" json:"size"`
+	Name    string `json:"name" binding:"required"`
+	Comment string `form:"comment" json:"comment"`
+}
+
+func TestBindingAll(t *testing.T) {
+	body := `{"id": 1, "token": "body", "name": "gin", "comment": "from body", "size": 50}`
+	req, _ := http.NewRequest(http.MethodPost, "/users/7?page=3", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", MIMEJSON+"; charset=utf-8")
+	req.Header.Set("X-Token", "header")
+
+	var obj allRequest
+	err := All.BindAll(req, map[string][]string{"id": {"7"}}, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "all", All.Name())
+
+	assert.Equal(t, 7, obj.ID)
+	assert.Equal(t, "header", obj.Token)
+	assert.Equal(t, 3, obj.Page)
+	assert.Equal(t, 50, obj.Size)
+	assert.Equal(t, "gin", obj.Name)
+	assert.Equal(t, "from body", obj.Comment)
+}
+
+func TestBindingAllDefaultsAndValidation(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/users", nil)
+
+	var obj allRequest
+	err := All.BindAll(req, nil, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, 1, obj.Page)
+	assert.Equal(t, 20, obj.Size)
+
+	req, _ = http.NewRequest(http.MethodPost, "/users", bytes.NewBufferString(`{"name": "gin"}`))
+	req.Header.Set("Content-Type", MIMEJSON)
+	obj = allRequest{}
+	assert.NoError(t, All.BindAll(req, nil, &obj))
+}
+
+func TestBindingAllForm(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "/?page=2", bytes.NewBufferString("comment=hello&Name=gin"))
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+
+	var obj allRequest
+	assert.NoError(t, All.BindAll(req, nil, &obj))
+	assert.Equal(t, 2, obj.Page)
+	assert.Equal(t, "hello", obj.Comment)
+	assert.Equal(t, "gin", obj.Name)
+}
+
+func TestBindingAllRequired(t *testing.T) {
+	var obj struct {
+		Token string `header:"X-Token,required" form:"token"`
+	}
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	err := All.BindAll(req, nil, &obj)
+
+	var bindErr *BindError
+	if assert.ErrorAs(t, err, &bindErr) {
+		assert.Equal(t, "header", bindErr.Source)
+		assert.Equal(t, "X-Token", bindErr.Key)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/?token=abc", nil)
+	assert.NoError(t, All.BindAll(req, nil, &obj))
+	assert.Equal(t, "abc", obj.Token)
+}
+
+func TestBindingAllInvalidBody(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"id": "x"`))
+	req.Header.Set("Content-Type", MIMEJSON)
+
+	var obj allRequest
+	assert.Error(t, All.BindAll(req, nil, &obj))
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin/internal/json"
+)
+
+// ErrFieldRequired is wrapped by BindError when a field tagged with the
+// "required" option, e.g. `form:"page,required"`, is missing from the request.
+var ErrFieldRequired = errors.New("field is required")
+
+// BindError reports which struct field is missing from the request and where
+// its value was expected to come from. It is returned by the form, query, uri,
+// header and multipart bindings for fields tagged with the "required" option.
+type BindError struct {
+	// Field is the name of the struct field.
+	Field string
+	// Key is the name of the value in the request.
+	Key string
+	// Source is the part of the request the value was read from:
+	// "query", "form", "header" or "uri".
+	Source string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *BindError) Error() string {
+	if errors.Is(e.Err, ErrFieldRequired) {
+		return fmt.Sprintf("binding: %s %q is required", e.Source, e.Key)
+	}
+	return fmt.Sprintf("binding: invalid %s %q: %v", e.Source, e.Key, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON renders the error as a field-level JSON object, e.g.
+// {"field":"Page","key":"page","source":"query","message":"field is required"}.
+func (e *BindError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Field   string `json:"field"`
+		Key     string `json:"key"`
+		Source  string `json:"source"`
+		Message string `json:"message"`
+	}{e.Field, e.Key, e.Source, e.Err.Error()})
+}
@@ -6,7 +6,10 @@
 
 package binding
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+)
 
 // Content-Type MIME of the most common data formats.
 const (
@@ -108,8 +111,5 @@ func Default(method, contentType string) Binding {
 }
 
 func validate(obj any) error {
-	if Validator == nil {
-		return nil
-	}
-	return Validator.ValidateStruct(obj)
+	return validateCtx(context.Background(), obj)
 }
@@ -414,6 +414,20 @@ func TestBindingQueryFail2(t *testing.T) {
 		"map_foo=unused", "")
 }
 
+func TestBindingQueryRequired(t *testing.T) {
+	var obj struct {
+		Page int `form:"page,required"`
+	}
+	req := requestWithBody(http.MethodGet, "/?size=10", "")
+	err := Query.Bind(req, &obj)
+
+	var bindErr *BindError
+	if assert.ErrorAs(t, err, &bindErr) {
+		assert.Equal(t, "query", bindErr.Source)
+		assert.Equal(t, "page", bindErr.Key)
+	}
+}
+
 func TestBindingQueryBoolFail(t *testing.T) {
 	testQueryBindingBoolFail(t, "GET",
 		"/?bool_foo=fasl", "/?bar2=foo",
@@ -5,6 +5,7 @@
 package binding
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -43,10 +44,20 @@ func (err SliceValidationError) Error() string {
 	}
 }
 
-var _ StructValidator = (*defaultValidator)(nil)
+var (
+	_ StructValidator  = (*defaultValidator)(nil)
+	_ ContextValidator = (*defaultValidator)(nil)
+)
 
 // ValidateStruct receives any kind of type, but only performed struct or pointer to struct type.
 func (v *defaultValidator) ValidateStruct(obj any) error {
+	return v.ValidateCtx(context.Background(), obj)
+}
+
+// ValidateCtx is like ValidateStruct, but it passes ctx to the validation functions
+// and only validates the fields of the validation groups carried by ctx, if any.
+// See WithValidationGroups.
+func (v *defaultValidator) ValidateCtx(ctx context.Context, obj any) error {
 	if obj == nil {
 		return nil
 	}
@@ -54,14 +65,14 @@ func (v *defaultValidator) ValidateStruct(obj any) error {
 	value := reflect.ValueOf(obj)
 	switch value.Kind() {
 	case reflect.Ptr:
-		return v.ValidateStruct(value.Elem().Interface())
+		return v.ValidateCtx(ctx, value.Elem().Interface())
 	case reflect.Struct:
-		return v.validateStruct(obj)
+		return v.validateStruct(ctx, obj)
 	case reflect.Slice, reflect.Array:
 		count := value.Len()
 		validateRet := make(SliceValidationError, 0)
 		for i := 0; i < count; i++ {
-			if err := v.ValidateStruct(value.Index(i).Interface()); err != nil {
+			if err := v.ValidateCtx(ctx, value.Index(i).Interface()); err != nil {
 				validateRet = append(validateRet, err)
 			}
 		}
@@ -75,9 +86,53 @@ func (v *defaultValidator) ValidateStruct(obj any) error {
 }
 
 // validateStruct receives struct type
-func (v *defaultValidator) validateStruct(obj any) error {
+func (v *defaultValidator) validateStruct(ctx context.Context, obj any) error {
 	v.lazyinit()
-	return v.validate.Struct(obj)
+	if groups := ValidationGroups(ctx); len(groups) > 0 {
+		return v.validate.StructFilteredCtx(ctx, obj, groupFilter(reflect.TypeOf(obj), groups))
+	}
+	return v.validate.StructCtx(ctx, obj)
+}
+
+// groupFilter returns a filter skipping the fields of typ whose groups tag does not
+// contain any of groups. The filter receives struct namespaces like User.Items[0].Name.
+func groupFilter(typ reflect.Type, groups []string) validator.FilterFunc {
+	return func(ns []byte) bool {
+		parts := strings.Split(string(ns), ".")
+		if typ.Name() != "" {
+			parts = parts[1:]
+		}
+		t := typ
+		for _, part := range parts {
+			name, _, _ := strings.Cut(part, "[")
+			for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+				t = t.Elem()
+			}
+			if t.Kind() != reflect.Struct {
+				return false
+			}
+			sf, ok := t.FieldByName(name)
+			if !ok {
+				return false
+			}
+			if tag, ok := sf.Tag.Lookup("groups"); ok && !inGroups(tag, groups) {
+				return true
+			}
+			t = sf.Type
+		}
+		return false
+	}
+}
+
+func inGroups(tag string, groups []string) bool {
+	for _, g := range strings.Split(tag, ",") {
+		for _, group := range groups {
+			if strings.TrimSpace(g) == group {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // Engine returns the underlying validator engine which powers the default
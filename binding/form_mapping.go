@@ -40,6 +40,8 @@ func MapFormWithTag(ptr any, form map[string][]string, tag string) error {
 
 var emptyField = reflect.StructField{}
 
+var timeType = reflect.TypeOf(time.Time{})
+
 func mapFormByTag(ptr any, form map[string][]string, tag string) error {
 	// Check if ptr is a map
 	ptrVal := reflect.ValueOf(ptr)
@@ -135,6 +137,7 @@ func mapping(value reflect.Value, field reflect.StructField, setter setter, tag
 type setOptions struct {
 	isDefaultExists bool
 	defaultValue    string
+	required        bool
 }
 
 func tryToSetValue(value reflect.Value, field reflect.StructField, setter setter, tag string) (bool, error) {
@@ -158,10 +161,41 @@ func tryToSetValue(value reflect.Value, field reflect.StructField, setter setter
 		if k, v := head(opt, "="); k == "default" {
 			setOpt.isDefaultExists = true
 			setOpt.defaultValue = v
+		} else if k == "required" {
+			setOpt.required = true
 		}
 	}
 
-	return setter.TrySet(value, field, tagValue, setOpt)
+	if !setOpt.isDefaultExists {
+		if defaultValue, ok := field.Tag.Lookup("default"); ok {
+			setOpt.isDefaultExists = true
+			setOpt.defaultValue = defaultValue
+		}
+	}
+
+	isSet, err := setter.TrySet(value, field, tagValue, setOpt)
+	if err != nil {
+		return false, err
+	}
+	if src, ok := setter.(formValuer); ok && !isSet {
+		if isSet, err = trySetNested(value, field, src.formValues(), tagValue, tag); err != nil {
+			return false, err
+		}
+	}
+	if !isSet && setOpt.required && isLeafType(value.Type()) {
+		return false, &BindError{Field: field.Name, Key: tagValue, Source: tag, Err: ErrFieldRequired}
+	}
+	return isSet, nil
+}
+
+// isLeafType reports whether values of typ are set as a whole rather than
+// field by field.
+func isLeafType(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct || typ == timeType {
+		return true
+	}
+	_, ok := lookupTypeDecoder(typ)
+	return ok
 }
 
 func setByForm(value reflect.Value, field reflect.StructField, form map[string][]string, tagValue string, opt setOptions) (isSet bool, err error) {
@@ -170,6 +204,13 @@ func setByForm(value reflect.Value, field reflect.StructField, form map[string][
 		return false, nil
 	}
 
+	if decode, ok := lookupTypeDecoder(value.Type()); ok {
+		if len(vs) == 0 {
+			vs = []string{opt.defaultValue}
+		}
+		return true, setByTypeDecoder(decode, vs, value)
+	}
+
 	switch value.Kind() {
 	case reflect.Slice:
 		if !ok {
@@ -198,6 +239,10 @@ func setByForm(value reflect.Value, field reflect.StructField, form map[string][
 }
 
 func setWithProperType(val string, value reflect.Value, field reflect.StructField) error {
+	if decode, ok := lookupTypeDecoder(value.Type()); ok {
+		return setByTypeDecoder(decode, []string{val}, value)
+	}
+
 	switch value.Kind() {
 	case reflect.Int:
 		return setIntField(val, 0, value)
@@ -291,8 +336,11 @@ func setFloatField(val string, bitSize int, field reflect.Value) error {
 
 func setTimeField(val string, structField reflect.StructField, value reflect.Value) error {
 	timeFormat := structField.Tag.Get("time_format")
-	if timeFormat == "" {
-		timeFormat = time.RFC3339
+	layouts := TimeLayouts
+	if timeFormat != "" {
+		layouts = []string{timeFormat}
+	} else if len(layouts) > 0 {
+		timeFormat = layouts[0]
 	}
 
 	switch tf := strings.ToLower(timeFormat); tf {
@@ -330,13 +378,18 @@ func setTimeField(val string, structField reflect.StructField, value reflect.Val
 		l = loc
 	}
 
-	t, err := time.ParseInLocation(timeFormat, val, l)
-	if err != nil {
-		return err
+	var firstErr error
+	for _, layout := range layouts {
+		t, err := time.ParseInLocation(layout, val, l)
+		if err == nil {
+			value.Set(reflect.ValueOf(t))
+			return nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
 	}
-
-	value.Set(reflect.ValueOf(t))
-	return nil
+	return firstErr
 }
 
 func setArray(vals []string, value reflect.Value, field reflect.StructField) error {
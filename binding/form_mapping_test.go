@@ -5,10 +5,14 @@
 package binding
 
 import (
+	"errors"
+	"net"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gin-gonic/gin/internal/json"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -74,6 +78,77 @@ func TestMappingDefault(t *testing.T) {
 	assert.Equal(t, [1]int{9}, s.Array)
 }
 
+func TestMappingDefaultTag(t *testing.T) {
+	var s struct {
+		Int      int    `form:"int" default:"10"`
+		Ptr      *int   `form:"ptr" default:"5"`
+		NoDef    *int   `form:"nodef"`
+		Override string `form:"override,default=option" default:"tag"`
+	}
+	err := mappingByPtr(&s, formSource{}, "form")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 10, s.Int)
+	if assert.NotNil(t, s.Ptr) {
+		assert.Equal(t, 5, *s.Ptr)
+	}
+	assert.Nil(t, s.NoDef)
+	assert.Equal(t, "option", s.Override)
+
+	err = mappingByPtr(&s, formSource{"int": {"0"}}, "form")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, s.Int)
+}
+
+func TestMappingRequired(t *testing.T) {
+	var s struct {
+		Page    int       `form:"page,required"`
+		Name    string    `form:"name,required,default=gin"`
+		Created time.Time `form:"created,required"`
+	}
+	err := mappingByPtr(&s, formSource{"page": {"2"}, "created": {"2023-01-01T00:00:00Z"}}, "form")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, s.Page)
+	assert.Equal(t, "gin", s.Name)
+
+	err = mappingByPtr(&s, formSource{"page": {""}, "created": {""}}, "form")
+	assert.NoError(t, err)
+
+	err = mappingByPtr(&s, formSource{"created": {""}}, "form")
+	var bindErr *BindError
+	if assert.ErrorAs(t, err, &bindErr) {
+		assert.Equal(t, "Page", bindErr.Field)
+		assert.Equal(t, "page", bindErr.Key)
+		assert.Equal(t, "form", bindErr.Source)
+		assert.ErrorIs(t, err, ErrFieldRequired)
+		assert.EqualError(t, err, `binding: form "page" is required`)
+	}
+
+	err = mappingByPtr(&s, formSource{"page": {"1"}}, "form")
+	assert.ErrorAs(t, err, &bindErr)
+	assert.Equal(t, "Created", bindErr.Field)
+
+	var h struct {
+		Token string `header:"X-Token,required"`
+	}
+	err = mapHeader(&h, map[string][]string{})
+	if assert.ErrorAs(t, err, &bindErr) {
+		assert.Equal(t, "header", bindErr.Source)
+		assert.Equal(t, "X-Token", bindErr.Key)
+	}
+}
+
+func TestBindErrorJSON(t *testing.T) {
+	err := &BindError{Field: "Page", Key: "page", Source: "query", Err: ErrFieldRequired}
+	data, jsonErr := json.Marshal(err)
+	assert.NoError(t, jsonErr)
+	assert.JSONEq(t, `{"field":"Page","key":"page","source":"query","message":"field is required"}`, string(data))
+
+	err = &BindError{Field: "Page", Key: "page", Source: "query", Err: errors.New("bad")}
+	assert.EqualError(t, err, `binding: invalid query "page": bad`)
+	assert.Equal(t, "bad", errors.Unwrap(err).Error())
+}
+
 func TestMappingSkipField(t *testing.T) {
 	var s struct {
 		A int
@@ -288,3 +363,98 @@ func TestMappingIgnoredCircularRef(t *testing.T) {
 	err := mappingByPtr(&s, formSource{}, "form")
 	assert.NoError(t, err)
 }
+
+type testCurrency string
+
+func TestMappingTypeDecoder(t *testing.T) {
+	RegisterTypeDecoder(reflect.TypeOf(testCurrency("")), func(values []string) (any, error) {
+		if len(values[0]) != 3 {
+			return nil, errors.New("invalid currency")
+		}
+		return strings.ToUpper(values[0]), nil
+	})
+	defer RegisterTypeDecoder(reflect.TypeOf(testCurrency("")), nil)
+
+	layouts := TimeLayouts
+	TimeLayouts = append(TimeLayouts, "2006-01-02 15:04:05", "2006-01-02")
+	defer func() { TimeLayouts = layouts }()
+
+	var s struct {
+		Currency  testCurrency   `form:"currency"`
+		Accepted  []testCurrency `form:"accepted"`
+		Fallback  testCurrency   `form:"fallback,default=eur"`
+		Addr      net.IP         `form:"addr"`
+		Day       time.Time      `form:"day"`
+		Timestamp time.Time      `form:"ts"`
+	}
+	err := mappingByPtr(&s, formSource{
+		"currency": {"usd"},
+		"accepted": {"usd", "gbp"},
+		"addr":     {"10.0.0.1"},
+		"day":      {"2023-04-01"},
+		"ts":       {"2023-04-01 10:30:00"},
+	}, "form")
+	assert.NoError(t, err)
+	assert.Equal(t, testCurrency("USD"), s.Currency)
+	assert.Equal(t, []testCurrency{"USD", "GBP"}, s.Accepted)
+	assert.Equal(t, testCurrency("EUR"), s.Fallback)
+	assert.Equal(t, "10.0.0.1", s.Addr.String())
+	assert.Equal(t, 1, s.Day.Day())
+	assert.Equal(t, 30, s.Timestamp.Minute())
+
+	err = mappingByPtr(&s, formSource{"currency": {"dollar"}}, "form")
+	assert.Error(t, err)
+
+	err = mappingByPtr(&s, formSource{"addr": {"not-an-ip"}}, "form")
+	assert.Error(t, err)
+}
+
+func TestMappingNestedForm(t *testing.T) {
+	type item struct {
+		Name string `form:"name"`
+		Qty  int    `form:"qty,default=1"`
+	}
+	var s struct {
+		Items   []item            `form:"items"`
+		Ptrs    []*item           `form:"ptrs"`
+		Filters map[string]string `form:"filters"`
+		Groups  map[string][]int  `form:"groups"`
+		IDs     []int             `form:"ids"`
+		Pair    [2]string         `form:"pair"`
+		Owner   struct {
+			Name    string `form:"name"`
+			Address struct {
+				City string `form:"city"`
+			} `form:"address"`
+		} `form:"owner"`
+	}
+
+	err := mappingByPtr(&s, formSource{
+		"items[0][name]":       {"apple"},
+		"items[0][qty]":        {"3"},
+		"items[5].name":        {"pear"},
+		"ptrs[0].name":         {"plum"},
+		"filters[status]":      {"open"},
+		"filters[owner]":       {"me"},
+		"groups[a][]":          {"1", "2"},
+		"ids[]":                {"7", "8"},
+		"pair[1]":              {"b"},
+		"owner[name]":          {"bob"},
+		"owner[address][city]": {"Paris"},
+	}, "form")
+	assert.NoError(t, err)
+	assert.Equal(t, []item{{Name: "apple", Qty: 3}, {Name: "pear", Qty: 1}}, s.Items)
+	assert.Len(t, s.Ptrs, 1)
+	assert.Equal(t, "plum", s.Ptrs[0].Name)
+	assert.Equal(t, map[string]string{"status": "open", "owner": "me"}, s.Filters)
+	assert.Equal(t, map[string][]int{"a": {1, 2}}, s.Groups)
+	assert.Equal(t, []int{7, 8}, s.IDs)
+	assert.Equal(t, [2]string{"", "b"}, s.Pair)
+	assert.Equal(t, "bob", s.Owner.Name)
+	assert.Equal(t, "Paris", s.Owner.Address.City)
+
+	err = mappingByPtr(&s, formSource{"items[x][name]": {"bad"}}, "form")
+	assert.Error(t, err)
+	err = mappingByPtr(&s, formSource{"pair[2]": {"bad"}}, "form")
+	assert.Error(t, err)
+}
@@ -0,0 +1,206 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// formValuer is implemented by the setters that are backed by plain form
+// values, which allows bracketed keys to be bound into nested values.
+type formValuer interface {
+	formValues() map[string][]string
+}
+
+func (form formSource) formValues() map[string][]string {
+	return form
+}
+
+func (r *multipartRequest) formValues() map[string][]string {
+	return r.MultipartForm.Value
+}
+
+// trySetNested binds the keys nested under key into value, supporting both the
+// bracket (items[0][name], filters[status]) and the dot (items[0].name)
+// notations, as well as items[] for appending to a slice.
+func trySetNested(value reflect.Value, field reflect.StructField, form map[string][]string, key, tag string) (bool, error) {
+	switch value.Kind() {
+	case reflect.Struct:
+		if isLeafType(value.Type()) {
+			return false, nil
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+	default:
+		return false, nil
+	}
+
+	sub := nestedForm(form, key)
+	if len(sub) == 0 {
+		return false, nil
+	}
+	return true, setNested(value, field, sub, key, tag)
+}
+
+func setNested(value reflect.Value, field reflect.StructField, form map[string][]string, key, tag string) error {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		value = value.Elem()
+	}
+
+	if vs, ok := form[""]; ok && value.Kind() != reflect.Slice {
+		_, err := setByForm(value, field, map[string][]string{"": vs}, "", setOptions{})
+		return err
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if isLeafType(value.Type()) {
+			return nil
+		}
+		_, err := mapping(value, emptyField, formSource(form), tag)
+		return err
+	case reflect.Slice:
+		return setNestedSlice(value, field, form, key, tag)
+	case reflect.Array:
+		for seg, sub := range nestedSegments(form) {
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= value.Len() {
+				return fmt.Errorf("invalid index %q for %s", seg, key)
+			}
+			if err := setNested(value.Index(i), field, sub, key+"["+seg+"]", tag); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		return setNestedMap(value, field, form, key, tag)
+	default:
+		return errUnknownType
+	}
+}
+
+// setNestedSlice fills a slice from indexed keys. Indexes only define the order
+// of the elements, so sparse indexes produce a compact slice.
+func setNestedSlice(value reflect.Value, field reflect.StructField, form map[string][]string, key, tag string) error {
+	type element struct {
+		index int
+		form  map[string][]string
+	}
+
+	var elements []element
+	for seg, sub := range nestedSegments(form) {
+		if seg == "" {
+			continue
+		}
+		i, err := strconv.Atoi(seg)
+		if err != nil || i < 0 {
+			return fmt.Errorf("invalid index %q for %s", seg, key)
+		}
+		elements = append(elements, element{index: i, form: sub})
+	}
+	sort.Slice(elements, func(i, j int) bool { return elements[i].index < elements[j].index })
+
+	appended := form[""]
+	slice := reflect.MakeSlice(value.Type(), len(elements)+len(appended), len(elements)+len(appended))
+	for i, e := range elements {
+		if err := setNested(slice.Index(i), field, e.form, key+"["+strconv.Itoa(e.index)+"]", tag); err != nil {
+			return err
+		}
+	}
+	for i, v := range appended {
+		err := setNested(slice.Index(len(elements)+i), field, map[string][]string{"": {v}}, key+"[]", tag)
+		if err != nil {
+			return err
+		}
+	}
+	value.Set(slice)
+	return nil
+}
+
+func setNestedMap(value reflect.Value, field reflect.StructField, form map[string][]string, key, tag string) error {
+	typ := value.Type()
+	if typ.Key().Kind() != reflect.String {
+		return fmt.Errorf("unsupported map key type %s for %s", typ.Key(), key)
+	}
+	if value.IsNil() {
+		value.Set(reflect.MakeMap(typ))
+	}
+	for seg, sub := range nestedSegments(form) {
+		elem := reflect.New(typ.Elem()).Elem()
+		if err := setNested(elem, field, sub, key+"["+seg+"]", tag); err != nil {
+			return err
+		}
+		value.SetMapIndex(reflect.ValueOf(seg).Convert(typ.Key()), elem)
+	}
+	return nil
+}
+
+// nestedForm returns the keys of form nested under prefix, with the prefix
+// removed: for the prefix "items", "items[0][name]" and "items[0].name" become
+// "0[name]" and "0.name". The values of "items[]" are stored under "".
+func nestedForm(form map[string][]string, prefix string) map[string][]string {
+	var sub map[string][]string
+	for k, vs := range form {
+		if len(k) <= len(prefix) || !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		rest, ok := trimSegment(k[len(prefix):])
+		if !ok {
+			continue
+		}
+		if sub == nil {
+			sub = make(map[string][]string)
+		}
+		sub[rest] = append(sub[rest], vs...)
+	}
+	return sub
+}
+
+// nestedSegments groups the keys of form by their first segment, mapping each
+// segment to the keys nested under it. The values of a segment itself are
+// stored under "".
+func nestedSegments(form map[string][]string) map[string]map[string][]string {
+	segments := make(map[string]map[string][]string)
+	for k, vs := range form {
+		seg, rest := k, ""
+		if i := strings.IndexAny(k, "[."); i >= 0 {
+			var ok bool
+			if rest, ok = trimSegment(k[i:]); !ok {
+				continue
+			}
+			seg = k[:i]
+		}
+		if seg == "" && rest != "" {
+			continue
+		}
+		if segments[seg] == nil {
+			segments[seg] = make(map[string][]string)
+		}
+		segments[seg][rest] = append(segments[seg][rest], vs...)
+	}
+	return segments
+}
+
+// trimSegment turns the "[name]rest" and ".name" forms into "name" followed by
+// the rest of the key.
+func trimSegment(s string) (string, bool) {
+	switch s[0] {
+	case '.':
+		return s[1:], len(s) > 1
+	case '[':
+		i := strings.IndexByte(s, ']')
+		if i < 0 {
+			return "", false
+		}
+		return s[1:i] + s[i+1:], true
+	}
+	return "", false
+}
@@ -6,6 +6,7 @@ package binding
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -24,6 +25,36 @@ var EnableDecoderUseNumber = false
 // keys which do not match any non-ignored, exported fields in the destination.
 var EnableDecoderDisallowUnknownFields = false
 
+// ErrJSONBodyTooLarge is returned by a JSON binding configured with
+// JSONOptions.MaxBodySize when the body exceeds the limit.
+var ErrJSONBodyTooLarge = errors.New("json: request body too large")
+
+// ErrJSONTooDeep is returned by a JSON binding configured with
+// JSONOptions.MaxDepth when the body is nested deeper than the limit.
+var ErrJSONTooDeep = errors.New("json: exceeded max depth")
+
+// JSONOptions configures a JSON binding created with JSONWithOptions.
+// The package-level EnableDecoderUseNumber and EnableDecoderDisallowUnknownFields
+// flags still apply on top of these options.
+type JSONOptions struct {
+	// DisallowUnknownFields rejects object keys which do not match any field of the destination.
+	DisallowUnknownFields bool
+	// UseNumber decodes numbers into an any as a json.Number instead of a float64.
+	UseNumber bool
+	// MaxDepth limits the nesting of objects and arrays. Zero means no limit.
+	MaxDepth int
+	// MaxBodySize limits the size of the body in bytes. Zero means no limit.
+	MaxBodySize int64
+}
+
+// JSONWithOptions returns a JSON binding configured with opts.
+func JSONWithOptions(opts JSONOptions) BindingBody {
+	return jsonOptionsBinding{opts: opts}
+}
+
+// JSONStrict is a JSON binding which rejects unknown fields.
+var JSONStrict = JSONWithOptions(JSONOptions{DisallowUnknownFields: true})
+
 type jsonBinding struct{}
 
 func (jsonBinding) Name() string {
@@ -34,14 +65,25 @@ func (jsonBinding) Bind(req *http.Request, obj any) error {
 	if req == nil || req.Body == nil {
 		return errors.New("invalid request")
 	}
-	return decodeJSON(req.Body, obj)
+	return decodeJSON(req.Context(), req.Body, obj)
+}
+
+func (b jsonBinding) BindBody(body []byte, obj any) error {
+	return b.BindBodyCtx(context.Background(), body, obj)
 }
 
-func (jsonBinding) BindBody(body []byte, obj any) error {
-	return decodeJSON(bytes.NewReader(body), obj)
+func (jsonBinding) BindBodyCtx(ctx context.Context, body []byte, obj any) error {
+	return decodeJSON(ctx, bytes.NewReader(body), obj)
 }
 
-func decodeJSON(r io.Reader, obj any) error {
+func decodeJSON(ctx context.Context, r io.Reader, obj any) error {
+	if err := decodeJSONBody(r, obj); err != nil {
+		return err
+	}
+	return validateCtx(ctx, obj)
+}
+
+func decodeJSONBody(r io.Reader, obj any) error {
 	decoder := json.NewDecoder(r)
 	if EnableDecoderUseNumber {
 		decoder.UseNumber()
@@ -49,8 +91,87 @@ func decodeJSON(r io.Reader, obj any) error {
 	if EnableDecoderDisallowUnknownFields {
 		decoder.DisallowUnknownFields()
 	}
+	return decoder.Decode(obj)
+}
+
+type jsonOptionsBinding struct {
+	opts JSONOptions
+}
+
+func (jsonOptionsBinding) Name() string {
+	return "json"
+}
+
+func (b jsonOptionsBinding) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	r := io.Reader(req.Body)
+	if b.opts.MaxBodySize > 0 {
+		r = io.LimitReader(r, b.opts.MaxBodySize+1)
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return b.bindBody(req.Context(), body, obj)
+}
+
+func (b jsonOptionsBinding) BindBody(body []byte, obj any) error {
+	return b.bindBody(context.Background(), body, obj)
+}
+
+func (b jsonOptionsBinding) BindBodyCtx(ctx context.Context, body []byte, obj any) error {
+	return b.bindBody(ctx, body, obj)
+}
+
+func (b jsonOptionsBinding) bindBody(ctx context.Context, body []byte, obj any) error {
+	if b.opts.MaxBodySize > 0 && int64(len(body)) > b.opts.MaxBodySize {
+		return ErrJSONBodyTooLarge
+	}
+	if b.opts.MaxDepth > 0 && jsonDepth(body) > b.opts.MaxDepth {
+		return ErrJSONTooDeep
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if EnableDecoderUseNumber || b.opts.UseNumber {
+		decoder.UseNumber()
+	}
+	if EnableDecoderDisallowUnknownFields || b.opts.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
 	if err := decoder.Decode(obj); err != nil {
 		return err
 	}
-	return validate(obj)
+	return validateCtx(ctx, obj)
+}
+
+// jsonDepth returns the maximum nesting of objects and arrays in data.
+func jsonDepth(data []byte) int {
+	depth, maxDepth := 0, 0
+	inString, escaped := false, false
+	for _, ch := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+		switch ch {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return maxDepth
 }
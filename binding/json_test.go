@@ -5,6 +5,9 @@
 package binding
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -28,3 +31,44 @@ func TestJSONBindingBindBodyMap(t *testing.T) {
 	assert.Equal(t, "FOO", s["foo"])
 	assert.Equal(t, "world", s["hello"])
 }
+
+func TestJSONStrictBinding(t *testing.T) {
+	var s struct {
+		Foo string `json:"foo"`
+	}
+	err := JSONStrict.BindBody([]byte(`{"foo": "FOO"}`), &s)
+	require.NoError(t, err)
+	assert.Equal(t, "FOO", s.Foo)
+	assert.Equal(t, "json", JSONStrict.Name())
+
+	err = JSONStrict.BindBody([]byte(`{"foo": "FOO", "bar": "BAR"}`), &s)
+	assert.Error(t, err)
+}
+
+func TestJSONWithOptionsLimits(t *testing.T) {
+	var obj any
+	b := JSONWithOptions(JSONOptions{MaxDepth: 2, MaxBodySize: 32, UseNumber: true})
+
+	err := b.BindBody([]byte(`{"a": [1, "[[[{"]}`), &obj)
+	require.NoError(t, err)
+	number := obj.(map[string]any)["a"].([]any)[0]
+	_, isFloat := number.(float64)
+	assert.False(t, isFloat)
+	assert.Equal(t, "1", fmt.Sprint(number))
+
+	err = b.BindBody([]byte(`{"a": [{"b": 1}]}`), &obj)
+	assert.ErrorIs(t, err, ErrJSONTooDeep)
+
+	req, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a": "`+strings.Repeat("x", 32)+`"}`))
+	err = b.Bind(req, &obj)
+	assert.ErrorIs(t, err, ErrJSONBodyTooLarge)
+
+	err = b.Bind(nil, &obj)
+	assert.Error(t, err)
+}
+
+func TestJSONDepth(t *testing.T) {
+	assert.Equal(t, 0, jsonDepth([]byte(`"string"`)))
+	assert.Equal(t, 1, jsonDepth([]byte(`{"a": "}\"{{"}`)))
+	assert.Equal(t, 3, jsonDepth([]byte(`[[{}], []]`)))
+}
@@ -8,6 +8,7 @@ package binding
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
 
@@ -21,17 +22,30 @@ func (msgpackBinding) Name() string {
 }
 
 func (msgpackBinding) Bind(req *http.Request, obj any) error {
-	return decodeMsgPack(req.Body, obj)
+	return decodeMsgPack(req.Context(), req.Body, obj)
 }
 
-func (msgpackBinding) BindBody(body []byte, obj any) error {
-	return decodeMsgPack(bytes.NewReader(body), obj)
+func (b msgpackBinding) BindBody(body []byte, obj any) error {
+	return b.BindBodyCtx(context.Background(), body, obj)
 }
 
-func decodeMsgPack(r io.Reader, obj any) error {
-	cdc := new(codec.MsgpackHandle)
-	if err := codec.NewDecoder(r, cdc).Decode(&obj); err != nil {
+func (msgpackBinding) BindBodyCtx(ctx context.Context, body []byte, obj any) error {
+	return decodeMsgPack(ctx, bytes.NewReader(body), obj)
+}
+
+func decodeMsgPack(ctx context.Context, r io.Reader, obj any) error {
+	if err := decodeMsgPackBody(r, obj); err != nil {
 		return err
 	}
-	return validate(obj)
+	return validateCtx(ctx, obj)
+}
+
+func decodeMsgPackBody(r io.Reader, obj any) error {
+	cdc := new(codec.MsgpackHandle)
+	return codec.NewDecoder(r, cdc).Decode(&obj)
+}
+
+func init() {
+	bodyDecoders[MIMEMSGPACK] = decodeMsgPackBody
+	bodyDecoders[MIMEMSGPACK2] = decodeMsgPackBody
 }
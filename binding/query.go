@@ -4,7 +4,10 @@
 
 package binding
 
-import "net/http"
+import (
+	"errors"
+	"net/http"
+)
 
 type queryBinding struct{}
 
@@ -15,7 +18,11 @@ func (queryBinding) Name() string {
 func (queryBinding) Bind(req *http.Request, obj any) error {
 	values := req.URL.Query()
 	if err := mapForm(obj, values); err != nil {
+		var bindErr *BindError
+		if errors.As(err, &bindErr) {
+			bindErr.Source = "query"
+		}
 		return err
 	}
-	return validate(obj)
+	return validateCtx(req.Context(), obj)
 }
@@ -0,0 +1,246 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// ErrUnsupportedValidator is returned by NewTranslator when binding.Validator
+// is not powered by go-playground/validator.
+var ErrUnsupportedValidator = errors.New("validator engine is not *validator.Validate")
+
+// TranslationRegisterFunc registers the messages of a locale into a validator.
+type TranslationRegisterFunc func(v *validator.Validate, trans ut.Translator) error
+
+type translation struct {
+	locale   locales.Translator
+	register TranslationRegisterFunc
+}
+
+var (
+	translationsMu sync.RWMutex
+	translations   = map[string]translation{}
+)
+
+// RegisterTranslation makes the messages of a locale available to
+// NewTranslator. The en, es, fr and zh messages of the validator are
+// registered by importing the binding/translations package:
+//
+//	import _ "github.com/gin-gonic/gin/binding/translations"
+func RegisterTranslation(locale locales.Translator, register TranslationRegisterFunc) {
+	translationsMu.Lock()
+	translations[locale.Locale()] = translation{locale: locale, register: register}
+	translationsMu.Unlock()
+}
+
+// FieldError is a single validation failure, in a form which is suitable to be
+// sent back to clients.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// FieldErrors is the list of validation failures returned by Translator.
+type FieldErrors []FieldError
+
+// Error concatenates the messages of all the field errors.
+func (errs FieldErrors) Error() string {
+	var b strings.Builder
+	for i, fe := range errs {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(fe.Field)
+		b.WriteString(": ")
+		b.WriteString(fe.Message)
+	}
+	return b.String()
+}
+
+// Translator turns the errors returned by the validator into FieldErrors,
+// whose messages are translated in the locale negotiated with the client.
+type Translator struct {
+	uni *ut.UniversalTranslator
+}
+
+// NewTranslator returns a Translator for the given locales, registering their
+// messages into the validator engine of binding.Validator. The first locale is
+// the fallback used when none of the locales accepted by the client is
+// available. It defaults to "en".
+func NewTranslator(localeNames ...string) (*Translator, error) {
+	v, ok := Validator.Engine().(*validator.Validate)
+	if !ok {
+		return nil, ErrUnsupportedValidator
+	}
+	if len(localeNames) == 0 {
+		localeNames = []string{"en"}
+	}
+
+	translationsMu.RLock()
+	defer translationsMu.RUnlock()
+
+	var uni *ut.UniversalTranslator
+	for _, name := range localeNames {
+		t, ok := translations[normalizeLocale(name)]
+		if !ok {
+			return nil, fmt.Errorf("binding: no translation registered for locale %q", name)
+		}
+		if uni == nil {
+			uni = ut.New(t.locale, t.locale)
+		} else if err := uni.AddTranslator(t.locale, true); err != nil {
+			return nil, err
+		}
+		trans, _ := uni.GetTranslator(t.locale.Locale())
+		if err := t.register(v, trans); err != nil {
+			return nil, err
+		}
+	}
+	return &Translator{uni: uni}, nil
+}
+
+// Translate converts the validation errors in err into FieldErrors, using the
+// best locale for the given Accept-Language header. Other errors are returned
+// unchanged. A nil Translator produces untranslated messages.
+func (t *Translator) Translate(err error, obj any, acceptLanguage string) error {
+	var errs validator.ValidationErrors
+	var sliceErrs SliceValidationError
+	switch {
+	case errors.As(err, &errs):
+	case errors.As(err, &sliceErrs):
+		// validation errors of a slice of structs: report the first failing element.
+		for _, e := range sliceErrs {
+			if errors.As(e, &errs) {
+				break
+			}
+		}
+		if errs == nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	var trans ut.Translator
+	if t != nil {
+		trans, _ = t.uni.FindTranslator(acceptedLocales(acceptLanguage)...)
+	}
+
+	typ := reflect.TypeOf(obj)
+	fieldErrs := make(FieldErrors, 0, len(errs))
+	for _, fe := range errs {
+		message := fe.Error()
+		if trans != nil {
+			message = fe.Translate(trans)
+		}
+		fieldErrs = append(fieldErrs, FieldError{
+			Field:   fieldName(typ, fe.StructNamespace()),
+			Rule:    fe.Tag(),
+			Message: message,
+		})
+	}
+	return fieldErrs
+}
+
+// acceptedLocales returns the locales of an Accept-Language header ordered by
+// preference, each followed by its base language.
+func acceptedLocales(header string) []string {
+	type accepted struct {
+		locale string
+		q      float64
+	}
+
+	var list []accepted
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		list = append(list, accepted{locale: normalizeLocale(locale), q: q})
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].q > list[j].q })
+
+	locales := make([]string, 0, 2*len(list))
+	for _, a := range list {
+		locales = append(locales, a.locale)
+		if base, _, ok := strings.Cut(a.locale, "_"); ok {
+			locales = append(locales, base)
+		}
+	}
+	return locales
+}
+
+// normalizeLocale converts a BCP 47 tag (pt-BR) to the CLDR form used by the
+// locales package (pt_BR).
+func normalizeLocale(locale string) string {
+	base, region, ok := strings.Cut(strings.ReplaceAll(locale, "-", "_"), "_")
+	if !ok {
+		return strings.ToLower(base)
+	}
+	return strings.ToLower(base) + "_" + strings.ToUpper(region)
+}
+
+// fieldName resolves the struct namespace of a validation error (User.Address.City)
+// to the name the client knows the field by: its json or form tag, if any.
+func fieldName(typ reflect.Type, namespace string) string {
+	for typ != nil && (typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+		typ = typ.Elem()
+	}
+	parts := strings.Split(namespace, ".")
+	if len(parts) > 1 && (typ == nil || typ.Name() != "") {
+		// the namespace starts with the name of the type, unless it is anonymous.
+		parts = parts[1:]
+	}
+	names := make([]string, 0, len(parts))
+	for _, part := range parts {
+		name, index, _ := strings.Cut(part, "[")
+		for typ != nil && (typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+			typ = typ.Elem()
+		}
+		display := name
+		if typ != nil && typ.Kind() == reflect.Struct {
+			if sf, ok := typ.FieldByName(name); ok {
+				display = StructFieldName(sf)
+				typ = sf.Type
+			} else {
+				typ = nil
+			}
+		}
+		if index != "" {
+			display += "[" + index
+		}
+		names = append(names, display)
+	}
+	return strings.Join(names, ".")
+}
+
+// StructFieldName returns the name of a struct field in the FieldError.Field of
+// its validation errors: its json, form, uri, header, xml or yaml tag, or else
+// its Go name.
+func StructFieldName(sf reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri", "header", "xml", "yaml"} {
+		if name, _, _ := strings.Cut(sf.Tag.Get(tag), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/fr"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	frTranslations "github.com/go-playground/validator/v10/translations/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+type translationUser struct {
+	Email   string `json:"email" binding:"required"`
+	Age     int    `form:"age" binding:"gte=18"`
+	Address struct {
+		City string `json:"city" binding:"required"`
+	} `json:"address"`
+}
+
+func TestTranslatorTranslate(t *testing.T) {
+	RegisterTranslation(en.New(), enTranslations.RegisterDefaultTranslations)
+	RegisterTranslation(fr.New(), frTranslations.RegisterDefaultTranslations)
+	translator, err := NewTranslator("en", "fr")
+	assert.NoError(t, err)
+
+	obj := translationUser{Age: 10}
+	verr := validate(&obj)
+	assert.Error(t, verr)
+
+	err = translator.Translate(verr, &obj, "fr-CA;q=0.9, de")
+	var fieldErrs FieldErrors
+	assert.True(t, errors.As(err, &fieldErrs))
+	assert.Len(t, fieldErrs, 3)
+	assert.Equal(t, "email", fieldErrs[0].Field)
+	assert.Equal(t, "required", fieldErrs[0].Rule)
+	assert.Equal(t, "Email est un champ obligatoire", fieldErrs[0].Message)
+	assert.Equal(t, "age", fieldErrs[1].Field)
+	assert.Equal(t, "gte", fieldErrs[1].Rule)
+	assert.Equal(t, "address.city", fieldErrs[2].Field)
+
+	err = translator.Translate(verr, &obj, "")
+	assert.Equal(t, "Email is a required field", err.(FieldErrors)[0].Message)
+
+	// untranslated
+	err = (*Translator)(nil).Translate(verr, &obj, "fr")
+	assert.Contains(t, err.(FieldErrors)[0].Message, "'required' tag")
+
+	// other errors are returned unchanged
+	other := errors.New("other")
+	assert.Equal(t, other, translator.Translate(other, &obj, "en"))
+}
+
+func TestNewTranslatorUnknownLocale(t *testing.T) {
+	_, err := NewTranslator("xx")
+	assert.Error(t, err)
+}
+
+func TestAcceptedLocales(t *testing.T) {
+	assert.Equal(t, []string{"pt_BR", "pt", "en"}, acceptedLocales("en;q=0.5, pt-BR, *"))
+	assert.Empty(t, acceptedLocales(""))
+}
@@ -0,0 +1,29 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package translations registers the en, es, fr and zh messages of the
+// validator for binding.NewTranslator and Engine.SetValidatorTranslator. It is
+// imported for its side effect only:
+//
+//	import _ "github.com/gin-gonic/gin/binding/translations"
+package translations
+
+import (
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/zh"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	esTranslations "github.com/go-playground/validator/v10/translations/es"
+	frTranslations "github.com/go-playground/validator/v10/translations/fr"
+	zhTranslations "github.com/go-playground/validator/v10/translations/zh"
+)
+
+func init() {
+	binding.RegisterTranslation(en.New(), enTranslations.RegisterDefaultTranslations)
+	binding.RegisterTranslation(es.New(), esTranslations.RegisterDefaultTranslations)
+	binding.RegisterTranslation(fr.New(), frTranslations.RegisterDefaultTranslations)
+	binding.RegisterTranslation(zh.New(), zhTranslations.RegisterDefaultTranslations)
+}
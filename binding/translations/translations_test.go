@@ -0,0 +1,17 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package translations
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisteredTranslations(t *testing.T) {
+	_, err := binding.NewTranslator("en", "es", "fr", "zh")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// TypeDecoder converts the raw values of a form, query, header or uri key into
+// a value of the type it was registered for. values holds every value sent for
+// the key, so a decoder may also implement comma-separated or repeated formats.
+type TypeDecoder func(values []string) (any, error)
+
+// TimeLayouts are the layouts tried, in order, when binding a time.Time field
+// that has no time_format tag. Append to it to accept other formats, e.g.
+//
+//	binding.TimeLayouts = append(binding.TimeLayouts, time.DateOnly)
+var TimeLayouts = []string{time.RFC3339}
+
+var (
+	typeDecodersMu sync.RWMutex
+	typeDecoders   = map[reflect.Type]TypeDecoder{
+		reflect.TypeOf(net.IP{}): decodeIP,
+	}
+)
+
+// RegisterTypeDecoder registers the decoder used to bind fields of type typ
+// from form, query, header and uri values, so that third-party types
+// (decimal.Decimal, enums...) can be bound without wrapper types. The value
+// returned by decode must be assignable or convertible to typ. Registering a
+// nil decoder removes the decoder of typ.
+func RegisterTypeDecoder(typ reflect.Type, decode TypeDecoder) {
+	typeDecodersMu.Lock()
+	defer typeDecodersMu.Unlock()
+	if decode == nil {
+		delete(typeDecoders, typ)
+		return
+	}
+	typeDecoders[typ] = decode
+}
+
+func lookupTypeDecoder(typ reflect.Type) (TypeDecoder, bool) {
+	typeDecodersMu.RLock()
+	decode, ok := typeDecoders[typ]
+	typeDecodersMu.RUnlock()
+	return decode, ok
+}
+
+func setByTypeDecoder(decode TypeDecoder, vals []string, value reflect.Value) error {
+	v, err := decode(vals)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		value.Set(reflect.Zero(value.Type()))
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.Type().AssignableTo(value.Type()):
+		value.Set(rv)
+	case rv.Type().ConvertibleTo(value.Type()):
+		value.Set(rv.Convert(value.Type()))
+	default:
+		return fmt.Errorf("type decoder for %s returned %s", value.Type(), rv.Type())
+	}
+	return nil
+}
+
+func decodeIP(values []string) (any, error) {
+	if len(values) == 0 || values[0] == "" {
+		return net.IP(nil), nil
+	}
+	ip := net.ParseIP(values[0])
+	if ip == nil {
+		return nil, fmt.Errorf("%q is not a valid IP address", values[0])
+	}
+	return ip, nil
+}
@@ -6,6 +6,8 @@ package binding
 
 import (
 	"bytes"
+	"context"
+	"net/http"
 	"testing"
 	"time"
 
@@ -226,3 +228,55 @@ func TestValidatorEngine(t *testing.T) {
 	// Check that the error matches expectation
 	assert.Error(t, errs, "", "", "notone")
 }
+
+type groupedUser struct {
+	ID      int    `binding:"required" groups:"update"`
+	Name    string `binding:"required"`
+	Profile struct {
+		Bio string `binding:"required" groups:"create"`
+	}
+}
+
+func TestValidateGroups(t *testing.T) {
+	obj := groupedUser{Name: "bob"}
+	assert.Error(t, Validate(context.Background(), &obj))
+
+	ctx := WithValidationGroups(context.Background(), "create")
+	err := Validate(ctx, &obj)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Bio")
+	assert.NotContains(t, err.Error(), "ID")
+
+	obj.Profile.Bio = "hi"
+	assert.NoError(t, Validate(ctx, &obj))
+	assert.Error(t, Validate(WithValidationGroups(context.Background(), "update"), &obj))
+	assert.Equal(t, []string{"create"}, ValidationGroups(ctx))
+
+	assert.NoError(t, Validate(WithoutValidation(context.Background()), &groupedUser{}))
+}
+
+type tenantKey struct{}
+
+type ctxValidator struct {
+	StructValidator
+	ctx context.Context
+}
+
+func (v *ctxValidator) ValidateCtx(ctx context.Context, obj any) error {
+	v.ctx = ctx
+	return nil
+}
+
+func TestContextValidator(t *testing.T) {
+	v := &ctxValidator{StructValidator: Validator}
+	backup := Validator
+	Validator = v
+	defer func() { Validator = backup }()
+
+	req := requestWithBody(http.MethodPost, "/", `{"foo":"bar"}`)
+	ctx := context.WithValue(req.Context(), tenantKey{}, "tenant")
+	req = req.WithContext(ctx)
+	var obj FooStruct
+	assert.NoError(t, JSON.Bind(req, &obj))
+	assert.Equal(t, "tenant", v.ctx.Value(tenantKey{}))
+}
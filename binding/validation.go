@@ -0,0 +1,70 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import "context"
+
+// ContextValidator is implemented by the StructValidators which need the
+// context of the request, e.g. to apply tenant specific rules. When Validator
+// implements it, ValidateCtx is called with the context of the request being
+// bound instead of ValidateStruct.
+type ContextValidator interface {
+	ValidateCtx(ctx context.Context, obj any) error
+}
+
+// BindingBodyCtx adds BindBodyCtx method to BindingBody. BindBodyCtx is similar
+// with BindBody, but it validates with ctx, like Bind does with the context of
+// the request, e.g. to honor WithoutValidation and WithValidationGroups.
+type BindingBodyCtx interface {
+	BindingBody
+	BindBodyCtx(ctx context.Context, body []byte, obj any) error
+}
+
+type validationGroupsKey struct{}
+
+type skipValidationKey struct{}
+
+// WithValidationGroups returns a copy of ctx carrying validation groups (also known
+// as scenarios, e.g. "create" or "update"). The default validator only validates the
+// fields whose groups tag contains one of them, fields without a groups tag are
+// always validated:
+//
+//	type User struct {
+//		ID   int    `json:"id" binding:"required" groups:"update"`
+//		Name string `json:"name" binding:"required"`
+//	}
+func WithValidationGroups(ctx context.Context, groups ...string) context.Context {
+	return context.WithValue(ctx, validationGroupsKey{}, groups)
+}
+
+// ValidationGroups returns the validation groups carried by ctx.
+func ValidationGroups(ctx context.Context) []string {
+	groups, _ := ctx.Value(validationGroupsKey{}).([]string)
+	return groups
+}
+
+// WithoutValidation returns a copy of ctx with which binding skips validation.
+func WithoutValidation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipValidationKey{}, true)
+}
+
+// Validate validates obj with Validator, passing ctx along when Validator
+// implements ContextValidator.
+func Validate(ctx context.Context, obj any) error {
+	return validateCtx(ctx, obj)
+}
+
+func validateCtx(ctx context.Context, obj any) error {
+	if Validator == nil {
+		return nil
+	}
+	if skip, _ := ctx.Value(skipValidationKey{}).(bool); skip {
+		return nil
+	}
+	if v, ok := Validator.(ContextValidator); ok {
+		return v.ValidateCtx(ctx, obj)
+	}
+	return Validator.ValidateStruct(obj)
+}
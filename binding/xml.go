@@ -6,6 +6,7 @@ package binding
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"io"
 	"net/http"
@@ -18,16 +19,23 @@ func (xmlBinding) Name() string {
 }
 
 func (xmlBinding) Bind(req *http.Request, obj any) error {
-	return decodeXML(req.Body, obj)
+	return decodeXML(req.Context(), req.Body, obj)
 }
 
-func (xmlBinding) BindBody(body []byte, obj any) error {
-	return decodeXML(bytes.NewReader(body), obj)
+func (b xmlBinding) BindBody(body []byte, obj any) error {
+	return b.BindBodyCtx(context.Background(), body, obj)
 }
-func decodeXML(r io.Reader, obj any) error {
-	decoder := xml.NewDecoder(r)
-	if err := decoder.Decode(obj); err != nil {
+
+func (xmlBinding) BindBodyCtx(ctx context.Context, body []byte, obj any) error {
+	return decodeXML(ctx, bytes.NewReader(body), obj)
+}
+func decodeXML(ctx context.Context, r io.Reader, obj any) error {
+	if err := decodeXMLBody(r, obj); err != nil {
 		return err
 	}
-	return validate(obj)
+	return validateCtx(ctx, obj)
+}
+
+func decodeXMLBody(r io.Reader, obj any) error {
+	return xml.NewDecoder(r).Decode(obj)
 }
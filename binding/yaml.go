@@ -6,6 +6,7 @@ package binding
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
 
@@ -19,17 +20,24 @@ func (yamlBinding) Name() string {
 }
 
 func (yamlBinding) Bind(req *http.Request, obj any) error {
-	return decodeYAML(req.Body, obj)
+	return decodeYAML(req.Context(), req.Body, obj)
 }
 
-func (yamlBinding) BindBody(body []byte, obj any) error {
-	return decodeYAML(bytes.NewReader(body), obj)
+func (b yamlBinding) BindBody(body []byte, obj any) error {
+	return b.BindBodyCtx(context.Background(), body, obj)
 }
 
-func decodeYAML(r io.Reader, obj any) error {
-	decoder := yaml.NewDecoder(r)
-	if err := decoder.Decode(obj); err != nil {
+func (yamlBinding) BindBodyCtx(ctx context.Context, body []byte, obj any) error {
+	return decodeYAML(ctx, bytes.NewReader(body), obj)
+}
+
+func decodeYAML(ctx context.Context, r io.Reader, obj any) error {
+	if err := decodeYAMLBody(r, obj); err != nil {
 		return err
 	}
-	return validate(obj)
+	return validateCtx(ctx, obj)
+}
+
+func decodeYAMLBody(r io.Reader, obj any) error {
+	return yaml.NewDecoder(r).Decode(obj)
 }
@@ -0,0 +1,212 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BlobInfo describes a blob of a BlobStorage.
+type BlobInfo struct {
+	Name        string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// BlobStorage stores the uploaded and static files, on the local disk with
+// DiskStorage, or in S3, GCS... with an adapter. The names of the blobs are
+// slash-separated paths, e.g. "avatars/me.png", which can not escape the
+// storage. The methods return an error satisfying errors.Is(err,
+// fs.ErrNotExist) for a missing blob.
+//
+// See Engine.UploadStorage, UploadOptions.Storage, RouterGroup.StaticStorage
+// and Context.FileFromStorage.
+type BlobStorage interface {
+	// Open returns the content of the blob name. When it is an io.ReadSeeker,
+	// the range requests are supported.
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	// Save stores the content read from r as the blob name, replacing it if
+	// any. info holds the size and the type of the content when known.
+	Save(ctx context.Context, name string, r io.Reader, info BlobInfo) error
+	// Stat describes the blob name.
+	Stat(ctx context.Context, name string) (BlobInfo, error)
+	// Delete removes the blob name.
+	Delete(ctx context.Context, name string) error
+}
+
+type diskStorage struct {
+	root string
+}
+
+// DiskStorage returns a BlobStorage storing the blobs as files under the
+// directory root, created when needed. The files are written to a temporary
+// file renamed at the end, with the mode 0640, and the types of the blobs are
+// guessed from their extensions.
+func DiskStorage(root string) BlobStorage {
+	return diskStorage{root: root}
+}
+
+// path returns the path of the file of the blob name.
+func (s diskStorage) path(op, name string) (string, error) {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" || filepath.Separator != '/' && strings.ContainsRune(name, filepath.Separator) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(s.root, filepath.FromSlash(name)), nil
+}
+
+func (s diskStorage) Open(_ context.Context, name string) (io.ReadCloser, error) {
+	p, err := s.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := f.Stat(); err != nil || info.IsDir() {
+		f.Close()
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return f, nil
+}
+
+func (s diskStorage) Save(_ context.Context, name string, r io.Reader, _ BlobInfo) error {
+	p, err := s.path("save", name)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".blob-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails once renamed
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), 0o640); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (s diskStorage) Stat(_ context.Context, name string) (BlobInfo, error) {
+	p, err := s.path("stat", name)
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	if info.IsDir() {
+		return BlobInfo{}, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return BlobInfo{
+		Name:        name,
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(path.Ext(name)),
+		ModTime:     info.ModTime(),
+	}, nil
+}
+
+func (s diskStorage) Delete(_ context.Context, name string) error {
+	p, err := s.path("delete", name)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(p); err == nil && info.IsDir() {
+		return &fs.PathError{Op: "delete", Path: name, Err: fs.ErrNotExist}
+	}
+	return os.Remove(p)
+}
+
+// FileFromStorage writes the blob name of storage into the body stream, with
+// the Range and conditional requests supported when the storage opens the
+// blobs as io.ReadSeeker. It returns an error satisfying errors.Is(err,
+// fs.ErrNotExist) when the blob is missing, before writing anything.
+func (c *Context) FileFromStorage(storage BlobStorage, name string) error {
+	ctx := c.Request.Context()
+	info, err := storage.Stat(ctx, name)
+	if err != nil {
+		return err
+	}
+	r, err := storage.Open(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	c.DataFromReaderWithConfig(http.StatusOK, r, ReaderConfig{
+		ContentType:   info.ContentType,
+		ContentLength: info.Size,
+		Name:          path.Base(name),
+		ModTime:       info.ModTime,
+	})
+	return nil
+}
+
+// saveUploadBlob saves src as the blob name of storage, once inspected by
+// inspector if any.
+func (c *Context) saveUploadBlob(src io.ReadSeeker, storage BlobStorage, name string, info UploadInfo, inspector UploadInspector) error {
+	if inspector != nil {
+		if _, err := c.inspectUpload(io.Discard, src, info, inspector); err != nil {
+			return err
+		}
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	ctx := context.Background()
+	if c.Request != nil {
+		ctx = c.Request.Context()
+	}
+	return storage.Save(ctx, name, src, BlobInfo{Name: name, Size: info.Size, ContentType: info.ContentType})
+}
+
+// StaticStorage serves the blobs of storage, like Static serves the files of a
+// directory: a missing blob is handled by the NoRoute handlers.
+//
+//	router.StaticStorage("/uploads", s3Storage)
+func (group *RouterGroup) StaticStorage(relativePath string, storage BlobStorage) IRoutes {
+	if strings.Contains(relativePath, ":") || strings.Contains(relativePath, "*") {
+		panic("URL parameters can not be used when serving a static folder")
+	}
+	handler := func(c *Context) {
+		group.staticError(c, c.FileFromStorage(storage, c.Param("filepath")))
+	}
+	urlPattern := path.Join(relativePath, "/*filepath")
+	return group.handleMethods([]string{http.MethodGet, http.MethodHead}, urlPattern, HandlersChain{handler})
+}
+
+// staticError handles the error of a static handler: a missing file is handled
+// by the NoRoute handlers.
+func (group *RouterGroup) staticError(c *Context, err error) {
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrInvalid) {
+		c.Writer.WriteHeader(http.StatusNotFound)
+		c.handlers = group.engine.noRoute
+		// Reset index
+		c.index = -1
+	} else if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+	}
+}
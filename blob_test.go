@@ -0,0 +1,181 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStorage is a BlobStorage whose blobs are not seekable, like the ones
+// of an object storage.
+type memoryStorage struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+	infos map[string]BlobInfo
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{blobs: map[string][]byte{}, infos: map[string]BlobInfo{}}
+}
+
+func (s *memoryStorage) Open(_ context.Context, name string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.blobs[strings.TrimPrefix(name, "/")]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memoryStorage) Save(_ context.Context, name string, r io.Reader, info BlobInfo) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info.Size = int64(len(data))
+	s.blobs[name], s.infos[name] = data, info
+	return nil
+}
+
+func (s *memoryStorage) Stat(_ context.Context, name string) (BlobInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info, ok := s.infos[strings.TrimPrefix(name, "/")]
+	if !ok {
+		return BlobInfo{}, fs.ErrNotExist
+	}
+	return info, nil
+}
+
+func (s *memoryStorage) Delete(_ context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.blobs, name)
+	delete(s.infos, name)
+	return nil
+}
+
+func TestDiskStorage(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	storage := DiskStorage(dir)
+
+	require.NoError(t, storage.Save(ctx, "docs/a.txt", strings.NewReader("hello"), BlobInfo{}))
+	data, err := os.ReadFile(filepath.Join(dir, "docs", "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	info, err := storage.Stat(ctx, "/docs/../docs/a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), info.Size)
+	assert.Equal(t, "text/plain; charset=utf-8", info.ContentType)
+
+	r, err := storage.Open(ctx, "docs/a.txt")
+	require.NoError(t, err)
+	data, _ = io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "hello", string(data))
+
+	require.NoError(t, storage.Save(ctx, "../../escape.txt", strings.NewReader("x"), BlobInfo{}))
+	_, err = os.Stat(filepath.Join(dir, "escape.txt"))
+	assert.NoError(t, err, "the name can not escape the root")
+
+	_, err = storage.Stat(ctx, "docs")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = storage.Open(ctx, "docs")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = storage.Stat(ctx, "/")
+	assert.ErrorIs(t, err, fs.ErrInvalid)
+
+	require.NoError(t, storage.Delete(ctx, "docs/a.txt"))
+	_, err = storage.Open(ctx, "docs/a.txt")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.ErrorIs(t, storage.Delete(ctx, "docs/a.txt"), fs.ErrNotExist)
+
+	entries, _ := os.ReadDir(filepath.Join(dir, "docs"))
+	assert.Empty(t, entries, "no temporary file is left")
+}
+
+func TestStaticStorage(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, DiskStorage(dir).Save(context.Background(), "css/site.css", strings.NewReader("body{}"), BlobInfo{}))
+	memory := newMemoryStorage()
+	require.NoError(t, memory.Save(context.Background(), "a.json", strings.NewReader(`{"a":1}`), BlobInfo{ContentType: MIMEJSON}))
+
+	router := New()
+	router.StaticStorage("/disk", DiskStorage(dir))
+	router.StaticStorage("/memory", memory)
+	router.NoRoute(func(c *Context) { c.String(http.StatusNotFound, "no route") })
+
+	w := PerformRequest(router, http.MethodGet, "/disk/css/site.css")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "body{}", w.Body.String())
+	assert.Equal(t, "text/css; charset=utf-8", w.Header().Get("Content-Type"))
+
+	w = PerformRequest(router, http.MethodGet, "/disk/css/site.css", header{"Range", "bytes=0-3"})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "body", w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/memory/a.json")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"a":1}`, w.Body.String())
+	assert.Equal(t, MIMEJSON, w.Header().Get("Content-Type"))
+	assert.Equal(t, "7", w.Header().Get("Content-Length"))
+
+	for _, p := range []string{"/disk/css", "/disk/missing.css", "/disk/../../etc/passwd", "/memory/b.json"} {
+		w = PerformRequest(router, http.MethodGet, p)
+		assert.Equal(t, http.StatusNotFound, w.Code, p)
+		assert.Equal(t, "no route", w.Body.String(), p)
+	}
+
+	assert.Panics(t, func() { router.StaticStorage("/:id", memory) })
+}
+
+func TestSaveUploadedFileStorage(t *testing.T) {
+	storage := newMemoryStorage()
+	c := uploadContext(t, "f", uploadFile{"me.png", pngImage(t, 10, 10)}, uploadFile{"virus.txt", []byte("EICAR")})
+	c.engine.UploadStorage = storage
+	files, err := c.FormFiles("f")
+	require.NoError(t, err)
+
+	require.NoError(t, c.SaveUploadedFile(files[0], filepath.Join("raw", "me.png")))
+	assert.Equal(t, files[0].Size, storage.infos["raw/me.png"].Size)
+
+	name, err := c.SaveUploadedFileChecked(files[0], "avatars", UploadOptions{AllowedTypes: []string{"image/png"}})
+	require.NoError(t, err)
+	assert.Equal(t, "avatars/me.png", name)
+	assert.Equal(t, "image/png", storage.infos[name].ContentType)
+
+	_, err = c.SaveUploadedFileChecked(files[0], "avatars", UploadOptions{})
+	assert.ErrorIs(t, err, ErrUploadExists)
+
+	_, err = c.SaveUploadedFileChecked(files[1], "docs", UploadOptions{Inspector: signatureScanner})
+	assert.ErrorIs(t, err, ErrUploadRejected)
+	c.engine.UploadInspector = signatureScanner
+	assert.ErrorIs(t, c.SaveUploadedFile(files[1], "raw/virus.txt"), ErrUploadRejected)
+	assert.Len(t, storage.blobs, 2)
+	assert.Len(t, c.Errors, 3)
+
+	dir := t.TempDir()
+	name, err = c.SaveUploadedFileChecked(files[0], "avatars", UploadOptions{Storage: DiskStorage(dir)})
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,38 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import "github.com/gin-gonic/gin/internal/bufpool"
+
+// BufferClassStats reports the use of a size class of the buffer pool.
+type BufferClassStats struct {
+	// Size is the capacity of the buffers of the class.
+	Size int `json:"size"`
+	// Gets is the number of buffers taken from the class.
+	Gets uint64 `json:"gets"`
+	// News is the number of buffers allocated for the class.
+	News uint64 `json:"news"`
+	// Puts is the number of buffers returned to the class.
+	Puts uint64 `json:"puts"`
+}
+
+// BufferPoolStats reports the use of the buffers shared by the JSON, XML and
+// YAML renderers, see GetBufferPoolStats.
+type BufferPoolStats struct {
+	Classes []BufferClassStats `json:"classes"`
+	// Dropped is the number of buffers too large to be pooled.
+	Dropped uint64 `json:"dropped"`
+}
+
+// GetBufferPoolStats returns the statistics of the buffer pool, which is shared
+// by all the engines. News close to Gets show that the pool is not effective.
+func GetBufferPoolStats() BufferPoolStats {
+	classes, dropped := bufpool.Stats()
+	stats := BufferPoolStats{Classes: make([]BufferClassStats, len(classes)), Dropped: dropped}
+	for i, c := range classes {
+		stats.Classes[i] = BufferClassStats(c)
+	}
+	return stats
+}
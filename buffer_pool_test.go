@@ -0,0 +1,42 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func totalGets(stats BufferPoolStats) (gets uint64) {
+	for _, c := range stats.Classes {
+		gets += c.Gets
+	}
+	return gets
+}
+
+func TestBufferPoolStats(t *testing.T) {
+	router := New()
+	router.POST("/echo", func(c *Context) {
+		body, err := c.GetRawData()
+		assert.NoError(t, err)
+		c.JSON(http.StatusOK, H{"body": string(body)})
+	})
+
+	before := GetBufferPoolStats()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/echo", strings.NewReader("hello"))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"body":"hello"}`, w.Body.String())
+	after := GetBufferPoolStats()
+	assert.Len(t, after.Classes, 6)
+	assert.Equal(t, 1024, after.Classes[0].Size)
+	assert.Equal(t, totalGets(before)+1, totalGets(after))
+}
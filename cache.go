@@ -0,0 +1,555 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin/internal/json"
+	"github.com/gin-gonic/gin/internal/singleflight"
+)
+
+const (
+	defaultCacheTTL         = time.Minute
+	defaultCacheMaxBodySize = 1 << 20
+)
+
+// ErrCacheMiss is returned by a CacheStore when the requested key does not exist.
+var ErrCacheMiss = errors.New("cache: key not found")
+
+// CachedResponse is a full HTTP response stored by the Cache middleware.
+type CachedResponse struct {
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"stored_at"`
+}
+
+// CacheStore is the interface which needs to be implemented by the storage
+// backends of the Cache middleware.
+type CacheStore interface {
+	// Get returns the response stored under key, or ErrCacheMiss if there is none.
+	Get(key string) (*CachedResponse, error)
+	// Set stores the response under key. A ttl <= 0 means the entry never expires.
+	Set(key string, value *CachedResponse, ttl time.Duration) error
+	// Delete removes the response stored under key.
+	Delete(key string) error
+}
+
+// CacheConfig defines the config for Cache middleware.
+type CacheConfig struct {
+	// Store is the backend where responses are kept.
+	// Optional. Default value is a new gin.NewMemoryStore().
+	Store CacheStore
+
+	// TTL is the lifetime of a cached response.
+	// Optional. Default value is one minute.
+	TTL time.Duration
+
+	// RouteTTL overrides TTL for the given route templates (as returned by Context.FullPath).
+	// Optional.
+	RouteTTL map[string]time.Duration
+
+	// VaryHeaders is a list of request headers whose values are part of the cache key.
+	// Optional.
+	VaryHeaders []string
+
+	// MaxBodySize is the size in bytes of the largest response body buffered
+	// to be cached, the larger responses are written but not cached.
+	// Optional. Default value is 1MB.
+	MaxBodySize int
+}
+
+// Cache returns a middleware that caches full GET and HEAD responses keyed by
+// method, host, request URI and the configured vary headers. Concurrent misses
+// for the same key are collapsed into a single handler execution.
+//
+// The no-store, no-cache, max-age and only-if-cached request directives of the
+// Cache-Control header are honored. The responses setting cookies, or whose
+// Cache-Control header is no-store or private, are not cached, and the ones
+// with a Vary header are cached per value of the headers it lists. The requests
+// with an Authorization or a Cookie header bypass the cache, unless the
+// response is explicitly public.
+func Cache(config CacheConfig) HandlerFunc {
+	store := config.Store
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	ttl := config.TTL
+	if ttl == 0 {
+		ttl = defaultCacheTTL
+	}
+	maxBodySize := config.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = defaultCacheMaxBodySize
+	}
+
+	var group singleflight.Group
+
+	return func(c *Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		directives := parseCacheControl(c.requestHeader("Cache-Control"))
+		if _, ok := directives["no-store"]; ok {
+			c.Next()
+			return
+		}
+
+		key := cacheKey(c, config.VaryHeaders)
+		// the responses to the requests of a user are only shared if they are public.
+		authenticated := c.requestHeader("Authorization") != "" || c.requestHeader("Cookie") != ""
+		if _, noCache := directives["no-cache"]; !noCache {
+			resp, err := lookupCachedResponse(store, key, c)
+			if err == nil && resp != nil && resp.acceptable(directives) && (!authenticated || resp.public()) {
+				writeCachedResponse(c, resp)
+				return
+			}
+			if err != nil && !errors.Is(err, ErrCacheMiss) {
+				_ = c.Error(err)
+			}
+		}
+		if _, ok := directives["only-if-cached"]; ok {
+			c.AbortWithStatus(http.StatusGatewayTimeout)
+			return
+		}
+
+		handle := func() *cacheFlight {
+			recorder := &cacheRecorder{ResponseWriter: c.Writer, maxBodySize: maxBodySize}
+			c.Writer = recorder
+			defer func() { c.Writer = recorder.ResponseWriter }()
+
+			c.Next()
+
+			if !recorder.cacheable() || (authenticated && !recorder.public()) {
+				return nil
+			}
+			resp := &CachedResponse{
+				Status:   recorder.Status(),
+				Header:   recorder.Header().Clone(),
+				Body:     recorder.body.Bytes(),
+				StoredAt: time.Now(),
+			}
+			routeTTL := ttl
+			if d, ok := config.RouteTTL[c.FullPath()]; ok {
+				routeTTL = d
+			}
+			flight := &cacheFlight{resp: resp, key: key}
+			if vary := varyHeaders(resp.Header); len(vary) > 0 {
+				// the base key holds the names of the headers the variants are keyed by.
+				marker := &CachedResponse{Header: http.Header{"Vary": vary}, StoredAt: resp.StoredAt}
+				if err := store.Set(key, marker, routeTTL); err != nil {
+					_ = c.Error(err)
+				}
+				flight.key = variantKey(key, vary, c)
+			}
+			if err := store.Set(flight.key, resp, routeTTL); err != nil {
+				_ = c.Error(err)
+			}
+			return flight
+		}
+		if authenticated {
+			handle()
+			return
+		}
+
+		executed := false
+		v, _, _ := group.Do(key, func() (any, error) {
+			executed = true
+			return handle(), nil
+		})
+		if executed {
+			return
+		}
+
+		// the response is shared if the request has the same varied headers.
+		if flight, ok := v.(*cacheFlight); ok && flight != nil {
+			if vary := varyHeaders(flight.resp.Header); len(vary) == 0 || variantKey(key, vary, c) == flight.key {
+				writeCachedResponse(c, flight.resp)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// cacheFlight is the result of the handlers of a request cached by Cache.
+type cacheFlight struct {
+	resp *CachedResponse
+	// key is the key of resp, which includes the varied headers.
+	key string
+}
+
+// lookupCachedResponse returns the response cached under key, or under the
+// key of its variant matching c for the responses with a Vary header.
+func lookupCachedResponse(store CacheStore, key string, c *Context) (*CachedResponse, error) {
+	resp, err := store.Get(key)
+	if err != nil || resp == nil || resp.Status != 0 {
+		return resp, err
+	}
+	return store.Get(variantKey(key, resp.Header.Values("Vary"), c))
+}
+
+// varyHeaders returns the canonical names of the headers of the Vary header.
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+// variantKey returns the key of the variant of the response cached under key
+// for the values of the vary headers of the request.
+func variantKey(key string, vary []string, c *Context) string {
+	var b strings.Builder
+	b.WriteString(key)
+	for _, name := range vary {
+		b.WriteString("\nvary ")
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(c.Request.Header.Values(name), ","))
+	}
+	return b.String()
+}
+
+func cacheKey(c *Context, varyHeaders []string) string {
+	var b strings.Builder
+	b.WriteString(c.Request.Method)
+	b.WriteByte(' ')
+	b.WriteString(c.Request.Host)
+	b.WriteString(c.Request.URL.RequestURI())
+	for _, name := range varyHeaders {
+		b.WriteByte('\n')
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(c.requestHeader(name))
+	}
+	return b.String()
+}
+
+// parseCacheControl parses a Cache-Control header into a map of directives.
+func parseCacheControl(header string) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(part, "=")
+		directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return directives
+}
+
+func (resp *CachedResponse) age() time.Duration {
+	return time.Since(resp.StoredAt)
+}
+
+// acceptable reports whether the response satisfies the max-age request directive.
+func (resp *CachedResponse) acceptable(directives map[string]string) bool {
+	maxAge, ok := directives["max-age"]
+	if !ok {
+		return true
+	}
+	seconds, err := strconv.Atoi(maxAge)
+	if err != nil {
+		return true
+	}
+	return resp.age() <= time.Duration(seconds)*time.Second
+}
+
+// public reports whether the response is explicitly cacheable by shared caches.
+func (resp *CachedResponse) public() bool {
+	_, ok := parseCacheControl(resp.Header.Get("Cache-Control"))["public"]
+	return ok
+}
+
+func writeCachedResponse(c *Context, resp *CachedResponse) {
+	header := c.Writer.Header()
+	for k, v := range resp.Header {
+		header[k] = v
+	}
+	header.Set("Age", strconv.Itoa(int(resp.age().Seconds())))
+	c.Status(resp.Status)
+	if c.Request.Method == http.MethodHead {
+		c.Writer.WriteHeaderNow()
+	} else {
+		_, _ = c.Writer.Write(resp.Body)
+	}
+	c.Abort()
+}
+
+type cacheRecorder struct {
+	ResponseWriter
+	body bytes.Buffer
+	// maxBodySize is the size beyond which the body is no longer buffered,
+	// 0 means no limit.
+	maxBodySize int
+	truncated   bool
+}
+
+func (w *cacheRecorder) Write(data []byte) (int, error) {
+	if w.record(len(data)) {
+		w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cacheRecorder) WriteString(s string) (int, error) {
+	if w.record(len(s)) {
+		w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// record reports whether n more bytes of the body are to be buffered. Once the
+// body exceeds maxBodySize, the buffered part is released.
+func (w *cacheRecorder) record(n int) bool {
+	if w.truncated {
+		return false
+	}
+	if w.maxBodySize > 0 && w.body.Len()+n > w.maxBodySize {
+		w.truncated = true
+		w.body = bytes.Buffer{}
+		return false
+	}
+	return true
+}
+
+func (w *cacheRecorder) cacheable() bool {
+	return w.Status() == http.StatusOK && w.shareable()
+}
+
+// shareable reports whether the response may be written to other requests.
+func (w *cacheRecorder) shareable() bool {
+	if w.truncated {
+		return false
+	}
+	// the cookies of a user must not be sent to the others.
+	if len(w.Header().Values("Set-Cookie")) > 0 {
+		return false
+	}
+	for _, name := range varyHeaders(w.Header()) {
+		if name == "*" {
+			return false
+		}
+	}
+	directives := parseCacheControl(w.Header().Get("Cache-Control"))
+	_, noStore := directives["no-store"]
+	_, private := directives["private"]
+	return !noStore && !private
+}
+
+func (w *cacheRecorder) public() bool {
+	_, ok := parseCacheControl(w.Header().Get("Cache-Control"))["public"]
+	return ok
+}
+
+const (
+	defaultMemoryStoreMaxEntries = 10000
+	defaultMemoryStoreSweep      = time.Minute
+)
+
+// MemoryStoreConfig defines the config of NewMemoryStoreWithConfig.
+type MemoryStoreConfig struct {
+	// MaxEntries is the maximum number of entries, beyond which the least
+	// recently used ones are evicted.
+	// Optional. Default value is 10000.
+	MaxEntries int
+
+	// SweepInterval is the minimum interval between two removals of all the
+	// expired entries, which are done by Set.
+	// Optional. Default value is one minute.
+	SweepInterval time.Duration
+}
+
+type memoryEntry struct {
+	key     string
+	value   *CachedResponse
+	expires time.Time
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && now.After(e.expires)
+}
+
+// MemoryStore is an in-process CacheStore, bounded in number of entries.
+type MemoryStore struct {
+	mu            sync.Mutex
+	maxEntries    int
+	sweepInterval time.Duration
+	lastSweep     time.Time
+	entries       map[string]*list.Element
+	// lru holds the entries, from the most to the least recently used.
+	lru *list.List
+}
+
+var _ CacheStore = (*MemoryStore)(nil)
+
+// NewMemoryStore returns an empty MemoryStore with the default config.
+func NewMemoryStore() *MemoryStore {
+	return NewMemoryStoreWithConfig(MemoryStoreConfig{})
+}
+
+// NewMemoryStoreWithConfig returns an empty MemoryStore with the given config.
+func NewMemoryStoreWithConfig(config MemoryStoreConfig) *MemoryStore {
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = defaultMemoryStoreMaxEntries
+	}
+	if config.SweepInterval <= 0 {
+		config.SweepInterval = defaultMemoryStoreSweep
+	}
+	return &MemoryStore{
+		maxEntries:    config.MaxEntries,
+		sweepInterval: config.SweepInterval,
+		lastSweep:     time.Now(),
+		entries:       make(map[string]*list.Element),
+		lru:           list.New(),
+	}
+}
+
+// Get implements the CacheStore interface.
+func (s *MemoryStore) Get(key string) (*CachedResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	entry := elem.Value.(*memoryEntry)
+	if entry.expired(time.Now()) {
+		s.remove(elem)
+		return nil, ErrCacheMiss
+	}
+	s.lru.MoveToFront(elem)
+	return entry.value, nil
+}
+
+// Set implements the CacheStore interface.
+func (s *MemoryStore) Set(key string, value *CachedResponse, ttl time.Duration) error {
+	entry := &memoryEntry{key: key, value: value}
+	now := time.Now()
+	if ttl > 0 {
+		entry.expires = now.Add(ttl)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastSweep) >= s.sweepInterval {
+		s.sweep(now)
+	}
+	if elem, ok := s.entries[key]; ok {
+		elem.Value = entry
+		s.lru.MoveToFront(elem)
+		return nil
+	}
+	s.entries[key] = s.lru.PushFront(entry)
+	for len(s.entries) > s.maxEntries {
+		s.remove(s.lru.Back())
+	}
+	return nil
+}
+
+// Delete implements the CacheStore interface.
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	if elem, ok := s.entries[key]; ok {
+		s.remove(elem)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of entries, including the expired ones which were
+// not removed yet.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// sweep removes the expired entries.
+func (s *MemoryStore) sweep(now time.Time) {
+	s.lastSweep = now
+	for elem := s.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if elem.Value.(*memoryEntry).expired(now) {
+			s.remove(elem)
+		}
+		elem = next
+	}
+}
+
+func (s *MemoryStore) remove(elem *list.Element) {
+	s.lru.Remove(elem)
+	delete(s.entries, elem.Value.(*memoryEntry).key)
+}
+
+// RedisClient is the subset of a Redis client used by RedisStore. It is kept
+// minimal so that any Redis library can be adapted with a few lines of code.
+// Get must return ErrCacheMiss when the key does not exist.
+type RedisClient interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Del(ctx context.Context, key string) error
+}
+
+// RedisStore is a CacheStore backed by Redis.
+type RedisStore struct {
+	client RedisClient
+	prefix string
+}
+
+var _ CacheStore = (*RedisStore)(nil)
+
+// NewRedisStore returns a RedisStore which stores responses through client,
+// prepending prefix to every key.
+func NewRedisStore(client RedisClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Get implements the CacheStore interface.
+func (s *RedisStore) Get(key string) (*CachedResponse, error) {
+	data, err := s.client.Get(context.Background(), s.prefix+key)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, ErrCacheMiss
+	}
+	var resp CachedResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Set implements the CacheStore interface.
+func (s *RedisStore) Set(key string, value *CachedResponse, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(context.Background(), s.prefix+key, data, ttl)
+}
+
+// Delete implements the CacheStore interface.
+func (s *RedisStore) Delete(key string) error {
+	return s.client.Del(context.Background(), s.prefix+key)
+}
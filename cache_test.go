@@ -0,0 +1,345 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheServesStoredResponse(t *testing.T) {
+	var calls int32
+	router := New()
+	router.Use(Cache(CacheConfig{}))
+	router.GET("/users/:id", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+		c.Header("X-Custom", "yes")
+		c.String(http.StatusOK, "user "+c.Param("id"))
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/users/1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "user 1", w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/users/1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "user 1", w.Body.String())
+	assert.Equal(t, "yes", w.Header().Get("X-Custom"))
+	assert.Equal(t, "0", w.Header().Get("Age"))
+
+	w = PerformRequest(router, http.MethodGet, "/users/2")
+	assert.Equal(t, "user 2", w.Body.String())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestCacheRequestDirectives(t *testing.T) {
+	var calls int32
+	router := New()
+	router.Use(Cache(CacheConfig{}))
+	router.GET("/", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+		c.String(http.StatusOK, "ok")
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/", header{"Cache-Control", "only-if-cached"})
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+
+	PerformRequest(router, http.MethodGet, "/", header{"Cache-Control", "no-store"})
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	PerformRequest(router, http.MethodGet, "/", header{"Cache-Control", "no-cache"})
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	PerformRequest(router, http.MethodGet, "/", header{"Cache-Control", "max-age=60"})
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestCacheSkipsUncacheableResponses(t *testing.T) {
+	var calls int32
+	router := New()
+	router.Use(Cache(CacheConfig{}))
+	router.GET("/error", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+		c.String(http.StatusInternalServerError, "fail")
+	})
+	router.GET("/private", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+		c.Header("Cache-Control", "private")
+		c.String(http.StatusOK, "secret")
+	})
+	router.POST("/post", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+	})
+	router.GET("/login", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+		c.SetCookie("session", "s3cr3t", 0, "/", "", false, true)
+		c.String(http.StatusOK, "welcome")
+	})
+	router.GET("/any", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+		c.Header("Vary", "*")
+		c.String(http.StatusOK, "any")
+	})
+
+	for i := 0; i < 2; i++ {
+		PerformRequest(router, http.MethodGet, "/error")
+		PerformRequest(router, http.MethodGet, "/private")
+		PerformRequest(router, http.MethodPost, "/post")
+		w := PerformRequest(router, http.MethodGet, "/login")
+		assert.Contains(t, w.Header().Get("Set-Cookie"), "session=s3cr3t")
+		PerformRequest(router, http.MethodGet, "/any")
+	}
+	assert.Equal(t, int32(10), atomic.LoadInt32(&calls))
+}
+
+func TestCacheMaxBodySize(t *testing.T) {
+	var calls int32
+	router := New()
+	router.Use(Cache(CacheConfig{MaxBodySize: 8}))
+	router.GET("/:size", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+		size, _ := strconv.Atoi(c.Param("size"))
+		c.String(http.StatusOK, strings.Repeat("a", size/2))
+		c.String(http.StatusOK, strings.Repeat("b", size-size/2))
+	})
+
+	for i := 0; i < 2; i++ {
+		w := PerformRequest(router, http.MethodGet, "/8")
+		assert.Equal(t, "aaaabbbb", w.Body.String())
+		w = PerformRequest(router, http.MethodGet, "/10")
+		assert.Equal(t, "aaaaabbbbb", w.Body.String())
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestCacheResponseVary(t *testing.T) {
+	var calls int32
+	router := New()
+	router.Use(Cache(CacheConfig{}))
+	router.GET("/", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+		c.Header("Vary", "Accept-Encoding, accept-language")
+		c.String(http.StatusOK, c.GetHeader("Accept-Encoding")+"/"+c.GetHeader("Accept-Language"))
+	})
+
+	variants := [][]header{
+		{{"Accept-Encoding", "gzip"}},
+		{{"Accept-Encoding", "br"}},
+		{{"Accept-Encoding", "gzip"}, {"Accept-Language", "fr"}},
+	}
+	for i := 0; i < 2; i++ {
+		for _, headers := range variants {
+			w := PerformRequest(router, http.MethodGet, "/", headers...)
+			expected := headers[0].Value + "/"
+			if len(headers) > 1 {
+				expected += headers[1].Value
+			}
+			assert.Equal(t, expected, w.Body.String())
+		}
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestCacheVaryHeadersAndRouteTTL(t *testing.T) {
+	store := NewMemoryStore()
+	router := New()
+	router.Use(Cache(CacheConfig{
+		Store:       store,
+		VaryHeaders: []string{"Accept-Language"},
+		RouteTTL:    map[string]time.Duration{"/short": time.Nanosecond},
+	}))
+	router.GET("/lang", func(c *Context) {
+		c.String(http.StatusOK, c.GetHeader("Accept-Language"))
+	})
+	var calls int32
+	router.GET("/short", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/lang", header{"Accept-Language", "en"})
+	assert.Equal(t, "en", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "/lang", header{"Accept-Language", "fr"})
+	assert.Equal(t, "fr", w.Body.String())
+
+	PerformRequest(router, http.MethodGet, "/short")
+	time.Sleep(time.Millisecond)
+	PerformRequest(router, http.MethodGet, "/short")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestCacheBypassesAuthenticatedRequests(t *testing.T) {
+	var calls int32
+	router := New()
+	router.Use(Cache(CacheConfig{}))
+	router.GET("/me", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+		c.String(http.StatusOK, "user="+c.GetHeader("Authorization"))
+	})
+	router.GET("/public", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+		c.Header("Cache-Control", "public")
+		c.String(http.StatusOK, "shared")
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/me", header{"Authorization", "alice"})
+	assert.Equal(t, "user=alice", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "/me", header{"Authorization", "bob"})
+	assert.Equal(t, "user=bob", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "/me")
+	assert.Equal(t, "user=", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "/me", header{"Cookie", "session=bob"})
+	assert.Equal(t, "user=", w.Body.String())
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+
+	PerformRequest(router, http.MethodGet, "/public", header{"Authorization", "alice"})
+	w = PerformRequest(router, http.MethodGet, "/public", header{"Authorization", "bob"})
+	assert.Equal(t, "shared", w.Body.String())
+	assert.Equal(t, int32(5), atomic.LoadInt32(&calls))
+}
+
+func TestCacheKeyIncludesHost(t *testing.T) {
+	router := New()
+	router.Use(Cache(CacheConfig{}))
+	router.GET("/", func(c *Context) {
+		c.String(http.StatusOK, c.Request.Host)
+	})
+
+	for _, host := range []string{"a.example.com", "b.example.com"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Host = host
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, host, w.Body.String())
+	}
+}
+
+func TestCacheCollapsesConcurrentMisses(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	router := New()
+	router.Use(Cache(CacheConfig{}))
+	router.GET("/slow", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		c.String(http.StatusOK, "done")
+	})
+
+	var wg sync.WaitGroup
+	bodies := make([]string, 5)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bodies[i] = PerformRequest(router, http.MethodGet, "/slow").Body.String()
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, body := range bodies {
+		assert.Equal(t, "done", body)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	_, err := store.Get("missing")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+
+	resp := &CachedResponse{Status: http.StatusOK, Body: []byte("body")}
+	assert.NoError(t, store.Set("key", resp, 0))
+	got, err := store.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, resp, got)
+
+	assert.NoError(t, store.Delete("key"))
+	_, err = store.Get("key")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+
+	assert.NoError(t, store.Set("expired", resp, time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	_, err = store.Get("expired")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+}
+
+func TestMemoryStoreLimits(t *testing.T) {
+	store := NewMemoryStoreWithConfig(MemoryStoreConfig{MaxEntries: 2, SweepInterval: time.Millisecond})
+	resp := &CachedResponse{Status: http.StatusOK}
+
+	assert.NoError(t, store.Set("a", resp, 0))
+	assert.NoError(t, store.Set("b", resp, 0))
+	_, _ = store.Get("a")
+	assert.NoError(t, store.Set("c", resp, 0))
+	assert.Equal(t, 2, store.Len())
+	_, err := store.Get("b")
+	assert.ErrorIs(t, err, ErrCacheMiss, "the least recently used entry is evicted")
+	_, err = store.Get("a")
+	assert.NoError(t, err)
+
+	// the expired entries are removed by Set, even if they are not read.
+	assert.NoError(t, store.Set("a", resp, time.Nanosecond))
+	time.Sleep(2 * time.Millisecond)
+	assert.NoError(t, store.Set("c", resp, 0))
+	assert.Equal(t, 1, store.Len())
+}
+
+type fakeRedisClient struct {
+	data map[string][]byte
+	ttls map[string]time.Duration
+}
+
+func (f *fakeRedisClient) Get(_ context.Context, key string) ([]byte, error) {
+	if v, ok := f.data[key]; ok {
+		return v, nil
+	}
+	return nil, ErrCacheMiss
+}
+
+func (f *fakeRedisClient) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	f.data[key] = value
+	f.ttls[key] = ttl
+	return nil
+}
+
+func (f *fakeRedisClient) Del(_ context.Context, key string) error {
+	delete(f.data, key)
+	return nil
+}
+
+func TestRedisStore(t *testing.T) {
+	client := &fakeRedisClient{data: map[string][]byte{}, ttls: map[string]time.Duration{}}
+	store := NewRedisStore(client, "gin:")
+
+	resp := &CachedResponse{
+		Status: http.StatusOK,
+		Header: http.Header{"Content-Type": {"text/plain"}},
+		Body:   []byte("hello"),
+	}
+	assert.NoError(t, store.Set("key", resp, time.Minute))
+	assert.Equal(t, time.Minute, client.ttls["gin:key"])
+
+	got, err := store.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Status, got.Status)
+	assert.Equal(t, resp.Header, got.Header)
+	assert.Equal(t, resp.Body, got.Body)
+
+	assert.NoError(t, store.Delete("key"))
+	_, err = store.Get("key")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+}
@@ -0,0 +1,167 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// certReloadInterval is the minimum interval between two checks of the
+// modification time of the certificate files.
+var certReloadInterval = time.Second
+
+// certStore holds the certificates added with AddCertificate and AddCertificateFunc.
+type certStore struct {
+	mu      sync.RWMutex
+	entries map[string]*certEntry
+}
+
+type certEntry struct {
+	certFile, keyFile string
+	fn                func(*tls.ClientHelloInfo) (*tls.Certificate, error)
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+// AddCertificate adds the certificate served by the TLS servers of the engine
+// to the clients requesting a host (SNI) matching hostPattern, which is either
+// a host name, a wildcard like "*.example.com" matching one label, or "*" for
+// the clients without a matching certificate. The files are reloaded when they
+// change, to renew the certificate without restarting. Adding a pattern again
+// replaces its certificate.
+//
+//	router.AddCertificate("example.com", "example.pem", "example.key")
+//	router.AddCertificate("*.example.org", "wildcard.pem", "wildcard.key")
+//	router.RunTLS(":443", "", "")
+func (engine *Engine) AddCertificate(hostPattern, certFile, keyFile string) error {
+	entry := &certEntry{certFile: certFile, keyFile: keyFile}
+	if err := entry.load(); err != nil {
+		return err
+	}
+	return engine.certificates.add(hostPattern, entry)
+}
+
+// AddCertificateFunc is AddCertificate with a function returning the certificate
+// for each handshake, e.g. from a secret store or an ACME client. A nil
+// certificate falls back to the other patterns.
+func (engine *Engine) AddCertificateFunc(hostPattern string, fn func(*tls.ClientHelloInfo) (*tls.Certificate, error)) error {
+	assert1(fn != nil, "AddCertificateFunc requires a function")
+	return engine.certificates.add(hostPattern, &certEntry{fn: fn})
+}
+
+// GetCertificate returns the certificate added with AddCertificate or
+// AddCertificateFunc for the host requested by the client, or nil. It is set
+// as tls.Config.GetCertificate by the TLS Run methods, and can be used with
+// servers configured outside of gin.
+func (engine *Engine) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	host := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+	candidates := []string{host}
+	if i := strings.IndexByte(host, '.'); i > 0 {
+		candidates = append(candidates, "*"+host[i:])
+	}
+	candidates = append(candidates, "*")
+
+	s := &engine.certificates
+	for _, pattern := range candidates {
+		s.mu.RLock()
+		entry := s.entries[pattern]
+		s.mu.RUnlock()
+		if entry == nil {
+			continue
+		}
+		cert, err := entry.certificate(hello)
+		if cert != nil || err != nil {
+			return cert, err
+		}
+	}
+	return nil, nil
+}
+
+// tlsConfig sets the certificates added to the engine on config.
+func (engine *Engine) tlsConfig(config *tls.Config) *tls.Config {
+	s := &engine.certificates
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.entries) == 0 {
+		return config
+	}
+	if config == nil {
+		config = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	config.GetCertificate = engine.GetCertificate
+	return config
+}
+
+func (s *certStore) add(pattern string, entry *certEntry) error {
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+	if pattern == "" || strings.Contains(pattern, "*") &&
+		pattern != "*" && (!strings.HasPrefix(pattern, "*.") || strings.Contains(pattern[1:], "*")) {
+		return fmt.Errorf("gin: invalid certificate host pattern %q", pattern)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		s.entries = make(map[string]*certEntry)
+	}
+	s.entries[pattern] = entry
+	return nil
+}
+
+func (e *certEntry) certificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if e.fn != nil {
+		return e.fn(hello)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if time.Since(e.checked) >= certReloadInterval {
+		if modTime, err := certModTime(e.certFile, e.keyFile); err == nil && modTime.After(e.modTime) {
+			// keep serving the previous certificate until both files are updated
+			debugPrintError(e.load())
+		}
+	}
+	return e.cert, nil
+}
+
+// load loads the files of e; e.mu must be held if e is shared.
+func (e *certEntry) load() error {
+	e.checked = time.Now()
+	modTime, err := certModTime(e.certFile, e.keyFile)
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(e.certFile, e.keyFile)
+	if err != nil {
+		return err
+	}
+	e.cert = &cert
+	e.modTime = modTime
+	return nil
+}
+
+// certModTime returns the latest modification time of the files.
+func certModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	var errs []error
+	for _, file := range files {
+		fi, err := os.Stat(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest, errors.Join(errs...)
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestCert(t *testing.T, ca *testCA, dir, name string) (string, string) {
+	cert := ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: name}, DNSNames: []string{name}})
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	require.NoError(t, err)
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0o600))
+	return certFile, keyFile
+}
+
+func servedName(t *testing.T, router *Engine, serverName string) string {
+	cert, err := router.GetCertificate(&tls.ClientHelloInfo{ServerName: serverName})
+	require.NoError(t, err)
+	if cert == nil {
+		return ""
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func TestEngineAddCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	router := New()
+	assert.Nil(t, router.tlsConfig(nil))
+
+	certFile, keyFile := writeTestCert(t, ca, t.TempDir(), "example.com")
+	require.NoError(t, router.AddCertificate("Example.com", certFile, keyFile))
+	certFile, keyFile = writeTestCert(t, ca, t.TempDir(), "*.example.org")
+	require.NoError(t, router.AddCertificate("*.example.org", certFile, keyFile))
+
+	assert.Equal(t, "example.com", servedName(t, router, "example.com"))
+	assert.Equal(t, "example.com", servedName(t, router, "EXAMPLE.com."))
+	assert.Equal(t, "*.example.org", servedName(t, router, "www.example.org"))
+	assert.Equal(t, "", servedName(t, router, "a.b.example.org"))
+	assert.Equal(t, "", servedName(t, router, "example.net"))
+
+	require.NoError(t, router.AddCertificateFunc("*", func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		cert := ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "default"}})
+		return &cert, nil
+	}))
+	assert.Equal(t, "default", servedName(t, router, "example.net"))
+	assert.Equal(t, "default", servedName(t, router, ""))
+
+	config := router.tlsConfig(nil)
+	require.NotNil(t, config)
+	assert.NotNil(t, config.GetCertificate)
+
+	assert.Error(t, router.AddCertificate("www.example.com", "missing.pem", "missing.key"))
+	for _, pattern := range []string{"", "*.*.example.com", "www.*.com", "**"} {
+		assert.Error(t, router.AddCertificateFunc(pattern, router.GetCertificate), pattern)
+	}
+}
+
+func TestEngineAddCertificateReload(t *testing.T) {
+	defer func(interval time.Duration) { certReloadInterval = interval }(certReloadInterval)
+	certReloadInterval = 0
+
+	ca := newTestCA(t)
+	router := New()
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, ca, dir, "old.example.com")
+	require.NoError(t, router.AddCertificate("*", certFile, keyFile))
+	assert.Equal(t, "old.example.com", servedName(t, router, "example.com"))
+
+	writeTestCert(t, ca, dir, "new.example.com")
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+	assert.Equal(t, "new.example.com", servedName(t, router, "example.com"))
+
+	// a broken update keeps the previous certificate
+	require.NoError(t, os.WriteFile(keyFile, []byte("broken"), 0o600))
+	later = later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+	assert.Equal(t, "new.example.com", servedName(t, router, "example.com"))
+}
@@ -0,0 +1,221 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a circuit of the CircuitBreaker middleware.
+type CircuitState string
+
+// The states of a circuit.
+const (
+	// CircuitClosed is the normal state: the requests are handled.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen is the state of a failing circuit: the requests are answered
+	// by the fallback handler, until the open timeout expires.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen is the state following the open timeout: a few probe
+	// requests are handled to check whether the upstream has recovered.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitBreakerConfig defines the config for CircuitBreaker middleware.
+type CircuitBreakerConfig struct {
+	// Class returns the class of the route of a request; each class has its own
+	// circuit, e.g. one per upstream service.
+	// Optional. Default value is Context.FullPath, one circuit per route.
+	Class func(c *Context) string
+
+	// Window is the period over which the failure rate is measured.
+	// Optional. Default value is 10 seconds.
+	Window time.Duration
+
+	// MinRequests is the number of requests in the window below which the circuit
+	// does not open, whatever the failure rate.
+	// Optional. Default value is 20.
+	MinRequests int
+
+	// FailureRate is the ratio of failed requests in the window, between 0 and 1,
+	// from which the circuit opens.
+	// Optional. Default value is 0.5.
+	FailureRate float64
+
+	// SlowThreshold is the duration from which a request counts as a failure.
+	// Optional. Default value is 0, the latency is not considered.
+	SlowThreshold time.Duration
+
+	// IsFailure reports whether a handled request failed.
+	// Optional. Default value reports the 5xx responses.
+	IsFailure func(c *Context) bool
+
+	// OpenTimeout is the duration a circuit stays open before probing the upstream.
+	// Optional. Default value is 30 seconds.
+	OpenTimeout time.Duration
+
+	// HalfOpenRequests is the number of probe requests handled when half-open,
+	// which must all succeed to close the circuit.
+	// Optional. Default value is 1.
+	HalfOpenRequests int
+
+	// Fallback answers the requests while the circuit is open.
+	// Optional. Default value aborts with 503 Service Unavailable.
+	Fallback HandlerFunc
+
+	// OnStateChange is called when a circuit changes state, e.g. to update metrics.
+	// Optional.
+	OnStateChange func(class string, from, to CircuitState)
+}
+
+// CircuitBreaker returns a middleware which stops calling the handlers of a
+// class of routes once too many of their requests fail or are slow, so that
+// gateways shed the load from failing backends instead of piling up requests.
+// After OpenTimeout, a few probe requests are let through: the circuit closes
+// if they succeed and opens again otherwise.
+func CircuitBreaker(config CircuitBreakerConfig) HandlerFunc {
+	if config.Class == nil {
+		config.Class = (*Context).FullPath
+	}
+	if config.Window <= 0 {
+		config.Window = 10 * time.Second
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = 20
+	}
+	if config.FailureRate <= 0 {
+		config.FailureRate = 0.5
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = func(c *Context) bool {
+			return c.Writer.Status() >= http.StatusInternalServerError
+		}
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = 1
+	}
+	if config.Fallback == nil {
+		config.Fallback = func(c *Context) {
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+		}
+	}
+
+	var mu sync.Mutex
+	circuits := make(map[string]*circuit)
+
+	return func(c *Context) {
+		class := config.Class(c)
+		mu.Lock()
+		cb, ok := circuits[class]
+		if !ok {
+			cb = &circuit{class: class, config: &config, state: CircuitClosed, windowStart: time.Now()}
+			circuits[class] = cb
+		}
+		mu.Unlock()
+
+		if !cb.allow() {
+			config.Fallback(c)
+			c.Abort()
+			return
+		}
+
+		start := time.Now()
+		completed := false
+		defer func() {
+			if !completed {
+				// the handlers panicked.
+				cb.record(true)
+			}
+		}()
+		c.Next()
+		completed = true
+		slow := config.SlowThreshold > 0 && time.Since(start) >= config.SlowThreshold
+		cb.record(slow || config.IsFailure(c))
+	}
+}
+
+// circuit is the breaker of a class of routes.
+type circuit struct {
+	class  string
+	config *CircuitBreakerConfig
+
+	mu          sync.Mutex
+	state       CircuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int
+	successes   int
+}
+
+// allow reports whether a request can be handled.
+func (cb *circuit) allow() bool {
+	cb.mu.Lock()
+	from := cb.state
+	allowed := false
+	switch cb.state {
+	case CircuitClosed:
+		allowed = true
+	case CircuitOpen:
+		if time.Since(cb.openedAt) >= cb.config.OpenTimeout {
+			cb.state, cb.probes, cb.successes = CircuitHalfOpen, 1, 0
+			allowed = true
+		}
+	case CircuitHalfOpen:
+		if cb.probes < cb.config.HalfOpenRequests {
+			cb.probes++
+			allowed = true
+		}
+	}
+	to := cb.state
+	cb.mu.Unlock()
+	cb.notify(from, to)
+	return allowed
+}
+
+// record records the outcome of a handled request.
+func (cb *circuit) record(failed bool) {
+	cb.mu.Lock()
+	from := cb.state
+	now := time.Now()
+	switch cb.state {
+	case CircuitClosed:
+		if now.Sub(cb.windowStart) >= cb.config.Window {
+			cb.windowStart, cb.requests, cb.failures = now, 0, 0
+		}
+		cb.requests++
+		if failed {
+			cb.failures++
+		}
+		if cb.requests >= cb.config.MinRequests &&
+			float64(cb.failures)/float64(cb.requests) >= cb.config.FailureRate {
+			cb.state, cb.openedAt = CircuitOpen, now
+		}
+	case CircuitHalfOpen:
+		if failed {
+			cb.state, cb.openedAt = CircuitOpen, now
+			break
+		}
+		cb.successes++
+		if cb.successes >= cb.config.HalfOpenRequests {
+			cb.state, cb.windowStart, cb.requests, cb.failures = CircuitClosed, now, 0, 0
+		}
+	}
+	to := cb.state
+	cb.mu.Unlock()
+	cb.notify(from, to)
+}
+
+func (cb *circuit) notify(from, to CircuitState) {
+	if from != to && cb.config.OnStateChange != nil {
+		cb.config.OnStateChange(cb.class, from, to)
+	}
+}
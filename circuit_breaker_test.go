@@ -0,0 +1,99 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var mu sync.Mutex
+	var changes []string
+	failing := true
+	router := New()
+	router.Use(CircuitBreaker(CircuitBreakerConfig{
+		MinRequests: 4,
+		OpenTimeout: 20 * time.Millisecond,
+		Fallback: func(c *Context) {
+			c.String(http.StatusServiceUnavailable, "fallback")
+		},
+		OnStateChange: func(class string, from, to CircuitState) {
+			mu.Lock()
+			changes = append(changes, class+" "+string(from)+"->"+string(to))
+			mu.Unlock()
+		},
+	}))
+	router.GET("/upstream", func(c *Context) {
+		if failing {
+			c.Status(http.StatusBadGateway)
+			return
+		}
+		c.String(http.StatusOK, "ok")
+	})
+	router.GET("/other", func(c *Context) { c.String(http.StatusOK, "other") })
+
+	// 2 failures out of 4 requests open the circuit.
+	for _, code := range []int{http.StatusBadGateway, http.StatusBadGateway} {
+		w := PerformRequest(router, http.MethodGet, "/upstream")
+		assert.Equal(t, code, w.Code)
+		PerformRequest(router, http.MethodGet, "/other")
+	}
+	failing = false
+	PerformRequest(router, http.MethodGet, "/upstream")
+	PerformRequest(router, http.MethodGet, "/upstream")
+	w := PerformRequest(router, http.MethodGet, "/upstream")
+	assert.Equal(t, "fallback", w.Body.String())
+	// other classes are not affected.
+	w = PerformRequest(router, http.MethodGet, "/other")
+	assert.Equal(t, "other", w.Body.String())
+
+	// a failed probe opens the circuit again.
+	failing = true
+	time.Sleep(30 * time.Millisecond)
+	w = PerformRequest(router, http.MethodGet, "/upstream")
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	w = PerformRequest(router, http.MethodGet, "/upstream")
+	assert.Equal(t, "fallback", w.Body.String())
+
+	// a successful probe closes it.
+	failing = false
+	time.Sleep(30 * time.Millisecond)
+	w = PerformRequest(router, http.MethodGet, "/upstream")
+	assert.Equal(t, "ok", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "/upstream")
+	assert.Equal(t, "ok", w.Body.String())
+
+	assert.Equal(t, []string{
+		"/upstream closed->open",
+		"/upstream open->half-open",
+		"/upstream half-open->open",
+		"/upstream open->half-open",
+		"/upstream half-open->closed",
+	}, changes)
+}
+
+func TestCircuitBreakerSlowAndPanics(t *testing.T) {
+	router := New()
+	router.Use(Recovery(), CircuitBreaker(CircuitBreakerConfig{
+		Class:         func(c *Context) string { return "upstream" },
+		MinRequests:   2,
+		FailureRate:   1,
+		SlowThreshold: 5 * time.Millisecond,
+	}))
+	router.GET("/slow", func(c *Context) { time.Sleep(10 * time.Millisecond) })
+	router.GET("/panic", func(c *Context) { panic("upstream down") })
+
+	w := PerformRequest(router, http.MethodGet, "/slow")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = PerformRequest(router, http.MethodGet, "/panic")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	w = PerformRequest(router, http.MethodGet, "/slow")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
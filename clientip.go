@@ -0,0 +1,277 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net"
+	"path"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// More trusted platforms, see Engine.TrustedPlatform.
+const (
+	// PlatformFastly when using the Fastly CDN. Trust Fastly-Client-IP for determining
+	// the client's IP
+	PlatformFastly = "Fastly-Client-IP"
+	// PlatformTrueClientIP when using Akamai or Cloudflare Enterprise. Trust True-Client-IP
+	// for determining the client's IP
+	PlatformTrueClientIP = "True-Client-IP"
+)
+
+// ClientIPStrategy resolves the client IP of a request, or returns false to let
+// the next strategy (and eventually the default algorithm) try. See
+// Engine.ClientIPStrategies.
+type ClientIPStrategy func(c *Context) (ip string, ok bool)
+
+// ClientIPFromHeader returns a strategy which reads the client IP from a header
+// holding a single IP, set by a reverse proxy (e.g. X-Real-IP or
+// CF-Connecting-IP). Unlike TrustedPlatform, the header is only trusted when
+// the request comes from a trusted proxy, see Engine.SetTrustedProxies.
+func ClientIPFromHeader(name string) ClientIPStrategy {
+	return func(c *Context) (string, bool) {
+		if !c.fromTrustedProxy() {
+			return "", false
+		}
+		ip := net.ParseIP(strings.TrimSpace(c.requestHeader(name)))
+		if ip == nil {
+			return "", false
+		}
+		return ip.String(), true
+	}
+}
+
+// ClientIPFromForwarded returns a strategy which reads the client IP from the
+// standard Forwarded header (RFC 7239). As for X-Forwarded-For, the header is
+// only trusted when the request comes from a trusted proxy, and the client is
+// the last hop which is not a trusted proxy. The header is also read by the
+// default algorithm when it is listed in Engine.RemoteIPHeaders.
+func ClientIPFromForwarded() ClientIPStrategy {
+	return func(c *Context) (string, bool) {
+		return c.forwardedClientIP()
+	}
+}
+
+func (c *Context) forwardedClientIP() (string, bool) {
+	if !c.fromTrustedProxy() {
+		return "", false
+	}
+	hops := forwardedFor(c.Request.Header.Values("Forwarded"))
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(hops[i])
+		if ip == nil {
+			// unknown or obfuscated identifier
+			return "", false
+		}
+		if i == 0 || !c.engine.isTrustedProxy(ip) {
+			return ip.String(), true
+		}
+	}
+	return "", false
+}
+
+func (c *Context) fromTrustedProxy() bool {
+	remoteIP := net.ParseIP(c.RemoteIP())
+	return remoteIP != nil && c.engine.isTrustedProxy(remoteIP)
+}
+
+// forwardedElement holds the parameters of an element of a Forwarded header.
+type forwardedElement struct {
+	// node is the node of the for parameter, without quotes, brackets and port.
+	node  string
+	proto string
+	host  string
+}
+
+// parseForwarded returns the elements of Forwarded headers.
+func parseForwarded(headers []string) []forwardedElement {
+	var elements []forwardedElement
+	for _, header := range headers {
+		for _, element := range splitForwarded(header, ',') {
+			var e forwardedElement
+			for _, pair := range splitForwarded(element, ';') {
+				key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok {
+					continue
+				}
+				switch strings.ToLower(key) {
+				case "for":
+					e.node = forwardedNode(value)
+				case "proto":
+					e.proto = strings.ToLower(forwardedValue(value))
+				case "host":
+					e.host = forwardedValue(value)
+				}
+			}
+			elements = append(elements, e)
+		}
+	}
+	return elements
+}
+
+// forwardedFor returns the node of the for parameter of each element of
+// Forwarded headers, without quotes, brackets and port.
+func forwardedFor(headers []string) []string {
+	var hops []string
+	for _, element := range parseForwarded(headers) {
+		if element.node != "" {
+			hops = append(hops, element.node)
+		}
+	}
+	return hops
+}
+
+// trustedForwarded returns the element of the Forwarded headers of the request
+// which was added by the outermost trusted proxy: the one whose for parameter
+// is the client, see ClientIPFromForwarded. It returns false when the request
+// does not come from a trusted proxy, or has no Forwarded header.
+func (c *Context) trustedForwarded() (forwardedElement, bool) {
+	if !c.fromTrustedProxy() {
+		return forwardedElement{}, false
+	}
+	elements := parseForwarded(c.Request.Header.Values("Forwarded"))
+	for i := len(elements) - 1; i >= 0; i-- {
+		ip := net.ParseIP(elements[i].node)
+		if i == 0 || ip == nil || !c.engine.isTrustedProxy(ip) {
+			return elements[i], true
+		}
+	}
+	return forwardedElement{}, false
+}
+
+// forwardedValue strips the quotes of a value, unescaping the quoted pairs.
+func forwardedValue(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return value
+	}
+	value = value[1 : len(value)-1]
+	if !strings.Contains(value, "\\") {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// Scheme returns the scheme of the request as sent by the client, "https" or
+// "http": the proto of the Forwarded header, or the X-Forwarded-Proto header,
+// set by a trusted proxy (see Engine.SetTrustedProxies), or else whether the
+// request was received with TLS.
+func (c *Context) Scheme() string {
+	proto := ""
+	if element, ok := c.trustedForwarded(); ok {
+		proto = element.proto
+	}
+	if proto == "" && c.fromTrustedProxy() {
+		proto, _, _ = strings.Cut(c.requestHeader("X-Forwarded-Proto"), ",")
+		proto = strings.ToLower(strings.TrimSpace(proto))
+	}
+	if proto == "https" || proto == "http" {
+		return proto
+	}
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// ForwardedHost returns the host requested by the client, as given by the host
+// of the Forwarded header, or the X-Forwarded-Host header, set by a trusted
+// proxy (see Engine.SetTrustedProxies), or "" when there is none.
+func (c *Context) ForwardedHost() string {
+	host := ""
+	if element, ok := c.trustedForwarded(); ok {
+		host = element.host
+	}
+	if host == "" && c.fromTrustedProxy() {
+		host, _, _ = strings.Cut(c.requestHeader("X-Forwarded-Host"), ",")
+		host = strings.TrimSpace(host)
+	}
+	if !httpguts.ValidHostHeader(host) {
+		return ""
+	}
+	return host
+}
+
+// Host returns the host requested by the client, with the port if any: the
+// forwarded one (see ForwardedHost), or else the Host header of the request.
+func (c *Context) Host() string {
+	if host := c.ForwardedHost(); host != "" {
+		return host
+	}
+	return c.Request.Host
+}
+
+// BaseURL returns the URL of the application as requested by the client,
+// without trailing slash, e.g. "https://example.com/api", for generating
+// absolute URLs in emails, Location headers or API documents:
+//
+//	link := c.BaseURL() + "/users/" + id
+//
+// It is made of Scheme, Host and the X-Forwarded-Prefix header set by a trusted
+// proxy serving the application under a path.
+func (c *Context) BaseURL() string {
+	return c.Scheme() + "://" + c.Host() + c.forwardedPrefix()
+}
+
+// forwardedPrefix returns the cleaned X-Forwarded-Prefix header set by a trusted
+// proxy, without trailing slash, or "".
+func (c *Context) forwardedPrefix() string {
+	prefix := c.requestHeader("X-Forwarded-Prefix")
+	if prefix == "" || !c.fromTrustedProxy() {
+		return ""
+	}
+	prefix = regSafePrefix.ReplaceAllString(prefix, "")
+	prefix = path.Clean("/" + prefix)
+	if prefix == "/" {
+		return ""
+	}
+	return prefix
+}
+
+// forwardedNode strips the quotes and port of a node: "[2001:db8::1]:4711" or 192.0.2.1:80.
+func forwardedNode(value string) string {
+	value = strings.Trim(strings.TrimSpace(value), `"`)
+	if strings.HasPrefix(value, "[") {
+		if end := strings.IndexByte(value, ']'); end > 0 {
+			return value[1:end]
+		}
+		return value
+	}
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		return host
+	}
+	return value
+}
+
+// splitForwarded splits s on sep, ignoring the separators in quoted strings.
+func splitForwarded(s string, sep byte) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case '\\':
+			if quoted {
+				i++
+			}
+		case sep:
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
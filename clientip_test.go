@@ -0,0 +1,158 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForwardedFor(t *testing.T) {
+	hops := forwardedFor([]string{
+		`for=192.0.2.60;proto=http;by=203.0.113.43, For="[2001:db8:cafe::17]:4711"`,
+		`for="198.51.100.17:80";host="a,b", for=unknown`,
+	})
+	assert.Equal(t, []string{"192.0.2.60", "2001:db8:cafe::17", "198.51.100.17", "unknown"}, hops)
+}
+
+func TestClientIPStrategies(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.RemoteAddr = "10.0.0.1:1234"
+	c.Request.Header.Set("Forwarded", `for=192.0.2.60, for="[2001:db8::1]", for=10.0.0.2`)
+	c.Request.Header.Set("X-Forwarded-For", "203.0.113.1")
+	c.Request.Header.Set("X-Client", "198.51.100.7")
+	_ = c.engine.SetTrustedProxies([]string{"10.0.0.0/8"})
+
+	c.engine.ClientIPStrategies = []ClientIPStrategy{ClientIPFromForwarded()}
+	assert.Equal(t, "2001:db8::1", c.ClientIP())
+
+	c.engine.ClientIPStrategies = []ClientIPStrategy{ClientIPFromHeader("X-Missing"), ClientIPFromHeader("X-Client")}
+	assert.Equal(t, "198.51.100.7", c.ClientIP())
+
+	// obfuscated identifiers are not trusted: fallback to X-Forwarded-For.
+	c.Request.Header.Set("Forwarded", `for=_hidden`)
+	c.engine.ClientIPStrategies = []ClientIPStrategy{ClientIPFromForwarded()}
+	assert.Equal(t, "203.0.113.1", c.ClientIP())
+
+	// headers of untrusted clients are ignored.
+	c.Request.RemoteAddr = "192.0.2.200:1234"
+	c.engine.ClientIPStrategies = []ClientIPStrategy{ClientIPFromForwarded(), ClientIPFromHeader("X-Client")}
+	assert.Equal(t, "192.0.2.200", c.ClientIP())
+
+	c.engine.TrustedPlatform = PlatformFastly
+	c.Request.Header.Set("Fastly-Client-IP", "198.51.100.8")
+	assert.Equal(t, "198.51.100.8", c.ClientIP())
+}
+
+func TestParseForwarded(t *testing.T) {
+	elements := parseForwarded([]string{
+		`for=192.0.2.60;proto=HTTPS;host="example.com:8443", for=10.0.0.2;host=internal`,
+		`host="a\"b";by=10.0.0.3`,
+	})
+	assert.Equal(t, []forwardedElement{
+		{node: "192.0.2.60", proto: "https", host: "example.com:8443"},
+		{node: "10.0.0.2", host: "internal"},
+		{host: `a"b`},
+	}, elements)
+}
+
+func TestClientIPForwarded(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.RemoteAddr = "10.0.0.1:1234"
+	c.Request.Header.Set("Forwarded", `for=192.0.2.60, for="[2001:db8::1]:4711", for=10.0.0.2`)
+	_ = c.engine.SetTrustedProxies([]string{"10.0.0.0/8"})
+	// Forwarded is not read by default.
+	assert.Equal(t, "10.0.0.1", c.ClientIP())
+	c.engine.RemoteIPHeaders = []string{"X-Forwarded-For", "Forwarded"}
+	assert.Equal(t, "2001:db8::1", c.ClientIP())
+
+	// X-Forwarded-For comes first.
+	c.Request.Header.Set("X-Forwarded-For", "203.0.113.1")
+	assert.Equal(t, "203.0.113.1", c.ClientIP())
+
+	c.engine.RemoteIPHeaders = []string{"forwarded", "X-Forwarded-For"}
+	assert.Equal(t, "2001:db8::1", c.ClientIP())
+	c.Request.Header.Set("Forwarded", `for=unknown`)
+	assert.Equal(t, "203.0.113.1", c.ClientIP())
+
+	c.Request.RemoteAddr = "192.0.2.200:1234"
+	c.Request.Header.Set("Forwarded", `for=192.0.2.60`)
+	assert.Equal(t, "192.0.2.200", c.ClientIP())
+}
+
+func TestContextSchemeAndForwardedHost(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Host = "internal:8080"
+	c.Request.RemoteAddr = "10.0.0.1:1234"
+	_ = c.engine.SetTrustedProxies([]string{"10.0.0.0/8"})
+	assert.Equal(t, "http", c.Scheme())
+	assert.Empty(t, c.ForwardedHost())
+
+	c.Request.TLS = &tls.ConnectionState{}
+	assert.Equal(t, "https", c.Scheme())
+	c.Request.TLS = nil
+
+	c.Request.Header.Set("X-Forwarded-Proto", "HTTPS, http")
+	c.Request.Header.Set("X-Forwarded-Host", "example.com, internal")
+	assert.Equal(t, "https", c.Scheme())
+	assert.Equal(t, "example.com", c.ForwardedHost())
+
+	// the element of the outermost trusted proxy wins over X-Forwarded-*.
+	c.Request.Header.Set("Forwarded", `for=198.51.100.1;proto=http;host=spoofed.example, `+
+		`for=203.0.113.7;proto=https;host="shop.example", for=10.0.0.2;proto=http;host=internal`)
+	assert.Equal(t, "https", c.Scheme())
+	assert.Equal(t, "shop.example", c.ForwardedHost())
+
+	// invalid values are ignored.
+	c.Request.Header.Set("Forwarded", `for=203.0.113.7;proto=gopher;host="bad host"`)
+	assert.Equal(t, "http", c.Scheme())
+	assert.Empty(t, c.ForwardedHost())
+
+	// the headers of untrusted clients are ignored.
+	c.Request.Header.Set("Forwarded", `for=203.0.113.7;proto=https;host=shop.example`)
+	c.Request.RemoteAddr = "192.0.2.200:1234"
+	assert.Equal(t, "http", c.Scheme())
+	assert.Empty(t, c.ForwardedHost())
+}
+
+func TestContextHostAndBaseURL(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodGet, "/users", nil)
+	c.Request.Host = "internal:8080"
+	c.Request.RemoteAddr = "10.0.0.1:1234"
+	_ = c.engine.SetTrustedProxies([]string{"10.0.0.0/8"})
+	assert.Equal(t, "internal:8080", c.Host())
+	assert.Equal(t, "http://internal:8080", c.BaseURL())
+
+	c.Request.Header.Set("Forwarded", `for=203.0.113.7;proto=https;host="shop.example"`)
+	c.Request.Header.Set("X-Forwarded-Prefix", "/api/")
+	assert.Equal(t, "shop.example", c.Host())
+	assert.Equal(t, "https://shop.example/api", c.BaseURL())
+
+	prefixes := map[string]string{
+		"/":              "",
+		"v1//":           "/v1",
+		"/a/../b":        "/a/b",
+		"/x<script>/y":   "/xscript/y",
+		"//evil.example": "/evilexample",
+	}
+	for prefix, expected := range prefixes {
+		c.Request.Header.Set("X-Forwarded-Prefix", prefix)
+		assert.Equal(t, "https://shop.example"+expected, c.BaseURL(), prefix)
+	}
+
+	// the headers of untrusted clients are ignored.
+	c.Request.Header.Set("X-Forwarded-Prefix", "/api")
+	c.Request.RemoteAddr = "192.0.2.200:1234"
+	assert.Equal(t, "internal:8080", c.Host())
+	assert.Equal(t, "http://internal:8080", c.BaseURL())
+}
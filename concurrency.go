@@ -0,0 +1,204 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConcurrencyOptions defines the options of the MaxConcurrent middleware.
+type ConcurrencyOptions struct {
+	// PerRoute is the maximum number of requests handled concurrently by each
+	// route (as returned by Context.FullPath).
+	// Optional. Default value is 0, no limit per route.
+	PerRoute int
+
+	// RouteLimits overrides PerRoute for the given routes.
+	// Optional.
+	RouteLimits map[string]int
+
+	// QueueSize is the number of requests which can wait for a slot when the
+	// limit is reached; the next ones are shed at once.
+	// Optional. Default value is 0, the requests are shed without waiting.
+	QueueSize int
+
+	// QueueTimeout is the maximum time a request waits in the queue.
+	// Optional. Default value is 1 second.
+	QueueTimeout time.Duration
+
+	// RetryAfter is sent in the Retry-After header of the shed requests.
+	// Optional. Default value is 1 second.
+	RetryAfter time.Duration
+
+	// ShedHandler answers the shed requests.
+	// Optional. Default value aborts with 503 Service Unavailable.
+	ShedHandler HandlerFunc
+}
+
+// ConcurrencyStats is a snapshot of the state of a ConcurrencyLimiter.
+type ConcurrencyStats struct {
+	// InFlight is the number of requests being handled.
+	InFlight int64 `json:"in_flight"`
+	// Queued is the number of requests waiting for a slot.
+	Queued int64 `json:"queued"`
+	// Shed is the number of requests shed since the creation of the limiter.
+	Shed uint64 `json:"shed"`
+	// Routes is the number of requests being handled by route.
+	Routes map[string]int64 `json:"routes"`
+}
+
+// ConcurrencyLimiter limits the number of requests handled concurrently, see
+// MaxConcurrent. Its Stats can be exported to a metrics system.
+type ConcurrencyLimiter struct {
+	global chan struct{}
+	opts   ConcurrencyOptions
+
+	inFlight int64
+	queued   int64
+	shed     uint64
+
+	mu     sync.Mutex
+	routes map[string]*routeConcurrency
+}
+
+type routeConcurrency struct {
+	sem      chan struct{}
+	inFlight int64
+}
+
+// MaxConcurrent returns a middleware which handles at most n requests at the same
+// time (n <= 0 means no global limit), and sheds the extra requests with a
+// 503 Service Unavailable and a Retry-After header, so that an overloaded
+// server keeps a bounded latency instead of slowing down every request.
+// Use NewConcurrencyLimiter to read the number of in-flight requests.
+func MaxConcurrent(n int, opts ConcurrencyOptions) HandlerFunc {
+	return NewConcurrencyLimiter(n, opts).Handler
+}
+
+// NewConcurrencyLimiter returns a ConcurrencyLimiter, whose Handler is the middleware.
+func NewConcurrencyLimiter(n int, opts ConcurrencyOptions) *ConcurrencyLimiter {
+	if opts.QueueTimeout <= 0 {
+		opts.QueueTimeout = time.Second
+	}
+	if opts.RetryAfter <= 0 {
+		opts.RetryAfter = time.Second
+	}
+	if opts.ShedHandler == nil {
+		opts.ShedHandler = func(c *Context) {
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+		}
+	}
+	l := &ConcurrencyLimiter{opts: opts, routes: make(map[string]*routeConcurrency)}
+	if n > 0 {
+		l.global = make(chan struct{}, n)
+	}
+	return l
+}
+
+// Handler is the middleware limiting the concurrency.
+func (l *ConcurrencyLimiter) Handler(c *Context) {
+	route := l.route(c.FullPath())
+	// the route slot is taken first, so that the requests queued for a busy
+	// route do not hold the global slots the other routes could use.
+	if !l.acquire(c, route.sem, l.global) {
+		atomic.AddUint64(&l.shed, 1)
+		seconds := int((l.opts.RetryAfter + time.Second - 1) / time.Second)
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		l.opts.ShedHandler(c)
+		c.Abort()
+		return
+	}
+	atomic.AddInt64(&l.inFlight, 1)
+	atomic.AddInt64(&route.inFlight, 1)
+	defer func() {
+		atomic.AddInt64(&route.inFlight, -1)
+		atomic.AddInt64(&l.inFlight, -1)
+		releaseSlots(l.global, route.sem)
+	}()
+	c.Next()
+}
+
+// Stats returns the current state of the limiter.
+func (l *ConcurrencyLimiter) Stats() ConcurrencyStats {
+	stats := ConcurrencyStats{
+		InFlight: atomic.LoadInt64(&l.inFlight),
+		Queued:   atomic.LoadInt64(&l.queued),
+		Shed:     atomic.LoadUint64(&l.shed),
+		Routes:   make(map[string]int64),
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for path, r := range l.routes {
+		stats.Routes[path] = atomic.LoadInt64(&r.inFlight)
+	}
+	return stats
+}
+
+func (l *ConcurrencyLimiter) route(path string) *routeConcurrency {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r, ok := l.routes[path]
+	if !ok {
+		r = &routeConcurrency{}
+		limit, ok := l.opts.RouteLimits[path]
+		if !ok {
+			limit = l.opts.PerRoute
+		}
+		if limit > 0 {
+			r.sem = make(chan struct{}, limit)
+		}
+		l.routes[path] = r
+	}
+	return r
+}
+
+// acquire takes a slot of each of the semaphores (nil meaning no limit),
+// waiting in the queue when needed.
+func (l *ConcurrencyLimiter) acquire(c *Context, sems ...chan struct{}) bool {
+	var timeout <-chan time.Time
+	for i, sem := range sems {
+		if sem == nil {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+			continue
+		default:
+		}
+		if timeout == nil {
+			if atomic.AddInt64(&l.queued, 1) > int64(l.opts.QueueSize) {
+				atomic.AddInt64(&l.queued, -1)
+				releaseSlots(sems[:i]...)
+				return false
+			}
+			defer atomic.AddInt64(&l.queued, -1)
+			timer := time.NewTimer(l.opts.QueueTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-timeout:
+			releaseSlots(sems[:i]...)
+			return false
+		case <-c.Request.Context().Done():
+			releaseSlots(sems[:i]...)
+			return false
+		}
+	}
+	return true
+}
+
+func releaseSlots(sems ...chan struct{}) {
+	for _, sem := range sems {
+		if sem != nil {
+			<-sem
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxConcurrent(t *testing.T) {
+	release := make(chan struct{})
+	limiter := NewConcurrencyLimiter(2, ConcurrencyOptions{RetryAfter: 1500 * time.Millisecond})
+	router := New()
+	router.Use(limiter.Handler)
+	router.GET("/slow", func(c *Context) { <-release })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			PerformRequest(router, http.MethodGet, "/slow")
+		}()
+	}
+	assert.Eventually(t, func() bool { return limiter.Stats().InFlight == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, int64(2), limiter.Stats().Routes["/slow"])
+
+	w := PerformRequest(router, http.MethodGet, "/slow")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Equal(t, uint64(1), limiter.Stats().Shed)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int64(0), limiter.Stats().InFlight)
+	w = PerformRequest(router, http.MethodGet, "/slow")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMaxConcurrentPerRouteAndQueue(t *testing.T) {
+	release, releaseSlow := make(chan struct{}), make(chan struct{})
+	limiter := NewConcurrencyLimiter(0, ConcurrencyOptions{
+		PerRoute:     1,
+		RouteLimits:  map[string]int{"/unlimited": 0},
+		QueueSize:    1,
+		QueueTimeout: 100 * time.Millisecond,
+		ShedHandler: func(c *Context) {
+			c.String(http.StatusTooManyRequests, "shed")
+		},
+	})
+	router := New()
+	router.Use(limiter.Handler)
+	router.GET("/slow", func(c *Context) { <-releaseSlow })
+	router.GET("/unlimited", func(c *Context) { <-release })
+	router.GET("/fast", func(c *Context) {})
+
+	var wg sync.WaitGroup
+	for _, path := range []string{"/slow", "/unlimited", "/unlimited"} {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			PerformRequest(router, http.MethodGet, path)
+		}(path)
+	}
+	assert.Eventually(t, func() bool { return limiter.Stats().InFlight == 3 }, time.Second, time.Millisecond)
+
+	// other routes have their own limit.
+	w := PerformRequest(router, http.MethodGet, "/fast")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// the first waiting request times out in the queue, the second one is shed at once.
+	queued := make(chan *httptest.ResponseRecorder)
+	go func() { queued <- PerformRequest(router, http.MethodGet, "/slow") }()
+	assert.Eventually(t, func() bool { return limiter.Stats().Queued == 1 }, time.Second, time.Millisecond)
+	start := time.Now()
+	w = PerformRequest(router, http.MethodGet, "/slow")
+	assert.Equal(t, "shed", w.Body.String())
+	assert.Less(t, time.Since(start), 10*time.Millisecond)
+	w = <-queued
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	// a queued request is handled once a slot is released.
+	go func() { queued <- PerformRequest(router, http.MethodGet, "/slow") }()
+	assert.Eventually(t, func() bool { return limiter.Stats().Queued == 1 }, time.Second, time.Millisecond)
+	close(releaseSlow)
+	w = <-queued
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	close(release)
+	wg.Wait()
+}
+
+func TestMaxConcurrentQueuedRouteKeepsGlobalSlots(t *testing.T) {
+	release := make(chan struct{})
+	limiter := NewConcurrencyLimiter(2, ConcurrencyOptions{PerRoute: 1, QueueSize: 1, QueueTimeout: time.Second})
+	router := New()
+	router.Use(limiter.Handler)
+	router.GET("/slow", func(c *Context) { <-release })
+	router.GET("/fast", func(c *Context) {})
+
+	done := make(chan struct{})
+	go func() {
+		PerformRequest(router, http.MethodGet, "/slow")
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return limiter.Stats().InFlight == 1 }, time.Second, time.Millisecond)
+	queued := make(chan *httptest.ResponseRecorder)
+	go func() { queued <- PerformRequest(router, http.MethodGet, "/slow") }()
+	assert.Eventually(t, func() bool { return limiter.Stats().Queued == 1 }, time.Second, time.Millisecond)
+
+	// the queued request waits for its route without holding the last global slot.
+	w := PerformRequest(router, http.MethodGet, "/fast")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	close(release)
+	<-done
+	assert.Equal(t, http.StatusOK, (<-queued).Code)
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"path"
+	"strings"
+)
+
+// When returns a middleware which runs middleware only for the requests for
+// which predicate returns true. The other requests go on with the next handler:
+//
+//	router.Use(gin.When(gin.MethodIs(http.MethodPost, http.MethodPut), csrfProtect))
+func When(predicate func(*Context) bool, middleware HandlerFunc) HandlerFunc {
+	return func(c *Context) {
+		if predicate(c) {
+			middleware(c)
+		}
+	}
+}
+
+// Unless returns a middleware which runs middleware except for the requests for
+// which predicate returns true.
+func Unless(predicate func(*Context) bool, middleware HandlerFunc) HandlerFunc {
+	return func(c *Context) {
+		if !predicate(c) {
+			middleware(c)
+		}
+	}
+}
+
+// UnlessPath returns a middleware which runs middleware except for the requests
+// matching one of patterns, see PathMatches:
+//
+//	router.Use(gin.UnlessPath(authRequired, "/health", "/public/*"))
+func UnlessPath(middleware HandlerFunc, patterns ...string) HandlerFunc {
+	return Unless(PathMatches(patterns...), middleware)
+}
+
+// PathMatches returns a predicate reporting whether the request matches one of
+// patterns. A pattern matches when it is equal to the route template of the
+// request (as returned by Context.FullPath) or when it matches the path of the
+// request with the syntax of path.Match. A trailing "/**" matches any sub-path.
+func PathMatches(patterns ...string) func(*Context) bool {
+	return func(c *Context) bool {
+		fullPath := c.FullPath()
+		reqPath := c.Request.URL.Path
+		for _, pattern := range patterns {
+			if pattern == fullPath && fullPath != "" || pathMatches(pattern, reqPath) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// pathMatches reports whether reqPath matches pattern with the syntax of
+// path.Match, or is a sub-path of a pattern with a trailing "/**".
+func pathMatches(pattern, reqPath string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return reqPath == prefix || strings.HasPrefix(reqPath, prefix+"/")
+	}
+	ok, _ := path.Match(pattern, reqPath)
+	return ok
+}
+
+// MethodIs returns a predicate reporting whether the method of the request is one of methods.
+func MethodIs(methods ...string) func(*Context) bool {
+	return func(c *Context) bool {
+		for _, method := range methods {
+			if c.Request.Method == method {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HeaderIs returns a predicate reporting whether the request header key has the
+// given value. An empty value matches any non-empty header.
+func HeaderIs(key, value string) func(*Context) bool {
+	return func(c *Context) bool {
+		got := c.requestHeader(key)
+		if value == "" {
+			return got != ""
+		}
+		return got == value
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func markMiddleware(c *Context) {
+	c.Header("X-Mark", "1")
+	c.Next()
+}
+
+func TestWhenAndUnless(t *testing.T) {
+	router := New()
+	router.Use(When(MethodIs(http.MethodPost), markMiddleware))
+	router.Use(Unless(HeaderIs("X-Internal", ""), func(c *Context) { c.Header("X-External", "1") }))
+	router.Any("/", func(c *Context) {})
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Empty(t, w.Header().Get("X-Mark"))
+	assert.Equal(t, "1", w.Header().Get("X-External"))
+
+	w = PerformRequest(router, http.MethodPost, "/", header{"X-Internal", "yes"})
+	assert.Equal(t, "1", w.Header().Get("X-Mark"))
+	assert.Empty(t, w.Header().Get("X-External"))
+}
+
+func TestUnlessPath(t *testing.T) {
+	router := New()
+	router.Use(UnlessPath(markMiddleware, "/health", "/users/:id", "/public/**", "/*.txt"))
+	for _, p := range []string{"/health", "/users/:id", "/public/*filepath", "/robots.txt", "/private"} {
+		router.GET(p, func(c *Context) {})
+	}
+
+	for p, marked := range map[string]bool{
+		"/health":        false,
+		"/users/1":       false,
+		"/public/":       false,
+		"/public/a/b.js": false,
+		"/robots.txt":    false,
+		"/private":       true,
+	} {
+		w := PerformRequest(router, http.MethodGet, p)
+		assert.Equal(t, marked, w.Header().Get("X-Mark") == "1", p)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// ConnRejectMode is how a listener limited by NewLimitListener rejects the
+// connections over the limits.
+type ConnRejectMode int
+
+const (
+	// ConnRejectClose closes the connections over the limits as soon as they are accepted.
+	ConnRejectClose ConnRejectMode = iota
+	// ConnRejectServiceUnavailable replies 503 Service Unavailable to the
+	// connections over the limits, then closes them. Over TLS, the reply can't be
+	// read and it behaves like ConnRejectClose.
+	ConnRejectServiceUnavailable
+	// ConnRejectWait stops accepting connections while MaxConnections is
+	// reached, so that they wait in the backlog of the listener. The connections
+	// over MaxConnectionsPerIP are closed.
+	ConnRejectWait
+)
+
+// ConnLimitConfig defines the config of NewLimitListener.
+type ConnLimitConfig struct {
+	// MaxConnections is the maximum number of open connections.
+	// Optional. Zero or less means no limit.
+	MaxConnections int
+
+	// MaxConnectionsPerIP is the maximum number of open connections from an IP
+	// address. Behind a load balancer, including with the PROXY protocol, it is
+	// the address of the load balancer.
+	// Optional. Zero or less means no limit.
+	MaxConnectionsPerIP int
+
+	// Reject is how the connections over the limits are rejected.
+	// Optional. Default value is ConnRejectClose.
+	Reject ConnRejectMode
+}
+
+var serviceUnavailableResponse = []byte("HTTP/1.1 503 Service Unavailable\r\n" +
+	"Content-Length: 0\r\nConnection: close\r\nRetry-After: 1\r\n\r\n")
+
+// NewLimitListener wraps l to limit the number of open connections, to protect
+// a public-facing server against file descriptor exhaustion. See
+// Engine.MaxConnections.
+func NewLimitListener(l net.Listener, config ConnLimitConfig) net.Listener {
+	ll := &limitListener{Listener: l, config: config, done: make(chan struct{})}
+	if config.MaxConnections > 0 && config.Reject == ConnRejectWait {
+		ll.slots = make(chan struct{}, config.MaxConnections)
+	}
+	if config.MaxConnectionsPerIP > 0 {
+		ll.perIP = make(map[string]int)
+	}
+	return ll
+}
+
+type limitListener struct {
+	net.Listener
+	config ConnLimitConfig
+	slots  chan struct{}
+	done   chan struct{}
+	once   sync.Once
+
+	mu    sync.Mutex
+	open  int
+	perIP map[string]int
+}
+
+func (l *limitListener) Accept() (net.Conn, error) {
+	for {
+		if l.slots != nil {
+			select {
+			case l.slots <- struct{}{}:
+			case <-l.done:
+				return nil, net.ErrClosed
+			}
+		}
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			if l.slots != nil {
+				<-l.slots
+			}
+			return nil, err
+		}
+		ip := connIP(conn)
+		if l.acquire(ip) {
+			return &limitConn{Conn: conn, release: func() { l.release(ip) }}, nil
+		}
+		if l.slots != nil {
+			<-l.slots
+		}
+		l.reject(conn)
+	}
+}
+
+func (l *limitListener) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return l.Listener.Close()
+}
+
+func (l *limitListener) acquire(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.slots == nil && l.config.MaxConnections > 0 && l.open >= l.config.MaxConnections {
+		return false
+	}
+	if l.perIP != nil {
+		if l.perIP[ip] >= l.config.MaxConnectionsPerIP {
+			return false
+		}
+		l.perIP[ip]++
+	}
+	l.open++
+	return true
+}
+
+func (l *limitListener) release(ip string) {
+	l.mu.Lock()
+	l.open--
+	if l.perIP != nil {
+		if l.perIP[ip]--; l.perIP[ip] <= 0 {
+			delete(l.perIP, ip)
+		}
+	}
+	l.mu.Unlock()
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+func (l *limitListener) reject(conn net.Conn) {
+	if l.config.Reject != ConnRejectServiceUnavailable {
+		conn.Close()
+		return
+	}
+	// don't block Accept on a slow client
+	go func() {
+		_ = conn.SetWriteDeadline(time.Now().Add(time.Second))
+		_, _ = conn.Write(serviceUnavailableResponse)
+		conn.Close()
+	}()
+}
+
+// connIP returns the IP address of the peer of conn, without reading the
+// PROXY protocol header.
+func connIP(conn net.Conn) string {
+	if pc, ok := conn.(*proxyProtocolConn); ok {
+		conn = pc.Conn
+	}
+	addr := conn.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+type limitConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
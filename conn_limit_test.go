@@ -0,0 +1,123 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runLimitedServer(t *testing.T, router *Engine) string {
+	router.GET("/example", func(c *Context) { c.String(http.StatusOK, "it worked") })
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = router.RunListener(listener) }()
+	t.Cleanup(func() { _ = router.Shutdown(context.Background()) })
+	assert.Eventually(t, func() bool { return router.State().Accepting }, time.Second, time.Millisecond)
+	return listener.Addr().String()
+}
+
+// request sends a keep-alive request on a new connection and returns the
+// connection with the status line of the response.
+func request(t *testing.T, addr string) (net.Conn, string) {
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	fmt.Fprint(conn, "GET /example HTTP/1.1\r\nHost: example.com\r\n\r\n")
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	status, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && err != io.EOF {
+		status = err.Error()
+	}
+	return conn, status
+}
+
+func TestEngineMaxConnections(t *testing.T) {
+	router := New()
+	router.MaxConnections = 1
+	addr := runLimitedServer(t, router)
+
+	first, status := request(t, addr)
+	assert.Equal(t, "HTTP/1.1 200 OK\r\n", status)
+	second, status := request(t, addr)
+	second.Close()
+	assert.NotContains(t, status, "200 OK")
+
+	first.Close()
+	assert.Eventually(t, func() bool {
+		conn, status := request(t, addr)
+		conn.Close()
+		return status == "HTTP/1.1 200 OK\r\n"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestEngineMaxConnectionsPerIP(t *testing.T) {
+	router := New()
+	router.MaxConnectionsPerIP = 2
+	router.ConnectionReject = ConnRejectServiceUnavailable
+	addr := runLimitedServer(t, router)
+
+	first, status := request(t, addr)
+	defer first.Close()
+	assert.Equal(t, "HTTP/1.1 200 OK\r\n", status)
+	second, status := request(t, addr)
+	defer second.Close()
+	assert.Equal(t, "HTTP/1.1 200 OK\r\n", status)
+	third, status := request(t, addr)
+	defer third.Close()
+	assert.Equal(t, "HTTP/1.1 503 Service Unavailable\r\n", status)
+}
+
+func TestLimitListenerWait(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ll := NewLimitListener(l, ConnLimitConfig{MaxConnections: 1, Reject: ConnRejectWait})
+
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+	}
+	first, err := ll.Accept()
+	require.NoError(t, err)
+
+	accepted := make(chan net.Conn)
+	go func() {
+		conn, err := ll.Accept()
+		assert.NoError(t, err)
+		accepted <- conn
+	}()
+	select {
+	case <-accepted:
+		t.Fatal("accepted over MaxConnections")
+	case <-time.After(20 * time.Millisecond):
+	}
+	first.Close()
+	second := <-accepted
+	assert.NoError(t, second.Close())
+
+	// Close unblocks a waiting Accept
+	third, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer third.Close()
+	blocked, err := ll.Accept()
+	require.NoError(t, err)
+	defer blocked.Close()
+	errc := make(chan error)
+	go func() {
+		_, err := ll.Accept()
+		errc <- err
+	}()
+	assert.NoError(t, ll.Close())
+	assert.ErrorIs(t, <-errc, net.ErrClosed)
+}
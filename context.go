@@ -5,10 +5,13 @@
 package gin
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"io"
 	"log"
 	"math"
+	"mime"
 	"mime/multipart"
 	"net"
 	"net/http"
@@ -17,6 +20,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-contrib/sse"
@@ -35,14 +39,24 @@ const (
 	MIMEMultipartPOSTForm = binding.MIMEMultipartPOSTForm
 	MIMEYAML              = binding.MIMEYAML
 	MIMETOML              = binding.MIMETOML
+	MIMENDJSON            = "application/x-ndjson"
 )
 
+// LocalizerKey is the key under which the Localizer of a request is stored, see Context.T.
+const LocalizerKey = "_gin-gonic/gin/localizerkey"
+
 // BodyBytesKey indicates a default body bytes key.
 const BodyBytesKey = "_gin-gonic/gin/bodybyteskey"
 
 // ContextKey is the key that a Context returns itself for.
 const ContextKey = "_gin-gonic/gin/contextkey"
 
+// ErrBodyTooLarge is returned by Context.BodyBytes when the request body is
+// larger than Engine.MaxCachedBodySize.
+var ErrBodyTooLarge = errors.New("request body too large")
+
+var errNoHTMLRenderer = errors.New("no HTML renderer is configured, use LoadHTMLGlob, LoadHTMLFiles, SetHTMLTemplate or SetHTMLRenderer")
+
 // abortIndex represents a typical value used in abort functions.
 const abortIndex int8 = math.MaxInt8 >> 1
 
@@ -57,10 +71,25 @@ type Context struct {
 	handlers HandlersChain
 	index    int8
 	fullPath string
+	// routeMeta is the metadata of the matched route, see RouteMeta.
+	routeMeta map[string]any
+	// chain is the buffer of the handlers chain with the post-match middleware, see UsePostMatch.
+	chain HandlersChain
+	// routeName is the name of the matched route, see RouteName.
+	routeName string
+	// routeTemplate is the template of the route matching the path with another
+	// method, for 405 Method Not Allowed responses, see RouteTemplate.
+	routeTemplate string
 
 	engine       *Engine
 	params       *Params
 	skippedNodes *[]skippedNode
+	// paramsBuf backs params, in paramsArray for the routes with up to
+	// inlineParams parameters, so that they don't need another allocation.
+	paramsBuf   Params
+	paramsArray [inlineParams]Param
+	// leak is set once the request of a Context ends, with Engine.DetectContextLeaks.
+	leak atomic.Pointer[contextLeak]
 
 	// This mutex protects Keys map.
 	mu sync.RWMutex
@@ -84,12 +113,43 @@ type Context struct {
 	// SameSite allows a server to define a cookie attribute making it impossible for
 	// the browser to send this cookie along with cross-site requests.
 	sameSite http.SameSite
+
+	// htmlRender overrides Engine.HTMLRender for the routes of a group.
+	htmlRender render.HTMLRender
+
+	// handlerProbe is only set on the Contexts reading the name of a named
+	// handler, see lookupNamedHandler.
+	handlerProbe *namedHandler
+
+	// finished is closed once the request has been handled, and watchers waits
+	// for the goroutines of OnClientGone.
+	finished chan struct{}
+	watchers sync.WaitGroup
+
+	// deferred are the jobs run once the request has been handled, see Defer.
+	deferred []func(context.Context)
 }
 
 /************************************/
 /********** CONTEXT CREATION ********/
 /************************************/
 
+// inlineParams is the number of route parameters stored in the Context itself.
+const inlineParams = 8
+
+// growRouting makes room for the parameters and the backtracking of the route
+// lookup, when routes are added while the pooled contexts are in use. The
+// context keeps the larger buffers.
+func (c *Context) growRouting(maxParams, maxSections uint16) {
+	if cap(*c.params) < int(maxParams) {
+		*c.params = make(Params, len(*c.params), maxParams)
+	}
+	if cap(*c.skippedNodes) < int(maxSections) {
+		skippedNodes := make([]skippedNode, 0, maxSections)
+		c.skippedNodes = &skippedNodes
+	}
+}
+
 func (c *Context) reset() {
 	c.Writer = &c.writermem
 	c.Params = c.Params[:0]
@@ -97,12 +157,16 @@ func (c *Context) reset() {
 	c.index = -1
 
 	c.fullPath = ""
+	c.routeMeta = nil
+	c.routeName = ""
+	c.routeTemplate = ""
 	c.Keys = nil
 	c.Errors = c.Errors[:0]
 	c.Accepted = nil
 	c.queryCache = nil
 	c.formCache = nil
 	c.sameSite = 0
+	c.htmlRender = nil
 	*c.params = (*c.params)[:0]
 	*c.skippedNodes = (*c.skippedNodes)[:0]
 }
@@ -124,6 +188,7 @@ func (c *Context) Copy() *Context {
 	for k, v := range c.Keys {
 		cp.Keys[k] = v
 	}
+	cloneFeatures(cp.Keys)
 	paramCopy := make([]Param, len(cp.Params))
 	copy(paramCopy, cp.Params)
 	cp.Params = paramCopy
@@ -161,6 +226,36 @@ func (c *Context) FullPath() string {
 	return c.fullPath
 }
 
+// RouteTemplate returns the template of the route matching the request, e.g.
+// "/users/:id", to be used as a low-cardinality label by metrics, tracing,
+// logging or rate limiting. Unlike FullPath, it is also set for the requests
+// answered with 405 Method Not Allowed (by the NoMethod handlers), to the
+// template registered with another method. It is empty for 404 Not Found.
+func (c *Context) RouteTemplate() string {
+	if c.fullPath != "" {
+		return c.fullPath
+	}
+	return c.routeTemplate
+}
+
+// RouteName returns the name given to the matched route with Name, or an empty string:
+//
+//	router.GET("/users/:id", getUser).(gin.RouteNamer).Name("users.show")
+func (c *Context) RouteName() string {
+	return c.routeName
+}
+
+// RouteMeta returns the value of the metadata key of the matched route, set
+// with Meta when the route was registered:
+//
+//	router.GET("/users", listUsers).(gin.RouteMetaSetter).Meta("perm", "users.read")
+//
+//	perm, ok := c.RouteMeta("perm")
+func (c *Context) RouteMeta(key string) (value any, exists bool) {
+	value, exists = c.routeMeta[key]
+	return
+}
+
 /************************************/
 /*********** FLOW CONTROL ***********/
 /************************************/
@@ -240,6 +335,22 @@ func (c *Context) Error(err error) *Error {
 	return parsedError
 }
 
+// Localizer translates messages in the locale of a request. It is implemented
+// by the github.com/gin-gonic/gin/i18n package.
+type Localizer interface {
+	T(key string, args ...any) string
+}
+
+// T translates the message key in the locale of the request, with the Localizer
+// stored under LocalizerKey by an i18n middleware. The key is returned as is
+// when there is no Localizer.
+func (c *Context) T(key string, args ...any) string {
+	if l, ok := c.Value(LocalizerKey).(Localizer); ok {
+		return l.T(key, args...)
+	}
+	return key
+}
+
 /************************************/
 /******** METADATA MANAGEMENT********/
 /************************************/
@@ -247,6 +358,7 @@ func (c *Context) Error(err error) *Error {
 // Set is used to store a new key/value pair exclusively for this context.
 // It also lazy initializes  c.Keys if it was not used previously.
 func (c *Context) Set(key string, value any) {
+	c.checkLeak()
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if c.Keys == nil {
@@ -259,6 +371,7 @@ func (c *Context) Set(key string, value any) {
 // Get returns the value for the given key, ie: (value, true).
 // If the value does not exist it returns (nil, false)
 func (c *Context) Get(key string) (value any, exists bool) {
+	c.checkLeak()
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	value, exists = c.Keys[key]
@@ -391,6 +504,7 @@ func (c *Context) GetStringMapStringSlice(key string) (smss map[string][]string)
 //	    id := c.Param("id") // id == "/john/"
 //	})
 func (c *Context) Param(key string) string {
+	c.checkLeak()
 	return c.Params.ByName(key)
 }
 
@@ -456,6 +570,7 @@ func (c *Context) QueryArray(key string) (values []string) {
 }
 
 func (c *Context) initQueryCache() {
+	c.checkLeak()
 	if c.queryCache == nil {
 		if c.Request != nil {
 			c.queryCache = c.Request.URL.Query()
@@ -526,6 +641,7 @@ func (c *Context) PostFormArray(key string) (values []string) {
 }
 
 func (c *Context) initFormCache() {
+	c.checkLeak()
 	if c.formCache == nil {
 		c.formCache = make(url.Values)
 		req := c.Request
@@ -596,6 +712,10 @@ func (c *Context) MultipartForm() (*multipart.Form, error) {
 }
 
 // SaveUploadedFile uploads the form file to specific dst.
+// See SaveUploadedFileChecked for the files sent by untrusted clients.
+// With an Engine.UploadInspector, the file is written to a temporary file with
+// the mode 0640, renamed to dst once accepted, see SaveUploadedFileChecked.
+// With an Engine.UploadStorage, the file is saved as the blob dst.
 func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error {
 	src, err := file.Open()
 	if err != nil {
@@ -603,10 +723,20 @@ func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error
 	}
 	defer src.Close()
 
+	if c.engine != nil && c.engine.UploadStorage != nil {
+		info := UploadInfo{Filename: file.Filename, ContentType: file.Header.Get("Content-Type"), Size: file.Size}
+		return c.uploadError(c.saveUploadBlob(src, c.engine.UploadStorage, filepath.ToSlash(dst), info, c.engine.UploadInspector))
+	}
+
 	if err = os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
 		return err
 	}
 
+	if c.engine != nil && c.engine.UploadInspector != nil {
+		info := UploadInfo{Filename: file.Filename, ContentType: file.Header.Get("Content-Type"), Size: file.Size}
+		return c.uploadError(c.writeUpload(src, dst, 0o640, 0, true, info, c.engine.UploadInspector))
+	}
+
 	out, err := os.Create(dst)
 	if err != nil {
 		return err
@@ -627,13 +757,14 @@ func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error
 // It decodes the json payload into the struct specified as a pointer.
 // It writes a 400 error and sets Content-Type header "text/plain" in the response if input is not valid.
 func (c *Context) Bind(obj any) error {
-	b := binding.Default(c.Request.Method, c.ContentType())
+	b := c.defaultBinding()
 	return c.MustBindWith(obj, b)
 }
 
 // BindJSON is a shortcut for c.MustBindWith(obj, binding.JSON).
+// The engine's JSON binding options are honored, see Engine.SetJSONBindingOptions.
 func (c *Context) BindJSON(obj any) error {
-	return c.MustBindWith(obj, binding.JSON)
+	return c.MustBindWith(obj, c.jsonBinding())
 }
 
 // BindXML is a shortcut for c.MustBindWith(obj, binding.BindXML).
@@ -692,13 +823,20 @@ func (c *Context) MustBindWith(obj any, b binding.Binding) error {
 // It decodes the json payload into the struct specified as a pointer.
 // Like c.Bind() but this method does not set the response status code to 400 or abort if input is not valid.
 func (c *Context) ShouldBind(obj any) error {
-	b := binding.Default(c.Request.Method, c.ContentType())
+	b := c.defaultBinding()
 	return c.ShouldBindWith(obj, b)
 }
 
 // ShouldBindJSON is a shortcut for c.ShouldBindWith(obj, binding.JSON).
+// The engine's JSON binding options are honored, see Engine.SetJSONBindingOptions.
 func (c *Context) ShouldBindJSON(obj any) error {
-	return c.ShouldBindWith(obj, binding.JSON)
+	return c.ShouldBindWith(obj, c.jsonBinding())
+}
+
+// ShouldBindJSONStrict is a shortcut for c.ShouldBindWith(obj, binding.JSONStrict).
+// It returns an error when the body contains fields unknown to obj.
+func (c *Context) ShouldBindJSONStrict(obj any) error {
+	return c.ShouldBindWith(obj, binding.JSONStrict)
 }
 
 // ShouldBindXML is a shortcut for c.ShouldBindWith(obj, binding.XML).
@@ -735,6 +873,56 @@ func (c *Context) ShouldBindUri(obj any) error {
 	return binding.Uri.BindUri(m, obj)
 }
 
+func (c *Context) defaultBinding() binding.Binding {
+	b := binding.Default(c.Request.Method, c.ContentType())
+	if b == binding.JSON {
+		return c.jsonBinding()
+	}
+	return b
+}
+
+func (c *Context) jsonBinding() binding.BindingBody {
+	if c.engine != nil && c.engine.jsonBinding != nil {
+		return c.engine.jsonBinding
+	}
+	return binding.JSON
+}
+
+// ShouldBindAll fills the passed struct pointer from the URI params, the headers, the query
+// (and form) values and the body in a single call, driven by the uri, header, form and json
+// (xml, yaml...) tags. The precedence is uri > header > form > body, see binding.All.
+func (c *Context) ShouldBindAll(obj any) error {
+	m := make(map[string][]string, len(c.Params))
+	for _, v := range c.Params {
+		m[v.Key] = []string{v.Value}
+	}
+	return binding.All.BindAll(c.Request, m, obj)
+}
+
+// ShouldBindNoValidate works like ShouldBind, but the bound struct is not validated.
+func (c *Context) ShouldBindNoValidate(obj any) error {
+	ctx := c.Request.Context()
+	c.Request = c.Request.WithContext(binding.WithoutValidation(ctx))
+	defer func() { c.Request = c.Request.WithContext(ctx) }()
+	return c.ShouldBind(obj)
+}
+
+// BindAndValidate works like ShouldBind, but validation failures are returned as a
+// binding.FieldErrors, which serializes to a list of {"field", "rule", "message"} objects.
+// Messages are translated in the locale accepted by the client when a translator has
+// been set with Engine.SetValidatorTranslator.
+func (c *Context) BindAndValidate(obj any) error {
+	err := c.ShouldBind(obj)
+	if err == nil {
+		return nil
+	}
+	var translator *binding.Translator
+	if c.engine != nil {
+		translator = c.engine.translator
+	}
+	return translator.Translate(err, obj, c.requestHeader("Accept-Language"))
+}
+
 // ShouldBindWith binds the passed struct pointer using the specified binding engine.
 // See the binding package.
 func (c *Context) ShouldBindWith(obj any, b binding.Binding) error {
@@ -742,7 +930,8 @@ func (c *Context) ShouldBindWith(obj any, b binding.Binding) error {
 }
 
 // ShouldBindBodyWith is similar with ShouldBindWith, but it stores the request
-// body into the context, and reuse when it is called again.
+// body into the context, and reuse when it is called again. The bindings
+// implementing binding.BindingBodyCtx validate with the context of the request.
 //
 // NOTE: This method reads the body before binding. So you should use
 // ShouldBindWith for better performance if you need to call only once.
@@ -760,6 +949,9 @@ func (c *Context) ShouldBindBodyWith(obj any, bb binding.BindingBody) (err error
 		}
 		c.Set(BodyBytesKey, body)
 	}
+	if bbc, ok := bb.(binding.BindingBodyCtx); ok {
+		return bbc.BindBodyCtx(c.Request.Context(), body, obj)
+	}
 	return bb.BindBody(body, obj)
 }
 
@@ -785,6 +977,12 @@ func (c *Context) ClientIP() string {
 		}
 	}
 
+	for _, strategy := range c.engine.ClientIPStrategies {
+		if ip, ok := strategy(c); ok {
+			return ip
+		}
+	}
+
 	// It also checks if the remoteIP is a trusted proxy or not.
 	// In order to perform this validation, it will see if the IP is contained within at least one of the CIDR blocks
 	// defined by Engine.SetTrustedProxies()
@@ -793,10 +991,19 @@ func (c *Context) ClientIP() string {
 		return ""
 	}
 	trusted := c.engine.isTrustedProxy(remoteIP)
+	if c.engine.ForwardedByClientIP {
+		c.engine.checkForwarding(c, remoteIP, trusted)
+	}
 
 	if trusted && c.engine.ForwardedByClientIP && c.engine.RemoteIPHeaders != nil {
 		for _, headerName := range c.engine.RemoteIPHeaders {
-			ip, valid := c.engine.validateHeader(c.requestHeader(headerName))
+			var ip string
+			var valid bool
+			if strings.EqualFold(headerName, "Forwarded") {
+				ip, valid = c.forwardedClientIP()
+			} else {
+				ip, valid = c.engine.validateHeader(c.requestHeader(headerName))
+			}
 			if valid {
 				return ip
 			}
@@ -859,6 +1066,7 @@ func (c *Context) Status(code int) {
 // It writes a header in the response.
 // If value == "", this method removes the header `c.Writer.Header().Del(key)`
 func (c *Context) Header(key, value string) {
+	c.checkLeak()
 	if value == "" {
 		c.Writer.Header().Del(key)
 		return
@@ -872,8 +1080,103 @@ func (c *Context) GetHeader(key string) string {
 }
 
 // GetRawData returns stream data.
+// When Engine.CacheRequestBody is enabled, the body is cached (see BodyBytes)
+// and can be read again by the following handlers.
 func (c *Context) GetRawData() ([]byte, error) {
-	return io.ReadAll(c.Request.Body)
+	if c.engine != nil && c.engine.CacheRequestBody {
+		return c.BodyBytes()
+	}
+	return readBody(c.Request.Body, c.Request.ContentLength)
+}
+
+// maxBodyPrealloc bounds the buffer allocated upfront from the Content-Length
+// of a request, which is given by the client.
+const maxBodyPrealloc = 1 << 20
+
+// readBody reads r until EOF like io.ReadAll, in a buffer allocated once for
+// the announced contentLength, so that the body is not copied while growing.
+func readBody(r io.Reader, contentLength int64) ([]byte, error) {
+	size := int64(bytes.MinRead)
+	if contentLength > 0 {
+		// one more byte, so that the read returning EOF does not grow the buffer.
+		size = contentLength + 1
+		if size > maxBodyPrealloc {
+			size = maxBodyPrealloc
+		}
+	}
+	buf := make([]byte, 0, size)
+	for {
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+		n, err := r.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return buf, err
+		}
+	}
+}
+
+// BodyBytes reads the request body once and caches it under BodyBytesKey, the
+// key also used by ShouldBindBodyWith, so that several handlers (signature
+// verification, logging, binding...) can each get it. c.Request.Body is
+// restored afterwards, see RestoreBody. ErrBodyTooLarge is returned when the
+// body is larger than Engine.MaxCachedBodySize, and then by every read of the
+// body: no part of it is returned nor cached.
+func (c *Context) BodyBytes() ([]byte, error) {
+	if cb, ok := c.Get(BodyBytesKey); ok {
+		if body, ok := cb.([]byte); ok {
+			return body, nil
+		}
+	}
+	if c.Request == nil || c.Request.Body == nil {
+		return nil, nil
+	}
+
+	var maxSize int64
+	if c.engine != nil {
+		maxSize = c.engine.MaxCachedBodySize
+	}
+	reader := io.Reader(c.Request.Body)
+	if maxSize > 0 {
+		reader = io.LimitReader(reader, maxSize+1)
+	}
+	body, err := io.ReadAll(reader)
+	if err == nil && maxSize > 0 && int64(len(body)) > maxSize {
+		err = ErrBodyTooLarge
+	}
+	if err != nil {
+		// the rest of the body must not be taken for the whole body.
+		c.Request.Body = failedBody{err: err}
+		return nil, err
+	}
+	c.Set(BodyBytesKey, body)
+	c.RestoreBody()
+	return body, nil
+}
+
+// failedBody replaces a request body which BodyBytes failed to read, so that
+// the following readers get the error instead of the rest of the body.
+type failedBody struct {
+	err error
+}
+
+func (b failedBody) Read([]byte) (int, error) { return 0, b.err }
+
+func (b failedBody) Close() error { return nil }
+
+// RestoreBody rewinds c.Request.Body to the start of the body cached by
+// BodyBytes, after it was consumed by a reader which does not know about the
+// cache. It does nothing when the body is not cached.
+func (c *Context) RestoreBody() {
+	if cb, ok := c.Get(BodyBytesKey); ok {
+		if body, ok := cb.([]byte); ok {
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+	}
 }
 
 // SetSameSite with cookie
@@ -883,18 +1186,17 @@ func (c *Context) SetSameSite(samesite http.SameSite) {
 
 // SetCookie adds a Set-Cookie header to the ResponseWriter's headers.
 // The provided cookie must have a valid Name. Invalid cookies may be
-// silently dropped.
+// silently dropped. See SetCookieSpec for the other cookie attributes.
 func (c *Context) SetCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) {
 	if path == "" {
 		path = "/"
 	}
-	http.SetCookie(c.Writer, &http.Cookie{
+	c.writeCookie(Cookie{
 		Name:     name,
-		Value:    url.QueryEscape(value),
+		Value:    value,
 		MaxAge:   maxAge,
 		Path:     path,
 		Domain:   domain,
-		SameSite: c.sameSite,
 		Secure:   secure,
 		HttpOnly: httpOnly,
 	})
@@ -915,6 +1217,7 @@ func (c *Context) Cookie(name string) (string, error) {
 
 // Render writes the response headers and calls render.Render to render data.
 func (c *Context) Render(code int, r render.Render) {
+	c.checkLeak()
 	c.Status(code)
 
 	if !bodyAllowedForStatus(code) {
@@ -932,12 +1235,26 @@ func (c *Context) Render(code int, r render.Render) {
 
 // HTML renders the HTTP template specified by its file name.
 // It also updates the HTTP code and sets the Content-Type as "text/html".
+// The variables of Engine.ViewGlobals are merged into obj.
 // See http://golang.org/doc/articles/wiki/
 func (c *Context) HTML(code int, name string, obj any) {
-	instance := c.engine.HTMLRender.Instance(name, obj)
+	htmlRender := c.htmlRenderer()
+	if htmlRender == nil {
+		c.AbortWithError(http.StatusInternalServerError, errNoHTMLRenderer) //nolint: errcheck
+		return
+	}
+	instance := htmlRender.Instance(name, c.viewData(obj))
 	c.Render(code, instance)
 }
 
+// htmlRenderer returns the HTML renderer of the route, see RouterGroup.LoadHTMLGlobWithLayouts.
+func (c *Context) htmlRenderer() render.HTMLRender {
+	if c.htmlRender != nil {
+		return c.htmlRender
+	}
+	return c.engine.HTMLRender
+}
+
 // IndentedJSON serializes the given struct as pretty JSON (indented + endlines) into the response body.
 // It also sets the Content-Type as "application/json".
 // WARNING: we recommend using this only for development purposes since printing pretty JSON is
@@ -1027,15 +1344,131 @@ func (c *Context) Data(code int, contentType string, data []byte) {
 }
 
 // DataFromReader writes the specified reader into the body stream and updates the HTTP code.
+// A negative contentLength is unknown: the response is chunked. See
+// DataFromReaderWithConfig for the Content-Type sniffing and the Range requests.
 func (c *Context) DataFromReader(code int, contentLength int64, contentType string, reader io.Reader, extraHeaders map[string]string) {
+	var header http.Header
+	if len(extraHeaders) > 0 {
+		header = make(http.Header, len(extraHeaders))
+		for k, v := range extraHeaders {
+			header.Set(k, v)
+		}
+	}
+	c.dataFromReader(code, reader, ReaderConfig{ContentType: contentType, Header: header}, contentLength)
+}
+
+// ReaderConfig defines the config for Context.DataFromReaderWithConfig.
+type ReaderConfig struct {
+	// ContentType of the data.
+	// Optional. Default value is guessed from the extension of Name, or sniffed
+	// from the first 512 bytes of the data with http.DetectContentType.
+	ContentType string
+
+	// ContentLength is the length of the data.
+	// Optional. Default value is 0: unknown, the response is chunked, unless the
+	// reader is an io.Seeker.
+	ContentLength int64
+
+	// Header holds extra response headers, e.g. Content-Disposition. They do not
+	// replace the headers already set.
+	// Optional.
+	Header http.Header
+
+	// Name is the name of the file the data comes from, whose extension gives
+	// the Content-Type.
+	// Optional.
+	Name string
+
+	// ModTime is the modification time of the data, sent as Last-Modified and
+	// checked against the conditional request headers of the seekable readers.
+	// Optional.
+	ModTime time.Time
+}
+
+// DataFromReaderWithConfig writes reader into the body stream with the status
+// code. When code is 200 OK and reader is an io.ReadSeeker at its start, the
+// response is served with http.ServeContent: the Range, If-Range and
+// conditional requests are answered with 206 Partial Content, 304 Not Modified
+// or 416 Range Not Satisfiable, and the length is found by seeking.
+func (c *Context) DataFromReaderWithConfig(code int, reader io.Reader, config ReaderConfig) {
+	length := config.ContentLength
+	if length <= 0 {
+		length = -1
+	}
+	c.dataFromReader(code, reader, config, length)
+}
+
+// dataFromReader serves reader with config, length being negative when unknown.
+func (c *Context) dataFromReader(code int, reader io.Reader, config ReaderConfig, length int64) {
+	header := c.Writer.Header()
+	for k, v := range config.Header {
+		if header.Get(k) == "" {
+			header[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+	if config.ContentType == "" && config.Name != "" {
+		config.ContentType = mime.TypeByExtension(filepath.Ext(config.Name))
+	}
+
+	if seeker, ok := reader.(io.ReadSeeker); ok && code == http.StatusOK && c.Request != nil && seekableFromStart(seeker, length) {
+		if config.ContentType != "" {
+			header.Set("Content-Type", config.ContentType)
+		}
+		http.ServeContent(c.Writer, c.Request, config.Name, config.ModTime, seeker)
+		return
+	}
+
+	if config.ContentType == "" {
+		config.ContentType, reader = sniffContentType(reader)
+	}
+	if !config.ModTime.IsZero() {
+		header.Set("Last-Modified", config.ModTime.UTC().Format(http.TimeFormat))
+	}
 	c.Render(code, render.Reader{
-		Headers:       extraHeaders,
-		ContentType:   contentType,
-		ContentLength: contentLength,
+		ContentType:   config.ContentType,
+		ContentLength: length,
 		Reader:        reader,
 	})
 }
 
+// seekableFromStart reports whether seeker is at its start, and holds length
+// bytes when length is known.
+func seekableFromStart(seeker io.Seeker, length int64) bool {
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil || offset != 0 {
+		return false
+	}
+	if length < 0 {
+		return true
+	}
+	size, err := seeker.Seek(0, io.SeekEnd)
+	if _, serr := seeker.Seek(0, io.SeekStart); err != nil || serr != nil {
+		return false
+	}
+	return size == length
+}
+
+// sniffContentType detects the Content-Type of the data of reader from its
+// first 512 bytes, and returns a reader of the whole data.
+func sniffContentType(reader io.Reader) (string, io.Reader) {
+	var buf [512]byte
+	n, err := io.ReadFull(reader, buf[:])
+	head := buf[:n]
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return http.DetectContentType(head), io.MultiReader(bytes.NewReader(head), errReader{err})
+	}
+	return http.DetectContentType(head), io.MultiReader(bytes.NewReader(head), reader)
+}
+
+// errReader is a reader failing with err.
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
 // File writes the specified file into the body stream in an efficient way.
 func (c *Context) File(filepath string) {
 	http.ServeFile(c.Writer, c.Request, filepath)
@@ -1211,6 +1644,63 @@ func (c *Context) Err() error {
 	return c.Request.Context().Err()
 }
 
+// IsClientGone returns true if the client closed the connection (or, with HTTP/2,
+// reset the stream) before the response was completed. Unlike Done and Err, it
+// does not depend on Engine.ContextWithFallback, and it is not true when the
+// request merely timed out (see Engine.RequestTimeout).
+func (c *Context) IsClientGone() bool {
+	if c.Request == nil {
+		return false
+	}
+	return errors.Is(c.Request.Context().Err(), context.Canceled)
+}
+
+// OnClientGone registers f to be called, in its own goroutine, when the client
+// goes away while the request is being handled, so that streaming handlers
+// (SSE, long polls...) can stop their work promptly. f is not called when the
+// request completes normally. It must be called from the handler chain, not
+// from a copy of the Context. The handling of the request only ends once f has
+// returned, so f can use c.
+func (c *Context) OnClientGone(f func()) {
+	if c.Request == nil {
+		return
+	}
+	if c.finished == nil {
+		c.finished = make(chan struct{})
+	}
+	ctx, finished := c.Request.Context(), c.finished
+	c.watchers.Add(1)
+	go func() {
+		defer c.watchers.Done()
+		select {
+		case <-ctx.Done():
+			select {
+			case <-finished:
+				// the server cancels the context once the request is handled.
+				return
+			default:
+			}
+			if errors.Is(ctx.Err(), context.Canceled) {
+				f()
+			}
+		case <-finished:
+		}
+	}()
+}
+
+// finish stops the OnClientGone goroutines once the request has been handled,
+// unless the client went away meanwhile, in which case they are left to fire.
+// It waits for them, so that c is not reused while a callback runs.
+func (c *Context) finish() {
+	if c.finished != nil {
+		if !c.IsClientGone() {
+			close(c.finished)
+		}
+		c.watchers.Wait()
+		c.finished = nil
+	}
+}
+
 // Value returns the value associated with this context for key, or nil
 // if no value is associated with key. Successive calls to Value with
 // the same key returns the same result.
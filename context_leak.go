@@ -0,0 +1,75 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ContextPoolStats reports the use of the pool of Contexts, see Engine.ContextPoolStats.
+type ContextPoolStats struct {
+	// Allocated is the number of Contexts allocated by the pool.
+	Allocated uint64 `json:"allocated"`
+	// Acquired is the number of Contexts taken from the pool, one per request.
+	Acquired uint64 `json:"acquired"`
+	// InUse is the number of requests being served.
+	InUse uint64 `json:"in_use"`
+	// Leaks is the number of Contexts used after the end of their request,
+	// detected when Engine.DetectContextLeaks is enabled.
+	Leaks uint64 `json:"leaks"`
+}
+
+type contextPoolStats struct {
+	allocated atomic.Uint64
+	acquired  atomic.Uint64
+	released  atomic.Uint64
+	leaks     atomic.Uint64
+}
+
+// ContextPoolStats returns the statistics of the pool of Contexts of the engine.
+// Many more allocations than the maximum number of concurrent requests show that
+// the pool is not effective, e.g. because contexts are leaked.
+func (engine *Engine) ContextPoolStats() ContextPoolStats {
+	s := &engine.contextStats
+	released := s.released.Load()
+	return ContextPoolStats{
+		Allocated: s.allocated.Load(),
+		Acquired:  s.acquired.Load(),
+		InUse:     s.acquired.Load() - released,
+		Leaks:     s.leaks.Load(),
+	}
+}
+
+// contextLeak describes the request of a released Context, to report its later uses.
+type contextLeak struct {
+	method, path, handler string
+	once                  sync.Once
+}
+
+// releaseContext returns c to the pool at the end of its request. With
+// DetectContextLeaks, c is not recycled: it is marked to report its later uses.
+func (engine *Engine) releaseContext(c *Context) {
+	engine.contextStats.released.Add(1)
+	if engine.DetectContextLeaks {
+		c.leak.Store(&contextLeak{method: c.Request.Method, path: c.fullPath, handler: c.HandlerName()})
+		return
+	}
+	engine.pool.Put(c)
+}
+
+// checkLeak reports the use of c after the end of its request, usually by a
+// goroutine started by a handler which should have used c.Copy().
+func (c *Context) checkLeak() {
+	leak := c.leak.Load()
+	if leak == nil {
+		return
+	}
+	leak.once.Do(func() {
+		c.engine.contextStats.leaks.Add(1)
+		debugPrintWarning("Context of %s %s (%s) used after the end of the request: "+
+			"goroutines must use c.Copy()", leak.method, leak.path, leak.handler)
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineContextPoolStats(t *testing.T) {
+	router := New()
+	var inUse uint64
+	router.GET("/", func(c *Context) { inUse = router.ContextPoolStats().InUse })
+	for i := 0; i < 3; i++ {
+		PerformRequest(router, http.MethodGet, "/")
+	}
+	stats := router.ContextPoolStats()
+	assert.Equal(t, uint64(1), inUse)
+	assert.Equal(t, uint64(3), stats.Acquired)
+	assert.Zero(t, stats.InUse)
+	assert.GreaterOrEqual(t, stats.Allocated, uint64(1))
+	assert.Zero(t, stats.Leaks)
+}
+
+func TestEngineDetectContextLeaks(t *testing.T) {
+	router := New()
+	router.DetectContextLeaks = true
+	var leaked *Context
+	router.GET("/users/:id", func(c *Context) {
+		leaked = c
+		c.String(http.StatusOK, c.Param("id"))
+	})
+	w := PerformRequest(router, http.MethodGet, "/users/1")
+	assert.Equal(t, "1", w.Body.String())
+	assert.Zero(t, router.ContextPoolStats().Leaks)
+
+	out := captureOutput(t, func() {
+		SetMode(DebugMode)
+		assert.Equal(t, "1", leaked.Param("id"))
+		leaked.Set("key", "value")
+		SetMode(TestMode)
+	})
+	assert.Contains(t, out, "[WARNING] Context of GET /users/:id (github.com/gin-gonic/gin.TestEngineDetectContextLeaks.func1) used after the end of the request")
+	assert.Equal(t, 1, strings.Count(out, "used after the end of the request"))
+	assert.Equal(t, uint64(1), router.ContextPoolStats().Leaks)
+
+	// the contexts are not recycled
+	first := leaked
+	PerformRequest(router, http.MethodGet, "/users/2")
+	assert.NotSame(t, first, leaked)
+	assert.Equal(t, "1", first.Params.ByName("id"))
+	assert.Equal(t, uint64(2), router.ContextPoolStats().Allocated)
+
+	// copies are safe
+	assert.Nil(t, first.Copy().leak.Load())
+}
@@ -21,12 +21,16 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin/binding"
+	_ "github.com/gin-gonic/gin/binding/translations"
+	"github.com/gin-gonic/gin/render"
 	testdata "github.com/gin-gonic/gin/testdata/protoexample"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -857,6 +861,34 @@ func TestContextRenderHTML2(t *testing.T) {
 	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
 }
 
+type testHTMLRenderer struct{}
+
+func (testHTMLRenderer) Instance(name string, data any) render.Render {
+	return render.String{Format: name + " %v", Data: []any{data}}
+}
+
+func TestContextRenderHTMLCustomRenderer(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, router := CreateTestContext(w)
+
+	router.SetHTMLRenderer(testHTMLRenderer{})
+	c.HTML(http.StatusOK, "hello", "gin")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello gin", w.Body.String())
+}
+
+func TestContextRenderHTMLWithoutRenderer(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.HTML(http.StatusOK, "hello", nil)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, errNoHTMLRenderer, c.Errors.Last().Err)
+}
+
 // Tests that no HTML is rendered if code is 204
 func TestContextRenderNoContentHTML(t *testing.T) {
 	w := httptest.NewRecorder()
@@ -1816,6 +1848,64 @@ func TestContextShouldBindHeader(t *testing.T) {
 	assert.Equal(t, 0, w.Body.Len())
 }
 
+func TestContextShouldBindJSONStrict(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"foo":"bar", "bar":"foo"}`))
+	c.Request.Header.Add("Content-Type", MIMEJSON)
+
+	var obj struct {
+		Foo string `json:"foo"`
+	}
+	assert.Error(t, c.ShouldBindJSONStrict(&obj))
+}
+
+func TestContextJSONBindingOptions(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, router := CreateTestContext(w)
+	router.SetJSONBindingOptions(binding.JSONOptions{DisallowUnknownFields: true})
+
+	var obj struct {
+		Foo string `json:"foo"`
+	}
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"foo":"bar", "bar":"foo"}`))
+	c.Request.Header.Add("Content-Type", MIMEJSON)
+	assert.Error(t, c.ShouldBindJSON(&obj))
+
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"foo":"bar", "bar":"foo"}`))
+	c.Request.Header.Add("Content-Type", MIMEJSON)
+	assert.Error(t, c.ShouldBind(&obj))
+
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"foo":"bar"}`))
+	c.Request.Header.Add("Content-Type", MIMEJSON)
+	assert.NoError(t, c.BindJSON(&obj))
+	assert.Equal(t, "bar", obj.Foo)
+}
+
+func TestContextShouldBindAll(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("POST", "/users/7?page=2", bytes.NewBufferString(`{"name": "gin"}`))
+	c.Request.Header.Add("Content-Type", MIMEJSON)
+	c.Request.Header.Add("X-Request-Id", "abc")
+	c.Params = Params{{Key: "id", Value: "7"}}
+
+	var obj struct {
+		ID        int    `uri:"id"`
+		RequestID string `header:"X-Request-Id"`
+		Page      int    `form:"page"`
+		Name      string `json:"name"`
+	}
+	assert.NoError(t, c.ShouldBindAll(&obj))
+	assert.Equal(t, 7, obj.ID)
+	assert.Equal(t, "abc", obj.RequestID)
+	assert.Equal(t, 2, obj.Page)
+	assert.Equal(t, "gin", obj.Name)
+	assert.Equal(t, 0, w.Body.Len())
+}
+
 func TestContextShouldBindWithQuery(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := CreateTestContext(w)
@@ -2027,6 +2117,75 @@ func TestContextGetRawData(t *testing.T) {
 	assert.Equal(t, "Fetch binary post data", string(data))
 }
 
+func TestReadBody(t *testing.T) {
+	body, err := readBody(strings.NewReader("hello"), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(body))
+	assert.Equal(t, 6, cap(body))
+
+	// the Content-Length is only a hint.
+	large := strings.Repeat("x", 3*bytes.MinRead)
+	body, err = readBody(strings.NewReader(large), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, large, string(body))
+	body, err = readBody(strings.NewReader("short"), 1<<40)
+	assert.NoError(t, err)
+	assert.Equal(t, "short", string(body))
+	assert.Equal(t, maxBodyPrealloc, cap(body))
+
+	_, err = readBody(iotest.ErrReader(errors.New("boom")), 0)
+	assert.EqualError(t, err, "boom")
+}
+
+func TestContextGetRawDataCached(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.engine.CacheRequestBody = true
+	c.Request, _ = http.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"foo":"bar"}`))
+
+	data, err := c.GetRawData()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"foo":"bar"}`, string(data))
+
+	data, err = c.GetRawData()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"foo":"bar"}`, string(data))
+
+	var obj struct {
+		Foo string `json:"foo"`
+	}
+	assert.NoError(t, c.ShouldBindJSON(&obj))
+	assert.Equal(t, "bar", obj.Foo)
+	assert.NoError(t, c.ShouldBindBodyWith(&obj, binding.JSON))
+
+	c.RestoreBody()
+	data, err = io.ReadAll(c.Request.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"foo":"bar"}`, string(data))
+}
+
+func TestContextBodyBytesTooLarge(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.engine.MaxCachedBodySize = 4
+	c.Request, _ = http.NewRequest(http.MethodPost, "/", bytes.NewBufferString("123456789"))
+
+	data, err := c.BodyBytes()
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+	assert.Nil(t, data)
+	// the rest of the body is neither returned nor cached.
+	data, err = c.BodyBytes()
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+	assert.Nil(t, data)
+	_, err = io.ReadAll(c.Request.Body)
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+	_, ok := c.Get(BodyBytesKey)
+	assert.False(t, ok)
+
+	c.Request, _ = http.NewRequest(http.MethodPost, "/", bytes.NewBufferString("1234"))
+	data, err = c.BodyBytes()
+	assert.NoError(t, err)
+	assert.Equal(t, "1234", string(data))
+}
+
 func TestContextRenderDataFromReader(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := CreateTestContext(w)
@@ -2235,6 +2394,91 @@ func TestContextWithFallbackDoneFromRequestContext(t *testing.T) {
 	assert.NotNil(t, <-c2.Done())
 }
 
+func TestContextIsClientGone(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	assert.False(t, c.IsClientGone())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.Request, _ = http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	assert.False(t, c.IsClientGone())
+	cancel()
+	assert.True(t, c.IsClientGone())
+
+	ctx, cancel = context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	c.Request = c.Request.WithContext(ctx)
+	assert.False(t, c.IsClientGone())
+}
+
+func TestContextOnClientGone(t *testing.T) {
+	gone := make(chan struct{})
+	completed := make(chan struct{}, 1)
+	router := New()
+	router.GET("/stream", func(c *Context) {
+		c.OnClientGone(func() { close(gone) })
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+		<-c.Request.Context().Done()
+		assert.True(t, c.IsClientGone())
+	})
+	router.GET("/ok", func(c *Context) {
+		c.OnClientGone(func() { completed <- struct{}{} })
+		c.String(http.StatusOK, "ok")
+	})
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/stream", nil)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	cancel()
+	resp.Body.Close()
+	select {
+	case <-gone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnClientGone callback not called")
+	}
+
+	resp, err = http.Get(srv.URL + "/ok")
+	require.NoError(t, err)
+	resp.Body.Close()
+	select {
+	case <-completed:
+		t.Fatal("OnClientGone callback called for a completed request")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestContextOnClientGoneBeforeRelease(t *testing.T) {
+	release := make(chan struct{})
+	var fullPath string
+	router := New()
+	router.GET("/wait", func(c *Context) {
+		c.OnClientGone(func() {
+			<-release
+			fullPath = c.FullPath()
+		})
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/wait", nil)
+	done := make(chan struct{})
+	go func() {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("the request ended before the OnClientGone callback")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-done
+	assert.Equal(t, "/wait", fullPath)
+}
+
 func TestContextWithFallbackErrFromRequestContext(t *testing.T) {
 	c, _ := CreateTestContext(httptest.NewRecorder())
 	// enable ContextWithFallback feature flag
@@ -2445,3 +2689,155 @@ func TestInterceptedHeader(t *testing.T) {
 	assert.Equal(t, "", w.Result().Header.Get("X-Test"))
 	assert.Equal(t, "present", w.Result().Header.Get("X-Test-2"))
 }
+
+func TestContextBindAndValidate(t *testing.T) {
+	router := New()
+	assert.NoError(t, router.SetValidatorTranslator("en", "es"))
+	router.POST("/", func(c *Context) {
+		var obj struct {
+			Email string `json:"email" binding:"required"`
+		}
+		if err := c.BindAndValidate(&obj); err != nil {
+			c.JSON(http.StatusBadRequest, H{"errors": err})
+			return
+		}
+		c.String(http.StatusOK, obj.Email)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", MIMEJSON)
+	req.Header.Set("Accept-Language", "es")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"errors":[{"field":"email","rule":"required","message":"Email es un campo requerido"}]}`, w.Body.String())
+}
+
+func TestContextShouldBindNoValidate(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	c.Request.Header.Set("Content-Type", MIMEJSON)
+
+	var obj struct {
+		Foo string `json:"foo" binding:"required"`
+	}
+	assert.NoError(t, c.ShouldBindNoValidate(&obj))
+	assert.NoError(t, binding.Validate(c.Request.Context(), &struct{}{}))
+	assert.Error(t, binding.Validate(c.Request.Context(), &obj))
+}
+
+func TestContextShouldBindBodyWithValidationContext(t *testing.T) {
+	var obj struct {
+		Foo string `json:"foo" xml:"foo" yaml:"foo" binding:"required"`
+	}
+	for _, bb := range []binding.BindingBody{binding.JSON, binding.JSONStrict, binding.XML, binding.YAML} {
+		c, _ := CreateTestContext(httptest.NewRecorder())
+		c.Request, _ = http.NewRequest(http.MethodPost, "/", nil)
+		body := []byte("{}")
+		if bb.Name() == "xml" {
+			body = []byte("<obj></obj>")
+		}
+		c.Set(BodyBytesKey, body)
+
+		assert.Error(t, c.ShouldBindBodyWith(&obj, bb), bb.Name())
+		c.Request = c.Request.WithContext(binding.WithoutValidation(c.Request.Context()))
+		assert.NoError(t, c.ShouldBindBodyWith(&obj, bb), bb.Name())
+	}
+}
+
+func TestValidationGroupsMiddleware(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id" binding:"required" groups:"update"`
+		Name string `json:"name" binding:"required"`
+	}
+	router := New()
+	handler := func(c *Context) {
+		var u user
+		if err := c.ShouldBindJSON(&u); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+	router.POST("/users", ValidationGroups("create"), handler)
+	router.PUT("/users", ValidationGroups("update"), handler)
+
+	for method, code := range map[string]int{http.MethodPost: http.StatusOK, http.MethodPut: http.StatusBadRequest} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/users", strings.NewReader(`{"name":"bob"}`))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code, method)
+	}
+}
+
+func TestContextDataFromReaderRange(t *testing.T) {
+	body := "0123456789"
+	modTime := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	serve := func(req *http.Request, reader io.Reader, config ReaderConfig) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := CreateTestContext(w)
+		c.Request = req
+		c.DataFromReaderWithConfig(http.StatusOK, reader, config)
+		c.Writer.WriteHeaderNow()
+		return w
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	w := serve(req, strings.NewReader(body), ReaderConfig{
+		Name:    "digits.txt",
+		ModTime: modTime,
+		Header:  http.Header{"Content-Disposition": {"inline"}},
+	})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "2345", w.Body.String())
+	assert.Equal(t, "bytes 2-5/10", w.Header().Get("Content-Range"))
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "inline", w.Header().Get("Content-Disposition"))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-Modified-Since", modTime.Format(http.TimeFormat))
+	w = serve(req, strings.NewReader(body), ReaderConfig{ModTime: modTime})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=20-")
+	w = serve(req, strings.NewReader(body), ReaderConfig{})
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+
+	// A reader which is not at its start is not served with ServeContent.
+	reader := strings.NewReader(body)
+	_, _ = reader.Seek(4, io.SeekStart)
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=0-1")
+	w = serve(req, reader, ReaderConfig{ContentType: "text/plain"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "456789", w.Body.String())
+}
+
+func TestContextDataFromReaderSniffing(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("x", 1000)
+	c.DataFromReaderWithConfig(http.StatusCreated, io.NopCloser(strings.NewReader(png)), ReaderConfig{})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Empty(t, w.Header().Get("Content-Length"))
+	assert.Equal(t, png, w.Body.String())
+
+	w = httptest.NewRecorder()
+	c, _ = CreateTestContext(w)
+	c.DataFromReaderWithConfig(http.StatusOK, io.NopCloser(strings.NewReader("{}")), ReaderConfig{Name: "a.json", ContentLength: 2})
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "2", w.Header().Get("Content-Length"))
+
+	w = httptest.NewRecorder()
+	c, _ = CreateTestContext(w)
+	c.DataFromReader(http.StatusOK, 0, "", io.NopCloser(strings.NewReader("")), nil)
+	assert.Equal(t, "0", w.Header().Get("Content-Length"))
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+}
@@ -0,0 +1,336 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoCookieKeys is returned by the signed and encrypted cookie helpers
+	// when Engine.SetCookieKeys was not called.
+	ErrNoCookieKeys = errors.New("no cookie keys are configured, use SetCookieKeys")
+
+	// ErrInvalidCookie is returned when a signed or encrypted cookie was tampered
+	// with, was produced with a key which is no longer in the key ring, or has
+	// expired.
+	ErrInvalidCookie = errors.New("invalid cookie")
+
+	errSecureCookiePrefix = errors.New("__Secure- cookies must be Secure")
+	errHostCookiePrefix   = errors.New("__Host- cookies must be Secure, with Path=/ and no Domain")
+	errPartitionedCookie  = errors.New("partitioned cookies must be Secure")
+	errInvalidCookie      = errors.New("invalid cookie name or attributes")
+)
+
+// Cookie describes a cookie set by Context.SetCookieSpec. Unlike http.Cookie,
+// it supports the Partitioned attribute (CHIPS).
+type Cookie struct {
+	Name  string
+	Value string
+
+	// Path defaults to "/".
+	Path   string
+	Domain string
+
+	// MaxAge=0 means no Max-Age attribute, MaxAge<0 deletes the cookie.
+	MaxAge  int
+	Expires time.Time
+
+	Secure   bool
+	HttpOnly bool
+
+	// SameSite defaults to the value set with Context.SetSameSite.
+	SameSite http.SameSite
+
+	// Partitioned stores the cookie in a jar partitioned by top-level site,
+	// as third-party cookies are phased out. It requires Secure.
+	Partitioned bool
+}
+
+// SetCookieSpec adds a Set-Cookie header for cookie. The value is escaped the
+// same way as by SetCookie, so that Cookie returns it unchanged. An error is
+// returned when the requirements of the __Secure- and __Host- name prefixes,
+// or of the Partitioned attribute, are not met, or when the cookie is invalid.
+func (c *Context) SetCookieSpec(cookie Cookie) error {
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+	if err := checkCookiePrefix(cookie.Name, cookie.Path, cookie.Domain, cookie.Secure); err != nil {
+		return err
+	}
+	if cookie.Partitioned && !cookie.Secure {
+		return errPartitionedCookie
+	}
+	if !c.writeCookie(cookie) {
+		return errInvalidCookie
+	}
+	return nil
+}
+
+// checkCookiePrefix returns an error if the requirements of the name prefixes
+// are not met: __Secure- cookies must be Secure, __Host- cookies must also be
+// bound to the host (no Domain) and to the whole site (Path=/).
+func checkCookiePrefix(name, path, domain string, secure bool) error {
+	switch {
+	case strings.HasPrefix(name, "__Host-"):
+		if !secure || path != "/" || domain != "" {
+			return errHostCookiePrefix
+		}
+	case strings.HasPrefix(name, "__Secure-"):
+		if !secure {
+			return errSecureCookiePrefix
+		}
+	}
+	return nil
+}
+
+// writeCookie adds the Set-Cookie header for cookie, or returns false if the
+// cookie is invalid.
+func (c *Context) writeCookie(cookie Cookie) bool {
+	if cookie.SameSite == 0 {
+		cookie.SameSite = c.sameSite
+	}
+	header := (&http.Cookie{
+		Name:     cookie.Name,
+		Value:    url.QueryEscape(cookie.Value),
+		Path:     cookie.Path,
+		Domain:   cookie.Domain,
+		MaxAge:   cookie.MaxAge,
+		Expires:  cookie.Expires,
+		Secure:   cookie.Secure,
+		HttpOnly: cookie.HttpOnly,
+		SameSite: cookie.SameSite,
+	}).String()
+	if header == "" {
+		return false
+	}
+	if cookie.Partitioned {
+		header += "; Partitioned"
+	}
+	c.Writer.Header().Add("Set-Cookie", header)
+	return true
+}
+
+type cookieKey struct {
+	sign    []byte
+	encrypt cipher.AEAD
+}
+
+// SetCookieKeys sets the key ring used by the signed and encrypted cookie
+// helpers. The first key signs and encrypts new cookies, all of them are
+// accepted when reading cookies, so that keys can be rotated by prepending
+// the new key and dropping the oldest one later. Keys should be at least 32
+// random bytes.
+func (engine *Engine) SetCookieKeys(keys ...[]byte) error {
+	ring := make([]cookieKey, 0, len(keys))
+	for _, key := range keys {
+		if len(key) == 0 {
+			return errors.New("empty cookie key")
+		}
+		block, err := aes.NewCipher(deriveCookieKey(key, "gin encrypted cookie"))
+		if err != nil {
+			return err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return err
+		}
+		ring = append(ring, cookieKey{sign: deriveCookieKey(key, "gin signed cookie"), encrypt: aead})
+	}
+	engine.cookieKeys = ring
+	return nil
+}
+
+func deriveCookieKey(key []byte, label string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
+
+func signCookie(key []byte, name string, expires int64, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	mac.Write([]byte{0})
+	mac.Write([]byte(strconv.FormatInt(expires, 10)))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// cookieExpires returns the expiry, in Unix seconds, stored in the signed and
+// encrypted cookies with maxAge, or 0 for no expiry: clients can keep a cookie
+// longer than its Max-Age, so it is checked when reading the cookie.
+func cookieExpires(maxAge int) int64 {
+	if maxAge <= 0 {
+		return 0
+	}
+	return time.Now().Add(time.Duration(maxAge) * time.Second).Unix()
+}
+
+func cookieExpired(expires int64) bool {
+	return expires != 0 && time.Now().Unix() >= expires
+}
+
+// SetSignedCookie is like SetCookie, but the value is signed with the cookie
+// keys of the engine, so that SignedCookie can detect tampering. The value
+// itself is readable by the client, use SetEncryptedCookie to hide it.
+// When maxAge is positive, the expiry is signed with the value and checked by
+// SignedCookie. SameSite defaults to Lax, and an error is returned when the
+// requirements of the __Host- and __Secure- name prefixes are not met, like
+// by SetCookieSpec.
+func (c *Context) SetSignedCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) error {
+	keys := c.engine.cookieKeys
+	if len(keys) == 0 {
+		return ErrNoCookieKeys
+	}
+	return c.setSecureCookie(name, encodeSignedCookie(keys[0].sign, name, value, cookieExpires(maxAge)), maxAge, path, domain, secure, httpOnly)
+}
+
+// encodeSignedCookie returns the value of a signed cookie: the value, its
+// expiry and their signature.
+func encodeSignedCookie(key []byte, name, value string, expires int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(value)) + "." +
+		strconv.FormatInt(expires, 10) + "." +
+		base64.RawURLEncoding.EncodeToString(signCookie(key, name, expires, value))
+}
+
+// SignedCookie returns the value of a cookie set by SetSignedCookie, or
+// http.ErrNoCookie if not found, or ErrInvalidCookie if its signature does
+// not match any of the cookie keys or it has expired.
+func (c *Context) SignedCookie(name string) (string, error) {
+	keys := c.engine.cookieKeys
+	if len(keys) == 0 {
+		return "", ErrNoCookieKeys
+	}
+	cookie, err := c.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	parts := strings.Split(cookie.Value, ".")
+	if len(parts) != 3 {
+		return "", ErrInvalidCookie
+	}
+	value, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	for _, key := range keys {
+		if hmac.Equal(mac, signCookie(key.sign, name, expires, string(value))) {
+			if cookieExpired(expires) {
+				return "", ErrInvalidCookie
+			}
+			return string(value), nil
+		}
+	}
+	return "", ErrInvalidCookie
+}
+
+// SetEncryptedCookie is like SetSignedCookie, but the value is encrypted
+// (AES-GCM) so that the client can neither read nor modify it.
+func (c *Context) SetEncryptedCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) error {
+	keys := c.engine.cookieKeys
+	if len(keys) == 0 {
+		return ErrNoCookieKeys
+	}
+	sealed, err := encryptCookie(keys[0].encrypt, name, value, cookieExpires(maxAge))
+	if err != nil {
+		return err
+	}
+	return c.setSecureCookie(name, sealed, maxAge, path, domain, secure, httpOnly)
+}
+
+// encryptCookie returns the value of an encrypted cookie: the nonce and the
+// sealed expiry and value.
+func encryptCookie(aead cipher.AEAD, name, value string, expires int64) (string, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+8+len(value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	plaintext := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint64(plaintext, uint64(expires))
+	plaintext = append(plaintext, value...)
+	// the cookie name is authenticated, so that values can not be swapped between cookies.
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, []byte(name))), nil
+}
+
+// EncryptedCookie returns the value of a cookie set by SetEncryptedCookie, or
+// http.ErrNoCookie if not found, or ErrInvalidCookie if it can not be
+// decrypted with any of the cookie keys or it has expired.
+func (c *Context) EncryptedCookie(name string) (string, error) {
+	keys := c.engine.cookieKeys
+	if len(keys) == 0 {
+		return "", ErrNoCookieKeys
+	}
+	cookie, err := c.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	for _, key := range keys {
+		size := key.encrypt.NonceSize()
+		if len(sealed) < size {
+			break
+		}
+		plaintext, err := key.encrypt.Open(nil, sealed[:size], sealed[size:], []byte(name))
+		if err != nil {
+			continue
+		}
+		if len(plaintext) < 8 || cookieExpired(int64(binary.BigEndian.Uint64(plaintext))) {
+			return "", ErrInvalidCookie
+		}
+		return string(plaintext[8:]), nil
+	}
+	return "", ErrInvalidCookie
+}
+
+// setSecureCookie adds a Set-Cookie header for the cookie, whose SameSite
+// defaults to Lax and path to "/", or returns an error if the requirements of
+// its name prefix are not met, see checkCookiePrefix.
+func (c *Context) setSecureCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) error {
+	if path == "" {
+		path = "/"
+	}
+	if err := checkCookiePrefix(name, path, domain, secure); err != nil {
+		return err
+	}
+	sameSite := c.sameSite
+	if sameSite == 0 {
+		sameSite = http.SameSiteLaxMode
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		MaxAge:   maxAge,
+		Path:     path,
+		Domain:   domain,
+		SameSite: sameSite,
+		Secure:   secure,
+		HttpOnly: httpOnly,
+	})
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cookieRoundTrip(t *testing.T, engine *Engine, set func(c *Context) error, get func(c *Context) (string, error)) (*http.Cookie, string, error) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.engine = engine
+	require.NoError(t, set(c))
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	c, _ = CreateTestContext(httptest.NewRecorder())
+	c.engine = engine
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.AddCookie(cookies[0])
+	value, err := get(c)
+	return cookies[0], value, err
+}
+
+func TestSignedCookie(t *testing.T) {
+	engine := New()
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	assert.ErrorIs(t, c.SetSignedCookie("a", "b", 0, "", "", false, false), ErrNoCookieKeys)
+
+	require.NoError(t, engine.SetCookieKeys([]byte("old key")))
+	set := func(c *Context) error { return c.SetSignedCookie("user", "gopher", 60, "", "", false, true) }
+	cookie, value, err := cookieRoundTrip(t, engine, set, func(c *Context) (string, error) { return c.SignedCookie("user") })
+	require.NoError(t, err)
+	assert.Equal(t, "gopher", value)
+	assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+	assert.Equal(t, "/", cookie.Path)
+
+	// rotation: cookies signed with the previous key are still accepted.
+	require.NoError(t, engine.SetCookieKeys([]byte("new key"), []byte("old key")))
+	c, _ = CreateTestContext(httptest.NewRecorder())
+	c.engine = engine
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.AddCookie(cookie)
+	value, err = c.SignedCookie("user")
+	require.NoError(t, err)
+	assert.Equal(t, "gopher", value)
+
+	// tampering
+	tampered := *cookie
+	tampered.Value = "YWRtaW4" + cookie.Value[strings.Index(cookie.Value, "."):]
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.AddCookie(&tampered)
+	_, err = c.SignedCookie("user")
+	assert.ErrorIs(t, err, ErrInvalidCookie)
+
+	_, err = c.SignedCookie("missing")
+	assert.ErrorIs(t, err, http.ErrNoCookie)
+}
+
+func TestEncryptedCookie(t *testing.T) {
+	engine := New()
+	require.NoError(t, engine.SetCookieKeys([]byte("secret")))
+	set := func(c *Context) error {
+		return c.SetEncryptedCookie("__Host-csrf", "token", 0, "", "", true, true)
+	}
+	cookie, value, err := cookieRoundTrip(t, engine, set, func(c *Context) (string, error) { return c.EncryptedCookie("__Host-csrf") })
+	require.NoError(t, err)
+	assert.Equal(t, "token", value)
+	assert.NotContains(t, cookie.Value, "token")
+	assert.True(t, cookie.Secure)
+	assert.Equal(t, "/", cookie.Path)
+	assert.Empty(t, cookie.Domain)
+
+	// the requirements of the name prefixes are checked like by SetCookieSpec.
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.engine = engine
+	assert.Equal(t, errHostCookiePrefix, c.SetEncryptedCookie("__Host-csrf", "token", 0, "/admin", "", true, true))
+	assert.Equal(t, errHostCookiePrefix, c.SetEncryptedCookie("__Host-csrf", "token", 0, "/", "example.com", true, true))
+	assert.Equal(t, errHostCookiePrefix, c.SetSignedCookie("__Host-csrf", "token", 0, "/", "", false, true))
+	assert.Equal(t, errSecureCookiePrefix, c.SetSignedCookie("__Secure-id", "token", 0, "/", "example.com", false, true))
+	assert.Empty(t, w.Header().Values("Set-Cookie"))
+
+	// a value encrypted for another cookie name is rejected.
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.AddCookie(&http.Cookie{Name: "other", Value: cookie.Value})
+	_, err = c.EncryptedCookie("other")
+	assert.ErrorIs(t, err, ErrInvalidCookie)
+
+	require.NoError(t, engine.SetCookieKeys([]byte("rotated")))
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.AddCookie(cookie)
+	_, err = c.EncryptedCookie("__Host-csrf")
+	assert.ErrorIs(t, err, ErrInvalidCookie)
+}
+
+func TestSignedAndEncryptedCookieExpiry(t *testing.T) {
+	engine := New()
+	require.NoError(t, engine.SetCookieKeys([]byte("secret")))
+	key := engine.cookieKeys[0]
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.engine = engine
+	request := func(value string) {
+		c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+		c.Request.AddCookie(&http.Cookie{Name: "session", Value: value})
+	}
+
+	past := time.Now().Add(-time.Second).Unix()
+	request(encodeSignedCookie(key.sign, "session", "gopher", past))
+	_, err := c.SignedCookie("session")
+	assert.ErrorIs(t, err, ErrInvalidCookie)
+
+	// the expiry is signed.
+	future := time.Now().Add(time.Hour).Unix()
+	signed := encodeSignedCookie(key.sign, "session", "gopher", past)
+	parts := strings.Split(signed, ".")
+	request(parts[0] + "." + strconv.FormatInt(future, 10) + "." + parts[2])
+	_, err = c.SignedCookie("session")
+	assert.ErrorIs(t, err, ErrInvalidCookie)
+
+	request(encodeSignedCookie(key.sign, "session", "gopher", future))
+	value, err := c.SignedCookie("session")
+	require.NoError(t, err)
+	assert.Equal(t, "gopher", value)
+
+	sealed, err := encryptCookie(key.encrypt, "session", "gopher", past)
+	require.NoError(t, err)
+	request(sealed)
+	_, err = c.EncryptedCookie("session")
+	assert.ErrorIs(t, err, ErrInvalidCookie)
+
+	sealed, err = encryptCookie(key.encrypt, "session", "gopher", 0)
+	require.NoError(t, err)
+	request(sealed)
+	value, err = c.EncryptedCookie("session")
+	require.NoError(t, err)
+	assert.Equal(t, "gopher", value)
+
+	assert.Zero(t, cookieExpires(0))
+	assert.InDelta(t, time.Now().Add(time.Minute).Unix(), cookieExpires(60), 1)
+}
+
+func TestContextSetCookieSpec(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.SetSameSite(http.SameSiteNoneMode)
+
+	assert.NoError(t, c.SetCookieSpec(Cookie{
+		Name:        "__Host-id",
+		Value:       "a b",
+		MaxAge:      60,
+		Secure:      true,
+		HttpOnly:    true,
+		Partitioned: true,
+	}))
+	assert.Equal(t, "__Host-id=a+b; Path=/; Max-Age=60; HttpOnly; Secure; SameSite=None; Partitioned", w.Header().Get("Set-Cookie"))
+
+	assert.Equal(t, errHostCookiePrefix, c.SetCookieSpec(Cookie{Name: "__Host-id", Secure: true, Domain: "example.com"}))
+	assert.Equal(t, errHostCookiePrefix, c.SetCookieSpec(Cookie{Name: "__Host-id", Secure: true, Path: "/admin"}))
+	assert.Equal(t, errSecureCookiePrefix, c.SetCookieSpec(Cookie{Name: "__Secure-id"}))
+	assert.Equal(t, errPartitionedCookie, c.SetCookieSpec(Cookie{Name: "id", Partitioned: true}))
+	assert.Equal(t, errInvalidCookie, c.SetCookieSpec(Cookie{Name: "in valid"}))
+	assert.Len(t, w.Header().Values("Set-Cookie"), 1)
+}
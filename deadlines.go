@@ -0,0 +1,53 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"time"
+)
+
+// SetReadDeadline sets the deadline for reading the request body, overriding the
+// ReadTimeout of the http.Server for this request. A zero value means no
+// deadline. See http.ResponseController.SetReadDeadline.
+func (c *Context) SetReadDeadline(deadline time.Time) error {
+	return http.NewResponseController(c.Writer).SetReadDeadline(deadline)
+}
+
+// SetWriteDeadline sets the deadline for writing the response, overriding the
+// WriteTimeout of the http.Server for this request, e.g. to extend it while
+// streaming. A zero value means no deadline. See
+// http.ResponseController.SetWriteDeadline.
+func (c *Context) SetWriteDeadline(deadline time.Time) error {
+	return http.NewResponseController(c.Writer).SetWriteDeadline(deadline)
+}
+
+// Deadlines returns a middleware which gives the requests read and write
+// from their start to read the body and write the response, overriding the
+// ReadTimeout and WriteTimeout of the http.Server, so that the long-streaming
+// routes (SSE, downloads) can outlive the tight timeouts of the API routes:
+//
+//	srv := &http.Server{Handler: router, ReadTimeout: 5 * time.Second, WriteTimeout: 10 * time.Second}
+//	router.GET("/events", gin.Deadlines(0, time.Hour), streamEvents)
+//	uploads := router.Group("/uploads", gin.Deadlines(10*time.Minute, 0))
+//
+// A zero duration keeps the timeout of the server. The deadlines are ignored by
+// the response writers which don't support them.
+func Deadlines(read, write time.Duration) HandlerFunc {
+	return func(c *Context) {
+		if read > 0 {
+			_ = c.SetReadDeadline(time.Now().Add(read))
+		}
+		if write > 0 {
+			_ = c.SetWriteDeadline(time.Now().Add(write))
+		}
+		c.Next()
+		// without WriteTimeout, the server won't reset the deadline of the
+		// next request on the connection
+		if srv, ok := c.Request.Context().Value(http.ServerContextKey).(*http.Server); ok && write > 0 && srv.WriteTimeout <= 0 {
+			_ = c.SetWriteDeadline(time.Time{})
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadlines(t *testing.T) {
+	router := New()
+	slow := func(c *Context) {
+		time.Sleep(100 * time.Millisecond)
+		c.String(http.StatusOK, "done")
+	}
+	router.GET("/api", slow)
+	router.GET("/stream", Deadlines(0, time.Second), slow)
+
+	srv := httptest.NewUnstartedServer(router)
+	srv.Config.WriteTimeout = 20 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	get := func(path string) (string, error) {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+	_, err := get("/api")
+	assert.Error(t, err)
+	body, err := get("/stream")
+	require.NoError(t, err)
+	assert.Equal(t, "done", body)
+}
+
+func TestDeadlinesWithoutServerTimeout(t *testing.T) {
+	router := New()
+	router.GET("/stream", Deadlines(time.Second, 20*time.Millisecond), func(c *Context) {
+		c.String(http.StatusOK, "done")
+	})
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	// the deadline does not leak to the next request on the connection
+	client := srv.Client()
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(srv.URL + "/stream")
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "done", string(body))
+		time.Sleep(40 * time.Millisecond)
+	}
+
+	w := PerformRequest(router, http.MethodGet, "/stream")
+	assert.Equal(t, "done", w.Body.String())
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	assert.ErrorIs(t, c.SetWriteDeadline(time.Now()), http.ErrNotSupported)
+}
@@ -5,26 +5,26 @@
 package gin
 
 import (
-	"fmt"
 	"html/template"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 )
 
-const ginSupportMinGoVer = 18
+const ginSupportMinGoVer = 20
 
 // IsDebugging returns true if the framework is running in debug mode.
 // Use SetMode(gin.ReleaseMode) to disable debug mode.
 func IsDebugging() bool {
-	return ginMode == debugCode
+	return ginMode.Load() == debugCode
 }
 
 // DebugPrintRouteFunc indicates debug log output format.
 var DebugPrintRouteFunc func(httpMethod, absolutePath, handlerName string, nuHandlers int)
 
 func debugPrintRoute(httpMethod, absolutePath string, handlers HandlersChain) {
-	if IsDebugging() {
+	if debugPrintEnabled() {
 		nuHandlers := len(handlers)
 		handlerName := nameOfFunction(handlers.Last())
 		if DebugPrintRouteFunc == nil {
@@ -36,7 +36,7 @@ func debugPrintRoute(httpMethod, absolutePath string, handlers HandlersChain) {
 }
 
 func debugPrintLoadTemplate(tmpl *template.Template) {
-	if IsDebugging() {
+	if debugPrintEnabled() {
 		var buf strings.Builder
 		for _, tmpl := range tmpl.Templates() {
 			buf.WriteString("\t- ")
@@ -47,15 +47,35 @@ func debugPrintLoadTemplate(tmpl *template.Template) {
 	}
 }
 
-func debugPrint(format string, values ...any) {
-	if IsDebugging() {
-		if !strings.HasSuffix(format, "\n") {
-			format += "\n"
+func debugPrintLoadLayoutPages(pages []string) {
+	if debugPrintEnabled() {
+		sort.Strings(pages)
+		var buf strings.Builder
+		for _, page := range pages {
+			buf.WriteString("\t- ")
+			buf.WriteString(page)
+			buf.WriteString("\n")
 		}
-		fmt.Fprintf(DefaultWriter, "[GIN-debug] "+format, values...)
+		debugPrint("Loaded HTML Pages with layouts (%d): \n%s\n", len(pages), buf.String())
 	}
 }
 
+func debugPrintEnabled() bool {
+	return CurrentLogLevel() <= LogLevelDebug
+}
+
+func debugPrint(format string, values ...any) {
+	logf(LogLevelDebug, format, values...)
+}
+
+func debugPrintInfo(format string, values ...any) {
+	logf(LogLevelInfo, format, values...)
+}
+
+func debugPrintWarning(format string, values ...any) {
+	logf(LogLevelWarn, format, values...)
+}
+
 func getMinVer(v string) (uint64, error) {
 	first := strings.IndexByte(v, '.')
 	last := strings.LastIndexByte(v, '.')
@@ -67,17 +87,17 @@ func getMinVer(v string) (uint64, error) {
 
 func debugPrintWARNINGDefault() {
 	if v, e := getMinVer(runtime.Version()); e == nil && v < ginSupportMinGoVer {
-		debugPrint(`[WARNING] Now Gin requires Go 1.18+.
+		debugPrintWarning(`Now Gin requires Go 1.20+.
 
 `)
 	}
-	debugPrint(`[WARNING] Creating an Engine instance with the Logger and Recovery middleware already attached.
+	debugPrintWarning(`Creating an Engine instance with the Logger and Recovery middleware already attached.
 
 `)
 }
 
 func debugPrintWARNINGNew() {
-	debugPrint(`[WARNING] Running in "debug" mode. Switch to "release" mode in production.
+	debugPrintWarning(`Running in "debug" mode. Switch to "release" mode in production.
  - using env:	export GIN_MODE=release
  - using code:	gin.SetMode(gin.ReleaseMode)
 
@@ -85,7 +105,7 @@ func debugPrintWARNINGNew() {
 }
 
 func debugPrintWARNINGSetHTMLTemplate() {
-	debugPrint(`[WARNING] Since SetHTMLTemplate() is NOT thread-safe. It should only be called
+	debugPrintWarning(`Since SetHTMLTemplate() is NOT thread-safe. It should only be called
 at initialization. ie. before any route is registered or the router is listening in a socket:
 
 	router := gin.Default()
@@ -94,8 +114,18 @@ at initialization. ie. before any route is registered or the router is listening
 `)
 }
 
+func debugPrintWARNINGSetHTMLRenderer() {
+	debugPrintWarning(`Since SetHTMLRenderer() is NOT thread-safe. It should only be called
+at initialization. ie. before any route is registered or the router is listening in a socket:
+
+	router := gin.Default()
+	router.SetHTMLRenderer(renderer) // << good place
+
+`)
+}
+
 func debugPrintError(err error) {
-	if err != nil && IsDebugging() {
-		fmt.Fprintf(DefaultErrorWriter, "[GIN-debug] [ERROR] %v\n", err)
+	if err != nil {
+		logf(LogLevelError, "%v", err)
 	}
 }
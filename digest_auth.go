@@ -0,0 +1,200 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DigestAuthConfig defines the config of the DigestAuthWithConfig middleware.
+type DigestAuthConfig struct {
+	// Realm is the name of the protection space, which is part of the hashed
+	// credentials.
+	// Optional. Default value is "Authorization Required".
+	Realm string
+
+	// Credentials returns the hash of the credentials of a user, that is
+	// DigestHA1(user, realm, password), so that the passwords do not have to be
+	// stored in clear.
+	// Required.
+	Credentials func(user, realm string) (ha1 string, ok bool)
+
+	// NonceTTL is the lifetime of the nonces sent to the clients. Once expired,
+	// the clients are asked to authenticate again with a new nonce.
+	// Optional. Default value is 5 minutes.
+	NonceTTL time.Duration
+}
+
+// DigestAuth returns a Digest HTTP Authorization middleware (RFC 7616, with the
+// MD5 algorithm), for legacy clients which do not support Basic authorization
+// over TLS. It takes as argument a map[string]string where the key is the user
+// name and the value is the password. The user name can be read with
+// Context.AuthUser.
+func DigestAuth(accounts Accounts) HandlerFunc {
+	return DigestAuthForRealm(accounts, "")
+}
+
+// DigestAuthForRealm is DigestAuth with the name of the Realm.
+func DigestAuthForRealm(accounts Accounts, realm string) HandlerFunc {
+	assert1(len(accounts) > 0, "Empty list of authorized credentials")
+	return DigestAuthWithConfig(DigestAuthConfig{
+		Realm: realm,
+		Credentials: func(user, realm string) (string, bool) {
+			password, ok := accounts[user]
+			if !ok {
+				return "", false
+			}
+			return DigestHA1(user, realm, password), true
+		},
+	})
+}
+
+// DigestHA1 returns the hash of the credentials of a user for DigestAuthConfig.
+func DigestHA1(user, realm, password string) string {
+	return md5Hex(user + ":" + realm + ":" + password)
+}
+
+// DigestAuthWithConfig returns a Digest HTTP Authorization middleware, see DigestAuth.
+//
+// Nonces are stateless: they carry their creation time, signed with a random key
+// of the middleware, so they are only valid for the instance which issued them.
+// The nonce count is not tracked, replays are only limited by NonceTTL.
+func DigestAuthWithConfig(config DigestAuthConfig) HandlerFunc {
+	assert1(config.Credentials != nil, "DigestAuthWithConfig requires Credentials")
+	if config.Realm == "" {
+		config.Realm = defaultAuthRealm
+	}
+	if config.NonceTTL <= 0 {
+		config.NonceTTL = 5 * time.Minute
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	nonces := digestNonces{key: key, ttl: config.NonceTTL}
+
+	return func(c *Context) {
+		params, ok := parseDigestAuthorization(c.requestHeader("Authorization"))
+		stale := false
+		if ok {
+			ok, stale = checkDigest(c, config, nonces, params)
+		}
+		if !ok {
+			challenge := "Digest realm=" + strconv.Quote(config.Realm) +
+				`, qop="auth", algorithm=MD5, nonce="` + nonces.issue() + `"`
+			if stale {
+				challenge += ", stale=true"
+			}
+			c.Header("WWW-Authenticate", challenge)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Set(AuthUserKey, params["username"])
+	}
+}
+
+// checkDigest checks the response of the client. stale is true when the response
+// is valid but the nonce has expired.
+func checkDigest(c *Context, config DigestAuthConfig, nonces digestNonces, params map[string]string) (ok, stale bool) {
+	uri := c.Request.RequestURI
+	if uri == "" {
+		uri = c.Request.URL.RequestURI()
+	}
+	if params["realm"] != config.Realm || params["uri"] != uri {
+		return false, false
+	}
+	if alg := params["algorithm"]; alg != "" && !strings.EqualFold(alg, "MD5") {
+		return false, false
+	}
+	issued, valid := nonces.check(params["nonce"])
+	if !valid {
+		return false, false
+	}
+	ha1, found := config.Credentials(params["username"], config.Realm)
+	if !found {
+		return false, false
+	}
+
+	ha2 := md5Hex(c.Request.Method + ":" + params["uri"])
+	var expected string
+	switch params["qop"] {
+	case "auth":
+		expected = md5Hex(ha1 + ":" + params["nonce"] + ":" + params["nc"] + ":" + params["cnonce"] + ":auth:" + ha2)
+	case "":
+		// RFC 2069 clients.
+		expected = md5Hex(ha1 + ":" + params["nonce"] + ":" + ha2)
+	default:
+		return false, false
+	}
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(params["response"]))) != 1 {
+		return false, false
+	}
+	if time.Since(issued) > nonces.ttl {
+		return false, true
+	}
+	return true, false
+}
+
+// parseDigestAuthorization parses the parameters of a Digest Authorization header.
+func parseDigestAuthorization(header string) (map[string]string, bool) {
+	scheme, rest, _ := strings.Cut(header, " ")
+	if !strings.EqualFold(scheme, "Digest") {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for _, pair := range splitForwarded(rest, ',') {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		v = strings.TrimSpace(v)
+		if unquoted, err := strconv.Unquote(v); err == nil {
+			v = unquoted
+		}
+		params[strings.ToLower(strings.TrimSpace(k))] = v
+	}
+	return params, params["username"] != "" && params["nonce"] != "" && params["response"] != ""
+}
+
+// digestNonces issues nonces made of their creation time and a signature.
+type digestNonces struct {
+	key []byte
+	ttl time.Duration
+}
+
+func (n digestNonces) issue() string {
+	b := binary.BigEndian.AppendUint64(nil, uint64(time.Now().UnixNano()))
+	return base64.RawURLEncoding.EncodeToString(append(b, n.sign(b)...))
+}
+
+func (n digestNonces) check(nonce string) (time.Time, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(nonce)
+	if err != nil || len(b) <= 8 || !hmac.Equal(b[8:], n.sign(b[:8])) {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(b))), true
+}
+
+func (n digestNonces) sign(b []byte) []byte {
+	mac := hmac.New(sha256.New, n.key)
+	mac.Write(b)
+	return mac.Sum(nil)[:16]
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var digestNonceRe = regexp.MustCompile(`nonce="([^"]+)"`)
+
+func digestAuthorization(user, password, realm, method, uri, nonce string) string {
+	ha1 := DigestHA1(user, realm, password)
+	ha2 := md5Hex(method + ":" + uri)
+	response := md5Hex(ha1 + ":" + nonce + ":00000001:abcdef:auth:" + ha2)
+	return fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s", qop=auth, nc=00000001, cnonce="abcdef", response="%s", algorithm=MD5`,
+		user, realm, nonce, uri, response)
+}
+
+func TestDigestAuth(t *testing.T) {
+	router := New()
+	router.Use(DigestAuthForRealm(Accounts{"admin": "password"}, "test"))
+	router.GET("/login", func(c *Context) {
+		c.String(http.StatusOK, c.AuthUser())
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/login?x=1", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	challenge := w.Header().Get("WWW-Authenticate")
+	assert.Contains(t, challenge, `Digest realm="test", qop="auth", algorithm=MD5`)
+	nonce := digestNonceRe.FindStringSubmatch(challenge)[1]
+
+	w = httptest.NewRecorder()
+	req.Header.Set("Authorization", digestAuthorization("admin", "password", "test", http.MethodGet, "/login?x=1", nonce))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "admin", w.Body.String())
+
+	for _, auth := range []string{
+		digestAuthorization("admin", "wrong", "test", http.MethodGet, "/login?x=1", nonce),
+		digestAuthorization("admin", "password", "other", http.MethodGet, "/login?x=1", nonce),
+		digestAuthorization("admin", "password", "test", http.MethodGet, "/other", nonce),
+		digestAuthorization("admin", "password", "test", http.MethodGet, "/login?x=1", "forged"),
+		digestAuthorization("root", "password", "test", http.MethodGet, "/login?x=1", nonce),
+		"Basic YWRtaW46cGFzc3dvcmQ=",
+	} {
+		w = httptest.NewRecorder()
+		req.Header.Set("Authorization", auth)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, auth)
+		assert.NotContains(t, w.Header().Get("WWW-Authenticate"), "stale")
+	}
+}
+
+func TestDigestAuthStaleNonce(t *testing.T) {
+	router := New()
+	router.Use(DigestAuthWithConfig(DigestAuthConfig{
+		Credentials: func(user, realm string) (string, bool) {
+			return DigestHA1(user, realm, "password"), user == "admin"
+		},
+		NonceTTL: time.Millisecond,
+	}))
+	router.GET("/", func(c *Context) {})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	router.ServeHTTP(w, req)
+	nonce := digestNonceRe.FindStringSubmatch(w.Header().Get("WWW-Authenticate"))[1]
+
+	time.Sleep(5 * time.Millisecond)
+	w = httptest.NewRecorder()
+	req.Header.Set("Authorization", digestAuthorization("admin", "password", defaultAuthRealm, http.MethodGet, "/", nonce))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), "stale=true")
+}
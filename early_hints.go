@@ -0,0 +1,76 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ErrHeadersWritten is returned when an informational response is sent after
+// the final response headers.
+var ErrHeadersWritten = errors.New("gin: response headers already written")
+
+// preloadTypes maps file extensions to the destination of a preload link.
+var preloadTypes = map[string]string{
+	".css":   "style",
+	".js":    "script",
+	".mjs":   "script",
+	".woff":  "font",
+	".woff2": "font",
+	".ttf":   "font",
+	".otf":   "font",
+	".png":   "image",
+	".jpg":   "image",
+	".jpeg":  "image",
+	".gif":   "image",
+	".svg":   "image",
+	".webp":  "image",
+	".avif":  "image",
+}
+
+// EarlyHints sends a 103 Early Hints informational response with Link headers,
+// so that the browser starts loading the resources of the page while the
+// handler prepares the final response. A link is either a Link header value,
+// or a URL turned into a preload link typed after its extension:
+//
+//	c.EarlyHints("/assets/app.css", "/assets/app.js", "<https://cdn.example.com>; rel=preconnect")
+//	// Link: </assets/app.css>; rel=preload; as=style
+//	// Link: </assets/app.js>; rel=preload; as=script
+//	// Link: <https://cdn.example.com>; rel=preconnect
+//
+// The Link headers are kept in the final response. Clients older than
+// HTTP/1.1 don't receive the informational response.
+func (c *Context) EarlyHints(links ...string) error {
+	if c.writermem.Written() {
+		return ErrHeadersWritten
+	}
+	header := c.writermem.Header()
+	for _, link := range links {
+		header.Add("Link", preloadLink(link))
+	}
+	if !c.Request.ProtoAtLeast(1, 1) {
+		return nil
+	}
+	c.writermem.ResponseWriter.WriteHeader(http.StatusEarlyHints)
+	return nil
+}
+
+func preloadLink(link string) string {
+	if strings.HasPrefix(link, "<") {
+		return link
+	}
+	value := "<" + link + ">; rel=preload"
+	ext := path.Ext(strings.SplitN(link, "?", 2)[0])
+	if as, ok := preloadTypes[strings.ToLower(ext)]; ok {
+		value += "; as=" + as
+		if as == "font" {
+			value += "; crossorigin"
+		}
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextEarlyHints(t *testing.T) {
+	router := New()
+	router.GET("/", func(c *Context) {
+		assert.NoError(t, c.EarlyHints("/app.css", "/app.js?v=2", "/font.WOFF2", "/data.json", "<https://cdn.example.com>; rel=preconnect"))
+		c.String(http.StatusOK, "page")
+		assert.Equal(t, ErrHeadersWritten, c.EarlyHints("/late.css"))
+	})
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	var hints []textproto.MIMEHeader
+	trace := &httptrace.ClientTrace{Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+		assert.Equal(t, http.StatusEarlyHints, code)
+		hints = append(hints, header)
+		return nil
+	}}
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	links := []string{
+		"</app.css>; rel=preload; as=style",
+		"</app.js?v=2>; rel=preload; as=script",
+		"</font.WOFF2>; rel=preload; as=font; crossorigin",
+		"</data.json>; rel=preload",
+		"<https://cdn.example.com>; rel=preconnect",
+	}
+	require.Len(t, hints, 1)
+	assert.Equal(t, links, hints[0]["Link"])
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, links, resp.Header["Link"])
+}
+
+func TestContextEarlyHintsHTTP10(t *testing.T) {
+	router := New()
+	router.GET("/", func(c *Context) {
+		assert.NoError(t, c.EarlyHints("/app.css"))
+		c.String(http.StatusOK, "page")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Proto, req.ProtoMinor = "HTTP/1.0", 0
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "</app.css>; rel=preload; as=style", w.Header().Get("Link"))
+}
@@ -0,0 +1,285 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/internal/json"
+	"gopkg.in/yaml.v3"
+)
+
+// EngineConfig holds the settings of an Engine which can be changed while
+// serving, without a restart, see Engine.ApplyConfig. It can be decoded from
+// JSON or YAML, see Engine.WatchConfig.
+type EngineConfig struct {
+	// TrustedProxies replaces the proxies trusted to set the client IP, see
+	// Engine.SetTrustedProxies. An empty list trusts no proxy.
+	// Optional. Default value is nil, the proxies set with SetTrustedProxies or TrustProxies are trusted.
+	TrustedProxies []string `json:"trusted_proxies" yaml:"trusted_proxies"`
+
+	// LogLevel sets the level of the messages gin prints about itself, see SetLogLevel.
+	// Optional. Default value is nil, the level is left unchanged.
+	LogLevel *LogLevel `json:"log_level" yaml:"log_level"`
+
+	// RateLimit is the rate of the requests of each client IP allowed by the
+	// Engine.RateLimit middleware.
+	// Optional. Default value is the zero TenantRate, the requests are not limited.
+	RateLimit TenantRate `json:"rate_limit" yaml:"rate_limit"`
+
+	// Maintenance enables, or disables with Disabled, the maintenance mode, see
+	// Engine.SetMaintenance.
+	// Optional. Default value is nil, the maintenance mode is left unchanged.
+	Maintenance *MaintenanceConfig `json:"maintenance" yaml:"maintenance"`
+
+	// CORSOrigins are the origins allowed by the Engine.CORS middleware, e.g.
+	// "https://app.example.com", "*" allowing any origin.
+	// Optional. Default value is nil, no cross-origin request is allowed.
+	CORSOrigins []string `json:"cors_origins" yaml:"cors_origins"`
+}
+
+// MaintenanceConfig is the maintenance mode of an EngineConfig, see MaintenanceOptions.
+type MaintenanceConfig struct {
+	// Disabled disables the maintenance mode, the other fields are then ignored.
+	Disabled bool `json:"disabled" yaml:"disabled"`
+
+	AllowPaths []string `json:"allow_paths" yaml:"allow_paths"`
+	AllowIPs   []string `json:"allow_ips" yaml:"allow_ips"`
+	// RetryAfter is a number of seconds.
+	RetryAfter int    `json:"retry_after" yaml:"retry_after"`
+	Message    string `json:"message" yaml:"message"`
+}
+
+// appliedConfig is the EngineConfig applied by ApplyConfig, prepared for the
+// requests.
+type appliedConfig struct {
+	config        EngineConfig
+	trustedCIDRs  []*net.IPNet
+	limiter       *tenantLimiter
+	corsOrigins   map[string]bool
+	corsAnyOrigin bool
+}
+
+// ApplyConfig replaces the reloadable settings of the engine with the ones of
+// config, e.g. from a configuration file edited by the operators, see
+// WatchConfig. It is safe to call while serving: config is validated first,
+// and nothing is applied if it is invalid; otherwise the requests see either
+// the previous settings or the new ones, swapped atomically. The settings
+// omitted in config get their default value, except the log level and the
+// maintenance mode, which are left unchanged, so that reloading the config
+// does not end a maintenance enabled by SetMaintenance.
+func (engine *Engine) ApplyConfig(config EngineConfig) error {
+	applied := &appliedConfig{
+		config:      config,
+		limiter:     newTenantLimiter(config.RateLimit, nil, defaultRateLimitClients),
+		corsOrigins: make(map[string]bool, len(config.CORSOrigins)),
+	}
+	if config.TrustedProxies != nil {
+		cidrs, err := parseCIDRs(config.TrustedProxies)
+		if err != nil {
+			return fmt.Errorf("gin: invalid trusted proxies: %w", err)
+		}
+		applied.trustedCIDRs = cidrs
+	}
+	if config.Maintenance != nil && !config.Maintenance.Disabled {
+		if _, err := parseCIDRs(config.Maintenance.AllowIPs); err != nil {
+			return fmt.Errorf("gin: invalid maintenance IPs: %w", err)
+		}
+	}
+	for _, origin := range config.CORSOrigins {
+		if origin == "*" {
+			applied.corsAnyOrigin = true
+		}
+		applied.corsOrigins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+	}
+
+	engine.config.Store(applied)
+	if config.LogLevel != nil {
+		SetLogLevel(*config.LogLevel)
+	}
+	switch m := config.Maintenance; {
+	case m == nil:
+	case m.Disabled:
+		_ = engine.SetMaintenance(false, MaintenanceOptions{})
+	default:
+		// cannot fail, the IPs are valid.
+		_ = engine.SetMaintenance(true, MaintenanceOptions{
+			AllowPaths: m.AllowPaths,
+			AllowIPs:   m.AllowIPs,
+			RetryAfter: time.Duration(m.RetryAfter) * time.Second,
+			Message:    m.Message,
+		})
+	}
+	return nil
+}
+
+// AppliedConfig returns the config applied by ApplyConfig, if any.
+func (engine *Engine) AppliedConfig() (EngineConfig, bool) {
+	if applied := engine.config.Load(); applied != nil {
+		return applied.config, true
+	}
+	return EngineConfig{}, false
+}
+
+// currentTrustedProxies returns the trusted proxies of the applied config, if
+// any, or the ones of TrustProxies or SetTrustedProxies.
+func (engine *Engine) currentTrustedProxies() []string {
+	if applied := engine.config.Load(); applied != nil && applied.config.TrustedProxies != nil {
+		return applied.config.TrustedProxies
+	}
+	if presets := engine.presets.Load(); presets != nil {
+		return presets.proxies
+	}
+	return engine.trustedProxies
+}
+
+// defaultRateLimitClients is the number of clients whose bucket is kept by
+// Engine.RateLimit.
+const defaultRateLimitClients = 100000
+
+// RateLimit returns a middleware limiting the rate of the requests of each
+// client IP to EngineConfig.RateLimit, as applied by ApplyConfig, with a token
+// bucket per client. The requests over the limit are answered 429 Too Many
+// Requests with a Retry-After header. The buckets are reset when a config is
+// applied, removed once full again, and at most the buckets of the 100000 most
+// recent clients are kept.
+func (engine *Engine) RateLimit() HandlerFunc {
+	return func(c *Context) {
+		applied := engine.config.Load()
+		if applied == nil {
+			return
+		}
+		if wait, ok := applied.limiter.allow(c.ClientIP(), time.Now()); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatus(http.StatusTooManyRequests)
+		}
+	}
+}
+
+// CORS returns a middleware allowing the cross-origin requests from the
+// EngineConfig.CORSOrigins, as applied by ApplyConfig, with their credentials
+// unless any origin is allowed. The preflight requests of these origins are
+// answered with a 204 No Content allowing the requested method and headers;
+// the requests of the other origins get no CORS header, so that the browsers
+// block them. It must be registered with Engine.Use for the preflight requests
+// of the routes without an OPTIONS handler to reach it.
+func (engine *Engine) CORS() HandlerFunc {
+	return func(c *Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			return
+		}
+		c.Writer.Header().Add("Vary", "Origin")
+		applied := engine.config.Load()
+		if applied == nil || (!applied.corsAnyOrigin && !applied.corsOrigins[strings.ToLower(origin)]) {
+			return
+		}
+		if applied.corsAnyOrigin {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+		method := c.GetHeader("Access-Control-Request-Method")
+		if c.Request.Method != http.MethodOptions || method == "" {
+			return
+		}
+		c.Header("Access-Control-Allow-Methods", method)
+		if headers := c.GetHeader("Access-Control-Request-Headers"); headers != "" {
+			c.Header("Access-Control-Allow-Headers", headers)
+		}
+		c.Header("Access-Control-Max-Age", "600")
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// WatchConfig applies the EngineConfig of the file at path with ApplyConfig,
+// then again every time the file changes, checked every interval (every
+// second if interval is not positive), so that the operators change the
+// settings of a running server by editing the file. The file is decoded as
+// YAML for the .yaml and .yml extensions, as JSON otherwise. The error of the
+// first load is returned; the following ones are passed to onError, or
+// printed if it is nil, and the previous settings are kept. The returned
+// function stops watching.
+func (engine *Engine) WatchConfig(path string, interval time.Duration, onError func(error)) (stop func(), err error) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	if onError == nil {
+		onError = debugPrintError
+	}
+	var last os.FileInfo
+	load := func() error {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size() {
+			return nil
+		}
+		last = info
+		config, err := readEngineConfig(path)
+		if err != nil {
+			return err
+		}
+		if err := engine.ApplyConfig(config); err != nil {
+			return err
+		}
+		debugPrintInfo("Applied the config of %s\n", path)
+		return nil
+	}
+	if err := load(); err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := load(); err != nil {
+					onError(fmt.Errorf("gin: config %s not applied: %w", path, err))
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}, nil
+}
+
+// readEngineConfig decodes the EngineConfig of the file at path, according to
+// its extension.
+func readEngineConfig(path string) (EngineConfig, error) {
+	var config EngineConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&config)
+	default:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&config)
+	}
+	return config, err
+}
@@ -0,0 +1,226 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clientIPRouter() *Engine {
+	router := New()
+	router.GET("/ip", func(c *Context) {
+		c.String(http.StatusOK, c.ClientIP())
+	})
+	return router
+}
+
+func TestApplyConfigTrustedProxies(t *testing.T) {
+	router := clientIPRouter()
+	require.NoError(t, router.SetTrustedProxies([]string{"10.0.0.1"}))
+	forwarded := header{"X-Forwarded-For", "203.0.113.7"}
+
+	w := PerformRequest(router, http.MethodGet, "/ip", forwarded)
+	assert.Equal(t, "192.0.2.1", w.Body.String())
+
+	require.NoError(t, router.ApplyConfig(EngineConfig{TrustedProxies: []string{"192.0.2.0/24"}}))
+	w = PerformRequest(router, http.MethodGet, "/ip", forwarded)
+	assert.Equal(t, "203.0.113.7", w.Body.String())
+	assert.Equal(t, []string{"192.0.2.0/24"}, router.adminConfig().TrustedProxies)
+
+	require.NoError(t, router.ApplyConfig(EngineConfig{TrustedProxies: []string{}}))
+	w = PerformRequest(router, http.MethodGet, "/ip", forwarded)
+	assert.Equal(t, "192.0.2.1", w.Body.String())
+
+	// the proxies set with SetTrustedProxies are trusted again.
+	require.NoError(t, router.ApplyConfig(EngineConfig{}))
+	w = PerformRequest(router, http.MethodGet, "/ip", forwarded)
+	assert.Equal(t, "192.0.2.1", w.Body.String())
+	assert.Equal(t, []string{"10.0.0.1"}, router.adminConfig().TrustedProxies)
+}
+
+func TestApplyConfigInvalid(t *testing.T) {
+	router := clientIPRouter()
+	level := LogLevelWarn
+	require.NoError(t, router.ApplyConfig(EngineConfig{CORSOrigins: []string{"https://a.example"}}))
+
+	err := router.ApplyConfig(EngineConfig{TrustedProxies: []string{"invalid"}, LogLevel: &level})
+	assert.ErrorContains(t, err, "invalid trusted proxies")
+	err = router.ApplyConfig(EngineConfig{Maintenance: &MaintenanceConfig{AllowIPs: []string{"10.0.0.0/33"}}})
+	assert.ErrorContains(t, err, "invalid maintenance IPs")
+
+	config, ok := router.AppliedConfig()
+	assert.True(t, ok)
+	assert.Equal(t, []string{"https://a.example"}, config.CORSOrigins)
+	assert.NotEqual(t, LogLevelWarn, CurrentLogLevel())
+	assert.False(t, router.InMaintenance())
+}
+
+func TestApplyConfigLogLevelAndMaintenance(t *testing.T) {
+	defer SetMode(TestMode)
+	router := clientIPRouter()
+	_, ok := router.AppliedConfig()
+	assert.False(t, ok)
+
+	level := LogLevelError
+	require.NoError(t, router.ApplyConfig(EngineConfig{
+		LogLevel: &level,
+		Maintenance: &MaintenanceConfig{
+			AllowPaths: []string{"/health"},
+			RetryAfter: 120,
+		},
+	}))
+	assert.Equal(t, LogLevelError, CurrentLogLevel())
+	w := PerformRequest(router, http.MethodGet, "/ip")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "120", w.Header().Get("Retry-After"))
+	assert.Equal(t, "Service Unavailable", w.Body.String())
+
+	// the log level and the maintenance mode are left unchanged when omitted.
+	require.NoError(t, router.ApplyConfig(EngineConfig{}))
+	assert.Equal(t, LogLevelError, CurrentLogLevel())
+	w = PerformRequest(router, http.MethodGet, "/ip")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	require.NoError(t, router.ApplyConfig(EngineConfig{Maintenance: &MaintenanceConfig{Disabled: true}}))
+	w = PerformRequest(router, http.MethodGet, "/ip")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	require.NoError(t, router.SetMaintenance(true, MaintenanceOptions{}))
+	require.NoError(t, router.ApplyConfig(EngineConfig{CORSOrigins: []string{"https://a.example"}}))
+	assert.True(t, router.InMaintenance())
+}
+
+func TestEngineRateLimit(t *testing.T) {
+	router := New()
+	router.Use(router.RateLimit())
+	router.GET("/", func(c *Context) {})
+
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, PerformRequest(router, http.MethodGet, "/").Code)
+	}
+
+	require.NoError(t, router.ApplyConfig(EngineConfig{RateLimit: TenantRate{Rate: 1, Burst: 2}}))
+	assert.Equal(t, http.StatusOK, PerformRequest(router, http.MethodGet, "/").Code)
+	assert.Equal(t, http.StatusOK, PerformRequest(router, http.MethodGet, "/").Code)
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	w = PerformRequest(router, http.MethodGet, "/", header{"X-Forwarded-For", "203.0.113.7"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	require.NoError(t, router.ApplyConfig(EngineConfig{}))
+	assert.Equal(t, http.StatusOK, PerformRequest(router, http.MethodGet, "/").Code)
+}
+
+func TestEngineCORS(t *testing.T) {
+	router := New()
+	router.Use(router.CORS())
+	router.GET("/data", func(c *Context) {
+		c.String(http.StatusOK, "data")
+	})
+	origin := header{"Origin", "https://app.example"}
+	preflight := []header{origin, {"Access-Control-Request-Method", "PUT"}, {"Access-Control-Request-Headers", "X-Token"}}
+
+	w := PerformRequest(router, http.MethodGet, "/data", origin)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+
+	require.NoError(t, router.ApplyConfig(EngineConfig{CORSOrigins: []string{"https://APP.example/"}}))
+	w = PerformRequest(router, http.MethodGet, "/data", origin)
+	assert.Equal(t, "data", w.Body.String())
+	assert.Equal(t, "https://app.example", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+
+	w = PerformRequest(router, http.MethodOptions, "/data", preflight...)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "PUT", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "X-Token", w.Header().Get("Access-Control-Allow-Headers"))
+
+	w = PerformRequest(router, http.MethodGet, "/data", header{"Origin", "https://evil.example"})
+	assert.Equal(t, "data", w.Body.String())
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	require.NoError(t, router.ApplyConfig(EngineConfig{CORSOrigins: []string{"*"}}))
+	w = PerformRequest(router, http.MethodGet, "/data", header{"Origin", "https://evil.example"})
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestApplyConfigConcurrent(t *testing.T) {
+	router := clientIPRouter()
+	router.Use(router.RateLimit(), router.CORS())
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				PerformRequest(router, http.MethodGet, "/ip", header{"X-Forwarded-For", "203.0.113.7"})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_ = router.ApplyConfig(EngineConfig{TrustedProxies: []string{"192.0.2.0/24"}, CORSOrigins: []string{"*"}})
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWatchConfig(t *testing.T) {
+	defer SetMode(TestMode)
+	router := clientIPRouter()
+	path := filepath.Join(t.TempDir(), "gin.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("trusted_proxies: []\nlog_level: warn\n"), 0o600))
+
+	errs := make(chan error, 10)
+	stop, err := router.WatchConfig(path, 10*time.Millisecond, func(err error) { errs <- err })
+	require.NoError(t, err)
+	defer stop()
+	config, _ := router.AppliedConfig()
+	assert.Equal(t, []string{}, config.TrustedProxies)
+	assert.Equal(t, LogLevelWarn, CurrentLogLevel())
+
+	require.NoError(t, os.WriteFile(path, []byte("unknown: true\n"), 0o600))
+	assert.ErrorContains(t, <-errs, "not applied")
+
+	require.NoError(t, os.WriteFile(path, []byte("cors_origins: [\"https://app.example\"]\nrate_limit: {rate: 5, burst: 10}\n"), 0o600))
+	assert.Eventually(t, func() bool {
+		config, _ := router.AppliedConfig()
+		return len(config.CORSOrigins) == 1
+	}, time.Second, 10*time.Millisecond)
+	config, _ = router.AppliedConfig()
+	assert.Equal(t, TenantRate{Rate: 5, Burst: 10}, config.RateLimit)
+}
+
+func TestWatchConfigJSON(t *testing.T) {
+	router := clientIPRouter()
+	path := filepath.Join(t.TempDir(), "gin.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"maintenance": {"message": "upgrading"}, "rate_limit": {"rate": 2}}`), 0o600))
+
+	stop, err := router.WatchConfig(path, 0, nil)
+	require.NoError(t, err)
+	stop()
+	assert.True(t, router.InMaintenance())
+	config, _ := router.AppliedConfig()
+	assert.Equal(t, "upgrading", config.Maintenance.Message)
+	assert.Equal(t, 2.0, config.RateLimit.Rate)
+
+	_, err = router.WatchConfig(filepath.Join(t.TempDir(), "missing.json"), 0, nil)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	require.NoError(t, os.WriteFile(path, []byte(`{"trusted_proxies": ["invalid"]}`), 0o600))
+	_, err = router.WatchConfig(path, 0, nil)
+	assert.ErrorContains(t, err, "invalid trusted proxies")
+}
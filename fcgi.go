@@ -0,0 +1,84 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/cgi"
+	"net/http/fcgi"
+	"os"
+	"strings"
+)
+
+// RunFastCGI serves the router with the FastCGI protocol on listener, for
+// deploying behind a web server like nginx (fastcgi_pass) or Apache
+// (mod_proxy_fcgi). With a nil listener, the connections are accepted on the
+// standard input, as when the process is spawned by the web server. The
+// warmup functions run first, see Warmup.
+// Note: this method will block the calling goroutine indefinitely unless an error happens.
+func (engine *Engine) RunFastCGI(listener net.Listener) (err error) {
+	if listener != nil {
+		debugPrintInfo("Listening and serving FastCGI on %s\n", listener.Addr())
+	} else {
+		debugPrintInfo("Serving FastCGI on stdin\n")
+	}
+	defer func() { debugPrintError(err) }()
+
+	if engine.isUnsafeTrustedProxies() {
+		debugPrintWarning("You trusted all proxies, this is NOT safe. We recommend you to set a value.\n" +
+			"Please check https://pkg.go.dev/github.com/gin-gonic/gin#readme-don-t-trust-all-proxies for details.")
+	}
+
+	if err = engine.RunWarmup(context.Background()); err != nil {
+		if listener != nil {
+			listener.Close()
+		}
+		return
+	}
+	err = fcgi.Serve(listener, engine)
+	return
+}
+
+// RunCGI serves the request of the CGI environment of the process with the
+// router, e.g. on shared hosting, and returns once it is answered. The routes
+// are matched against the path following the name of the script, e.g. "/users"
+// for "/cgi-bin/app.cgi/users", or against PATH_INFO when the request URI was
+// rewritten without the name of the script. The warmup functions run first, see Warmup,
+// for each request: keep them short. The standard output holding the
+// response, the logs must be written to another writer, e.g. with
+// gin.DefaultWriter = os.Stderr set before the engine is created.
+func (engine *Engine) RunCGI() (err error) {
+	defer func() { debugPrintError(err) }()
+
+	if err = engine.RunWarmup(context.Background()); err != nil {
+		return
+	}
+	err = cgi.Serve(cgiHandler(engine, os.Getenv("SCRIPT_NAME"), os.Getenv("PATH_INFO")))
+	return
+}
+
+// cgiHandler routes the requests of a CGI script with the path following the
+// script name, or with the path info when the request URI does not start with
+// the script name, e.g. once rewritten by the web server.
+func cgiHandler(engine http.Handler, scriptName, pathInfo string) http.Handler {
+	script := strings.TrimSuffix(scriptName, "/")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := strings.CutPrefix(r.URL.Path, script)
+		switch {
+		case script != "" && ok && (p == "" || p[0] == '/'):
+			r.URL.Path = p
+			r.URL.RawPath = strings.TrimPrefix(r.URL.RawPath, script)
+		case pathInfo != "":
+			r.URL.Path = pathInfo
+			r.URL.RawPath = ""
+		}
+		if r.URL.Path == "" {
+			r.URL.Path = "/"
+		}
+		engine.ServeHTTP(w, r)
+	})
+}
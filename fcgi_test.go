@@ -0,0 +1,159 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fcgiRequest sends a FastCGI request with params and body on conn, like a web
+// server would, and returns the status, the headers and the body of the answer.
+func fcgiRequest(t *testing.T, conn net.Conn, params map[string]string, body string) (string, textproto.MIMEHeader, string) {
+	record := func(typ uint8, content []byte) {
+		header := []byte{1, typ, 0, 1, 0, 0, 0, 0}
+		binary.BigEndian.PutUint16(header[4:], uint16(len(content)))
+		_, err := conn.Write(append(header, content...))
+		require.NoError(t, err)
+	}
+	record(1, []byte{0, 1, 0, 0, 0, 0, 0, 0}) // FCGI_BEGIN_REQUEST, responder
+	var encoded []byte
+	for name, value := range params {
+		encoded = append(encoded, byte(len(name)), byte(len(value)))
+		encoded = append(encoded, name+value...)
+	}
+	record(4, encoded) // FCGI_PARAMS
+	record(4, nil)
+	if body != "" {
+		record(5, []byte(body)) // FCGI_STDIN
+	}
+	record(5, nil)
+
+	var stdout bytes.Buffer
+	for {
+		var header [8]byte
+		_, err := io.ReadFull(conn, header[:])
+		require.NoError(t, err)
+		content := make([]byte, int(binary.BigEndian.Uint16(header[4:]))+int(header[6]))
+		_, err = io.ReadFull(conn, content)
+		require.NoError(t, err)
+		if header[1] == 3 { // FCGI_END_REQUEST
+			break
+		}
+		if header[1] == 6 { // FCGI_STDOUT
+			stdout.Write(content[:binary.BigEndian.Uint16(header[4:])])
+		}
+	}
+	reader := textproto.NewReader(bufio.NewReader(&stdout))
+	mimeHeader, err := reader.ReadMIMEHeader()
+	require.NoError(t, err)
+	rest, _ := io.ReadAll(reader.R)
+	return mimeHeader.Get("Status"), mimeHeader, string(rest)
+}
+
+func TestRunFastCGI(t *testing.T) {
+	router := New()
+	router.POST("/users/:id", func(c *Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusCreated, "%s %s %s %s", c.Param("id"), c.Query("q"), body, c.ClientIP())
+	})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- router.RunFastCGI(listener) }()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	status, header, body := fcgiRequest(t, conn, map[string]string{
+		"REQUEST_METHOD":  "POST",
+		"REQUEST_URI":     "/users/42?q=x",
+		"SCRIPT_NAME":     "",
+		"SERVER_PROTOCOL": "HTTP/1.1",
+		"REMOTE_ADDR":     "203.0.113.7",
+		"REMOTE_PORT":     "5000",
+		"CONTENT_LENGTH":  "5",
+		"HTTP_HOST":       "example.com",
+	}, "hello")
+	assert.Equal(t, "201 Created", status)
+	assert.Equal(t, "text/plain; charset=utf-8", header.Get("Content-Type"))
+	assert.Equal(t, "42 x hello 203.0.113.7", body)
+
+	listener.Close()
+	assert.Error(t, <-done)
+}
+
+func TestRunFastCGIWarmupError(t *testing.T) {
+	router := New()
+	router.Warmup(func(context.Context) error { return errors.New("cache unavailable") })
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	assert.ErrorContains(t, router.RunFastCGI(listener), "cache unavailable")
+	_, err = listener.Accept()
+	assert.ErrorIs(t, err, net.ErrClosed)
+}
+
+// TestRunCGIHelper is the CGI program run by TestRunCGI.
+func TestRunCGIHelper(t *testing.T) {
+	if os.Getenv("GIN_CGI_HELPER") != "1" {
+		t.Skip("run by TestRunCGI")
+	}
+	DefaultWriter = os.Stderr
+	router := New()
+	router.GET("/hello/:name", func(c *Context) {
+		c.Header("X-Program", "cgi")
+		c.String(http.StatusOK, "hello %s from %s", c.Param("name"), c.Request.URL.Path)
+	})
+	if err := router.RunCGI(); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestRunCGI(t *testing.T) {
+	handler := &cgi.Handler{
+		Path: os.Args[0],
+		Root: "/cgi-bin/app",
+		Args: []string{"-test.run=^TestRunCGIHelper$"},
+		Env:  []string{"GIN_CGI_HELPER=1"},
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/cgi-bin/app/hello/gopher", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "cgi", w.Header().Get("X-Program"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "hello gopher from /hello/gopher"), w.Body.String())
+}
+
+func TestCGIHandlerPath(t *testing.T) {
+	router := New()
+	router.GET("/*path", func(c *Context) { c.String(http.StatusOK, c.Param("path")) })
+	for _, tc := range []struct{ script, pathInfo, uri, want string }{
+		{"/cgi-bin/app.cgi", "/users", "/cgi-bin/app.cgi/users", "/users"},
+		{"/cgi-bin/app.cgi", "", "/cgi-bin/app.cgi", "/"},
+		{"/cgi-bin/app.cgi", "/users", "/users", "/users"},
+		{"/cgi-bin/app.cgi", "", "/cgi-bin/app.cgi.bak", "/cgi-bin/app.cgi.bak"},
+		{"", "", "/users", "/users"},
+	} {
+		w := httptest.NewRecorder()
+		cgiHandler(router, tc.script, tc.pathInfo).ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.uri, nil))
+		assert.Equal(t, http.StatusOK, w.Code, tc.uri)
+		assert.Equal(t, tc.want, w.Body.String(), tc.uri)
+	}
+}
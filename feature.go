@@ -0,0 +1,162 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"strings"
+)
+
+// featuresKey and featureAttributesKey are the context keys of the feature
+// decisions of the request, and of the attributes of its FeatureContext.
+const (
+	featuresKey          = "_gin-gonic/gin/featureskey"
+	featureAttributesKey = "_gin-gonic/gin/featureattributeskey"
+)
+
+// FeatureContext is what a FeatureProvider knows of the request for which a
+// feature flag is evaluated.
+type FeatureContext struct {
+	// UserID is the authenticated user, stored under AuthUserKey, e.g. by BasicAuth.
+	UserID   string
+	ClientIP string
+	Header   http.Header
+	// Attributes are set by the handlers with Context.SetFeatureAttribute, e.g.
+	// the plan or the country of the user.
+	Attributes map[string]any
+}
+
+// FeatureProvider evaluates feature flags, usually backed by a flag service
+// (LaunchDarkly, Unleash, flagd...) or a configuration file, see
+// Engine.FeatureProvider.
+type FeatureProvider interface {
+	FeatureEnabled(name string, fc FeatureContext) bool
+}
+
+// FeatureProviderFunc is an adapter to allow the use of an ordinary function as
+// FeatureProvider.
+type FeatureProviderFunc func(name string, fc FeatureContext) bool
+
+// FeatureEnabled calls f(name, fc).
+func (f FeatureProviderFunc) FeatureEnabled(name string, fc FeatureContext) bool {
+	return f(name, fc)
+}
+
+// StaticFeatures is a FeatureProvider of the given flags, for tests and local
+// development.
+type StaticFeatures map[string]bool
+
+// FeatureEnabled reports whether the flag is true in f.
+func (f StaticFeatures) FeatureEnabled(name string, _ FeatureContext) bool {
+	return f[name]
+}
+
+// FeatureEnabled reports whether the feature flag is enabled for the request,
+// according to Engine.FeatureProvider. Flags are disabled without a provider.
+// The decision is kept for the rest of the request, so that it stays consistent
+// between the middleware, the handler and the templates.
+func (c *Context) FeatureEnabled(name string) bool {
+	features := c.features()
+	if enabled, ok := features[name]; ok {
+		return enabled
+	}
+	enabled := false
+	if c.engine.FeatureProvider != nil {
+		enabled = c.engine.FeatureProvider.FeatureEnabled(name, c.featureContext())
+	}
+	features[name] = enabled
+	return enabled
+}
+
+// Features returns the feature decisions taken for the request so far, e.g. by
+// the Features middleware, to be passed to templates.
+func (c *Context) Features() map[string]bool {
+	return c.features()
+}
+
+// SetFeatureAttribute sets an attribute of the FeatureContext of the feature
+// flags evaluated afterwards for the request.
+func (c *Context) SetFeatureAttribute(key string, value any) {
+	attrs, _ := c.Get(featureAttributesKey)
+	m, _ := attrs.(map[string]any)
+	if m == nil {
+		m = make(map[string]any)
+		c.Set(featureAttributesKey, m)
+	}
+	m[key] = value
+}
+
+func (c *Context) features() map[string]bool {
+	if v, ok := c.Get(featuresKey); ok {
+		return v.(map[string]bool)
+	}
+	features := make(map[string]bool)
+	c.Set(featuresKey, features)
+	return features
+}
+
+// cloneFeatures replaces the feature decisions and attributes of keys, which
+// are modified in place, by copies, so that a copy of the context does not
+// share them with the request, see Context.Copy.
+func cloneFeatures(keys map[string]any) {
+	if features, ok := keys[featuresKey].(map[string]bool); ok {
+		cp := make(map[string]bool, len(features))
+		for name, enabled := range features {
+			cp[name] = enabled
+		}
+		keys[featuresKey] = cp
+	}
+	if attrs, ok := keys[featureAttributesKey].(map[string]any); ok {
+		cp := make(map[string]any, len(attrs))
+		for k, v := range attrs {
+			cp[k] = v
+		}
+		keys[featureAttributesKey] = cp
+	}
+}
+
+func (c *Context) featureContext() FeatureContext {
+	attrs, _ := c.Get(featureAttributesKey)
+	m, _ := attrs.(map[string]any)
+	return FeatureContext{
+		UserID:     c.GetString(AuthUserKey),
+		ClientIP:   c.ClientIP(),
+		Header:     c.Request.Header,
+		Attributes: m,
+	}
+}
+
+// FeaturesConfig defines the config for the FeaturesWithConfig middleware.
+type FeaturesConfig struct {
+	// Names are the feature flags evaluated for every request.
+	Names []string
+
+	// Optional. Default value is "" (disabled). A response header listing the
+	// enabled flags, e.g. "X-Features", for the client applications and caches.
+	Header string
+}
+
+// Features returns a middleware which evaluates the given feature flags for
+// every request, so that Context.Features holds their decisions for the
+// templates. See FeaturesWithConfig.
+func Features(names ...string) HandlerFunc {
+	return FeaturesWithConfig(FeaturesConfig{Names: names})
+}
+
+// FeaturesWithConfig returns a Features middleware with config.
+func FeaturesWithConfig(config FeaturesConfig) HandlerFunc {
+	return func(c *Context) {
+		var enabled []string
+		for _, name := range config.Names {
+			if c.FeatureEnabled(name) {
+				enabled = append(enabled, name)
+			}
+		}
+		if config.Header != "" && len(enabled) > 0 {
+			c.Header(config.Header, strings.Join(enabled, ","))
+		}
+		c.Next()
+	}
+}
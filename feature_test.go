@@ -0,0 +1,96 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureEnabled(t *testing.T) {
+	var evaluations int
+	router := New()
+	router.FeatureProvider = FeatureProviderFunc(func(name string, fc FeatureContext) bool {
+		evaluations++
+		switch name {
+		case "beta":
+			return fc.UserID == "alice" || fc.Header.Get("X-Beta") == "1"
+		case "pro":
+			return fc.Attributes["plan"] == "pro"
+		}
+		return false
+	})
+	router.GET("/", func(c *Context) {
+		c.Set(AuthUserKey, c.Query("user"))
+		c.SetFeatureAttribute("plan", c.Query("plan"))
+		c.Next()
+	}, func(c *Context) {
+		c.String(http.StatusOK, "beta=%t beta=%t pro=%t", c.FeatureEnabled("beta"), c.FeatureEnabled("beta"), c.FeatureEnabled("pro"))
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/?user=alice&plan=pro")
+	assert.Equal(t, "beta=true beta=true pro=true", w.Body.String())
+	assert.Equal(t, 2, evaluations)
+
+	w = PerformRequest(router, http.MethodGet, "/?user=bob")
+	assert.Equal(t, "beta=false beta=false pro=false", w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/?user=bob", header{"X-Beta", "1"})
+	assert.Equal(t, "beta=true beta=true pro=false", w.Body.String())
+
+	router.FeatureProvider = nil
+	w = PerformRequest(router, http.MethodGet, "/?user=alice")
+	assert.Equal(t, "beta=false beta=false pro=false", w.Body.String())
+}
+
+func TestFeaturesMiddleware(t *testing.T) {
+	router := New()
+	router.FeatureProvider = StaticFeatures{"new-checkout": true, "dark-mode": true, "legacy": false}
+	router.Use(FeaturesWithConfig(FeaturesConfig{Names: []string{"new-checkout", "legacy", "dark-mode"}, Header: "X-Features"}))
+	router.GET("/", func(c *Context) {
+		c.JSON(http.StatusOK, c.Features())
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, "new-checkout,dark-mode", w.Header().Get("X-Features"))
+	assert.JSONEq(t, `{"new-checkout":true,"legacy":false,"dark-mode":true}`, w.Body.String())
+
+	router = New()
+	router.Use(Features("legacy"))
+	router.GET("/", func(c *Context) { c.JSON(http.StatusOK, c.Features()) })
+	w = PerformRequest(router, http.MethodGet, "/")
+	assert.JSONEq(t, `{"legacy":false}`, w.Body.String())
+}
+
+func TestFeatureEnabledOnCopy(t *testing.T) {
+	c, router := CreateTestContext(httptest.NewRecorder())
+	router.FeatureProvider = StaticFeatures{"beta": true}
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.SetFeatureAttribute("plan", "pro")
+	assert.True(t, c.FeatureEnabled("beta"))
+
+	cp := c.Copy()
+	var wg sync.WaitGroup
+	for _, ctx := range []*Context{c, cp} {
+		wg.Add(1)
+		go func(ctx *Context) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				ctx.FeatureEnabled(fmt.Sprintf("flag-%d", i))
+				ctx.SetFeatureAttribute(fmt.Sprintf("attr-%d", i), i)
+			}
+		}(ctx)
+	}
+	wg.Wait()
+
+	assert.True(t, cp.FeatureEnabled("beta"))
+	assert.Len(t, c.Features(), 101)
+	assert.Len(t, cp.Features(), 101)
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin/internal/json"
+)
+
+// FlashCookieName is the name of the cookie carrying the flash messages.
+var FlashCookieName = "gin_flash"
+
+// flashesKey caches the flash messages of the request.
+const flashesKey = "_gin-gonic/gin/flasheskey"
+
+// Common flash message levels.
+const (
+	FlashInfo    = "info"
+	FlashSuccess = "success"
+	FlashWarning = "warning"
+	FlashError   = "error"
+)
+
+// FlashMessage is a message stored by Context.Flash.
+type FlashMessage struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// Flash stores a message to be displayed by the next request of the client,
+// which is the classic POST-redirect-GET pattern of server-rendered pages:
+//
+//	c.Flash(gin.FlashSuccess, "Your profile has been updated")
+//	c.Redirect(http.StatusSeeOther, "/profile")
+//
+// The messages are kept in a cookie, which is signed when cookie keys are set
+// with Engine.SetCookieKeys.
+func (c *Context) Flash(level, message string) {
+	pending, _ := c.Get(flashesKey + "/pending")
+	flashes, _ := pending.([]FlashMessage)
+	flashes = append(flashes, FlashMessage{Level: level, Message: message})
+	c.Set(flashesKey+"/pending", flashes)
+
+	data, err := json.Marshal(flashes)
+	if err != nil {
+		return
+	}
+	c.removeSetCookie(FlashCookieName)
+	value := base64.RawURLEncoding.EncodeToString(data)
+	if len(c.engine.cookieKeys) > 0 {
+		_ = c.SetSignedCookie(FlashCookieName, value, 0, "/", "", false, true)
+		return
+	}
+	_ = c.setSecureCookie(FlashCookieName, value, 0, "/", "", false, true)
+}
+
+// Flashes returns the flash messages stored by the previous request, and
+// deletes them, so that they are displayed once. They can be passed to a
+// template:
+//
+//	c.HTML(http.StatusOK, "profile.tmpl", gin.H{"Flashes": c.Flashes()})
+//
+//	{{range .Flashes}}<div class="alert-{{.Level}}">{{.Message}}</div>{{end}}
+func (c *Context) Flashes() []FlashMessage {
+	if cached, ok := c.Get(flashesKey); ok {
+		flashes, _ := cached.([]FlashMessage)
+		return flashes
+	}
+
+	var flashes []FlashMessage
+	var value string
+	var err error
+	if len(c.engine.cookieKeys) > 0 {
+		value, err = c.SignedCookie(FlashCookieName)
+	} else {
+		var cookie *http.Cookie
+		if cookie, err = c.Request.Cookie(FlashCookieName); err == nil {
+			value = cookie.Value
+		}
+	}
+	if err == nil {
+		if data, err := base64.RawURLEncoding.DecodeString(value); err == nil {
+			_ = json.Unmarshal(data, &flashes)
+		}
+		if _, pending := c.Get(flashesKey + "/pending"); !pending {
+			_ = c.setSecureCookie(FlashCookieName, "", -1, "/", "", false, true)
+		}
+	}
+	c.Set(flashesKey, flashes)
+	return flashes
+}
+
+// removeSetCookie removes the Set-Cookie headers already set for the named cookie.
+func (c *Context) removeSetCookie(name string) {
+	header := c.Writer.Header()
+	values := header.Values("Set-Cookie")
+	kept := values[:0]
+	for _, v := range values {
+		if !strings.HasPrefix(v, name+"=") {
+			kept = append(kept, v)
+		}
+	}
+	if len(kept) == 0 {
+		header.Del("Set-Cookie")
+		return
+	}
+	header["Set-Cookie"] = kept
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testFlashes(t *testing.T, router *Engine) {
+	router.POST("/profile", func(c *Context) {
+		c.SetCookie("other", "1", 0, "/", "", false, false)
+		c.Flash(FlashSuccess, "Profile updated")
+		c.Flash(FlashWarning, "Check your <email>")
+		c.Redirect(http.StatusSeeOther, "/profile")
+	})
+	router.GET("/profile", func(c *Context) {
+		flashes := c.Flashes()
+		assert.Equal(t, flashes, c.Flashes())
+		c.JSON(http.StatusOK, flashes)
+	})
+
+	client := NewTestClient(router)
+	resp := client.POST("/profile").Do(t).AssertStatus(http.StatusSeeOther)
+	assert.Len(t, resp.Result().Cookies(), 2)
+
+	client.GET("/profile").Do(t).AssertJSON([]FlashMessage{
+		{Level: FlashSuccess, Message: "Profile updated"},
+		{Level: FlashWarning, Message: "Check your <email>"},
+	})
+	// messages are displayed once.
+	client.GET("/profile").Do(t).AssertJSON("null")
+}
+
+func TestFlash(t *testing.T) {
+	testFlashes(t, New())
+}
+
+func TestFlashSigned(t *testing.T) {
+	router := New()
+	assert.NoError(t, router.SetCookieKeys([]byte("secret")))
+	testFlashes(t, router)
+
+	// tampered messages are ignored.
+	client := NewTestClient(router)
+	client.GET("/profile").WithCookie(&http.Cookie{Name: FlashCookieName, Value: "W10.bad"}).Do(t).AssertJSON("null")
+}
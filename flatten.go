@@ -0,0 +1,61 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+// RouteFlattener is implemented by the IRoutes returned by Engine and RouterGroup,
+// to flatten the routes just registered, see RouterGroup.Flatten.
+type RouteFlattener interface {
+	Flatten() IRoutes
+}
+
+var _ RouteFlattener = (*RouterGroup)(nil)
+
+// Flatten composes the handlers given for the route(s) registered by the last
+// call on the group into a single handler, which runs them in a plain loop,
+// stopping when one aborts:
+//
+//	router.GET("/ping", auth, pong).(gin.RouteFlattener).Flatten()
+//
+// It saves the Context.Next bookkeeping on hot routes whose middleware only work
+// before the handler. The middleware of the engine and of the groups (Logger,
+// Recovery...) are kept as they are. Calling c.Next in a flattened middleware
+// returns at once, so a middleware given for the route which does some work
+// after c.Next (a timer...) must not be flattened: its post-processing would
+// run before the handler. Skip must be called before Flatten. The flattened
+// handler is reported under the name of the route handler.
+func (group *RouterGroup) Flatten() IRoutes {
+	for i := range group.lastRoutes {
+		r := &group.lastRoutes[i]
+		root := group.engine.trees.get(r.method)
+		if root == nil || r.handlers < 2 {
+			continue
+		}
+		n := root.findRoute(r.path)
+		if n == nil {
+			continue
+		}
+		groupSize := len(n.handlers) - r.handlers
+		handlers := make(HandlersChain, groupSize, groupSize+1)
+		copy(handlers, n.handlers)
+		n.handlers = append(handlers, flattenHandlers(n.handlers[groupSize:]))
+		r.handlers = 1
+	}
+	return group.returnObj()
+}
+
+func flattenHandlers(handlers HandlersChain) HandlerFunc {
+	chain := make(HandlersChain, len(handlers))
+	copy(chain, handlers)
+	flat := func(c *Context) {
+		for _, h := range chain {
+			h(c)
+			if c.IsAborted() {
+				return
+			}
+		}
+	}
+	last := chain.Last()
+	return newNamedHandler(namedHandler{name: nameOfFunction(last), handler: unwrapHandler(last)}, flat)
+}
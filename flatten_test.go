@@ -0,0 +1,86 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlatten(t *testing.T) {
+	var order []string
+	mark := func(name string) HandlerFunc {
+		return func(c *Context) { order = append(order, name) }
+	}
+	router := New()
+	router.Use(mark("global"), func(c *Context) {
+		c.Next()
+		order = append(order, "after")
+	})
+	group := router.Group("/", mark("group"))
+	group.GET("/flat", mark("auth"), func(c *Context) {
+		order = append(order, "handler")
+		c.String(http.StatusOK, c.HandlerName())
+	}).(RouteFlattener).Flatten()
+
+	w := PerformRequest(router, http.MethodGet, "/flat")
+	assert.Equal(t, http.StatusOK, w.Code)
+	// the middleware of the engine and groups are not flattened.
+	assert.Equal(t, []string{"global", "group", "auth", "handler", "after"}, order)
+	chain, _ := router.RouteChain(http.MethodGet, "/flat")
+	assert.Len(t, chain, 4)
+	assert.Equal(t, "github.com/gin-gonic/gin.TestFlatten.func3", w.Body.String())
+	routes := router.Routes()
+	assert.Len(t, routes, 1)
+	assert.Equal(t, "github.com/gin-gonic/gin.TestFlatten.func3", routes[0].Handler)
+}
+
+func TestFlattenAbortAndNext(t *testing.T) {
+	router := New()
+	router.GET("/denied", func(c *Context) {
+		c.AbortWithStatus(http.StatusForbidden)
+	}, func(c *Context) {
+		t.Error("handler called after abort")
+	}).(RouteFlattener).Flatten()
+	router.GET("/next", func(c *Context) {
+		c.Next()
+		c.Header("X-Before", "1")
+	}, func(c *Context) {
+		c.String(http.StatusOK, "ok")
+	}).(RouteFlattener).Flatten()
+
+	w := PerformRequest(router, http.MethodGet, "/denied")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = PerformRequest(router, http.MethodGet, "/next")
+	assert.Equal(t, "ok", w.Body.String())
+	assert.Equal(t, "1", w.Header().Get("X-Before"))
+}
+
+func TestFlattenAfterSkip(t *testing.T) {
+	var order []string
+	skipped := func(c *Context) { order = append(order, "skipped") }
+	router := New()
+	router.Use(skipped)
+	routes := router.GET("/flat", skipped, func(c *Context) { order = append(order, "auth") }, func(c *Context) {
+		order = append(order, "handler")
+	})
+	routes.(RouteSkipper).Skip(skipped)
+	routes.(RouteFlattener).Flatten()
+
+	PerformRequest(router, http.MethodGet, "/flat")
+	assert.Equal(t, []string{"auth", "handler"}, order)
+	chain, _ := router.RouteChain(http.MethodGet, "/flat")
+	assert.Len(t, chain, 1)
+}
+
+func BenchmarkFlatten(B *testing.B) {
+	router := New()
+	middleware := func(c *Context) {}
+	router.GET("/flat", middleware, middleware, middleware, middleware, func(c *Context) {}).(RouteFlattener).Flatten()
+	runRequest(B, router, http.MethodGet, "/flat")
+}
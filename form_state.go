@@ -0,0 +1,228 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// FormField is the state of a form field: the value to display back to the
+// user and its validation errors.
+type FormField struct {
+	Name   string
+	Values []string
+	Errors []string
+}
+
+// Value returns the first value of the field.
+func (f *FormField) Value() string {
+	if len(f.Values) == 0 {
+		return ""
+	}
+	return f.Values[0]
+}
+
+// Error returns the first error of the field.
+func (f *FormField) Error() string {
+	if len(f.Errors) == 0 {
+		return ""
+	}
+	return f.Errors[0]
+}
+
+// FormState is the state of a submitted form, to be re-displayed with the input
+// of the user and the validation errors after a failed POST, see Context.FormState.
+type FormState struct {
+	// Fields are the fields of the form by name, the Field of their
+	// binding.FieldError, e.g. "email" or "address.city" (see
+	// binding.StructFieldName).
+	Fields map[string]*FormField
+
+	// Errors are the errors which are not related to a field.
+	Errors []string
+}
+
+// Field returns the state of the named field. It never returns nil.
+func (s *FormState) Field(name string) *FormField {
+	if f, ok := s.Fields[name]; ok {
+		return f
+	}
+	return &FormField{Name: name}
+}
+
+// Value returns the value of the named field.
+func (s *FormState) Value(name string) string {
+	return s.Field(name).Value()
+}
+
+// Error returns the first error of the named field.
+func (s *FormState) Error(name string) string {
+	return s.Field(name).Error()
+}
+
+// HasError returns true if the named field has errors.
+func (s *FormState) HasError(name string) bool {
+	return len(s.Field(name).Errors) > 0
+}
+
+// Valid returns true if there are no errors at all.
+func (s *FormState) Valid() bool {
+	if len(s.Errors) > 0 {
+		return false
+	}
+	for _, f := range s.Fields {
+		if len(f.Errors) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// FormState returns the state of a form bound into obj, with err the error
+// returned by the binding (nil when successful), so that a server-rendered
+// form can be displayed again after a failed POST:
+//
+//	var form SignupForm
+//	if err := c.ShouldBind(&form); err != nil {
+//		c.HTML(http.StatusUnprocessableEntity, "signup.tmpl", gin.H{"Form": c.FormState(&form, err)})
+//		return
+//	}
+//
+//	<input name="email" value="{{.Form.Value "email"}}"> {{.Form.Error "email"}}
+//
+// The values are those submitted by the user, even the ones which could not be
+// bound (e.g. "abc" for a number), and otherwise those of obj. The validation
+// messages are translated when a translator has been set with
+// Engine.SetValidatorTranslator.
+func (c *Context) FormState(obj any, err error) *FormState {
+	state := &FormState{Fields: make(map[string]*FormField)}
+	field := func(name string) *FormField {
+		f, ok := state.Fields[name]
+		if !ok {
+			f = &FormField{Name: name}
+			state.Fields[name] = f
+		}
+		return f
+	}
+
+	formStateValues(reflect.ValueOf(obj), "", field)
+	if c.Request != nil && c.Request.Form != nil {
+		for name, values := range c.Request.Form {
+			field(name).Values = values
+		}
+	}
+
+	if err == nil {
+		return state
+	}
+	var translator *binding.Translator
+	if c.engine != nil {
+		translator = c.engine.translator
+	}
+	err = translator.Translate(err, obj, c.requestHeader("Accept-Language"))
+
+	var fieldErrs binding.FieldErrors
+	var bindErr *binding.BindError
+	switch {
+	case errors.As(err, &fieldErrs):
+		for _, fe := range fieldErrs {
+			f := field(fe.Field)
+			f.Errors = append(f.Errors, fe.Message)
+		}
+	case errors.As(err, &bindErr):
+		f := field(bindErr.Key)
+		f.Errors = append(f.Errors, bindErr.Err.Error())
+	default:
+		state.Errors = append(state.Errors, err.Error())
+	}
+	return state
+}
+
+// formStateValues collects the values of the fields of a struct, named as in
+// the validation errors: nested fields are prefixed with the names of their
+// parents, e.g. "address.city" or "items[0].name".
+func formStateValues(v reflect.Value, prefix string, field func(string) *FormField) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if !sf.IsExported() || sf.Tag.Get("form") == "-" {
+			continue
+		}
+		name := prefix + binding.StructFieldName(sf)
+		fv := v.Field(i)
+		switch ft := indirectType(sf.Type); {
+		case isFormStruct(ft):
+			formStateValues(fv, name+".", field)
+		case (ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array) && isFormStruct(indirectType(ft.Elem())):
+			fv = reflect.Indirect(fv)
+			for j := 0; fv.IsValid() && j < fv.Len(); j++ {
+				formStateValues(fv.Index(j), fmt.Sprintf("%s[%d].", name, j), field)
+			}
+		default:
+			field(name).Values = formatFormValues(fv, sf)
+		}
+	}
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isFormStruct returns true for the structs whose fields are bound one by one.
+func isFormStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{})
+}
+
+func formatFormValues(v reflect.Value, sf reflect.StructField) []string {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		values := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, formatFormValues(v.Index(i), sf)...)
+		}
+		return values
+	case reflect.Struct:
+		if t, ok := v.Interface().(time.Time); ok {
+			if t.IsZero() {
+				return nil
+			}
+			layout := sf.Tag.Get("time_format")
+			if layout == "" {
+				layout = time.RFC3339
+			}
+			return []string{t.Format(layout)}
+		}
+	}
+	if v.IsZero() {
+		return nil
+	}
+	return []string{fmt.Sprint(v.Interface())}
+}
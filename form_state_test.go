@@ -0,0 +1,106 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+type signupForm struct {
+	Email    string    `form:"email" binding:"required,email"`
+	Age      int       `form:"age" binding:"gte=18"`
+	Tags     []string  `form:"tags"`
+	Birthday time.Time `form:"birthday" time_format:"2006-01-02"`
+	Address  struct {
+		City string `form:"city" binding:"required"`
+	} `form:"address"`
+	Secret string `form:"-"`
+}
+
+func TestContextFormState(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader("email=bob&age=12&tags=a&tags=b"))
+	c.Request.Header.Set("Content-Type", MIMEPOSTForm)
+
+	var form signupForm
+	err := c.ShouldBind(&form)
+	assert.Error(t, err)
+	form.Birthday = time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+	state := c.FormState(&form, err)
+
+	assert.False(t, state.Valid())
+	assert.Equal(t, "bob", state.Value("email"))
+	assert.True(t, state.HasError("email"))
+	assert.Contains(t, state.Error("email"), "'email'")
+	assert.Equal(t, "12", state.Value("age"))
+	assert.False(t, state.Valid())
+	assert.Equal(t, []string{"a", "b"}, state.Field("tags").Values)
+	assert.Equal(t, "2000-01-02", state.Value("birthday"))
+	assert.Empty(t, state.Value("Secret"))
+	assert.True(t, state.HasError("address.city"))
+	assert.Empty(t, state.Value("missing"))
+	assert.False(t, state.HasError("missing"))
+}
+
+func TestContextFormStateBindError(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader("age=abc"))
+	c.Request.Header.Set("Content-Type", MIMEPOSTForm)
+
+	var form struct {
+		Age  int    `form:"age"`
+		Name string `form:"name,required"`
+	}
+	err := c.ShouldBind(&form)
+	assert.Error(t, err)
+	state := c.FormState(&form, err)
+	assert.Equal(t, "abc", state.Value("age"))
+	assert.False(t, state.Valid())
+
+	c.Request, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader("age=1"))
+	c.Request.Header.Set("Content-Type", MIMEPOSTForm)
+	err = c.ShouldBind(&form)
+	assert.Error(t, err)
+	state = c.FormState(&form, err)
+	assert.Equal(t, "1", state.Value("age"))
+	assert.Equal(t, "field is required", state.Error("name"))
+
+	state = c.FormState(&form, errors.New("csrf token mismatch"))
+	assert.Equal(t, []string{"csrf token mismatch"}, state.Errors)
+
+	state = c.FormState(&form, nil)
+	assert.True(t, state.Valid())
+}
+
+func TestContextFormStateNested(t *testing.T) {
+	type item struct {
+		Name string `json:"name" binding:"required"`
+	}
+	var form struct {
+		Address struct {
+			City string `form:"city" binding:"required"`
+		} `form:"address"`
+		Items []item `json:"items" binding:"dive"`
+	}
+	form.Address.City = "Paris"
+	form.Items = []item{{Name: "a"}, {}}
+
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodPost, "/", nil)
+	state := c.FormState(&form, binding.Validator.ValidateStruct(&form))
+	assert.Equal(t, "Paris", state.Value("address.city"))
+	assert.Equal(t, "a", state.Value("items[0].name"))
+	assert.False(t, state.HasError("items[0].name"))
+	assert.True(t, state.HasError("items[1].name"))
+	assert.Len(t, state.Fields, 3)
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrForwardLoop is returned by Context.Forward when the request was already
+// forwarded to the same route, or too many times.
+var ErrForwardLoop = errors.New("gin: forward loop")
+
+// maxForwards is the maximum number of times a request can be forwarded.
+const maxForwards = 10
+
+type forwardKey struct{}
+
+// ForwardOptions defines the request dispatched by Context.Forward.
+type ForwardOptions struct {
+	// Optional. Default value is the body of the original request, or its copy
+	// when it was cached by BodyBytes.
+	Body io.Reader
+
+	// Optional. Default value is a copy of the headers of the original request.
+	Header http.Header
+
+	// KeepKeys copies the keys of the context (see Context.Set) to the context
+	// of the forwarded request, e.g. the authenticated user.
+	KeepKeys bool
+}
+
+// Forward dispatches a new request for method and path (which may have a query
+// string) to the routes of the engine, with a fresh Context, and writes its
+// response to c.Writer. It replaces Engine.HandleContext: c is left untouched,
+// except that it is aborted so that its remaining handlers do not write to the
+// response, and that it gets the errors of the forwarded request. Like any
+// request, the forwarded one goes through the global middleware again.
+//
+//	router.GET("/legacy/:id", func(c *gin.Context) {
+//		_ = c.Forward(http.MethodGet, "/v2/items/"+c.Param("id"), gin.ForwardOptions{KeepKeys: true})
+//	})
+//
+// ErrForwardLoop is returned, before anything is dispatched, when the request
+// was already forwarded to the same method and path, or more than 10 times.
+func (c *Context) Forward(method, path string, opts ForwardOptions) error {
+	target, err := url.Parse(path)
+	if err != nil {
+		return err
+	}
+	target = c.Request.URL.ResolveReference(target)
+
+	// the method and path of the original request and of each forwarded one.
+	visited, _ := c.Request.Context().Value(forwardKey{}).([]string)
+	visited = append(visited[:len(visited):len(visited)], c.Request.Method+" "+c.Request.URL.Path)
+	if len(visited) > maxForwards {
+		return ErrForwardLoop
+	}
+	hop := strings.ToUpper(method) + " " + target.Path
+	for _, v := range visited {
+		if v == hop {
+			return ErrForwardLoop
+		}
+	}
+
+	req := c.Request.Clone(context.WithValue(c.Request.Context(), forwardKey{}, visited))
+	req.Method = strings.ToUpper(method)
+	req.URL = target
+	req.RequestURI = target.RequestURI()
+	if opts.Header != nil {
+		req.Header = opts.Header.Clone()
+	}
+	switch {
+	case opts.Body != nil:
+		req.Body = io.NopCloser(opts.Body)
+		req.ContentLength = -1
+		if r, ok := opts.Body.(interface{ Len() int }); ok {
+			req.ContentLength = int64(r.Len())
+		}
+	default:
+		if cb, ok := c.Get(BodyBytesKey); ok {
+			if body, ok := cb.([]byte); ok {
+				req.Body = io.NopCloser(bytes.NewReader(body))
+				req.ContentLength = int64(len(body))
+			}
+		}
+	}
+
+	engine := c.engine
+	fc := engine.pool.Get().(*Context)
+	engine.contextStats.acquired.Add(1)
+	fc.writermem.reset(c.Writer)
+	fc.Request = req
+	fc.reset()
+	if opts.KeepKeys {
+		c.mu.RLock()
+		fc.Keys = make(map[string]any, len(c.Keys))
+		for k, v := range c.Keys {
+			fc.Keys[k] = v
+		}
+		c.mu.RUnlock()
+	}
+
+	engine.handleHTTPRequest(fc)
+	fc.finish()
+	c.Errors = append(c.Errors, fc.Errors...)
+	c.deferred = append(c.deferred, fc.deferred...)
+	fc.deferred = nil
+	c.Abort()
+	engine.releaseContext(fc)
+	return nil
+}
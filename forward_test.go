@@ -0,0 +1,86 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForward(t *testing.T) {
+	router := New()
+	var globalCalls int
+	router.Use(func(c *Context) { globalCalls++ })
+	router.GET("/v2/items/:id", func(c *Context) {
+		user, _ := c.Get("user")
+		c.String(http.StatusCreated, "item %s q=%s user=%v", c.Param("id"), c.Query("q"), user)
+	})
+	router.GET("/legacy/:id", func(c *Context) {
+		c.Set("user", "alice")
+		err := c.Forward(http.MethodGet, "/v2/items/"+c.Param("id")+"?q=1", ForwardOptions{KeepKeys: true})
+		assert.NoError(t, err)
+		assert.True(t, c.IsAborted())
+		assert.Equal(t, "42", c.Param("id"))
+		assert.Equal(t, "/legacy/:id", c.FullPath())
+	}, func(c *Context) {
+		t.Error("handler called after Forward")
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/legacy/42")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "item 42 q=1 user=alice", w.Body.String())
+	assert.Equal(t, 2, globalCalls)
+}
+
+func TestForwardBody(t *testing.T) {
+	router := New()
+	router.POST("/echo", func(c *Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "%s %s", c.GetHeader("X-Forwarded"), body)
+	})
+	router.POST("/cached", func(c *Context) {
+		_, err := c.BodyBytes()
+		require.NoError(t, err)
+		assert.NoError(t, c.Forward(http.MethodPost, "echo", ForwardOptions{}))
+	})
+	router.POST("/replaced", func(c *Context) {
+		assert.NoError(t, c.Forward(http.MethodPost, "/echo", ForwardOptions{
+			Body:   strings.NewReader("replaced"),
+			Header: http.Header{"X-Forwarded": {"yes"}},
+		}))
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/cached", strings.NewReader("original"))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, " original", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/replaced", strings.NewReader("original"))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "yes replaced", w.Body.String())
+}
+
+func TestForwardLoop(t *testing.T) {
+	router := New()
+	var errs []error
+	router.GET("/a", func(c *Context) { errs = append(errs, c.Forward(http.MethodGet, "/b", ForwardOptions{})) })
+	router.GET("/b", func(c *Context) { errs = append(errs, c.Forward(http.MethodGet, "/a", ForwardOptions{})) })
+	router.GET("/self/:n", func(c *Context) { errs = append(errs, c.Forward(http.MethodGet, c.Param("n")+"x", ForwardOptions{})) })
+
+	PerformRequest(router, http.MethodGet, "/a")
+	assert.Equal(t, []error{ErrForwardLoop, nil}, errs)
+
+	errs = nil
+	PerformRequest(router, http.MethodGet, "/self/x")
+	assert.Len(t, errs, maxForwards+1)
+	assert.ErrorIs(t, errs[0], ErrForwardLoop)
+}
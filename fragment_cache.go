@@ -0,0 +1,219 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin/internal/singleflight"
+	"github.com/gin-gonic/gin/render"
+)
+
+// fragmentCache holds the state of the fragments rendered by Context.HTMLCached.
+type fragmentCache struct {
+	mu sync.Mutex
+	// store is the in-process store, replaced when the fragments are
+	// invalidated.
+	store *MemoryStore
+	// keys are the keys set in Engine.FragmentCache since the fragments were
+	// last invalidated.
+	keys map[string]struct{}
+	// renderers are the renderers of the groups, see
+	// RouterGroup.LoadHTMLGlobWithLayouts, whose index is part of the keys of
+	// their fragments.
+	renderers []render.HTMLRender
+	group     singleflight.Group
+	// generation is part of the keys of the fragments, so that incrementing it
+	// invalidates all of them.
+	generation atomic.Uint64
+}
+
+// fragmentStore returns Engine.FragmentCache, or the in-process store.
+func (engine *Engine) fragmentStore() CacheStore {
+	if engine.FragmentCache != nil {
+		return engine.FragmentCache
+	}
+	f := &engine.fragments
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.store == nil {
+		f.store = NewMemoryStore()
+	}
+	return f.store
+}
+
+// addRenderer registers the renderer of a group.
+func (f *fragmentCache) addRenderer(r render.HTMLRender) {
+	f.mu.Lock()
+	f.renderers = append(f.renderers, r)
+	f.mu.Unlock()
+}
+
+// rendererID returns the index of the renderer r of a group plus 1, or 0 for
+// Engine.HTMLRender.
+func (f *fragmentCache) rendererID(r render.HTMLRender) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, renderer := range f.renderers {
+		if renderer == r {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// track records a key set in Engine.FragmentCache, to be deleted by
+// InvalidateFragments.
+func (f *fragmentCache) track(key string, set bool) {
+	f.mu.Lock()
+	if set {
+		if f.keys == nil {
+			f.keys = make(map[string]struct{})
+		}
+		f.keys[key] = struct{}{}
+	} else {
+		delete(f.keys, key)
+	}
+	f.mu.Unlock()
+}
+
+// fragmentKey returns the key under which the fragment of the template name
+// rendered by r for key is stored. It changes whenever the templates are loaded
+// again, by the Engine or by r in debug mode.
+func (engine *Engine) fragmentKey(r render.HTMLRender, name, key string) string {
+	var reloads uint64
+	if stats, ok := templateCacheStats(r); ok {
+		reloads = stats.Reloads
+	}
+	return "gin:fragment:" + strconv.FormatUint(engine.fragments.generation.Load(), 10) + "." +
+		strconv.FormatUint(reloads, 10) + ":" + strconv.Itoa(engine.fragments.rendererID(r)) + ":" +
+		name + ":" + key
+}
+
+// InvalidateFragment removes the fragments of the template name rendered for
+// key by Context.HTMLCached, with Engine.HTMLRender and the renderers of the
+// groups, e.g. when the data they show is updated.
+func (engine *Engine) InvalidateFragment(name, key string) error {
+	engine.fragments.mu.Lock()
+	renderers := append([]render.HTMLRender(nil), engine.fragments.renderers...)
+	engine.fragments.mu.Unlock()
+	if engine.HTMLRender != nil {
+		renderers = append(renderers, engine.HTMLRender)
+	}
+	if len(renderers) == 0 {
+		return errNoHTMLRenderer
+	}
+	store := engine.fragmentStore()
+	for _, r := range renderers {
+		storeKey := engine.fragmentKey(r, name, key)
+		if err := store.Delete(storeKey); err != nil {
+			return err
+		}
+		engine.fragments.track(storeKey, false)
+	}
+	return nil
+}
+
+// InvalidateFragments removes all the fragments rendered by
+// Context.HTMLCached. It is called when templates are loaded or set, e.g. by
+// LoadHTMLGlob or SetHTMLRenderer. The fragments of a shared FragmentCache are
+// only invalidated for this Engine, the other instances of the application
+// keep theirs until they expire.
+func (engine *Engine) InvalidateFragments() {
+	f := &engine.fragments
+	f.generation.Add(1)
+	f.mu.Lock()
+	f.store = nil
+	keys := f.keys
+	f.keys = nil
+	f.mu.Unlock()
+	if engine.FragmentCache == nil {
+		return
+	}
+	for key := range keys {
+		_ = engine.FragmentCache.Delete(key)
+	}
+}
+
+// HTMLCached renders the template name with data into a fragment of HTML, to be
+// embedded in a page, and keeps it for ttl (or until it is invalidated, when
+// ttl <= 0) in Engine.FragmentCache. key identifies the data of the fragment:
+// while the fragment is cached, data is not rendered again and can be nil,
+// which is how expensive partials are rendered only once.
+//
+//	sidebar, err := c.HTMLCached("sidebar.tmpl", "user:"+id, time.Minute, data)
+//	c.HTML(http.StatusOK, "page.tmpl", gin.H{"Sidebar": sidebar})
+//
+// The fragments are invalidated automatically when the templates are loaded
+// again, and explicitly with Engine.InvalidateFragment and
+// Engine.InvalidateFragments. Concurrent renders of a missing fragment are
+// collapsed into one.
+func (c *Context) HTMLCached(name, key string, ttl time.Duration, data any) (template.HTML, error) {
+	r := c.htmlRenderer()
+	if r == nil {
+		return "", errNoHTMLRenderer
+	}
+	// Instance reloads the templates which changed in debug mode, before
+	// fragmentKey reads the number of reloads.
+	instance := r.Instance(name, data)
+	store := c.engine.fragmentStore()
+	storeKey := c.engine.fragmentKey(r, name, key)
+
+	cached, err := store.Get(storeKey)
+	if err == nil && cached != nil {
+		return template.HTML(cached.Body), nil //nolint:gosec
+	}
+	if err != nil && !errors.Is(err, ErrCacheMiss) {
+		_ = c.Error(err)
+	}
+
+	v, err, _ := c.engine.fragments.group.Do(storeKey, func() (any, error) {
+		var w fragmentWriter
+		if err := instance.Render(&w); err != nil {
+			return nil, err
+		}
+		fragment := &CachedResponse{
+			Status:   http.StatusOK,
+			Body:     w.body.Bytes(),
+			StoredAt: time.Now(),
+		}
+		if err := store.Set(storeKey, fragment, ttl); err != nil {
+			_ = c.Error(err)
+		} else if c.engine.FragmentCache != nil {
+			c.engine.fragments.track(storeKey, true)
+		}
+		return fragment, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(v.(*CachedResponse).Body), nil //nolint:gosec
+}
+
+// fragmentWriter is the http.ResponseWriter into which HTMLCached renders.
+type fragmentWriter struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (w *fragmentWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = make(http.Header)
+	}
+	return w.header
+}
+
+func (w *fragmentWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *fragmentWriter) WriteHeader(int) {}
@@ -0,0 +1,182 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fragmentRouter(renders *int) *Engine {
+	router := New()
+	templ := template.Must(template.New("").Funcs(template.FuncMap{
+		"count": func() string {
+			*renders++
+			return ""
+		},
+	}).Parse(`{{define "sidebar"}}{{count}}<ul>{{range .}}<li>{{.}}</li>{{end}}</ul>{{end}}`))
+	router.SetHTMLTemplate(templ)
+	router.GET("/", func(c *Context) {
+		var data []string
+		if c.Query("user") == "ada" {
+			data = []string{"a", "<b>"}
+		}
+		sidebar, err := c.HTMLCached("sidebar", c.Query("user"), 0, data)
+		if err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.String(http.StatusOK, string(sidebar))
+	})
+	return router
+}
+
+func TestHTMLCached(t *testing.T) {
+	renders := 0
+	router := fragmentRouter(&renders)
+
+	w := PerformRequest(router, http.MethodGet, "/?user=ada")
+	assert.Equal(t, "<ul><li>a</li><li>&lt;b&gt;</li></ul>", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "/?user=ada")
+	assert.Equal(t, "<ul><li>a</li><li>&lt;b&gt;</li></ul>", w.Body.String())
+	assert.Equal(t, 1, renders)
+
+	w = PerformRequest(router, http.MethodGet, "/?user=bob")
+	assert.Equal(t, "<ul></ul>", w.Body.String())
+	assert.Equal(t, 2, renders)
+
+	require.NoError(t, router.InvalidateFragment("sidebar", "ada"))
+	PerformRequest(router, http.MethodGet, "/?user=ada")
+	PerformRequest(router, http.MethodGet, "/?user=bob")
+	assert.Equal(t, 3, renders)
+
+	router.InvalidateFragments()
+	PerformRequest(router, http.MethodGet, "/?user=ada")
+	PerformRequest(router, http.MethodGet, "/?user=bob")
+	assert.Equal(t, 5, renders)
+
+	// loading templates invalidates the fragments.
+	router.SetHTMLTemplate(template.Must(template.New("").Parse(`{{define "sidebar"}}<nav></nav>{{end}}`)))
+	w = PerformRequest(router, http.MethodGet, "/?user=ada")
+	assert.Equal(t, "<nav></nav>", w.Body.String())
+}
+
+func TestHTMLCachedTTLAndStore(t *testing.T) {
+	renders := 0
+	router := fragmentRouter(&renders)
+	store := NewMemoryStore()
+	router.FragmentCache = store
+
+	c := CreateTestContextOnly(httptest.NewRecorder(), router)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	_, err := c.HTMLCached("sidebar", "k", time.Millisecond, nil)
+	require.NoError(t, err)
+	assert.Len(t, store.entries, 1)
+	time.Sleep(5 * time.Millisecond)
+	_, err = c.HTMLCached("sidebar", "k", time.Millisecond, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, renders)
+
+	_, err = c.HTMLCached("missing", "k", 0, nil)
+	assert.Error(t, err)
+	assert.Len(t, store.entries, 1)
+
+	router.InvalidateFragments()
+	assert.Empty(t, store.entries)
+
+	router.HTMLRender = nil
+	_, err = c.HTMLCached("sidebar", "k", 0, nil)
+	assert.Equal(t, errNoHTMLRenderer, err)
+	assert.Equal(t, errNoHTMLRenderer, router.InvalidateFragment("sidebar", "k"))
+}
+
+func TestHTMLCachedGroupRenderers(t *testing.T) {
+	router := New()
+	store := NewMemoryStore()
+	router.FragmentCache = store
+	for path, upper := range map[string]func(string) string{"/a": strings.ToUpper, "/b": strings.ToLower} {
+		group := router.Group(path)
+		group.SetFuncMap(template.FuncMap{"upper": upper})
+		group.LoadHTMLGlobWithLayouts("./testdata/layout/layouts/*", "./testdata/layout/pages/*")
+		group.GET("/", func(c *Context) {
+			fragment, err := c.HTMLCached("index.tmpl", "k", 0, H{"name": "Gin"})
+			if err != nil {
+				c.String(http.StatusInternalServerError, err.Error())
+				return
+			}
+			c.String(http.StatusOK, string(fragment))
+		})
+	}
+
+	w := PerformRequest(router, http.MethodGet, "/a/")
+	assert.Contains(t, w.Body.String(), "Hello GIN")
+	w = PerformRequest(router, http.MethodGet, "/b/")
+	assert.Contains(t, w.Body.String(), "Hello gin")
+	assert.Len(t, store.entries, 2)
+
+	require.NoError(t, router.InvalidateFragment("index.tmpl", "k"))
+	assert.Empty(t, store.entries)
+}
+
+func TestHTMLCachedConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	renders := 0
+	router := New()
+	router.SetHTMLTemplate(template.Must(template.New("").Funcs(template.FuncMap{
+		"slow": func() string {
+			mu.Lock()
+			renders++
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			return "slow"
+		},
+	}).Parse(`{{define "slow"}}{{slow}}{{end}}`)))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := CreateTestContextOnly(httptest.NewRecorder(), router)
+			fragment, err := c.HTMLCached("slow", "", 0, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, template.HTML("slow"), fragment)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, renders)
+}
+
+func TestHTMLCachedDebugReload(t *testing.T) {
+	defer SetMode(TestMode)
+	SetMode(DebugMode)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "box.tmpl")
+	require.NoError(t, os.WriteFile(file, []byte(`{{define "box"}}v1{{end}}`), 0o600))
+
+	router := New()
+	router.LoadHTMLGlob(filepath.Join(dir, "*.tmpl"))
+	c := CreateTestContextOnly(httptest.NewRecorder(), router)
+	fragment, err := c.HTMLCached("box", "", 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, template.HTML("v1"), fragment)
+
+	require.NoError(t, os.WriteFile(file, []byte(`{{define "box"}}v2{{end}}`), 0o600))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(file, later, later))
+	fragment, err = c.HTMLCached("box", "", 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, template.HTML("v2"), fragment)
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+// frozenRoutes indexes the static routes of each method by path, see Engine.Freeze.
+type frozenRoutes map[string]map[string]*node
+
+func (f frozenRoutes) get(method, path string) *node {
+	if f == nil {
+		return nil
+	}
+	return f[method][path]
+}
+
+// Freeze compiles the routes registered so far for faster matching: the routes
+// without parameters are looked up by a single hash of the path, and only the
+// other requests walk the radix tree. It is worth it for large route tables,
+// and must be called once all the routes are registered, before serving;
+// registering a route afterwards panics. Route metadata and names can still be
+// changed.
+func (engine *Engine) Freeze() {
+	frozen := make(frozenRoutes, len(engine.trees))
+	for _, tree := range engine.trees {
+		routes := make(map[string]*node)
+		collectStatic("", tree.root, routes)
+		frozen[tree.method] = routes
+	}
+	engine.frozen = frozen
+}
+
+// Frozen reports whether Freeze has been called.
+func (engine *Engine) Frozen() bool {
+	return engine.frozen != nil
+}
+
+// collectStatic adds the routes of n without parameters to routes.
+func collectStatic(path string, n *node, routes map[string]*node) {
+	if n.nType == param || n.nType == catchAll {
+		return
+	}
+	path += n.path
+	if n.handlers != nil {
+		routes[path] = n
+	}
+	for _, child := range n.children {
+		collectStatic(path, child, routes)
+	}
+}
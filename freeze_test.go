@@ -0,0 +1,73 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type routeResult struct {
+	code int
+	body string
+}
+
+func TestFreezeMatchesTree(t *testing.T) {
+	router := New()
+	handler := func(c *Context) {
+		tag, _ := c.RouteMeta("tag")
+		c.String(http.StatusOK, "%s %s %v %v", c.FullPath(), c.RouteName(), c.Params, tag)
+	}
+	router.GET("/", handler)
+	router.GET("/users", handler).(RouteNamer).Name("users")
+	router.GET("/users/new", handler).(RouteMetaSetter).Meta("tag", "static")
+	router.GET("/users/:id", handler)
+	router.GET("/users/:id/posts", handler)
+	router.GET("/files/*path", handler)
+	router.GET("/static/*path", handler)
+	router.GET("/static", handler)
+	router.POST("/users", handler)
+
+	paths := []string{
+		"/", "/users", "/users/", "/users/new", "/users/42", "/users/42/posts",
+		"/static", "/static/a/b", "/files/x", "/missing",
+	}
+	want := map[string]*routeResult{}
+	for _, path := range paths {
+		w := PerformRequest(router, http.MethodGet, path)
+		want[path] = &routeResult{w.Code, w.Body.String()}
+	}
+
+	router.Freeze()
+	assert.True(t, router.Frozen())
+	assert.Len(t, router.frozen[http.MethodGet], 4)
+	for _, path := range paths {
+		w := PerformRequest(router, http.MethodGet, path)
+		assert.Equal(t, want[path], &routeResult{w.Code, w.Body.String()}, path)
+	}
+	w := PerformRequest(router, http.MethodPost, "/users")
+	assert.Equal(t, "/users  [] <nil>", w.Body.String())
+}
+
+func TestFreezePanicsOnLateRoute(t *testing.T) {
+	router := New()
+	router.GET("/", func(c *Context) {})
+	router.Freeze()
+	assert.Panics(t, func() {
+		router.GET("/late", func(c *Context) {})
+	})
+}
+
+func BenchmarkFrozenStaticRoutes(B *testing.B) {
+	router := New()
+	for i := 0; i < 500; i++ {
+		router.GET(fmt.Sprintf("/api/v1/resource%d/list", i), func(c *Context) {})
+	}
+	router.Freeze()
+	runRequest(B, router, http.MethodGet, "/api/v1/resource499/list")
+}
@@ -5,8 +5,10 @@
 package gin
 
 import (
+	"context"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"net"
 	"net/http"
 	"os"
@@ -14,7 +16,10 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/gin-gonic/gin/binding"
 	"github.com/gin-gonic/gin/internal/bytesconv"
 	"github.com/gin-gonic/gin/render"
 	"golang.org/x/net/http2"
@@ -64,11 +69,21 @@ type RouteInfo struct {
 	Path        string
 	Handler     string
 	HandlerFunc HandlerFunc
+	// Meta is the metadata of the route, see RouterGroup.Meta.
+	Meta map[string]any
+	// Name is the name of the route, see RouterGroup.Name.
+	Name string
 }
 
 // RoutesInfo defines a RouteInfo slice.
 type RoutesInfo []RouteInfo
 
+// HTMLRenderer is implemented by HTML template engines. Instance returns the
+// render.Render which writes the named template executed with data.
+// Third-party engines (jet, pongo2, templ...) can be plugged in through
+// Engine.SetHTMLRenderer.
+type HTMLRenderer = render.HTMLRender
+
 // Trusted platforms
 const (
 	// PlatformGoogleAppEngine when running on Google App Engine. Trust X-Appengine-Remote-Addr
@@ -102,6 +117,11 @@ type Engine struct {
 	// RedirectTrailingSlash is independent of this option.
 	RedirectFixedPath bool
 
+	// RedirectAllowedHosts are the hosts, besides the host of the request, to which
+	// Context.SafeRedirect and the redirect helpers built on it may redirect, e.g.
+	// "accounts.example.com" or "*.example.com".
+	RedirectAllowedHosts []string
+
 	// HandleMethodNotAllowed if enabled, the router checks if another method is allowed for the
 	// current route, if the current request can not be routed.
 	// If this is the case, the request is answered with 'Method Not Allowed'
@@ -110,6 +130,17 @@ type Engine struct {
 	// handler.
 	HandleMethodNotAllowed bool
 
+	// HandleHEAD if enabled, a HEAD request without a matching HEAD route is served
+	// by the handlers of the matching GET route. Their body is discarded but its
+	// size is sent as Content-Length. Handlers can check Context.IsHead to skip
+	// generating the body.
+	HandleHEAD bool
+
+	// SuggestRoutes if enabled, in debug mode, the default 404 and 405 responses list the
+	// near-miss routes of the request (see Context.RouteSuggestions), to speed up the
+	// debugging of API clients.
+	SuggestRoutes bool
+
 	// ForwardedByClientIP if enabled, client IP will be parsed from the request's headers that
 	// match those stored at `(*gin.Engine).RemoteIPHeaders`. If no IP was
 	// fetched, it falls back to the IP obtained from
@@ -138,36 +169,151 @@ type Engine struct {
 	// `(*gin.Engine).ForwardedByClientIP` is `true` and
 	// `(*gin.Context).Request.RemoteAddr` is matched by at least one of the
 	// network origins of list defined by `(*gin.Engine).SetTrustedProxies()`.
+	// The Forwarded header (RFC 7239) is read from its for parameters when listed.
 	RemoteIPHeaders []string
 
 	// TrustedPlatform if set to a constant of value gin.Platform*, trusts the headers set by
 	// that platform, for example to determine the client IP
 	TrustedPlatform string
 
+	// ClientIPStrategies are tried in order by Context.ClientIP, after TrustedPlatform and
+	// before the X-Forwarded-For algorithm, e.g. ClientIPFromForwarded().
+	ClientIPStrategies []ClientIPStrategy
+
+	// FeatureProvider evaluates the feature flags of Context.FeatureEnabled.
+	FeatureProvider FeatureProvider
+
+	// ProxyProtocol if enabled, RunListener and RunFd accept the HAProxy
+	// PROXY protocol, so that the client IP is the one reported by the load balancer.
+	// See NewProxyProtocolListener.
+	ProxyProtocol bool
+
+	// DetectContextLeaks if enabled, the Contexts are not recycled, and their uses
+	// after the end of the request, usually by goroutines which should have used
+	// Context.Copy, are logged with the route and the handler. It is meant for
+	// development and tests: it disables the pool of Contexts.
+	DetectContextLeaks bool
+
+	// BackgroundWorkers if positive, is the number of goroutines running the jobs
+	// of Context.Defer, 4 by default.
+	BackgroundWorkers int
+
+	// MaxConnections if positive, limits the number of open connections of the
+	// servers started by the Run methods, see NewLimitListener.
+	MaxConnections int
+
+	// MaxConnectionsPerIP if positive, limits the number of open connections from
+	// an IP address to the servers started by the Run methods.
+	MaxConnectionsPerIP int
+
+	// ConnectionReject is how the connections over MaxConnections and
+	// MaxConnectionsPerIP are rejected. Default value is ConnRejectClose.
+	ConnectionReject ConnRejectMode
+
+	// WarmupTimeout if positive, is the time after which the context of the
+	// warmup functions is done, see Engine.Warmup.
+	WarmupTimeout time.Duration
+
+	// IgnoreWarmupErrors if enabled, the failures of the warmup functions are
+	// logged, and the server starts anyway.
+	IgnoreWarmupErrors bool
+
 	// MaxMultipartMemory value of 'maxMemory' param that is given to http.Request's ParseMultipartForm
 	// method call.
 	MaxMultipartMemory int64
 
+	// UploadInspector if set, inspects the content of the files saved by
+	// Context.SaveUploadedFile and Context.SaveUploadedFileChecked, e.g. with an
+	// antivirus, before they are stored. See UploadInspector.
+	UploadInspector UploadInspector
+
+	// UploadStorage if set, stores the files saved by Context.SaveUploadedFile
+	// and Context.SaveUploadedFileChecked instead of the local disk, e.g. in an
+	// S3 or GCS adapter. See BlobStorage.
+	UploadStorage BlobStorage
+
+	// StaticCache if set, keeps the small files served by Static and StaticFS
+	// in memory. See StaticCache.
+	StaticCache *StaticCache
+
+	// FragmentCache if set, stores the fragments rendered by Context.HTMLCached
+	// instead of an in-process MemoryStore, e.g. a RedisStore shared by the
+	// instances of the application.
+	FragmentCache CacheStore
+
+	// CacheRequestBody if enabled, Context.GetRawData caches the request body, so that it
+	// can be read again by the following handlers. See Context.BodyBytes.
+	CacheRequestBody bool
+
+	// MaxCachedBodySize is the maximum size of a request body cached by Context.BodyBytes.
+	// Zero or less means no limit.
+	MaxCachedBodySize int64
+
 	// UseH2C enable h2c support.
 	UseH2C bool
 
 	// ContextWithFallback enable fallback Context.Deadline(), Context.Done(), Context.Err() and Context.Value() when Context.Request.Context() is not nil.
 	ContextWithFallback bool
 
-	delims           render.Delims
-	secureJSONPrefix string
-	HTMLRender       render.HTMLRender
-	FuncMap          template.FuncMap
-	allNoRoute       HandlersChain
-	allNoMethod      HandlersChain
-	noRoute          HandlersChain
-	noMethod         HandlersChain
-	pool             sync.Pool
-	trees            methodTrees
-	maxParams        uint16
-	maxSections      uint16
-	trustedProxies   []string
-	trustedCIDRs     []*net.IPNet
+	// RequestTimeout if positive, the context of every request is given a deadline that far in
+	// the future, so that the work started by handlers (database calls, outgoing requests...) is
+	// cancelled on timeout as well as on client disconnect. Handlers see it through
+	// c.Request.Context(), or through the Context itself when ContextWithFallback is enabled.
+	RequestTimeout time.Duration
+
+	// RequestTimeoutFunc if set, returns the timeout of a given request, overriding RequestTimeout.
+	// Returning zero or less leaves the request without deadline.
+	RequestTimeoutFunc func(req *http.Request) time.Duration
+
+	// SlowRequestThreshold if positive, the requests whose handlers take at least that long are
+	// reported to SlowRequestFunc and tagged as slow in the lines of the Logger middleware, to
+	// catch latency regressions without a full APM.
+	SlowRequestThreshold time.Duration
+
+	// SlowRequestFunc if set, is called after the handlers of a slow request, with the time they
+	// took and the template of the matched route (empty for unmatched requests). It can be used
+	// to log the request or to mark its tracing span, found in c.Request.Context().
+	SlowRequestFunc func(c *Context, duration time.Duration, route string)
+
+	delims             render.Delims
+	secureJSONPrefix   string
+	HTMLRender         render.HTMLRender
+	FuncMap            template.FuncMap
+	viewGlobals        []func(c *Context) map[string]any
+	assets             *Assets
+	allNoRoute         HandlersChain
+	allNoMethod        HandlersChain
+	noRoute            HandlersChain
+	noMethod           HandlersChain
+	postMatch          HandlersChain
+	pool               sync.Pool
+	trees              methodTrees
+	maxParams          uint16
+	maxSections        uint16
+	trustedProxies     []string
+	trustedCIDRs       []*net.IPNet
+	jsonBinding        binding.BindingBody
+	translator         *binding.Translator
+	cookieKeys         []cookieKey
+	urlKeys            [][]byte
+	maintenance        atomic.Pointer[maintenance]
+	config             atomic.Pointer[appliedConfig]
+	presets            atomic.Pointer[trustedPresets]
+	forwardingWarnings forwardingWarnings
+	servers            serverTracker
+	certificates       certStore
+	httpsRedirect      *HTTPSRedirectConfig
+	contextStats       contextPoolStats
+	background         backgroundJobs
+	schedules          scheduler
+	warmup             warmup
+	frozen             frozenRoutes
+	fragments          fragmentCache
+	groups             []*RouterGroup
+
+	handlerRegistryMu sync.RWMutex
+	handlerRegistry   map[string]HandlerFunc
+	loadedRoutes      atomic.Pointer[loadedRoutes]
 }
 
 var _ IRouter = (*Engine)(nil)
@@ -199,6 +345,7 @@ func New() *Engine {
 		RemoveExtraSlash:       false,
 		UnescapePathValues:     true,
 		MaxMultipartMemory:     defaultMultipartMemory,
+		MaxCachedBodySize:      defaultMultipartMemory,
 		trees:                  make(methodTrees, 0, 9),
 		delims:                 render.Delims{Left: "{{", Right: "}}"},
 		secureJSONPrefix:       "while(1);",
@@ -207,6 +354,7 @@ func New() *Engine {
 	}
 	engine.RouterGroup.engine = engine
 	engine.pool.New = func() any {
+		engine.contextStats.allocated.Add(1)
 		return engine.allocateContext(engine.maxParams)
 	}
 	return engine
@@ -230,9 +378,12 @@ func (engine *Engine) Handler() http.Handler {
 }
 
 func (engine *Engine) allocateContext(maxParams uint16) *Context {
-	v := make(Params, 0, maxParams)
 	skippedNodes := make([]skippedNode, 0, engine.maxSections)
-	return &Context{engine: engine, params: &v, skippedNodes: &skippedNodes}
+	c := &Context{engine: engine, skippedNodes: &skippedNodes}
+	c.paramsBuf = c.paramsArray[:0]
+	c.params = &c.paramsBuf
+	c.growRouting(maxParams, engine.maxSections)
+	return c
 }
 
 // Delims sets template left and right delims and returns an Engine instance.
@@ -247,27 +398,85 @@ func (engine *Engine) SecureJsonPrefix(prefix string) *Engine {
 	return engine
 }
 
+// SetJSONBindingOptions configures the JSON binding used by Context.Bind, Context.BindJSON,
+// Context.ShouldBind and Context.ShouldBindJSON for this engine, e.g. to reject unknown
+// fields or limit the nesting depth and size of the body.
+func (engine *Engine) SetJSONBindingOptions(opts binding.JSONOptions) *Engine {
+	engine.jsonBinding = binding.JSONWithOptions(opts)
+	return engine
+}
+
+// SetValidatorTranslator enables translated validation messages for Context.BindAndValidate.
+// The locale of each request is negotiated from its Accept-Language header, falling back to
+// the first of the given locales ("en" when none is given). The locales must have been
+// registered with binding.RegisterTranslation, or by importing the binding/translations package:
+//
+//	import _ "github.com/gin-gonic/gin/binding/translations"
+func (engine *Engine) SetValidatorTranslator(locales ...string) error {
+	translator, err := binding.NewTranslator(locales...)
+	if err != nil {
+		return err
+	}
+	engine.translator = translator
+	return nil
+}
+
 // LoadHTMLGlob loads HTML files identified by glob pattern
 // and associates the result with HTML renderer.
 func (engine *Engine) LoadHTMLGlob(pattern string) {
+	engine.InvalidateFragments()
 	left := engine.delims.Left
 	right := engine.delims.Right
 	templ := template.Must(template.New("").Delims(left, right).Funcs(engine.FuncMap).ParseGlob(pattern))
 
 	if IsDebugging() {
 		debugPrintLoadTemplate(templ)
-		engine.HTMLRender = render.HTMLDebug{Glob: pattern, FuncMap: engine.FuncMap, Delims: engine.delims}
+		engine.HTMLRender = render.HTMLDebug{Glob: pattern, FuncMap: engine.FuncMap, Delims: engine.delims, Cache: new(render.TemplateCache)}
+		return
+	}
+
+	engine.SetHTMLTemplate(templ)
+}
+
+// LoadHTMLFS loads the HTML files matching the patterns from the given fs.FS, e.g. an embed.FS,
+// and associates the result with HTML renderer.
+// The patterns follow the semantics of fs.Glob.
+func (engine *Engine) LoadHTMLFS(fsys fs.FS, patterns ...string) {
+	engine.InvalidateFragments()
+	if IsDebugging() {
+		engine.HTMLRender = render.HTMLDebug{FileSystem: fsys, Patterns: patterns, FuncMap: engine.FuncMap, Delims: engine.delims, Cache: new(render.TemplateCache)}
 		return
 	}
 
+	templ := template.Must(template.New("").Delims(engine.delims.Left, engine.delims.Right).Funcs(engine.FuncMap).ParseFS(fsys, patterns...))
 	engine.SetHTMLTemplate(templ)
 }
 
+// LoadHTMLGlobWithLayouts loads the layouts identified by layoutGlob and the pages identified
+// by pageGlob, composing every page with the layouts, and associates the result with HTML renderer.
+// Layouts declare sections with {{block "name" .}} that pages override with {{define "name"}}.
+// Pages are rendered by their file base name, e.g. c.HTML(200, "index.tmpl", data).
+// In debug mode the templates are re-parsed whenever one of the files changes.
+func (engine *Engine) LoadHTMLGlobWithLayouts(layoutGlob, pageGlob string) {
+	engine.InvalidateFragments()
+	engine.HTMLRender = engine.newHTMLLayout(layoutGlob, pageGlob, engine.FuncMap)
+}
+
+func (engine *Engine) newHTMLLayout(layoutGlob, pageGlob string, funcMap template.FuncMap) *render.HTMLLayout {
+	r, err := render.NewHTMLLayout(layoutGlob, pageGlob, engine.delims, funcMap, IsDebugging())
+	if err != nil {
+		panic(err)
+	}
+	debugPrintLoadLayoutPages(r.Pages())
+	return r
+}
+
 // LoadHTMLFiles loads a slice of HTML files
 // and associates the result with HTML renderer.
 func (engine *Engine) LoadHTMLFiles(files ...string) {
+	engine.InvalidateFragments()
 	if IsDebugging() {
-		engine.HTMLRender = render.HTMLDebug{Files: files, FuncMap: engine.FuncMap, Delims: engine.delims}
+		engine.HTMLRender = render.HTMLDebug{Files: files, FuncMap: engine.FuncMap, Delims: engine.delims, Cache: new(render.TemplateCache)}
 		return
 	}
 
@@ -277,6 +486,7 @@ func (engine *Engine) LoadHTMLFiles(files ...string) {
 
 // SetHTMLTemplate associate a template with HTML renderer.
 func (engine *Engine) SetHTMLTemplate(templ *template.Template) {
+	engine.InvalidateFragments()
 	if len(engine.trees) > 0 {
 		debugPrintWARNINGSetHTMLTemplate()
 	}
@@ -284,6 +494,41 @@ func (engine *Engine) SetHTMLTemplate(templ *template.Template) {
 	engine.HTMLRender = render.HTMLProduction{Template: templ.Funcs(engine.FuncMap)}
 }
 
+// SetHTMLRenderer associates a custom HTML template engine with the HTML renderer.
+// It replaces any templates previously loaded with LoadHTMLGlob, LoadHTMLFiles or SetHTMLTemplate.
+func (engine *Engine) SetHTMLRenderer(renderer HTMLRenderer) {
+	engine.InvalidateFragments()
+	if len(engine.trees) > 0 {
+		debugPrintWARNINGSetHTMLRenderer()
+	}
+
+	engine.HTMLRender = renderer
+}
+
+// TemplateCacheStats reports how often the debug-mode templates were parsed.
+type TemplateCacheStats = render.TemplateCacheStats
+
+// TemplateCacheStats returns the statistics of the templates loaded in debug
+// mode, which are parsed again only when one of their files changes. ok is
+// false when the HTML renderer does not reload its templates.
+func (engine *Engine) TemplateCacheStats() (stats TemplateCacheStats, ok bool) {
+	return templateCacheStats(engine.HTMLRender)
+}
+
+func templateCacheStats(r render.HTMLRender) (stats TemplateCacheStats, ok bool) {
+	switch r := r.(type) {
+	case render.HTMLDebug:
+		if r.Cache != nil {
+			return r.Cache.Stats(), true
+		}
+	case *render.HTMLLayout:
+		if r.Reload {
+			return r.Stats(), true
+		}
+	}
+	return stats, false
+}
+
 // SetFuncMap sets the FuncMap used for template.FuncMap.
 func (engine *Engine) SetFuncMap(funcMap template.FuncMap) {
 	engine.FuncMap = funcMap
@@ -311,6 +556,35 @@ func (engine *Engine) Use(middleware ...HandlerFunc) IRoutes {
 	return engine
 }
 
+// UsePostMatch attaches middleware which runs after the route of a request has been
+// found, between the global middleware of Use (e.g. Recovery and Logger) and the
+// rest of its handlers chain, so that Context.FullPath, Context.Params and
+// Context.RouteMeta are available. They only run
+// for the requests matching a route, not for the 404 and 405 handlers, and apply to
+// the routes registered before and after the call. This suits metrics or
+// authorization keyed by route template.
+func (engine *Engine) UsePostMatch(middleware ...HandlerFunc) {
+	longest := 0
+	for _, tree := range engine.trees {
+		tree.root.walk(func(n *node) {
+			if len(n.handlers) > longest {
+				longest = len(n.handlers)
+			}
+		})
+	}
+	assert1(longest+len(engine.postMatch)+len(middleware) < int(abortIndex), "too many handlers")
+	engine.postMatch = append(engine.postMatch, middleware...)
+}
+
+// UseExcept attaches a global middleware to the router, except for the routes registered
+// with the given paths. See RouterGroup.UseExcept.
+func (engine *Engine) UseExcept(middleware HandlerFunc, paths ...string) IRoutes {
+	engine.RouterGroup.UseExcept(middleware, paths...)
+	engine.rebuild404Handlers()
+	engine.rebuild405Handlers()
+	return engine
+}
+
 func (engine *Engine) rebuild404Handlers() {
 	engine.allNoRoute = engine.combineHandlers(engine.noRoute)
 }
@@ -319,10 +593,14 @@ func (engine *Engine) rebuild405Handlers() {
 	engine.allNoMethod = engine.combineHandlers(engine.noMethod)
 }
 
-func (engine *Engine) addRoute(method, path string, handlers HandlersChain) {
+// addRoute registers the route and returns its node, which is only valid until
+// the next route of the method is registered.
+func (engine *Engine) addRoute(method, path string, handlers HandlersChain) *node {
 	assert1(path[0] == '/', "path must begin with '/'")
 	assert1(method != "", "HTTP method can not be empty")
 	assert1(len(handlers) > 0, "there must be at least one handler")
+	assert1(len(handlers)+len(engine.postMatch) < int(abortIndex), "too many handlers")
+	assert1(engine.frozen == nil, "routes can not be added after Freeze")
 
 	debugPrintRoute(method, path, handlers)
 
@@ -332,7 +610,7 @@ func (engine *Engine) addRoute(method, path string, handlers HandlersChain) {
 		root.fullPath = "/"
 		engine.trees = append(engine.trees, methodTree{method: method, root: root})
 	}
-	root.addRoute(path, handlers)
+	route := root.addRoute(path, handlers)
 
 	// Update maxParams
 	if paramsCount := countParams(path); paramsCount > engine.maxParams {
@@ -342,6 +620,7 @@ func (engine *Engine) addRoute(method, path string, handlers HandlersChain) {
 	if sectionsCount := countSections(path); sectionsCount > engine.maxSections {
 		engine.maxSections = sectionsCount
 	}
+	return route
 }
 
 // Routes returns a slice of registered routes, including some useful information, such as:
@@ -362,6 +641,8 @@ func iterate(path, method string, routes RoutesInfo, root *node) RoutesInfo {
 			Path:        path,
 			Handler:     nameOfFunction(handlerFunc),
 			HandlerFunc: handlerFunc,
+			Meta:        root.meta,
+			Name:        root.name,
 		})
 	}
 	for _, child := range root.children {
@@ -377,13 +658,17 @@ func (engine *Engine) Run(addr ...string) (err error) {
 	defer func() { debugPrintError(err) }()
 
 	if engine.isUnsafeTrustedProxies() {
-		debugPrint("[WARNING] You trusted all proxies, this is NOT safe. We recommend you to set a value.\n" +
+		debugPrintWarning("You trusted all proxies, this is NOT safe. We recommend you to set a value.\n" +
 			"Please check https://pkg.go.dev/github.com/gin-gonic/gin#readme-don-t-trust-all-proxies for details.")
 	}
 
 	address := resolveAddress(addr)
-	debugPrint("Listening and serving HTTP on %s\n", address)
-	err = http.ListenAndServe(address, engine.Handler())
+	debugPrintInfo("Listening and serving HTTP on %s\n", address)
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return
+	}
+	err = engine.serve(listener, serveHTTP)
 	return
 }
 
@@ -391,9 +676,14 @@ func (engine *Engine) prepareTrustedCIDRs() ([]*net.IPNet, error) {
 	if engine.trustedProxies == nil {
 		return nil, nil
 	}
+	return parseCIDRs(engine.trustedProxies)
+}
 
-	cidr := make([]*net.IPNet, 0, len(engine.trustedProxies))
-	for _, trustedProxy := range engine.trustedProxies {
+// parseCIDRs parses a list of IP addresses and CIDRs into networks. The
+// networks parsed before an invalid entry are returned with the error.
+func parseCIDRs(list []string) ([]*net.IPNet, error) {
+	cidr := make([]*net.IPNet, 0, len(list))
+	for _, trustedProxy := range list {
 		if !strings.Contains(trustedProxy, "/") {
 			ip := parseIP(trustedProxy)
 			if ip == nil {
@@ -425,6 +715,7 @@ func (engine *Engine) prepareTrustedCIDRs() ([]*net.IPNet, error) {
 // Engine.SetTrustedProxies(nil), then Context.ClientIP() will
 // return the remote address directly.
 func (engine *Engine) SetTrustedProxies(trustedProxies []string) error {
+	engine.presets.Store(nil)
 	engine.trustedProxies = trustedProxies
 	return engine.parseTrustedProxies()
 }
@@ -443,10 +734,16 @@ func (engine *Engine) parseTrustedProxies() error {
 
 // isTrustedProxy will check whether the IP address is included in the trusted list according to Engine.trustedCIDRs
 func (engine *Engine) isTrustedProxy(ip net.IP) bool {
-	if engine.trustedCIDRs == nil {
+	trustedCIDRs := engine.trustedCIDRs
+	if applied := engine.config.Load(); applied != nil && applied.config.TrustedProxies != nil {
+		trustedCIDRs = applied.trustedCIDRs
+	} else if presets := engine.presets.Load(); presets != nil {
+		trustedCIDRs = presets.cidrs
+	}
+	if trustedCIDRs == nil {
 		return false
 	}
-	for _, cidr := range engine.trustedCIDRs {
+	for _, cidr := range trustedCIDRs {
 		if cidr.Contains(ip) {
 			return true
 		}
@@ -492,52 +789,49 @@ func parseIP(ip string) net.IP {
 
 // RunTLS attaches the router to a http.Server and starts listening and serving HTTPS (secure) requests.
 // It is a shortcut for http.ListenAndServeTLS(addr, certFile, keyFile, router)
+// The certificates added with AddCertificate are served to the matching hosts,
+// certFile and keyFile to the others; they may be empty when a "*" certificate is added.
 // Note: this method will block the calling goroutine indefinitely unless an error happens.
 func (engine *Engine) RunTLS(addr, certFile, keyFile string) (err error) {
-	debugPrint("Listening and serving HTTPS on %s\n", addr)
+	debugPrintInfo("Listening and serving HTTPS on %s\n", addr)
 	defer func() { debugPrintError(err) }()
 
 	if engine.isUnsafeTrustedProxies() {
-		debugPrint("[WARNING] You trusted all proxies, this is NOT safe. We recommend you to set a value.\n" +
+		debugPrintWarning("You trusted all proxies, this is NOT safe. We recommend you to set a value.\n" +
 			"Please check https://pkg.go.dev/github.com/gin-gonic/gin#readme-don-t-trust-all-proxies for details.")
 	}
 
-	err = http.ListenAndServeTLS(addr, certFile, keyFile, engine.Handler())
+	if addr == "" {
+		addr = ":https"
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return
+	}
+	err = engine.serveTLS(listener, func(srv *http.Server, listener net.Listener) error {
+		srv.TLSConfig = engine.tlsConfig(nil)
+		return srv.ServeTLS(listener, certFile, keyFile)
+	})
 	return
 }
 
 // RunUnix attaches the router to a http.Server and starts listening and serving HTTP requests
-// through the specified unix socket (i.e. a file).
+// through the specified unix socket (i.e. a file). See RunUnixWithConfig to set the
+// file mode and ownership of the socket.
 // Note: this method will block the calling goroutine indefinitely unless an error happens.
 func (engine *Engine) RunUnix(file string) (err error) {
-	debugPrint("Listening and serving HTTP on unix:/%s", file)
-	defer func() { debugPrintError(err) }()
-
-	if engine.isUnsafeTrustedProxies() {
-		debugPrint("[WARNING] You trusted all proxies, this is NOT safe. We recommend you to set a value.\n" +
-			"Please check https://github.com/gin-gonic/gin/blob/master/docs/doc.md#dont-trust-all-proxies for details.")
-	}
-
-	listener, err := net.Listen("unix", file)
-	if err != nil {
-		return
-	}
-	defer listener.Close()
-	defer os.Remove(file)
-
-	err = http.Serve(listener, engine.Handler())
-	return
+	return engine.RunUnixWithConfig(file, UnixSocketConfig{})
 }
 
 // RunFd attaches the router to a http.Server and starts listening and serving HTTP requests
 // through the specified file descriptor.
 // Note: this method will block the calling goroutine indefinitely unless an error happens.
 func (engine *Engine) RunFd(fd int) (err error) {
-	debugPrint("Listening and serving HTTP on fd@%d", fd)
+	debugPrintInfo("Listening and serving HTTP on fd@%d", fd)
 	defer func() { debugPrintError(err) }()
 
 	if engine.isUnsafeTrustedProxies() {
-		debugPrint("[WARNING] You trusted all proxies, this is NOT safe. We recommend you to set a value.\n" +
+		debugPrintWarning("You trusted all proxies, this is NOT safe. We recommend you to set a value.\n" +
 			"Please check https://github.com/gin-gonic/gin/blob/master/docs/doc.md#dont-trust-all-proxies for details.")
 	}
 
@@ -554,33 +848,65 @@ func (engine *Engine) RunFd(fd int) (err error) {
 // RunListener attaches the router to a http.Server and starts listening and serving HTTP requests
 // through the specified net.Listener
 func (engine *Engine) RunListener(listener net.Listener) (err error) {
-	debugPrint("Listening and serving HTTP on listener what's bind with address@%s", listener.Addr())
+	debugPrintInfo("Listening and serving HTTP on listener what's bind with address@%s", listener.Addr())
 	defer func() { debugPrintError(err) }()
 
 	if engine.isUnsafeTrustedProxies() {
-		debugPrint("[WARNING] You trusted all proxies, this is NOT safe. We recommend you to set a value.\n" +
+		debugPrintWarning("You trusted all proxies, this is NOT safe. We recommend you to set a value.\n" +
 			"Please check https://github.com/gin-gonic/gin/blob/master/docs/doc.md#dont-trust-all-proxies for details.")
 	}
 
-	err = http.Serve(listener, engine.Handler())
+	serve := serveHTTP
+	if engine.ProxyProtocol {
+		// outermost, so that the connection limits don't wait for the PROXY headers.
+		serve = func(srv *http.Server, listener net.Listener) error {
+			return srv.Serve(NewProxyProtocolListener(listener))
+		}
+	}
+	err = engine.serve(listener, serve)
 	return
 }
 
 // ServeHTTP conforms to the http.Handler interface.
 func (engine *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if timeout := engine.requestTimeout(req); timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
 	c := engine.pool.Get().(*Context)
+	engine.contextStats.acquired.Add(1)
 	c.writermem.reset(w)
 	c.Request = req
 	c.reset()
 
-	engine.handleHTTPRequest(c)
+	if engine.SlowRequestThreshold > 0 && engine.SlowRequestFunc != nil {
+		start := time.Now()
+		engine.handleHTTPRequest(c)
+		if d := time.Since(start); d >= engine.SlowRequestThreshold {
+			engine.SlowRequestFunc(c, d, c.fullPath)
+		}
+	} else {
+		engine.handleHTTPRequest(c)
+	}
+	c.finish()
+	c.runDeferred()
 
-	engine.pool.Put(c)
+	engine.releaseContext(c)
+}
+
+func (engine *Engine) requestTimeout(req *http.Request) time.Duration {
+	if engine.RequestTimeoutFunc != nil {
+		return engine.RequestTimeoutFunc(req)
+	}
+	return engine.RequestTimeout
 }
 
 // HandleContext re-enters a context that has been rewritten.
 // This can be done by setting c.Request.URL.Path to your new target.
 // Disclaimer: You can loop yourself to deal with this, use wisely.
+// Context.Forward is a safer alternative.
 func (engine *Engine) HandleContext(c *Context) {
 	oldIndexValue := c.index
 	c.reset()
@@ -602,6 +928,22 @@ func (engine *Engine) handleHTTPRequest(c *Context) {
 		rPath = cleanPath(rPath)
 	}
 
+	loaded := engine.loadedRoutes.Load()
+	tsr := false
+	if loaded != nil {
+		var served bool
+		if served, tsr = loaded.serve(c, rPath, unescape); served {
+			return
+		}
+	}
+
+	if n := engine.frozen.get(httpMethod, rPath); n != nil {
+		engine.serveMatched(c, nodeValue{handlers: n.handlers, fullPath: n.fullPath, meta: n.meta, name: n.name, global: n.global})
+		c.Next()
+		c.writermem.WriteHeaderNow()
+		return
+	}
+
 	// Find root of the tree for the given HTTP method
 	t := engine.trees
 	for i, tl := 0, len(t); i < tl; i++ {
@@ -610,38 +952,54 @@ func (engine *Engine) handleHTTPRequest(c *Context) {
 		}
 		root := t[i].root
 		// Find route in tree
+		c.growRouting(engine.maxParams, engine.maxSections)
 		value := root.getValue(rPath, c.params, c.skippedNodes, unescape)
 		if value.params != nil {
 			c.Params = *value.params
 		}
 		if value.handlers != nil {
-			c.handlers = value.handlers
-			c.fullPath = value.fullPath
+			engine.serveMatched(c, value)
 			c.Next()
 			c.writermem.WriteHeaderNow()
 			return
 		}
-		if httpMethod != http.MethodConnect && rPath != "/" {
-			if value.tsr && engine.RedirectTrailingSlash {
-				redirectTrailingSlash(c)
-				return
-			}
-			if engine.RedirectFixedPath && redirectFixedPath(c, root, engine.RedirectFixedPath) {
-				return
+		tsr = tsr || value.tsr
+		break
+	}
+
+	if httpMethod != http.MethodConnect && rPath != "/" {
+		if tsr && engine.RedirectTrailingSlash {
+			redirectTrailingSlash(c)
+			return
+		}
+		if engine.RedirectFixedPath {
+			for _, trees := range []methodTrees{loaded.methodTrees(), engine.trees} {
+				if root := trees.get(httpMethod); root != nil && redirectFixedPath(c, root, engine.RedirectFixedPath) {
+					return
+				}
 			}
 		}
-		break
+	}
+
+	if httpMethod == http.MethodHead && engine.HandleHEAD && engine.serveHead(c, rPath, unescape) {
+		return
 	}
 
 	if engine.HandleMethodNotAllowed {
-		for _, tree := range engine.trees {
-			if tree.method == httpMethod {
-				continue
-			}
-			if value := tree.root.getValue(rPath, nil, c.skippedNodes, unescape); value.handlers != nil {
-				c.handlers = engine.allNoMethod
-				serveError(c, http.StatusMethodNotAllowed, default405Body)
-				return
+		if loaded != nil {
+			c.growRouting(loaded.maxParams, loaded.maxSections)
+		}
+		for _, trees := range []methodTrees{loaded.methodTrees(), engine.trees} {
+			for _, tree := range trees {
+				if tree.method == httpMethod {
+					continue
+				}
+				if value := tree.root.getValue(rPath, nil, c.skippedNodes, unescape); value.handlers != nil {
+					c.routeTemplate = value.fullPath
+					c.handlers = engine.allNoMethod
+					serveError(c, http.StatusMethodNotAllowed, default405Body)
+					return
+				}
 			}
 		}
 	}
@@ -649,6 +1007,22 @@ func (engine *Engine) handleHTTPRequest(c *Context) {
 	serveError(c, http.StatusNotFound, default404Body)
 }
 
+// serveMatched prepares c to run the handlers of the matched route.
+func (engine *Engine) serveMatched(c *Context, value nodeValue) {
+	c.handlers = value.handlers
+	c.fullPath = value.fullPath
+	c.routeMeta = value.meta
+	c.routeName = value.name
+	if len(engine.postMatch) > 0 {
+		// the post-match middleware run after the global ones, e.g. within
+		// Recovery and Logger.
+		c.chain = append(c.chain[:0], value.handlers[:value.global]...)
+		c.chain = append(append(c.chain, engine.postMatch...), value.handlers[value.global:]...)
+		c.handlers = c.chain
+	}
+	engine.applyMaintenance(c)
+}
+
 var mimePlain = []string{MIMEPlain}
 
 func serveError(c *Context, code int, defaultMessage []byte) {
@@ -659,6 +1033,9 @@ func serveError(c *Context, code int, defaultMessage []byte) {
 	}
 	if c.writermem.Status() == code {
 		c.writermem.Header()["Content-Type"] = mimePlain
+		if c.engine.SuggestRoutes && IsDebugging() {
+			defaultMessage = suggestionsBody(c, defaultMessage)
+		}
 		_, err := c.Writer.Write(defaultMessage)
 		if err != nil {
 			debugPrint("cannot write message to writer during serve error: %v", err)
@@ -5,7 +5,9 @@
 package ginS
 
 import (
+	"crypto/tls"
 	"html/template"
+	"io/fs"
 	"net/http"
 	"sync"
 
@@ -27,16 +29,31 @@ func LoadHTMLGlob(pattern string) {
 	engine().LoadHTMLGlob(pattern)
 }
 
+// LoadHTMLGlobWithLayouts is a wrapper for Engine.LoadHTMLGlobWithLayouts.
+func LoadHTMLGlobWithLayouts(layoutGlob, pageGlob string) {
+	engine().LoadHTMLGlobWithLayouts(layoutGlob, pageGlob)
+}
+
 // LoadHTMLFiles is a wrapper for Engine.LoadHTMLFiles.
 func LoadHTMLFiles(files ...string) {
 	engine().LoadHTMLFiles(files...)
 }
 
+// LoadHTMLFS is a wrapper for Engine.LoadHTMLFS.
+func LoadHTMLFS(fsys fs.FS, patterns ...string) {
+	engine().LoadHTMLFS(fsys, patterns...)
+}
+
 // SetHTMLTemplate is a wrapper for Engine.SetHTMLTemplate.
 func SetHTMLTemplate(templ *template.Template) {
 	engine().SetHTMLTemplate(templ)
 }
 
+// SetHTMLRenderer is a wrapper for Engine.SetHTMLRenderer.
+func SetHTMLRenderer(renderer gin.HTMLRenderer) {
+	engine().SetHTMLRenderer(renderer)
+}
+
 // NoRoute adds handlers for NoRoute. It returns a 404 code by default.
 func NoRoute(handlers ...gin.HandlerFunc) {
 	engine().NoRoute(handlers...)
@@ -145,6 +162,13 @@ func RunTLS(addr, certFile, keyFile string) (err error) {
 	return engine().RunTLS(addr, certFile, keyFile)
 }
 
+// RunMTLS attaches to a http.Server and starts listening and serving HTTPS requests
+// with client certificate authentication.
+// Note: this method will block the calling goroutine indefinitely unless an error happens.
+func RunMTLS(addr, certFile, keyFile, clientCAFile string, clientAuth tls.ClientAuthType) (err error) {
+	return engine().RunMTLS(addr, certFile, keyFile, clientCAFile, clientAuth)
+}
+
 // RunUnix attaches to a http.Server and starts listening and serving HTTP requests
 // through the specified unix socket (i.e. a file)
 // Note: this method will block the calling goroutine indefinitely unless an error happens.
@@ -152,6 +176,18 @@ func RunUnix(file string) (err error) {
 	return engine().RunUnix(file)
 }
 
+// RunUnixWithConfig is RunUnix with a config for the socket file.
+// Note: this method will block the calling goroutine indefinitely unless an error happens.
+func RunUnixWithConfig(file string, config gin.UnixSocketConfig) (err error) {
+	return engine().RunUnixWithConfig(file, config)
+}
+
+// RunActivated serves plain HTTP on the sockets passed by systemd socket activation.
+// Note: this method will block the calling goroutine indefinitely unless an error happens.
+func RunActivated() (err error) {
+	return engine().RunActivated()
+}
+
 // RunFd attaches the router to a http.Server and starts listening and serving HTTP requests
 // through the specified file descriptor.
 // Note: the method will block the calling goroutine indefinitely unless on error happens.
@@ -12,13 +12,16 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"reflect"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/net/http2"
 )
 
@@ -80,6 +83,33 @@ func TestLoadHTMLGlobDebugMode(t *testing.T) {
 	assert.Equal(t, "<h1>Hello world</h1>", string(resp))
 }
 
+func TestLoadHTMLGlobDebugModeCachesTemplates(t *testing.T) {
+	var router *Engine
+	ts := setupHTMLFiles(
+		t,
+		DebugMode,
+		false,
+		func(r *Engine) {
+			router = r
+			router.LoadHTMLGlob("./testdata/template/*")
+		},
+	)
+	defer ts.Close()
+
+	for i := 0; i < 3; i++ {
+		res, err := http.Get(fmt.Sprintf("%s/test", ts.URL))
+		require.NoError(t, err)
+		res.Body.Close()
+	}
+	stats, ok := router.TemplateCacheStats()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), stats.Reloads)
+	assert.Equal(t, uint64(2), stats.Hits)
+
+	_, ok = New().TemplateCacheStats()
+	assert.False(t, ok)
+}
+
 func TestH2c(t *testing.T) {
 	ln, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
@@ -303,6 +333,46 @@ func TestLoadHTMLFilesUsingTLS(t *testing.T) {
 	assert.Equal(t, "<h1>Hello world</h1>", string(resp))
 }
 
+func TestLoadHTMLFSDebugMode(t *testing.T) {
+	ts := setupHTMLFiles(
+		t,
+		DebugMode,
+		false,
+		func(router *Engine) {
+			router.LoadHTMLFS(os.DirFS("./testdata/template"), "*.tmpl")
+		},
+	)
+	defer ts.Close()
+
+	res, err := http.Get(fmt.Sprintf("%s/test", ts.URL))
+	if err != nil {
+		t.Error(err)
+	}
+
+	resp, _ := io.ReadAll(res.Body)
+	assert.Equal(t, "<h1>Hello world</h1>", string(resp))
+}
+
+func TestLoadHTMLFSReleaseMode(t *testing.T) {
+	ts := setupHTMLFiles(
+		t,
+		ReleaseMode,
+		false,
+		func(router *Engine) {
+			router.LoadHTMLFS(os.DirFS("./testdata/template"), "*.tmpl")
+		},
+	)
+	defer ts.Close()
+
+	res, err := http.Get(fmt.Sprintf("%s/test", ts.URL))
+	if err != nil {
+		t.Error(err)
+	}
+
+	resp, _ := io.ReadAll(res.Body)
+	assert.Equal(t, "<h1>Hello world</h1>", string(resp))
+}
+
 func TestLoadHTMLFilesFuncMap(t *testing.T) {
 	ts := setupHTMLFiles(
 		t,
@@ -534,6 +604,117 @@ func TestEngineHandleContext(t *testing.T) {
 	})
 }
 
+func TestEngineRequestTimeout(t *testing.T) {
+	r := New()
+	r.ContextWithFallback = true
+	r.RequestTimeout = 10 * time.Millisecond
+	r.GET("/slow", func(c *Context) {
+		deadline, ok := c.Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(10*time.Millisecond), deadline, 10*time.Millisecond)
+		select {
+		case <-c.Done():
+			c.String(http.StatusGatewayTimeout, c.Err().Error())
+		case <-time.After(time.Second):
+			c.String(http.StatusOK, "done")
+		}
+	})
+
+	w := PerformRequest(r, http.MethodGet, "/slow")
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Equal(t, "context deadline exceeded", w.Body.String())
+
+	r.RequestTimeoutFunc = func(req *http.Request) time.Duration { return 0 }
+	r.GET("/none", func(c *Context) {
+		_, ok := c.Request.Context().Deadline()
+		assert.False(t, ok)
+	})
+	w = PerformRequest(r, http.MethodGet, "/none")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestEngineUsePostMatch(t *testing.T) {
+	var calls []string
+	r := New()
+	r.HandleMethodNotAllowed = true
+	r.Use(func(c *Context) { calls = append(calls, "use") })
+	r.GET("/users/:id", func(c *Context) { calls = append(calls, "handler") }).(RouteMetaSetter).Meta("perm", "users.read")
+	r.UsePostMatch(func(c *Context) {
+		perm, _ := c.RouteMeta("perm")
+		calls = append(calls, fmt.Sprintf("post-match %s %s %v", c.FullPath(), c.Param("id"), perm))
+		if c.Param("id") == "0" {
+			c.AbortWithStatus(http.StatusForbidden)
+		}
+	})
+	r.GET("/ping", func(c *Context) { calls = append(calls, "ping") })
+
+	PerformRequest(r, http.MethodGet, "/users/1")
+	assert.Equal(t, []string{"use", "post-match /users/:id 1 users.read", "handler"}, calls)
+
+	calls = nil
+	w := PerformRequest(r, http.MethodGet, "/users/0")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, []string{"use", "post-match /users/:id 0 users.read"}, calls)
+
+	calls = nil
+	PerformRequest(r, http.MethodGet, "/ping")
+	assert.Equal(t, []string{"use", "post-match /ping  <nil>", "ping"}, calls)
+
+	// not run for unmatched requests.
+	calls = nil
+	PerformRequest(r, http.MethodGet, "/missing")
+	PerformRequest(r, http.MethodPost, "/ping")
+	assert.Equal(t, []string{"use", "use"}, calls)
+
+	// after the global middleware, but before the group and excluded ones.
+	calls = nil
+	r.UseExcept(func(c *Context) { calls = append(calls, "except") }, "/api/skip")
+	api := r.Group("/api", func(c *Context) { calls = append(calls, "group") })
+	api.GET("/items", func(c *Context) { calls = append(calls, "items") })
+	api.GET("/skip", func(c *Context) { calls = append(calls, "skip") })
+	PerformRequest(r, http.MethodGet, "/api/items")
+	assert.Equal(t, []string{"use", "except", "post-match /api/items  <nil>", "group", "items"}, calls)
+	calls = nil
+	PerformRequest(r, http.MethodGet, "/api/skip")
+	assert.Equal(t, []string{"use", "post-match /api/skip  <nil>", "group", "skip"}, calls)
+
+	// within Recovery.
+	r.Use(Recovery())
+	r.UsePostMatch(func(c *Context) {
+		if c.Param("id") == "panic" {
+			panic("post-match")
+		}
+	})
+	r.GET("/panic/:id", func(c *Context) {})
+	w = PerformRequest(r, http.MethodGet, "/panic/panic")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	assert.Panics(t, func() { r.UsePostMatch(make([]HandlerFunc, abortIndex)...) })
+}
+
+func TestEngineSlowRequest(t *testing.T) {
+	var reported []string
+	buffer := new(strings.Builder)
+	r := New()
+	r.SlowRequestThreshold = 5 * time.Millisecond
+	r.SlowRequestFunc = func(c *Context, duration time.Duration, route string) {
+		assert.GreaterOrEqual(t, duration, 5*time.Millisecond)
+		reported = append(reported, route)
+	}
+	r.Use(LoggerWithWriter(buffer))
+	r.GET("/slow/:id", func(c *Context) { time.Sleep(10 * time.Millisecond) })
+	r.GET("/fast", func(c *Context) {})
+
+	PerformRequest(r, http.MethodGet, "/slow/1")
+	PerformRequest(r, http.MethodGet, "/fast")
+	assert.Equal(t, []string{"/slow/:id"}, reported)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"/slow/1" [SLOW]`)
+	assert.NotContains(t, lines[1], "[SLOW]")
+}
+
 func TestEngineHandleContextManyReEntries(t *testing.T) {
 	expectValue := 10000
 
@@ -696,3 +877,42 @@ func assertRoutePresent(t *testing.T, gotRoutes RoutesInfo, wantRoute RouteInfo)
 
 func handlerTest1(c *Context) {}
 func handlerTest2(c *Context) {}
+
+func TestLoadHTMLGlobWithLayouts(t *testing.T) {
+	router := New()
+	router.SetFuncMap(template.FuncMap{"upper": strings.ToUpper})
+	router.LoadHTMLGlobWithLayouts("./testdata/layout/layouts/*", "./testdata/layout/pages/*")
+	router.GET("/", func(c *Context) {
+		c.HTML(http.StatusOK, "index.tmpl", H{"name": "gin"})
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "<html><title>Index</title><body><h1>Hello GIN</h1></body></html>", w.Body.String())
+
+	assert.Panics(t, func() {
+		router.LoadHTMLGlobWithLayouts("./testdata/layout/nothing/*", "./testdata/layout/pages/*")
+	})
+}
+
+func TestRouterGroupLoadHTMLGlobWithLayouts(t *testing.T) {
+	router := New()
+	router.SetFuncMap(template.FuncMap{"upper": strings.ToUpper})
+	router.SetHTMLTemplate(template.Must(template.New("index.tmpl").Parse("engine")))
+
+	admin := router.Group("/admin")
+	admin.SetFuncMap(template.FuncMap{"upper": strings.ToLower})
+	admin.LoadHTMLGlobWithLayouts("./testdata/layout/layouts/*", "./testdata/layout/pages/*")
+	admin.GET("/", func(c *Context) {
+		c.HTML(http.StatusOK, "index.tmpl", H{"name": "GIN"})
+	})
+	router.GET("/", func(c *Context) {
+		c.HTML(http.StatusOK, "index.tmpl", nil)
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/admin/")
+	assert.Equal(t, "<html><title>Index</title><body><h1>Hello gin</h1></body></html>", w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, "engine", w.Body.String())
+}
@@ -0,0 +1,443 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/internal/json"
+)
+
+// MIMEGraphQL is the content type of a request whose body is a raw GraphQL query.
+const MIMEGraphQL = "application/graphql"
+
+const (
+	defaultGraphQLMaxMemory  = 32 << 20 // 32 MB
+	defaultGraphQLMaxQueries = 1000
+)
+
+// GraphQLRequest is a GraphQL operation sent by a client.
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	Extensions    map[string]any `json:"extensions,omitempty"`
+}
+
+// GraphQLHandler executes a GraphQL operation with the executor of the
+// application and returns the result, which is sent as JSON. Uploaded files
+// are available in Variables as *multipart.FileHeader values.
+type GraphQLHandler func(c *Context, req *GraphQLRequest) any
+
+// GraphQLQueryStore stores the queries of the automatic persisted queries
+// protocol, keyed by their SHA-256 hash.
+type GraphQLQueryStore interface {
+	Get(hash string) (query string, ok bool)
+	Set(hash, query string)
+}
+
+// GraphQLOptions defines the config of RouterGroup.GraphQL.
+type GraphQLOptions struct {
+	// QueryStore enables automatic persisted queries when set.
+	// Optional. See NewGraphQLQueryStore.
+	QueryStore GraphQLQueryStore
+
+	// PlaygroundPath is the path, relative to the group, of a GraphiQL playground.
+	// Optional. No playground is served when empty.
+	PlaygroundPath string
+
+	// MaxMemory is the memory used to parse multipart (upload) requests.
+	// Optional. Default value is 32 MB.
+	MaxMemory int64
+}
+
+// GraphQLError is an error of the GraphQL response format.
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+var (
+	errGraphQLPersistedQueryNotFound = errors.New("PersistedQueryNotFound")
+	errGraphQLPersistedQueryMismatch = errors.New("provided sha does not match query")
+	errGraphQLMissingQuery           = errors.New("no query provided")
+	errGraphQLMutationMethod         = errors.New("mutations can only be sent with POST")
+)
+
+// GraphQL registers a GraphQL endpoint at relativePath. Operations are accepted
+// as GET query parameters (except mutations), as a JSON or application/graphql
+// POST body, and as multipart requests following the GraphQL multipart request
+// specification for file uploads. The routes are registered on the group, so
+// its middleware (authentication...) applies to them.
+func (group *RouterGroup) GraphQL(relativePath string, handler GraphQLHandler, opts GraphQLOptions) IRoutes {
+	if opts.MaxMemory <= 0 {
+		opts.MaxMemory = defaultGraphQLMaxMemory
+	}
+
+	serve := func(c *Context) {
+		req, status, err := parseGraphQLRequest(c, opts)
+		if err == nil {
+			status, err = resolvePersistedQuery(req, opts.QueryStore)
+		}
+		if err == nil && c.Request.Method != http.MethodPost && req.operationType() == "mutation" {
+			// mutations over GET could be triggered by links and images of other sites.
+			c.Header("Allow", http.MethodPost)
+			status, err = http.StatusMethodNotAllowed, errGraphQLMutationMethod
+		}
+		if err != nil {
+			writeGraphQLError(c, status, err)
+			return
+		}
+		c.JSON(http.StatusOK, handler(c, req))
+	}
+	group.GET(relativePath, serve)
+	group.POST(relativePath, serve)
+
+	if opts.PlaygroundPath != "" {
+		endpoint := group.calculateAbsolutePath(relativePath)
+		group.GET(opts.PlaygroundPath, func(c *Context) {
+			c.Header("Content-Type", MIMEHTML+"; charset=utf-8")
+			c.Status(http.StatusOK)
+			_ = graphiQLTemplate.Execute(c.Writer, endpoint)
+		})
+	}
+	return group.returnObj()
+}
+
+func parseGraphQLRequest(c *Context, opts GraphQLOptions) (*GraphQLRequest, int, error) {
+	req := &GraphQLRequest{}
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		for name, dst := range map[string]*map[string]any{"variables": &req.Variables, "extensions": &req.Extensions} {
+			if v := c.Query(name); v != "" {
+				if err := json.Unmarshal([]byte(v), dst); err != nil {
+					return nil, http.StatusBadRequest, fmt.Errorf("invalid %s: %w", name, err)
+				}
+			}
+		}
+		return req, http.StatusOK, nil
+	}
+
+	switch c.ContentType() {
+	case MIMEGraphQL:
+		body, err := c.GetRawData()
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		req.Query = string(body)
+	case binding.MIMEMultipartPOSTForm:
+		if err := parseGraphQLMultipart(c, req, opts.MaxMemory); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	default:
+		if err := json.NewDecoder(c.Request.Body).Decode(req); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+	return req, http.StatusOK, nil
+}
+
+// graphQLOperation is an operation definition of a GraphQL document.
+type graphQLOperation struct {
+	typ  string
+	name string
+}
+
+// graphQLOperations returns the operations defined by the GraphQL document
+// query: its definitions starting with query, mutation, subscription or with a
+// selection set (the query shorthand). Fragments are skipped.
+func graphQLOperations(query string) []graphQLOperation {
+	var ops []graphQLOperation
+	depth := 0
+	definition := true // the next token starts a definition
+	naming := false    // the next token may be the name of the last operation
+	for i := 0; i < len(query); {
+		ch := query[i]
+		switch {
+		case ch == '#':
+			for i < len(query) && query[i] != '\n' && query[i] != '\r' {
+				i++
+			}
+			continue
+		case ch == '"':
+			i = skipGraphQLString(query, i)
+			continue
+		case isGraphQLNameStart(ch):
+			start := i
+			for i < len(query) && (isGraphQLNameStart(query[i]) || query[i] >= '0' && query[i] <= '9') {
+				i++
+			}
+			if depth > 0 {
+				continue
+			}
+			name := query[start:i]
+			switch {
+			case definition:
+				if name == "query" || name == "mutation" || name == "subscription" {
+					ops = append(ops, graphQLOperation{typ: name})
+					naming = true
+				}
+				definition = false
+			case naming:
+				ops[len(ops)-1].name = name
+				naming = false
+			}
+			continue
+		case ch == '{' || ch == '(' || ch == '[':
+			if depth == 0 && definition && ch == '{' {
+				ops = append(ops, graphQLOperation{typ: "query"})
+			}
+			depth++
+			definition, naming = false, false
+		case ch == '}' || ch == ')' || ch == ']':
+			if depth > 0 {
+				depth--
+			}
+			// only a selection set can bring the depth back to zero.
+			definition = depth == 0 && ch == '}'
+		case ch != ' ' && ch != '\t' && ch != '\n' && ch != '\r' && ch != ',':
+			naming = false
+		}
+		i++
+	}
+	return ops
+}
+
+// skipGraphQLString returns the index following the string or block string
+// starting at query[i].
+func skipGraphQLString(query string, i int) int {
+	if strings.HasPrefix(query[i:], `"""`) {
+		for j := i + 3; j < len(query); j++ {
+			if query[j] == '\\' && strings.HasPrefix(query[j+1:], `"""`) {
+				j += 3
+			} else if strings.HasPrefix(query[j:], `"""`) {
+				return j + 3
+			}
+		}
+		return len(query)
+	}
+	for j := i + 1; j < len(query); j++ {
+		switch query[j] {
+		case '\\':
+			j++
+		case '"', '\n', '\r':
+			return j + 1
+		}
+	}
+	return len(query)
+}
+
+func isGraphQLNameStart(ch byte) bool {
+	return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
+
+// operationType returns the type of the operation of the request which would
+// be executed: "query", "mutation" or "subscription". When the operation can
+// not be selected, it is "mutation" if the document defines any.
+func (req *GraphQLRequest) operationType() string {
+	ops := graphQLOperations(req.Query)
+	mutation := false
+	for _, op := range ops {
+		if op.name == req.OperationName && (req.OperationName != "" || len(ops) == 1) {
+			return op.typ
+		}
+		mutation = mutation || op.typ == "mutation"
+	}
+	if mutation {
+		return "mutation"
+	}
+	return "query"
+}
+
+// parseGraphQLMultipart implements https://github.com/jaydenseric/graphql-multipart-request-spec.
+func parseGraphQLMultipart(c *Context, req *GraphQLRequest, maxMemory int64) error {
+	if err := c.Request.ParseMultipartForm(maxMemory); err != nil {
+		return err
+	}
+	form := c.Request.MultipartForm
+	if err := json.Unmarshal([]byte(firstValue(form.Value["operations"])), req); err != nil {
+		return fmt.Errorf("invalid operations: %w", err)
+	}
+	var fileMap map[string][]string
+	if m := firstValue(form.Value["map"]); m != "" {
+		if err := json.Unmarshal([]byte(m), &fileMap); err != nil {
+			return fmt.Errorf("invalid map: %w", err)
+		}
+	}
+	for key, paths := range fileMap {
+		files := form.File[key]
+		if len(files) == 0 {
+			return fmt.Errorf("missing file %q", key)
+		}
+		for _, path := range paths {
+			name, rest, _ := strings.Cut(path, ".")
+			if name != "variables" || rest == "" {
+				return fmt.Errorf("invalid file path %q", path)
+			}
+			if req.Variables == nil {
+				req.Variables = make(map[string]any)
+			}
+			if err := setGraphQLVariable(req.Variables, strings.Split(rest, "."), files[0]); err != nil {
+				return fmt.Errorf("invalid file path %q: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+func setGraphQLVariable(container any, path []string, value any) error {
+	key := path[0]
+	last := len(path) == 1
+	switch v := container.(type) {
+	case map[string]any:
+		if last {
+			v[key] = value
+			return nil
+		}
+		return setGraphQLVariable(v[key], path[1:], value)
+	case []any:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(v) {
+			return fmt.Errorf("invalid index %q", key)
+		}
+		if last {
+			v[i] = value
+			return nil
+		}
+		return setGraphQLVariable(v[i], path[1:], value)
+	}
+	return fmt.Errorf("%q is not an object or a list", key)
+}
+
+// resolvePersistedQuery implements the automatic persisted queries protocol. As
+// clients expect, a query which is not yet stored is reported with a 200.
+func resolvePersistedQuery(req *GraphQLRequest, store GraphQLQueryStore) (int, error) {
+	ext, _ := req.Extensions["persistedQuery"].(map[string]any)
+	hash, _ := ext["sha256Hash"].(string)
+	if store == nil || hash == "" {
+		if req.Query == "" {
+			return http.StatusBadRequest, errGraphQLMissingQuery
+		}
+		return http.StatusOK, nil
+	}
+
+	if req.Query == "" {
+		query, ok := store.Get(hash)
+		if !ok {
+			return http.StatusOK, errGraphQLPersistedQueryNotFound
+		}
+		req.Query = query
+		return http.StatusOK, nil
+	}
+	sum := sha256.Sum256([]byte(req.Query))
+	if hex.EncodeToString(sum[:]) != hash {
+		return http.StatusBadRequest, errGraphQLPersistedQueryMismatch
+	}
+	store.Set(hash, req.Query)
+	return http.StatusOK, nil
+}
+
+func writeGraphQLError(c *Context, status int, err error) {
+	gqlErr := GraphQLError{Message: err.Error()}
+	if errors.Is(err, errGraphQLPersistedQueryNotFound) {
+		gqlErr.Extensions = map[string]any{"code": "PERSISTED_QUERY_NOT_FOUND"}
+	}
+	_ = c.Error(err)
+	c.AbortWithStatusJSON(status, H{"errors": []GraphQLError{gqlErr}})
+}
+
+func firstValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+type graphQLQueryStore struct {
+	mu         sync.Mutex
+	maxQueries int
+	queries    map[string]*list.Element
+	lru        *list.List
+}
+
+type graphQLStoredQuery struct {
+	hash  string
+	query string
+}
+
+// NewGraphQLQueryStore returns an in-memory GraphQLQueryStore keeping the 1000
+// most recently used queries.
+func NewGraphQLQueryStore() GraphQLQueryStore {
+	return NewGraphQLQueryStoreWithLimit(defaultGraphQLMaxQueries)
+}
+
+// NewGraphQLQueryStoreWithLimit returns an in-memory GraphQLQueryStore keeping
+// the maxQueries most recently used queries. Since any client can store
+// queries, the store must be bounded.
+func NewGraphQLQueryStoreWithLimit(maxQueries int) GraphQLQueryStore {
+	if maxQueries <= 0 {
+		maxQueries = defaultGraphQLMaxQueries
+	}
+	return &graphQLQueryStore{
+		maxQueries: maxQueries,
+		queries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+func (s *graphQLQueryStore) Get(hash string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.queries[hash]
+	if !ok {
+		return "", false
+	}
+	s.lru.MoveToFront(elem)
+	return elem.Value.(*graphQLStoredQuery).query, true
+}
+
+func (s *graphQLQueryStore) Set(hash, query string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.queries[hash]; ok {
+		s.lru.MoveToFront(elem)
+		return
+	}
+	s.queries[hash] = s.lru.PushFront(&graphQLStoredQuery{hash: hash, query: query})
+	for s.lru.Len() > s.maxQueries {
+		oldest := s.lru.Remove(s.lru.Back()).(*graphQLStoredQuery)
+		delete(s.queries, oldest.hash)
+	}
+}
+
+var graphiQLTemplate = template.Must(template.New("graphiql").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <title>GraphiQL</title>
+  <link rel="stylesheet" href="https://unpkg.com/graphiql/graphiql.min.css" />
+</head>
+<body style="margin: 0;">
+  <div id="graphiql" style="height: 100vh;"></div>
+  <script crossorigin src="https://unpkg.com/react/umd/react.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/react-dom/umd/react-dom.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/graphiql/graphiql.min.js"></script>
+  <script>
+    const fetcher = GraphiQL.createFetcher({ url: {{.}} });
+    ReactDOM.render(React.createElement(GraphiQL, { fetcher: fetcher }), document.getElementById('graphiql'));
+  </script>
+</body>
+</html>
+`))
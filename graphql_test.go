@@ -0,0 +1,159 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func echoGraphQL(c *Context, req *GraphQLRequest) any {
+	data := H{"query": req.Query, "user": c.GetString("user")}
+	if fh, ok := req.Variables["file"].(*multipart.FileHeader); ok {
+		data["file"] = fh.Filename
+	}
+	return H{"data": data}
+}
+
+func TestGraphQLGetAndPost(t *testing.T) {
+	router := New()
+	router.Use(func(c *Context) { c.Set("user", "bob") })
+	router.GraphQL("/graphql", echoGraphQL, GraphQLOptions{PlaygroundPath: "/graphiql"})
+
+	w := PerformRequest(router, http.MethodGet, "/graphql?query="+url.QueryEscape("{ me }"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":{"query":"{ me }","user":"bob"}}`, w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/graphql?query="+url.QueryEscape("mutation { drop }"))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	w = PerformRequest(router, http.MethodGet, "/graphql")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"errors":[{"message":"no query provided"}]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ a }","variables":{"x":1}}`))
+	req.Header.Set("Content-Type", MIMEJSON)
+	router.ServeHTTP(w, req)
+	assert.JSONEq(t, `{"data":{"query":"{ a }","user":"bob"}}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{ b }`))
+	req.Header.Set("Content-Type", MIMEGraphQL)
+	router.ServeHTTP(w, req)
+	assert.JSONEq(t, `{"data":{"query":"{ b }","user":"bob"}}`, w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/graphiql")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"/graphql"`)
+}
+
+func TestGraphQLPersistedQueries(t *testing.T) {
+	router := New()
+	router.GraphQL("/graphql", echoGraphQL, GraphQLOptions{QueryStore: NewGraphQLQueryStore()})
+
+	query := "{ persisted }"
+	sum := sha256.Sum256([]byte(query))
+	ext := url.QueryEscape(`{"persistedQuery":{"version":1,"sha256Hash":"` + hex.EncodeToString(sum[:]) + `"}}`)
+
+	w := PerformRequest(router, http.MethodGet, "/graphql?extensions="+ext)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`, w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/graphql?query="+url.QueryEscape("{ other }")+"&extensions="+ext)
+	assert.Contains(t, w.Body.String(), "provided sha does not match query")
+
+	w = PerformRequest(router, http.MethodGet, "/graphql?query="+url.QueryEscape(query)+"&extensions="+ext)
+	assert.Contains(t, w.Body.String(), `"query":"{ persisted }"`)
+
+	w = PerformRequest(router, http.MethodGet, "/graphql?extensions="+ext)
+	assert.Contains(t, w.Body.String(), `"query":"{ persisted }"`)
+}
+
+func TestGraphQLMultipartUpload(t *testing.T) {
+	router := New()
+	router.GraphQL("/graphql", echoGraphQL, GraphQLOptions{})
+
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	_ = mw.WriteField("operations", `{"query":"mutation($file: Upload!) { upload(file: $file) }","variables":{"file":null}}`)
+	_ = mw.WriteField("map", `{"0":["variables.file"]}`)
+	fw, _ := mw.CreateFormFile("0", "a.txt")
+	_, _ = fw.Write([]byte("content"))
+	mw.Close()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/graphql", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"file":"a.txt"`)
+
+	assert.Error(t, setGraphQLVariable(map[string]any{"list": []any{nil}}, []string{"list", "3"}, 1))
+	assert.Error(t, setGraphQLVariable(map[string]any{"x": 1}, []string{"x", "y"}, 1))
+}
+
+func TestGraphQLMutationsOnlyOverPost(t *testing.T) {
+	router := New()
+	router.GraphQL("/graphql", echoGraphQL, GraphQLOptions{QueryStore: NewGraphQLQueryStore()})
+	get := func(query, operationName string) int {
+		return PerformRequest(router, http.MethodGet, "/graphql?query="+url.QueryEscape(query)+"&operationName="+operationName).Code
+	}
+
+	assert.Equal(t, http.StatusMethodNotAllowed, get("# comment\nmutation { drop }", ""))
+	assert.Equal(t, http.StatusMethodNotAllowed, get(`{ a } mutation{drop}`, ""))
+	assert.Equal(t, http.StatusMethodNotAllowed, get(`query A { a } mutation B { drop }`, "B"))
+	assert.Equal(t, http.StatusOK, get(`query A { a } mutation B { drop }`, "A"))
+	assert.Equal(t, http.StatusOK, get(`{ a(s: "} mutation { x }", t: """ "} mutation""") }`, ""))
+	assert.Equal(t, http.StatusOK, get(`query($v: In = {a: 1}) { mutation }`, ""))
+
+	// a persisted mutation can not be run over GET either.
+	query := "mutation { drop }"
+	sum := sha256.Sum256([]byte(query))
+	ext := `{"persistedQuery":{"version":1,"sha256Hash":"` + hex.EncodeToString(sum[:]) + `"}}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"mutation { drop }","extensions":`+ext+`}`))
+	req.Header.Set("Content-Type", MIMEJSON)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = PerformRequest(router, http.MethodGet, "/graphql?extensions="+url.QueryEscape(ext))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, http.MethodPost, w.Header().Get("Allow"))
+}
+
+func TestGraphQLOperations(t *testing.T) {
+	assert.Equal(t, []graphQLOperation{{typ: "query"}}, graphQLOperations(`{ a }`))
+	assert.Equal(t, []graphQLOperation{
+		{typ: "query", name: "Q"},
+		{typ: "mutation", name: "M"},
+		{typ: "subscription"},
+	}, graphQLOperations(`query Q($a: [Int] = [1]) @d { a } fragment F on T { b } mutation M { c } subscription { d }`))
+	assert.Empty(t, graphQLOperations(`# { a }`))
+}
+
+func TestGraphQLQueryStoreLimit(t *testing.T) {
+	store := NewGraphQLQueryStoreWithLimit(2)
+	store.Set("a", "{ a }")
+	store.Set("b", "{ b }")
+	_, _ = store.Get("a")
+	store.Set("c", "{ c }")
+
+	_, ok := store.Get("b")
+	assert.False(t, ok)
+	query, ok := store.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "{ a }", query)
+	_, ok = store.Get("c")
+	assert.True(t, ok)
+}
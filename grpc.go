@@ -0,0 +1,82 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// RunWithGRPC attaches the router and grpcServer, e.g. a *grpc.Server, to a
+// http.Server and starts listening and serving on addr: the HTTP/2 requests
+// whose Content-Type is application/grpc are handled by grpcServer, the other
+// ones by the router, so that a service needs neither two ports nor a
+// connection multiplexer.
+//
+// With tlsConfig, the connections are served with TLS, HTTP/2 being negotiated
+// with ALPN; its certificates are completed by the ones added with
+// AddCertificate. Without, the HTTP/2 connections are served in cleartext
+// (h2c), as the gRPC clients using insecure credentials expect.
+//
+//	grpcServer := grpc.NewServer()
+//	pb.RegisterGreeterServer(grpcServer, &greeter{})
+//	router.RunWithGRPC(":8080", grpcServer, nil)
+//
+// Note: this method will block the calling goroutine indefinitely unless an error happens.
+func (engine *Engine) RunWithGRPC(addr string, grpcServer http.Handler, tlsConfig *tls.Config) (err error) {
+	defer func() { debugPrintError(err) }()
+
+	if engine.isUnsafeTrustedProxies() {
+		debugPrintWarning("You trusted all proxies, this is NOT safe. We recommend you to set a value.\n" +
+			"Please check https://pkg.go.dev/github.com/gin-gonic/gin#readme-don-t-trust-all-proxies for details.")
+	}
+
+	address := resolveAddress([]string{addr})
+	debugPrintInfo("Listening and serving HTTP and gRPC on %s\n", address)
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return
+	}
+	err = engine.serveWithGRPC(listener, grpcServer, tlsConfig)
+	return
+}
+
+func (engine *Engine) serveWithGRPC(listener net.Listener, grpcServer http.Handler, tlsConfig *tls.Config) error {
+	if tlsConfig == nil {
+		return engine.serve(listener, func(srv *http.Server, listener net.Listener) error {
+			srv.Handler = engine.HandlerWithGRPC(grpcServer)
+			return srv.Serve(listener)
+		})
+	}
+	return engine.serveTLS(listener, func(srv *http.Server, listener net.Listener) error {
+		srv.Handler = grpcHandler(grpcServer, srv.Handler)
+		srv.TLSConfig = engine.tlsConfig(tlsConfig.Clone())
+		return srv.ServeTLS(listener, "", "")
+	})
+}
+
+// HandlerWithGRPC returns a http.Handler serving the gRPC requests with
+// grpcServer and the other ones with the engine, for the servers created
+// outside of RunWithGRPC. The HTTP/2 cleartext connections are accepted.
+func (engine *Engine) HandlerWithGRPC(grpcServer http.Handler) http.Handler {
+	return h2c.NewHandler(grpcHandler(grpcServer, engine), &http2.Server{})
+}
+
+// grpcHandler serves the gRPC requests with grpcServer and the other ones with
+// handler.
+func grpcHandler(grpcServer, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.ProtoMajor == 2 && strings.HasPrefix(req.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, req)
+			return
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+)
+
+// fakeGRPCServer answers the gRPC requests like a *grpc.Server would, with the
+// protocol of the request.
+var fakeGRPCServer = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Grpc-Status", "0")
+	_, _ = io.WriteString(w, "grpc over "+req.Proto)
+})
+
+func grpcRouter(t *testing.T, tlsConfig *tls.Config) (*Engine, string) {
+	router := New()
+	router.POST("/hello", func(c *Context) {
+		c.String(http.StatusOK, "gin over "+c.Request.Proto)
+	})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- router.serveWithGRPC(listener, fakeGRPCServer, tlsConfig) }()
+	t.Cleanup(func() {
+		assert.NoError(t, router.Shutdown(context.Background()))
+		assert.ErrorIs(t, <-done, http.ErrServerClosed)
+	})
+	return router, listener.Addr().String()
+}
+
+func postGRPC(t *testing.T, client *http.Client, url, contentType string) string {
+	resp, err := client.Post(url, contentType, strings.NewReader("message"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestRunWithGRPCCleartext(t *testing.T) {
+	_, addr := grpcRouter(t, nil)
+
+	h2c := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+	assert.Equal(t, "grpc over HTTP/2.0", postGRPC(t, h2c, "http://"+addr+"/helloworld.Greeter/SayHello", "application/grpc"))
+	assert.Equal(t, "grpc over HTTP/2.0", postGRPC(t, h2c, "http://"+addr+"/helloworld.Greeter/SayHello", "application/grpc+proto"))
+	assert.Equal(t, "gin over HTTP/2.0", postGRPC(t, h2c, "http://"+addr+"/hello", "text/plain"))
+
+	http1 := &http.Client{}
+	assert.Equal(t, "gin over HTTP/1.1", postGRPC(t, http1, "http://"+addr+"/hello", "application/grpc"))
+}
+
+func TestRunWithGRPCTLS(t *testing.T) {
+	cert, err := tls.LoadX509KeyPair("./testdata/certificate/cert.pem", "./testdata/certificate/key.pem")
+	require.NoError(t, err)
+	_, addr := grpcRouter(t, &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12})
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		ForceAttemptHTTP2: true,
+	}}
+	assert.Equal(t, "grpc over HTTP/2.0", postGRPC(t, client, "https://"+addr+"/helloworld.Greeter/SayHello", "application/grpc"))
+	assert.Equal(t, "gin over HTTP/2.0", postGRPC(t, client, "https://"+addr+"/hello", "text/plain"))
+}
+
+func TestRunWithGRPCError(t *testing.T) {
+	router := New()
+	assert.Error(t, router.RunWithGRPC("127.0.0.1:-1", fakeGRPCServer, nil))
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"reflect"
+)
+
+type namedHandler struct {
+	name    string
+	handler HandlerFunc
+}
+
+// newNamedHandler returns a handler running run, which carries nh: calling it
+// with a Context whose handlerProbe is set stores nh there instead, see
+// lookupNamedHandler. The name is thus kept by the handlers chain itself.
+//
+//go:noinline
+func newNamedHandler(nh namedHandler, run HandlerFunc) HandlerFunc {
+	return func(c *Context) {
+		if c.handlerProbe != nil {
+			*c.handlerProbe = nh
+			return
+		}
+		run(c)
+	}
+}
+
+// namedHandlerPC is the code pointer shared by the handlers returned by newNamedHandler.
+var namedHandlerPC = reflect.ValueOf(newNamedHandler(namedHandler{}, nil)).Pointer()
+
+// NameHandler returns h with a human-readable name, which is reported instead of
+// the name of its function by Context.HandlerName, Context.HandlerNames,
+// Engine.Routes, Engine.RoutesSnapshot, Engine.RouteChain and the debug output.
+func NameHandler(name string, h HandlerFunc) HandlerFunc {
+	return newNamedHandler(namedHandler{name: name, handler: h}, h)
+}
+
+func lookupNamedHandler(h HandlerFunc) (namedHandler, bool) {
+	if h == nil || reflect.ValueOf(h).Pointer() != namedHandlerPC {
+		return namedHandler{}, false
+	}
+	var nh namedHandler
+	h(&Context{handlerProbe: &nh})
+	return nh, true
+}
+
+// unwrapHandler returns the handler given to NameHandler if h was returned by it.
+func unwrapHandler(h HandlerFunc) HandlerFunc {
+	if nh, ok := lookupNamedHandler(h); ok {
+		return nh.handler
+	}
+	return h
+}
+
+// UseNamed adds a named middleware to the group, see NameHandler.
+func (group *RouterGroup) UseNamed(name string, middleware HandlerFunc) IRoutes {
+	return group.Use(NameHandler(name, middleware))
+}
+
+// UseNamed attaches a named global middleware to the router, see NameHandler.
+func (engine *Engine) UseNamed(name string, middleware HandlerFunc) IRoutes {
+	return engine.Use(NameHandler(name, middleware))
+}
+
+// RouteChain returns the names of the handlers (middleware first) which serve the
+// given method and path. path is either a route template ("/users/:id") or a
+// request path ("/users/42"). ok is false when no route matches.
+func (engine *Engine) RouteChain(method, path string) (names []string, ok bool) {
+	root := engine.trees.get(method)
+	if root == nil {
+		return nil, false
+	}
+	var handlers HandlersChain
+	if n := root.findRoute(path); n != nil {
+		handlers = n.handlers
+	} else {
+		skippedNodes := make([]skippedNode, 0, engine.maxSections)
+		handlers = root.getValue(path, nil, &skippedNodes, false).handlers
+	}
+	if handlers == nil {
+		return nil, false
+	}
+	names = make([]string, 0, len(handlers))
+	for _, h := range handlers {
+		names = append(names, nameOfFunction(h))
+	}
+	return names, true
+}
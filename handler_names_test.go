@@ -0,0 +1,70 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func namedMiddleware() HandlerFunc {
+	return func(c *Context) {}
+}
+
+func TestUseNamed(t *testing.T) {
+	var names []string
+	router := New()
+	router.UseNamed("auth", namedMiddleware())
+	api := router.Group("/api")
+	api.UseNamed("audit", namedMiddleware())
+	api.Use(namedMiddleware())
+	api.GET("/users/:id", NameHandler("getUser", func(c *Context) {
+		names = c.HandlerNames()
+	}))
+
+	PerformRequest(router, http.MethodGet, "/api/users/1")
+	assert.Equal(t, []string{"auth", "audit", "github.com/gin-gonic/gin.namedMiddleware.func1", "getUser"}, names)
+
+	chain, ok := router.RouteChain(http.MethodGet, "/api/users/:id")
+	assert.True(t, ok)
+	assert.Equal(t, names, chain)
+	chain, ok = router.RouteChain(http.MethodGet, "/api/users/42")
+	assert.True(t, ok)
+	assert.Equal(t, names, chain)
+	_, ok = router.RouteChain(http.MethodGet, "/missing")
+	assert.False(t, ok)
+	_, ok = router.RouteChain(http.MethodPost, "/api/users/42")
+	assert.False(t, ok)
+
+	snapshot := router.RoutesSnapshot()
+	assert.Equal(t, "getUser", snapshot[0].Handler)
+	assert.Equal(t, []string{"auth", "audit", "github.com/gin-gonic/gin.namedMiddleware.func1"}, snapshot[0].Middleware)
+	assert.Equal(t, "getUser", router.Routes()[0].Handler)
+}
+
+func TestSkipNamedMiddleware(t *testing.T) {
+	router := New()
+	router.UseNamed("logger", skipLogger)
+	router.GET("/metrics", func(c *Context) {}).(RouteSkipper).Skip(skipLogger)
+	w := PerformRequest(router, http.MethodGet, "/metrics")
+	assert.Empty(t, w.Header().Get("X-Logged"))
+}
+
+func TestNameHandlerKeepsTheName(t *testing.T) {
+	called := 0
+	h := func(c *Context) { called++ }
+	a, b := NameHandler("a", h), NameHandler("b", h)
+	assert.Equal(t, "a", nameOfFunction(a))
+	assert.Equal(t, "b", nameOfFunction(b))
+	assert.Equal(t, reflect.ValueOf(h).Pointer(), reflect.ValueOf(unwrapHandler(a)).Pointer())
+	assert.Contains(t, nameOfFunction(h), "TestNameHandlerKeepsTheName")
+	assert.Equal(t, 0, called)
+
+	a(&Context{})
+	assert.Equal(t, 1, called)
+}
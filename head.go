@@ -0,0 +1,117 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// IsHead reports whether the request is a HEAD request, so that handlers can skip
+// generating a body which would be discarded, see Engine.HandleHEAD.
+func (c *Context) IsHead() bool {
+	return c.Request.Method == http.MethodHead
+}
+
+// serveHead serves a HEAD request with the GET route matching rPath, if any,
+// see Engine.HandleHEAD.
+func (engine *Engine) serveHead(c *Context, rPath string, unescape bool) bool {
+	var value nodeValue
+	if n := engine.frozen.get(http.MethodGet, rPath); n != nil {
+		value = nodeValue{handlers: n.handlers, fullPath: n.fullPath, meta: n.meta, name: n.name, global: n.global}
+	} else if root := engine.trees.get(http.MethodGet); root != nil {
+		// the lookup of a HEAD route may have left some params.
+		*c.params = (*c.params)[:0]
+		*c.skippedNodes = (*c.skippedNodes)[:0]
+		c.growRouting(engine.maxParams, engine.maxSections)
+		value = root.getValue(rPath, c.params, c.skippedNodes, unescape)
+		if value.handlers == nil {
+			return false
+		}
+		if value.params != nil {
+			c.Params = *value.params
+		}
+	} else {
+		return false
+	}
+
+	w := &headWriter{ResponseWriter: c.Writer, size: noWritten}
+	c.Writer = w
+	engine.serveMatched(c, value)
+	c.Next()
+	w.finish()
+	c.writermem.WriteHeaderNow()
+	return true
+}
+
+// headWriter discards the body written by a GET handler serving a HEAD request,
+// and sets the Content-Length of the response to the size of the discarded body.
+// The headers are only sent once the handlers are done, or when flushed.
+type headWriter struct {
+	ResponseWriter
+	size     int
+	finished bool
+}
+
+func (w *headWriter) WriteHeaderNow() {
+	if w.finished {
+		w.ResponseWriter.WriteHeaderNow()
+	} else if w.size == noWritten {
+		w.size = 0
+	}
+}
+
+func (w *headWriter) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	if !w.finished {
+		w.size += len(data)
+	}
+	return len(data), nil
+}
+
+func (w *headWriter) WriteString(s string) (int, error) {
+	w.WriteHeaderNow()
+	if !w.finished {
+		w.size += len(s)
+	}
+	return len(s), nil
+}
+
+func (w *headWriter) Size() int {
+	if w.finished {
+		return w.ResponseWriter.Size()
+	}
+	return w.size
+}
+
+func (w *headWriter) Written() bool {
+	return w.Size() != noWritten
+}
+
+// Flush sends the headers: the Content-Length is unknown from then on.
+func (w *headWriter) Flush() {
+	w.finished = true
+	w.ResponseWriter.Flush()
+}
+
+// Hijack implements the http.Hijacker interface.
+func (w *headWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.finished = true
+	return w.ResponseWriter.Hijack()
+}
+
+// finish sends the headers once the handlers are done.
+func (w *headWriter) finish() {
+	if w.finished {
+		return
+	}
+	w.finished = true
+	if w.size > 0 && w.Header().Get("Content-Length") == "" && bodyAllowedForStatus(w.Status()) {
+		w.Header().Set("Content-Length", strconv.Itoa(w.size))
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
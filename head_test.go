@@ -0,0 +1,91 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleHEAD(t *testing.T) {
+	router := New()
+	router.HandleHEAD = true
+	router.GET("/users/:id", func(c *Context) {
+		c.Header("X-Head", "false")
+		if c.IsHead() {
+			c.Header("X-Head", "true")
+		}
+		c.String(http.StatusOK, "user %s", c.Param("id"))
+	})
+	router.HEAD("/explicit", func(c *Context) { c.Status(http.StatusNoContent) })
+	router.GET("/explicit", func(c *Context) { c.String(http.StatusOK, "get") })
+
+	w := PerformRequest(router, http.MethodHead, "/users/42")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, "7", w.Header().Get("Content-Length"))
+	assert.Equal(t, "true", w.Header().Get("X-Head"))
+
+	w = PerformRequest(router, http.MethodGet, "/users/42")
+	assert.Equal(t, "user 42", w.Body.String())
+	assert.Equal(t, "false", w.Header().Get("X-Head"))
+
+	w = PerformRequest(router, http.MethodHead, "/explicit")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = PerformRequest(router, http.MethodHead, "/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	router.HandleHEAD = false
+	w = PerformRequest(router, http.MethodHead, "/users/42")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandleHEADKeepsContentLengthAndStatus(t *testing.T) {
+	router := New()
+	router.HandleHEAD = true
+	router.GET("/chunks", func(c *Context) {
+		c.Status(http.StatusAccepted)
+		_, _ = c.Writer.WriteString("abc")
+		_, _ = c.Writer.Write([]byte("de"))
+		assert.Equal(t, 5, c.Writer.Size())
+	})
+	router.GET("/sized", func(c *Context) {
+		c.Header("Content-Length", "100")
+		c.String(http.StatusOK, "ignored")
+	})
+	router.GET("/empty", func(c *Context) { c.AbortWithStatus(http.StatusUnauthorized) })
+
+	w := PerformRequest(router, http.MethodHead, "/chunks")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Content-Length"))
+	assert.Empty(t, w.Body.String())
+
+	w = PerformRequest(router, http.MethodHead, "/sized")
+	assert.Equal(t, "100", w.Header().Get("Content-Length"))
+
+	w = PerformRequest(router, http.MethodHead, "/empty")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Length"))
+}
+
+func TestHandleHEADServer(t *testing.T) {
+	router := New()
+	router.HandleHEAD = true
+	router.GET("/", func(c *Context) { c.String(http.StatusOK, "hello") })
+	router.Freeze()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	res, err := http.Head(ts.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, int64(5), res.ContentLength)
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HTTPSRedirectConfig defines the config of the HTTP listener started along
+// the TLS servers, see EnableHTTPSRedirect.
+type HTTPSRedirectConfig struct {
+	// Addr is the address of the HTTP listener.
+	// Optional. Default value is ":80".
+	Addr string
+
+	// Code is the status code of the redirects.
+	// Optional. Default value is 308 Permanent Redirect, which keeps the method.
+	Code int
+
+	// HSTSMaxAge adds a Strict-Transport-Security header to the HTTPS responses,
+	// so that browsers use HTTPS directly for this duration.
+	// Optional. Default value doesn't add the header.
+	HSTSMaxAge time.Duration
+
+	// HSTSIncludeSubdomains and HSTSPreload add the includeSubDomains and
+	// preload directives to the Strict-Transport-Security header.
+	// Optional. Default values are false.
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+
+	// AllowPaths are the paths served over HTTP instead of being redirected,
+	// with the syntax of path.Match and a trailing "/**" matching any sub-path.
+	// Optional. Default value allows the ACME HTTP-01 challenges,
+	// "/.well-known/acme-challenge/**".
+	AllowPaths []string
+
+	// AllowHandler serves the allowed paths, e.g. the HTTP handler of an ACME client.
+	// Optional. Default value is the engine.
+	AllowHandler http.Handler
+}
+
+// EnableHTTPSRedirect makes RunTLS and RunMTLS also listen for HTTP on
+// config.Addr, redirecting the requests to HTTPS with the same host, path and
+// query. It must be called before the Run methods.
+//
+//	router.EnableHTTPSRedirect(gin.HTTPSRedirectConfig{HSTSMaxAge: 365 * 24 * time.Hour})
+//	router.RunTLS(":443", "cert.pem", "key.pem")
+func (engine *Engine) EnableHTTPSRedirect(config HTTPSRedirectConfig) {
+	if config.Addr == "" {
+		config.Addr = ":80"
+	}
+	if config.Code == 0 {
+		config.Code = http.StatusPermanentRedirect
+	}
+	if config.AllowPaths == nil {
+		config.AllowPaths = []string{"/.well-known/acme-challenge/**"}
+	}
+	if config.AllowHandler == nil {
+		config.AllowHandler = engine.Handler()
+	}
+	engine.httpsRedirect = &config
+}
+
+// serveTLS is serve for the TLS servers, with the HTTPS redirect listener.
+func (engine *Engine) serveTLS(listener net.Listener, serve func(*http.Server, net.Listener) error) error {
+	config := engine.httpsRedirect
+	if config == nil {
+		return engine.serve(listener, serve)
+	}
+	redirectListener, err := net.Listen("tcp", config.Addr)
+	if err != nil {
+		return err
+	}
+	defer redirectListener.Close()
+
+	redirect := config.redirectHandler(listener.Addr())
+	debugPrintInfo("Listening and redirecting HTTP to HTTPS on %s\n", redirectListener.Addr())
+	go func() {
+		err := engine.serve(redirectListener, func(srv *http.Server, listener net.Listener) error {
+			srv.Handler = redirect
+			return srv.Serve(listener)
+		})
+		if !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, net.ErrClosed) {
+			debugPrintError(err)
+		}
+	}()
+
+	hsts := config.hsts()
+	return engine.serve(listener, func(srv *http.Server, listener net.Listener) error {
+		if hsts != "" {
+			handler := srv.Handler
+			srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Strict-Transport-Security", hsts)
+				handler.ServeHTTP(w, req)
+			})
+		}
+		return serve(srv, listener)
+	})
+}
+
+// redirectHandler redirects to the HTTPS server listening on addr.
+func (config *HTTPSRedirectConfig) redirectHandler(addr net.Addr) http.Handler {
+	port := ""
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok && tcpAddr.Port != 443 {
+		port = ":" + strconv.Itoa(tcpAddr.Port)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for _, pattern := range config.AllowPaths {
+			if pathMatches(pattern, req.URL.Path) {
+				config.AllowHandler.ServeHTTP(w, req)
+				return
+			}
+		}
+		host := req.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if host == "" {
+			http.Error(w, "missing host", http.StatusBadRequest)
+			return
+		}
+		if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+			host = "[" + host + "]"
+		}
+		http.Redirect(w, req, "https://"+host+port+req.URL.RequestURI(), config.Code)
+	})
+}
+
+func (config *HTTPSRedirectConfig) hsts() string {
+	if config.HSTSMaxAge <= 0 {
+		return ""
+	}
+	value := "max-age=" + strconv.FormatInt(int64(config.HSTSMaxAge/time.Second), 10)
+	if config.HSTSIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	if config.HSTSPreload {
+		value += "; preload"
+	}
+	return value
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPSRedirectHandler(t *testing.T) {
+	router := New()
+	router.GET("/.well-known/acme-challenge/:token", func(c *Context) { c.String(http.StatusOK, c.Param("token")) })
+	router.EnableHTTPSRedirect(HTTPSRedirectConfig{})
+	config := router.httpsRedirect
+	assert.Equal(t, ":80", config.Addr)
+
+	redirect := config.redirectHandler(&net.TCPAddr{Port: 443})
+	w := httptest.NewRecorder()
+	redirect.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "http://example.com:80/a/b?c=d", nil))
+	assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+	assert.Equal(t, "https://example.com/a/b?c=d", w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	redirect.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://example.com/.well-known/acme-challenge/token", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "token", w.Body.String())
+
+	redirect = config.redirectHandler(&net.TCPAddr{Port: 8443})
+	w = httptest.NewRecorder()
+	redirect.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://[::1]:8080/", nil))
+	assert.Equal(t, "https://[::1]:8443/", w.Header().Get("Location"))
+
+	assert.Equal(t, "", config.hsts())
+	config.HSTSMaxAge = time.Hour
+	config.HSTSIncludeSubdomains = true
+	config.HSTSPreload = true
+	assert.Equal(t, "max-age=3600; includeSubDomains; preload", config.hsts())
+}
+
+func TestRunTLSWithHTTPSRedirect(t *testing.T) {
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	redirectAddr := free.Addr().String()
+	free.Close()
+
+	router := New()
+	router.GET("/example", func(c *Context) { c.String(http.StatusOK, "it worked") })
+	router.EnableHTTPSRedirect(HTTPSRedirectConfig{Addr: redirectAddr, HSTSMaxAge: time.Hour})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- router.serveTLS(listener, func(srv *http.Server, listener net.Listener) error {
+			return srv.ServeTLS(listener, "./testdata/certificate/cert.pem", "./testdata/certificate/key.pem")
+		})
+	}()
+	assert.Eventually(t, func() bool { return router.State().Servers == 2 }, time.Second, time.Millisecond)
+
+	client := &http.Client{
+		Transport:     &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}, //nolint: gosec
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	resp, err := client.Get("http://" + redirectAddr + "/example?a=1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusPermanentRedirect, resp.StatusCode)
+	location := resp.Header.Get("Location")
+	assert.Equal(t, "https://127.0.0.1:"+portOf(listener.Addr())+"/example?a=1", location)
+
+	resp, err = client.Get(location)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "max-age=3600", resp.Header.Get("Strict-Transport-Security"))
+
+	assert.NoError(t, router.Shutdown(context.Background()))
+	assert.Equal(t, http.ErrServerClosed, <-errc)
+}
+
+func portOf(addr net.Addr) string {
+	_, port, _ := net.SplitHostPort(addr.String())
+	return port
+}
@@ -0,0 +1,324 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package i18n provides message catalogs, locale detection and locale-aware
+// formatting for gin applications.
+//
+// Catalogs use the JSON or YAML format of go-i18n: a message is either a
+// template string or an object with plural forms (zero, one, two, few, many,
+// other) and an optional description:
+//
+//	{
+//	  "hello": "Hello {{.Name}}!",
+//	  "unread": {
+//	    "description": "number of unread emails",
+//	    "one": "You have {{.PluralCount}} unread email",
+//	    "other": "You have {{.PluralCount}} unread emails"
+//	  }
+//	}
+package i18n
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/gin-gonic/gin/internal/json"
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/zh"
+	"gopkg.in/yaml.v3"
+)
+
+// message is a translated message, with its plural forms.
+type message struct {
+	forms map[string]*template.Template
+}
+
+// Bundle holds the message catalogs and the locales of an application. It is
+// safe for concurrent use, so catalogs can be reloaded while serving.
+type Bundle struct {
+	defaultLocale string
+
+	mu          sync.RWMutex
+	messages    map[string]map[string]*message
+	translators map[string]locales.Translator
+}
+
+// NewBundle returns an empty Bundle whose fallback locale is defaultLocale. The
+// formatting and plural rules of en, de, es, fr and zh are registered, see
+// RegisterLocale for the others.
+func NewBundle(defaultLocale string) *Bundle {
+	b := &Bundle{
+		defaultLocale: Canonical(defaultLocale),
+		messages:      make(map[string]map[string]*message),
+		translators:   make(map[string]locales.Translator),
+	}
+	for _, t := range []locales.Translator{en.New(), de.New(), es.New(), fr.New(), zh.New()} {
+		b.RegisterLocale(t)
+	}
+	return b
+}
+
+// DefaultLocale returns the fallback locale of the bundle.
+func (b *Bundle) DefaultLocale() string {
+	return b.defaultLocale
+}
+
+// RegisterLocale registers the plural rules and number and date formats of a
+// locale, e.g. github.com/go-playground/locales/ja.New().
+func (b *Bundle) RegisterLocale(t locales.Translator) {
+	b.mu.Lock()
+	b.translators[Canonical(t.Locale())] = t
+	b.mu.Unlock()
+}
+
+// AddMessages adds the messages of a locale. A value is either a string or a
+// map of plural forms, as decoded from a catalog file.
+func (b *Bundle) AddMessages(locale string, messages map[string]any) error {
+	parsed := make(map[string]*message, len(messages))
+	for key, value := range messages {
+		m, err := parseMessage(key, value)
+		if err != nil {
+			return fmt.Errorf("i18n: %s: %w", locale, err)
+		}
+		parsed[key] = m
+	}
+
+	locale = Canonical(locale)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	catalog := b.messages[locale]
+	if catalog == nil {
+		catalog = make(map[string]*message, len(parsed))
+		b.messages[locale] = catalog
+	}
+	for key, m := range parsed {
+		catalog[key] = m
+	}
+	return nil
+}
+
+// LoadFS loads the catalog files of fsys matching the glob patterns (all the
+// files by default). The locale of a file is the last part of its name before
+// the extension: en.json, active.pt-BR.yaml... Supported extensions are .json,
+// .yaml and .yml.
+func (b *Bundle) LoadFS(fsys fs.FS, patterns ...string) error {
+	if len(patterns) == 0 {
+		patterns = []string{"*"}
+	}
+	for _, pattern := range patterns {
+		files, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if err := b.loadFile(fsys, file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (b *Bundle) loadFile(fsys fs.FS, file string) error {
+	ext := path.Ext(file)
+	if ext != ".json" && ext != ".yaml" && ext != ".yml" {
+		return nil
+	}
+	name := strings.TrimSuffix(path.Base(file), ext)
+	locale := name[strings.LastIndexByte(name, '.')+1:]
+
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return err
+	}
+	var messages map[string]any
+	if ext == ".json" {
+		err = json.Unmarshal(data, &messages)
+	} else {
+		err = yaml.Unmarshal(data, &messages)
+	}
+	if err != nil {
+		return fmt.Errorf("i18n: %s: %w", file, err)
+	}
+	return b.AddMessages(locale, messages)
+}
+
+var pluralForms = map[string]bool{"zero": true, "one": true, "two": true, "few": true, "many": true, "other": true}
+
+func parseMessage(key string, value any) (*message, error) {
+	m := &message{forms: make(map[string]*template.Template)}
+	switch v := value.(type) {
+	case string:
+		t, err := template.New(key).Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		m.forms["other"] = t
+	case map[string]any:
+		for form, text := range v {
+			s, ok := text.(string)
+			if !ok {
+				return nil, fmt.Errorf("message %q: %s is not a string", key, form)
+			}
+			if !pluralForms[form] {
+				// description, hash... of the go-i18n format.
+				continue
+			}
+			t, err := template.New(key + "." + form).Parse(s)
+			if err != nil {
+				return nil, err
+			}
+			m.forms[form] = t
+		}
+		if m.forms["other"] == nil {
+			return nil, fmt.Errorf("message %q has no other form", key)
+		}
+	default:
+		return nil, fmt.Errorf("message %q is neither a string nor plural forms", key)
+	}
+	return m, nil
+}
+
+// Localizer returns a Localizer for the first of the preferred locales which has
+// a catalog, or for the default locale.
+func (b *Bundle) Localizer(preferred ...string) *Localizer {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	locale := b.defaultLocale
+	for _, p := range preferred {
+		if l, ok := b.match(Canonical(p)); ok {
+			locale = l
+			break
+		}
+	}
+	return &Localizer{bundle: b, locale: locale, translator: b.translator(locale)}
+}
+
+// match returns the locale with a catalog for locale or its base language.
+func (b *Bundle) match(locale string) (string, bool) {
+	if _, ok := b.messages[locale]; ok {
+		return locale, true
+	}
+	if base, _, ok := strings.Cut(locale, "-"); ok {
+		if _, ok := b.messages[base]; ok {
+			return base, true
+		}
+	}
+	return "", false
+}
+
+func (b *Bundle) translator(locale string) locales.Translator {
+	if t, ok := b.translators[locale]; ok {
+		return t
+	}
+	base, _, _ := strings.Cut(locale, "-")
+	if t, ok := b.translators[base]; ok {
+		return t
+	}
+	return b.translators["en"]
+}
+
+// lookup returns the message for key in locale, falling back to the base
+// language and to the default locale.
+func (b *Bundle) lookup(locale, key string) (*message, locales.Translator) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	candidates := []string{locale}
+	if base, _, ok := strings.Cut(locale, "-"); ok {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, b.defaultLocale)
+	for _, l := range candidates {
+		if m, ok := b.messages[l][key]; ok {
+			return m, b.translator(l)
+		}
+	}
+	return nil, nil
+}
+
+// Canonical returns the canonical form of a BCP 47 locale: pt_br -> pt-BR.
+func Canonical(locale string) string {
+	lang, region, ok := strings.Cut(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	if !ok {
+		return strings.ToLower(lang)
+	}
+	return strings.ToLower(lang) + "-" + strings.ToUpper(region)
+}
+
+// pluralCount returns the plural count carried by the arguments of T: a number,
+// or the PluralCount or Count entry of a map or a struct.
+func pluralCount(data any) (float64, bool) {
+	v := reflect.ValueOf(data)
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return 0, false
+		}
+		for _, name := range []string{"PluralCount", "Count"} {
+			if f := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key())); f.IsValid() {
+				return toFloat(f)
+			}
+		}
+	case reflect.Struct:
+		for _, name := range []string{"PluralCount", "Count"} {
+			if f := v.FieldByName(name); f.IsValid() {
+				return toFloat(f)
+			}
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return pluralCount(v.Elem().Interface())
+		}
+	default:
+		return toFloat(v)
+	}
+	return 0, false
+}
+
+func toFloat(v reflect.Value) (float64, bool) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+func (m *message) render(t locales.Translator, data any) string {
+	form := "other"
+	if count, ok := pluralCount(data); ok {
+		if _, isNumber := toFloat(reflect.ValueOf(data)); isNumber {
+			data = map[string]any{"PluralCount": data, "Count": data}
+		}
+		if t != nil {
+			rule := t.CardinalPluralRule(count, 0)
+			if name := strings.ToLower(rule.String()); m.forms[name] != nil {
+				form = name
+			}
+		} else if count == 1 && m.forms["one"] != nil {
+			form = "one"
+		}
+	}
+	var buf bytes.Buffer
+	if err := m.forms[form].Execute(&buf, data); err != nil {
+		return m.forms[form].Name()
+	}
+	return buf.String()
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func newTestBundle(t *testing.T) *Bundle {
+	b := NewBundle("en")
+	require.NoError(t, b.LoadFS(os.DirFS("testdata")))
+	return b
+}
+
+func TestBundle(t *testing.T) {
+	b := newTestBundle(t)
+
+	en := b.Localizer("de", "en-US")
+	assert.Equal(t, "en", en.Locale())
+	assert.Equal(t, "Hello Bob!", en.T("hello", gin.H{"Name": "Bob"}))
+	assert.Equal(t, "You have 1 unread email", en.T("unread", 1))
+	assert.Equal(t, "You have 3 unread emails", en.T("unread", map[string]any{"PluralCount": 3}))
+	assert.Equal(t, "missing", en.T("missing"))
+	assert.Equal(t, "1,234.50", en.FormatNumber(1234.5, 2))
+
+	fr := b.Localizer("fr-CA")
+	assert.Equal(t, "fr", fr.Locale())
+	assert.Equal(t, "Bonjour Bob !", fr.T("hello", struct{ Name string }{"Bob"}))
+	// French uses the singular for 0.
+	assert.Equal(t, "Vous avez 0 e-mail non lu", fr.T("unread", 0))
+	assert.Equal(t, "English only", fr.T("only.en"))
+	assert.Regexp(t, `^1\D234,50$`, fr.FormatNumber(1234.5, 2))
+	assert.Equal(t, "2 janv. 2006", fr.FormatDate(time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)))
+
+	assert.Error(t, b.AddMessages("de", map[string]any{"bad": map[string]any{"one": "x"}}))
+	assert.Error(t, b.AddMessages("de", map[string]any{"bad": "{{"}))
+}
+
+func TestAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"fr-CH", "en", "de"}, AcceptLanguage("de;q=0.7, fr-ch, *;q=0.5, en;q=0.9, es;q=0"))
+	assert.Equal(t, "pt-BR", Canonical("pt_br"))
+}
+
+func TestMiddleware(t *testing.T) {
+	router := gin.New()
+	router.Use(Middleware(Config{Bundle: newTestBundle(t)}))
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, c.T("hello", gin.H{"Name": "Bob"}))
+	})
+
+	request := func(target string, header, cookie string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Accept-Language", header)
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: "lang", Value: cookie})
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("/", "fr-FR,en;q=0.5", "")
+	assert.Equal(t, "Bonjour Bob !", w.Body.String())
+	assert.Equal(t, "fr", w.Header().Get("Content-Language"))
+	assert.Equal(t, "Hello Bob!", request("/", "de", "").Body.String())
+	assert.Equal(t, "Hello Bob!", request("/", "fr", "en").Body.String())
+	assert.Equal(t, "Bonjour Bob !", request("/?lang=fr", "en", "en").Body.String())
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Equal(t, "hello", c.T("hello"))
+	assert.Nil(t, Localize(c))
+	assert.Panics(t, func() { Middleware(Config{}) })
+}
+
+func TestFuncMap(t *testing.T) {
+	l := newTestBundle(t).Localizer("en")
+	tmpl := template.Must(template.New("").Funcs(FuncMap()).Parse(`{{t .L "unread" 2}} {{formatNumber .L 1000 0}}`))
+	var b strings.Builder
+	require.NoError(t, tmpl.Execute(&b, gin.H{"L": l}))
+	assert.Equal(t, "You have 2 unread emails 1,000", b.String())
+}
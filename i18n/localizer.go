@@ -0,0 +1,180 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"html/template"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/locales"
+)
+
+// Localizer translates messages and formats values in a locale.
+type Localizer struct {
+	bundle     *Bundle
+	locale     string
+	translator locales.Translator
+}
+
+var _ gin.Localizer = (*Localizer)(nil)
+
+// Locale returns the locale of the Localizer.
+func (l *Localizer) Locale() string {
+	return l.locale
+}
+
+// T returns the message key in the locale, or key itself when there is no such
+// message. The optional argument is the data of the message template, a map or
+// a struct; a number, or its PluralCount or Count entry, selects the plural form:
+//
+//	l.T("hello", gin.H{"Name": "Bob"})
+//	l.T("unread", 3)
+func (l *Localizer) T(key string, args ...any) string {
+	m, t := l.bundle.lookup(l.locale, key)
+	if m == nil {
+		return key
+	}
+	var data any
+	if len(args) > 0 {
+		data = args[0]
+	}
+	return m.render(t, data)
+}
+
+// FormatNumber formats n with the given number of decimals, e.g. 1,234.5 in
+// English and 1.234,5 in German.
+func (l *Localizer) FormatNumber(n float64, decimals int) string {
+	return l.translator.FmtNumber(n, uint64(decimals))
+}
+
+// FormatPercent formats n, a percentage between 0 and 100, with the given number of decimals.
+func (l *Localizer) FormatPercent(n float64, decimals int) string {
+	return l.translator.FmtPercent(n, uint64(decimals))
+}
+
+// FormatDate formats the date of t in the medium format of the locale, e.g. Jan 2, 2006.
+func (l *Localizer) FormatDate(t time.Time) string {
+	return l.translator.FmtDateMedium(t)
+}
+
+// FormatTime formats the time of t in the short format of the locale, e.g. 3:04 PM.
+func (l *Localizer) FormatTime(t time.Time) string {
+	return l.translator.FmtTimeShort(t)
+}
+
+// Config defines the config of the i18n middleware.
+type Config struct {
+	// Bundle holds the catalogs of the application.
+	Bundle *Bundle
+
+	// QueryParam is the query parameter which selects the locale, e.g. ?lang=fr.
+	// Optional. Default value is "lang", "-" disables it.
+	QueryParam string
+
+	// CookieName is the cookie which holds the locale chosen by the user.
+	// Optional. Default value is "lang", "-" disables it.
+	CookieName string
+}
+
+// Middleware returns a middleware which detects the locale of each request, from
+// the query string, a cookie and the Accept-Language header in that order, and
+// stores its Localizer in the context, see Localize and gin.Context.T.
+func Middleware(config Config) gin.HandlerFunc {
+	if config.Bundle == nil {
+		panic("i18n: Middleware requires a Bundle")
+	}
+	if config.QueryParam == "" {
+		config.QueryParam = "lang"
+	}
+	if config.CookieName == "" {
+		config.CookieName = "lang"
+	}
+	return func(c *gin.Context) {
+		var preferred []string
+		if config.QueryParam != "-" {
+			if lang := c.Query(config.QueryParam); lang != "" {
+				preferred = append(preferred, lang)
+			}
+		}
+		if config.CookieName != "-" {
+			if lang, err := c.Cookie(config.CookieName); err == nil && lang != "" {
+				preferred = append(preferred, lang)
+			}
+		}
+		preferred = append(preferred, AcceptLanguage(c.GetHeader("Accept-Language"))...)
+		l := config.Bundle.Localizer(preferred...)
+		c.Set(gin.LocalizerKey, l)
+		c.Header("Content-Language", l.Locale())
+		c.Next()
+	}
+}
+
+// Localize returns the Localizer stored by the middleware, or nil.
+func Localize(c *gin.Context) *Localizer {
+	l, _ := c.Value(gin.LocalizerKey).(*Localizer)
+	return l
+}
+
+// AcceptLanguage returns the locales of an Accept-Language header, ordered by preference.
+func AcceptLanguage(header string) []string {
+	type accepted struct {
+		locale string
+		q      float64
+	}
+	var list []accepted
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			list = append(list, accepted{locale: Canonical(locale), q: q})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].q > list[j].q })
+	locales := make([]string, len(list))
+	for i, a := range list {
+		locales[i] = a.locale
+	}
+	return locales
+}
+
+// FuncMap returns template functions using the Localizer given as first
+// argument, to be added to Engine.FuncMap (or SetFuncMap) before loading the
+// templates:
+//
+//	router.SetFuncMap(i18n.FuncMap())
+//	c.HTML(http.StatusOK, "index.tmpl", gin.H{"L": i18n.Localize(c)})
+//
+//	{{t .L "hello" .User}} {{formatNumber .L .Total 2}} {{formatDate .L .Date}}
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"t": func(l *Localizer, key string, args ...any) string {
+			if l == nil {
+				return key
+			}
+			return l.T(key, args...)
+		},
+		"formatNumber": func(l *Localizer, n float64, decimals int) string {
+			return l.FormatNumber(n, decimals)
+		},
+		"formatDate": func(l *Localizer, t time.Time) string {
+			return l.FormatDate(t)
+		},
+		"formatTime": func(l *Localizer, t time.Time) string {
+			return l.FormatTime(t)
+		},
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package bufpool provides the buffers shared by the renderers and the request
+// body readers, pooled by size class so that a large payload does not pin a
+// large buffer for the small ones.
+package bufpool
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+)
+
+// Sizes are the capacities of the size classes. Buffers grown past the largest
+// one are not pooled.
+var Sizes = [...]int{1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20}
+
+type class struct {
+	pool sync.Pool
+	gets atomic.Uint64
+	news atomic.Uint64
+	puts atomic.Uint64
+}
+
+var (
+	classes [len(Sizes)]class
+	dropped atomic.Uint64
+)
+
+func init() {
+	for i := range classes {
+		size := Sizes[i]
+		c := &classes[i]
+		c.pool.New = func() any {
+			c.news.Add(1)
+			return bytes.NewBuffer(make([]byte, 0, size))
+		}
+	}
+}
+
+// Get returns an empty buffer with a capacity of at least sizeHint, or of the
+// largest class for larger hints. It must be returned with Put.
+func Get(sizeHint int) *bytes.Buffer {
+	i := 0
+	for i < len(Sizes)-1 && Sizes[i] < sizeHint {
+		i++
+	}
+	classes[i].gets.Add(1)
+	return classes[i].pool.Get().(*bytes.Buffer)
+}
+
+// Put resets buf and returns it to the class of its capacity. The bytes of buf
+// must not be used afterwards.
+func Put(buf *bytes.Buffer) {
+	n := buf.Cap()
+	if n < Sizes[0] || n > Sizes[len(Sizes)-1]*2 {
+		dropped.Add(1)
+		return
+	}
+	i := len(Sizes) - 1
+	for i > 0 && Sizes[i] > n {
+		i--
+	}
+	buf.Reset()
+	classes[i].puts.Add(1)
+	classes[i].pool.Put(buf)
+}
+
+// ClassStats are the statistics of a size class.
+type ClassStats struct {
+	Size int
+	Gets uint64
+	News uint64
+	Puts uint64
+}
+
+// Stats returns the statistics of the size classes, and the number of buffers
+// which were too large to be pooled.
+func Stats() ([]ClassStats, uint64) {
+	stats := make([]ClassStats, len(Sizes))
+	for i := range classes {
+		c := &classes[i]
+		stats[i] = ClassStats{Size: Sizes[i], Gets: c.gets.Load(), News: c.news.Load(), Puts: c.puts.Load()}
+	}
+	return stats, dropped.Load()
+}
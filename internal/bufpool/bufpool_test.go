@@ -0,0 +1,56 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package bufpool
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGetSizeClass(t *testing.T) {
+	for _, hint := range []int{0, 1, 1 << 10, 1<<10 + 1, 100 << 10, 1 << 20, 8 << 20} {
+		buf := Get(hint)
+		want := hint
+		if want > Sizes[len(Sizes)-1] {
+			want = Sizes[len(Sizes)-1]
+		}
+		if buf.Len() != 0 || buf.Cap() < want {
+			t.Errorf("Get(%d) = len %d cap %d", hint, buf.Len(), buf.Cap())
+		}
+		Put(buf)
+	}
+}
+
+func TestPutStats(t *testing.T) {
+	before, dropped := Stats()
+
+	buf := Get(3 << 10)
+	buf.WriteString("data")
+	Put(buf)
+	Put(bytes.NewBuffer(make([]byte, 0, 4<<20)))
+
+	after, droppedAfter := Stats()
+	if after[1].Size != 4<<10 {
+		t.Fatalf("size = %d", after[1].Size)
+	}
+	if after[1].Gets != before[1].Gets+1 || after[1].Puts != before[1].Puts+1 {
+		t.Errorf("stats = %+v, before %+v", after[1], before[1])
+	}
+	if droppedAfter != dropped+1 {
+		t.Errorf("dropped = %d, want %d", droppedAfter, dropped+1)
+	}
+	if buf := Get(3 << 10); buf.Len() != 0 {
+		t.Errorf("pooled buffer not reset: %q", buf.String())
+	}
+}
+
+func BenchmarkGetPut(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf := Get(512)
+		buf.WriteString("payload")
+		Put(buf)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// WrapMiddleware adapts a net/http middleware, e.g. of the chi or gorilla
+// ecosystems, into a gin middleware: the following handlers run as the next
+// handler of middleware, and the chain is aborted if middleware does not call
+// it, e.g. when it rejects the request.
+//
+// The context keys are shared both ways: the request given to middleware
+// returns the keys set with c.Set, and the Context with ContextKey, from its
+// Context().Value; the request given by middleware to the next handler, with
+// the values it added, becomes c.Request, whose values are returned by
+// c.Request.Context().Value, or by c.Value when ContextWithFallback is
+// enabled. The writer given to the next handler, e.g. a compressing one,
+// receives the response of the following handlers.
+//
+//	router.Use(gin.WrapMiddleware(middleware.RealIP))
+func WrapMiddleware(middleware func(http.Handler) http.Handler) HandlerFunc {
+	return func(c *Context) {
+		writer := c.Writer
+		defer func() { c.Writer = writer }()
+
+		called := false
+		next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = true
+			c.Request = req
+			if w == writer {
+				c.Next()
+				return
+			}
+			wrapped := &responseWriter{}
+			wrapped.reset(w)
+			c.Writer = wrapped
+			c.Next()
+			// like the engine does, for the statuses set without a body.
+			wrapped.WriteHeaderNow()
+			c.Writer = writer
+		})
+		req := c.Request.WithContext(&keysContext{Context: c.Request.Context(), c: c})
+		middleware(next).ServeHTTP(writer, req)
+		if !called {
+			c.Abort()
+		}
+	}
+}
+
+// keysContext returns the keys of c from its Value.
+type keysContext struct {
+	context.Context
+	c *Context
+}
+
+func (ctx *keysContext) Value(key any) any {
+	if key == ContextKey {
+		return ctx.c
+	}
+	if keyAsString, ok := key.(string); ok {
+		if val, exists := ctx.c.Get(keyAsString); exists {
+			return val
+		}
+	}
+	return ctx.Context.Value(key)
+}
+
+// HandlerFromEngine returns a http.Handler serving the requests with engine
+// under prefix, e.g. "/api", for mounting it in another router, like a
+// http.ServeMux or a chi router, so that an application adopts gin route by
+// route. The routes of engine are matched against the path following prefix,
+// which is added to the redirects of the trailing slashes like a
+// X-Forwarded-Prefix header. The requests outside of prefix get a 404.
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("/api/", gin.HandlerFromEngine(router, "/api"))
+func HandlerFromEngine(engine *Engine, prefix string) http.Handler {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return engine
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		p := strings.TrimPrefix(req.URL.Path, prefix)
+		if len(p) == len(req.URL.Path) || (p != "" && p[0] != '/') {
+			http.NotFound(w, req)
+			return
+		}
+		r2 := req.Clone(req.Context())
+		r2.URL.Path = p
+		if r2.URL.Path == "" {
+			r2.URL.Path = "/"
+		}
+		if req.URL.RawPath != "" {
+			r2.URL.RawPath = strings.TrimPrefix(req.URL.RawPath, prefix)
+			if r2.URL.RawPath == "" {
+				r2.URL.RawPath = "/"
+			}
+		}
+		r2.Header.Set("X-Forwarded-Prefix", req.Header.Get("X-Forwarded-Prefix")+prefix)
+		engine.ServeHTTP(w, r2)
+	})
+}
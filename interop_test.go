@@ -0,0 +1,188 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type interopKey struct{}
+
+func TestWrapMiddleware(t *testing.T) {
+	router := New()
+	router.Use(func(c *Context) {
+		c.Set("user", "gopher")
+		c.Next()
+	})
+	router.Use(WrapMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			c, _ := req.Context().Value(ContextKey).(*Context)
+			assert.NotNil(t, c)
+			w.Header().Set("X-User", req.Context().Value("user").(string))
+			ctx := context.WithValue(req.Context(), interopKey{}, "request-id")
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	}))
+	router.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "%s %s", c.Request.Context().Value(interopKey{}), c.MustGet("user"))
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "request-id gopher", w.Body.String())
+	assert.Equal(t, "gopher", w.Header().Get("X-User"))
+}
+
+func TestWrapMiddlewareContextWithFallback(t *testing.T) {
+	router := New()
+	router.ContextWithFallback = true
+	router.Use(WrapMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), interopKey{}, "request-id")))
+		})
+	}))
+	router.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "%s", c.Value(interopKey{}))
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, "request-id", w.Body.String())
+}
+
+func TestWrapMiddlewareAbort(t *testing.T) {
+	router := New()
+	called := false
+	router.Use(WrapMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("Authorization") == "" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}))
+	router.GET("/", func(c *Context) {
+		called = true
+		c.Status(http.StatusNoContent)
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "unauthorized\n", w.Body.String())
+	assert.False(t, called)
+
+	w = PerformRequest(router, http.MethodGet, "/", header{"Authorization", "Bearer x"})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.True(t, called)
+}
+
+// upperWriter writes the body in upper case, like the middlewares encoding the
+// response do.
+type upperWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *upperWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *upperWriter) Write(data []byte) (int, error) {
+	return w.ResponseWriter.Write([]byte(strings.ToUpper(string(data))))
+}
+
+func TestWrapMiddlewareWriter(t *testing.T) {
+	router := New()
+	var status int
+	router.Use(func(c *Context) {
+		writer := c.Writer
+		c.Next()
+		assert.Same(t, writer, c.Writer)
+		assert.Equal(t, http.StatusAccepted, c.Writer.Status())
+	})
+	router.Use(WrapMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			uw := &upperWriter{ResponseWriter: w}
+			next.ServeHTTP(uw, req)
+			status = uw.status
+		})
+	}))
+	router.GET("/", func(c *Context) {
+		c.String(http.StatusAccepted, "hello")
+	})
+	router.GET("/status", func(c *Context) {
+		c.Status(http.StatusAccepted)
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "HELLO", w.Body.String())
+
+	status = 0
+	w = PerformRequest(router, http.MethodGet, "/status")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, http.StatusAccepted, status)
+}
+
+func TestHandlerFromEngine(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", func(c *Context) {
+		c.String(http.StatusOK, "%s %s %s", c.Param("id"), c.Request.URL.Path, c.Request.Context().Value(interopKey{}))
+	})
+	router.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "root")
+	})
+	router.GET("/docs/", func(c *Context) {})
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/", HandlerFromEngine(router, "/api/"))
+	outer := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mux.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), interopKey{}, "outer")))
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		outer.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	w := serve("/api/users/42")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "42 /users/42 outer", w.Body.String())
+
+	w = serve("/api/")
+	assert.Equal(t, "root", w.Body.String())
+
+	w = serve("/api/docs")
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/api/docs/", w.Header().Get("Location"))
+
+	w = serve("/other")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandlerFromEngineOutsidePrefix(t *testing.T) {
+	router := New()
+	router.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "root")
+	})
+	handler := HandlerFromEngine(router, "/api")
+	for path, body := range map[string]string{
+		"/api":  "root",
+		"/apix": "404 page not found\n",
+		"/":     "404 page not found\n",
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, body, w.Body.String(), path)
+	}
+	assert.Same(t, router, HandlerFromEngine(router, ""))
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net"
+	"net/http"
+	"sync/atomic"
+)
+
+// IPRules is a set of allow and deny rules, which can be reloaded at runtime
+// while being used by IPFilter middleware.
+type IPRules struct {
+	rules atomic.Pointer[ipRuleSet]
+}
+
+type ipRuleSet struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+// NewIPRules returns the rules for the given lists of IP addresses and CIDRs,
+// see Reload.
+func NewIPRules(allow, deny []string) (*IPRules, error) {
+	r := &IPRules{}
+	if err := r.Reload(allow, deny); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload atomically replaces the rules. A client is denied when its IP matches
+// one of the deny rules, or when allow is not empty and its IP matches none of
+// them. The current rules are kept when a list is invalid.
+func (r *IPRules) Reload(allow, deny []string) error {
+	allowNets, err := parseCIDRs(allow)
+	if err != nil {
+		return err
+	}
+	denyNets, err := parseCIDRs(deny)
+	if err != nil {
+		return err
+	}
+	r.rules.Store(&ipRuleSet{allow: allowNets, deny: denyNets})
+	return nil
+}
+
+// Allowed reports whether ip is allowed by the rules.
+func (r *IPRules) Allowed(ip net.IP) bool {
+	rules := r.rules.Load()
+	if rules == nil {
+		return true
+	}
+	if ip == nil {
+		return len(rules.allow) == 0 && len(rules.deny) == 0
+	}
+	if containsIP(rules.deny, ip) {
+		return false
+	}
+	return len(rules.allow) == 0 || containsIP(rules.allow, ip)
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// IPFilterConfig defines the config for IPFilter middleware.
+type IPFilterConfig struct {
+	// Rules are the allow and deny rules, which can be reloaded while in use.
+	Rules *IPRules
+
+	// DeniedHandler is called for the denied clients.
+	// Optional. Default value aborts with a 403 Forbidden.
+	DeniedHandler HandlerFunc
+}
+
+// IPFilter returns a middleware which rejects the clients denied by
+// config.Rules. The client IP is resolved with Context.ClientIP, so the
+// trusted proxies configuration of the engine applies. Being a middleware, it
+// can be scoped to a group, e.g. an admin area.
+func IPFilter(config IPFilterConfig) HandlerFunc {
+	if config.Rules == nil {
+		panic("gin: IPFilter requires Rules")
+	}
+	denied := config.DeniedHandler
+	if denied == nil {
+		denied = func(c *Context) {
+			c.AbortWithStatus(http.StatusForbidden)
+		}
+	}
+	return func(c *Context) {
+		if !config.Rules.Allowed(net.ParseIP(c.ClientIP())) {
+			denied(c)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
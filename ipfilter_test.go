@@ -0,0 +1,67 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPFilter(t *testing.T) {
+	rules, err := NewIPRules([]string{"10.0.0.0/8", "192.0.2.1"}, []string{"10.0.0.66"})
+	require.NoError(t, err)
+
+	router := New()
+	_ = router.SetTrustedProxies([]string{"127.0.0.1"})
+	router.GET("/public", func(c *Context) {})
+	admin := router.Group("/admin", IPFilter(IPFilterConfig{Rules: rules}))
+	admin.GET("", func(c *Context) {})
+
+	request := func(path, remoteAddr, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("/admin", "10.1.2.3:1234", ""))
+	assert.Equal(t, http.StatusOK, request("/admin", "192.0.2.1:1234", ""))
+	assert.Equal(t, http.StatusForbidden, request("/admin", "10.0.0.66:1234", ""))
+	assert.Equal(t, http.StatusForbidden, request("/admin", "192.0.2.2:1234", ""))
+	assert.Equal(t, http.StatusOK, request("/public", "192.0.2.2:1234", ""))
+	// trusted proxy
+	assert.Equal(t, http.StatusOK, request("/admin", "127.0.0.1:1234", "10.9.9.9"))
+	assert.Equal(t, http.StatusForbidden, request("/admin", "192.0.2.2:1234", "10.9.9.9"))
+
+	assert.Error(t, rules.Reload([]string{"nope"}, nil))
+	assert.Equal(t, http.StatusOK, request("/admin", "10.1.2.3:1234", ""))
+
+	require.NoError(t, rules.Reload(nil, []string{"10.0.0.0/8"}))
+	assert.Equal(t, http.StatusForbidden, request("/admin", "10.1.2.3:1234", ""))
+	assert.Equal(t, http.StatusOK, request("/admin", "192.0.2.2:1234", ""))
+}
+
+func TestIPFilterDeniedHandler(t *testing.T) {
+	rules, _ := NewIPRules(nil, []string{"192.0.2.0/24"})
+	router := New()
+	router.Use(IPFilter(IPFilterConfig{Rules: rules, DeniedHandler: func(c *Context) {
+		c.String(http.StatusUnauthorized, "go away")
+	}}))
+	router.GET("/", func(c *Context) { c.String(http.StatusOK, "ok") })
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "go away", w.Body.String())
+
+	assert.Panics(t, func() { IPFilter(IPFilterConfig{}) })
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+// Skipper is a function to skip logs based on provided Context.
+type Skipper func(c *Context) bool
+
+// StatusRange is an inclusive range of HTTP status codes, e.g. {200, 299}.
+type StatusRange struct {
+	Min int `json:"min" yaml:"min"`
+	Max int `json:"max" yaml:"max"`
+}
+
+// Contains reports whether status is in the range.
+func (r StatusRange) Contains(status int) bool {
+	return status >= r.Min && status <= r.Max
+}
+
+// LogSampleRule logs a fraction of the requests whose status is in Statuses.
+type LogSampleRule struct {
+	Statuses StatusRange `json:"statuses" yaml:"statuses"`
+	// Rate is the fraction of the requests logged, between 0 and 1.
+	Rate float64 `json:"rate" yaml:"rate"`
+}
+
+// LogRules are the filtering and sampling rules of the Logger middleware, see LogFilter.
+type LogRules struct {
+	// SkipPaths are the requests not logged, with the syntax of PathMatches
+	// (route templates, path.Match patterns and trailing "/**").
+	SkipPaths []string `json:"skip_paths,omitempty" yaml:"skip_paths,omitempty"`
+
+	// SkipStatuses are the statuses of the requests not logged.
+	SkipStatuses []StatusRange `json:"skip_statuses,omitempty" yaml:"skip_statuses,omitempty"`
+
+	// Sample are the sampling rules; the first rule whose range contains the
+	// status of a request applies, the requests matching no rule are all logged.
+	// For instance, to log 1% of the successful requests and all the others:
+	//
+	//	Sample: []gin.LogSampleRule{{Statuses: gin.StatusRange{Min: 200, Max: 299}, Rate: 0.01}}
+	Sample []LogSampleRule `json:"sample,omitempty" yaml:"sample,omitempty"`
+}
+
+// LogFilter holds the LogRules of Logger middleware, which can be replaced at
+// runtime, e.g. to log every request while investigating an incident:
+//
+//	filter := gin.NewLogFilter(rules)
+//	router.Use(gin.LoggerWithConfig(gin.LoggerConfig{Filter: filter}))
+//	...
+//	filter.Update(gin.LogRules{})
+type LogFilter struct {
+	rules atomic.Pointer[logRules]
+}
+
+type logRules struct {
+	LogRules
+	skipPath func(*Context) bool
+}
+
+// NewLogFilter returns a LogFilter applying rules.
+func NewLogFilter(rules LogRules) *LogFilter {
+	f := &LogFilter{}
+	f.Update(rules)
+	return f
+}
+
+// Update replaces the rules of the filter. It is safe to call while serving.
+func (f *LogFilter) Update(rules LogRules) {
+	compiled := &logRules{LogRules: rules}
+	if len(rules.SkipPaths) > 0 {
+		compiled.skipPath = PathMatches(rules.SkipPaths...)
+	}
+	f.rules.Store(compiled)
+}
+
+// Rules returns the current rules of the filter.
+func (f *LogFilter) Rules() LogRules {
+	return f.rules.Load().LogRules
+}
+
+// logged reports whether the handled request must be logged.
+func (f *LogFilter) logged(c *Context) bool {
+	rules := f.rules.Load()
+	if rules.skipPath != nil && rules.skipPath(c) {
+		return false
+	}
+	status := c.Writer.Status()
+	for _, r := range rules.SkipStatuses {
+		if r.Contains(status) {
+			return false
+		}
+	}
+	for _, rule := range rules.Sample {
+		if rule.Statuses.Contains(status) {
+			return rule.Rate >= 1 || rand.Float64() < rule.Rate
+		}
+	}
+	return true
+}
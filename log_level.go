@@ -0,0 +1,146 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+)
+
+// LogLevel is the level of the messages gin prints about itself: registered
+// routes, loaded templates, warnings and errors. It does not affect the Logger
+// middleware.
+type LogLevel int32
+
+const (
+	// LogLevelDebug prints every message.
+	LogLevelDebug LogLevel = iota
+	// LogLevelInfo prints informational messages, warnings and errors.
+	LogLevelInfo
+	// LogLevelWarn prints warnings and errors.
+	LogLevelWarn
+	// LogLevelError prints errors.
+	LogLevelError
+	// LogLevelOff prints nothing.
+	LogLevelOff
+)
+
+var logLevelNames = [...]string{
+	LogLevelDebug: "debug",
+	LogLevelInfo:  "info",
+	LogLevelWarn:  "warn",
+	LogLevelError: "error",
+	LogLevelOff:   "off",
+}
+
+// String returns the name of the level.
+func (level LogLevel) String() string {
+	if level < LogLevelDebug || level > LogLevelOff {
+		return fmt.Sprintf("LogLevel(%d)", int32(level))
+	}
+	return logLevelNames[level]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (level LogLevel) MarshalText() ([]byte, error) {
+	return []byte(level.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (level *LogLevel) UnmarshalText(text []byte) error {
+	l, err := ParseLogLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*level = l
+	return nil
+}
+
+// ParseLogLevel returns the level named text: debug, info, warn, error or off.
+func ParseLogLevel(text string) (LogLevel, error) {
+	for level, name := range logLevelNames {
+		if strings.EqualFold(text, name) {
+			return LogLevel(level), nil
+		}
+	}
+	return 0, fmt.Errorf("gin: unknown log level %q (available levels: debug info warn error off)", text)
+}
+
+var logLevel atomic.Int32
+
+// SetLogLevel sets the level of the messages gin prints about itself. It is safe
+// to call while serving. SetMode resets it.
+func SetLogLevel(level LogLevel) {
+	logLevel.Store(int32(level))
+}
+
+// CurrentLogLevel returns the level set by SetLogLevel or SetMode.
+func CurrentLogLevel() LogLevel {
+	return LogLevel(logLevel.Load())
+}
+
+// DebugLogFunc, when set, receives the messages gin prints about itself
+// instead of DefaultWriter and DefaultErrorWriter, e.g. to forward them to a
+// structured logger. Messages below CurrentLogLevel are not passed.
+var DebugLogFunc func(level LogLevel, format string, values ...any)
+
+func logf(level LogLevel, format string, values ...any) {
+	if level < CurrentLogLevel() {
+		return
+	}
+	if DebugLogFunc != nil {
+		DebugLogFunc(level, format, values...)
+		return
+	}
+	if !strings.HasSuffix(format, "\n") {
+		format += "\n"
+	}
+	switch level {
+	case LogLevelWarn:
+		fmt.Fprintf(DefaultWriter, "[GIN-debug] [WARNING] "+format, values...)
+	case LogLevelError:
+		fmt.Fprintf(DefaultErrorWriter, "[GIN-debug] [ERROR] "+format, values...)
+	default:
+		fmt.Fprintf(DefaultWriter, "[GIN-debug] "+format, values...)
+	}
+}
+
+// ToggleDebugOnSignal switches the log level to LogLevelDebug when one of the
+// signals is received (SIGHUP by default), and back to the previous level on the
+// next one, to troubleshoot a running server without restarting it.
+// The returned function stops listening.
+func ToggleDebugOnSignal(signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, signals...)
+	go func() {
+		previous := LogLevelOff
+		for {
+			select {
+			case <-ch:
+				if current := CurrentLogLevel(); current != LogLevelDebug {
+					previous = current
+					SetLogLevel(LogLevelDebug)
+				} else {
+					SetLogLevel(previous)
+				}
+				logf(LogLevelInfo, "Log level switched to %s", CurrentLogLevel())
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
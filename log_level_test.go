@@ -0,0 +1,68 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	for _, level := range []LogLevel{LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError, LogLevelOff} {
+		parsed, err := ParseLogLevel(level.String())
+		assert.NoError(t, err)
+		assert.Equal(t, level, parsed)
+	}
+	level, err := ParseLogLevel("WARN")
+	assert.NoError(t, err)
+	assert.Equal(t, LogLevelWarn, level)
+
+	_, err = ParseLogLevel("verbose")
+	assert.Error(t, err)
+	assert.Equal(t, "LogLevel(9)", LogLevel(9).String())
+}
+
+func TestSetModeResetsLogLevel(t *testing.T) {
+	defer SetMode(TestMode)
+	SetMode(DebugMode)
+	assert.Equal(t, LogLevelDebug, CurrentLogLevel())
+	SetMode(ReleaseMode)
+	assert.Equal(t, LogLevelOff, CurrentLogLevel())
+	New().SetMode(DebugMode)
+	assert.Equal(t, DebugMode, Mode())
+	assert.Equal(t, LogLevelDebug, CurrentLogLevel())
+}
+
+func TestLogLevelFiltersMessages(t *testing.T) {
+	re := captureOutput(t, func() {
+		SetMode(ReleaseMode)
+		SetLogLevel(LogLevelWarn)
+		debugPrint("route")
+		debugPrintInfo("listening")
+		debugPrintWarning("careful")
+		debugPrintError(errors.New("failed"))
+		SetMode(TestMode)
+	})
+	assert.Equal(t, "[GIN-debug] [WARNING] careful\n[GIN-debug] [ERROR] failed\n", re)
+}
+
+func TestDebugLogFunc(t *testing.T) {
+	defer SetMode(TestMode)
+	var messages []string
+	DebugLogFunc = func(level LogLevel, format string, values ...any) {
+		messages = append(messages, level.String()+": "+fmt.Sprintf(format, values...))
+	}
+	defer func() { DebugLogFunc = nil }()
+
+	SetMode(DebugMode)
+	debugPrintInfo("listening on %s", ":8080")
+	SetLogLevel(LogLevelError)
+	debugPrintWarning("careful")
+	debugPrintError(errors.New("failed"))
+	assert.Equal(t, []string{"info: listening on :8080", "error: failed"}, messages)
+}
@@ -0,0 +1,28 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package gin
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToggleDebugOnSignal(t *testing.T) {
+	defer SetMode(TestMode)
+	SetMode(ReleaseMode)
+	SetLogLevel(LogLevelWarn)
+	stop := ToggleDebugOnSignal(syscall.SIGUSR1)
+	defer stop()
+
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool { return CurrentLogLevel() == LogLevelDebug }, time.Second, time.Millisecond)
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool { return CurrentLogLevel() == LogLevelWarn }, time.Second, time.Millisecond)
+}
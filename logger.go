@@ -47,6 +47,16 @@ type LoggerConfig struct {
 	// SkipPaths is an url path array which logs are not written.
 	// Optional.
 	SkipPaths []string
+
+	// Skip is a Skipper that indicates which logs should not be written,
+	// called once the request has been handled.
+	// Optional.
+	Skip Skipper
+
+	// Filter holds path patterns, status ranges and sampling rules deciding
+	// which requests are logged, which can be updated at runtime.
+	// Optional.
+	Filter *LogFilter
 }
 
 // LogFormatter gives the signature of the formatter function passed to LoggerWithFormatter
@@ -76,6 +86,8 @@ type LogFormatterParams struct {
 	BodySize int
 	// Keys are the keys set on the request's context.
 	Keys map[string]any
+	// Slow is set when the latency reaches Engine.SlowRequestThreshold.
+	Slow bool
 }
 
 // StatusCodeColor is the ANSI color for appropriately logging http status code to a terminal.
@@ -140,13 +152,18 @@ var defaultLogFormatter = func(param LogFormatterParams) string {
 	if param.Latency > time.Minute {
 		param.Latency = param.Latency.Truncate(time.Second)
 	}
-	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+	var slow string
+	if param.Slow {
+		slow = " [SLOW]"
+	}
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v%s\n%s",
 		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
 		statusColor, param.StatusCode, resetColor,
 		param.Latency,
 		param.ClientIP,
 		methodColor, param.Method, resetColor,
 		param.Path,
+		slow,
 		param.ErrorMessage,
 	)
 }
@@ -239,32 +256,42 @@ func LoggerWithConfig(conf LoggerConfig) HandlerFunc {
 		// Process request
 		c.Next()
 
-		// Log only when path is not being skipped
-		if _, ok := skip[path]; !ok {
-			param := LogFormatterParams{
-				Request: c.Request,
-				isTerm:  isTerm,
-				Keys:    c.Keys,
-			}
+		// Log only when the request is not being skipped
+		if _, ok := skip[path]; ok {
+			return
+		}
+		if conf.Skip != nil && conf.Skip(c) {
+			return
+		}
+		if conf.Filter != nil && !conf.Filter.logged(c) {
+			return
+		}
+		param := LogFormatterParams{
+			Request: c.Request,
+			isTerm:  isTerm,
+			Keys:    c.Keys,
+		}
 
-			// Stop timer
-			param.TimeStamp = time.Now()
-			param.Latency = param.TimeStamp.Sub(start)
+		// Stop timer
+		param.TimeStamp = time.Now()
+		param.Latency = param.TimeStamp.Sub(start)
+		if threshold := c.engine.SlowRequestThreshold; threshold > 0 {
+			param.Slow = param.Latency >= threshold
+		}
 
-			param.ClientIP = c.ClientIP()
-			param.Method = c.Request.Method
-			param.StatusCode = c.Writer.Status()
-			param.ErrorMessage = c.Errors.ByType(ErrorTypePrivate).String()
+		param.ClientIP = c.ClientIP()
+		param.Method = c.Request.Method
+		param.StatusCode = c.Writer.Status()
+		param.ErrorMessage = c.Errors.ByType(ErrorTypePrivate).String()
 
-			param.BodySize = c.Writer.Size()
+		param.BodySize = c.Writer.Size()
 
-			if raw != "" {
-				path = path + "?" + raw
-			}
+		if raw != "" {
+			path = path + "?" + raw
+		}
 
-			param.Path = path
+		param.Path = path
 
-			fmt.Fprint(out, formatter(param))
-		}
+		fmt.Fprint(out, formatter(param))
 	}
 }
@@ -414,6 +414,59 @@ func TestLoggerWithConfigSkippingPaths(t *testing.T) {
 	assert.Contains(t, buffer.String(), "")
 }
 
+func TestLoggerWithConfigSkipper(t *testing.T) {
+	buffer := new(strings.Builder)
+	router := New()
+	router.Use(LoggerWithConfig(LoggerConfig{
+		Output: buffer,
+		Skip: func(c *Context) bool {
+			return c.Writer.Status() == http.StatusNoContent
+		},
+	}))
+	router.GET("/logged", func(c *Context) {})
+	router.GET("/skipped", func(c *Context) { c.Status(http.StatusNoContent) })
+
+	PerformRequest(router, "GET", "/skipped")
+	assert.Empty(t, buffer.String())
+	PerformRequest(router, "GET", "/logged")
+	assert.Contains(t, buffer.String(), "/logged")
+}
+
+func TestLoggerWithConfigFilter(t *testing.T) {
+	buffer := new(strings.Builder)
+	filter := NewLogFilter(LogRules{
+		SkipPaths:    []string{"/health", "/static/**"},
+		SkipStatuses: []StatusRange{{Min: 300, Max: 399}},
+		Sample: []LogSampleRule{
+			{Statuses: StatusRange{Min: 200, Max: 299}, Rate: 0},
+			{Statuses: StatusRange{Min: 500, Max: 599}, Rate: 1},
+		},
+	})
+	router := New()
+	router.Use(LoggerWithConfig(LoggerConfig{Output: buffer, Filter: filter}))
+	router.GET("/health", func(c *Context) {})
+	router.GET("/static/*file", func(c *Context) { c.Status(http.StatusNotFound) })
+	router.GET("/ok", func(c *Context) {})
+	router.GET("/redirect", func(c *Context) { c.Redirect(http.StatusFound, "/ok") })
+	router.GET("/error", func(c *Context) { c.Status(http.StatusInternalServerError) })
+	router.GET("/missing", func(c *Context) { c.Status(http.StatusNotFound) })
+
+	for _, path := range []string{"/health", "/static/app.js", "/ok", "/redirect", "/error", "/missing"} {
+		PerformRequest(router, "GET", path)
+	}
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "/error")
+	assert.Contains(t, lines[1], "/missing")
+
+	// the rules can be replaced at runtime.
+	buffer.Reset()
+	filter.Update(LogRules{})
+	assert.Equal(t, LogRules{}, filter.Rules())
+	PerformRequest(router, "GET", "/health")
+	assert.Contains(t, buffer.String(), "/health")
+}
+
 func TestDisableConsoleColor(t *testing.T) {
 	New()
 	assert.Equal(t, autoColor, consoleColorMode)
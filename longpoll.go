@@ -0,0 +1,61 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// LongPoll answers a long polling request: it calls poll with a context done
+// after maxWait, or when the client goes away, and waits for it to return. When
+// poll returns a result, it is rendered as JSON with 200 OK; when it returns
+// false, e.g. because its context is done, the response is 204 No Content so
+// that the client polls again. Nothing is written once the client is gone.
+// LongPoll reports whether a result was sent.
+//
+// poll must block on a channel or on its context rather than sleep and retry,
+// see PollChan:
+//
+//	router.GET("/events", func(c *gin.Context) {
+//		sub := broker.Subscribe(c.Query("since"))
+//		defer sub.Close()
+//		c.LongPoll(30*time.Second, gin.PollChan(sub.Events()))
+//	})
+func (c *Context) LongPoll(maxWait time.Duration, poll func(ctx context.Context) (any, bool)) bool {
+	parent := context.Background()
+	if c.Request != nil {
+		parent = c.Request.Context()
+	}
+	ctx, cancel := context.WithTimeout(parent, maxWait)
+	defer cancel()
+
+	result, ok := poll(ctx)
+	if c.IsClientGone() {
+		c.Abort()
+		return false
+	}
+	c.Header("Cache-Control", "no-store")
+	if !ok {
+		c.Status(http.StatusNoContent)
+		return false
+	}
+	c.JSON(http.StatusOK, result)
+	return true
+}
+
+// PollChan returns a poll function for LongPoll receiving the result from ch.
+// It returns false when ch is closed or when the context is done first.
+func PollChan[T any](ch <-chan T) func(ctx context.Context) (any, bool) {
+	return func(ctx context.Context) (any, bool) {
+		select {
+		case v, ok := <-ch:
+			return v, ok
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
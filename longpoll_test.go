@@ -0,0 +1,85 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLongPollResult(t *testing.T) {
+	events := make(chan string, 1)
+	router := New()
+	router.GET("/poll", func(c *Context) {
+		c.LongPoll(time.Second, PollChan(events))
+	})
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		events <- "hello"
+	}()
+	w := PerformRequest(router, http.MethodGet, "/poll")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"hello"`, w.Body.String())
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+}
+
+func TestLongPollTimeout(t *testing.T) {
+	var sent bool
+	router := New()
+	router.GET("/poll", func(c *Context) {
+		sent = c.LongPoll(10*time.Millisecond, PollChan(make(chan int)))
+	})
+
+	start := time.Now()
+	w := PerformRequest(router, http.MethodGet, "/poll")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.False(t, sent)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestLongPollClosedChan(t *testing.T) {
+	ch := make(chan int)
+	close(ch)
+	router := New()
+	router.GET("/poll", func(c *Context) {
+		c.LongPoll(time.Minute, PollChan(ch))
+	})
+	w := PerformRequest(router, http.MethodGet, "/poll")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestLongPollClientGone(t *testing.T) {
+	var sent bool
+	aborted := make(chan bool, 1)
+	router := New()
+	router.GET("/poll", func(c *Context) {
+		sent = c.LongPoll(time.Minute, func(ctx context.Context) (any, bool) {
+			<-ctx.Done()
+			return "late", true
+		})
+		aborted <- c.IsAborted()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/poll", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	router.ServeHTTP(w, req)
+
+	assert.True(t, <-aborted)
+	assert.False(t, sent)
+	assert.Empty(t, w.Body.String())
+	assert.Empty(t, w.Header().Get("Cache-Control"))
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MaintenanceOptions defines the behavior of the maintenance mode, see Engine.SetMaintenance.
+type MaintenanceOptions struct {
+	// AllowPaths are the routes still served during maintenance (health checks,
+	// status page...), with the syntax of PathMatches.
+	// Optional.
+	AllowPaths []string
+
+	// AllowIPs are the client IP addresses and CIDRs still served during
+	// maintenance, e.g. the office network to check the migration.
+	// Optional.
+	AllowIPs []string
+
+	// Allow is a custom predicate for the requests still served during maintenance.
+	// Optional.
+	Allow func(c *Context) bool
+
+	// RetryAfter is sent in the Retry-After header of the 503 responses.
+	// Optional. No header is sent by default.
+	RetryAfter time.Duration
+
+	// Message is sent to the clients, as JSON ({"error": Message}) when they
+	// accept it, as plain text otherwise.
+	// Optional. Default value is "Service Unavailable".
+	Message string
+
+	// HTML is the page sent to the clients accepting HTML, instead of Message.
+	// Optional.
+	HTML string
+
+	// Handler writes the response, instead of the default one.
+	// Optional.
+	Handler HandlerFunc
+}
+
+type maintenance struct {
+	opts       MaintenanceOptions
+	allowPaths func(*Context) bool
+	allowIPs   []*net.IPNet
+}
+
+// SetMaintenance enables or disables the maintenance mode, which can be done
+// at any time while serving. During maintenance, the routes are answered with
+// a 503 Service Unavailable, except for the requests allowed by opts. The
+// global middleware (Logger, Recovery...) still runs. opts is ignored when
+// disabling.
+func (engine *Engine) SetMaintenance(enabled bool, opts MaintenanceOptions) error {
+	if !enabled {
+		engine.maintenance.Store(nil)
+		return nil
+	}
+	allowIPs, err := parseCIDRs(opts.AllowIPs)
+	if err != nil {
+		return err
+	}
+	if opts.Message == "" {
+		opts.Message = http.StatusText(http.StatusServiceUnavailable)
+	}
+	engine.maintenance.Store(&maintenance{
+		opts:       opts,
+		allowPaths: PathMatches(opts.AllowPaths...),
+		allowIPs:   allowIPs,
+	})
+	return nil
+}
+
+// InMaintenance returns true if the maintenance mode is enabled.
+func (engine *Engine) InMaintenance() bool {
+	return engine.maintenance.Load() != nil
+}
+
+// applyMaintenance replaces the handlers of the route matched by c during maintenance.
+func (engine *Engine) applyMaintenance(c *Context) {
+	if m := engine.maintenance.Load(); m != nil && !m.allows(c) {
+		c.handlers = engine.combineHandlers(HandlersChain{m.handle})
+	}
+}
+
+func (m *maintenance) allows(c *Context) bool {
+	if len(m.opts.AllowPaths) > 0 && m.allowPaths(c) {
+		return true
+	}
+	if len(m.allowIPs) > 0 && containsIP(m.allowIPs, net.ParseIP(c.ClientIP())) {
+		return true
+	}
+	return m.opts.Allow != nil && m.opts.Allow(c)
+}
+
+func (m *maintenance) handle(c *Context) {
+	if m.opts.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int((m.opts.RetryAfter+time.Second-1)/time.Second)))
+	}
+	if m.opts.Handler != nil {
+		c.Status(http.StatusServiceUnavailable)
+		m.opts.Handler(c)
+		c.Abort()
+		return
+	}
+
+	offers := []string{MIMEPlain, MIMEJSON}
+	if m.opts.HTML != "" {
+		offers = []string{MIMEHTML, MIMEPlain, MIMEJSON}
+	}
+	switch c.NegotiateFormat(offers...) {
+	case MIMEHTML:
+		c.Data(http.StatusServiceUnavailable, MIMEHTML+"; charset=utf-8", []byte(m.opts.HTML))
+	case MIMEJSON:
+		c.JSON(http.StatusServiceUnavailable, H{"error": m.opts.Message})
+	default:
+		c.String(http.StatusServiceUnavailable, m.opts.Message)
+	}
+	c.Abort()
+}
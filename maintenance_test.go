@@ -0,0 +1,73 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenance(t *testing.T) {
+	router := New()
+	var global int
+	router.Use(func(c *Context) { global++ })
+	router.GET("/users/:id", func(c *Context) { c.String(http.StatusOK, "user") })
+	router.GET("/healthz", func(c *Context) { c.String(http.StatusOK, "ok") })
+
+	require.NoError(t, router.SetMaintenance(true, MaintenanceOptions{
+		AllowPaths: []string{"/healthz"},
+		AllowIPs:   []string{"10.0.0.0/8"},
+		RetryAfter: 90 * time.Second,
+	}))
+	assert.True(t, router.InMaintenance())
+
+	w := PerformRequest(router, http.MethodGet, "/users/1")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "90", w.Header().Get("Retry-After"))
+	assert.Equal(t, "Service Unavailable", w.Body.String())
+	assert.Equal(t, 1, global)
+
+	w = PerformRequest(router, http.MethodGet, "/users/1", header{Key: "Accept", Value: MIMEJSON})
+	assert.Equal(t, `{"error":"Service Unavailable"}`, w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/healthz")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.RemoteAddr = "10.1.1.1:1234"
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	require.NoError(t, router.SetMaintenance(false, MaintenanceOptions{}))
+	assert.False(t, router.InMaintenance())
+	w = PerformRequest(router, http.MethodGet, "/users/1")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Error(t, router.SetMaintenance(true, MaintenanceOptions{AllowIPs: []string{"nope"}}))
+	assert.False(t, router.InMaintenance())
+}
+
+func TestMaintenanceCustomResponse(t *testing.T) {
+	router := New()
+	router.GET("/", func(c *Context) {})
+	require.NoError(t, router.SetMaintenance(true, MaintenanceOptions{HTML: "<h1>Back soon</h1>"}))
+
+	w := PerformRequest(router, http.MethodGet, "/", header{Key: "Accept", Value: "text/html"})
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "<h1>Back soon</h1>", w.Body.String())
+
+	require.NoError(t, router.SetMaintenance(true, MaintenanceOptions{Handler: func(c *Context) {
+		c.Writer.WriteString("custom")
+	}}))
+	w = PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "custom", w.Body.String())
+}
@@ -8,6 +8,7 @@ import (
 	"flag"
 	"io"
 	"os"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin/binding"
 )
@@ -25,7 +26,7 @@ const (
 )
 
 const (
-	debugCode = iota
+	debugCode int32 = iota
 	releaseCode
 	testCode
 )
@@ -43,10 +44,10 @@ var DefaultWriter io.Writer = os.Stdout
 // DefaultErrorWriter is the default io.Writer used by Gin to debug errors
 var DefaultErrorWriter io.Writer = os.Stderr
 
-var (
-	ginMode  = debugCode
-	modeName = DebugMode
-)
+// ginMode is read on every debug print and may be switched while serving.
+var ginMode atomic.Int32
+
+var modeNames = [...]string{debugCode: DebugMode, releaseCode: ReleaseMode, testCode: TestMode}
 
 func init() {
 	mode := os.Getenv(EnvGinMode)
@@ -54,6 +55,8 @@ func init() {
 }
 
 // SetMode sets gin mode according to input string.
+// It is safe to call while serving, and resets the log level: LogLevelDebug in
+// debug mode, LogLevelOff otherwise. See SetLogLevel.
 func SetMode(value string) {
 	if value == "" {
 		if flag.Lookup("test.v") != nil {
@@ -65,16 +68,23 @@ func SetMode(value string) {
 
 	switch value {
 	case DebugMode:
-		ginMode = debugCode
+		ginMode.Store(debugCode)
+		SetLogLevel(LogLevelDebug)
 	case ReleaseMode:
-		ginMode = releaseCode
+		ginMode.Store(releaseCode)
+		SetLogLevel(LogLevelOff)
 	case TestMode:
-		ginMode = testCode
+		ginMode.Store(testCode)
+		SetLogLevel(LogLevelOff)
 	default:
 		panic("gin mode unknown: " + value + " (available mode: debug release test)")
 	}
+}
 
-	modeName = value
+// SetMode sets gin mode, see SetMode. The mode is process-wide: it applies to
+// every engine.
+func (engine *Engine) SetMode(value string) {
+	SetMode(value)
 }
 
 // DisableBindValidation closes the default validator.
@@ -96,5 +106,5 @@ func EnableJsonDecoderDisallowUnknownFields() {
 
 // Mode returns current gin mode.
 func Mode() string {
-	return modeName
+	return modeNames[ginMode.Load()]
 }
@@ -0,0 +1,176 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path"
+)
+
+// RunMTLS attaches the router to a http.Server and starts listening and serving
+// HTTPS requests with client certificate authentication: the client certificates
+// are verified against the CA certificates in the PEM file clientCAFile, as
+// required by clientAuth. Use tls.RequireAndVerifyClientCert to reject the
+// clients without a certificate at the TLS handshake, or
+// tls.VerifyClientCertIfGiven to require them only on some routes with
+// RequireClientCert.
+// Note: this method will block the calling goroutine indefinitely unless an error happens.
+func (engine *Engine) RunMTLS(addr, certFile, keyFile, clientCAFile string, clientAuth tls.ClientAuthType) (err error) {
+	debugPrintInfo("Listening and serving HTTPS with client certificates on %s\n", addr)
+	defer func() { debugPrintError(err) }()
+
+	if engine.isUnsafeTrustedProxies() {
+		debugPrintWarning("You trusted all proxies, this is NOT safe. We recommend you to set a value.\n" +
+			"Please check https://pkg.go.dev/github.com/gin-gonic/gin#readme-don-t-trust-all-proxies for details.")
+	}
+
+	tlsConfig, err := mtlsConfig(clientCAFile, clientAuth)
+	if err != nil {
+		return
+	}
+	if addr == "" {
+		addr = ":https"
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return
+	}
+	err = engine.serveTLS(listener, func(srv *http.Server, listener net.Listener) error {
+		srv.TLSConfig = engine.tlsConfig(tlsConfig)
+		return srv.ServeTLS(listener, certFile, keyFile)
+	})
+	return
+}
+
+func mtlsConfig(clientCAFile string, clientAuth tls.ClientAuthType) (*tls.Config, error) {
+	pem, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("gin: no CA certificate found in %s", clientCAFile)
+	}
+	return &tls.Config{
+		ClientCAs:  pool,
+		ClientAuth: clientAuth,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
+
+// ClientCertificate returns the verified certificate of the client, or nil if
+// the client did not present one or the server did not verify it. Its Subject
+// and subject alternative names (DNSNames, URIs, EmailAddresses, IPAddresses)
+// identify the client.
+func (c *Context) ClientCertificate() *x509.Certificate {
+	state := c.Request.TLS
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
+
+// ClientCertPolicy defines the client certificates accepted by RequireClientCert.
+// The certificate must match every non-empty list of patterns, and a list is
+// matched when one of its patterns matches, with the syntax of path.Match.
+type ClientCertPolicy struct {
+	// CommonNames are the accepted subject common names.
+	// Optional.
+	CommonNames []string
+
+	// Organizations are the accepted subject organizations.
+	// Optional.
+	Organizations []string
+
+	// DNSNames are the accepted DNS subject alternative names, e.g. "*.internal".
+	// Optional.
+	DNSNames []string
+
+	// URIs are the accepted URI subject alternative names, e.g. SPIFFE ids
+	// like "spiffe://example.org/ns/prod/sa/*".
+	// Optional.
+	URIs []string
+
+	// Verify is a custom check of the certificate.
+	// Optional.
+	Verify func(c *Context, cert *x509.Certificate) bool
+
+	// DeniedHandler is called when the certificate is missing or denied.
+	// Optional. Default value aborts with 401 Unauthorized when the certificate
+	// is missing and 403 Forbidden when it is denied.
+	DeniedHandler HandlerFunc
+}
+
+// RequireClientCert returns a middleware which requires a verified client
+// certificate accepted by policy, see RunMTLS. The common name of the subject is
+// set as the authenticated user, see Context.AuthUser, and the certificate is
+// available with Context.ClientCertificate:
+//
+//	internal := router.Group("/internal", gin.RequireClientCert(gin.ClientCertPolicy{
+//		URIs: []string{"spiffe://example.org/ns/prod/*"},
+//	}))
+func RequireClientCert(policy ClientCertPolicy) HandlerFunc {
+	return func(c *Context) {
+		cert := c.ClientCertificate()
+		if cert == nil {
+			policy.deny(c, http.StatusUnauthorized)
+			return
+		}
+		if !policy.accepts(c, cert) {
+			policy.deny(c, http.StatusForbidden)
+			return
+		}
+		c.Set(AuthUserKey, cert.Subject.CommonName)
+	}
+}
+
+func (policy *ClientCertPolicy) deny(c *Context, code int) {
+	if policy.DeniedHandler != nil {
+		c.Status(code)
+		policy.DeniedHandler(c)
+		c.Abort()
+		return
+	}
+	c.AbortWithStatus(code)
+}
+
+func (policy *ClientCertPolicy) accepts(c *Context, cert *x509.Certificate) bool {
+	if !matchesAny(policy.CommonNames, cert.Subject.CommonName) ||
+		!matchesAny(policy.Organizations, cert.Subject.Organization...) ||
+		!matchesAny(policy.DNSNames, cert.DNSNames...) {
+		return false
+	}
+	if len(policy.URIs) > 0 {
+		uris := make([]string, len(cert.URIs))
+		for i, u := range cert.URIs {
+			uris[i] = u.String()
+		}
+		if !matchesAny(policy.URIs, uris...) {
+			return false
+		}
+	}
+	return policy.Verify == nil || policy.Verify(c, cert)
+}
+
+// matchesAny reports whether one of the values matches one of the patterns, or
+// true if there are no patterns.
+func matchesAny(patterns []string, values ...string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		for _, value := range values {
+			if ok, _ := path.Match(pattern, value); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
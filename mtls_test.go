@@ -0,0 +1,139 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) issue(t *testing.T, tmpl *x509.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func (ca *testCA) writePEM(t *testing.T) string {
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0o600))
+	return file
+}
+
+func TestRequireClientCert(t *testing.T) {
+	ca := newTestCA(t)
+	tlsConfig, err := mtlsConfig(ca.writePEM(t), tls.VerifyClientCertIfGiven)
+	require.NoError(t, err)
+
+	router := New()
+	router.GET("/public", func(c *Context) { c.String(http.StatusOK, "public") })
+	internal := router.Group("/internal", RequireClientCert(ClientCertPolicy{
+		URIs:          []string{"spiffe://example.org/ns/prod/*"},
+		Organizations: []string{"Example"},
+	}))
+	internal.GET("/whoami", func(c *Context) {
+		cert := c.ClientCertificate()
+		c.String(http.StatusOK, c.AuthUser()+" "+cert.URIs[0].String())
+	})
+
+	srv := httptest.NewUnstartedServer(router)
+	srv.TLS = tlsConfig
+	srv.StartTLS()
+	defer srv.Close()
+
+	get := func(cert *tls.Certificate, path string) (int, string) {
+		transport := srv.Client().Transport.(*http.Transport).Clone()
+		if cert != nil {
+			transport.TLSClientConfig.Certificates = []tls.Certificate{*cert}
+		}
+		resp, err := (&http.Client{Transport: transport}).Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	code, body := get(nil, "/public")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "public", body)
+	code, _ = get(nil, "/internal/whoami")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	prod, _ := url.Parse("spiffe://example.org/ns/prod/billing")
+	client := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "billing", Organization: []string{"Example"}},
+		URIs:        []*url.URL{prod},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	code, body = get(&client, "/internal/whoami")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "billing spiffe://example.org/ns/prod/billing", body)
+
+	dev, _ := url.Parse("spiffe://example.org/ns/dev/billing")
+	other := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "billing", Organization: []string{"Example"}},
+		URIs:        []*url.URL{dev},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	code, _ = get(&other, "/internal/whoami")
+	assert.Equal(t, http.StatusForbidden, code)
+}
+
+func TestClientCertificateWithoutTLS(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Nil(t, c.ClientCertificate())
+}
+
+func TestRunMTLSBadClientCA(t *testing.T) {
+	router := New()
+	assert.Error(t, router.RunMTLS(":0", "./testdata/certificate/cert.pem", "./testdata/certificate/key.pem", "./testdata/missing.pem", tls.RequireAndVerifyClientCert))
+	assert.Error(t, router.RunMTLS(":0", "./testdata/certificate/cert.pem", "./testdata/certificate/key.pem", "./testdata/certificate/key.pem", tls.RequireAndVerifyClientCert))
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strconv"
+)
+
+// Subtypes of the multipart responses, see Context.Multipart.
+const (
+	// MultipartMixedReplace is the subtype of the streams whose parts replace
+	// the previous one, e.g. the frames of an MJPEG stream.
+	MultipartMixedReplace = "x-mixed-replace"
+	// MultipartByteRanges is the subtype of the answers to the requests of
+	// several ranges.
+	MultipartByteRanges = "byteranges"
+	// MultipartMixed is the subtype of independent parts.
+	MultipartMixed = "mixed"
+)
+
+// MultipartWriter writes the parts of a multipart response, see Context.Multipart.
+type MultipartWriter struct {
+	c  *Context
+	mw *multipart.Writer
+}
+
+// Multipart starts a multipart response of subtype, e.g. MultipartMixedReplace,
+// whose parts are separated by boundary, or by a random boundary when empty. It
+// sets the Content-Type of the response; the status, 200 OK by default, must be
+// set before, e.g. 206 Partial Content for MultipartByteRanges:
+//
+//	mp := c.Multipart(gin.MultipartMixedReplace, "")
+//	for frame := range camera.Frames() {
+//		if err := mp.WritePart("image/jpeg", frame); err != nil {
+//			return // the client is gone
+//		}
+//	}
+//	mp.Close()
+//
+// It panics when boundary is not a valid boundary.
+func (c *Context) Multipart(subtype, boundary string) *MultipartWriter {
+	mw := multipart.NewWriter(c.Writer)
+	if boundary != "" {
+		if err := mw.SetBoundary(boundary); err != nil {
+			panic(fmt.Sprintf("gin: invalid multipart boundary %q: %v", boundary, err))
+		}
+	}
+	header := c.Writer.Header()
+	header.Set("Content-Type", "multipart/"+subtype+"; boundary="+mw.Boundary())
+	header.Del("Content-Length")
+	if subtype == MultipartMixedReplace {
+		header.Set("Cache-Control", "no-cache")
+	}
+	return &MultipartWriter{c: c, mw: mw}
+}
+
+// Boundary returns the boundary of the parts.
+func (m *MultipartWriter) Boundary() string {
+	return m.mw.Boundary()
+}
+
+// CreatePart starts a part with header, and returns the writer of its body.
+// The part ends with the next one, or with Close. CreatePart fails once the
+// client is gone.
+func (m *MultipartWriter) CreatePart(header textproto.MIMEHeader) (io.Writer, error) {
+	if m.c.IsClientGone() {
+		return nil, m.c.Request.Context().Err()
+	}
+	return m.mw.CreatePart(header)
+}
+
+// WritePart writes a part of contentType whose body is data, and flushes it to
+// the client.
+func (m *MultipartWriter) WritePart(contentType string, data []byte) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Length", strconv.Itoa(len(data)))
+	w, err := m.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(data); err != nil {
+		return err
+	}
+	m.c.Writer.Flush()
+	return nil
+}
+
+// WriteRange writes the part of a MultipartByteRanges response holding the
+// bytes start to end, inclusive, of a content of size bytes, read from r.
+func (m *MultipartWriter) WriteRange(contentType string, start, end, size int64, r io.Reader) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	w, err := m.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.CopyN(w, r, end-start+1)
+	return err
+}
+
+// Close writes the closing boundary and flushes the response.
+func (m *MultipartWriter) Close() error {
+	if err := m.mw.Close(); err != nil {
+		return err
+	}
+	m.c.Writer.Flush()
+	return nil
+}
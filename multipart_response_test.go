@@ -0,0 +1,94 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipartMixedReplace(t *testing.T) {
+	router := New()
+	router.GET("/stream", func(c *Context) {
+		mp := c.Multipart(MultipartMixedReplace, "frame")
+		for _, frame := range []string{"one", "two"} {
+			assert.NoError(t, mp.WritePart("image/jpeg", []byte(frame)))
+		}
+		assert.NoError(t, mp.Close())
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/stream")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "multipart/x-mixed-replace; boundary=frame", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	assert.True(t, w.Flushed)
+
+	r := multipart.NewReader(w.Body, "frame")
+	for _, frame := range []string{"one", "two"} {
+		part, err := r.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, "image/jpeg", part.Header.Get("Content-Type"))
+		assert.Equal(t, "3", part.Header.Get("Content-Length"))
+		data, _ := io.ReadAll(part)
+		assert.Equal(t, frame, string(data))
+	}
+	_, err := r.NextPart()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestMultipartByteRanges(t *testing.T) {
+	content := "0123456789"
+	router := New()
+	router.GET("/file", func(c *Context) {
+		c.Status(http.StatusPartialContent)
+		mp := c.Multipart(MultipartByteRanges, "")
+		src := strings.NewReader(content)
+		assert.NoError(t, mp.WriteRange("text/plain", 0, 1, 10, io.NewSectionReader(src, 0, 2)))
+		assert.NoError(t, mp.WriteRange("text/plain", 7, 9, 10, io.NewSectionReader(src, 7, 3)))
+		assert.NoError(t, mp.Close())
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/file")
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	mediaType, params, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/byteranges", mediaType)
+	assert.NotEmpty(t, params["boundary"])
+
+	r := multipart.NewReader(w.Body, params["boundary"])
+	for _, want := range []struct{ contentRange, data string }{{"bytes 0-1/10", "01"}, {"bytes 7-9/10", "789"}} {
+		part, err := r.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, want.contentRange, part.Header.Get("Content-Range"))
+		data, _ := io.ReadAll(part)
+		assert.Equal(t, want.data, string(data))
+	}
+}
+
+func TestMultipartClientGone(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	ctx, cancel := context.WithCancel(context.Background())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+
+	mp := c.Multipart(MultipartMixed, "")
+	assert.NoError(t, mp.WritePart("text/plain", []byte("a")))
+	cancel()
+	assert.ErrorIs(t, mp.WritePart("text/plain", []byte("b")), context.Canceled)
+}
+
+func TestMultipartInvalidBoundary(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	assert.Panics(t, func() { c.Multipart(MultipartMixed, "bad boundary!\n") })
+}
@@ -0,0 +1,158 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// proxyProtocolTimeout bounds the time a new connection has to send its PROXY header.
+const proxyProtocolTimeout = 5 * time.Second
+
+var (
+	proxyProtocolV1Prefix  = []byte("PROXY ")
+	proxyProtocolSignature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+	errProxyProtocolHeader = errors.New("invalid PROXY protocol header")
+)
+
+// NewProxyProtocolListener wraps l so that the connections accepted from a
+// load balancer speaking the HAProxy PROXY protocol (v1 or v2) report the
+// address of the client as their RemoteAddr, instead of the address of the
+// load balancer. Connections without PROXY header are left unchanged.
+//
+// Only use it behind a load balancer which sends the header, otherwise clients
+// can spoof their address. See Engine.ProxyProtocol.
+func NewProxyProtocolListener(l net.Listener) net.Listener {
+	return &proxyProtocolListener{Listener: l}
+}
+
+type proxyProtocolListener struct {
+	net.Listener
+}
+
+func (l *proxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &proxyProtocolConn{Conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+// proxyProtocolConn reads the PROXY header lazily, in the goroutine serving the
+// connection, so that a slow client does not block Accept.
+type proxyProtocolConn struct {
+	net.Conn
+	reader *bufio.Reader
+
+	once       sync.Once
+	err        error
+	remoteAddr net.Addr
+}
+
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.reader.Read(b)
+}
+
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+	return c.Conn.RemoteAddr()
+}
+
+func (c *proxyProtocolConn) readHeader() {
+	_ = c.Conn.SetReadDeadline(time.Now().Add(proxyProtocolTimeout))
+	defer func() { _ = c.Conn.SetReadDeadline(time.Time{}) }()
+
+	peek, err := c.reader.Peek(len(proxyProtocolV1Prefix))
+	switch {
+	case err != nil:
+		// too short to carry a header: let the server handle the connection.
+		return
+	case bytes.Equal(peek, proxyProtocolV1Prefix):
+		c.remoteAddr, c.err = readProxyProtocolV1(c.reader)
+	case bytes.HasPrefix(proxyProtocolSignature, peek):
+		c.remoteAddr, c.err = readProxyProtocolV2(c.reader)
+	}
+}
+
+// readProxyProtocolV1 reads a header like "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n".
+func readProxyProtocolV1(r *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for len(line) < 107 { // the maximum length of a v1 header
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errProxyProtocolHeader
+	}
+	fields := strings.Fields(string(line))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, errProxyProtocolHeader
+	}
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.Atoi(fields[4])
+	if ip == nil || err != nil || port < 0 || port > 65535 {
+		return nil, errProxyProtocolHeader
+	}
+	return &net.TCPAddr{IP: ip, Port: port}, nil
+}
+
+// readProxyProtocolV2 reads a binary header.
+func readProxyProtocolV2(r *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:12], proxyProtocolSignature) || header[12]>>4 != 2 {
+		return nil, errProxyProtocolHeader
+	}
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	if header[12]&0x0f == 0 {
+		// LOCAL command: health check of the load balancer itself.
+		return nil, nil
+	}
+	switch header[13] >> 4 {
+	case 1: // AF_INET
+		if len(payload) < 12 {
+			return nil, errProxyProtocolHeader
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:]))}, nil
+	case 2: // AF_INET6
+		if len(payload) < 36 {
+			return nil, errProxyProtocolHeader
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:]))}, nil
+	}
+	// AF_UNSPEC or AF_UNIX: keep the address of the connection.
+	return nil, nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func proxyProtocolConnWith(t *testing.T, header []byte) *proxyProtocolConn {
+	client, server := net.Pipe()
+	t.Cleanup(func() { client.Close(); server.Close() })
+	go func() {
+		_, _ = client.Write(append(header, "GET / HTTP/1.1\r\n"...))
+	}()
+	return &proxyProtocolConn{Conn: server, reader: bufio.NewReader(server)}
+}
+
+func TestProxyProtocolV1(t *testing.T) {
+	conn := proxyProtocolConnWith(t, []byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n"))
+	assert.Equal(t, "192.0.2.1:56324", conn.RemoteAddr().String())
+	line := make([]byte, 16)
+	_, err := io.ReadFull(conn, line)
+	assert.NoError(t, err)
+	assert.Equal(t, "GET / HTTP/1.1\r\n", string(line))
+
+	conn = proxyProtocolConnWith(t, []byte("PROXY UNKNOWN\r\n"))
+	assert.Equal(t, "pipe", conn.RemoteAddr().String())
+
+	conn = proxyProtocolConnWith(t, []byte("PROXY TCP4 nope\r\n"))
+	_, err = conn.Read(line)
+	assert.Equal(t, errProxyProtocolHeader, err)
+}
+
+func TestProxyProtocolV2(t *testing.T) {
+	header := append([]byte{}, proxyProtocolSignature...)
+	header = append(header, 0x21, 0x21, 0, 36) // PROXY command, TCP over IPv6
+	header = append(header, net.ParseIP("2001:db8::1")...)
+	header = append(header, net.ParseIP("2001:db8::2")...)
+	header = binary.BigEndian.AppendUint16(header, 4711)
+	header = binary.BigEndian.AppendUint16(header, 443)
+
+	conn := proxyProtocolConnWith(t, header)
+	assert.Equal(t, "[2001:db8::1]:4711", conn.RemoteAddr().String())
+	line := make([]byte, 16)
+	_, err := io.ReadFull(conn, line)
+	assert.NoError(t, err)
+	assert.Equal(t, "GET / HTTP/1.1\r\n", string(line))
+}
+
+func TestProxyProtocolWithoutHeader(t *testing.T) {
+	conn := proxyProtocolConnWith(t, nil)
+	assert.Equal(t, "pipe", conn.RemoteAddr().String())
+	line := make([]byte, 16)
+	_, err := io.ReadFull(conn, line)
+	assert.NoError(t, err)
+	assert.Equal(t, "GET / HTTP/1.1\r\n", string(line))
+}
+
+func TestRunListenerProxyProtocol(t *testing.T) {
+	router := New()
+	router.ProxyProtocol = true
+	router.GET("/", func(c *Context) { c.String(http.StatusOK, c.RemoteIP()) })
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- router.RunListener(listener) }()
+	defer func() {
+		listener.Close()
+		<-done
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("PROXY TCP4 203.0.113.7 192.0.2.1 56324 80\r\n" +
+		"GET / HTTP/1.1\r\nHost: example.com\r\nConnection: close\r\n\r\n"))
+	require.NoError(t, err)
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", string(body))
+}
@@ -0,0 +1,285 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// QuotaUsage is the usage of a quota key over a window: the number of requests,
+// and the bytes of their bodies and of the response bodies.
+type QuotaUsage struct {
+	Requests int64
+	Bytes    int64
+}
+
+// QuotaLimits are the limits of a quota key over a window. A zero limit is
+// unlimited.
+type QuotaLimits struct {
+	Requests int64
+	Bytes    int64
+}
+
+// QuotaPlan is the plan of a quota key: over Hard the requests are rejected,
+// crossing Soft calls QuotaConfig.OnSoftLimit, e.g. to notify the customer or
+// to bill the overage.
+type QuotaPlan struct {
+	Hard QuotaLimits
+	Soft QuotaLimits
+}
+
+// QuotaStore stores the usage of the quota keys, e.g. in Redis or in a database
+// for the quotas to be shared by several instances and to be billed. It must be
+// safe for concurrent use.
+type QuotaStore interface {
+	// Get returns the usage of key in the window starting at window.
+	Get(key string, window time.Time) (QuotaUsage, error)
+	// Add adds delta, which may be negative, to the usage of key in the window
+	// starting at window, and returns the new usage.
+	Add(key string, window time.Time, delta QuotaUsage) (QuotaUsage, error)
+}
+
+// QuotaWindow returns the start and the end of the billing window containing now.
+type QuotaWindow func(now time.Time) (start, end time.Time)
+
+// QuotaDaily is the QuotaWindow of the UTC days.
+func QuotaDaily(now time.Time) (start, end time.Time) {
+	y, m, d := now.UTC().Date()
+	start = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// QuotaMonthly is the QuotaWindow of the UTC calendar months.
+func QuotaMonthly(now time.Time) (start, end time.Time) {
+	y, m, _ := now.UTC().Date()
+	start = time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// QuotaEvery returns the QuotaWindow of the consecutive periods of d since the
+// Unix epoch.
+func QuotaEvery(d time.Duration) QuotaWindow {
+	assert1(d > 0, "QuotaEvery requires a positive duration")
+	return func(now time.Time) (start, end time.Time) {
+		start = now.Truncate(d)
+		return start, start.Add(d)
+	}
+}
+
+// QuotaConfig defines the config of the Quota middleware.
+type QuotaConfig struct {
+	// Plan returns the plan of key.
+	// Required.
+	Plan func(c *Context, key string) QuotaPlan
+
+	// Key returns the quota key of the request, e.g. its API key. The requests
+	// with an empty key are not accounted.
+	// Optional. Default value is Context.TenantID.
+	Key func(c *Context) string
+
+	// Store stores the usage.
+	// Optional. Default value is a NewMemoryQuotaStore.
+	Store QuotaStore
+
+	// Window returns the billing window.
+	// Optional. Default value is QuotaMonthly.
+	Window QuotaWindow
+
+	// OnSoftLimit is called by the request crossing a soft limit of its key,
+	// once per window.
+	// Optional.
+	OnSoftLimit func(c *Context, key string, usage QuotaUsage)
+
+	// OnHardLimit is called for each request rejected over a hard limit of its key.
+	// Optional.
+	OnHardLimit func(c *Context, key string, usage QuotaUsage)
+
+	// ExceededHandler is called when a request is rejected.
+	// Optional. Default value aborts with 429 Too Many Requests.
+	ExceededHandler HandlerFunc
+}
+
+// Quota returns a middleware accounting the requests and the bytes of each quota
+// key over billing windows, and rejecting the requests over the hard limits of
+// their plan. The requests and the bytes left are sent in the X-Quota-Limit,
+// X-Quota-Remaining, X-Quota-Bytes-Limit and X-Quota-Bytes-Remaining headers,
+// and the seconds until the end of the window in X-Quota-Reset. The usage is
+// accounted when the request comes in, so that a byte limit is enforced from
+// the next request; the bodies of unknown length (chunked) are accounted as
+// they are read. Store errors are added to the context errors, and the
+// request is served.
+func Quota(config QuotaConfig) HandlerFunc {
+	assert1(config.Plan != nil, "Quota requires a Plan")
+	if config.Key == nil {
+		config.Key = (*Context).TenantID
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryQuotaStore()
+	}
+	if config.Window == nil {
+		config.Window = QuotaMonthly
+	}
+	if config.ExceededHandler == nil {
+		config.ExceededHandler = func(c *Context) {
+			c.AbortWithStatus(http.StatusTooManyRequests)
+		}
+	}
+	return func(c *Context) {
+		key := config.Key(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+		now := time.Now()
+		start, end := config.Window(now)
+		plan := config.Plan(c, key)
+
+		in := QuotaUsage{Requests: 1}
+		var body *quotaBody
+		switch {
+		case c.Request.ContentLength > 0:
+			in.Bytes = c.Request.ContentLength
+		case c.Request.ContentLength < 0 && c.Request.Body != nil:
+			body = &quotaBody{ReadCloser: c.Request.Body}
+			c.Request.Body = body
+		}
+		usage, err := config.Store.Add(key, start, in)
+		if err != nil {
+			_ = c.Error(err)
+			c.Next()
+			return
+		}
+		reset := int((end.Sub(now) + time.Second - 1) / time.Second)
+		if quotaExceeded(usage, in, plan.Hard) {
+			if usage, err = config.Store.Add(key, start, QuotaUsage{Requests: -in.Requests, Bytes: -in.Bytes}); err != nil {
+				_ = c.Error(err)
+			}
+			setQuotaHeaders(c, plan.Hard, usage, reset)
+			c.Header("Retry-After", strconv.Itoa(reset))
+			if config.OnHardLimit != nil {
+				config.OnHardLimit(c, key, usage)
+			}
+			config.ExceededHandler(c)
+			c.Abort()
+			return
+		}
+		setQuotaHeaders(c, plan.Hard, usage, reset)
+		softCrossed := quotaCrossed(usage.Requests-in.Requests, usage.Requests, plan.Soft.Requests) ||
+			quotaCrossed(usage.Bytes-in.Bytes, usage.Bytes, plan.Soft.Bytes)
+
+		c.Next()
+
+		size := int64(c.Writer.Size())
+		if size < 0 {
+			size = 0
+		}
+		if body != nil {
+			size += body.n
+		}
+		if size > 0 {
+			if usage, err = config.Store.Add(key, start, QuotaUsage{Bytes: size}); err != nil {
+				_ = c.Error(err)
+			} else {
+				softCrossed = softCrossed || quotaCrossed(usage.Bytes-size, usage.Bytes, plan.Soft.Bytes)
+			}
+		}
+		if softCrossed && config.OnSoftLimit != nil {
+			config.OnSoftLimit(c, key, usage)
+		}
+	}
+}
+
+// quotaBody counts the bytes read from a request body of unknown length.
+type quotaBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *quotaBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+// quotaExceeded reports whether the request in, accounted in usage, is over the
+// request limit, or comes when the byte limit is reached already.
+func quotaExceeded(usage, in QuotaUsage, limits QuotaLimits) bool {
+	return limits.Requests > 0 && usage.Requests > limits.Requests ||
+		limits.Bytes > 0 && usage.Bytes-in.Bytes >= limits.Bytes
+}
+
+// quotaCrossed reports whether a usage going from before to after crossed a positive
+// limit.
+func quotaCrossed(before, after, limit int64) bool {
+	return limit > 0 && before < limit && after >= limit
+}
+
+func setQuotaHeaders(c *Context, limits QuotaLimits, usage QuotaUsage, reset int) {
+	if limits.Requests > 0 {
+		c.Header("X-Quota-Limit", strconv.FormatInt(limits.Requests, 10))
+		c.Header("X-Quota-Remaining", strconv.FormatInt(quotaRemaining(usage.Requests, limits.Requests), 10))
+	}
+	if limits.Bytes > 0 {
+		c.Header("X-Quota-Bytes-Limit", strconv.FormatInt(limits.Bytes, 10))
+		c.Header("X-Quota-Bytes-Remaining", strconv.FormatInt(quotaRemaining(usage.Bytes, limits.Bytes), 10))
+	}
+	if limits.Requests > 0 || limits.Bytes > 0 {
+		c.Header("X-Quota-Reset", strconv.Itoa(reset))
+	}
+}
+
+func quotaRemaining(used, limit int64) int64 {
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
+
+// MemoryQuotaStore is a QuotaStore in memory, which keeps the usage of the
+// current window of each key only.
+type MemoryQuotaStore struct {
+	mu   sync.Mutex
+	keys map[string]*windowUsage
+}
+
+type windowUsage struct {
+	start time.Time
+	usage QuotaUsage
+}
+
+// NewMemoryQuotaStore returns an empty MemoryQuotaStore.
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{keys: make(map[string]*windowUsage)}
+}
+
+// Get implements QuotaStore.
+func (s *MemoryQuotaStore) Get(key string, window time.Time) (QuotaUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if w := s.keys[key]; w != nil && w.start.Equal(window) {
+		return w.usage, nil
+	}
+	return QuotaUsage{}, nil
+}
+
+// Add implements QuotaStore. The usage of the previous windows of key is dropped.
+func (s *MemoryQuotaStore) Add(key string, window time.Time, delta QuotaUsage) (QuotaUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w := s.keys[key]
+	if w == nil || w.start.Before(window) {
+		w = &windowUsage{start: window}
+		s.keys[key] = w
+	} else if !w.start.Equal(window) {
+		return QuotaUsage{}, nil
+	}
+	w.usage.Requests += delta.Requests
+	w.usage.Bytes += delta.Bytes
+	return w.usage, nil
+}
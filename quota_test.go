@@ -0,0 +1,204 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func quotaRouter(config QuotaConfig) *Engine {
+	router := New()
+	router.Use(Quota(config))
+	router.GET("/", func(c *Context) {
+		c.String(http.StatusOK, strings.Repeat("x", 10))
+	})
+	return router
+}
+
+func TestQuotaHardLimit(t *testing.T) {
+	var rejected []QuotaUsage
+	config := QuotaConfig{
+		Key: func(c *Context) string { return c.GetHeader("X-API-Key") },
+		Plan: func(c *Context, key string) QuotaPlan {
+			return QuotaPlan{Hard: QuotaLimits{Requests: 2}}
+		},
+		OnHardLimit: func(c *Context, key string, usage QuotaUsage) {
+			rejected = append(rejected, usage)
+		},
+	}
+	router := quotaRouter(config)
+
+	w := PerformRequest(router, http.MethodGet, "/", header{"X-API-Key", "k1"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-Quota-Limit"))
+	assert.Equal(t, "1", w.Header().Get("X-Quota-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("X-Quota-Reset"))
+	assert.Empty(t, w.Header().Get("X-Quota-Bytes-Limit"))
+
+	w = PerformRequest(router, http.MethodGet, "/", header{"X-API-Key", "k1"})
+	assert.Equal(t, "0", w.Header().Get("X-Quota-Remaining"))
+
+	w = PerformRequest(router, http.MethodGet, "/", header{"X-API-Key", "k1"})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-Quota-Remaining"))
+	assert.Equal(t, w.Header().Get("X-Quota-Reset"), w.Header().Get("Retry-After"))
+	assert.Equal(t, []QuotaUsage{{Requests: 2, Bytes: 20}}, rejected)
+
+	w = PerformRequest(router, http.MethodGet, "/", header{"X-API-Key", "k2"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-Quota-Limit"))
+}
+
+func TestQuotaBytes(t *testing.T) {
+	var soft []QuotaUsage
+	store := NewMemoryQuotaStore()
+	router := quotaRouter(QuotaConfig{
+		Key:   func(c *Context) string { return "k" },
+		Store: store,
+		Plan: func(c *Context, key string) QuotaPlan {
+			return QuotaPlan{Hard: QuotaLimits{Bytes: 30}, Soft: QuotaLimits{Bytes: 15}}
+		},
+		OnSoftLimit: func(c *Context, key string, usage QuotaUsage) {
+			soft = append(soft, usage)
+		},
+	})
+
+	for i := 0; i < 3; i++ {
+		w := PerformRequest(router, http.MethodGet, "/")
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	assert.Equal(t, []QuotaUsage{{Requests: 2, Bytes: 20}}, soft)
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("X-Quota-Bytes-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-Quota-Bytes-Remaining"))
+
+	start, _ := QuotaMonthly(time.Now())
+	usage, err := store.Get("k", start)
+	assert.NoError(t, err)
+	assert.Equal(t, QuotaUsage{Requests: 3, Bytes: 30}, usage)
+}
+
+func TestQuotaChunkedBody(t *testing.T) {
+	store := NewMemoryQuotaStore()
+	router := New()
+	router.Use(Quota(QuotaConfig{
+		Key:   func(c *Context) string { return "k" },
+		Store: store,
+		Plan: func(c *Context, key string) QuotaPlan {
+			return QuotaPlan{Hard: QuotaLimits{Bytes: 100}}
+		},
+	}))
+	router.POST("/", func(c *Context) {
+		_, _ = io.Copy(io.Discard, c.Request.Body)
+	})
+
+	body := strings.Repeat("x", 100)
+	// a chunked request.
+	req, _ := http.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader(body)))
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	start, _ := QuotaMonthly(time.Now())
+	usage, err := store.Get("k", start)
+	assert.NoError(t, err)
+	assert.Equal(t, QuotaUsage{Requests: 1, Bytes: 100}, usage)
+
+	req, _ = http.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader(body)))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestQuotaSoftLimitRequests(t *testing.T) {
+	calls := 0
+	router := quotaRouter(QuotaConfig{
+		Key:         func(c *Context) string { return "k" },
+		Plan:        func(c *Context, key string) QuotaPlan { return QuotaPlan{Soft: QuotaLimits{Requests: 2}} },
+		OnSoftLimit: func(c *Context, key string, usage QuotaUsage) { calls++ },
+	})
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, PerformRequest(router, http.MethodGet, "/").Code)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+type failingQuotaStore struct{}
+
+func (failingQuotaStore) Get(string, time.Time) (QuotaUsage, error) {
+	return QuotaUsage{}, errors.New("store down")
+}
+
+func (failingQuotaStore) Add(string, time.Time, QuotaUsage) (QuotaUsage, error) {
+	return QuotaUsage{}, errors.New("store down")
+}
+
+func TestQuotaStoreError(t *testing.T) {
+	var errs []string
+	router := New()
+	router.Use(func(c *Context) {
+		c.Next()
+		errs = c.Errors.Errors()
+	}, Quota(QuotaConfig{
+		Key:   func(c *Context) string { return "k" },
+		Store: failingQuotaStore{},
+		Plan:  func(c *Context, key string) QuotaPlan { return QuotaPlan{Hard: QuotaLimits{Requests: 1}} },
+	}))
+	router.GET("/", func(c *Context) {})
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"store down"}, errs)
+}
+
+func TestQuotaWindows(t *testing.T) {
+	now := time.Date(2023, 2, 14, 15, 4, 5, 0, time.UTC)
+	start, end := QuotaDaily(now)
+	assert.Equal(t, time.Date(2023, 2, 14, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2023, 2, 15, 0, 0, 0, 0, time.UTC), end)
+
+	start, end = QuotaMonthly(now)
+	assert.Equal(t, time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC), end)
+
+	start, end = QuotaEvery(time.Hour)(now)
+	assert.Equal(t, time.Date(2023, 2, 14, 15, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2023, 2, 14, 16, 0, 0, 0, time.UTC), end)
+
+	assert.Panics(t, func() { QuotaEvery(0) })
+	assert.Panics(t, func() { Quota(QuotaConfig{}) })
+}
+
+func TestMemoryQuotaStoreWindows(t *testing.T) {
+	s := NewMemoryQuotaStore()
+	jan := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	usage, _ := s.Add("k", jan, QuotaUsage{Requests: 1, Bytes: 5})
+	assert.Equal(t, QuotaUsage{Requests: 1, Bytes: 5}, usage)
+	usage, _ = s.Add("k", feb, QuotaUsage{Requests: 1})
+	assert.Equal(t, QuotaUsage{Requests: 1}, usage)
+
+	usage, _ = s.Get("k", jan)
+	assert.Equal(t, QuotaUsage{}, usage)
+	usage, _ = s.Add("k", jan, QuotaUsage{Requests: 1})
+	assert.Equal(t, QuotaUsage{}, usage)
+	usage, _ = s.Get("k", feb)
+	assert.Equal(t, QuotaUsage{Requests: 1}, usage)
+}
@@ -0,0 +1,11 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build race
+
+package gin
+
+// raceEnabled is set when the tests run with the race detector, which makes
+// sync.Pool drop some of the pooled values, so allocations can not be gated.
+const raceEnabled = true
@@ -0,0 +1,156 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrUnsafeRedirect is returned by the redirect helpers of Context for a
+// location on another host, which is not listed in Engine.RedirectAllowedHosts.
+var ErrUnsafeRedirect = errors.New("gin: redirect to a host which is not allowed")
+
+// RouteURL returns the path of the route named name (see RouterGroup.Name), with
+// its parameters replaced by the values of params. The values of params which
+// are not route parameters are added as query string:
+//
+//	router.GET("/users/:id", getUser).(gin.RouteNamer).Name("users.show")
+//	router.RouteURL("users.show", map[string]string{"id": "42", "tab": "posts"})
+//	// "/users/42?tab=posts"
+func (engine *Engine) RouteURL(name string, params map[string]string) (string, error) {
+	for _, route := range engine.Routes() {
+		if route.Name == name {
+			return buildRouteURL(route.Path, params)
+		}
+	}
+	return "", fmt.Errorf("gin: no route named %q", name)
+}
+
+func buildRouteURL(route string, params map[string]string) (string, error) {
+	var b strings.Builder
+	query := make(url.Values, len(params))
+	for name, value := range params {
+		query.Set(name, value)
+	}
+	for rest := route; ; {
+		i := strings.IndexAny(rest, ":*")
+		if i < 0 {
+			b.WriteString(rest)
+			break
+		}
+		b.WriteString(rest[:i])
+		end := strings.IndexByte(rest[i:], '/')
+		if end < 0 {
+			end = len(rest)
+		} else {
+			end += i
+		}
+		name := rest[i+1 : end]
+		value, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("gin: missing parameter %q of route %q", name, route)
+		}
+		query.Del(name)
+		if rest[i] == '*' {
+			// the value of a catch-all parameter begins with a slash.
+			b.WriteString((&url.URL{Path: strings.TrimPrefix(value, "/")}).EscapedPath())
+		} else {
+			b.WriteString(url.PathEscape(value))
+		}
+		rest = rest[end:]
+	}
+	if len(query) > 0 {
+		b.WriteByte('?')
+		b.WriteString(query.Encode())
+	}
+	return b.String(), nil
+}
+
+// RedirectToRoute redirects to the route named name, see Engine.RouteURL.
+func (c *Context) RedirectToRoute(name string, params map[string]string, code int) error {
+	location, err := c.engine.RouteURL(name, params)
+	if err != nil {
+		return err
+	}
+	c.Redirect(code, location)
+	return nil
+}
+
+// RedirectBack redirects with 303 See Other to the page which sent the request,
+// given by the Referer header, e.g. after the submission of a form. fallback is
+// used when the request has no Referer, or when it is on a host which is not
+// allowed, see SafeRedirect.
+func (c *Context) RedirectBack(fallback string) {
+	location := c.requestHeader("Referer")
+	if location == "" || !c.redirectAllowed(location) {
+		location = fallback
+	}
+	c.Redirect(http.StatusSeeOther, location)
+}
+
+// RedirectTemporary redirects with 307 Temporary Redirect, which keeps the method
+// and the body of the request unlike 302 Found. See SafeRedirect.
+func (c *Context) RedirectTemporary(location string) error {
+	return c.SafeRedirect(http.StatusTemporaryRedirect, location)
+}
+
+// RedirectPermanent redirects with 308 Permanent Redirect, which keeps the method
+// and the body of the request unlike 301 Moved Permanently. See SafeRedirect.
+func (c *Context) RedirectPermanent(location string) error {
+	return c.SafeRedirect(http.StatusPermanentRedirect, location)
+}
+
+// SafeRedirect works like Redirect, but refuses to redirect to a location taken
+// from the request (e.g. a "next" query parameter) when it leads to another
+// host than the one of the request, unless that host is listed in
+// Engine.RedirectAllowedHosts. Relative locations are resolved against the path
+// of the request. ErrUnsafeRedirect is returned, and nothing is written, for a
+// location which is not allowed.
+func (c *Context) SafeRedirect(code int, location string) error {
+	if !c.redirectAllowed(location) {
+		return ErrUnsafeRedirect
+	}
+	c.Redirect(code, location)
+	return nil
+}
+
+// redirectAllowed reports whether location is relative, on the host of the
+// request, or the one forwarded by a trusted proxy (see Context.ForwardedHost)
+// unless all the proxies are trusted, or on one of Engine.RedirectAllowedHosts.
+func (c *Context) redirectAllowed(location string) bool {
+	// browsers read backslashes as slashes: "/\evil.com" is "//evil.com".
+	if strings.HasPrefix(location, "\\") || strings.HasPrefix(location, "/\\") {
+		return false
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return false
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	if u.Host == "" {
+		return u.Scheme == ""
+	}
+	if strings.EqualFold(u.Host, c.Request.Host) {
+		return true
+	}
+	// the forwarded host could be set by any client when all proxies are trusted.
+	if !c.engine.isUnsafeTrustedProxies() && strings.EqualFold(u.Host, c.ForwardedHost()) {
+		return true
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range c.engine.RedirectAllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:]) {
+			return true
+		}
+	}
+	return false
+}
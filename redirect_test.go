@@ -0,0 +1,115 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteURL(t *testing.T) {
+	router := New()
+	router.GET("/users/:id/posts/:post", func(c *Context) {}).(RouteNamer).Name("posts.show")
+	router.GET("/files/*path", func(c *Context) {}).(RouteNamer).Name("files")
+	router.GET("/home", func(c *Context) {}).(RouteNamer).Name("home")
+
+	tests := []struct {
+		name   string
+		params map[string]string
+		url    string
+	}{
+		{"posts.show", map[string]string{"id": "42", "post": "a b"}, "/users/42/posts/a%20b"},
+		{"posts.show", map[string]string{"id": "1", "post": "2", "tab": "x&y"}, "/users/1/posts/2?tab=x%26y"},
+		{"files", map[string]string{"path": "/css/site.css"}, "/files/css/site.css"},
+		{"home", nil, "/home"},
+	}
+	for _, tt := range tests {
+		u, err := router.RouteURL(tt.name, tt.params)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.url, u)
+	}
+
+	_, err := router.RouteURL("posts.show", map[string]string{"id": "1"})
+	assert.EqualError(t, err, `gin: missing parameter "post" of route "/users/:id/posts/:post"`)
+	_, err = router.RouteURL("unknown", nil)
+	assert.EqualError(t, err, `gin: no route named "unknown"`)
+}
+
+func TestRedirectHelpers(t *testing.T) {
+	router := New()
+	router.RedirectAllowedHosts = []string{"accounts.example.com", "*.cdn.example.com"}
+	router.GET("/users/:id", func(c *Context) {}).(RouteNamer).Name("users.show")
+	router.POST("/to-route", func(c *Context) {
+		assert.NoError(t, c.RedirectToRoute("users.show", map[string]string{"id": "7"}, http.StatusSeeOther))
+	})
+	router.POST("/back", func(c *Context) { c.RedirectBack("/home") })
+	router.POST("/next", func(c *Context) {
+		if err := c.RedirectTemporary(c.Query("next")); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+		}
+	})
+	router.GET("/moved", func(c *Context) { assert.NoError(t, c.RedirectPermanent("/new")) })
+
+	w := PerformRequest(router, http.MethodPost, "/to-route")
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/users/7", w.Header().Get("Location"))
+
+	w = PerformRequest(router, http.MethodGet, "/moved")
+	assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+	assert.Equal(t, "/new", w.Header().Get("Location"))
+
+	backs := map[string]string{
+		"":                              "/home",
+		"/form?x=1":                     "/form?x=1",
+		"http://example.com/form":       "http://example.com/form",
+		"https://evil.com/":             "/home",
+		"https://accounts.example.com/": "https://accounts.example.com/",
+	}
+	for referer, location := range backs {
+		w = PerformRequest(router, http.MethodPost, "/back", header{"Referer", referer})
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, location, w.Header().Get("Location"), referer)
+	}
+
+	nexts := map[string]int{
+		"/dashboard":                         http.StatusTemporaryRedirect,
+		"https://img.cdn.example.com/a":      http.StatusTemporaryRedirect,
+		"https://cdn.example.com.evil/a":     http.StatusBadRequest,
+		"//evil.com/":                        http.StatusBadRequest,
+		"/\\evil.com":                        http.StatusBadRequest,
+		"javascript:alert(1)":                http.StatusBadRequest,
+		"https://accounts.example.com:8443/": http.StatusTemporaryRedirect,
+	}
+	for next, code := range nexts {
+		w = PerformRequest(router, http.MethodPost, "/next?next="+url.QueryEscape(next))
+		assert.Equal(t, code, w.Code, next)
+	}
+}
+
+func TestRedirectForwardedHost(t *testing.T) {
+	router := New()
+	router.POST("/back", func(c *Context) { c.RedirectBack("/home") })
+	referer := header{"Referer", "https://shop.example/cart"}
+
+	w := PerformRequest(router, http.MethodPost, "/back", referer)
+	assert.Equal(t, "/home", w.Header().Get("Location"))
+
+	// all the proxies are trusted by default.
+	forwarded := header{"Forwarded", "for=203.0.113.7;host=shop.example"}
+	w = PerformRequest(router, http.MethodPost, "/back", referer, forwarded)
+	assert.Equal(t, "/home", w.Header().Get("Location"))
+
+	require.NoError(t, router.SetTrustedProxies([]string{"192.0.2.1"}))
+	w = PerformRequest(router, http.MethodPost, "/back", referer, forwarded)
+	assert.Equal(t, "https://shop.example/cart", w.Header().Get("Location"))
+
+	require.NoError(t, router.SetTrustedProxies(nil))
+	w = PerformRequest(router, http.MethodPost, "/back", referer, header{"X-Forwarded-Host", "shop.example"})
+	assert.Equal(t, "/home", w.Header().Get("Location"))
+}
@@ -6,6 +6,7 @@ package render
 
 import (
 	"html/template"
+	"io/fs"
 	"net/http"
 )
 
@@ -17,12 +18,21 @@ type Delims struct {
 	Right string
 }
 
-// HTMLRender interface is to be implemented by HTMLProduction and HTMLDebug.
+// HTMLRender interface is to be implemented by HTMLProduction and HTMLDebug,
+// as well as by any third-party template engine.
 type HTMLRender interface {
 	// Instance returns an HTML instance.
 	Instance(string, any) Render
 }
 
+// HTMLRenderFunc is an adapter to allow the use of an ordinary function as HTMLRender.
+type HTMLRenderFunc func(name string, data any) Render
+
+// Instance (HTMLRenderFunc) calls f(name, data).
+func (f HTMLRenderFunc) Instance(name string, data any) Render {
+	return f(name, data)
+}
+
 // HTMLProduction contains template reference and its delims.
 type HTMLProduction struct {
 	Template *template.Template
@@ -31,10 +41,15 @@ type HTMLProduction struct {
 
 // HTMLDebug contains template delims and pattern and function with file list.
 type HTMLDebug struct {
-	Files   []string
-	Glob    string
-	Delims  Delims
-	FuncMap template.FuncMap
+	Files      []string
+	Glob       string
+	FileSystem fs.FS
+	Patterns   []string
+	Delims     Delims
+	FuncMap    template.FuncMap
+	// Cache, if set, keeps the parsed templates until one of their files
+	// changes. Without it the templates are parsed for every render.
+	Cache *TemplateCache
 }
 
 // HTML contains template reference and its name with given interface object.
@@ -64,6 +79,14 @@ func (r HTMLDebug) Instance(name string, data any) Render {
 	}
 }
 func (r HTMLDebug) loadTemplate() *template.Template {
+	if r.Cache != nil {
+		modTimes, err := r.modificationTimes()
+		return r.Cache.get(modTimes, err, r.parseTemplate)
+	}
+	return r.parseTemplate()
+}
+
+func (r HTMLDebug) parseTemplate() *template.Template {
 	if r.FuncMap == nil {
 		r.FuncMap = template.FuncMap{}
 	}
@@ -73,6 +96,9 @@ func (r HTMLDebug) loadTemplate() *template.Template {
 	if r.Glob != "" {
 		return template.Must(template.New("").Delims(r.Delims.Left, r.Delims.Right).Funcs(r.FuncMap).ParseGlob(r.Glob))
 	}
+	if r.FileSystem != nil && len(r.Patterns) > 0 {
+		return template.Must(template.New("").Delims(r.Delims.Left, r.Delims.Right).Funcs(r.FuncMap).ParseFS(r.FileSystem, r.Patterns...))
+	}
 	panic("the HTML debug render was created without files or glob pattern")
 }
 
@@ -0,0 +1,120 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package render
+
+import (
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TemplateCacheStats reports how often the templates of a debug renderer were
+// parsed again.
+type TemplateCacheStats struct {
+	// Hits is the number of renders which reused the parsed templates.
+	Hits uint64 `json:"hits"`
+	// Reloads is the number of times the templates were parsed.
+	Reloads uint64 `json:"reloads"`
+	// Files is the number of template files watched.
+	Files int `json:"files"`
+}
+
+// TemplateCache keeps the templates parsed by HTMLDebug until one of their
+// files is added, removed or modified, instead of parsing them for every render.
+// The zero value is ready to use.
+type TemplateCache struct {
+	mu       sync.RWMutex
+	template *template.Template
+	modTimes map[string]time.Time
+
+	hits    atomic.Uint64
+	reloads atomic.Uint64
+}
+
+// Stats returns the statistics of the cache.
+func (tc *TemplateCache) Stats() TemplateCacheStats {
+	tc.mu.RLock()
+	files := len(tc.modTimes)
+	tc.mu.RUnlock()
+	return TemplateCacheStats{Hits: tc.hits.Load(), Reloads: tc.reloads.Load(), Files: files}
+}
+
+// get returns the cached template, calling parse when the files have changed.
+// The template is not cached when the files cannot be listed.
+func (tc *TemplateCache) get(modTimes map[string]time.Time, err error, parse func() *template.Template) *template.Template {
+	if err == nil {
+		tc.mu.RLock()
+		tmpl := tc.template
+		unchanged := tmpl != nil && sameModTimes(tc.modTimes, modTimes)
+		tc.mu.RUnlock()
+		if unchanged {
+			tc.hits.Add(1)
+			return tmpl
+		}
+	}
+
+	tmpl := parse()
+	tc.reloads.Add(1)
+	if err == nil {
+		tc.mu.Lock()
+		tc.template = tmpl
+		tc.modTimes = modTimes
+		tc.mu.Unlock()
+	}
+	return tmpl
+}
+
+func sameModTimes(old, cur map[string]time.Time) bool {
+	if len(old) != len(cur) {
+		return false
+	}
+	for file, modTime := range cur {
+		if t, ok := old[file]; !ok || !t.Equal(modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// modificationTimes returns the modification times of the template files of r.
+func (r HTMLDebug) modificationTimes() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time)
+	if r.FileSystem != nil && len(r.Files) == 0 && r.Glob == "" {
+		for _, pattern := range r.Patterns {
+			files, err := fs.Glob(r.FileSystem, pattern)
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range files {
+				info, err := fs.Stat(r.FileSystem, file)
+				if err != nil {
+					return nil, err
+				}
+				modTimes[file] = info.ModTime()
+			}
+		}
+		return modTimes, nil
+	}
+
+	files := r.Files
+	if len(files) == 0 {
+		var err error
+		if files, err = filepath.Glob(r.Glob); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[file] = info.ModTime()
+	}
+	return modTimes, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package render
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func renderDebug(t *testing.T, r HTMLDebug) string {
+	w := httptest.NewRecorder()
+	require.NoError(t, r.Instance("page.tmpl", nil).Render(w))
+	return w.Body.String()
+}
+
+func TestTemplateCacheReloadsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.tmpl")
+	require.NoError(t, os.WriteFile(page, []byte("v1"), 0o600))
+
+	cache := new(TemplateCache)
+	r := HTMLDebug{Glob: filepath.Join(dir, "*.tmpl"), Cache: cache}
+	assert.Equal(t, "v1", renderDebug(t, r))
+	assert.Equal(t, "v1", renderDebug(t, r))
+	assert.Equal(t, TemplateCacheStats{Hits: 1, Reloads: 1, Files: 1}, cache.Stats())
+
+	require.NoError(t, os.WriteFile(page, []byte("v2"), 0o600))
+	later := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(page, later, later))
+	assert.Equal(t, "v2", renderDebug(t, r))
+	assert.Equal(t, TemplateCacheStats{Hits: 1, Reloads: 2, Files: 1}, cache.Stats())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.tmpl"), []byte("other"), 0o600))
+	assert.Equal(t, "v2", renderDebug(t, r))
+	assert.Equal(t, TemplateCacheStats{Hits: 1, Reloads: 3, Files: 2}, cache.Stats())
+}
+
+func TestTemplateCacheFS(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "page.tmpl"), []byte("fs"), 0o600))
+
+	cache := new(TemplateCache)
+	r := HTMLDebug{FileSystem: os.DirFS(dir), Patterns: []string{"*.tmpl"}, Cache: cache}
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "fs", renderDebug(t, r))
+	}
+	assert.Equal(t, TemplateCacheStats{Hits: 2, Reloads: 1, Files: 1}, cache.Stats())
+}
+
+func TestTemplateCacheMissingFile(t *testing.T) {
+	cache := new(TemplateCache)
+	r := HTMLDebug{Files: []string{filepath.Join(t.TempDir(), "missing.tmpl")}, Cache: cache}
+	assert.Panics(t, func() { r.Instance("missing.tmpl", nil) })
+	assert.Equal(t, TemplateCacheStats{}, cache.Stats())
+}
@@ -0,0 +1,179 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HTMLLayout composes every page template with a shared set of layout templates.
+// Layouts declare overridable sections with {{block "name" .}}, pages redefine
+// them with {{define "name"}}. Rendering a page executes the first layout file
+// matched by LayoutGlob with the page's blocks.
+//
+// When Reload is true the templates are re-parsed as soon as a matched file is
+// added, removed or modified, which is meant for development only.
+type HTMLLayout struct {
+	LayoutGlob string
+	PageGlob   string
+	Delims     Delims
+	FuncMap    template.FuncMap
+	Reload     bool
+
+	mu        sync.RWMutex
+	root      string
+	templates map[string]*template.Template
+	modTimes  map[string]time.Time
+
+	hits    atomic.Uint64
+	reloads atomic.Uint64
+}
+
+// NewHTMLLayout returns an HTMLLayout whose templates have already been parsed.
+func NewHTMLLayout(layoutGlob, pageGlob string, delims Delims, funcMap template.FuncMap, reload bool) (*HTMLLayout, error) {
+	r := &HTMLLayout{
+		LayoutGlob: layoutGlob,
+		PageGlob:   pageGlob,
+		Delims:     delims,
+		FuncMap:    funcMap,
+		Reload:     reload,
+	}
+	if err := r.Load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Load (re-)parses all layouts and pages.
+func (r *HTMLLayout) Load() error {
+	layouts, err := filepath.Glob(r.LayoutGlob)
+	if err != nil {
+		return err
+	}
+	if len(layouts) == 0 {
+		return fmt.Errorf("html/template: pattern matches no files: %#q", r.LayoutGlob)
+	}
+	pages, err := filepath.Glob(r.PageGlob)
+	if err != nil {
+		return err
+	}
+
+	funcMap := r.FuncMap
+	if funcMap == nil {
+		funcMap = template.FuncMap{}
+	}
+	base, err := template.New(filepath.Base(layouts[0])).
+		Delims(r.Delims.Left, r.Delims.Right).Funcs(funcMap).ParseFiles(layouts...)
+	if err != nil {
+		return err
+	}
+
+	templates := make(map[string]*template.Template, len(pages))
+	for _, page := range pages {
+		tmpl, err := base.Clone()
+		if err != nil {
+			return err
+		}
+		if tmpl, err = tmpl.ParseFiles(page); err != nil {
+			return err
+		}
+		templates[filepath.Base(page)] = tmpl
+	}
+
+	modTimes, err := r.modificationTimes()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.root = base.Name()
+	r.templates = templates
+	r.modTimes = modTimes
+	r.mu.Unlock()
+	r.reloads.Add(1)
+	return nil
+}
+
+// Stats returns how often the templates were reused and parsed.
+func (r *HTMLLayout) Stats() TemplateCacheStats {
+	r.mu.RLock()
+	files := len(r.modTimes)
+	r.mu.RUnlock()
+	return TemplateCacheStats{Hits: r.hits.Load(), Reloads: r.reloads.Load(), Files: files}
+}
+
+// Pages returns the names of the loaded pages.
+func (r *HTMLLayout) Pages() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.templates))
+	for name := range r.templates {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Instance (HTMLLayout) returns an HTML instance which it realizes Render interface.
+// The name is the base name of a page file.
+func (r *HTMLLayout) Instance(name string, data any) Render {
+	if r.Reload {
+		if !r.changed() {
+			r.hits.Add(1)
+		} else if err := r.Load(); err != nil {
+			panic(err)
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tmpl, ok := r.templates[name]
+	if !ok {
+		return HTML{Template: template.New(""), Name: name, Data: data}
+	}
+	return HTML{Template: tmpl, Name: r.root, Data: data}
+}
+
+func (r *HTMLLayout) modificationTimes() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time)
+	for _, pattern := range []string{r.LayoutGlob, r.PageGlob} {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			info, err := os.Stat(file)
+			if err != nil {
+				return nil, err
+			}
+			modTimes[file] = info.ModTime()
+		}
+	}
+	return modTimes, nil
+}
+
+func (r *HTMLLayout) changed() bool {
+	modTimes, err := r.modificationTimes()
+	if err != nil {
+		return true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(modTimes) != len(r.modTimes) {
+		return true
+	}
+	for file, modTime := range modTimes {
+		if old, ok := r.modTimes[file]; !ok || !old.Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
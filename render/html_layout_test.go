@@ -0,0 +1,69 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package render
+
+import (
+	"html/template"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderHTMLLayout(t *testing.T) {
+	funcMap := template.FuncMap{"upper": strings.ToUpper}
+	htmlRender, err := NewHTMLLayout("../testdata/layout/layouts/*", "../testdata/layout/pages/*", Delims{"{{", "}}"}, funcMap, false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"index.tmpl", "about.tmpl"}, htmlRender.Pages())
+
+	w := httptest.NewRecorder()
+	err = htmlRender.Instance("index.tmpl", map[string]any{"name": "gin"}).Render(w)
+	assert.NoError(t, err)
+	assert.Equal(t, "<html><title>Index</title><body><h1>Hello GIN</h1></body></html>", w.Body.String())
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+
+	w = httptest.NewRecorder()
+	err = htmlRender.Instance("about.tmpl", nil).Render(w)
+	assert.NoError(t, err)
+	assert.Equal(t, "<html><title>Default</title><body>About</body></html>", w.Body.String())
+
+	err = htmlRender.Instance("missing.tmpl", nil).Render(httptest.NewRecorder())
+	assert.Error(t, err)
+}
+
+func TestRenderHTMLLayoutErrors(t *testing.T) {
+	_, err := NewHTMLLayout("../testdata/layout/nothing/*", "../testdata/layout/pages/*", Delims{"{{", "}}"}, nil, false)
+	assert.Error(t, err)
+
+	_, err = NewHTMLLayout("[", "../testdata/layout/pages/*", Delims{"{{", "}}"}, nil, false)
+	assert.Error(t, err)
+}
+
+func TestRenderHTMLLayoutReload(t *testing.T) {
+	dir := t.TempDir()
+	layout := filepath.Join(dir, "layout.tmpl")
+	page := filepath.Join(dir, "page.html")
+	require.NoError(t, os.WriteFile(layout, []byte(`[{{block "content" .}}{{end}}]`), 0o600))
+	require.NoError(t, os.WriteFile(page, []byte(`{{define "content"}}v1{{end}}`), 0o600))
+
+	htmlRender, err := NewHTMLLayout(filepath.Join(dir, "*.tmpl"), filepath.Join(dir, "*.html"), Delims{"{{", "}}"}, nil, true)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	require.NoError(t, htmlRender.Instance("page.html", nil).Render(w))
+	assert.Equal(t, "[v1]", w.Body.String())
+
+	require.NoError(t, os.WriteFile(page, []byte(`{{define "content"}}v2{{end}}`), 0o600))
+	require.NoError(t, os.Chtimes(page, time.Now().Add(time.Hour), time.Now().Add(time.Hour)))
+
+	w = httptest.NewRecorder()
+	require.NoError(t, htmlRender.Instance("page.html", nil).Render(w))
+	assert.Equal(t, "[v2]", w.Body.String())
+}
@@ -10,6 +10,7 @@ import (
 	"html/template"
 	"net/http"
 
+	"github.com/gin-gonic/gin/internal/bufpool"
 	"github.com/gin-gonic/gin/internal/bytesconv"
 	"github.com/gin-gonic/gin/internal/json"
 )
@@ -65,11 +66,13 @@ func (r JSON) WriteContentType(w http.ResponseWriter) {
 // WriteJSON marshals the given interface object and writes it with custom ContentType.
 func WriteJSON(w http.ResponseWriter, obj any) error {
 	writeContentType(w, jsonContentType)
-	jsonBytes, err := json.Marshal(obj)
-	if err != nil {
+	buf := bufpool.Get(0)
+	defer bufpool.Put(buf)
+	if err := json.NewEncoder(buf).Encode(obj); err != nil {
 		return err
 	}
-	_, err = w.Write(jsonBytes)
+	// Encode terminates the value with a newline, which Marshal does not.
+	_, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}))
 	return err
 }
 
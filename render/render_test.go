@@ -11,6 +11,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strconv"
 	"strings"
 	"testing"
@@ -523,6 +524,25 @@ func TestRenderHTMLDebugGlob(t *testing.T) {
 	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
 }
 
+func TestRenderHTMLDebugFS(t *testing.T) {
+	w := httptest.NewRecorder()
+	htmlRender := HTMLDebug{
+		FileSystem: os.DirFS("../testdata/template"),
+		Patterns:   []string{"hello.tmpl"},
+		Delims:     Delims{"{[{", "}]}"},
+		FuncMap:    nil,
+	}
+	instance := htmlRender.Instance("hello.tmpl", map[string]any{
+		"name": "thinkerou",
+	})
+
+	err := instance.Render(w)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "<h1>Hello thinkerou</h1>", w.Body.String())
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+}
+
 func TestRenderHTMLDebugPanics(t *testing.T) {
 	htmlRender := HTMLDebug{
 		Files:   nil,
@@ -533,6 +553,20 @@ func TestRenderHTMLDebugPanics(t *testing.T) {
 	assert.Panics(t, func() { htmlRender.Instance("", nil) })
 }
 
+func TestRenderHTMLRenderFunc(t *testing.T) {
+	w := httptest.NewRecorder()
+	htmlRender := HTMLRenderFunc(func(name string, data any) Render {
+		return Data{ContentType: "text/html; charset=utf-8", Data: []byte(name + ":" + data.(string))}
+	})
+	instance := htmlRender.Instance("index", "gin")
+
+	err := instance.Render(w)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "index:gin", w.Body.String())
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+}
+
 func TestRenderReader(t *testing.T) {
 	w := httptest.NewRecorder()
 
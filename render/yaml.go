@@ -7,6 +7,7 @@ package render
 import (
 	"net/http"
 
+	"github.com/gin-gonic/gin/internal/bufpool"
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,12 +22,17 @@ var yamlContentType = []string{"application/x-yaml; charset=utf-8"}
 func (r YAML) Render(w http.ResponseWriter) error {
 	r.WriteContentType(w)
 
-	bytes, err := yaml.Marshal(r.Data)
-	if err != nil {
+	buf := bufpool.Get(0)
+	defer bufpool.Put(buf)
+	enc := yaml.NewEncoder(buf)
+	if err := enc.Encode(r.Data); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
 		return err
 	}
 
-	_, err = w.Write(bytes)
+	_, err := w.Write(buf.Bytes())
 	return err
 }
 
@@ -0,0 +1,226 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// BufferedResponseWriter is the ResponseWriter installed by BufferedResponse.
+// It keeps the response in memory until the handler chain completes.
+type BufferedResponseWriter interface {
+	ResponseWriter
+
+	// Body returns the buffered body, or nil once the response has been spilled.
+	Body() []byte
+
+	// Reset discards the buffered body, e.g. to replace it with an error page.
+	// It reports false once the response has been spilled.
+	Reset() bool
+
+	// Spilled returns true if the response went over the size limit, or was
+	// flushed or hijacked, and is being streamed to the client.
+	Spilled() bool
+}
+
+// BufferedResponse returns a middleware which buffers the response written by
+// the rest of the chain, and sends it once the chain completes. Until then,
+// the status and headers can still be modified, e.g. by middleware setting
+// cookies after the handler wrote its JSON. A response larger than maxSize
+// bytes (when positive), or explicitly flushed, is spilled: what was buffered
+// is sent and the rest of the response is streamed as usual. When the chain
+// panics, the buffered response is discarded so that Recovery can answer.
+func BufferedResponse(maxSize int) HandlerFunc {
+	return func(c *Context) {
+		w := &bufferedWriter{
+			ResponseWriter: c.Writer,
+			status:         c.Writer.Status(),
+			size:           noWritten,
+			maxSize:        maxSize,
+		}
+		c.Writer = w
+		defer func() { c.Writer = w.ResponseWriter }()
+
+		c.Next()
+		w.flush()
+	}
+}
+
+type bufferedWriter struct {
+	ResponseWriter
+	buf     bytes.Buffer
+	status  int
+	size    int
+	maxSize int
+	spilled bool
+}
+
+var (
+	_ BufferedResponseWriter = (*bufferedWriter)(nil)
+	_ ResponseTrailer        = (*bufferedWriter)(nil)
+)
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if w.spilled {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if code > 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	if w.spilled {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	if w.size == noWritten {
+		w.size = 0
+	}
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	if !w.spilled && w.maxSize > 0 && w.buf.Len()+len(data) > w.maxSize {
+		w.spill()
+	}
+	if w.spilled {
+		return w.ResponseWriter.Write(data)
+	}
+	w.WriteHeaderNow()
+	n, err := w.buf.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	if !w.spilled && w.maxSize > 0 && w.buf.Len()+len(s) > w.maxSize {
+		w.spill()
+	}
+	if w.spilled {
+		return w.ResponseWriter.WriteString(s)
+	}
+	w.WriteHeaderNow()
+	n, err := io.WriteString(&w.buf, s)
+	w.size += n
+	return n, err
+}
+
+func (w *bufferedWriter) Status() int {
+	if w.spilled {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if w.spilled {
+		return w.ResponseWriter.Size()
+	}
+	return w.size
+}
+
+func (w *bufferedWriter) Written() bool {
+	if w.spilled {
+		return w.ResponseWriter.Written()
+	}
+	return w.size != noWritten
+}
+
+// Flush spills the response: streaming handlers (SSE...) are not buffered.
+func (w *bufferedWriter) Flush() {
+	w.spill()
+	w.ResponseWriter.Flush()
+}
+
+// Hijack implements the http.Hijacker interface.
+func (w *bufferedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.buf.Reset()
+	w.spilled = true
+	return w.ResponseWriter.Hijack()
+}
+
+// AddTrailer implements the ResponseTrailer interface: the headers of the
+// buffered response are only written when it is sent.
+func (w *bufferedWriter) AddTrailer(name string) {
+	if t, ok := w.ResponseWriter.(ResponseTrailer); ok {
+		t.AddTrailer(name)
+	}
+}
+
+func (w *bufferedWriter) Body() []byte {
+	if w.spilled {
+		return nil
+	}
+	return w.buf.Bytes()
+}
+
+func (w *bufferedWriter) Reset() bool {
+	if w.spilled {
+		return false
+	}
+	w.buf.Reset()
+	w.size = noWritten
+	return true
+}
+
+func (w *bufferedWriter) Spilled() bool {
+	return w.spilled
+}
+
+// spill sends the status, headers and buffered body, and switches to streaming.
+func (w *bufferedWriter) spill() {
+	if w.spilled {
+		return
+	}
+	w.spilled = true
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.size == noWritten {
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+	if w.buf.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+}
+
+// flush sends the buffered response once the chain completes. Nothing is sent
+// when nothing was written, so that the engine writes the final status.
+func (w *bufferedWriter) flush() {
+	if w.spilled {
+		return
+	}
+	if w.size == noWritten {
+		w.ResponseWriter.WriteHeader(w.status)
+		w.spilled = true
+		return
+	}
+	// a Content-Length prevents the trailers of HTTP/1.1 responses
+	if w.Header().Get("Content-Length") == "" && !hasTrailers(w.Header()) {
+		w.Header().Set("Content-Length", strconv.Itoa(w.buf.Len()))
+	}
+	w.spill()
+}
+
+// hasTrailers reports whether header declares trailers, or holds undeclared
+// trailers with the http.TrailerPrefix.
+func hasTrailers(header http.Header) bool {
+	if len(header["Trailer"]) > 0 {
+		return true
+	}
+	for key := range header {
+		if strings.HasPrefix(key, http.TrailerPrefix) {
+			return true
+		}
+	}
+	return false
+}
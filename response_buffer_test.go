@@ -0,0 +1,97 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferedResponseLateMutation(t *testing.T) {
+	router := New()
+	router.Use(BufferedResponse(0), func(c *Context) {
+		c.Next()
+		c.SetCookie("session", "abc", 0, "/", "", false, true)
+		if c.Writer.Status() == http.StatusOK {
+			c.Status(http.StatusCreated)
+		}
+	})
+	router.POST("/users", func(c *Context) {
+		c.JSON(http.StatusOK, H{"id": 1})
+	})
+
+	w := PerformRequest(router, http.MethodPost, "/users")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"id":1}`, w.Body.String())
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "session=abc")
+	assert.Equal(t, "8", w.Header().Get("Content-Length"))
+}
+
+func TestBufferedResponseReset(t *testing.T) {
+	router := New()
+	router.Use(BufferedResponse(0), func(c *Context) {
+		c.Next()
+		if len(c.Errors) > 0 {
+			assert.True(t, c.Writer.(BufferedResponseWriter).Reset())
+			c.JSON(http.StatusInternalServerError, H{"error": c.Errors.Last().Error()})
+		}
+	})
+	router.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "partial")
+		_ = c.Error(errors.New("boom"))
+	})
+	router.GET("/empty", func(c *Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, `{"error":"boom"}`, w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/empty")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestBufferedResponseSpill(t *testing.T) {
+	router := New()
+	router.Use(BufferedResponse(10), func(c *Context) {
+		c.Next()
+		bw := c.Writer.(BufferedResponseWriter)
+		assert.True(t, bw.Spilled())
+		assert.Nil(t, bw.Body())
+		assert.False(t, bw.Reset())
+		c.Header("X-Late", "ignored")
+	})
+	router.GET("/", func(c *Context) {
+		c.Header("X-Early", "yes")
+		c.Status(http.StatusAccepted)
+		_, _ = c.Writer.WriteString(strings.Repeat("a", 6))
+		_, _ = c.Writer.Write([]byte(strings.Repeat("b", 6)))
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "aaaaaabbbbbb", w.Body.String())
+	assert.Equal(t, "yes", w.Header().Get("X-Early"))
+	assert.Empty(t, w.Header().Get("Content-Length"))
+}
+
+func TestBufferedResponsePanic(t *testing.T) {
+	router := New()
+	router.Use(Recovery(), BufferedResponse(0))
+	router.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "partial")
+		panic("oops")
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Body.String())
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"strings"
 )
 
 const (
@@ -43,13 +44,80 @@ type ResponseWriter interface {
 	Pusher() http.Pusher
 }
 
+// ResponseCapabilities is implemented by the ResponseWriter of gin, and can be
+// checked for with a type assertion since the writer may be wrapped by middleware:
+//
+//	if rc, ok := c.Writer.(gin.ResponseCapabilities); ok && !rc.CanHijack() {
+//		c.AbortWithStatus(http.StatusNotImplemented)
+//	}
+type ResponseCapabilities interface {
+	// CanHijack returns true if the underlying http.ResponseWriter supports
+	// Hijack, which panics otherwise. HTTP/2 connections can't be hijacked.
+	CanHijack() bool
+
+	// CanFlush returns true if the underlying http.ResponseWriter supports Flush,
+	// which panics otherwise.
+	CanFlush() bool
+}
+
+// ResponseTee is implemented by the ResponseWriter of gin, and can be checked
+// for with a type assertion since the writer may be wrapped by middleware:
+//
+//	if tee, ok := c.Writer.(gin.ResponseTee); ok {
+//		tee.Tee(&audit, gin.TeeConfig{MaxSize: 64 << 10})
+//	}
+type ResponseTee interface {
+	// Tee copies the response body written from now on to w, e.g. for auditing,
+	// within the limits of config. Errors of w are ignored.
+	Tee(w io.Writer, config TeeConfig)
+}
+
+// ResponseTrailer is implemented by the ResponseWriter of gin, and can be
+// checked for with a type assertion since the writer may be wrapped by middleware.
+type ResponseTrailer interface {
+	// AddTrailer declares the trailer name, sent after the body, e.g. a checksum
+	// or a gRPC status. It must be called before the headers are written; the
+	// value is set with Header().Set(name, value) once the body is written.
+	// Undeclared trailers can be set with the http.TrailerPrefix.
+	AddTrailer(name string)
+}
+
+// TeeConfig defines the config of ResponseTee.Tee.
+type TeeConfig struct {
+	// MaxSize is the maximum number of bytes copied, the rest of the body is skipped.
+	// Optional. Default value is 0, no limit.
+	MaxSize int
+
+	// ContentTypes restricts the copy to the responses whose Content-Type starts
+	// with one of them, e.g. "application/json" or "text/".
+	// Optional. Default value is nil, every response is copied.
+	ContentTypes []string
+}
+
+type responseTee struct {
+	w       io.Writer
+	config  TeeConfig
+	copied  int
+	checked bool
+	skip    bool
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 	size   int
 	status int
+	tees   []responseTee
 }
 
-var _ ResponseWriter = (*responseWriter)(nil)
+var (
+	_ ResponseWriter       = (*responseWriter)(nil)
+	_ ResponseCapabilities = (*responseWriter)(nil)
+	_ ResponseTee          = (*responseWriter)(nil)
+	_ ResponseTrailer      = (*responseWriter)(nil)
+	// ReadFrom isn't part of ResponseWriter, so that it isn't promoted to the
+	// wrappers embedding a ResponseWriter, bypassing their Write method.
+	_ io.ReaderFrom = (*responseWriter)(nil)
+)
 
 func (w *responseWriter) Unwrap() http.ResponseWriter {
 	return w.ResponseWriter
@@ -59,12 +127,13 @@ func (w *responseWriter) reset(writer http.ResponseWriter) {
 	w.ResponseWriter = writer
 	w.size = noWritten
 	w.status = defaultStatus
+	w.tees = nil
 }
 
 func (w *responseWriter) WriteHeader(code int) {
 	if code > 0 && w.status != code {
 		if w.Written() {
-			debugPrint("[WARNING] Headers were already written. Wanted to override status code %d with %d", w.status, code)
+			debugPrintWarning("Headers were already written. Wanted to override status code %d with %d", w.status, code)
 			return
 		}
 		w.status = code
@@ -82,6 +151,9 @@ func (w *responseWriter) Write(data []byte) (n int, err error) {
 	w.WriteHeaderNow()
 	n, err = w.ResponseWriter.Write(data)
 	w.size += n
+	if len(w.tees) > 0 {
+		w.tee(data[:n])
+	}
 	return
 }
 
@@ -89,9 +161,81 @@ func (w *responseWriter) WriteString(s string) (n int, err error) {
 	w.WriteHeaderNow()
 	n, err = io.WriteString(w.ResponseWriter, s)
 	w.size += n
+	if len(w.tees) > 0 {
+		w.tee([]byte(s[:n]))
+	}
+	return
+}
+
+// ReadFrom implements the io.ReaderFrom interface, so that io.Copy, http.ServeFile
+// and Context.File use the ReadFrom of the underlying http.ResponseWriter,
+// which sends files with sendfile(2) when possible.
+func (w *responseWriter) ReadFrom(r io.Reader) (n int64, err error) {
+	w.WriteHeaderNow()
+	rf, ok := w.ResponseWriter.(io.ReaderFrom)
+	if !ok || len(w.tees) > 0 {
+		// Write copies to the tees and counts the size
+		return io.Copy(writerOnly{w}, r)
+	}
+	n, err = rf.ReadFrom(r)
+	w.size += int(n)
 	return
 }
 
+// writerOnly hides the io.ReaderFrom of a writer, so that io.Copy uses its Write method.
+type writerOnly struct {
+	io.Writer
+}
+
+func (w *responseWriter) AddTrailer(name string) {
+	if w.Written() {
+		debugPrintWarning("Headers were already written. Trailer %s must be set with the http.TrailerPrefix", name)
+		return
+	}
+	w.Header().Add("Trailer", http.CanonicalHeaderKey(name))
+}
+
+func (w *responseWriter) Tee(writer io.Writer, config TeeConfig) {
+	w.tees = append(w.tees, responseTee{w: writer, config: config})
+}
+
+func (w *responseWriter) tee(data []byte) {
+	for i := range w.tees {
+		t := &w.tees[i]
+		if !t.checked {
+			t.checked = true
+			t.skip = !matchContentType(w.Header().Get("Content-Type"), t.config.ContentTypes)
+		}
+		if t.skip {
+			continue
+		}
+		chunk := data
+		if t.config.MaxSize > 0 {
+			if t.copied >= t.config.MaxSize {
+				continue
+			}
+			if rest := t.config.MaxSize - t.copied; len(chunk) > rest {
+				chunk = chunk[:rest]
+			}
+		}
+		t.copied += len(chunk)
+		_, _ = t.w.Write(chunk)
+	}
+}
+
+func matchContentType(contentType string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(contentType, strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
 func (w *responseWriter) Status() int {
 	return w.status
 }
@@ -123,6 +267,16 @@ func (w *responseWriter) Flush() {
 	w.ResponseWriter.(http.Flusher).Flush()
 }
 
+func (w *responseWriter) CanHijack() bool {
+	_, ok := w.ResponseWriter.(http.Hijacker)
+	return ok
+}
+
+func (w *responseWriter) CanFlush() bool {
+	_, ok := w.ResponseWriter.(http.Flusher)
+	return ok
+}
+
 func (w *responseWriter) Pusher() (pusher http.Pusher) {
 	if pusher, ok := w.ResponseWriter.(http.Pusher); ok {
 		return pusher
@@ -5,11 +5,15 @@
 package gin
 
 import (
+	"bytes"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TODO
@@ -104,6 +108,33 @@ func TestResponseWriterWrite(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestResponseWriterTee(t *testing.T) {
+	testWriter := httptest.NewRecorder()
+	writer := &responseWriter{}
+	writer.reset(testWriter)
+	w := ResponseWriter(writer)
+
+	tee, ok := w.(ResponseTee)
+	assert.True(t, ok)
+
+	var all, capped, images bytes.Buffer
+	tee.Tee(&all, TeeConfig{})
+	tee.Tee(&capped, TeeConfig{MaxSize: 6, ContentTypes: []string{"application/json"}})
+	tee.Tee(&images, TeeConfig{ContentTypes: []string{"image/"}})
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = w.Write([]byte(`{"a":`))
+	_, _ = w.WriteString(`"hello"}`)
+	assert.Equal(t, `{"a":"hello"}`, testWriter.Body.String())
+	assert.Equal(t, `{"a":"hello"}`, all.String())
+	assert.Equal(t, `{"a":"`, capped.String())
+	assert.Empty(t, images.String())
+
+	writer.reset(httptest.NewRecorder())
+	_, _ = w.WriteString("next")
+	assert.Equal(t, `{"a":"hello"}`, all.String())
+}
+
 func TestResponseWriterHijack(t *testing.T) {
 	testWriter := httptest.NewRecorder()
 	writer := &responseWriter{}
@@ -186,3 +217,108 @@ func TestPusherWithoutPusher(t *testing.T) {
 	pusher := w.Pusher()
 	assert.Nil(t, pusher, "Expected pusher to be nil")
 }
+
+func TestResponseWriterAddTrailer(t *testing.T) {
+	router := New()
+	handler := func(c *Context) {
+		c.Writer.(ResponseTrailer).AddTrailer("x-checksum")
+		c.String(http.StatusOK, "body")
+		c.Writer.Header().Set("X-Checksum", "abc")
+		c.Writer.Header().Set(http.TrailerPrefix+"X-Late", "late")
+		c.Writer.(ResponseTrailer).AddTrailer("X-Ignored")
+	}
+	router.GET("/", handler)
+	router.GET("/buffered", BufferedResponse(0), handler)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	for _, path := range []string{"/", "/buffered"} {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, "body", string(body))
+		assert.Equal(t, "abc", resp.Trailer.Get("X-Checksum"), path)
+		assert.Equal(t, "late", resp.Trailer.Get("X-Late"), path)
+		assert.Empty(t, resp.Trailer.Get("X-Ignored"), path)
+	}
+}
+
+type readerFromRecorder struct {
+	*httptest.ResponseRecorder
+	readFrom bool
+}
+
+func (r *readerFromRecorder) ReadFrom(src io.Reader) (int64, error) {
+	r.readFrom = true
+	return io.Copy(r.ResponseRecorder, src)
+}
+
+func TestResponseWriterReadFrom(t *testing.T) {
+	rec := &readerFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+	writer := &responseWriter{}
+	writer.reset(rec)
+	writer.WriteHeader(http.StatusCreated)
+
+	n, err := io.Copy(writer, io.LimitReader(strings.NewReader("hello"), 10))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+	assert.True(t, rec.readFrom)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "hello", rec.Body.String())
+	assert.Equal(t, 5, writer.Size())
+
+	// the tees need the Write method
+	rec = &readerFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+	writer.reset(rec)
+	var tee bytes.Buffer
+	writer.Tee(&tee, TeeConfig{})
+	_, err = io.Copy(writer, io.LimitReader(strings.NewReader("hello"), 10))
+	assert.NoError(t, err)
+	assert.False(t, rec.readFrom)
+	assert.Equal(t, "hello", tee.String())
+	assert.Equal(t, 5, writer.Size())
+
+	// without io.ReaderFrom
+	plain := httptest.NewRecorder()
+	writer.reset(plain)
+	_, err = writer.ReadFrom(strings.NewReader("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", plain.Body.String())
+	assert.Equal(t, 5, writer.Size())
+}
+
+func TestResponseWriterReadFromNotPromoted(t *testing.T) {
+	router := New()
+	router.GET("/", BufferedResponse(0), func(c *Context) {
+		_, ok := c.Writer.(io.ReaderFrom)
+		assert.False(t, ok)
+		_, _ = io.Copy(c.Writer, io.LimitReader(strings.NewReader("buffered"), 10))
+		c.Header("X-After", "body")
+	})
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, "buffered", w.Body.String())
+	assert.Equal(t, "body", w.Header().Get("X-After"))
+}
+
+func TestResponseWriterCapabilities(t *testing.T) {
+	writer := &responseWriter{}
+	writer.reset(httptest.NewRecorder())
+	assert.False(t, writer.CanHijack())
+	assert.True(t, writer.CanFlush())
+
+	writer.reset(&nonPusherResponseWriter{})
+	assert.False(t, writer.CanFlush())
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writer := &responseWriter{}
+		writer.reset(w)
+		assert.True(t, writer.CanHijack())
+		assert.True(t, writer.CanFlush())
+	}))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
@@ -5,8 +5,10 @@
 package gin
 
 import (
+	"html/template"
 	"net/http"
 	"path"
+	"reflect"
 	"regexp"
 	"strings"
 )
@@ -50,6 +52,24 @@ type IRoutes interface {
 	StaticFS(string, http.FileSystem) IRoutes
 }
 
+// RouteSkipper is implemented by the IRoutes returned by Engine and RouterGroup,
+// to remove middleware from the routes just registered, see RouterGroup.Skip.
+type RouteSkipper interface {
+	Skip(...HandlerFunc) IRoutes
+}
+
+// RouteMetaSetter is implemented by the IRoutes returned by Engine and
+// RouterGroup, to set metadata on the routes just registered, see RouterGroup.Meta.
+type RouteMetaSetter interface {
+	Meta(string, any) IRoutes
+}
+
+// RouteNamer is implemented by the IRoutes returned by Engine and RouterGroup,
+// to name the routes just registered, see RouterGroup.Name.
+type RouteNamer interface {
+	Name(string) IRoutes
+}
+
 // RouterGroup is used internally to configure router, a RouterGroup is associated with
 // a prefix and an array of handlers (middleware).
 type RouterGroup struct {
@@ -57,9 +77,38 @@ type RouterGroup struct {
 	basePath string
 	engine   *Engine
 	root     bool
+	funcMap  template.FuncMap
+
+	// excluded maps the index of a middleware added with UseExcept to the
+	// absolute paths it must not be applied to.
+	excluded map[int]map[string]bool
+	// lastRoutes are the routes registered by the last call, see Skip.
+	lastRoutes []routeKey
+	// meta is the metadata of the routes registered on the group, see SetMeta.
+	meta map[string]any
+	// parent is the group this group was created from, and hasRoutes is set once a
+	// route is registered on the group or its sub-groups, see CheckRoutes.
+	parent    *RouterGroup
+	hasRoutes bool
+	// global is the number of engine middleware at the head of Handlers, see
+	// globalMiddleware.
+	global int
 }
 
-var _ IRouter = (*RouterGroup)(nil)
+type routeKey struct {
+	method string
+	path   string
+	// handlers is the number of handlers given for the route, which end its
+	// handlers chain after the group middleware, see Flatten.
+	handlers int
+}
+
+var (
+	_ IRouter         = (*RouterGroup)(nil)
+	_ RouteSkipper    = (*RouterGroup)(nil)
+	_ RouteMetaSetter = (*RouterGroup)(nil)
+	_ RouteNamer      = (*RouterGroup)(nil)
+)
 
 // Use adds middleware to the group, see example code in GitHub.
 func (group *RouterGroup) Use(middleware ...HandlerFunc) IRoutes {
@@ -67,14 +116,156 @@ func (group *RouterGroup) Use(middleware ...HandlerFunc) IRoutes {
 	return group.returnObj()
 }
 
+// UseExcept adds middleware to the group, except for the routes registered with the
+// given paths, relative to the group:
+//
+//	api.UseExcept(authRequired, "/health", "/login")
+func (group *RouterGroup) UseExcept(middleware HandlerFunc, relativePaths ...string) IRoutes {
+	paths := make(map[string]bool, len(relativePaths))
+	for _, p := range relativePaths {
+		paths[group.calculateAbsolutePath(p)] = true
+	}
+	excluded := make(map[int]map[string]bool, len(group.excluded)+1)
+	for i, p := range group.excluded {
+		excluded[i] = p
+	}
+	excluded[len(group.Handlers)] = paths
+	group.excluded = excluded
+	group.Handlers = append(group.Handlers, middleware)
+	return group.returnObj()
+}
+
+// Skip removes the given middleware from the handlers chain of the route(s) registered
+// by the last call on the group:
+//
+//	router.GET("/metrics", metricsHandler).(gin.RouteSkipper).Skip(logger, authRequired)
+//
+// Middleware are matched by function, so every instance of a middleware returned by
+// a constructor (e.g. gin.Logger()) is removed. Named middleware are matched by the
+// function given to NameHandler.
+func (group *RouterGroup) Skip(middleware ...HandlerFunc) IRoutes {
+	skipped := make(map[uintptr]bool, len(middleware))
+	for _, m := range middleware {
+		skipped[reflect.ValueOf(m).Pointer()] = true
+	}
+	for i := range group.lastRoutes {
+		r := &group.lastRoutes[i]
+		root := group.engine.trees.get(r.method)
+		if root == nil {
+			continue
+		}
+		n := root.findRoute(r.path)
+		if n == nil {
+			continue
+		}
+		last := len(n.handlers) - 1
+		global := n.global
+		groupSize := len(n.handlers) - r.handlers
+		handlers := make(HandlersChain, 0, len(n.handlers))
+		for j, h := range n.handlers[:last] {
+			switch {
+			case !skipped[reflect.ValueOf(unwrapHandler(h)).Pointer()]:
+				handlers = append(handlers, h)
+			case j >= groupSize:
+				r.handlers--
+			case j < global:
+				n.global--
+			}
+		}
+		n.handlers = append(handlers, n.handlers[last])
+	}
+	return group.returnObj()
+}
+
+// Meta sets a metadata entry on the route(s) registered by the last call on the
+// group, which can be read by middleware and handlers with Context.RouteMeta:
+//
+//	router.DELETE("/users/:id", deleteUser).(gin.RouteMetaSetter).Meta("perm", "users.delete")
+func (group *RouterGroup) Meta(key string, value any) IRoutes {
+	for _, r := range group.lastRoutes {
+		if root := group.engine.trees.get(r.method); root != nil {
+			root.addMeta(r.path, map[string]any{key: value})
+		}
+	}
+	return group.returnObj()
+}
+
+// Name names the route(s) registered by the last call on the group, see Context.RouteName:
+//
+//	router.GET("/users/:id", getUser).(gin.RouteNamer).Name("users.show")
+func (group *RouterGroup) Name(name string) IRoutes {
+	for _, r := range group.lastRoutes {
+		if root := group.engine.trees.get(r.method); root != nil {
+			if n := root.findRoute(r.path); n != nil {
+				n.name = name
+			}
+		}
+	}
+	return group.returnObj()
+}
+
+// SetMeta sets a metadata entry on the routes registered afterwards on the group
+// and its sub-groups, which can be overridden per route with Meta:
+//
+//	admin := router.Group("/admin")
+//	admin.SetMeta("perm", "admin")
+//	admin.SetMeta("ratelimit", "strict")
+func (group *RouterGroup) SetMeta(key string, value any) {
+	meta := make(map[string]any, len(group.meta)+1)
+	for k, v := range group.meta {
+		meta[k] = v
+	}
+	meta[key] = value
+	group.meta = meta
+}
+
 // Group creates a new router group. You should add all the routes that have common middlewares or the same path prefix.
 // For example, all the routes that use a common middleware for authorization could be grouped.
 func (group *RouterGroup) Group(relativePath string, handlers ...HandlerFunc) *RouterGroup {
-	return &RouterGroup{
+	g := &RouterGroup{
 		Handlers: group.combineHandlers(handlers),
 		basePath: group.calculateAbsolutePath(relativePath),
 		engine:   group.engine,
+		funcMap:  group.funcMap,
+		excluded: group.excluded,
+		meta:     group.meta,
+		parent:   group,
+		global:   group.globalMiddleware(),
 	}
+	group.engine.groups = append(group.engine.groups, g)
+	return g
+}
+
+// SetFuncMap sets template functions used by the templates loaded with the group's
+// LoadHTMLGlobWithLayouts. They are merged on top of Engine.FuncMap and inherited by sub-groups.
+func (group *RouterGroup) SetFuncMap(funcMap template.FuncMap) {
+	merged := make(template.FuncMap, len(group.funcMap)+len(funcMap))
+	for name, fn := range group.funcMap {
+		merged[name] = fn
+	}
+	for name, fn := range funcMap {
+		merged[name] = fn
+	}
+	group.funcMap = merged
+}
+
+// LoadHTMLGlobWithLayouts works like Engine.LoadHTMLGlobWithLayouts, but the templates are
+// compiled with the group's FuncMap and only used by the routes registered on the group
+// after this call.
+func (group *RouterGroup) LoadHTMLGlobWithLayouts(layoutGlob, pageGlob string) {
+	funcMap := make(template.FuncMap, len(group.engine.FuncMap)+len(group.funcMap))
+	for name, fn := range group.engine.FuncMap {
+		funcMap[name] = fn
+	}
+	for name, fn := range group.funcMap {
+		funcMap[name] = fn
+	}
+	r := group.engine.newHTMLLayout(layoutGlob, pageGlob, funcMap)
+	group.engine.fragments.addRenderer(r)
+	group.engine.InvalidateFragments()
+	group.Use(func(c *Context) {
+		c.htmlRender = r
+	})
 }
 
 // BasePath returns the base path of router group.
@@ -85,11 +276,59 @@ func (group *RouterGroup) BasePath() string {
 
 func (group *RouterGroup) handle(httpMethod, relativePath string, handlers HandlersChain) IRoutes {
 	absolutePath := group.calculateAbsolutePath(relativePath)
-	handlers = group.combineHandlers(handlers)
-	group.engine.addRoute(httpMethod, absolutePath, handlers)
+	route := routeKey{method: httpMethod, path: absolutePath, handlers: len(handlers)}
+	global := group.globalMiddleware()
+	for i := global - 1; i >= 0; i-- {
+		if group.excluded[i][absolutePath] {
+			global--
+		}
+	}
+	handlers = group.dropExcluded(group.combineHandlers(handlers), absolutePath)
+	n := group.engine.addRoute(httpMethod, absolutePath, handlers)
+	n.global = global
+	n.mergeMeta(group.meta)
+	group.lastRoutes = []routeKey{route}
+	for g := group; g != nil && !g.hasRoutes; g = g.parent {
+		g.hasRoutes = true
+	}
 	return group.returnObj()
 }
 
+// handleMethods registers the route for each of methods.
+func (group *RouterGroup) handleMethods(methods []string, relativePath string, handlers HandlersChain) IRoutes {
+	routes := make([]routeKey, 0, len(methods))
+	for _, method := range methods {
+		group.handle(method, relativePath, handlers)
+		routes = append(routes, group.lastRoutes...)
+	}
+	group.lastRoutes = routes
+	return group.returnObj()
+}
+
+// globalMiddleware returns the number of engine middleware at the head of the
+// group handlers, after which the post-match middleware run, see Engine.UsePostMatch.
+func (group *RouterGroup) globalMiddleware() int {
+	if group.root {
+		return len(group.Handlers)
+	}
+	return group.global
+}
+
+// dropExcluded removes from handlers the group middleware excluded for absolutePath by UseExcept.
+func (group *RouterGroup) dropExcluded(handlers HandlersChain, absolutePath string) HandlersChain {
+	if len(group.excluded) == 0 {
+		return handlers
+	}
+	kept := handlers[:0]
+	for i, h := range handlers {
+		if i < len(group.Handlers) && group.excluded[i][absolutePath] {
+			continue
+		}
+		kept = append(kept, h)
+	}
+	return kept
+}
+
 // Handle registers a new request handle and middleware with the given path and method.
 // The last handler should be the real handler, the other ones should be middleware that can and should be shared among different routes.
 // See the example code in GitHub.
@@ -145,20 +384,12 @@ func (group *RouterGroup) HEAD(relativePath string, handlers ...HandlerFunc) IRo
 // Any registers a route that matches all the HTTP methods.
 // GET, POST, PUT, PATCH, HEAD, OPTIONS, DELETE, CONNECT, TRACE.
 func (group *RouterGroup) Any(relativePath string, handlers ...HandlerFunc) IRoutes {
-	for _, method := range anyMethods {
-		group.handle(method, relativePath, handlers)
-	}
-
-	return group.returnObj()
+	return group.handleMethods(anyMethods, relativePath, handlers)
 }
 
 // Match registers a route that matches the specified methods that you declared.
 func (group *RouterGroup) Match(methods []string, relativePath string, handlers ...HandlerFunc) IRoutes {
-	for _, method := range methods {
-		group.handle(method, relativePath, handlers)
-	}
-
-	return group.returnObj()
+	return group.handleMethods(methods, relativePath, handlers)
 }
 
 // StaticFile registers a single route in order to serve a single file of the local filesystem.
@@ -182,14 +413,14 @@ func (group *RouterGroup) staticFileHandler(relativePath string, handler Handler
 	if strings.Contains(relativePath, ":") || strings.Contains(relativePath, "*") {
 		panic("URL parameters can not be used when serving a static file")
 	}
-	group.GET(relativePath, handler)
-	group.HEAD(relativePath, handler)
-	return group.returnObj()
+	return group.handleMethods([]string{http.MethodGet, http.MethodHead}, relativePath, HandlersChain{handler})
 }
 
 // Static serves files from the given file system root.
 // Internally a http.FileServer is used, therefore http.NotFound is used instead
-// of the Router's NotFound handler.
+// of the Router's NotFound handler. The small files may be kept in memory, see
+// Engine.StaticCache, and the fingerprinted ones are cached forever by the
+// clients, see Engine.SetAssets.
 // To use the operating system's file system implementation,
 // use :
 //
@@ -208,28 +439,53 @@ func (group *RouterGroup) StaticFS(relativePath string, fs http.FileSystem) IRou
 	urlPattern := path.Join(relativePath, "/*filepath")
 
 	// Register GET and HEAD handlers
-	group.GET(urlPattern, handler)
-	group.HEAD(urlPattern, handler)
-	return group.returnObj()
+	return group.handleMethods([]string{http.MethodGet, http.MethodHead}, urlPattern, HandlersChain{handler})
 }
 
 func (group *RouterGroup) createStaticHandler(relativePath string, fs http.FileSystem) HandlerFunc {
 	absolutePath := group.calculateAbsolutePath(relativePath)
 	fileServer := http.StripPrefix(absolutePath, http.FileServer(fs))
+	cachePrefix := strings.TrimSuffix(absolutePath, "/")
 
 	return func(c *Context) {
 		if _, noListing := fs.(*onlyFilesFS); noListing {
 			c.Writer.WriteHeader(http.StatusNotFound)
 		}
 
-		file := c.Param("filepath")
-		// Check if file exists and/or if we have permission to access it
-		f, err := fs.Open(file)
-		if err != nil {
+		notFound := func() {
 			c.Writer.WriteHeader(http.StatusNotFound)
 			c.handlers = group.engine.noRoute
 			// Reset index
 			c.index = -1
+		}
+
+		file := c.Param("filepath")
+		if assets := group.engine.assets; assets != nil {
+			urlPath := cachePrefix + file
+			if original, ok := assets.file(urlPath); ok {
+				if original != urlPath && strings.HasPrefix(original, cachePrefix+"/") {
+					// a file fingerprinted by NewAssets, served under its own name.
+					defer func(old, oldRaw string) {
+						c.Request.URL.Path, c.Request.URL.RawPath = old, oldRaw
+					}(c.Request.URL.Path, c.Request.URL.RawPath)
+					c.Request.URL.Path, c.Request.URL.RawPath = original, ""
+					file = strings.TrimPrefix(original, cachePrefix)
+				}
+				// a missing or changed file must not be cached forever.
+				if !assets.unchanged(urlPath, fs, file) {
+					notFound()
+					return
+				}
+				c.Header("Cache-Control", assets.cacheControl)
+			}
+		}
+		if cache := group.engine.StaticCache; cache != nil && cache.serve(c, cachePrefix+file, fs, file) {
+			return
+		}
+		// Check if file exists and/or if we have permission to access it
+		f, err := fs.Open(file)
+		if err != nil {
+			notFound()
 			return
 		}
 		f.Close()
@@ -193,3 +193,110 @@ func testRoutesInterface(t *testing.T, r IRoutes) {
 	assert.Equal(t, r, r.Static("/static", "."))
 	assert.Equal(t, r, r.StaticFS("/static2", Dir(".", false)))
 }
+
+func TestRouterGroupUseExcept(t *testing.T) {
+	var calls []string
+	mw := func(name string) HandlerFunc {
+		return func(c *Context) { calls = append(calls, name+" "+c.FullPath()) }
+	}
+	router := New()
+	router.UseExcept(mw("global"), "/health")
+	api := router.Group("/api")
+	api.UseExcept(mw("auth"), "/login")
+	api.GET("/login", func(c *Context) {})
+	api.GET("/users", func(c *Context) {})
+	router.GET("/health", func(c *Context) {})
+
+	PerformRequest(router, http.MethodGet, "/api/login")
+	PerformRequest(router, http.MethodGet, "/api/users")
+	PerformRequest(router, http.MethodGet, "/health")
+	PerformRequest(router, http.MethodGet, "/missing")
+	assert.Equal(t, []string{"global /api/login", "global /api/users", "auth /api/users", "global "}, calls)
+}
+
+func skipLogger(c *Context) { c.Header("X-Logged", "yes") }
+
+func TestRouterGroupMeta(t *testing.T) {
+	router := New()
+	meta := func(c *Context) {
+		perm, _ := c.RouteMeta("perm")
+		ttl, ok := c.RouteMeta("ttl")
+		c.String(http.StatusOK, "%v %v %v", perm, ttl, ok)
+	}
+	router.GET("/users", meta).(RouteMetaSetter).Meta("perm", "users.read").(RouteMetaSetter).Meta("ttl", 60)
+	// splits the node of /users.
+	router.GET("/u", meta)
+	router.GET("/users/:id", meta).(RouteMetaSetter).Meta("perm", "users.show")
+	router.Any("/any", meta).(RouteMetaSetter).Meta("perm", "any")
+
+	w := PerformRequest(router, http.MethodGet, "/users")
+	assert.Equal(t, "users.read 60 true", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "/u")
+	assert.Equal(t, "<nil> <nil> false", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "/users/1")
+	assert.Equal(t, "users.show <nil> false", w.Body.String())
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodDelete} {
+		w = PerformRequest(router, method, "/any")
+		assert.Equal(t, "any <nil> false", w.Body.String())
+	}
+}
+
+func TestRouterGroupSetMeta(t *testing.T) {
+	router := New()
+	meta := func(c *Context) {
+		perm, _ := c.RouteMeta("perm")
+		limit, _ := c.RouteMeta("ratelimit")
+		c.String(http.StatusOK, "%v %v", perm, limit)
+	}
+	admin := router.Group("/admin")
+	admin.SetMeta("perm", "admin")
+	admin.SetMeta("ratelimit", "strict")
+	admin.GET("/users", meta)
+	admin.DELETE("/users/:id", meta).(RouteMetaSetter).Meta("perm", "admin.delete")
+	reports := admin.Group("/reports")
+	reports.SetMeta("ratelimit", "relaxed")
+	reports.GET("", meta)
+	router.GET("/public", meta)
+
+	w := PerformRequest(router, http.MethodGet, "/admin/users")
+	assert.Equal(t, "admin strict", w.Body.String())
+	w = PerformRequest(router, http.MethodDelete, "/admin/users/1")
+	assert.Equal(t, "admin.delete strict", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "/admin/reports")
+	assert.Equal(t, "admin relaxed", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "/public")
+	assert.Equal(t, "<nil> <nil>", w.Body.String())
+
+	for _, route := range router.Routes() {
+		if route.Path == "/admin/users/:id" {
+			assert.Equal(t, map[string]any{"perm": "admin.delete", "ratelimit": "strict"}, route.Meta)
+		}
+	}
+}
+
+func TestRouterGroupSkip(t *testing.T) {
+	auth := func(c *Context) { c.AbortWithStatus(http.StatusUnauthorized) }
+	router := New()
+	router.Use(skipLogger)
+	api := router.Group("/api", auth)
+	api.GET("/metrics", func(c *Context) { c.Status(http.StatusOK) }).(RouteSkipper).Skip(auth, skipLogger)
+	api.Any("/ping", func(c *Context) { c.Status(http.StatusOK) }).(RouteSkipper).Skip(auth)
+	api.GET("/private", func(c *Context) { c.Status(http.StatusOK) })
+
+	w := PerformRequest(router, http.MethodGet, "/api/metrics")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-Logged"))
+
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodDelete} {
+		w = PerformRequest(router, method, "/api/ping")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "yes", w.Header().Get("X-Logged"))
+	}
+
+	w = PerformRequest(router, http.MethodGet, "/api/private")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	router.StaticFile("/favicon.ico", "./testdata/template/hello.tmpl").(RouteSkipper).Skip(skipLogger)
+	w = PerformRequest(router, http.MethodHead, "/favicon.ico")
+	assert.Empty(t, w.Header().Get("X-Logged"))
+}
@@ -0,0 +1,162 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// RouteWarningKind is the kind of a RouteWarning.
+type RouteWarningKind string
+
+// The kinds of warnings reported by CheckRoutes.
+const (
+	// RouteUnreachable is reported when no request can reach a route, as the
+	// routes taking precedence over it serve all its requests, e.g. a route
+	// registered in code with the path of a route loaded by LoadRoutes.
+	RouteUnreachable RouteWarningKind = "unreachable"
+	// RouteDuplicateHandler is reported when the same handler appears several times
+	// in the handlers chain of a route, e.g. a middleware used by a group and a route.
+	RouteDuplicateHandler RouteWarningKind = "duplicate-handler"
+	// RouteEmptyGroup is reported for the groups on which no route was registered.
+	RouteEmptyGroup RouteWarningKind = "empty-group"
+)
+
+// routeProbe is the value given to the parameters of a route to build a request
+// path reaching it: no static route contains it, so that the parameters match.
+const routeProbe = "\x00"
+
+// RouteWarning is a possible mistake in the routes of an engine, see CheckRoutes.
+type RouteWarning struct {
+	Kind    RouteWarningKind `json:"kind"`
+	Method  string           `json:"method,omitempty"`
+	Path    string           `json:"path"`
+	Message string           `json:"message"`
+}
+
+// String returns the message of the warning.
+func (w RouteWarning) String() string {
+	return w.Message
+}
+
+// CheckRoutes returns warnings about the registered routes which are dead or
+// possibly misconfigured: routes unreachable by any request, handlers
+// registered twice in a chain and groups without routes. It is meant to be
+// called once all the routes are registered, e.g. in a test or at startup.
+func (engine *Engine) CheckRoutes() []RouteWarning {
+	var warnings []RouteWarning
+	loaded := engine.loadedRoutes.Load()
+	for _, tree := range engine.trees {
+		var routes []*node
+		tree.root.walk(func(n *node) {
+			if n.handlers != nil {
+				routes = append(routes, n)
+			}
+		})
+		sort.Slice(routes, func(i, j int) bool { return routes[i].fullPath < routes[j].fullPath })
+		for _, route := range routes {
+			if by, ok := unreachableBy(tree.method, tree.root, loaded, route.fullPath); ok {
+				warnings = append(warnings, RouteWarning{
+					Kind:    RouteUnreachable,
+					Method:  tree.method,
+					Path:    route.fullPath,
+					Message: fmt.Sprintf("%s %s is unreachable, its requests are served by %s", tree.method, route.fullPath, by),
+				})
+			}
+			seen := make(map[uintptr]bool, len(route.handlers))
+			for _, h := range route.handlers {
+				id := handlerIdentity(h)
+				if seen[id] {
+					warnings = append(warnings, RouteWarning{
+						Kind:    RouteDuplicateHandler,
+						Method:  tree.method,
+						Path:    route.fullPath,
+						Message: fmt.Sprintf("%s %s uses %s more than once", tree.method, route.fullPath, nameOfFunction(h)),
+					})
+				}
+				seen[id] = true
+			}
+		}
+	}
+	for _, group := range engine.groups {
+		if !group.hasRoutes {
+			warnings = append(warnings, RouteWarning{
+				Kind:    RouteEmptyGroup,
+				Path:    group.basePath,
+				Message: fmt.Sprintf("group %s has no routes", group.basePath),
+			})
+		}
+	}
+	return warnings
+}
+
+// MustCheckRoutes calls CheckRoutes and, in debug mode, panics if some routes
+// are unreachable, to fail fast during development. The other warnings are
+// only informational and always returned.
+func (engine *Engine) MustCheckRoutes() []RouteWarning {
+	warnings := engine.CheckRoutes()
+	if !IsDebugging() {
+		return warnings
+	}
+	var messages []string
+	for _, w := range warnings {
+		if w.Kind == RouteUnreachable {
+			messages = append(messages, w.Message)
+		}
+	}
+	if len(messages) > 0 {
+		panic("gin: unreachable routes:\n" + strings.Join(messages, "\n"))
+	}
+	return warnings
+}
+
+// unreachableBy reports whether the route of the tree root with the given full
+// path can not be reached, and returns the path of the route serving its
+// requests instead, either a route of loaded or of the tree.
+func unreachableBy(method string, root *node, loaded *loadedRoutes, fullPath string) (string, bool) {
+	path := probePath(fullPath)
+	skippedNodes := make([]skippedNode, 0, countSections(path)+1)
+	if loaded != nil {
+		if loadedRoot := loaded.trees.get(method); loadedRoot != nil {
+			if value := loadedRoot.getValue(path, nil, &skippedNodes, false); value.handlers != nil {
+				return value.fullPath + " (loaded)", true
+			}
+		}
+	}
+	skippedNodes = skippedNodes[:0]
+	value := root.getValue(path, nil, &skippedNodes, false)
+	if value.handlers == nil || value.fullPath == fullPath {
+		return "", false
+	}
+	return value.fullPath, true
+}
+
+// probePath returns a request path matching the route with the given full
+// path, whose parameters are set to routeProbe.
+func probePath(fullPath string) string {
+	segments := strings.Split(fullPath, "/")
+	for i, s := range segments {
+		switch {
+		case strings.HasPrefix(s, ":"):
+			segments[i] = routeProbe
+		case strings.HasPrefix(s, "*"):
+			segments[i] = routeProbe
+			return strings.Join(segments[:i+1], "/")
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// handlerIdentity returns an identifier of the function value h, after
+// unwrapping NameHandler. Unlike the code pointer given by reflect, it differs
+// between the closures returned by several calls of the same function, e.g.
+// requireRole("admin") and requireRole("editor").
+func handlerIdentity(h HandlerFunc) uintptr {
+	h = unwrapHandler(h)
+	return *(*uintptr)(unsafe.Pointer(&h))
+}
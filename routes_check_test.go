@@ -0,0 +1,72 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbePath(t *testing.T) {
+	assert.Equal(t, "/users/me", probePath("/users/me"))
+	assert.Equal(t, "/users/"+routeProbe+"/posts", probePath("/users/:id/posts"))
+	assert.Equal(t, "/files/"+routeProbe, probePath("/files/*path"))
+}
+
+func requireRole(role string) HandlerFunc {
+	return func(c *Context) {
+		c.Set("role", role)
+	}
+}
+
+func TestCheckRoutes(t *testing.T) {
+	SetMode(ReleaseMode)
+	defer SetMode(TestMode)
+
+	router := New()
+	router.GET("/users/:id", handlerTest1)
+	router.GET("/users/me", handlerTest1)
+	router.GET("/admin", requireRole("a"), requireRole("b"), handlerTest1)
+	api := router.Group("/api", handlerTest2)
+	api.GET("/ping", handlerTest2, handlerTest1)
+	router.Group("/empty")
+	v1 := router.Group("/v1")
+	v1.Group("/users").POST("", handlerTest1)
+
+	warnings := router.CheckRoutes()
+	assert.Equal(t, []RouteWarning{
+		{Kind: RouteDuplicateHandler, Method: "GET", Path: "/api/ping", Message: "GET /api/ping uses github.com/gin-gonic/gin.handlerTest2 more than once"},
+		{Kind: RouteEmptyGroup, Path: "/empty", Message: "group /empty has no routes"},
+	}, warnings)
+
+	SetMode(DebugMode)
+	assert.Equal(t, warnings, router.MustCheckRoutes())
+}
+
+func TestCheckRoutesUnreachable(t *testing.T) {
+	SetMode(ReleaseMode)
+	defer SetMode(TestMode)
+
+	router := New()
+	router.RegisterHandler("users.get", handlerTest1)
+	router.GET("/users/:id", handlerTest1)
+	router.GET("/users/me", handlerTest1)
+	router.GET("/posts/:id", handlerTest1)
+	assert.NoError(t, router.LoadRoutes(RouteManifest{Routes: []RouteSpec{
+		{Method: "GET", Path: "/users/:name", Handler: "users.get"},
+		{Method: "GET", Path: "/posts/latest", Handler: "users.get"},
+	}}))
+
+	warnings := router.CheckRoutes()
+	assert.Equal(t, []RouteWarning{
+		{Kind: RouteUnreachable, Method: "GET", Path: "/users/:id", Message: "GET /users/:id is unreachable, its requests are served by /users/:name (loaded)"},
+		{Kind: RouteUnreachable, Method: "GET", Path: "/users/me", Message: "GET /users/me is unreachable, its requests are served by /users/:name (loaded)"},
+	}, warnings)
+	assert.Equal(t, warnings, router.MustCheckRoutes())
+
+	SetMode(DebugMode)
+	assert.Panics(t, func() { router.MustCheckRoutes() })
+}
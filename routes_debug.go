@@ -0,0 +1,150 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/gin-gonic/gin/internal/json"
+)
+
+// RoutesFormat is the output format of Engine.DebugRoutes.
+type RoutesFormat string
+
+// The formats of Engine.DebugRoutes.
+const (
+	// RoutesTable is a text table of the routes, in the order of the trees.
+	RoutesTable RoutesFormat = "table"
+	// RoutesJSON is the JSON description of the radix trees.
+	RoutesJSON RoutesFormat = "json"
+	// RoutesDOT is the Graphviz description of the radix trees.
+	RoutesDOT RoutesFormat = "dot"
+)
+
+// RouteTree is the description of a method tree written by DebugRoutes in JSON.
+type RouteTree struct {
+	Method string     `json:"method"`
+	Root   *RouteNode `json:"root"`
+}
+
+// RouteNode is a node of a RouteTree. Route and Handler are only set for the
+// nodes of a route.
+type RouteNode struct {
+	Path     string       `json:"path"`
+	Type     string       `json:"type"`
+	Priority uint32       `json:"priority"`
+	Route    string       `json:"route,omitempty"`
+	Handler  string       `json:"handler,omitempty"`
+	Handlers int          `json:"handlers,omitempty"`
+	Children []*RouteNode `json:"children,omitempty"`
+}
+
+// DebugRoutes writes the routes of the engine to w in the given format: a table
+// of the routes, or the radix trees in JSON or in Graphviz DOT (render it with
+// `dot -Tsvg`). The nodes of the trees are listed in their matching order,
+// which helps diagnose precedence issues. It can be called at any time, e.g.
+// from an admin endpoint.
+func (engine *Engine) DebugRoutes(w io.Writer, format RoutesFormat) error {
+	switch format {
+	case RoutesTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "METHOD\tPATH\tHANDLER\tHANDLERS\tNAME")
+		for _, tree := range engine.trees {
+			tree.root.walkRoutes("", func(path string, n *node) {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", tree.method, path, nameOfFunction(n.handlers.Last()), len(n.handlers), n.name)
+			})
+		}
+		return tw.Flush()
+	case RoutesJSON:
+		data, err := json.MarshalIndent(engine.routeTrees(), "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	case RoutesDOT:
+		bw := bufio.NewWriter(w)
+		fmt.Fprintln(bw, "digraph routes {")
+		fmt.Fprintln(bw, "  rankdir=LR;")
+		fmt.Fprintln(bw, "  node [shape=box, fontname=monospace];")
+		id := 0
+		var writeNode func(parent string, n *RouteNode)
+		writeNode = func(parent string, n *RouteNode) {
+			id++
+			name := "n" + strconv.Itoa(id)
+			label := n.Path
+			attrs := ""
+			if n.Route != "" {
+				label += "\n" + n.Handler
+				attrs = ", style=bold"
+			}
+			if n.Type != "static" && n.Type != "root" {
+				attrs += ", shape=hexagon"
+			}
+			fmt.Fprintf(bw, "  %s [label=%s%s];\n", name, strconv.Quote(label), attrs)
+			fmt.Fprintf(bw, "  %s -> %s;\n", parent, name)
+			for _, child := range n.Children {
+				writeNode(name, child)
+			}
+		}
+		for _, tree := range engine.routeTrees() {
+			method := strconv.Quote(tree.Method)
+			fmt.Fprintf(bw, "  %s [shape=ellipse];\n", method)
+			writeNode(method, tree.Root)
+		}
+		fmt.Fprintln(bw, "}")
+		return bw.Flush()
+	}
+	return fmt.Errorf("gin: unknown routes format %q", format)
+}
+
+// routeTrees returns the description of the trees of the engine.
+func (engine *Engine) routeTrees() []RouteTree {
+	trees := make([]RouteTree, 0, len(engine.trees))
+	for _, tree := range engine.trees {
+		trees = append(trees, RouteTree{Method: tree.method, Root: describeNode(tree.root)})
+	}
+	return trees
+}
+
+func describeNode(n *node) *RouteNode {
+	d := &RouteNode{Path: n.path, Type: nodeTypeName(n.nType), Priority: n.priority}
+	if len(n.handlers) > 0 {
+		d.Route = n.fullPath
+		d.Handler = nameOfFunction(n.handlers.Last())
+		d.Handlers = len(n.handlers)
+	}
+	for _, child := range n.children {
+		d.Children = append(d.Children, describeNode(child))
+	}
+	return d
+}
+
+func nodeTypeName(t nodeType) string {
+	switch t {
+	case root:
+		return "root"
+	case param:
+		return "param"
+	case catchAll:
+		return "catchAll"
+	}
+	return "static"
+}
+
+// walkRoutes calls f for each node of a route, in matching order, with its path.
+func (n *node) walkRoutes(prefix string, f func(path string, n *node)) {
+	path := prefix + n.path
+	if len(n.handlers) > 0 {
+		f(path, n)
+	}
+	for _, child := range n.children {
+		child.walkRoutes(path, f)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func debugRoutesEngine() *Engine {
+	router := New()
+	router.GET("/users/:id", handlerTest1).(RouteNamer).Name("users.show")
+	router.GET("/users/me", handlerTest2)
+	router.POST("/files/*path", handlerTest1)
+	return router
+}
+
+func TestDebugRoutesTable(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, debugRoutesEngine().DebugRoutes(&b, RoutesTable))
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Regexp(t, `^METHOD\s+PATH\s+HANDLER\s+HANDLERS\s+NAME$`, lines[0])
+	assert.Regexp(t, `^GET\s+/users/me\s+github.com/gin-gonic/gin.handlerTest2\s+1\s*$`, lines[1])
+	assert.Regexp(t, `^GET\s+/users/:id\s+github.com/gin-gonic/gin.handlerTest1\s+1\s+users.show$`, lines[2])
+	assert.Regexp(t, `^POST\s+/files/\*path\s+`, lines[3])
+}
+
+func TestDebugRoutesJSON(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, debugRoutesEngine().DebugRoutes(&b, RoutesJSON))
+	var trees []RouteTree
+	require.NoError(t, json.Unmarshal([]byte(b.String()), &trees))
+	require.Len(t, trees, 2)
+	assert.Equal(t, http.MethodGet, trees[0].Method)
+
+	root := trees[0].Root
+	assert.Equal(t, "/users/", root.Path)
+	require.Len(t, root.Children, 2)
+	assert.Equal(t, RouteNode{Path: "me", Type: "static", Priority: 1, Route: "/users/me",
+		Handler: "github.com/gin-gonic/gin.handlerTest2", Handlers: 1}, *root.Children[0])
+	assert.Equal(t, "param", root.Children[1].Type)
+	assert.Equal(t, "/users/:id", root.Children[1].Route)
+}
+
+func TestDebugRoutesDOT(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, debugRoutesEngine().DebugRoutes(&b, RoutesDOT))
+	dot := b.String()
+	assert.True(t, strings.HasPrefix(dot, "digraph routes {\n"))
+	assert.True(t, strings.HasSuffix(dot, "}\n"))
+	assert.Contains(t, dot, `"GET" [shape=ellipse];`)
+	assert.Contains(t, dot, `[label="me\ngithub.com/gin-gonic/gin.handlerTest2", style=bold];`)
+	assert.Contains(t, dot, `[label=":id\ngithub.com/gin-gonic/gin.handlerTest1", style=bold, shape=hexagon];`)
+	assert.Contains(t, dot, `"GET" -> n1;`)
+
+	assert.EqualError(t, New().DebugRoutes(&b, "xml"), `gin: unknown routes format "xml"`)
+}
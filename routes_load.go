@@ -0,0 +1,160 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RouteManifest is a declarative description of routes, see Engine.LoadRoutes.
+// It can be decoded from JSON or YAML:
+//
+//	routes:
+//	  - method: GET
+//	    path: /users/:id
+//	    handler: users.get
+//	    middleware: [auth]
+//	    meta:
+//	      perm: users.read
+type RouteManifest struct {
+	Routes []RouteSpec `json:"routes" yaml:"routes"`
+}
+
+// RouteSpec is a route of a RouteManifest. Handler and Middleware are names of
+// handlers registered with Engine.RegisterHandler. Method "ANY" registers the
+// route for all the methods, like RouterGroup.Any. Meta is the metadata of the
+// route, see RouterGroup.Meta.
+type RouteSpec struct {
+	Method     string         `json:"method" yaml:"method"`
+	Path       string         `json:"path" yaml:"path"`
+	Handler    string         `json:"handler" yaml:"handler"`
+	Middleware []string       `json:"middleware,omitempty" yaml:"middleware,omitempty"`
+	Meta       map[string]any `json:"meta,omitempty" yaml:"meta,omitempty"`
+}
+
+type loadedRoutes struct {
+	trees       methodTrees
+	maxParams   uint16
+	maxSections uint16
+}
+
+// RegisterHandler makes a handler (or middleware) available by name to the
+// route manifests given to LoadRoutes.
+func (engine *Engine) RegisterHandler(name string, handler HandlerFunc) {
+	engine.handlerRegistryMu.Lock()
+	defer engine.handlerRegistryMu.Unlock()
+	if engine.handlerRegistry == nil {
+		engine.handlerRegistry = make(map[string]HandlerFunc)
+	}
+	engine.handlerRegistry[name] = NameHandler(name, handler)
+}
+
+// LoadRoutes replaces the routes loaded from the previous manifest by the routes
+// of manifest. It can be called at any time while serving: the new routes are
+// built aside and swapped atomically, so that gateway-style applications can
+// reload their routing from a file or a database without restarting. The
+// global middleware of the engine is applied to the loaded routes, which take
+// precedence over the routes registered in code. Like for the routes registered
+// in code, these are the middleware attached with Use before the call: call
+// LoadRoutes again to apply the ones attached afterwards. The loaded routes are
+// also taken into account by RedirectTrailingSlash, RedirectFixedPath and
+// HandleMethodNotAllowed. Nothing is changed when the manifest is invalid.
+func (engine *Engine) LoadRoutes(manifest RouteManifest) (err error) {
+	if len(manifest.Routes) == 0 {
+		engine.loadedRoutes.Store(nil)
+		return nil
+	}
+
+	engine.handlerRegistryMu.RLock()
+	defer engine.handlerRegistryMu.RUnlock()
+	lookup := func(name string) (HandlerFunc, error) {
+		if h, ok := engine.handlerRegistry[name]; ok {
+			return h, nil
+		}
+		return nil, fmt.Errorf("gin: unknown handler %q", name)
+	}
+
+	loaded := &loadedRoutes{}
+	defer func() {
+		// conflicting routes make the tree panic.
+		if r := recover(); r != nil {
+			err = fmt.Errorf("gin: invalid route manifest: %v", r)
+		}
+	}()
+	for _, spec := range manifest.Routes {
+		if !strings.HasPrefix(spec.Path, "/") {
+			return fmt.Errorf("gin: path %q must begin with '/'", spec.Path)
+		}
+		handlers := make(HandlersChain, 0, len(spec.Middleware)+1)
+		for _, name := range append(spec.Middleware, spec.Handler) {
+			h, err := lookup(name)
+			if err != nil {
+				return err
+			}
+			handlers = append(handlers, h)
+		}
+		handlers = engine.combineHandlers(handlers)
+
+		methods := []string{strings.ToUpper(spec.Method)}
+		if methods[0] == "ANY" {
+			methods = anyMethods
+		}
+		for _, method := range methods {
+			if matched := regEnLetter.MatchString(method); !matched {
+				return fmt.Errorf("gin: invalid http method %q", spec.Method)
+			}
+			root := loaded.trees.get(method)
+			if root == nil {
+				root = &node{fullPath: "/"}
+				loaded.trees = append(loaded.trees, methodTree{method: method, root: root})
+			}
+			route := root.addRoute(spec.Path, handlers)
+			route.mergeMeta(spec.Meta)
+			route.global = len(engine.Handlers)
+		}
+		if n := countParams(spec.Path); n > loaded.maxParams {
+			loaded.maxParams = n
+		}
+		if n := countSections(spec.Path); n > loaded.maxSections {
+			loaded.maxSections = n
+		}
+	}
+	engine.loadedRoutes.Store(loaded)
+	return nil
+}
+
+// methodTrees returns the trees of the loaded routes, or nil if loaded is nil.
+func (loaded *loadedRoutes) methodTrees() methodTrees {
+	if loaded == nil {
+		return nil
+	}
+	return loaded.trees
+}
+
+// serve handles the request with a loaded route, if one matches. Otherwise it
+// reports whether a loaded route matches the path with or without a trailing
+// slash, see Engine.RedirectTrailingSlash.
+func (loaded *loadedRoutes) serve(c *Context, rPath string, unescape bool) (served, tsr bool) {
+	root := loaded.trees.get(c.Request.Method)
+	if root == nil {
+		return false, false
+	}
+	// the contexts of the pool are sized for the routes registered in code.
+	c.growRouting(loaded.maxParams, loaded.maxSections)
+	value := root.getValue(rPath, c.params, c.skippedNodes, unescape)
+	if value.handlers == nil {
+		*c.params = (*c.params)[:0]
+		*c.skippedNodes = (*c.skippedNodes)[:0]
+		return false, value.tsr
+	}
+	if value.params != nil {
+		c.Params = *value.params
+	}
+	c.engine.serveMatched(c, value)
+	c.Next()
+	c.writermem.WriteHeaderNow()
+	return true, false
+}
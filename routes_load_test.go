@@ -0,0 +1,110 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestLoadRoutes(t *testing.T) {
+	router := New()
+	router.Use(func(c *Context) { c.Header("X-Global", "1") })
+	router.GET("/static", func(c *Context) { c.String(http.StatusOK, "static") })
+	router.RegisterHandler("auth", func(c *Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+		}
+	})
+	router.RegisterHandler("users.get", func(c *Context) {
+		c.String(http.StatusOK, "user "+c.Param("id")+" "+c.Param("a")+c.Param("f"))
+	})
+	router.RegisterHandler("ok", func(c *Context) { c.String(http.StatusOK, "ok "+c.FullPath()) })
+	router.RegisterHandler("meta", func(c *Context) {
+		perm, _ := c.RouteMeta("perm")
+		c.String(http.StatusOK, "%v", perm)
+	})
+
+	var manifest RouteManifest
+	require.NoError(t, yaml.Unmarshal([]byte(`
+routes:
+  - method: GET
+    path: /users/:id/:a/:b/:c/:d/:e/:f
+    handler: users.get
+    middleware: [auth]
+  - method: any
+    path: /ping
+    handler: ok
+  - method: GET
+    path: /meta
+    handler: meta
+    meta:
+      perm: users.read
+`), &manifest))
+	require.NoError(t, router.LoadRoutes(manifest))
+
+	w := PerformRequest(router, http.MethodGet, "/users/1/2/3/4/5/6/7")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = PerformRequest(router, http.MethodGet, "/users/1/2/3/4/5/6/7", header{Key: "Authorization", Value: "x"})
+	assert.Equal(t, "user 1 27", w.Body.String())
+	assert.Equal(t, "1", w.Header().Get("X-Global"))
+	w = PerformRequest(router, http.MethodPost, "/ping")
+	assert.Equal(t, "ok /ping", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "/meta")
+	assert.Equal(t, "users.read", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "/static")
+	assert.Equal(t, "static", w.Body.String())
+
+	// invalid manifests leave the loaded routes unchanged.
+	assert.EqualError(t, router.LoadRoutes(RouteManifest{Routes: []RouteSpec{{Method: "GET", Path: "/x", Handler: "missing"}}}),
+		`gin: unknown handler "missing"`)
+	assert.Error(t, router.LoadRoutes(RouteManifest{Routes: []RouteSpec{
+		{Method: "GET", Path: "/x/:a", Handler: "ok"},
+		{Method: "GET", Path: "/x/:b", Handler: "ok"},
+	}}))
+	assert.Error(t, router.LoadRoutes(RouteManifest{Routes: []RouteSpec{{Method: "GET", Path: "x", Handler: "ok"}}}))
+	w = PerformRequest(router, http.MethodGet, "/ping")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	require.NoError(t, router.LoadRoutes(RouteManifest{Routes: []RouteSpec{{Method: "GET", Path: "/pong", Handler: "ok"}}}))
+	w = PerformRequest(router, http.MethodGet, "/ping")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = PerformRequest(router, http.MethodGet, "/pong")
+	assert.Equal(t, "ok /pong", w.Body.String())
+
+	require.NoError(t, router.LoadRoutes(RouteManifest{}))
+	w = PerformRequest(router, http.MethodGet, "/pong")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestLoadRoutesRedirectsAndMethodNotAllowed(t *testing.T) {
+	router := New()
+	router.HandleMethodNotAllowed = true
+	router.RedirectFixedPath = true
+	router.RegisterHandler("ok", func(c *Context) { c.String(http.StatusOK, "ok") })
+	manifest := RouteManifest{Routes: []RouteSpec{{Method: "GET", Path: "/items", Handler: "ok"}}}
+	require.NoError(t, router.LoadRoutes(manifest))
+
+	w := PerformRequest(router, http.MethodGet, "/items/")
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/items", w.Header().Get("Location"))
+	w = PerformRequest(router, http.MethodGet, "/ITEMS")
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/items", w.Header().Get("Location"))
+	w = PerformRequest(router, http.MethodPost, "/items")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	// the global middleware are the ones attached before the routes are loaded.
+	router.Use(func(c *Context) { c.Header("X-Global", "1") })
+	w = PerformRequest(router, http.MethodGet, "/items")
+	assert.Empty(t, w.Header().Get("X-Global"))
+	require.NoError(t, router.LoadRoutes(manifest))
+	w = PerformRequest(router, http.MethodGet, "/items")
+	assert.Equal(t, "1", w.Header().Get("X-Global"))
+}
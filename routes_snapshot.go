@@ -0,0 +1,122 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RouteSnapshot is a stable and serializable description of a route.
+type RouteSnapshot struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Handler    string   `json:"handler"`
+	Middleware []string `json:"middleware"`
+}
+
+// RoutesSnapshot is the list of the routes of an engine, sorted by path and method.
+type RoutesSnapshot []RouteSnapshot
+
+// RouteChangeKind is the kind of a RouteChange.
+type RouteChangeKind string
+
+// The kinds of changes reported by DiffRoutes.
+const (
+	RouteAdded   RouteChangeKind = "added"
+	RouteRemoved RouteChangeKind = "removed"
+	RouteChanged RouteChangeKind = "changed"
+)
+
+// RouteChange is a difference between two RoutesSnapshot. Old is nil for an
+// added route and New is nil for a removed route.
+type RouteChange struct {
+	Kind RouteChangeKind `json:"kind"`
+	Old  *RouteSnapshot  `json:"old,omitempty"`
+	New  *RouteSnapshot  `json:"new,omitempty"`
+}
+
+// String returns a human readable description of the change.
+func (rc RouteChange) String() string {
+	switch rc.Kind {
+	case RouteAdded:
+		return fmt.Sprintf("+ %s %s", rc.New.Method, rc.New.Path)
+	case RouteRemoved:
+		return fmt.Sprintf("- %s %s", rc.Old.Method, rc.Old.Path)
+	}
+	return fmt.Sprintf("~ %s %s: %s [%s] -> %s [%s]", rc.New.Method, rc.New.Path,
+		rc.Old.Handler, strings.Join(rc.Old.Middleware, ", "),
+		rc.New.Handler, strings.Join(rc.New.Middleware, ", "))
+}
+
+// RoutesSnapshot returns a description of all the registered routes, including
+// the names of their handler and middleware, in a stable order. Snapshots can be
+// stored (e.g. as JSON) and compared with DiffRoutes, to check in tests that no
+// route disappeared or changed its middleware unintentionally.
+func (engine *Engine) RoutesSnapshot() RoutesSnapshot {
+	var snapshot RoutesSnapshot
+	for _, tree := range engine.trees {
+		snapshot = snapshotRoutes("", tree.method, snapshot, tree.root)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].Path != snapshot[j].Path {
+			return snapshot[i].Path < snapshot[j].Path
+		}
+		return snapshot[i].Method < snapshot[j].Method
+	})
+	return snapshot
+}
+
+func snapshotRoutes(path, method string, snapshot RoutesSnapshot, root *node) RoutesSnapshot {
+	path += root.path
+	if n := len(root.handlers); n > 0 {
+		middleware := make([]string, 0, n-1)
+		for _, h := range root.handlers[:n-1] {
+			middleware = append(middleware, nameOfFunction(h))
+		}
+		snapshot = append(snapshot, RouteSnapshot{
+			Method:     method,
+			Path:       path,
+			Handler:    nameOfFunction(root.handlers.Last()),
+			Middleware: middleware,
+		})
+	}
+	for _, child := range root.children {
+		snapshot = snapshotRoutes(path, method, snapshot, child)
+	}
+	return snapshot
+}
+
+// DiffRoutes returns the routes which were added, removed or changed (handler or
+// middleware) between before and after.
+func DiffRoutes(before, after RoutesSnapshot) []RouteChange {
+	key := func(r RouteSnapshot) string { return r.Method + " " + r.Path }
+
+	beforeRoutes := make(map[string]*RouteSnapshot, len(before))
+	for i := range before {
+		beforeRoutes[key(before[i])] = &before[i]
+	}
+
+	var changes []RouteChange
+	seen := make(map[string]bool, len(after))
+	for i := range after {
+		k := key(after[i])
+		seen[k] = true
+		o, ok := beforeRoutes[k]
+		switch {
+		case !ok:
+			changes = append(changes, RouteChange{Kind: RouteAdded, New: &after[i]})
+		case o.Handler != after[i].Handler || strings.Join(o.Middleware, "\n") != strings.Join(after[i].Middleware, "\n"):
+			changes = append(changes, RouteChange{Kind: RouteChanged, Old: o, New: &after[i]})
+		}
+	}
+	for i := range before {
+		if !seen[key(before[i])] {
+			changes = append(changes, RouteChange{Kind: RouteRemoved, Old: &before[i]})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func snapshotAuth(c *Context) {}
+
+func snapshotHandler(c *Context) {}
+
+func TestRoutesSnapshot(t *testing.T) {
+	router := New()
+	router.GET("/b", snapshotHandler)
+	api := router.Group("/api", snapshotAuth)
+	api.POST("/users", snapshotHandler)
+	api.GET("/users", snapshotHandler)
+
+	snapshot := router.RoutesSnapshot()
+	assert.Equal(t, RoutesSnapshot{
+		{Method: "GET", Path: "/api/users", Handler: "github.com/gin-gonic/gin.snapshotHandler", Middleware: []string{"github.com/gin-gonic/gin.snapshotAuth"}},
+		{Method: "POST", Path: "/api/users", Handler: "github.com/gin-gonic/gin.snapshotHandler", Middleware: []string{"github.com/gin-gonic/gin.snapshotAuth"}},
+		{Method: "GET", Path: "/b", Handler: "github.com/gin-gonic/gin.snapshotHandler", Middleware: []string{}},
+	}, snapshot)
+
+	data, err := json.Marshal(snapshot)
+	assert.NoError(t, err)
+	var decoded RoutesSnapshot
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, snapshot, decoded)
+}
+
+func TestDiffRoutes(t *testing.T) {
+	before := New()
+	before.GET("/removed", snapshotHandler)
+	before.GET("/same", snapshotHandler)
+	before.GET("/admin", snapshotAuth, snapshotHandler)
+
+	after := New()
+	after.GET("/same", snapshotHandler)
+	after.GET("/admin", snapshotHandler)
+	after.POST("/added", snapshotHandler)
+
+	changes := DiffRoutes(before.RoutesSnapshot(), after.RoutesSnapshot())
+	assert.Len(t, changes, 3)
+	assert.Equal(t, RouteAdded, changes[0].Kind)
+	assert.Equal(t, "+ POST /added", changes[0].String())
+	assert.Equal(t, RouteChanged, changes[1].Kind)
+	assert.Equal(t, "/admin", changes[1].New.Path)
+	assert.Contains(t, changes[1].String(), "~ GET /admin")
+	assert.Equal(t, RouteRemoved, changes[2].Kind)
+	assert.Equal(t, "- GET /removed", changes[2].String())
+
+	assert.Empty(t, DiffRoutes(after.RoutesSnapshot(), after.RoutesSnapshot()))
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin/internal/bytesconv"
+)
+
+// The reasons of a RouteSuggestion.
+const (
+	// SuggestionMethod is the reason of a route matching the path with another method.
+	SuggestionMethod = "method"
+	// SuggestionTrailingSlash is the reason of a route matching the path with or
+	// without a trailing slash.
+	SuggestionTrailingSlash = "trailing-slash"
+	// SuggestionCase is the reason of a route matching the path with another case.
+	SuggestionCase = "case"
+)
+
+// RouteSuggestion is a route close to an unmatched request, see Context.RouteSuggestions.
+type RouteSuggestion struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// String returns the method and path of the suggestion.
+func (s RouteSuggestion) String() string {
+	return s.Method + " " + s.Path
+}
+
+// RouteSuggestions returns the near-miss routes of a request which matched no
+// route, typically in a NoRoute or NoMethod handler: the same path with another
+// method, the path with or without a trailing slash and the path with another
+// case. It returns nil for the requests which matched a route.
+//
+//	router.NoRoute(func(c *gin.Context) {
+//		c.JSON(http.StatusNotFound, gin.H{"error": "not found", "suggestions": c.RouteSuggestions()})
+//	})
+func (c *Context) RouteSuggestions() []RouteSuggestion {
+	if c.fullPath != "" || c.engine == nil || c.Request == nil {
+		return nil
+	}
+	engine := c.engine
+	rPath := c.Request.URL.Path
+	method := c.Request.Method
+	skippedNodes := make([]skippedNode, 0, engine.maxSections)
+
+	var suggestions []RouteSuggestion
+	for _, tree := range engine.trees {
+		value := tree.root.getValue(rPath, nil, &skippedNodes, false)
+		if value.handlers != nil {
+			if tree.method != method {
+				suggestions = append(suggestions, RouteSuggestion{Method: tree.method, Path: rPath, Reason: SuggestionMethod})
+			}
+			continue
+		}
+		if value.tsr && rPath != "/" {
+			fixed := rPath + "/"
+			if strings.HasSuffix(rPath, "/") {
+				fixed = rPath[:len(rPath)-1]
+			}
+			suggestions = append(suggestions, RouteSuggestion{Method: tree.method, Path: fixed, Reason: SuggestionTrailingSlash})
+			continue
+		}
+		if fixed, ok := tree.root.findCaseInsensitivePath(cleanPath(rPath), false); ok {
+			suggestions = append(suggestions, RouteSuggestion{Method: tree.method, Path: bytesconv.BytesToString(fixed), Reason: SuggestionCase})
+		}
+	}
+	// suggestions with the method of the request first.
+	sorted := make([]RouteSuggestion, 0, len(suggestions))
+	for _, s := range suggestions {
+		if s.Method == method {
+			sorted = append(sorted, s)
+		}
+	}
+	for _, s := range suggestions {
+		if s.Method != method {
+			sorted = append(sorted, s)
+		}
+	}
+	return sorted
+}
+
+// suggestionsBody appends the route suggestions of c to the body of a 404 or 405 response.
+func suggestionsBody(c *Context, body []byte) []byte {
+	suggestions := c.RouteSuggestions()
+	if len(suggestions) == 0 {
+		return body
+	}
+	var b strings.Builder
+	b.Write(body)
+	b.WriteString("\n\nDid you mean:\n")
+	for _, s := range suggestions {
+		b.WriteString("  ")
+		b.WriteString(s.String())
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteSuggestions(t *testing.T) {
+	var suggestions []RouteSuggestion
+	router := New()
+	router.RedirectTrailingSlash = false
+	router.RedirectFixedPath = false
+	router.NoRoute(func(c *Context) { suggestions = c.RouteSuggestions() })
+	router.GET("/users/", func(c *Context) {
+		assert.Nil(t, c.RouteSuggestions())
+	})
+	router.POST("/users", func(c *Context) {})
+	router.GET("/Items/:id", func(c *Context) {})
+
+	PerformRequest(router, http.MethodGet, "/users")
+	assert.Equal(t, []RouteSuggestion{
+		{Method: http.MethodGet, Path: "/users/", Reason: SuggestionTrailingSlash},
+		{Method: http.MethodPost, Path: "/users", Reason: SuggestionMethod},
+	}, suggestions)
+
+	PerformRequest(router, http.MethodGet, "/items/42")
+	assert.Equal(t, []RouteSuggestion{{Method: http.MethodGet, Path: "/Items/42", Reason: SuggestionCase}}, suggestions)
+
+	PerformRequest(router, http.MethodGet, "/missing")
+	assert.Empty(t, suggestions)
+
+	PerformRequest(router, http.MethodGet, "/users/")
+}
+
+func TestRouteSuggestionsInDebugResponses(t *testing.T) {
+	SetMode(DebugMode)
+	defer SetMode(TestMode)
+
+	router := New()
+	router.HandleMethodNotAllowed = true
+	router.SuggestRoutes = true
+	router.POST("/users", func(c *Context) {})
+	router.DELETE("/users", func(c *Context) {})
+
+	w := PerformRequest(router, http.MethodGet, "/users")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "405 method not allowed\n\nDid you mean:\n  POST /users\n  DELETE /users\n", w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/missing")
+	assert.Equal(t, "404 page not found", w.Body.String())
+
+	SetMode(ReleaseMode)
+	w = PerformRequest(router, http.MethodGet, "/users")
+	assert.Equal(t, "405 method not allowed", w.Body.String())
+}
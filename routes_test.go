@@ -719,6 +719,45 @@ func TestRouteContextHoldsFullPath(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
+func TestRouteContextHoldsTemplateAndName(t *testing.T) {
+	router := New()
+	router.HandleMethodNotAllowed = true
+	var template, name string
+	router.Use(func(c *Context) {
+		c.Next()
+		template, name = c.RouteTemplate(), c.RouteName()
+	})
+	router.GET("/users/:id", func(c *Context) {}).(RouteNamer).Name("users.show")
+	// splits the node of /users/:id.
+	router.GET("/users", func(c *Context) {})
+	router.Any("/ping", func(c *Context) {}).(RouteNamer).Name("ping")
+
+	PerformRequest(router, http.MethodGet, "/users/1")
+	assert.Equal(t, "/users/:id", template)
+	assert.Equal(t, "users.show", name)
+
+	PerformRequest(router, http.MethodGet, "/users")
+	assert.Equal(t, "/users", template)
+	assert.Empty(t, name)
+
+	PerformRequest(router, http.MethodPatch, "/ping")
+	assert.Equal(t, "ping", name)
+
+	w := PerformRequest(router, http.MethodPost, "/users/1")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "/users/:id", template)
+	assert.Empty(t, name)
+
+	PerformRequest(router, http.MethodGet, "/missing")
+	assert.Empty(t, template)
+
+	for _, route := range router.Routes() {
+		if route.Path == "/users/:id" {
+			assert.Equal(t, "users.show", route.Name)
+		}
+	}
+}
+
 func TestEngineHandleMethodNotAllowedCornerCase(t *testing.T) {
 	r := New()
 	r.HandleMethodNotAllowed = true
@@ -0,0 +1,86 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrInvalidRoute is wrapped by the errors of TryHandle: conflicting routes,
+// invalid wildcards, invalid methods...
+var ErrInvalidRoute = errors.New("gin: invalid route")
+
+// TryHandle is like Handle, but returns an error instead of panicking when the
+// route cannot be registered, so that routes coming from user-provided
+// configuration (plugin systems, gateways) can be rejected gracefully. Nothing
+// is registered when an error is returned.
+func (group *RouterGroup) TryHandle(httpMethod, relativePath string, handlers ...HandlerFunc) (IRoutes, error) {
+	absolutePath := group.calculateAbsolutePath(relativePath)
+	if err := group.checkRoute(httpMethod, absolutePath, handlers); err != nil {
+		return group.returnObj(), err
+	}
+	return group.handle(httpMethod, relativePath, handlers), nil
+}
+
+// TryGET is a shortcut for router.TryHandle("GET", path, handlers).
+func (group *RouterGroup) TryGET(relativePath string, handlers ...HandlerFunc) (IRoutes, error) {
+	return group.TryHandle(http.MethodGet, relativePath, handlers...)
+}
+
+// TryPOST is a shortcut for router.TryHandle("POST", path, handlers).
+func (group *RouterGroup) TryPOST(relativePath string, handlers ...HandlerFunc) (IRoutes, error) {
+	return group.TryHandle(http.MethodPost, relativePath, handlers...)
+}
+
+// TryPUT is a shortcut for router.TryHandle("PUT", path, handlers).
+func (group *RouterGroup) TryPUT(relativePath string, handlers ...HandlerFunc) (IRoutes, error) {
+	return group.TryHandle(http.MethodPut, relativePath, handlers...)
+}
+
+// TryPATCH is a shortcut for router.TryHandle("PATCH", path, handlers).
+func (group *RouterGroup) TryPATCH(relativePath string, handlers ...HandlerFunc) (IRoutes, error) {
+	return group.TryHandle(http.MethodPatch, relativePath, handlers...)
+}
+
+// TryDELETE is a shortcut for router.TryHandle("DELETE", path, handlers).
+func (group *RouterGroup) TryDELETE(relativePath string, handlers ...HandlerFunc) (IRoutes, error) {
+	return group.TryHandle(http.MethodDelete, relativePath, handlers...)
+}
+
+// checkRoute returns the error the registration of a route would panic with.
+// The route is inserted in a copy of the nodes of the tree along its path, since
+// the tree may be modified before a conflict is detected.
+func (group *RouterGroup) checkRoute(httpMethod, absolutePath string, handlers HandlersChain) (err error) {
+	invalid := func(reason any) error {
+		return fmt.Errorf("%w %s %s: %v", ErrInvalidRoute, httpMethod, absolutePath, reason)
+	}
+	switch size := len(group.Handlers) + len(handlers); {
+	case !regEnLetter.MatchString(httpMethod):
+		return invalid("http method is not valid")
+	case absolutePath == "" || absolutePath[0] != '/':
+		return invalid("path must begin with '/'")
+	case size == 0:
+		return invalid("there must be at least one handler")
+	case size+len(group.engine.postMatch) >= int(abortIndex):
+		return invalid("too many handlers")
+	case group.engine.frozen != nil:
+		return invalid("routes can not be added after Freeze")
+	}
+
+	root := group.engine.trees.get(httpMethod)
+	if root == nil {
+		// the path itself may be invalid, e.g. with a wildcard without name.
+		root = &node{fullPath: "/"}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = invalid(r)
+		}
+	}()
+	root.clonePath(absolutePath).addRoute(absolutePath, append(group.Handlers[:len(group.Handlers):len(group.Handlers)], handlers...))
+	return nil
+}
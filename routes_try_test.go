@@ -0,0 +1,61 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTryHandle(t *testing.T) {
+	router := New()
+	ok := func(c *Context) { c.String(http.StatusOK, c.FullPath()) }
+	_, err := router.TryGET("/users/:id", ok)
+	require.NoError(t, err)
+	api := router.Group("/api")
+	routes, err := api.TryPOST("/items", ok)
+	require.NoError(t, err)
+	assert.Equal(t, api, routes)
+
+	for _, tc := range []struct {
+		method, path string
+		handlers     []HandlerFunc
+	}{
+		{http.MethodGet, "/users/:name", []HandlerFunc{ok}},
+		{http.MethodGet, "/users/:id", []HandlerFunc{ok}},
+		{http.MethodGet, "/files/*path/more", []HandlerFunc{ok}},
+		{http.MethodGet, "/users/:", []HandlerFunc{ok}},
+		{http.MethodOptions, "/files/*", []HandlerFunc{ok}},
+		{"get", "/lower", []HandlerFunc{ok}},
+		{http.MethodGet, "/none", nil},
+		{http.MethodGet, "/many", make([]HandlerFunc, abortIndex)},
+	} {
+		_, err := router.TryHandle(tc.method, tc.path, tc.handlers...)
+		assert.ErrorIs(t, err, ErrInvalidRoute, "%s %s", tc.method, tc.path)
+	}
+
+	// the rejected routes left the tree unchanged.
+	assert.Len(t, router.Routes(), 2)
+	w := PerformRequest(router, http.MethodGet, "/users/1")
+	assert.Equal(t, "/users/:id", w.Body.String())
+	w = PerformRequest(router, http.MethodPost, "/api/items")
+	assert.Equal(t, "/api/items", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "/users/")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	for _, try := range []func(string, ...HandlerFunc) (IRoutes, error){router.TryPUT, router.TryPATCH, router.TryDELETE} {
+		_, err = try("/users/:id", ok)
+		assert.NoError(t, err)
+	}
+
+	router.Freeze()
+	assert.NotPanics(t, func() {
+		_, err = router.TryGET("/frozen", ok)
+	})
+	assert.ErrorIs(t, err, ErrInvalidRoute)
+}
@@ -0,0 +1,336 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverlapPolicy defines what a scheduled job does when its previous run is not
+// over at the time of the next one.
+type OverlapPolicy int
+
+const (
+	// SkipIfRunning skips the runs which would overlap the previous one.
+	SkipIfRunning OverlapPolicy = iota
+	// AllowOverlap starts every run, even when the previous one is not over.
+	AllowOverlap
+)
+
+// ScheduleConfig defines the config for Engine.ScheduleWithConfig.
+type ScheduleConfig struct {
+	// Optional. Default value is 0: the job starts at the scheduled time.
+	// Each run is delayed by a random duration up to Jitter, to spread the load
+	// of several instances of a service.
+	Jitter time.Duration
+
+	// Optional. Default value is SkipIfRunning.
+	Overlap OverlapPolicy
+
+	// Optional. Default value is time.Local. The location in which the cron
+	// expression is evaluated.
+	Location *time.Location
+}
+
+// Schedule runs job periodically, at the times given by spec, until
+// Engine.Shutdown. See ScheduleWithConfig.
+func (engine *Engine) Schedule(spec string, job func(ctx context.Context)) error {
+	return engine.ScheduleWithConfig(spec, job, ScheduleConfig{})
+}
+
+// ScheduleWithConfig runs job periodically, at the times given by spec, until
+// Engine.Shutdown, which waits for the runs in progress and cancels their
+// context when its own is done. spec is a cron expression of five fields
+// (minute, hour, day of month, month, day of week) supporting lists, ranges,
+// steps and names, or one of the descriptors @yearly (@annually), @monthly,
+// @weekly, @daily (@midnight), @hourly and "@every <duration>":
+//
+//	router.Schedule("*/15 * * * *", purgeExpiredSessions)
+//	router.Schedule("0 3 * * mon-fri", rebuildSearchIndex)
+//	router.Schedule("@every 30s", refreshExchangeRates)
+//
+// The panics of job are recovered and logged to DefaultErrorWriter.
+func (engine *Engine) ScheduleWithConfig(spec string, job func(ctx context.Context), config ScheduleConfig) error {
+	sched, err := parseSchedule(spec)
+	if err != nil {
+		return err
+	}
+	if config.Location == nil {
+		config.Location = time.Local
+	}
+	engine.schedules.start(sched, job, config)
+	return nil
+}
+
+// scheduler runs the jobs of Engine.Schedule.
+type scheduler struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	loops  sync.WaitGroup
+	jobs   sync.WaitGroup
+}
+
+func (s *scheduler) start(sched schedule, job func(context.Context), config ScheduleConfig) {
+	s.mu.Lock()
+	if s.stop == nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+		s.stop = make(chan struct{})
+	}
+	ctx, stop := s.ctx, s.stop
+	s.loops.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		defer s.loops.Done()
+		var running atomic.Bool
+		for {
+			next := sched.next(time.Now().In(config.Location))
+			if next.IsZero() {
+				return
+			}
+			delay := time.Until(next)
+			if config.Jitter > 0 {
+				delay += time.Duration(rand.Int63n(int64(config.Jitter)))
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			if config.Overlap == SkipIfRunning && !running.CompareAndSwap(false, true) {
+				continue
+			}
+			s.jobs.Add(1)
+			go func() {
+				defer s.jobs.Done()
+				if config.Overlap == SkipIfRunning {
+					defer running.Store(false)
+				}
+				defer recoverJob("scheduled job")
+				job(ctx)
+			}()
+		}
+	}()
+}
+
+// shutdown stops scheduling the jobs and waits for the runs in progress until
+// ctx is done, then cancels their context.
+func (s *scheduler) shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop == nil {
+		return nil
+	}
+	close(s.stop)
+	s.stop = nil
+	s.loops.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		s.jobs.Wait()
+		close(done)
+	}()
+	defer s.cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// schedule returns the next activation time, later than t, or the zero time
+// when there is none.
+type schedule interface {
+	next(t time.Time) time.Time
+}
+
+type everySchedule time.Duration
+
+func (d everySchedule) next(t time.Time) time.Time {
+	return t.Add(time.Duration(d))
+}
+
+// cronSchedule is a cron expression: each field is a bit set of the values it
+// matches.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are set for a "*" day of month or day of week: the
+	// day must then match both fields, instead of either.
+	domStar, dowStar bool
+}
+
+var scheduleDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = [5]cronField{
+	{"minute", 0, 59, nil},
+	{"hour", 0, 23, nil},
+	{"day of month", 1, 31, nil},
+	{"month", 1, 12, []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{"day of week", 0, 7, []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+func parseSchedule(spec string) (schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if every, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(every))
+		if err != nil {
+			return nil, fmt.Errorf("gin: invalid schedule %q: %w", spec, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("gin: invalid schedule %q: the duration must be positive", spec)
+		}
+		return everySchedule(d), nil
+	}
+	if expr, ok := scheduleDescriptors[spec]; ok {
+		spec = expr
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("gin: invalid schedule %q: expected 5 fields, got %d", spec, len(fields))
+	}
+	var bits [5]uint64
+	for i, field := range fields {
+		b, err := cronFields[i].parse(field)
+		if err != nil {
+			return nil, fmt.Errorf("gin: invalid schedule %q: %w", spec, err)
+		}
+		bits[i] = b
+	}
+	// 7 is also Sunday.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return &cronSchedule{
+		minute: bits[0], hour: bits[1], dom: bits[2], month: bits[3], dow: bits[4],
+		domStar: fields[2] == "*", dowStar: fields[4] == "*",
+	}, nil
+}
+
+// parse returns the bit set of the values of a comma-separated list of "*",
+// values, and ranges, each with an optional step.
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		expr, stepExpr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepExpr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepExpr, f.name)
+			}
+		}
+
+		lo, hi := f.min, f.max
+		if expr != "*" {
+			loExpr, hiExpr, isRange := strings.Cut(expr, "-")
+			var err error
+			if lo, err = f.value(loExpr); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = f.value(hiExpr); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("invalid range %q in %s", expr, f.name)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if name != "" && strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s", s, f.name)
+	}
+	return v, nil
+}
+
+func (s *cronSchedule) next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// a schedule which never matches, e.g. on February 30th, gives up.
+	yearLimit := t.Year() + 5
+
+search:
+	for t.Year() <= yearLimit {
+		for s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			if t.Month() == time.January {
+				continue search
+			}
+		}
+		for !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			if t.Day() == 1 {
+				continue search
+			}
+		}
+		for s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			if t.Hour() == 0 {
+				continue search
+			}
+		}
+		for s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			if t.Minute() == 0 {
+				continue search
+			}
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// Monday
+	now := time.Date(2023, 5, 15, 10, 17, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2023, 5, 15, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2023, 5, 15, 10, 30, 0, 0, time.UTC)},
+		{"5,45 9-11 * * *", time.Date(2023, 5, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 3 * * mon-fri", time.Date(2023, 5, 16, 3, 0, 0, 0, time.UTC)},
+		{"0 0 * * SAT,7", time.Date(2023, 5, 20, 0, 0, 0, 0, time.UTC)},
+		{"30 12 1 jan *", time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC)},
+		{"0 0 13 * fri", time.Date(2023, 5, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+		{"@hourly", time.Date(2023, 5, 15, 11, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2023, 5, 21, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", now.Add(90 * time.Second)},
+	}
+	for _, tt := range tests {
+		sched, err := parseSchedule(tt.spec)
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.next, sched.next(now), tt.spec)
+	}
+}
+
+func TestScheduleInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *",
+		"*/0 * * * *", "5-1 * * * *", "* * * foo *", "@every", "@every -1s", "@sometimes",
+	} {
+		assert.Error(t, New().Schedule(spec, func(context.Context) {}), spec)
+	}
+}
+
+func TestScheduleRunsUntilShutdown(t *testing.T) {
+	router := New()
+	var runs atomic.Int32
+	require.NoError(t, router.Schedule("@every 5ms", func(ctx context.Context) {
+		runs.Add(1)
+	}))
+	require.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+
+	require.NoError(t, router.Shutdown(context.Background()))
+	n := runs.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, n, runs.Load())
+}
+
+func TestScheduleOverlap(t *testing.T) {
+	router := New()
+	var started atomic.Int32
+	job := func(ctx context.Context) {
+		started.Add(1)
+		<-ctx.Done()
+	}
+	require.NoError(t, router.Schedule("@every 2ms", job))
+	time.Sleep(30 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, router.Shutdown(ctx), context.DeadlineExceeded)
+	assert.Equal(t, int32(1), started.Load())
+
+	router = New()
+	started.Store(0)
+	require.NoError(t, router.ScheduleWithConfig("@every 2ms", job, ScheduleConfig{Overlap: AllowOverlap, Jitter: time.Millisecond}))
+	require.Eventually(t, func() bool { return started.Load() >= 3 }, time.Second, time.Millisecond)
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, router.Shutdown(ctx), context.DeadlineExceeded)
+}
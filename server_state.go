@@ -0,0 +1,180 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// ServerPhase is the lifecycle phase of the servers run by an engine.
+type ServerPhase string
+
+const (
+	// ServerStarting is the phase before a server is listening.
+	ServerStarting ServerPhase = "starting"
+	// ServerServing is the phase while at least one server is accepting connections.
+	ServerServing ServerPhase = "serving"
+	// ServerDraining is the phase between Shutdown and the end of the in-flight requests.
+	ServerDraining ServerPhase = "draining"
+	// ServerStopped is the phase once all the servers are closed.
+	ServerStopped ServerPhase = "stopped"
+)
+
+var serverPhases = [...]ServerPhase{ServerStarting, ServerServing, ServerDraining, ServerStopped}
+
+const (
+	phaseStarting int32 = iota
+	phaseServing
+	phaseDraining
+	phaseStopped
+)
+
+// ServerState reports the lifecycle of the servers run by an engine, see Engine.State.
+type ServerState struct {
+	// Phase is the lifecycle phase.
+	Phase ServerPhase `json:"phase"`
+	// Accepting is true while new connections are accepted, i.e. in the serving phase.
+	Accepting bool `json:"accepting"`
+	// Ready is true while accepting connections, once the warmup functions have
+	// run, see Engine.Warmup.
+	Ready bool `json:"ready"`
+	// Servers is the number of running servers.
+	Servers int `json:"servers"`
+	// ActiveConnections is the number of connections handling a request.
+	ActiveConnections int `json:"active_connections"`
+	// IdleConnections is the number of open connections waiting for a request.
+	IdleConnections int `json:"idle_connections"`
+}
+
+type serverTracker struct {
+	phase   atomic.Int32
+	mu      sync.Mutex
+	servers map[*http.Server]struct{}
+	conns   map[net.Conn]http.ConnState
+}
+
+// State returns the lifecycle phase of the servers started by the Run methods or
+// registered with TrackServer, and their connection counts, so that health
+// checks and orchestration hooks can tell a draining or warming up server apart.
+func (engine *Engine) State() ServerState {
+	t := &engine.servers
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	phase := t.phase.Load()
+	state := ServerState{
+		Phase:     serverPhases[phase],
+		Accepting: phase == phaseServing,
+		Servers:   len(t.servers),
+	}
+	state.Ready = state.Accepting && engine.warmedUp()
+	for _, s := range t.conns {
+		if s == http.StateIdle {
+			state.IdleConnections++
+		} else {
+			state.ActiveConnections++
+		}
+	}
+	return state
+}
+
+// TrackServer registers a server configured outside of gin, so that State
+// counts its connections and Shutdown drains it. Call it right before srv.Serve,
+// after RunWarmup; the engine is then in the serving phase. An existing srv.ConnState hook is kept.
+func (engine *Engine) TrackServer(srv *http.Server) {
+	t := &engine.servers
+	connState := srv.ConnState
+	srv.ConnState = func(conn net.Conn, state http.ConnState) {
+		t.mu.Lock()
+		switch state {
+		case http.StateHijacked, http.StateClosed:
+			delete(t.conns, conn)
+		default:
+			t.conns[conn] = state
+		}
+		t.mu.Unlock()
+		if connState != nil {
+			connState(conn, state)
+		}
+	}
+	t.mu.Lock()
+	if t.servers == nil {
+		t.servers = make(map[*http.Server]struct{})
+		t.conns = make(map[net.Conn]http.ConnState)
+	}
+	t.servers[srv] = struct{}{}
+	t.phase.CompareAndSwap(phaseStarting, phaseServing)
+	t.phase.CompareAndSwap(phaseStopped, phaseServing)
+	t.mu.Unlock()
+}
+
+// untrackServer removes a server whose Serve method returned.
+func (engine *Engine) untrackServer(srv *http.Server) {
+	t := &engine.servers
+	t.mu.Lock()
+	delete(t.servers, srv)
+	if len(t.servers) == 0 && t.phase.Load() == phaseServing {
+		t.phase.Store(phaseStopped)
+	}
+	t.mu.Unlock()
+}
+
+// Shutdown gracefully stops the servers started by the Run methods or
+// registered with TrackServer: it stops accepting connections, then waits for
+// the in-flight requests, the scheduled jobs in progress (see Engine.Schedule)
+// and the jobs of Context.Defer until ctx is done, see http.Server.Shutdown.
+// The Run methods return http.ErrServerClosed.
+func (engine *Engine) Shutdown(ctx context.Context) error {
+	t := &engine.servers
+	t.mu.Lock()
+	t.phase.Store(phaseDraining)
+	servers := make([]*http.Server, 0, len(t.servers))
+	for srv := range t.servers {
+		servers = append(servers, srv)
+	}
+	t.mu.Unlock()
+
+	var errs []error
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := engine.schedules.shutdown(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := engine.background.drain(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	t.phase.Store(phaseStopped)
+	return errors.Join(errs...)
+}
+
+// serve serves on listener with a server tracked for State and Shutdown.
+func (engine *Engine) serve(listener net.Listener, serve func(*http.Server, net.Listener) error) error {
+	if engine.MaxConnections > 0 || engine.MaxConnectionsPerIP > 0 {
+		listener = NewLimitListener(listener, ConnLimitConfig{
+			MaxConnections:      engine.MaxConnections,
+			MaxConnectionsPerIP: engine.MaxConnectionsPerIP,
+			Reject:              engine.ConnectionReject,
+		})
+	}
+	if err := engine.RunWarmup(context.Background()); err != nil {
+		listener.Close()
+		return err
+	}
+	srv := &http.Server{Handler: engine.Handler()}
+	engine.TrackServer(srv)
+	defer engine.untrackServer(srv)
+	return serve(srv, listener)
+}
+
+func serveHTTP(srv *http.Server, listener net.Listener) error {
+	return srv.Serve(listener)
+}
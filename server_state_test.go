@@ -0,0 +1,80 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineStateAndShutdown(t *testing.T) {
+	router := New()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	router.GET("/slow", func(c *Context) {
+		close(started)
+		<-release
+		c.String(http.StatusOK, "done")
+	})
+	assert.Equal(t, ServerState{Phase: ServerStarting}, router.State())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	runErr := make(chan error, 1)
+	go func() { runErr <- router.RunListener(listener) }()
+	assert.Eventually(t, func() bool { return router.State().Accepting }, time.Second, time.Millisecond)
+
+	body := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if !assert.NoError(t, err) {
+			body <- ""
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		body <- string(b)
+	}()
+	<-started
+	state := router.State()
+	assert.Equal(t, ServerServing, state.Phase)
+	assert.Equal(t, 1, state.Servers)
+	assert.Equal(t, 1, state.ActiveConnections)
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- router.Shutdown(context.Background()) }()
+	assert.Eventually(t, func() bool { return router.State().Phase == ServerDraining }, time.Second, time.Millisecond)
+	assert.False(t, router.State().Accepting)
+	assert.Equal(t, http.ErrServerClosed, <-runErr)
+
+	close(release)
+	assert.Equal(t, "done", <-body)
+	assert.NoError(t, <-shutdown)
+	state = router.State()
+	assert.Equal(t, ServerStopped, state.Phase)
+	assert.Zero(t, state.ActiveConnections+state.IdleConnections)
+}
+
+func TestEngineTrackServerKeepsConnState(t *testing.T) {
+	router := New()
+	states := make(chan http.ConnState, 8)
+	srv := &http.Server{Handler: router, ConnState: func(_ net.Conn, s http.ConnState) { states <- s }}
+	router.TrackServer(srv)
+	assert.Equal(t, ServerServing, router.State().Phase)
+
+	srv.ConnState(&net.TCPConn{}, http.StateNew)
+	assert.Equal(t, http.StateNew, <-states)
+	assert.Equal(t, 1, router.State().ActiveConnections)
+
+	router.untrackServer(srv)
+	assert.Equal(t, ServerStopped, router.State().Phase)
+}
@@ -0,0 +1,308 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package serverless runs a gin router in AWS Lambda, behind an API Gateway
+// REST API (payload version 1.0), an HTTP API (payload version 2.0) or an
+// Application Load Balancer, so that the same router runs in Lambda and on a
+// server:
+//
+//	func main() {
+//		router := gin.New()
+//		router.GET("/users/:id", getUser)
+//		lambda.Start(serverless.Handler(router))
+//	}
+//
+// The events are translated to http.Request, and the responses to the format
+// of the event source, with the binary bodies encoded in base64 and the
+// multi-value headers.
+package serverless
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrUnknownEvent is returned for the events which are not HTTP events of API
+// Gateway or of an Application Load Balancer.
+var ErrUnknownEvent = errors.New("serverless: unknown event")
+
+// event holds the fields of the API Gateway v1 and v2 and ALB events.
+type event struct {
+	Version string `json:"version"`
+
+	// v1 and ALB
+	HTTPMethod                      string              `json:"httpMethod"`
+	Path                            string              `json:"path"`
+	QueryStringParameters           map[string]string   `json:"queryStringParameters"`
+	MultiValueQueryStringParameters map[string][]string `json:"multiValueQueryStringParameters"`
+	MultiValueHeaders               map[string][]string `json:"multiValueHeaders"`
+
+	// v2
+	RawPath        string   `json:"rawPath"`
+	RawQueryString string   `json:"rawQueryString"`
+	Cookies        []string `json:"cookies"`
+
+	Headers         map[string]string `json:"headers"`
+	Body            string            `json:"body"`
+	IsBase64Encoded bool              `json:"isBase64Encoded"`
+
+	RequestContext struct {
+		HTTP struct {
+			Method   string `json:"method"`
+			SourceIP string `json:"sourceIp"`
+		} `json:"http"`
+		Identity struct {
+			SourceIP string `json:"sourceIp"`
+		} `json:"identity"`
+		ELB *struct {
+			TargetGroupArn string `json:"targetGroupArn"`
+		} `json:"elb"`
+	} `json:"requestContext"`
+}
+
+type eventSource int
+
+const (
+	sourceAPIGatewayV1 eventSource = iota
+	sourceAPIGatewayV2
+	sourceALB
+)
+
+func (e *event) source() eventSource {
+	switch {
+	case e.Version == "2.0":
+		return sourceAPIGatewayV2
+	case e.RequestContext.ELB != nil:
+		return sourceALB
+	default:
+		return sourceAPIGatewayV1
+	}
+}
+
+// response is the response of the three event sources, whose fields are set
+// according to the source.
+type response struct {
+	StatusCode        int                 `json:"statusCode"`
+	StatusDescription string              `json:"statusDescription,omitempty"`
+	Headers           map[string]string   `json:"headers,omitempty"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders,omitempty"`
+	Cookies           []string            `json:"cookies,omitempty"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
+
+type eventKey struct{}
+
+// Event returns the raw event of the request, e.g. to read the claims of the
+// API Gateway authorizer from its requestContext.
+func Event(req *http.Request) (json.RawMessage, bool) {
+	raw, ok := req.Context().Value(eventKey{}).(json.RawMessage)
+	return raw, ok
+}
+
+// Handler returns the Lambda handler serving the HTTP events with engine, to
+// give to lambda.Start. The warmup functions of engine run with the first
+// event, and again with the next ones while they fail, see gin.Engine.Warmup.
+func Handler(engine *gin.Engine) func(ctx context.Context, raw json.RawMessage) (any, error) {
+	return func(ctx context.Context, raw json.RawMessage) (any, error) {
+		if err := engine.RunWarmup(ctx); err != nil {
+			return nil, err
+		}
+		var e event
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return nil, err
+		}
+		req, err := newRequest(context.WithValue(ctx, eventKey{}, raw), &e)
+		if err != nil {
+			return nil, err
+		}
+		w := newResponseWriter()
+		engine.ServeHTTP(w, req)
+		return w.response(e.source(), e.MultiValueHeaders != nil), nil
+	}
+}
+
+// newRequest translates e to a http.Request.
+func newRequest(ctx context.Context, e *event) (*http.Request, error) {
+	var method, path, rawQuery, sourceIP string
+	header := make(http.Header)
+	switch e.source() {
+	case sourceAPIGatewayV2:
+		method, path, rawQuery = e.RequestContext.HTTP.Method, e.RawPath, e.RawQueryString
+		sourceIP = e.RequestContext.HTTP.SourceIP
+		if len(e.Cookies) > 0 {
+			header.Set("Cookie", strings.Join(e.Cookies, "; "))
+		}
+	case sourceALB:
+		method, path = e.HTTPMethod, e.Path
+		// the values of the query parameters of the ALB events are not decoded.
+		var parts []string
+		for name, values := range e.MultiValueQueryStringParameters {
+			for _, value := range values {
+				parts = append(parts, name+"="+value)
+			}
+		}
+		if e.MultiValueQueryStringParameters == nil {
+			for name, value := range e.QueryStringParameters {
+				parts = append(parts, name+"="+value)
+			}
+		}
+		rawQuery = strings.Join(parts, "&")
+	default:
+		method, path = e.HTTPMethod, e.Path
+		sourceIP = e.RequestContext.Identity.SourceIP
+		query := url.Values(e.MultiValueQueryStringParameters)
+		if query == nil {
+			query = make(url.Values, len(e.QueryStringParameters))
+			for name, value := range e.QueryStringParameters {
+				query.Set(name, value)
+			}
+		}
+		rawQuery = query.Encode()
+	}
+	if method == "" {
+		return nil, ErrUnknownEvent
+	}
+
+	for name, values := range e.MultiValueHeaders {
+		for _, value := range values {
+			header.Add(name, value)
+		}
+	}
+	if e.MultiValueHeaders == nil {
+		for name, value := range e.Headers {
+			header.Set(name, value)
+		}
+	}
+
+	body := []byte(e.Body)
+	if e.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(e.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = decoded
+	}
+
+	u := &url.URL{Path: path, RawQuery: rawQuery}
+	req, err := http.NewRequestWithContext(ctx, method, u.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	req.Host = header.Get("Host")
+	req.RequestURI = u.RequestURI()
+	if sourceIP == "" {
+		// behind an ALB, the client is given by X-Forwarded-For.
+		sourceIP = "127.0.0.1"
+	}
+	req.RemoteAddr = net.JoinHostPort(sourceIP, "0")
+	if header.Get("X-Forwarded-Proto") == "https" {
+		req.URL.Scheme = "https"
+	}
+	return req, nil
+}
+
+// responseWriter buffers the response of the engine.
+type responseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseWriter() *responseWriter {
+	return &responseWriter{header: make(http.Header)}
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(data)
+}
+
+// Flush does nothing: the responses of Lambda are not streamed.
+func (w *responseWriter) Flush() {}
+
+// response returns the response in the format of source, with multi-value
+// headers if multiValue.
+func (w *responseWriter) response(source eventSource, multiValue bool) *response {
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	resp := &response{StatusCode: status}
+	if textContent(w.header) {
+		resp.Body = w.body.String()
+	} else {
+		resp.Body = base64.StdEncoding.EncodeToString(w.body.Bytes())
+		resp.IsBase64Encoded = true
+	}
+
+	switch {
+	case source == sourceAPIGatewayV2:
+		resp.Cookies = w.header.Values("Set-Cookie")
+		resp.Headers = make(map[string]string, len(w.header))
+		for name, values := range w.header {
+			if name != "Set-Cookie" {
+				resp.Headers[name] = strings.Join(values, ",")
+			}
+		}
+	case source == sourceAPIGatewayV1 || multiValue:
+		resp.MultiValueHeaders = w.header
+	default:
+		resp.Headers = make(map[string]string, len(w.header))
+		for name, values := range w.header {
+			resp.Headers[name] = values[len(values)-1]
+		}
+	}
+	if source == sourceALB {
+		resp.StatusDescription = strconv.Itoa(status) + " " + http.StatusText(status)
+	}
+	return resp
+}
+
+// textContent reports whether the body of a response with header is text,
+// which is not encoded in base64.
+func textContent(header http.Header) bool {
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"),
+		mediaType == "application/json", mediaType == "application/xml",
+		mediaType == "application/javascript", mediaType == "application/x-www-form-urlencoded",
+		mediaType == "image/svg+xml":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package serverless
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func newRouter() *gin.Engine {
+	router := gin.New()
+	router.POST("/users/:id", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Header("X-Tag", "a")
+		c.Writer.Header().Add("X-Tag", "b")
+		c.SetCookie("session", "s1", 0, "/", "", false, true)
+		c.SetCookie("theme", "dark", 0, "/", "", false, false)
+		cookie, _ := c.Cookie("lang")
+		c.String(http.StatusCreated, "%s %s %s %s %s %s", c.Param("id"), strings.Join(c.QueryArray("q"),
+			","), body, c.GetHeader("Accept"), cookie, c.ClientIP())
+	})
+	router.GET("/logo.png", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", []byte{0x89, 'P', 'N', 'G'})
+	})
+	router.GET("/event", func(c *gin.Context) {
+		raw, ok := Event(c.Request)
+		var e struct {
+			RequestContext struct {
+				Stage string `json:"stage"`
+			} `json:"requestContext"`
+		}
+		_ = json.Unmarshal(raw, &e)
+		c.String(http.StatusOK, "%t %s", ok, e.RequestContext.Stage)
+	})
+	return router
+}
+
+func invoke(t *testing.T, router *gin.Engine, e string) *response {
+	out, err := Handler(router)(context.Background(), json.RawMessage(e))
+	require.NoError(t, err)
+	return out.(*response)
+}
+
+func TestAPIGatewayV1(t *testing.T) {
+	resp := invoke(t, newRouter(), `{
+		"resource": "/{proxy+}",
+		"path": "/users/42",
+		"httpMethod": "POST",
+		"headers": {"Accept": "text/plain", "Cookie": "lang=fr"},
+		"multiValueHeaders": {"Accept": ["text/plain"], "Cookie": ["lang=fr"]},
+		"queryStringParameters": {"q": "b"},
+		"multiValueQueryStringParameters": {"q": ["a b", "b"]},
+		"requestContext": {"stage": "prod", "identity": {"sourceIp": "203.0.113.7"}},
+		"body": "aGVsbG8=",
+		"isBase64Encoded": true
+	}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "42 a b,b hello text/plain fr 203.0.113.7", resp.Body)
+	assert.False(t, resp.IsBase64Encoded)
+	assert.Empty(t, resp.StatusDescription)
+	assert.Equal(t, []string{"a", "b"}, resp.MultiValueHeaders["X-Tag"])
+	assert.Len(t, resp.MultiValueHeaders["Set-Cookie"], 2)
+	assert.Equal(t, []string{"text/plain; charset=utf-8"}, resp.MultiValueHeaders["Content-Type"])
+}
+
+func TestAPIGatewayV1SingleValues(t *testing.T) {
+	resp := invoke(t, newRouter(), `{
+		"path": "/users/7",
+		"httpMethod": "POST",
+		"headers": {"Accept": "*/*"},
+		"queryStringParameters": {"q": "a&b"},
+		"requestContext": {"identity": {"sourceIp": "198.51.100.1"}},
+		"body": "hi"
+	}`)
+	assert.Equal(t, "7 a&b hi */*  198.51.100.1", resp.Body)
+}
+
+func TestAPIGatewayV2(t *testing.T) {
+	resp := invoke(t, newRouter(), `{
+		"version": "2.0",
+		"routeKey": "$default",
+		"rawPath": "/users/42",
+		"rawQueryString": "q=a%20b&q=b",
+		"cookies": ["lang=fr", "other=1"],
+		"headers": {"accept": "text/plain"},
+		"requestContext": {"http": {"method": "POST", "path": "/users/42", "sourceIp": "203.0.113.7"}},
+		"body": "hello",
+		"isBase64Encoded": false
+	}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "42 a b,b hello text/plain fr 203.0.113.7", resp.Body)
+	assert.Equal(t, "a,b", resp.Headers["X-Tag"])
+	assert.NotContains(t, resp.Headers, "Set-Cookie")
+	assert.Equal(t, []string{"session=s1; Path=/; HttpOnly", "theme=dark; Path=/"}, resp.Cookies)
+	assert.Nil(t, resp.MultiValueHeaders)
+}
+
+func TestALB(t *testing.T) {
+	resp := invoke(t, newRouter(), `{
+		"requestContext": {"elb": {"targetGroupArn": "arn:aws:elasticloadbalancing:region:123456789012:targetgroup/my-target-group/6d0ecf831eec9f09"}},
+		"httpMethod": "POST",
+		"path": "/users/42",
+		"queryStringParameters": {"q": "a%20b"},
+		"headers": {"accept": "text/plain", "cookie": "lang=fr", "x-forwarded-for": "203.0.113.7"},
+		"body": "hello",
+		"isBase64Encoded": false
+	}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "201 Created", resp.StatusDescription)
+	assert.Equal(t, "42 a b hello text/plain fr 203.0.113.7", resp.Body)
+	assert.Equal(t, "b", resp.Headers["X-Tag"])
+	assert.Nil(t, resp.MultiValueHeaders)
+}
+
+func TestALBMultiValueHeaders(t *testing.T) {
+	resp := invoke(t, newRouter(), `{
+		"requestContext": {"elb": {"targetGroupArn": "arn"}},
+		"httpMethod": "POST",
+		"path": "/users/42",
+		"multiValueQueryStringParameters": {"q": ["a%20b", "b"]},
+		"multiValueHeaders": {"accept": ["text/plain"], "x-forwarded-for": ["203.0.113.7"]},
+		"body": "aGVsbG8=",
+		"isBase64Encoded": true
+	}`)
+	assert.Equal(t, "42 a b,b hello text/plain  203.0.113.7", resp.Body)
+	assert.Equal(t, []string{"a", "b"}, resp.MultiValueHeaders["X-Tag"])
+	assert.Nil(t, resp.Headers)
+}
+
+func TestBinaryResponse(t *testing.T) {
+	resp := invoke(t, newRouter(), `{"version": "2.0", "rawPath": "/logo.png", "requestContext": {"http": {"method": "GET"}}}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, resp.IsBase64Encoded)
+	body, err := base64.StdEncoding.DecodeString(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x89, 'P', 'N', 'G'}, body)
+}
+
+func TestEvent(t *testing.T) {
+	resp := invoke(t, newRouter(), `{"path": "/event", "httpMethod": "GET", "requestContext": {"stage": "prod"}}`)
+	assert.Equal(t, "true prod", resp.Body)
+}
+
+func TestNotFound(t *testing.T) {
+	resp := invoke(t, newRouter(), `{"version": "2.0", "rawPath": "/missing", "requestContext": {"http": {"method": "GET"}}}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "404 page not found", resp.Body)
+}
+
+func TestHandlerErrors(t *testing.T) {
+	handler := Handler(newRouter())
+	_, err := handler(context.Background(), json.RawMessage(`{"source": "aws.events"}`))
+	assert.ErrorIs(t, err, ErrUnknownEvent)
+	_, err = handler(context.Background(), json.RawMessage(`{"path": "/", "httpMethod": "GET", "body": "!", "isBase64Encoded": true}`))
+	assert.Error(t, err)
+	_, err = handler(context.Background(), json.RawMessage(`[]`))
+	assert.Error(t, err)
+}
+
+func TestHandlerWarmup(t *testing.T) {
+	router := newRouter()
+	calls := 0
+	router.Warmup(func(context.Context) error {
+		calls++
+		if calls == 1 {
+			return errors.New("cache unavailable")
+		}
+		return nil
+	})
+	handler := Handler(router)
+	e := json.RawMessage(`{"path": "/event", "httpMethod": "GET"}`)
+	_, err := handler(context.Background(), e)
+	assert.ErrorContains(t, err, "cache unavailable")
+	_, err = handler(context.Background(), e)
+	assert.NoError(t, err)
+	_, err = handler(context.Background(), e)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestTextContent(t *testing.T) {
+	for contentType, text := range map[string]bool{
+		"":                                  true,
+		"text/html; charset=utf-8":          true,
+		"application/json":                  true,
+		"application/problem+json":          true,
+		"application/atom+xml":              true,
+		"image/svg+xml":                     true,
+		"application/x-www-form-urlencoded": true,
+		"application/octet-stream":          false,
+		"image/png":                         false,
+		"invalid;;":                         false,
+	} {
+		header := http.Header{}
+		if contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		assert.Equal(t, text, textContent(header), contentType)
+	}
+	assert.False(t, textContent(http.Header{"Content-Type": {"text/plain"}, "Content-Encoding": {"gzip"}}))
+}
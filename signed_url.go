@@ -0,0 +1,161 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	signedURLExpires   = "expires"
+	signedURLSignature = "signature"
+)
+
+var (
+	// ErrNoURLKeys is returned by the signed URL helpers when
+	// Engine.SetURLKeys was not called.
+	ErrNoURLKeys = errors.New("no URL keys are configured, use SetURLKeys")
+
+	// ErrInvalidSignedURL is the error of the requests rejected by
+	// VerifySignedURL whose signature is missing or does not match.
+	ErrInvalidSignedURL = errors.New("invalid URL signature")
+
+	// ErrSignedURLExpired is the error of the requests rejected by
+	// VerifySignedURL whose URL expired.
+	ErrSignedURLExpired = errors.New("signed URL expired")
+)
+
+// SetURLKeys sets the key ring of the signed URLs, see SignURL. The first key
+// signs new URLs, all of them are accepted by VerifySignedURL, so that keys can
+// be rotated by prepending the new key and dropping the oldest one once the
+// URLs it signed expired. Keys should be at least 32 random bytes.
+func (engine *Engine) SetURLKeys(keys ...[]byte) error {
+	ring := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		if len(key) == 0 {
+			return errors.New("empty URL key")
+		}
+		ring = append(ring, deriveCookieKey(key, "gin signed URL"))
+	}
+	engine.urlKeys = ring
+	return nil
+}
+
+// SignURL returns rawURL, a path with an optional query, signed for GET and
+// HEAD requests, e.g. a temporary download link, valid for expiry. The claims
+// are added to the query and covered by the signature, for the handler to
+// trust them:
+//
+//	link, err := router.SignURL("/downloads/report.pdf", time.Hour, map[string]string{"user": "42"})
+//	// "/downloads/report.pdf?expires=1700000000&signature=...&user=42"
+//
+// See VerifySignedURL.
+func (engine *Engine) SignURL(rawURL string, expiry time.Duration, claims map[string]string) (string, error) {
+	return engine.SignURLMethod(http.MethodGet, rawURL, expiry, claims)
+}
+
+// SignURLMethod works like SignURL, but signs rawURL for the requests of
+// method, e.g. POST for a webhook callback.
+func (engine *Engine) SignURLMethod(method, rawURL string, expiry time.Duration, claims map[string]string) (string, error) {
+	return engine.signURL(method, rawURL, time.Now().Add(expiry), claims)
+}
+
+// SignRouteURL returns the URL of the route named name, see RouteURL, signed
+// for GET and HEAD requests and valid for expiry. The values of params which
+// are not route parameters are added as query string, covered by the
+// signature.
+func (engine *Engine) SignRouteURL(name string, params map[string]string, expiry time.Duration) (string, error) {
+	rawURL, err := engine.RouteURL(name, params)
+	if err != nil {
+		return "", err
+	}
+	return engine.SignURL(rawURL, expiry, nil)
+}
+
+func (engine *Engine) signURL(method, rawURL string, expires time.Time, claims map[string]string) (string, error) {
+	if len(engine.urlKeys) == 0 {
+		return "", ErrNoURLKeys
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	for name, value := range claims {
+		query.Set(name, value)
+	}
+	query.Set(signedURLExpires, strconv.FormatInt(expires.Unix(), 10))
+	query.Set(signedURLSignature, signURL(engine.urlKeys[0], method, u.EscapedPath(), query))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// signURL returns the signature of the request of method to path with query,
+// whose signature parameter is ignored.
+func signURL(key []byte, method, path string, query url.Values) string {
+	unsigned := make(url.Values, len(query))
+	for name, values := range query {
+		if name != signedURLSignature {
+			unsigned[name] = values
+		}
+	}
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(method + "\n" + path + "\n" + unsigned.Encode()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignedURL returns a middleware which rejects with 403 Forbidden the
+// requests whose URL was not signed by SignURL, SignURLMethod or SignRouteURL
+// with one of the keys of the engine, or was changed, or expired. The error,
+// ErrInvalidSignedURL or ErrSignedURLExpired, is added to the errors of the
+// context as a public error.
+//
+//	router.GET("/downloads/*file", gin.VerifySignedURL(), download)
+func VerifySignedURL() HandlerFunc {
+	return func(c *Context) {
+		if err := c.verifySignedURL(); err != nil {
+			_ = c.Error(err).SetType(ErrorTypePublic)
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Next()
+	}
+}
+
+func (c *Context) verifySignedURL() error {
+	query := c.Request.URL.Query()
+	signature := query.Get(signedURLSignature)
+	if signature == "" {
+		return ErrInvalidSignedURL
+	}
+	valid := false
+	for _, key := range c.engine.urlKeys {
+		if hmac.Equal([]byte(signature), []byte(signURL(key, c.Request.Method, c.Request.URL.EscapedPath(), query))) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return ErrInvalidSignedURL
+	}
+	expires, err := strconv.ParseInt(query.Get(signedURLExpires), 10, 64)
+	if err != nil {
+		return ErrInvalidSignedURL
+	}
+	if time.Now().Unix() > expires {
+		return ErrSignedURLExpired
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedURLRouter(t *testing.T, keys ...[]byte) *Engine {
+	router := New()
+	require.NoError(t, router.SetURLKeys(keys...))
+	router.GET("/downloads/*file", VerifySignedURL(), func(c *Context) {
+		c.String(http.StatusOK, c.Param("file")+" for "+c.Query("user"))
+	}).(RouteNamer).Name("download")
+	router.POST("/callbacks/:id", VerifySignedURL(), func(c *Context) {
+		c.String(http.StatusOK, c.Param("id"))
+	})
+	return router
+}
+
+func TestSignURL(t *testing.T) {
+	router := signedURLRouter(t, []byte("key"))
+
+	link, err := router.SignURL("/downloads/my report.pdf?v=2", time.Hour, map[string]string{"user": "42"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link, "/downloads/my%20report.pdf?expires="), link)
+
+	w := PerformRequest(router, http.MethodGet, link)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "/my report.pdf for 42", w.Body.String())
+
+	for _, tampered := range []string{
+		strings.Replace(link, "user=42", "user=43", 1),
+		strings.Replace(link, "v=2", "v=3", 1),
+		strings.Replace(link, "report", "secrets", 1),
+		link + "&admin=1",
+		strings.SplitN(link, "?", 2)[0],
+	} {
+		w = PerformRequest(router, http.MethodGet, tampered)
+		assert.Equal(t, http.StatusForbidden, w.Code, tampered)
+	}
+
+	expired, err := router.signURL(http.MethodGet, "/downloads/a.pdf", time.Now().Add(-time.Second), nil)
+	require.NoError(t, err)
+	w = PerformRequest(router, http.MethodGet, expired)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	c, _ := CreateTestContext(nil)
+	c.engine = router
+	c.Request, _ = http.NewRequest(http.MethodGet, expired, nil)
+	assert.Equal(t, ErrSignedURLExpired, c.verifySignedURL())
+	c.Request, _ = http.NewRequest(http.MethodHead, link, nil)
+	assert.NoError(t, c.verifySignedURL())
+	c.Request, _ = http.NewRequest(http.MethodGet, link+"x", nil)
+	assert.Equal(t, ErrInvalidSignedURL, c.verifySignedURL())
+}
+
+func TestSignURLMethodAndRotation(t *testing.T) {
+	router := signedURLRouter(t, []byte("old"))
+	callback, err := router.SignURLMethod(http.MethodPost, "/callbacks/7", time.Hour, nil)
+	require.NoError(t, err)
+
+	w := PerformRequest(router, http.MethodPost, callback)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = PerformRequest(router, http.MethodGet, strings.Replace(callback, "callbacks", "downloads", 1))
+	assert.Equal(t, http.StatusForbidden, w.Code, "the signature covers the method and the path")
+
+	require.NoError(t, router.SetURLKeys([]byte("new"), []byte("old")))
+	w = PerformRequest(router, http.MethodPost, callback)
+	assert.Equal(t, http.StatusOK, w.Code, "the URLs signed with the old key are accepted")
+	newCallback, err := router.SignURLMethod(http.MethodPost, "/callbacks/7", time.Hour, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, callback, newCallback)
+
+	require.NoError(t, router.SetURLKeys([]byte("new")))
+	w = PerformRequest(router, http.MethodPost, callback)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = PerformRequest(router, http.MethodPost, newCallback)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Error(t, router.SetURLKeys([]byte{}))
+	_, err = New().SignURL("/a", time.Hour, nil)
+	assert.Equal(t, ErrNoURLKeys, err)
+}
+
+func TestSignRouteURL(t *testing.T) {
+	router := signedURLRouter(t, []byte("key"))
+	link, err := router.SignRouteURL("download", map[string]string{"file": "/docs/a.pdf", "user": "9"}, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link, "/downloads/docs/a.pdf?expires="), link)
+
+	w := PerformRequest(router, http.MethodGet, link)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "/docs/a.pdf for 9", w.Body.String())
+
+	_, err = router.SignRouteURL("missing", nil, time.Minute)
+	assert.Error(t, err)
+}
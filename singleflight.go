@@ -0,0 +1,91 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin/internal/singleflight"
+)
+
+// Singleflight returns a middleware which collapses concurrent identical GET and
+// HEAD requests into a single execution of the handlers: the requests arriving
+// while a first one is being handled wait for its response, which is buffered
+// and written to all of them. This protects expensive endpoints from thundering
+// herds, e.g. when a popular resource expires from a cache.
+//
+// keyFunc returns the key identifying identical requests, an empty key disables
+// the deduplication of the request. The default key is made of the method, the
+// request URI and the Authorization and Cookie headers, so that responses are
+// never shared between users. The responses setting cookies, or whose
+// Cache-Control header is no-store or private, are not shared either: the
+// waiting requests are then handled on their own, as are the ones whose
+// values of the headers listed by the Vary header of the response differ.
+func Singleflight(keyFunc func(c *Context) string) HandlerFunc {
+	return singleflightWithGroup(keyFunc, &singleflight.Group{})
+}
+
+func singleflightWithGroup(keyFunc func(c *Context) string, group *singleflight.Group) HandlerFunc {
+	if keyFunc == nil {
+		keyFunc = func(c *Context) string {
+			return cacheKey(c, []string{"Authorization", "Cookie"})
+		}
+	}
+
+	return func(c *Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+		key := keyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		executed := false
+		v, _, _ := group.Do(key, func() (any, error) {
+			executed = true
+			recorder := &cacheRecorder{ResponseWriter: c.Writer}
+			c.Writer = recorder
+			defer func() { c.Writer = recorder.ResponseWriter }()
+
+			c.Next()
+
+			if !recorder.shareable() {
+				return nil, nil
+			}
+			resp := &CachedResponse{
+				Status: recorder.Status(),
+				Header: recorder.Header().Clone(),
+				Body:   recorder.body.Bytes(),
+			}
+			return &cacheFlight{resp: resp, key: variantKey(key, varyHeaders(resp.Header), c)}, nil
+		})
+		if executed {
+			return
+		}
+
+		flight, ok := v.(*cacheFlight)
+		if !ok || flight == nil || variantKey(key, varyHeaders(flight.resp.Header), c) != flight.key {
+			// the response is not shareable, varies for the request or the
+			// handlers panicked: handle the request on its own.
+			c.Next()
+			return
+		}
+		resp := flight.resp
+		header := c.Writer.Header()
+		for k, v := range resp.Header {
+			header[k] = v
+		}
+		c.Status(resp.Status)
+		if c.Request.Method == http.MethodHead {
+			c.Writer.WriteHeaderNow()
+		} else {
+			_, _ = c.Writer.Write(resp.Body)
+		}
+		c.Abort()
+	}
+}
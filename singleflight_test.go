@@ -0,0 +1,173 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin/internal/singleflight"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSingleflight(t *testing.T) {
+	var calls int32
+	started := make(chan struct{}, 5)
+	waiting := make(chan string, 5)
+	release := make(chan struct{})
+	router := New()
+	router.Use(singleflightWithGroup(nil, &singleflight.Group{OnWait: func(key string) { waiting <- key }}))
+	router.GET("/slow", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+		started <- struct{}{}
+		<-release
+		c.Header("X-Result", "shared")
+		c.String(http.StatusServiceUnavailable, "busy")
+	})
+
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 5)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = PerformRequest(router, http.MethodGet, "/slow")
+		}(i)
+	}
+	<-started
+	for i := 0; i < 4; i++ {
+		<-waiting
+	}
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, w := range responses {
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "shared", w.Header().Get("X-Result"))
+		assert.Equal(t, "busy", w.Body.String())
+	}
+}
+
+func TestSingleflightKeys(t *testing.T) {
+	var calls int32
+	started := make(chan struct{}, 5)
+	waiting := make(chan string, 5)
+	release := make(chan struct{})
+	router := New()
+	router.Use(singleflightWithGroup(func(c *Context) string {
+		return c.Query("key")
+	}, &singleflight.Group{OnWait: func(key string) { waiting <- key }}))
+	handler := func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+		started <- struct{}{}
+		<-release
+		c.String(http.StatusOK, c.Query("key"))
+	}
+	router.GET("/", handler)
+	router.POST("/", handler)
+
+	var wg sync.WaitGroup
+	for _, r := range []struct{ method, path string }{
+		{http.MethodGet, "/?key=a"},
+		{http.MethodGet, "/?key=a&x=1"},
+		{http.MethodGet, "/?key=b"},
+		{http.MethodGet, "/"},
+		{http.MethodPost, "/?key=a"},
+	} {
+		wg.Add(1)
+		go func(method, path string) {
+			defer wg.Done()
+			PerformRequest(router, method, path)
+		}(r.method, r.path)
+	}
+	// a, b, the request without key and the POST request are handled, the
+	// second request of a waits.
+	for i := 0; i < 4; i++ {
+		<-started
+	}
+	assert.Equal(t, "a", <-waiting)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+}
+
+func TestSingleflightSkipsUnshareableResponses(t *testing.T) {
+	var calls int32
+	started := make(chan struct{}, 5)
+	waiting := make(chan string, 5)
+	release := make(chan struct{})
+	router := New()
+	router.Use(singleflightWithGroup(nil, &singleflight.Group{OnWait: func(key string) { waiting <- key }}))
+	router.GET("/login", func(c *Context) {
+		n := atomic.AddInt32(&calls, 1)
+		started <- struct{}{}
+		<-release
+		c.SetCookie("session", strconv.Itoa(int(n)), 0, "/", "", false, true)
+		c.String(http.StatusOK, "ok")
+	})
+
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 3)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = PerformRequest(router, http.MethodGet, "/login")
+		}(i)
+	}
+	<-started
+	for i := 0; i < 2; i++ {
+		<-waiting
+	}
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	cookies := make(map[string]bool)
+	for _, w := range responses {
+		cookies[w.Header().Get("Set-Cookie")] = true
+	}
+	assert.Len(t, cookies, 3)
+}
+
+func TestSingleflightVary(t *testing.T) {
+	var calls int32
+	started := make(chan struct{}, 2)
+	waiting := make(chan string, 2)
+	release := make(chan struct{})
+	router := New()
+	router.Use(singleflightWithGroup(nil, &singleflight.Group{OnWait: func(key string) { waiting <- key }}))
+	router.GET("/", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+		started <- struct{}{}
+		<-release
+		c.Header("Vary", "Accept-Encoding")
+		c.String(http.StatusOK, c.GetHeader("Accept-Encoding"))
+	})
+
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 2)
+	for i, encoding := range []string{"gzip", "br"} {
+		wg.Add(1)
+		go func(i int, encoding string) {
+			defer wg.Done()
+			responses[i] = PerformRequest(router, http.MethodGet, "/", header{"Accept-Encoding", encoding})
+		}(i, encoding)
+	}
+	<-started
+	<-waiting
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, "gzip", responses[0].Body.String())
+	assert.Equal(t, "br", responses[1].Body.String())
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// SplitVariantKey is the context key of the name of the variant chosen by Split
+// for the request.
+const SplitVariantKey = "_gin-gonic/gin/splitvariant"
+
+// SplitVariant is a handler receiving a share of the traffic of a route, see
+// SplitWithConfig.
+type SplitVariant struct {
+	// Name identifies the variant in the sticky cookie, the override header and
+	// the OnServed hook.
+	Name string
+	// Weight is the share of the new clients served by the variant, relative to
+	// the weights of the other variants.
+	Weight  int
+	Handler HandlerFunc
+}
+
+// SplitConfig defines the config for SplitWithConfig.
+type SplitConfig struct {
+	Variants []SplitVariant
+
+	// Optional. Default value is "gin_split". The cookie which keeps a client on
+	// the same variant. Routes split independently need different cookies.
+	Cookie string
+
+	// Optional. Default value is 24 hours. The lifetime of the sticky cookie.
+	CookieMaxAge time.Duration
+
+	// Optional. Default value is "" (disabled). A request header whose value,
+	// when it is the name of a variant, selects that variant, e.g. for the
+	// tests of the canary: "X-Canary".
+	Header string
+
+	// Optional. Called once the variant has handled the request, e.g. to count
+	// the requests and errors of each variant.
+	OnServed func(c *Context, variant string)
+}
+
+// Split returns a handler sending stableWeight parts of the traffic to stable
+// and canaryWeight parts to canary, keeping each client on the same variant
+// with a cookie:
+//
+//	router.GET("/api/x", gin.Split(90, stableHandler, 10, canaryHandler))
+//
+// See SplitWithConfig.
+func Split(stableWeight int, stable HandlerFunc, canaryWeight int, canary HandlerFunc) HandlerFunc {
+	return SplitWithConfig(SplitConfig{Variants: []SplitVariant{
+		{Name: "stable", Weight: stableWeight, Handler: stable},
+		{Name: "canary", Weight: canaryWeight, Handler: canary},
+	}})
+}
+
+// SplitWithConfig returns a handler splitting the traffic of a route between
+// variants, according to their weights. The variant of a client is kept in a
+// cookie, so that its following requests go to the same variant, unless the
+// configured header selects another one. The name of the variant is stored in
+// the context under SplitVariantKey.
+func SplitWithConfig(config SplitConfig) HandlerFunc {
+	total := 0
+	variants := make(map[string]SplitVariant, len(config.Variants))
+	for _, v := range config.Variants {
+		assert1(v.Name != "" && v.Handler != nil, "split variants must have a name and a handler")
+		assert1(v.Weight >= 0, "split weights can not be negative")
+		variants[v.Name] = v
+		total += v.Weight
+	}
+	assert1(total > 0, "split weights must not be all zero")
+	if config.Cookie == "" {
+		config.Cookie = "gin_split"
+	}
+	if config.CookieMaxAge == 0 {
+		config.CookieMaxAge = 24 * time.Hour
+	}
+
+	pick := func() SplitVariant {
+		n := rand.Intn(total)
+		for _, v := range config.Variants {
+			if n < v.Weight {
+				return v
+			}
+			n -= v.Weight
+		}
+		return config.Variants[len(config.Variants)-1]
+	}
+
+	return func(c *Context) {
+		var v SplitVariant
+		var ok bool
+		if config.Header != "" {
+			v, ok = variants[c.requestHeader(config.Header)]
+		}
+		if !ok {
+			var name string
+			if cookie, err := c.Request.Cookie(config.Cookie); err == nil {
+				name = cookie.Value
+			}
+			// a variant whose weight dropped to zero no longer gets clients.
+			if v, ok = variants[name]; !ok || v.Weight == 0 {
+				v = pick()
+				c.writeCookie(Cookie{
+					Name:     config.Cookie,
+					Value:    v.Name,
+					MaxAge:   int(config.CookieMaxAge / time.Second),
+					Path:     "/",
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+		}
+
+		c.Set(SplitVariantKey, v.Name)
+		v.Handler(c)
+		if config.OnServed != nil {
+			config.OnServed(c, v.Name)
+		}
+	}
+}
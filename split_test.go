@@ -0,0 +1,72 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	router := New()
+	router.GET("/x", Split(90, func(c *Context) {
+		c.String(http.StatusOK, "stable")
+	}, 10, func(c *Context) {
+		c.String(http.StatusOK, "canary")
+	}))
+
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		w := PerformRequest(router, http.MethodGet, "/x")
+		counts[w.Body.String()]++
+		cookie := w.Header().Get("Set-Cookie")
+		assert.True(t, strings.HasPrefix(cookie, "gin_split="+w.Body.String()+";"), cookie)
+	}
+	assert.InDelta(t, 900, counts["stable"], 60)
+	assert.InDelta(t, 100, counts["canary"], 60)
+
+	// sticky
+	for i := 0; i < 20; i++ {
+		w := PerformRequest(router, http.MethodGet, "/x", header{"Cookie", "gin_split=canary"})
+		assert.Equal(t, "canary", w.Body.String())
+		assert.Empty(t, w.Header().Get("Set-Cookie"))
+	}
+}
+
+func TestSplitWithConfig(t *testing.T) {
+	served := map[string]int{}
+	router := New()
+	router.GET("/x", SplitWithConfig(SplitConfig{
+		Variants: []SplitVariant{
+			{Name: "v1", Weight: 0, Handler: func(c *Context) { c.String(http.StatusOK, "v1") }},
+			{Name: "v2", Weight: 1, Handler: func(c *Context) {
+				c.String(http.StatusOK, "v2 "+c.MustGet(SplitVariantKey).(string))
+			}},
+		},
+		Cookie: "x_variant",
+		Header: "X-Variant",
+		OnServed: func(c *Context, variant string) {
+			served[variant+" "+http.StatusText(c.Writer.Status())]++
+		},
+	}))
+
+	w := PerformRequest(router, http.MethodGet, "/x", header{"Cookie", "x_variant=v1"})
+	assert.Equal(t, "v2 v2", w.Body.String())
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "x_variant=v2; Path=/; Max-Age=86400; HttpOnly; SameSite=Lax")
+
+	w = PerformRequest(router, http.MethodGet, "/x", header{"X-Variant", "v1"})
+	assert.Equal(t, "v1", w.Body.String())
+	assert.Empty(t, w.Header().Get("Set-Cookie"))
+
+	w = PerformRequest(router, http.MethodGet, "/x", header{"X-Variant", "v3"})
+	assert.Equal(t, "v2 v2", w.Body.String())
+	assert.Equal(t, map[string]int{"v1 OK": 1, "v2 OK": 2}, served)
+
+	assert.Panics(t, func() { SplitWithConfig(SplitConfig{}) })
+	assert.Panics(t, func() { Split(-1, func(c *Context) {}, 2, func(c *Context) {}) })
+}
@@ -0,0 +1,231 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin/internal/singleflight"
+)
+
+const (
+	defaultStaticCacheMaxFileSize = 64 << 10
+	defaultStaticCacheMaxSize     = 32 << 20
+)
+
+// StaticCacheConfig defines the config of a StaticCache.
+type StaticCacheConfig struct {
+	// MaxFileSize is the size of the largest file kept in memory, the larger
+	// ones are read from the file system for each request.
+	// Optional. Default value is 64KB.
+	MaxFileSize int64
+
+	// MaxSize is the total size of the files kept in memory, beyond which the
+	// least recently used ones are evicted.
+	// Optional. Default value is 32MB.
+	MaxSize int64
+}
+
+// StaticCache keeps the small files served by Static and StaticFS in memory,
+// see Engine.StaticCache, so that the hot assets are served without opening
+// nor stating them, and the concurrent reads of a file which is not in memory
+// are coalesced into one.
+//
+// The files are not revalidated: the ones which change while the cache is used
+// must be invalidated with Invalidate, InvalidatePrefix or Purge, e.g. by the
+// deployment of new assets. The eviction approximates LRU with the CLOCK
+// algorithm, whose hits only set a flag under a read lock, without recording
+// their time nor reordering the entries.
+type StaticCache struct {
+	config  StaticCacheConfig
+	flights singleflight.Group
+
+	mu         sync.RWMutex
+	entries    map[string]*staticCacheEntry
+	ring       []*staticCacheEntry
+	hand       int
+	size       int64
+	generation uint64
+}
+
+type staticCacheEntry struct {
+	key         string
+	name        string
+	content     []byte
+	contentType string
+	modTime     time.Time
+	index       int
+	referenced  atomic.Bool
+}
+
+// NewStaticCache returns a StaticCache with config.
+func NewStaticCache(config StaticCacheConfig) *StaticCache {
+	if config.MaxFileSize <= 0 {
+		config.MaxFileSize = defaultStaticCacheMaxFileSize
+	}
+	if config.MaxSize <= 0 {
+		config.MaxSize = defaultStaticCacheMaxSize
+	}
+	return &StaticCache{config: config, entries: make(map[string]*staticCacheEntry)}
+}
+
+// Invalidate removes the file served at urlPath, e.g. "/assets/app.css", from
+// the cache.
+func (sc *StaticCache) Invalidate(urlPath string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.generation++
+	if entry, ok := sc.entries[urlPath]; ok {
+		sc.remove(entry)
+	}
+}
+
+// InvalidatePrefix removes the files served under prefix, e.g. "/assets/",
+// from the cache.
+func (sc *StaticCache) InvalidatePrefix(prefix string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.generation++
+	for key, entry := range sc.entries {
+		if strings.HasPrefix(key, prefix) {
+			sc.remove(entry)
+		}
+	}
+}
+
+// Purge removes all the files from the cache.
+func (sc *StaticCache) Purge() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.generation++
+	sc.entries = make(map[string]*staticCacheEntry)
+	sc.ring = nil
+	sc.hand = 0
+	sc.size = 0
+}
+
+// Len returns the number of files in the cache.
+func (sc *StaticCache) Len() int {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return len(sc.entries)
+}
+
+// Size returns the total size of the files in the cache.
+func (sc *StaticCache) Size() int64 {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.size
+}
+
+// serve serves the file name of fs, served at key, from the cache, loading it
+// first if needed. It returns false for the files which are not kept in
+// memory: missing, directories, index.html files redirected by http.FileServer
+// or larger than MaxFileSize.
+func (sc *StaticCache) serve(c *Context, key string, fs http.FileSystem, name string) bool {
+	if strings.HasSuffix(name, "/") || path.Base(name) == "index.html" {
+		return false
+	}
+	sc.mu.RLock()
+	entry, ok := sc.entries[key]
+	sc.mu.RUnlock()
+	if ok {
+		entry.referenced.Store(true)
+	} else {
+		v, _, _ := sc.flights.Do(key, func() (any, error) {
+			return sc.load(key, fs, name), nil
+		})
+		if entry, _ = v.(*staticCacheEntry); entry == nil {
+			return false
+		}
+	}
+	if c.Writer.Header().Get("Content-Type") == "" {
+		c.Header("Content-Type", entry.contentType)
+	}
+	http.ServeContent(c.Writer, c.Request, entry.name, entry.modTime, bytes.NewReader(entry.content))
+	return true
+}
+
+// load reads the file name of fs and stores it under key, unless it is not
+// kept in memory.
+func (sc *StaticCache) load(key string, fs http.FileSystem, name string) *staticCacheEntry {
+	sc.mu.RLock()
+	generation := sc.generation
+	sc.mu.RUnlock()
+
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil || !stat.Mode().IsRegular() || stat.Size() > sc.config.MaxFileSize {
+		return nil
+	}
+	content, err := io.ReadAll(io.LimitReader(f, sc.config.MaxFileSize+1))
+	if err != nil || int64(len(content)) > sc.config.MaxFileSize {
+		return nil
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+	entry := &staticCacheEntry{
+		key:         key,
+		name:        stat.Name(),
+		content:     content,
+		contentType: contentType,
+		modTime:     stat.ModTime(),
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	// the files invalidated while they were read may be stale.
+	if sc.generation == generation && int64(len(content)) <= sc.config.MaxSize {
+		if old, ok := sc.entries[key]; ok {
+			sc.remove(old)
+		}
+		sc.evict(int64(len(content)))
+		entry.index = len(sc.ring)
+		sc.ring = append(sc.ring, entry)
+		sc.entries[key] = entry
+		sc.size += int64(len(content))
+	}
+	return entry
+}
+
+// evict removes the entries until size more bytes fit in the cache, skipping
+// once the ones referenced since the hand passed them.
+func (sc *StaticCache) evict(size int64) {
+	for sc.size+size > sc.config.MaxSize && len(sc.ring) > 0 {
+		if sc.hand >= len(sc.ring) {
+			sc.hand = 0
+		}
+		entry := sc.ring[sc.hand]
+		if entry.referenced.Swap(false) {
+			sc.hand++
+			continue
+		}
+		sc.remove(entry)
+	}
+}
+
+func (sc *StaticCache) remove(entry *staticCacheEntry) {
+	last := sc.ring[len(sc.ring)-1]
+	sc.ring[entry.index] = last
+	last.index = entry.index
+	sc.ring[len(sc.ring)-1] = nil
+	sc.ring = sc.ring[:len(sc.ring)-1]
+	delete(sc.entries, entry.key)
+	sc.size -= int64(len(entry.content))
+}
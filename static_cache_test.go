@@ -0,0 +1,191 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingFS counts the files opened in the wrapped file system, and blocks
+// them until release is closed if set.
+type countingFS struct {
+	http.FileSystem
+	opens   atomic.Int32
+	release chan struct{}
+}
+
+func (fs *countingFS) Open(name string) (http.File, error) {
+	fs.opens.Add(1)
+	if fs.release != nil {
+		<-fs.release
+	}
+	return fs.FileSystem.Open(name)
+}
+
+func staticCacheRouter(t *testing.T, config StaticCacheConfig, files map[string]string) (*Engine, *countingFS, string) {
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	fs := &countingFS{FileSystem: Dir(dir, false)}
+	router := New()
+	router.StaticCache = NewStaticCache(config)
+	router.StaticFS("/assets", fs)
+	return router, fs, dir
+}
+
+func TestStaticCache(t *testing.T) {
+	router, fs, dir := staticCacheRouter(t, StaticCacheConfig{}, map[string]string{
+		"css/app.css": "body{}",
+		"data":        "\x00\x01binary",
+	})
+
+	for i := 0; i < 3; i++ {
+		w := PerformRequest(router, http.MethodGet, "/assets/css/app.css")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "body{}", w.Body.String())
+		assert.Equal(t, "text/css; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.NotEmpty(t, w.Header().Get("Last-Modified"))
+	}
+	assert.Equal(t, int32(1), fs.opens.Load())
+	assert.Equal(t, 1, router.StaticCache.Len())
+	assert.Equal(t, int64(6), router.StaticCache.Size())
+
+	w := PerformRequest(router, http.MethodGet, "/assets/data", header{"Range", "bytes=2-"})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "binary", w.Body.String())
+	assert.Equal(t, "application/octet-stream", w.Header().Get("Content-Type"))
+
+	w = PerformRequest(router, http.MethodHead, "/assets/css/app.css")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	// the files are not revalidated until they are invalidated.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "css/app.css"), []byte("body{color:red}"), 0o600))
+	w = PerformRequest(router, http.MethodGet, "/assets/css/app.css")
+	assert.Equal(t, "body{}", w.Body.String())
+
+	router.StaticCache.Invalidate("/assets/css/app.css")
+	w = PerformRequest(router, http.MethodGet, "/assets/css/app.css")
+	assert.Equal(t, "body{color:red}", w.Body.String())
+}
+
+func TestStaticCacheNotCached(t *testing.T) {
+	router, fs, _ := staticCacheRouter(t, StaticCacheConfig{MaxFileSize: 4}, map[string]string{
+		"large.txt":       "too large",
+		"docs/index.html": "<p>docs</p>",
+	})
+
+	for i := 0; i < 2; i++ {
+		w := PerformRequest(router, http.MethodGet, "/assets/large.txt")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "too large", w.Body.String())
+	}
+	w := PerformRequest(router, http.MethodGet, "/assets/docs/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "<p>docs</p>", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "/assets/docs/index.html")
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+
+	w = PerformRequest(router, http.MethodGet, "/assets/missing.txt")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, 0, router.StaticCache.Len())
+	assert.Greater(t, fs.opens.Load(), int32(4))
+}
+
+func TestStaticCacheCoalescing(t *testing.T) {
+	router, fs, _ := staticCacheRouter(t, StaticCacheConfig{}, map[string]string{"app.js": "run()"})
+	fs.release = make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := PerformRequest(router, http.MethodGet, "/assets/app.js")
+			assert.Equal(t, "run()", w.Body.String())
+		}()
+	}
+	for fs.opens.Load() == 0 {
+		runtime.Gosched()
+	}
+	close(fs.release)
+	wg.Wait()
+	assert.Equal(t, int32(1), fs.opens.Load())
+}
+
+func TestStaticCacheEviction(t *testing.T) {
+	router, fs, _ := staticCacheRouter(t, StaticCacheConfig{MaxSize: 10}, map[string]string{
+		"a": "aaaa",
+		"b": "bbbb",
+		"c": "cccc",
+	})
+
+	PerformRequest(router, http.MethodGet, "/assets/a")
+	PerformRequest(router, http.MethodGet, "/assets/b")
+	// a is referenced, b is evicted for c.
+	PerformRequest(router, http.MethodGet, "/assets/a")
+	PerformRequest(router, http.MethodGet, "/assets/c")
+	assert.Equal(t, 2, router.StaticCache.Len())
+	assert.Equal(t, int64(8), router.StaticCache.Size())
+
+	opens := fs.opens.Load()
+	w := PerformRequest(router, http.MethodGet, "/assets/a")
+	assert.Equal(t, "aaaa", w.Body.String())
+	assert.Equal(t, opens, fs.opens.Load())
+	PerformRequest(router, http.MethodGet, "/assets/b")
+	assert.Equal(t, opens+1, fs.opens.Load())
+}
+
+func TestStaticCacheInvalidation(t *testing.T) {
+	router, _, _ := staticCacheRouter(t, StaticCacheConfig{}, map[string]string{
+		"css/a.css": "a",
+		"css/b.css": "b",
+		"app.js":    "run()",
+	})
+	for _, name := range []string{"css/a.css", "css/b.css", "app.js"} {
+		PerformRequest(router, http.MethodGet, "/assets/"+name)
+	}
+	assert.Equal(t, 3, router.StaticCache.Len())
+
+	router.StaticCache.InvalidatePrefix("/assets/css/")
+	assert.Equal(t, 1, router.StaticCache.Len())
+	assert.Equal(t, int64(5), router.StaticCache.Size())
+
+	router.StaticCache.Invalidate("/assets/unknown")
+	assert.Equal(t, 1, router.StaticCache.Len())
+
+	router.StaticCache.Purge()
+	assert.Equal(t, 0, router.StaticCache.Len())
+	assert.Equal(t, int64(0), router.StaticCache.Size())
+	w := PerformRequest(router, http.MethodGet, "/assets/app.js")
+	assert.Equal(t, "run()", w.Body.String())
+	assert.Equal(t, 1, router.StaticCache.Len())
+}
+
+func TestStaticCacheRootGroup(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "robots.txt"), []byte("User-agent: *"), 0o600))
+	router := New()
+	router.StaticCache = NewStaticCache(StaticCacheConfig{})
+	router.Static("/", dir)
+
+	w := PerformRequest(router, http.MethodGet, "/robots.txt")
+	assert.True(t, strings.HasPrefix(w.Body.String(), "User-agent"))
+	assert.Equal(t, 1, router.StaticCache.Len())
+	router.StaticCache.Invalidate("/robots.txt")
+	assert.Equal(t, 0, router.StaticCache.Len())
+}
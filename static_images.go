@@ -0,0 +1,423 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/internal/singleflight"
+)
+
+// defaultImageCacheEntries is the number of images of the default cache of
+// StaticImages.
+const defaultImageCacheEntries = 100
+
+// Fit modes of the images resized by StaticImages.
+const (
+	// ImageFitContain scales the image to fit in the requested size, keeping its
+	// aspect ratio.
+	ImageFitContain = "contain"
+	// ImageFitCover scales the image to cover the requested size, keeping its
+	// aspect ratio, and crops its center.
+	ImageFitCover = "cover"
+)
+
+// ImageEncoder encodes img to w, see ImageOptions.Encoders.
+type ImageEncoder func(w io.Writer, img image.Image) error
+
+// ImageOptions defines the config of RouterGroup.StaticImages.
+type ImageOptions struct {
+	// Secret signs the URLs of the resized images, see ImageURL: the requests
+	// of a resize whose "s" query parameter is not a valid signature are
+	// rejected with 403 Forbidden, so that clients can not ask for any size.
+	// Secret or Sizes is required.
+	Secret []byte
+
+	// Sizes are the transformations which can be requested without signature,
+	// e.g. the sizes of the thumbnails: the other ones are rejected with 403
+	// Forbidden, unless they are signed with Secret.
+	// Secret or Sizes is required.
+	Sizes []ImageParams
+
+	// MaxWidth and MaxHeight are the maximum dimensions of a resized image.
+	// Optional. Default value is 4096.
+	MaxWidth  int
+	MaxHeight int
+
+	// Encoders are the encoders of the output formats, by media type, added to
+	// the built-in JPEG, PNG and GIF ones. The "image/avif" and "image/webp"
+	// encoders are used for the clients accepting them, in this order, e.g.
+	// from an AVIF or a WebP package:
+	//
+	//	Encoders: map[string]gin.ImageEncoder{
+	//		"image/webp": func(w io.Writer, img image.Image) error {
+	//			return webp.Encode(w, img, &webp.Options{Quality: 80})
+	//		},
+	//	}
+	//
+	// Optional.
+	Encoders map[string]ImageEncoder
+
+	// Quality is the quality of the JPEG images, from 1 to 100.
+	// Optional. Default value is 85.
+	Quality int
+
+	// Cache stores the transformed images.
+	// Optional. Default value is a new MemoryStore of 100 images.
+	Cache CacheStore
+
+	// CacheTTL is the lifetime of a transformed image in Cache.
+	// Optional. Default value is one hour.
+	CacheTTL time.Duration
+
+	// CacheControl is the Cache-Control header of the images.
+	// Optional. Default value is "public, max-age=86400".
+	CacheControl string
+}
+
+// ImageParams are the transformations of an image served by StaticImages, set
+// by the "w", "h" and "fit" query parameters.
+type ImageParams struct {
+	// Width and Height are the dimensions of the image, one of them being
+	// computed from the aspect ratio when 0. The images are never enlarged.
+	Width  int
+	Height int
+	// Fit is ImageFitContain, the default, or ImageFitCover.
+	Fit string
+}
+
+// equal reports whether p and other are the same transformation.
+func (p ImageParams) equal(other ImageParams) bool {
+	return p.query().Encode() == other.query().Encode()
+}
+
+func (p ImageParams) query() url.Values {
+	query := url.Values{}
+	if p.Width > 0 {
+		query.Set("w", strconv.Itoa(p.Width))
+	}
+	if p.Height > 0 {
+		query.Set("h", strconv.Itoa(p.Height))
+	}
+	if p.Fit != "" && p.Fit != ImageFitContain {
+		query.Set("fit", p.Fit)
+	}
+	return query
+}
+
+// ImageURL returns the URL of the image at urlPath, served by StaticImages,
+// transformed with params and signed with secret, e.g.
+//
+//	gin.ImageURL(secret, "/img/photos/cat.jpg", gin.ImageParams{Width: 320})
+//
+// returns "/img/photos/cat.jpg?s=...&w=320".
+func ImageURL(secret []byte, urlPath string, params ImageParams) string {
+	query := params.query()
+	if len(query) == 0 {
+		return urlPath
+	}
+	if len(secret) > 0 {
+		query.Set("s", imageSignature(secret, urlPath, params))
+	}
+	return urlPath + "?" + query.Encode()
+}
+
+// imageSignature returns the signature of the transformation of the image at
+// urlPath.
+func imageSignature(secret []byte, urlPath string, params ImageParams) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(urlPath + "?" + params.query().Encode()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parseImageParams parses the transformations of the query.
+func parseImageParams(query url.Values) (ImageParams, error) {
+	var params ImageParams
+	var err error
+	if w := query.Get("w"); w != "" {
+		if params.Width, err = strconv.Atoi(w); err != nil || params.Width <= 0 {
+			return params, errors.New("invalid image width")
+		}
+	}
+	if h := query.Get("h"); h != "" {
+		if params.Height, err = strconv.Atoi(h); err != nil || params.Height <= 0 {
+			return params, errors.New("invalid image height")
+		}
+	}
+	switch params.Fit = query.Get("fit"); params.Fit {
+	case "", ImageFitContain, ImageFitCover:
+	default:
+		return params, errors.New("invalid image fit")
+	}
+	return params, nil
+}
+
+// StaticImages serves the images of source, like StaticStorage, resized
+// according to the "w", "h" and "fit" query parameters, see ImageParams and
+// ImageURL, and converted to the AVIF or WebP format for the clients accepting
+// them when encoders are given. The transformed images are cached, and the
+// concurrent requests of the same image are transformed once.
+//
+//	router.StaticImages("/img", gin.DiskStorage("./images"), gin.ImageOptions{Secret: secret})
+//
+// The JPEG, PNG and GIF images are transformed, the other files are served as
+// they are. Since transforming an image is expensive, the transformations must
+// be signed with ImageOptions.Secret, or be one of ImageOptions.Sizes.
+func (group *RouterGroup) StaticImages(relativePath string, source BlobStorage, opts ImageOptions) IRoutes {
+	if strings.Contains(relativePath, ":") || strings.Contains(relativePath, "*") {
+		panic("URL parameters can not be used when serving a static folder")
+	}
+	assert1(len(opts.Secret) > 0 || len(opts.Sizes) > 0, "StaticImages requires a Secret or Sizes")
+	if opts.MaxWidth <= 0 {
+		opts.MaxWidth = 4096
+	}
+	if opts.MaxHeight <= 0 {
+		opts.MaxHeight = 4096
+	}
+	if opts.Quality <= 0 {
+		opts.Quality = 85
+	}
+	if opts.Cache == nil {
+		opts.Cache = NewMemoryStoreWithConfig(MemoryStoreConfig{MaxEntries: defaultImageCacheEntries})
+	}
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = time.Hour
+	}
+	if opts.CacheControl == "" {
+		opts.CacheControl = "public, max-age=86400"
+	}
+	encoders := map[string]ImageEncoder{
+		"image/jpeg": func(w io.Writer, img image.Image) error {
+			return jpeg.Encode(w, img, &jpeg.Options{Quality: opts.Quality})
+		},
+		"image/png": png.Encode,
+		"image/gif": func(w io.Writer, img image.Image) error {
+			return gif.Encode(w, img, nil)
+		},
+	}
+	for mediaType, encoder := range opts.Encoders {
+		encoders[mediaType] = encoder
+	}
+
+	var flights singleflight.Group
+	handler := func(c *Context) {
+		name := c.Param("filepath")
+		ctx := c.Request.Context()
+		info, err := source.Stat(ctx, name)
+		if err != nil {
+			group.staticError(c, err)
+			return
+		}
+		switch info.ContentType {
+		case "image/jpeg", "image/png", "image/gif":
+		default:
+			group.staticError(c, c.FileFromStorage(source, name))
+			return
+		}
+
+		query := c.Request.URL.Query()
+		params, err := parseImageParams(query)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusBadRequest, err).SetType(ErrorTypePublic)
+			return
+		}
+		if params.Width > opts.MaxWidth || params.Height > opts.MaxHeight {
+			_ = c.AbortWithError(http.StatusBadRequest, errors.New("image too large")).SetType(ErrorTypePublic)
+			return
+		}
+		transform := params.Width > 0 || params.Height > 0
+		if transform && !imageAllowed(opts, c.Request.URL.Path, params, query.Get("s")) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		c.Header("Vary", "Accept")
+		format := negotiateImageFormat(c.requestHeader("Accept"), info.ContentType, encoders)
+		if !transform && format == info.ContentType {
+			c.Header("Cache-Control", opts.CacheControl)
+			group.staticError(c, c.FileFromStorage(source, name))
+			return
+		}
+
+		key := strings.Join([]string{"img", name, strconv.FormatInt(info.ModTime.UnixNano(), 10), params.query().Encode(), format}, "|")
+		if resp, err := opts.Cache.Get(key); err == nil && resp != nil {
+			writeCachedResponse(c, resp)
+			return
+		}
+		v, err, _ := flights.Do(key, func() (any, error) {
+			// shared by the concurrent requests, so it is not canceled with
+			// the first one.
+			body, err := transformImage(context.Background(), source, name, params, encoders[format])
+			if err != nil {
+				return nil, err
+			}
+			resp := &CachedResponse{
+				Status: http.StatusOK,
+				Header: http.Header{
+					"Content-Type":  {format},
+					"Cache-Control": {opts.CacheControl},
+					"Vary":          {"Accept"},
+				},
+				Body:     body,
+				StoredAt: time.Now(),
+			}
+			if !info.ModTime.IsZero() {
+				resp.Header.Set("Last-Modified", info.ModTime.UTC().Format(http.TimeFormat))
+			}
+			if err := opts.Cache.Set(key, resp, opts.CacheTTL); err != nil {
+				_ = c.Error(err)
+			}
+			return resp, nil
+		})
+		if errors.Is(err, fs.ErrNotExist) {
+			group.staticError(c, err)
+			return
+		}
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		writeCachedResponse(c, v.(*CachedResponse))
+	}
+	urlPattern := path.Join(relativePath, "/*filepath")
+	return group.handleMethods([]string{http.MethodGet, http.MethodHead}, urlPattern, HandlersChain{handler})
+}
+
+// imageAllowed reports whether the transformation params of the image at
+// urlPath is one of opts.Sizes, or is signed by sig.
+func imageAllowed(opts ImageOptions, urlPath string, params ImageParams, sig string) bool {
+	for _, size := range opts.Sizes {
+		if params.equal(size) {
+			return true
+		}
+	}
+	if len(opts.Secret) == 0 {
+		return false
+	}
+	want := imageSignature(opts.Secret, urlPath, params)
+	return hmac.Equal([]byte(sig), []byte(want))
+}
+
+// negotiateImageFormat returns the media type of the image of type sourceType
+// served to the client accepting accept.
+func negotiateImageFormat(accept, sourceType string, encoders map[string]ImageEncoder) string {
+	for _, mediaType := range []string{"image/avif", "image/webp"} {
+		if _, ok := encoders[mediaType]; ok && strings.Contains(accept, mediaType) {
+			return mediaType
+		}
+	}
+	return sourceType
+}
+
+// transformImage returns the image name of source transformed with params and
+// encoded with encoder.
+func transformImage(ctx context.Context, source BlobStorage, name string, params ImageParams, encoder ImageEncoder) ([]byte, error) {
+	r, err := source.Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	img = resizeImage(img, params)
+	buf := new(bytes.Buffer)
+	if err = encoder(buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resizeImage returns img resized with params, never enlarged, averaging the
+// source pixels covered by each pixel.
+func resizeImage(img image.Image, params ImageParams) image.Image {
+	bounds := img.Bounds()
+	sw, sh := bounds.Dx(), bounds.Dy()
+	w, h := params.Width, params.Height
+	if w <= 0 && h <= 0 || sw == 0 || sh == 0 {
+		return img
+	}
+	// the region of the source image, cropped around its center with
+	// ImageFitCover.
+	region := bounds
+	switch {
+	case w <= 0:
+		w = maxInt(1, sw*h/sh)
+	case h <= 0:
+		h = maxInt(1, sh*w/sw)
+	case params.Fit == ImageFitCover:
+		if sw*h > sh*w {
+			cw := sh * w / h
+			region.Min.X += (sw - cw) / 2
+			region.Max.X = region.Min.X + cw
+		} else {
+			ch := sw * h / w
+			region.Min.Y += (sh - ch) / 2
+			region.Max.Y = region.Min.Y + ch
+		}
+	default:
+		if sw*h > sh*w {
+			h = maxInt(1, sh*w/sw)
+		} else {
+			w = maxInt(1, sw*h/sh)
+		}
+	}
+	if w >= region.Dx() || h >= region.Dy() {
+		w, h = region.Dx(), region.Dy()
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, region.Dx(), region.Dy()))
+	draw.Draw(src, src.Bounds(), img, region.Min, draw.Src)
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	rw, rh := region.Dx(), region.Dy()
+	for y := 0; y < h; y++ {
+		y0, y1 := y*rh/h, maxInt((y+1)*rh/h, y*rh/h+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := x*rw/w, maxInt((x+1)*rw/w, x*rw/w+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			i := dst.PixOffset(x, y)
+			for j := range sum {
+				dst.Pix[i+j] = uint8(sum[j] / n)
+			}
+		}
+	}
+	return dst
+}
+
+func maxInt(a, b int) int {
+	if a >= b {
+		return a
+	}
+	return b
+}
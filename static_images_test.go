@@ -0,0 +1,153 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// imageStorage returns a storage holding a 100x50 PNG image, red on its left
+// half and blue on its right half, and a text file.
+func imageStorage(t *testing.T) BlobStorage {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	for y := 0; y < 50; y++ {
+		for x := 0; x < 100; x++ {
+			if x < 50 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	buf := new(bytes.Buffer)
+	require.NoError(t, png.Encode(buf, img))
+	storage := DiskStorage(t.TempDir())
+	require.NoError(t, storage.Save(context.Background(), "photos/flag.png", buf, BlobInfo{}))
+	require.NoError(t, storage.Save(context.Background(), "notes.txt", strings.NewReader("hello"), BlobInfo{}))
+	return storage
+}
+
+func decodePNG(t *testing.T, data []byte) image.Image {
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	return img
+}
+
+func TestStaticImages(t *testing.T) {
+	router := New()
+	router.StaticImages("/img", imageStorage(t), ImageOptions{
+		MaxWidth: 1000,
+		Sizes: []ImageParams{
+			{Width: 50}, {Width: 10}, {Width: 10, Height: 10}, {Width: 10, Height: 10, Fit: ImageFitCover}, {Width: 400},
+		},
+	})
+	router.NoRoute(func(c *Context) { c.String(http.StatusNotFound, "no route") })
+
+	w := PerformRequest(router, http.MethodGet, "/img/photos/flag.png")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=86400", w.Header().Get("Cache-Control"))
+	assert.Equal(t, image.Rect(0, 0, 100, 50), decodePNG(t, w.Body.Bytes()).Bounds())
+
+	w = PerformRequest(router, http.MethodGet, "/img/photos/flag.png?w=50")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", w.Header().Get("Vary"))
+	img := decodePNG(t, w.Body.Bytes())
+	assert.Equal(t, image.Rect(0, 0, 50, 25), img.Bounds())
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, color.RGBAModel.Convert(img.At(10, 10)))
+	assert.Equal(t, color.RGBA{B: 255, A: 255}, color.RGBAModel.Convert(img.At(40, 10)))
+
+	w = PerformRequest(router, http.MethodGet, "/img/photos/flag.png?h=10&w=10")
+	assert.Equal(t, image.Rect(0, 0, 10, 5), decodePNG(t, w.Body.Bytes()).Bounds())
+
+	w = PerformRequest(router, http.MethodGet, "/img/photos/flag.png?w=10&h=10&fit=cover")
+	img = decodePNG(t, w.Body.Bytes())
+	assert.Equal(t, image.Rect(0, 0, 10, 10), img.Bounds())
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, color.RGBAModel.Convert(img.At(1, 5)))
+
+	w = PerformRequest(router, http.MethodGet, "/img/photos/flag.png?w=400")
+	assert.Equal(t, image.Rect(0, 0, 100, 50), decodePNG(t, w.Body.Bytes()).Bounds(), "the images are not enlarged")
+
+	w = PerformRequest(router, http.MethodHead, "/img/photos/flag.png?w=50")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/img/notes.txt?w=50")
+	assert.Equal(t, "hello", w.Body.String())
+
+	for query, code := range map[string]int{"w=abc": http.StatusBadRequest, "w=-1": http.StatusBadRequest, "w=2000": http.StatusBadRequest, "fit=zoom": http.StatusBadRequest} {
+		w = PerformRequest(router, http.MethodGet, "/img/photos/flag.png?"+query)
+		assert.Equal(t, code, w.Code, query)
+	}
+	w = PerformRequest(router, http.MethodGet, "/img/photos/missing.png?w=10")
+	assert.Equal(t, "no route", w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/img/photos/flag.png?w=60")
+	assert.Equal(t, http.StatusForbidden, w.Code, "not one of the sizes")
+	assert.Panics(t, func() { router.StaticImages("/unsigned", imageStorage(t), ImageOptions{}) })
+}
+
+func TestStaticImagesSigned(t *testing.T) {
+	secret := []byte("secret")
+	router := New()
+	router.StaticImages("/img", imageStorage(t), ImageOptions{Secret: secret})
+
+	w := PerformRequest(router, http.MethodGet, "/img/photos/flag.png?w=20")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	url := ImageURL(secret, "/img/photos/flag.png", ImageParams{Width: 20, Height: 20, Fit: ImageFitCover})
+	assert.True(t, strings.HasPrefix(url, "/img/photos/flag.png?fit=cover&h=20&s="), url)
+	w = PerformRequest(router, http.MethodGet, url)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, image.Rect(0, 0, 20, 20), decodePNG(t, w.Body.Bytes()).Bounds())
+
+	w = PerformRequest(router, http.MethodGet, strings.Replace(url, "h=20", "h=30", 1))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = PerformRequest(router, http.MethodGet, "/img/photos/flag.png")
+	assert.Equal(t, http.StatusOK, w.Code, "the originals are not signed")
+	assert.Equal(t, "/img/a.png", ImageURL(secret, "/img/a.png", ImageParams{}))
+}
+
+func TestStaticImagesFormats(t *testing.T) {
+	var encoded atomic.Int32
+	webp := func(w io.Writer, img image.Image) error {
+		encoded.Add(1)
+		_, err := fmt.Fprintf(w, "webp %dx%d", img.Bounds().Dx(), img.Bounds().Dy())
+		return err
+	}
+	router := New()
+	router.StaticImages("/img", imageStorage(t), ImageOptions{
+		Sizes:    []ImageParams{{Width: 50}},
+		Encoders: map[string]ImageEncoder{"image/webp": webp},
+	})
+
+	for i := 0; i < 2; i++ {
+		w := PerformRequest(router, http.MethodGet, "/img/photos/flag.png?w=50", header{"Accept", "image/avif,image/webp,*/*"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/webp", w.Header().Get("Content-Type"))
+		assert.Equal(t, "webp 50x25", w.Body.String())
+	}
+	assert.Equal(t, int32(1), encoded.Load(), "the transformed images are cached")
+
+	w := PerformRequest(router, http.MethodGet, "/img/photos/flag.png", header{"Accept", "image/webp"})
+	assert.Equal(t, "webp 100x50", w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/img/photos/flag.png?w=50", header{"Accept", "image/png"})
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+}
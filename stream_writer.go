@@ -0,0 +1,250 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin/internal/json"
+)
+
+// StreamFormat is the format of the payload of a StreamWriter.
+type StreamFormat int
+
+const (
+	// StreamRaw streams bytes of StreamConfig.ContentType.
+	StreamRaw StreamFormat = iota
+	// StreamNDJSON streams JSON values, one per line, see StreamWriter.WriteJSON.
+	StreamNDJSON
+	// StreamSSE streams server-sent events, see StreamWriter.WriteEvent.
+	StreamSSE
+)
+
+// StreamConfig defines the config for Context.StreamWriter.
+type StreamConfig struct {
+	// Format sets the Content-Type of the response, and the headers disabling
+	// the caches and the proxy buffering for StreamSSE.
+	// Optional. Default value is StreamRaw.
+	Format StreamFormat
+
+	// ContentType overrides the Content-Type of Format.
+	// Optional. Default value is "application/octet-stream" for StreamRaw.
+	ContentType string
+
+	// FlushInterval is the maximum time the written data waits in the buffer
+	// before being flushed to the client. A negative value flushes after each
+	// write.
+	// Optional. Default value is 100 milliseconds.
+	FlushInterval time.Duration
+
+	// MaxBuffered is the number of buffered bytes over which the data is flushed
+	// at once.
+	// Optional. Default value is 32KB.
+	MaxBuffered int
+
+	// WriteTimeout is the deadline of each write to the connection, so that a
+	// client which does not read stops the stream with an error instead of
+	// blocking it.
+	// Optional. Default value is 0: no deadline.
+	WriteTimeout time.Duration
+}
+
+// ErrStreamClosed is returned by the writes of a StreamWriter once its function
+// has returned.
+var ErrStreamClosed = errors.New("gin: write to a closed stream")
+
+// StreamWriter buffers the writes of a streaming response, see Context.StreamWriter.
+// It is safe for concurrent use.
+type StreamWriter struct {
+	c      *Context
+	config StreamConfig
+	rc     *http.ResponseController
+
+	mu      sync.Mutex
+	buf     *bufio.Writer
+	err     error
+	closed  bool
+	pending bool
+}
+
+// StreamWriter sends a streaming response written by fn, and returns the error
+// of fn, or the first error of the writes to the client. Unlike Stream, the
+// writes are buffered and flushed when the buffer holds config.MaxBuffered
+// bytes, config.FlushInterval after the first write since the last flush, or
+// when fn calls Flush; they fail once the client is gone or a write timed out,
+// so that fn can stop. The response is sent with chunked encoding:
+//
+//	err := c.StreamWriter(gin.StreamConfig{Format: gin.StreamNDJSON}, func(w *gin.StreamWriter) error {
+//		for rows.Next() {
+//			...
+//			if err := w.WriteJSON(row); err != nil {
+//				return err
+//			}
+//		}
+//		return rows.Err()
+//	})
+func (c *Context) StreamWriter(config StreamConfig, fn func(w *StreamWriter) error) error {
+	if config.FlushInterval == 0 {
+		config.FlushInterval = 100 * time.Millisecond
+	}
+	if config.MaxBuffered <= 0 {
+		config.MaxBuffered = 32 << 10
+	}
+	header := c.Writer.Header()
+	if config.ContentType == "" {
+		switch config.Format {
+		case StreamNDJSON:
+			config.ContentType = MIMENDJSON
+		case StreamSSE:
+			config.ContentType = "text/event-stream"
+		default:
+			config.ContentType = "application/octet-stream"
+		}
+	}
+	header.Set("Content-Type", config.ContentType)
+	header.Del("Content-Length")
+	if config.Format == StreamSSE {
+		header.Set("Cache-Control", "no-cache")
+		header.Set("X-Accel-Buffering", "no")
+	}
+
+	sw := &StreamWriter{c: c, config: config, rc: http.NewResponseController(c.Writer)}
+	sw.buf = bufio.NewWriterSize(deadlineWriter{sw}, config.MaxBuffered)
+	c.Writer.WriteHeaderNow()
+
+	var stop chan struct{}
+	if config.FlushInterval > 0 {
+		stop = make(chan struct{})
+		go sw.flushLoop(stop)
+	}
+	err := fn(sw)
+
+	if stop != nil {
+		close(stop)
+	}
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	sw.closed = true
+	flushErr := sw.flushLocked()
+	if err != nil {
+		return err
+	}
+	return flushErr
+}
+
+// Write writes p to the stream.
+func (w *StreamWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.check(); err != nil {
+		return 0, err
+	}
+	buffered := w.buf.Buffered()
+	n, err := w.buf.Write(p)
+	if err != nil {
+		w.err = err
+		return n, err
+	}
+	w.pending = true
+	// The buffer was written to the response when it filled up.
+	if w.config.FlushInterval < 0 || w.buf.Buffered() != buffered+n {
+		return n, w.flushLocked()
+	}
+	return n, nil
+}
+
+// WriteJSON writes v encoded in JSON, followed by a newline, as NDJSON.
+func (w *StreamWriter) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// WriteEvent writes a server-sent event named name, whose data is data encoded
+// like Context.SSEvent does.
+func (w *StreamWriter) WriteEvent(name string, data any) error {
+	return sse.Encode(w, sse.Event{Event: name, Data: data})
+}
+
+// Flush sends the buffered data to the client.
+func (w *StreamWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.check(); err != nil {
+		return err
+	}
+	return w.flushLocked()
+}
+
+func (w *StreamWriter) check() error {
+	switch {
+	case w.err != nil:
+		return w.err
+	case w.closed:
+		return ErrStreamClosed
+	case w.c.IsClientGone():
+		w.err = w.c.Request.Context().Err()
+		return w.err
+	}
+	return nil
+}
+
+func (w *StreamWriter) flushLocked() error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.buf.Flush(); err != nil {
+		w.err = err
+		return err
+	}
+	if w.pending {
+		w.pending = false
+		if err := w.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			w.err = err
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *StreamWriter) flushLoop(stop chan struct{}) {
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			w.mu.Lock()
+			if w.pending && w.err == nil {
+				_ = w.flushLocked()
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// deadlineWriter writes to the response, with the write deadline of the stream.
+type deadlineWriter struct {
+	w *StreamWriter
+}
+
+func (d deadlineWriter) Write(p []byte) (int, error) {
+	if d.w.config.WriteTimeout > 0 {
+		err := d.w.rc.SetWriteDeadline(time.Now().Add(d.w.config.WriteTimeout))
+		if err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return 0, err
+		}
+	}
+	return d.w.c.Writer.Write(p)
+}
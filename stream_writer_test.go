@@ -0,0 +1,139 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamWriterNDJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Header("Content-Length", "10")
+
+	err := c.StreamWriter(StreamConfig{Format: StreamNDJSON, WriteTimeout: time.Second}, func(sw *StreamWriter) error {
+		for i := 1; i <= 3; i++ {
+			if err := sw.WriteJSON(H{"n": i}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, MIMENDJSON, w.Header().Get("Content-Type"))
+	assert.Empty(t, w.Header().Get("Content-Length"))
+	assert.Equal(t, "{\"n\":1}\n{\"n\":2}\n{\"n\":3}\n", w.Body.String())
+	assert.True(t, w.Flushed)
+}
+
+func TestStreamWriterSSE(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	err := c.StreamWriter(StreamConfig{Format: StreamSSE}, func(sw *StreamWriter) error {
+		return sw.WriteEvent("message", "hi")
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "no", w.Header().Get("X-Accel-Buffering"))
+	assert.Equal(t, "event:message\ndata:hi\n\n", w.Body.String())
+}
+
+func TestStreamWriterError(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	errQuery := errors.New("query failed")
+
+	var stream *StreamWriter
+	err := c.StreamWriter(StreamConfig{ContentType: "text/csv"}, func(sw *StreamWriter) error {
+		stream = sw
+		_, _ = sw.Write([]byte("a,b\n"))
+		return errQuery
+	})
+
+	assert.Equal(t, errQuery, err)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, "a,b\n", w.Body.String())
+	_, err = stream.Write([]byte("late"))
+	assert.Equal(t, ErrStreamClosed, err)
+	assert.Equal(t, ErrStreamClosed, stream.Flush())
+}
+
+func TestStreamWriterClientGone(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	ctx, cancel := context.WithCancel(context.Background())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+
+	writes := 0
+	err := c.StreamWriter(StreamConfig{}, func(sw *StreamWriter) error {
+		for {
+			if _, err := sw.Write([]byte("x")); err != nil {
+				return err
+			}
+			writes++
+			if writes == 2 {
+				cancel()
+			}
+		}
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 2, writes)
+}
+
+func TestStreamWriterFlushes(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	flushed := func(sw *StreamWriter) bool {
+		sw.mu.Lock()
+		defer sw.mu.Unlock()
+		return w.Flushed
+	}
+	_ = c.StreamWriter(StreamConfig{FlushInterval: 10 * time.Millisecond}, func(sw *StreamWriter) error {
+		_, _ = sw.Write([]byte("x"))
+		assert.False(t, flushed(sw))
+		assert.Eventually(t, func() bool { return flushed(sw) }, time.Second, time.Millisecond)
+		return nil
+	})
+
+	w = httptest.NewRecorder()
+	c, _ = CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	_ = c.StreamWriter(StreamConfig{FlushInterval: time.Hour, MaxBuffered: 4}, func(sw *StreamWriter) error {
+		_, _ = sw.Write([]byte("ab"))
+		assert.False(t, w.Flushed)
+		_, _ = sw.Write([]byte("cdefgh"))
+		assert.True(t, w.Flushed)
+		assert.Equal(t, "abcdefgh", w.Body.String())
+		return nil
+	})
+
+	w = httptest.NewRecorder()
+	c, _ = CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	_ = c.StreamWriter(StreamConfig{FlushInterval: -1}, func(sw *StreamWriter) error {
+		_, _ = sw.Write([]byte("a"))
+		assert.True(t, w.Flushed)
+		assert.Equal(t, "a", w.Body.String())
+		return nil
+	})
+}
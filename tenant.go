@@ -0,0 +1,396 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"container/list"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TenantKey is the context key of the *Tenant of the request, set by the Tenants
+// middleware. It is part of the Keys passed to the log formatters.
+const TenantKey = "_gin-gonic/gin/tenantkey"
+
+// Tenant is the tenant a request was resolved to.
+type Tenant struct {
+	ID string
+	// Data is what TenantConfig.Lookup returned for the tenant, e.g. its plan or
+	// its database.
+	Data any
+
+	// known is set when the tenant was checked by TenantConfig.Lookup.
+	known bool
+}
+
+// TenantResolver extracts the tenant ID of the request, reporting false when the
+// request does not name one.
+type TenantResolver func(c *Context) (string, bool)
+
+// TenantFromSubdomain resolves the tenant from the subdomain of domain in the
+// Host of the request: "acme" for "acme.example.com" with domain "example.com".
+// Deeper subdomains ("api.acme.example.com") are not resolved.
+func TenantFromSubdomain(domain string) TenantResolver {
+	suffix := "." + strings.ToLower(strings.TrimPrefix(domain, "."))
+	return func(c *Context) (string, bool) {
+		host := c.Request.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		sub, ok := strings.CutSuffix(strings.ToLower(host), suffix)
+		if !ok || sub == "" || strings.Contains(sub, ".") {
+			return "", false
+		}
+		return sub, true
+	}
+}
+
+// TenantFromHeader resolves the tenant from the request header name, e.g.
+// "X-Tenant-ID".
+func TenantFromHeader(name string) TenantResolver {
+	return func(c *Context) (string, bool) {
+		id := c.requestHeader(name)
+		return id, id != ""
+	}
+}
+
+// TenantFromPathPrefix resolves the tenant from the first segment of the path
+// of the request, for routes grouped under the tenant:
+//
+//	tenants := router.Group("/:tenant", gin.Tenants(gin.TenantFromPathPrefix()))
+func TenantFromPathPrefix() TenantResolver {
+	return func(c *Context) (string, bool) {
+		p := strings.TrimPrefix(c.Request.URL.Path, "/")
+		id, _, _ := strings.Cut(p, "/")
+		return id, id != ""
+	}
+}
+
+// TenantFromClaim resolves the tenant from a claim of the token claims stored in
+// the context under claimsKey by the authentication middleware, e.g. the
+// jwt.MapClaims of a JWT middleware. The claims must be a map with string keys
+// and the claim a string. The token must have been verified before.
+func TenantFromClaim(claimsKey, claim string) TenantResolver {
+	return func(c *Context) (string, bool) {
+		value, ok := claimValue(c, claimsKey, claim)
+		if !ok {
+			return "", false
+		}
+		id, ok := value.(string)
+		return id, ok && id != ""
+	}
+}
+
+// claimValue returns the claim of the token claims stored in the context under
+// claimsKey, a map with string keys.
+func claimValue(c *Context, claimsKey, claim string) (any, bool) {
+	claims, ok := c.Get(claimsKey)
+	if !ok {
+		return nil, false
+	}
+	v := reflect.ValueOf(claims)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	value := v.MapIndex(reflect.ValueOf(claim).Convert(v.Type().Key()))
+	if !value.IsValid() {
+		return nil, false
+	}
+	return value.Interface(), true
+}
+
+// TenantConfig defines the config of the TenantsWithConfig middleware.
+type TenantConfig struct {
+	// Resolvers are tried in order until one resolves the tenant.
+	// Required.
+	Resolvers []TenantResolver
+
+	// Lookup checks that the tenant exists and returns its Data.
+	// Optional. Default value accepts any tenant, without Data.
+	Lookup func(c *Context, id string) (data any, ok bool)
+
+	// Optional lets the requests without a tenant go on, Context.Tenant
+	// returning nil.
+	// Optional. Default value is false.
+	Optional bool
+
+	// MissingHandler is called when no resolver names a tenant.
+	// Optional. Default value aborts with 400 Bad Request.
+	MissingHandler HandlerFunc
+
+	// UnknownHandler is called when Lookup rejects the tenant.
+	// Optional. Default value aborts with 404 Not Found.
+	UnknownHandler HandlerFunc
+}
+
+// Tenants returns a middleware which resolves the tenant of the request with
+// the first of resolvers naming one, see Context.Tenant:
+//
+//	router.Use(gin.Tenants(gin.TenantFromSubdomain("example.com"), gin.TenantFromHeader("X-Tenant-ID")))
+func Tenants(resolvers ...TenantResolver) HandlerFunc {
+	return TenantsWithConfig(TenantConfig{Resolvers: resolvers})
+}
+
+// TenantsWithConfig returns a tenant resolution middleware with config, see Tenants.
+func TenantsWithConfig(config TenantConfig) HandlerFunc {
+	assert1(len(config.Resolvers) > 0, "TenantsWithConfig requires a Resolver")
+	if config.MissingHandler == nil {
+		config.MissingHandler = func(c *Context) {
+			c.AbortWithStatus(http.StatusBadRequest)
+		}
+	}
+	if config.UnknownHandler == nil {
+		config.UnknownHandler = func(c *Context) {
+			c.AbortWithStatus(http.StatusNotFound)
+		}
+	}
+	return func(c *Context) {
+		id, ok := resolveTenant(c, config.Resolvers)
+		if !ok {
+			if config.Optional {
+				c.Next()
+				return
+			}
+			config.MissingHandler(c)
+			c.Abort()
+			return
+		}
+		tenant := &Tenant{ID: id, known: config.Lookup != nil}
+		if config.Lookup != nil {
+			if tenant.Data, ok = config.Lookup(c, id); !ok {
+				config.UnknownHandler(c)
+				c.Abort()
+				return
+			}
+		}
+		c.Set(TenantKey, tenant)
+		c.Next()
+	}
+}
+
+func resolveTenant(c *Context, resolvers []TenantResolver) (string, bool) {
+	for _, resolve := range resolvers {
+		if id, ok := resolve(c); ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// Tenant returns the tenant of the request resolved by the Tenants middleware,
+// or nil.
+func (c *Context) Tenant() *Tenant {
+	if value, ok := c.Get(TenantKey); ok {
+		tenant, _ := value.(*Tenant)
+		return tenant
+	}
+	return nil
+}
+
+// TenantID returns the ID of the tenant of the request, or "".
+func (c *Context) TenantID() string {
+	if tenant := c.Tenant(); tenant != nil {
+		return tenant.ID
+	}
+	return ""
+}
+
+// TenantIs returns a predicate reporting whether the tenant of the request is
+// one of ids, to configure middleware per tenant with When:
+//
+//	router.Use(gin.When(gin.TenantIs("acme"), auditLog))
+func TenantIs(ids ...string) func(*Context) bool {
+	return func(c *Context) bool {
+		id := c.TenantID()
+		for _, want := range ids {
+			if id != "" && id == want {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// OnlyTenants returns a middleware which serves the routes only to the tenants
+// ids, and 404 Not Found to the others, for route groups reserved to some
+// tenants:
+//
+//	beta := router.Group("/beta", gin.OnlyTenants("acme", "globex"))
+func OnlyTenants(ids ...string) HandlerFunc {
+	allowed := TenantIs(ids...)
+	return func(c *Context) {
+		if !allowed(c) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.Next()
+	}
+}
+
+// TenantMiddleware returns a middleware which runs the middleware of the tenant
+// of the request in perTenant, if any, then the next handlers.
+func TenantMiddleware(perTenant map[string]HandlerFunc) HandlerFunc {
+	return func(c *Context) {
+		if middleware := perTenant[c.TenantID()]; middleware != nil {
+			middleware(c)
+		}
+	}
+}
+
+// TenantLogger returns a Logger middleware writing the log of each tenant to
+// its writer in outputs, and the log of the other requests to conf.Output.
+// Conf is shared by all the tenants otherwise. It must be used after the
+// Tenants middleware.
+func TenantLogger(conf LoggerConfig, outputs map[string]io.Writer) HandlerFunc {
+	fallback := LoggerWithConfig(conf)
+	loggers := make(map[string]HandlerFunc, len(outputs))
+	for id, out := range outputs {
+		tenantConf := conf
+		tenantConf.Output = out
+		loggers[id] = LoggerWithConfig(tenantConf)
+	}
+	return func(c *Context) {
+		if logger, ok := loggers[c.TenantID()]; ok {
+			logger(c)
+			return
+		}
+		fallback(c)
+	}
+}
+
+// TenantRate is the rate limit of a tenant: Rate requests per second on
+// average, with bursts of up to Burst requests.
+type TenantRate struct {
+	Rate  float64
+	Burst int
+}
+
+// TenantRateLimit returns a middleware limiting the rate of the requests of each
+// tenant to its rate in perTenant, or to def, with a token bucket per tenant.
+// The requests over the limit are answered 429 Too Many Requests with a
+// Retry-After header. The requests without a tenant, or of a tenant with a
+// zero rate, are not limited.
+//
+// Since any client can name a tenant, def only applies to the tenants checked
+// by the Lookup of TenantsWithConfig: the requests of the other tenants which
+// are not in perTenant are answered 404 Not Found.
+func TenantRateLimit(def TenantRate, perTenant map[string]TenantRate) HandlerFunc {
+	limiter := newTenantLimiter(def, perTenant, 0)
+	return func(c *Context) {
+		tenant := c.Tenant()
+		if tenant == nil || tenant.ID == "" {
+			c.Next()
+			return
+		}
+		if _, ok := perTenant[tenant.ID]; !ok && !tenant.known {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if wait, ok := limiter.allow(tenant.ID, time.Now()); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatus(http.StatusTooManyRequests)
+			return
+		}
+		c.Next()
+	}
+}
+
+// tenantBucketsSweep is how often the full buckets are removed, see tenantLimiter.sweep.
+const tenantBucketsSweep = time.Minute
+
+type tenantLimiter struct {
+	mu      sync.Mutex
+	def     TenantRate
+	rates   map[string]TenantRate
+	buckets map[string]*tokenBucket
+	swept   time.Time
+	// maxBuckets is the maximum number of buckets, beyond which the least
+	// recently used ones are removed, 0 for no limit. lru holds the buckets,
+	// from the most to the least recently used.
+	maxBuckets int
+	lru        *list.List
+}
+
+func newTenantLimiter(def TenantRate, rates map[string]TenantRate, maxBuckets int) *tenantLimiter {
+	return &tenantLimiter{
+		def:        def,
+		rates:      rates,
+		buckets:    make(map[string]*tokenBucket),
+		maxBuckets: maxBuckets,
+		lru:        list.New(),
+	}
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	// full is when the bucket is full again.
+	full time.Time
+	elem *list.Element
+}
+
+// allow takes a token from the bucket of the tenant id, or reports how long to
+// wait for the next one.
+func (l *tenantLimiter) allow(id string, now time.Time) (time.Duration, bool) {
+	rate, ok := l.rates[id]
+	if !ok {
+		rate = l.def
+	}
+	if rate.Rate <= 0 {
+		return 0, true
+	}
+	burst := float64(rate.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+	b := l.buckets[id]
+	if b == nil {
+		if l.maxBuckets > 0 && len(l.buckets) >= l.maxBuckets {
+			l.remove(l.lru.Back().Value.(string))
+		}
+		b = &tokenBucket{tokens: burst, last: now, elem: l.lru.PushFront(id)}
+		l.buckets[id] = b
+	} else {
+		l.lru.MoveToFront(b.elem)
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate.Rate)
+	b.last = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / rate.Rate * float64(time.Second)), false
+	}
+	b.tokens--
+	b.full = now.Add(time.Duration((burst - b.tokens) / rate.Rate * float64(time.Second)))
+	return 0, true
+}
+
+// sweep removes the buckets which are full again, since a new bucket is the
+// same, so that the buckets of the idle tenants do not pile up.
+func (l *tenantLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < tenantBucketsSweep {
+		return
+	}
+	l.swept = now
+	for id, b := range l.buckets {
+		if !now.Before(b.full) {
+			l.remove(id)
+		}
+	}
+}
+
+func (l *tenantLimiter) remove(id string) {
+	l.lru.Remove(l.buckets[id].elem)
+	delete(l.buckets, id)
+}
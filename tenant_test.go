@@ -0,0 +1,209 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tenantRouter(middleware ...HandlerFunc) *Engine {
+	router := New()
+	router.Use(middleware...)
+	router.GET("/*path", func(c *Context) {
+		c.String(http.StatusOK, c.TenantID())
+	})
+	return router
+}
+
+func TestTenantResolvers(t *testing.T) {
+	router := tenantRouter(Tenants(TenantFromSubdomain("example.com"), TenantFromHeader("X-Tenant-ID")))
+
+	w := PerformRequest(router, http.MethodGet, "http://acme.example.com:8080/")
+	assert.Equal(t, "acme", w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "http://api.acme.example.com/", header{"X-Tenant-ID", "globex"})
+	assert.Equal(t, "globex", w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "http://example.com/")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	router = tenantRouter(Tenants(TenantFromPathPrefix()))
+	w = PerformRequest(router, http.MethodGet, "/initech/users")
+	assert.Equal(t, "initech", w.Body.String())
+}
+
+type testClaims map[string]any
+
+func TestTenantFromClaim(t *testing.T) {
+	router := tenantRouter(func(c *Context) {
+		if tenant := c.Query("tenant"); tenant != "" {
+			c.Set("claims", testClaims{"tid": tenant, "n": 1})
+		}
+	}, TenantsWithConfig(TenantConfig{
+		Resolvers: []TenantResolver{TenantFromClaim("claims", "tid"), TenantFromClaim("claims", "n")},
+		Optional:  true,
+	}))
+
+	w := PerformRequest(router, http.MethodGet, "/?tenant=acme")
+	assert.Equal(t, "acme", w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestTenantLookup(t *testing.T) {
+	plans := map[string]string{"acme": "pro"}
+	router := New()
+	router.Use(TenantsWithConfig(TenantConfig{
+		Resolvers: []TenantResolver{TenantFromHeader("X-Tenant-ID")},
+		Lookup: func(c *Context, id string) (any, bool) {
+			plan, ok := plans[id]
+			return plan, ok
+		},
+	}))
+	router.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "%s %v", c.Tenant().ID, c.Tenant().Data)
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/", header{"X-Tenant-ID", "acme"})
+	assert.Equal(t, "acme pro", w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/", header{"X-Tenant-ID", "umbrella"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTenantsRequiresResolver(t *testing.T) {
+	assert.Panics(t, func() { Tenants() })
+}
+
+func TestTenantMiddlewareConfig(t *testing.T) {
+	router := New()
+	router.Use(Tenants(TenantFromHeader("X-Tenant-ID")))
+	router.Use(When(TenantIs("acme"), func(c *Context) { c.Header("X-Audit", "1") }))
+	router.Use(TenantMiddleware(map[string]HandlerFunc{
+		"globex": func(c *Context) { c.Header("X-Globex", "1") },
+	}))
+	beta := router.Group("/beta", OnlyTenants("acme"))
+	beta.GET("", func(c *Context) { c.String(http.StatusOK, "beta") })
+	router.GET("/", func(c *Context) {})
+
+	w := PerformRequest(router, http.MethodGet, "/beta", header{"X-Tenant-ID", "acme"})
+	assert.Equal(t, "beta", w.Body.String())
+	assert.Equal(t, "1", w.Header().Get("X-Audit"))
+	assert.Empty(t, w.Header().Get("X-Globex"))
+
+	w = PerformRequest(router, http.MethodGet, "/beta", header{"X-Tenant-ID", "globex"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-Globex"))
+	assert.Empty(t, w.Header().Get("X-Audit"))
+}
+
+func TestTenantLogger(t *testing.T) {
+	var acme, other bytes.Buffer
+	router := tenantRouter(
+		TenantsWithConfig(TenantConfig{Resolvers: []TenantResolver{TenantFromHeader("X-Tenant-ID")}, Optional: true}),
+		TenantLogger(LoggerConfig{Output: &other}, map[string]io.Writer{"acme": &acme}),
+	)
+
+	PerformRequest(router, http.MethodGet, "/a", header{"X-Tenant-ID", "acme"})
+	PerformRequest(router, http.MethodGet, "/b", header{"X-Tenant-ID", "globex"})
+	PerformRequest(router, http.MethodGet, "/c")
+
+	assert.Contains(t, acme.String(), "/a")
+	assert.NotContains(t, acme.String(), "/b")
+	assert.Contains(t, other.String(), "/b")
+	assert.Contains(t, other.String(), "/c")
+}
+
+func TestTenantRateLimit(t *testing.T) {
+	router := tenantRouter(
+		TenantsWithConfig(TenantConfig{
+			Resolvers: []TenantResolver{TenantFromHeader("X-Tenant-ID")},
+			Lookup: func(c *Context, id string) (any, bool) {
+				return nil, id != "unknown"
+			},
+			Optional: true,
+		}),
+		TenantRateLimit(TenantRate{Rate: 1, Burst: 2}, map[string]TenantRate{"acme": {Rate: 0.5}, "free": {}}),
+	)
+
+	for i := 0; i < 2; i++ {
+		w := PerformRequest(router, http.MethodGet, "/", header{"X-Tenant-ID", "globex"})
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	w := PerformRequest(router, http.MethodGet, "/", header{"X-Tenant-ID", "globex"})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	w = PerformRequest(router, http.MethodGet, "/", header{"X-Tenant-ID", "acme"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = PerformRequest(router, http.MethodGet, "/", header{"X-Tenant-ID", "acme"})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, PerformRequest(router, http.MethodGet, "/", header{"X-Tenant-ID", "free"}).Code)
+		assert.Equal(t, http.StatusOK, PerformRequest(router, http.MethodGet, "/").Code)
+	}
+}
+
+func TestTenantRateLimitUnknownTenants(t *testing.T) {
+	router := tenantRouter(
+		Tenants(TenantFromHeader("X-Tenant-ID")),
+		TenantRateLimit(TenantRate{Rate: 1, Burst: 2}, map[string]TenantRate{"acme": {Rate: 1}}),
+	)
+
+	// without a Lookup, only the tenants with a rate are served.
+	w := PerformRequest(router, http.MethodGet, "/", header{"X-Tenant-ID", "acme"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = PerformRequest(router, http.MethodGet, "/", header{"X-Tenant-ID", "random"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTenantLimiterRefill(t *testing.T) {
+	l := newTenantLimiter(TenantRate{Rate: 10, Burst: 1}, nil, 0)
+	now := time.Now()
+	_, ok := l.allow("acme", now)
+	assert.True(t, ok)
+	wait, ok := l.allow("acme", now)
+	assert.False(t, ok)
+	assert.Equal(t, 100*time.Millisecond, wait)
+	_, ok = l.allow("acme", now.Add(100*time.Millisecond))
+	assert.True(t, ok)
+}
+
+func TestTenantLimiterSweep(t *testing.T) {
+	// a token comes back in 100s.
+	l := newTenantLimiter(TenantRate{Rate: 0.01, Burst: 2}, nil, 0)
+	now := time.Now()
+	l.allow("acme", now)
+	l.allow("globex", now.Add(tenantBucketsSweep))
+	assert.Len(t, l.buckets, 2)
+
+	// only the full buckets are removed.
+	l.allow("initech", now.Add(150*time.Second))
+	assert.Len(t, l.buckets, 2)
+	assert.NotContains(t, l.buckets, "acme")
+}
+
+func TestTenantLimiterMaxBuckets(t *testing.T) {
+	l := newTenantLimiter(TenantRate{Rate: 1, Burst: 1}, nil, 2)
+	now := time.Now()
+	for _, id := range []string{"a", "b", "a", "c"} {
+		l.allow(id, now)
+	}
+	// b is the least recently used.
+	assert.Len(t, l.buckets, 2)
+	assert.Contains(t, l.buckets, "a")
+	assert.Contains(t, l.buckets, "c")
+	assert.Equal(t, 2, l.lru.Len())
+}
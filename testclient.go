@@ -0,0 +1,345 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/internal/json"
+)
+
+// TestingT is the subset of *testing.T (and *testing.B) used by TestClient.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+	FailNow()
+}
+
+// TestClient performs requests against an http.Handler (usually an Engine)
+// without a network connection, which removes the httptest boilerplate of
+// handler tests:
+//
+//	client := gin.NewTestClient(router)
+//	client.POST("/users").WithJSON(gin.H{"name": "bob"}).Do(t).
+//		AssertStatus(http.StatusCreated).
+//		AssertJSONPath("name", "bob")
+//
+// Cookies set by responses are sent back with the following requests, and all
+// the exchanges are recorded, see History.
+type TestClient struct {
+	handler http.Handler
+	header  http.Header
+	jar     http.CookieJar
+
+	mu      sync.Mutex
+	history []*TestResponse
+}
+
+var testClientURL = &url.URL{Scheme: "http", Host: "example.com", Path: "/"}
+
+// NewTestClient returns a TestClient for handler.
+func NewTestClient(handler http.Handler) *TestClient {
+	jar, _ := cookiejar.New(nil)
+	return &TestClient{handler: handler, header: make(http.Header), jar: jar}
+}
+
+// SetHeader sets a header sent with every request of the client.
+func (tc *TestClient) SetHeader(key, value string) *TestClient {
+	tc.header.Set(key, value)
+	return tc
+}
+
+// History returns the responses received so far, in order.
+func (tc *TestClient) History() []*TestResponse {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return append([]*TestResponse(nil), tc.history...)
+}
+
+// Request starts a request with the given method and path (which may include a query string).
+func (tc *TestClient) Request(method, path string) *TestRequest {
+	return &TestRequest{client: tc, method: method, path: path, header: tc.header.Clone(), query: url.Values{}}
+}
+
+// GET is a shortcut for tc.Request(http.MethodGet, path).
+func (tc *TestClient) GET(path string) *TestRequest {
+	return tc.Request(http.MethodGet, path)
+}
+
+// POST is a shortcut for tc.Request(http.MethodPost, path).
+func (tc *TestClient) POST(path string) *TestRequest {
+	return tc.Request(http.MethodPost, path)
+}
+
+// PUT is a shortcut for tc.Request(http.MethodPut, path).
+func (tc *TestClient) PUT(path string) *TestRequest {
+	return tc.Request(http.MethodPut, path)
+}
+
+// PATCH is a shortcut for tc.Request(http.MethodPatch, path).
+func (tc *TestClient) PATCH(path string) *TestRequest {
+	return tc.Request(http.MethodPatch, path)
+}
+
+// DELETE is a shortcut for tc.Request(http.MethodDelete, path).
+func (tc *TestClient) DELETE(path string) *TestRequest {
+	return tc.Request(http.MethodDelete, path)
+}
+
+// HEAD is a shortcut for tc.Request(http.MethodHead, path).
+func (tc *TestClient) HEAD(path string) *TestRequest {
+	return tc.Request(http.MethodHead, path)
+}
+
+// OPTIONS is a shortcut for tc.Request(http.MethodOptions, path).
+func (tc *TestClient) OPTIONS(path string) *TestRequest {
+	return tc.Request(http.MethodOptions, path)
+}
+
+// TestRequest is a request being built by a TestClient.
+type TestRequest struct {
+	client  *TestClient
+	method  string
+	path    string
+	header  http.Header
+	query   url.Values
+	cookies []*http.Cookie
+	body    []byte
+	err     error
+}
+
+// WithHeader sets a header of the request.
+func (r *TestRequest) WithHeader(key, value string) *TestRequest {
+	r.header.Set(key, value)
+	return r
+}
+
+// WithQuery adds a query parameter to the request.
+func (r *TestRequest) WithQuery(key, value string) *TestRequest {
+	r.query.Add(key, value)
+	return r
+}
+
+// WithCookie adds a cookie to the request.
+func (r *TestRequest) WithCookie(cookie *http.Cookie) *TestRequest {
+	r.cookies = append(r.cookies, cookie)
+	return r
+}
+
+// WithBody sets the body of the request and its content type.
+func (r *TestRequest) WithBody(contentType string, body []byte) *TestRequest {
+	r.header.Set("Content-Type", contentType)
+	r.body = body
+	return r
+}
+
+// WithJSON sets the JSON encoding of obj as the body of the request.
+func (r *TestRequest) WithJSON(obj any) *TestRequest {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		r.err = err
+	}
+	return r.WithBody(MIMEJSON, body)
+}
+
+// WithForm sets the url-encoded form as the body of the request.
+func (r *TestRequest) WithForm(form url.Values) *TestRequest {
+	return r.WithBody(MIMEPOSTForm, []byte(form.Encode()))
+}
+
+// Do performs the request. Building errors fail the test immediately.
+func (r *TestRequest) Do(t TestingT) *TestResponse {
+	t.Helper()
+	if r.err != nil {
+		t.Errorf("gin: can not build %s %s request: %v", r.method, r.path, r.err)
+		t.FailNow()
+	}
+
+	path := r.path
+	if len(r.query) > 0 {
+		sep := "?"
+		if strings.Contains(path, "?") {
+			sep = "&"
+		}
+		path += sep + r.query.Encode()
+	}
+	req := httptest.NewRequest(r.method, path, bytes.NewReader(r.body))
+	req.Header = r.header
+	for _, cookie := range r.client.jar.Cookies(testClientURL) {
+		req.AddCookie(cookie)
+	}
+	for _, cookie := range r.cookies {
+		req.AddCookie(cookie)
+	}
+
+	w := httptest.NewRecorder()
+	r.client.handler.ServeHTTP(w, req)
+
+	resp := &TestResponse{ResponseRecorder: w, Request: req, t: t}
+	r.client.jar.SetCookies(testClientURL, w.Result().Cookies())
+	r.client.mu.Lock()
+	r.client.history = append(r.client.history, resp)
+	r.client.mu.Unlock()
+	return resp
+}
+
+// TestResponse is a response recorded by a TestClient, with assertion helpers.
+// The helpers report failures with t.Errorf and return the response, so that
+// they can be chained.
+type TestResponse struct {
+	*httptest.ResponseRecorder
+
+	// Request is the request which produced the response.
+	Request *http.Request
+
+	t TestingT
+}
+
+// AssertStatus checks the status code of the response.
+func (resp *TestResponse) AssertStatus(code int) *TestResponse {
+	resp.t.Helper()
+	if resp.Code != code {
+		resp.t.Errorf("%s %s: expected status %d, got %d", resp.Request.Method, resp.Request.URL, code, resp.Code)
+	}
+	return resp
+}
+
+// AssertHeader checks a header of the response.
+func (resp *TestResponse) AssertHeader(key, value string) *TestResponse {
+	resp.t.Helper()
+	if got := resp.Header().Get(key); got != value {
+		resp.t.Errorf("%s %s: expected header %s to be %q, got %q", resp.Request.Method, resp.Request.URL, key, value, got)
+	}
+	return resp
+}
+
+// AssertBody checks the body of the response.
+func (resp *TestResponse) AssertBody(body string) *TestResponse {
+	resp.t.Helper()
+	if got := resp.Body.String(); got != body {
+		resp.t.Errorf("%s %s: expected body %q, got %q", resp.Request.Method, resp.Request.URL, body, got)
+	}
+	return resp
+}
+
+// AssertBodyContains checks that the body of the response contains s.
+func (resp *TestResponse) AssertBodyContains(s string) *TestResponse {
+	resp.t.Helper()
+	if got := resp.Body.String(); !strings.Contains(got, s) {
+		resp.t.Errorf("%s %s: expected body to contain %q, got %q", resp.Request.Method, resp.Request.URL, s, got)
+	}
+	return resp
+}
+
+// AssertJSON checks that the body of the response is a JSON document equal to
+// expected, which is either a JSON string or a value to be encoded.
+func (resp *TestResponse) AssertJSON(expected any) *TestResponse {
+	resp.t.Helper()
+	want, err := normalizeJSON(expected)
+	if err != nil {
+		resp.t.Errorf("gin: invalid expected JSON: %v", err)
+		return resp
+	}
+	var got any
+	if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+		resp.t.Errorf("%s %s: body is not JSON: %v", resp.Request.Method, resp.Request.URL, err)
+		return resp
+	}
+	if !reflect.DeepEqual(want, got) {
+		resp.t.Errorf("%s %s: expected JSON %v, got %s", resp.Request.Method, resp.Request.URL, want, resp.Body.String())
+	}
+	return resp
+}
+
+// AssertJSONPath checks a value of the JSON body of the response, designated by
+// a dot separated path of object keys and array indexes, e.g. "users.0.name".
+func (resp *TestResponse) AssertJSONPath(path string, expected any) *TestResponse {
+	resp.t.Helper()
+	got, err := resp.JSONPath(path)
+	if err != nil {
+		resp.t.Errorf("%s %s: %v", resp.Request.Method, resp.Request.URL, err)
+		return resp
+	}
+	want, err := normalizeJSON(expected)
+	if err != nil {
+		resp.t.Errorf("gin: invalid expected JSON: %v", err)
+		return resp
+	}
+	if !reflect.DeepEqual(want, got) {
+		resp.t.Errorf("%s %s: expected %s to be %v, got %v", resp.Request.Method, resp.Request.URL, path, want, got)
+	}
+	return resp
+}
+
+// JSONPath returns the value of the JSON body designated by path, see AssertJSONPath.
+func (resp *TestResponse) JSONPath(path string) (any, error) {
+	var v any
+	if err := json.Unmarshal(resp.Body.Bytes(), &v); err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return v, nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			var ok bool
+			if v, ok = node[key]; !ok {
+				return nil, fmt.Errorf("JSON path %q: key %q not found", path, key)
+			}
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("JSON path %q: invalid index %q", path, key)
+			}
+			v = node[i]
+		default:
+			return nil, fmt.Errorf("JSON path %q: %q is not an object or an array", path, key)
+		}
+	}
+	return v, nil
+}
+
+// DecodeJSON decodes the JSON body of the response into obj.
+func (resp *TestResponse) DecodeJSON(obj any) *TestResponse {
+	resp.t.Helper()
+	if err := json.Unmarshal(resp.Body.Bytes(), obj); err != nil {
+		resp.t.Errorf("%s %s: can not decode JSON body: %v", resp.Request.Method, resp.Request.URL, err)
+	}
+	return resp
+}
+
+// normalizeJSON converts v to the generic form produced by decoding JSON into an any.
+func normalizeJSON(v any) (any, error) {
+	var data []byte
+	switch v := v.(type) {
+	case string:
+		var out any
+		if err := json.Unmarshal([]byte(v), &out); err != nil {
+			// a plain string value
+			return v, nil
+		}
+		return out, nil
+	case []byte:
+		data = v
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	var out any
+	err := json.Unmarshal(data, &out)
+	return out, err
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingT struct {
+	errors []string
+	failed bool
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) FailNow() { t.failed = true }
+
+func testClientRouter() *Engine {
+	router := New()
+	router.POST("/users", func(c *Context) {
+		var user struct {
+			Name string `json:"name"`
+		}
+		_ = c.ShouldBindJSON(&user)
+		c.SetCookie("session", "abc", 0, "/", "", false, false)
+		c.JSON(http.StatusCreated, H{"name": user.Name, "tags": []string{"a", "b"}})
+	})
+	router.GET("/me", func(c *Context) {
+		session, _ := c.Cookie("session")
+		c.Header("X-Session", session)
+		c.String(http.StatusOK, "%s %s %s", c.Query("lang"), c.GetHeader("X-Token"), c.PostForm("a"))
+	})
+	router.PUT("/form", func(c *Context) {
+		c.String(http.StatusOK, c.PostForm("a"))
+	})
+	return router
+}
+
+func TestTestClient(t *testing.T) {
+	client := NewTestClient(testClientRouter()).SetHeader("X-Token", "secret")
+
+	client.POST("/users").WithJSON(H{"name": "bob"}).Do(t).
+		AssertStatus(http.StatusCreated).
+		AssertHeader("Content-Type", "application/json; charset=utf-8").
+		AssertJSON(`{"name":"bob","tags":["a","b"]}`).
+		AssertJSON(H{"name": "bob", "tags": []string{"a", "b"}}).
+		AssertJSONPath("name", "bob").
+		AssertJSONPath("tags.1", "b")
+
+	client.GET("/me?x=1").WithQuery("lang", "fr").Do(t).
+		AssertStatus(http.StatusOK).
+		AssertHeader("X-Session", "abc").
+		AssertBody("fr secret ").
+		AssertBodyContains("secret")
+
+	client.PUT("/form").WithForm(url.Values{"a": {"1"}}).Do(t).AssertBody("1")
+
+	var out struct {
+		Name string `json:"name"`
+	}
+	resp := client.Request(http.MethodPost, "/users").WithBody(MIMEJSON, []byte(`{"name":"ann"}`)).Do(t).DecodeJSON(&out)
+	assert.Equal(t, "ann", out.Name)
+	assert.Equal(t, http.MethodPost, resp.Request.Method)
+	assert.Len(t, client.History(), 4)
+}
+
+func TestTestClientFailures(t *testing.T) {
+	rt := &recordingT{}
+	client := NewTestClient(testClientRouter())
+
+	resp := client.POST("/users").WithJSON(H{"name": "bob"}).Do(rt)
+	resp.AssertStatus(http.StatusOK).
+		AssertHeader("X-Missing", "x").
+		AssertBody("nope").
+		AssertBodyContains("nope").
+		AssertJSON(`{"name":"ann"}`).
+		AssertJSONPath("tags.5", "x").
+		AssertJSONPath("name", "ann")
+	assert.Len(t, rt.errors, 7)
+	assert.False(t, rt.failed)
+
+	client.GET("/").WithJSON(make(chan int)).Do(rt)
+	assert.True(t, rt.failed)
+
+	_, err := resp.JSONPath("name.x")
+	assert.Error(t, err)
+}
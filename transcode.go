@@ -0,0 +1,316 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// TranscodeRule maps a protobuf service method to an HTTP route, the same way
+// a google.api.http annotation does.
+type TranscodeRule struct {
+	// Method is the HTTP method of the route.
+	Method string
+
+	// Path is a google.api.http path template. Variables are bound to the fields
+	// of the request message: {name} and {name=*} match one segment, {name=**}
+	// matches the rest of the path. Nested fields are referred to with dots, e.g.
+	// /v1/users/{user.id}.
+	Path string
+
+	// Body is the field of the request message the body is decoded into, or "*"
+	// to decode the body into the whole request message. When empty, the request
+	// has no body. The fields which are neither bound by the path nor by the body
+	// are read from the query string.
+	Body string
+
+	// MaxBodySize limits the size of the request body in bytes. Larger bodies
+	// are answered with a 413.
+	// Optional. Default value is 4 MiB, the default limit of gRPC servers.
+	MaxBodySize int64
+}
+
+const defaultTranscodeMaxBodySize = 4 << 20
+
+// TranscodeError is implemented by the errors returned by transcoded methods
+// which carry an HTTP status code. Other errors are answered with a 500.
+type TranscodeError interface {
+	error
+	HTTPStatus() int
+}
+
+type transcodeParam struct {
+	name  string
+	field string
+}
+
+// Transcode registers a route serving a protobuf service method over HTTP.
+// The request message is built from the path variables, the body and the query
+// string, as described by rule, and call is invoked with it. Since call has the
+// signature of a gRPC server method, the same service implementation can serve
+// both gRPC and REST clients:
+//
+//	gin.Transcode(router, gin.TranscodeRule{Method: http.MethodGet, Path: "/v1/users/{id}"}, srv.GetUser)
+//
+// Request and response bodies are JSON (protojson), or binary protobuf when the
+// Content-Type or Accept header of the request is application/x-protobuf.
+func Transcode[Req, Resp proto.Message](routes IRoutes, rule TranscodeRule, call func(context.Context, Req) (Resp, error)) IRoutes {
+	path, params := transcodePath(rule.Path)
+	var zero Req
+	reqType := zero.ProtoReflect().Type()
+	if rule.MaxBodySize <= 0 {
+		rule.MaxBodySize = defaultTranscodeMaxBodySize
+	}
+
+	return routes.Handle(rule.Method, path, func(c *Context) {
+		msg := reqType.New()
+		if err := transcodeRequest(c, msg, rule, params); err != nil {
+			status := http.StatusBadRequest
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			_ = c.AbortWithError(status, err).SetType(ErrorTypeBind)
+			return
+		}
+
+		resp, err := call(c.Request.Context(), msg.Interface().(Req))
+		if err != nil {
+			status := http.StatusInternalServerError
+			var terr TranscodeError
+			if errors.As(err, &terr) {
+				status = terr.HTTPStatus()
+			}
+			_ = c.Error(err)
+			c.AbortWithStatusJSON(status, H{"code": status, "message": err.Error()})
+			return
+		}
+
+		if c.NegotiateFormat(MIMEJSON, binding.MIMEPROTOBUF) == binding.MIMEPROTOBUF {
+			c.ProtoBuf(http.StatusOK, resp)
+			return
+		}
+		data, err := protojson.Marshal(resp)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		c.Data(http.StatusOK, MIMEJSON+"; charset=utf-8", data)
+	})
+}
+
+// transcodePath converts a google.api.http path template to a gin path.
+func transcodePath(template string) (string, []transcodeParam) {
+	var b strings.Builder
+	var params []transcodeParam
+	for i := 0; i < len(template); i++ {
+		if template[i] != '{' {
+			b.WriteByte(template[i])
+			continue
+		}
+		end := strings.IndexByte(template[i:], '}')
+		if end < 0 {
+			panic("unterminated variable in path template " + template)
+		}
+		field, pattern, _ := strings.Cut(template[i+1:i+end], "=")
+		name := strings.ReplaceAll(field, ".", "_")
+		switch pattern {
+		case "", "*":
+			b.WriteString(":" + name)
+		case "**":
+			if i+end+1 != len(template) {
+				panic("'**' must be the last segment of path template " + template)
+			}
+			b.WriteString("*" + name)
+		default:
+			panic(fmt.Sprintf("unsupported variable pattern %q in path template %s", pattern, template))
+		}
+		params = append(params, transcodeParam{name: name, field: field})
+		i += end
+	}
+	return b.String(), params
+}
+
+func transcodeRequest(c *Context, msg protoreflect.Message, rule TranscodeRule, params []transcodeParam) error {
+	bound := make(map[string]bool, len(params)+1)
+
+	body := rule.Body
+	if body != "" && c.Request.Body != nil {
+		data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, rule.MaxBodySize))
+		if err != nil {
+			return err
+		}
+		if len(data) > 0 {
+			target := msg
+			if body != "*" {
+				fd := protoField(msg, body)
+				if fd == nil || fd.Message() == nil || fd.IsList() || fd.IsMap() {
+					return fmt.Errorf("body field %q is not a message field", body)
+				}
+				target = msg.Mutable(fd).Message()
+			}
+			if c.ContentType() == binding.MIMEPROTOBUF {
+				err = proto.Unmarshal(data, target.Interface())
+			} else {
+				err = protojson.Unmarshal(data, target.Interface())
+			}
+			if err != nil {
+				return err
+			}
+		}
+		bound[body] = true
+	}
+
+	for _, p := range params {
+		value := c.Param(p.name)
+		if strings.HasPrefix(value, "/") {
+			value = value[1:]
+		}
+		if err := setProtoField(msg, p.field, []string{value}); err != nil {
+			return err
+		}
+		bound[p.field] = true
+	}
+
+	if bound["*"] {
+		return nil
+	}
+	for key, values := range c.Request.URL.Query() {
+		if bound[key] || protoFieldPath(msg, key) == nil {
+			continue
+		}
+		if err := setProtoField(msg, key, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func protoField(msg protoreflect.Message, name string) protoreflect.FieldDescriptor {
+	fields := msg.Descriptor().Fields()
+	if fd := fields.ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	return fields.ByJSONName(name)
+}
+
+// protoFieldPath returns the descriptor of the field at the dotted path, or nil.
+func protoFieldPath(msg protoreflect.Message, path string) protoreflect.FieldDescriptor {
+	desc := msg.Descriptor()
+	var fd protoreflect.FieldDescriptor
+	for _, name := range strings.Split(path, ".") {
+		if desc == nil {
+			return nil
+		}
+		if fd = desc.Fields().ByName(protoreflect.Name(name)); fd == nil {
+			if fd = desc.Fields().ByJSONName(name); fd == nil {
+				return nil
+			}
+		}
+		desc = fd.Message()
+	}
+	return fd
+}
+
+func setProtoField(msg protoreflect.Message, path string, values []string) error {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		fd := protoField(msg, name)
+		if fd == nil {
+			return fmt.Errorf("unknown field %q in %s", path, msg.Descriptor().FullName())
+		}
+		if i < len(names)-1 {
+			if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+				return fmt.Errorf("field %q of %s is not a message", name, msg.Descriptor().FullName())
+			}
+			msg = msg.Mutable(fd).Message()
+			continue
+		}
+
+		if fd.IsMap() {
+			return fmt.Errorf("map field %q can not be bound from a string", path)
+		}
+		if fd.IsList() {
+			list := msg.Mutable(fd).List()
+			for _, s := range values {
+				v, err := parseProtoValue(fd, s, list.NewElement)
+				if err != nil {
+					return err
+				}
+				list.Append(v)
+			}
+			return nil
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		v, err := parseProtoValue(fd, values[0], func() protoreflect.Value { return msg.NewField(fd) })
+		if err != nil {
+			return err
+		}
+		msg.Set(fd, v)
+	}
+	return nil
+}
+
+// parseProtoValue parses s into a value of the kind of fd. newValue returns an
+// empty value of the field, used for message kinds.
+func parseProtoValue(fd protoreflect.FieldDescriptor, s string, newValue func() protoreflect.Value) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(s)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(s, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(s, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(s, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.BytesKind:
+		v, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			v, err = base64.URLEncoding.DecodeString(s)
+		}
+		return protoreflect.ValueOfBytes(v), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(s)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		v, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), err
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		// well-known types (Timestamp, Duration, wrappers...) have a string JSON form.
+		v := newValue()
+		err := protojson.Unmarshal([]byte(strconv.Quote(s)), v.Message().Interface())
+		return v, err
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported field kind %s", fd.Kind())
+}
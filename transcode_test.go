@@ -0,0 +1,108 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/testdata/protoexample"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+type notFoundError struct{}
+
+func (notFoundError) Error() string   { return "not found" }
+func (notFoundError) HTTPStatus() int { return http.StatusNotFound }
+
+func echoTest(_ context.Context, req *protoexample.Test) (*protoexample.Test, error) {
+	switch req.GetLabel() {
+	case "missing":
+		return nil, notFoundError{}
+	case "fail":
+		return nil, errors.New("boom")
+	}
+	return req, nil
+}
+
+func TestTranscodePath(t *testing.T) {
+	path, params := transcodePath("/v1/{label}/items/{group.name=*}/{rest=**}")
+	assert.Equal(t, "/v1/:label/items/:group_name/*rest", path)
+	assert.Equal(t, []transcodeParam{{"label", "label"}, {"group_name", "group.name"}, {"rest", "rest"}}, params)
+
+	assert.Panics(t, func() { transcodePath("/v1/{name=shelves/*}") })
+	assert.Panics(t, func() { transcodePath("/v1/{name=**}/x") })
+	assert.Panics(t, func() { transcodePath("/v1/{name") })
+}
+
+func TestTranscode(t *testing.T) {
+	router := New()
+	Transcode(router, TranscodeRule{Method: http.MethodGet, Path: "/v1/tests/{label}"}, echoTest)
+	Transcode(router, TranscodeRule{Method: http.MethodPost, Path: "/v1/tests/{label}", Body: "*"}, echoTest)
+
+	w := PerformRequest(router, http.MethodGet, "/v1/tests/foo?type=5&reps=1&reps=2&unknown=x")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"label":"foo","type":5,"reps":["1","2"]}`, w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/v1/tests/foo?type=abc")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = PerformRequest(router, http.MethodGet, "/v1/tests/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"code":404,"message":"not found"}`, w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/v1/tests/fail")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	// the path variable wins over the body
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tests/bar", strings.NewReader(`{"label":"baz","type":3}`))
+	req.Header.Set("Content-Type", MIMEJSON)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"label":"bar","type":3}`, w.Body.String())
+
+	// binary protobuf in and out
+	body, _ := proto.Marshal(&protoexample.Test{Label: proto.String("x"), Reps: []int64{7}})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/v1/tests/bin", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", binding.MIMEPROTOBUF)
+	req.Header.Set("Accept", binding.MIMEPROTOBUF)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp protoexample.Test
+	assert.NoError(t, proto.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "bin", resp.GetLabel())
+	assert.Equal(t, []int64{7}, resp.GetReps())
+}
+
+func TestTranscodeLimitsAndContext(t *testing.T) {
+	type ctxKey struct{}
+	router := New()
+	Transcode(router, TranscodeRule{Method: http.MethodPost, Path: "/v1/tests", Body: "*", MaxBodySize: 16},
+		func(ctx context.Context, req *protoexample.Test) (*protoexample.Test, error) {
+			label, _ := ctx.Value(ctxKey{}).(string)
+			req.Label = proto.String(label)
+			return req, nil
+		})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tests", strings.NewReader(`{"label":"x"}`))
+	req = req.WithContext(context.WithValue(req.Context(), ctxKey{}, "from request"))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"label":"from request"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/v1/tests", strings.NewReader(`{"label":"too large for the limit"}`))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
@@ -0,0 +1,231 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+// TransformRule is a declarative rule of the Transform middleware, which can be
+// loaded from a configuration file. The request is transformed before the next
+// handlers, e.g. a reverse proxy to a legacy service, and the response when its
+// headers are written. In each group of fields, the removals are applied
+// first, then the renames, then the sets.
+type TransformRule struct {
+	// Paths restricts the rule to the requests matching one of the patterns,
+	// see PathMatches.
+	// Optional. Default value applies the rule to every request.
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+
+	// When restricts the rule to the requests for which it returns true.
+	// Optional.
+	When func(*Context) bool `json:"-" yaml:"-"`
+
+	// PathPattern is a regular expression whose matches in the path of the
+	// request are replaced by PathReplacement, which can refer to the
+	// submatches, e.g. "^/api/v1/(.*)" and "/legacy/$1". The route is not
+	// matched again, see Context.Forward for that.
+	// Optional.
+	PathPattern     string `json:"path_pattern,omitempty" yaml:"path_pattern,omitempty"`
+	PathReplacement string `json:"path_replacement,omitempty" yaml:"path_replacement,omitempty"`
+
+	// RemoveQuery, RenameQuery (old name to new name) and SetQuery transform
+	// the query parameters of the request.
+	// Optional.
+	RemoveQuery []string          `json:"remove_query,omitempty" yaml:"remove_query,omitempty"`
+	RenameQuery map[string]string `json:"rename_query,omitempty" yaml:"rename_query,omitempty"`
+	SetQuery    map[string]string `json:"set_query,omitempty" yaml:"set_query,omitempty"`
+
+	// RemoveRequestHeaders, RenameRequestHeaders (old name to new name) and
+	// SetRequestHeaders transform the headers of the request.
+	// Optional.
+	RemoveRequestHeaders []string          `json:"remove_request_headers,omitempty" yaml:"remove_request_headers,omitempty"`
+	RenameRequestHeaders map[string]string `json:"rename_request_headers,omitempty" yaml:"rename_request_headers,omitempty"`
+	SetRequestHeaders    map[string]string `json:"set_request_headers,omitempty" yaml:"set_request_headers,omitempty"`
+
+	// ClaimHeaders sets request headers to claims of the token claims stored in
+	// the context under ClaimsKey by the authentication middleware, e.g.
+	// {"X-User-Id": "sub"}, for the upstream services. The claims must be a map
+	// with string keys, see TenantFromClaim. The header of a missing claim is
+	// removed, so that it can not be sent by the client.
+	// Optional.
+	ClaimsKey    string            `json:"claims_key,omitempty" yaml:"claims_key,omitempty"`
+	ClaimHeaders map[string]string `json:"claim_headers,omitempty" yaml:"claim_headers,omitempty"`
+
+	// KeyHeaders sets request headers to the values of keys of the context,
+	// e.g. {"X-User": gin.AuthUserKey}. The header of a missing key is removed.
+	// Optional.
+	KeyHeaders map[string]string `json:"key_headers,omitempty" yaml:"key_headers,omitempty"`
+
+	// RemoveResponseHeaders, RenameResponseHeaders (old name to new name) and
+	// SetResponseHeaders transform the headers of the response.
+	// Optional.
+	RemoveResponseHeaders []string          `json:"remove_response_headers,omitempty" yaml:"remove_response_headers,omitempty"`
+	RenameResponseHeaders map[string]string `json:"rename_response_headers,omitempty" yaml:"rename_response_headers,omitempty"`
+	SetResponseHeaders    map[string]string `json:"set_response_headers,omitempty" yaml:"set_response_headers,omitempty"`
+}
+
+type transformRule struct {
+	TransformRule
+	paths       func(*Context) bool
+	pathPattern *regexp.Regexp
+}
+
+// Transform returns a middleware transforming the requests and the responses
+// with the rules applying to them, in order:
+//
+//	router.Any("/api/v1/*path", gin.Transform(gin.TransformRule{
+//		PathPattern:           "^/api/v1/",
+//		PathReplacement:       "/legacy/",
+//		ClaimsKey:             "claims",
+//		ClaimHeaders:          map[string]string{"X-User-Id": "sub"},
+//		RemoveRequestHeaders:  []string{"Authorization"},
+//		RemoveResponseHeaders: []string{"Server", "X-Powered-By"},
+//	}), gin.WrapH(legacyProxy))
+//
+// It panics when a PathPattern is not a valid regular expression.
+func Transform(rules ...TransformRule) HandlerFunc {
+	compiled := make([]transformRule, len(rules))
+	for i, rule := range rules {
+		compiled[i].TransformRule = rule
+		if len(rule.Paths) > 0 {
+			compiled[i].paths = PathMatches(rule.Paths...)
+		}
+		if rule.PathPattern != "" {
+			pattern, err := regexp.Compile(rule.PathPattern)
+			if err != nil {
+				panic(fmt.Sprintf("gin: invalid transform path pattern %q: %v", rule.PathPattern, err))
+			}
+			compiled[i].pathPattern = pattern
+		}
+	}
+
+	return func(c *Context) {
+		var responseRules []*transformRule
+		for i := range compiled {
+			rule := &compiled[i]
+			if rule.paths != nil && !rule.paths(c) || rule.When != nil && !rule.When(c) {
+				continue
+			}
+			rule.transformRequest(c)
+			if len(rule.RemoveResponseHeaders)+len(rule.RenameResponseHeaders)+len(rule.SetResponseHeaders) > 0 {
+				responseRules = append(responseRules, rule)
+			}
+		}
+		if len(responseRules) == 0 {
+			c.Next()
+			return
+		}
+
+		w := &transformWriter{ResponseWriter: c.Writer, rules: responseRules}
+		c.Writer = w
+		defer func() { c.Writer = w.ResponseWriter }()
+		c.Next()
+		w.transform()
+	}
+}
+
+func (rule *transformRule) transformRequest(c *Context) {
+	req := c.Request
+	rewritten := false
+	if rule.pathPattern != nil {
+		req.URL.Path = rule.pathPattern.ReplaceAllString(req.URL.Path, rule.PathReplacement)
+		req.URL.RawPath = ""
+		rewritten = true
+	}
+	if len(rule.RemoveQuery)+len(rule.RenameQuery)+len(rule.SetQuery) > 0 {
+		rewritten = true
+		query := req.URL.Query()
+		for _, name := range rule.RemoveQuery {
+			query.Del(name)
+		}
+		for from, to := range rule.RenameQuery {
+			if values, ok := query[from]; ok {
+				delete(query, from)
+				query[to] = values
+			}
+		}
+		for name, value := range rule.SetQuery {
+			query.Set(name, value)
+		}
+		req.URL.RawQuery = query.Encode()
+		c.queryCache = nil
+	}
+	if rewritten {
+		req.RequestURI = req.URL.RequestURI()
+	}
+
+	transformHeader(req.Header, rule.RemoveRequestHeaders, rule.RenameRequestHeaders, rule.SetRequestHeaders)
+	for header, claim := range rule.ClaimHeaders {
+		if value, ok := claimValue(c, rule.ClaimsKey, claim); ok {
+			req.Header.Set(header, fmt.Sprint(value))
+		} else {
+			req.Header.Del(header)
+		}
+	}
+	for header, key := range rule.KeyHeaders {
+		if value, ok := c.Get(key); ok {
+			req.Header.Set(header, fmt.Sprint(value))
+		} else {
+			req.Header.Del(header)
+		}
+	}
+}
+
+// transformHeader removes, renames then sets the headers of header.
+func transformHeader(header http.Header, remove []string, rename, set map[string]string) {
+	for _, name := range remove {
+		header.Del(name)
+	}
+	for from, to := range rename {
+		if values := header.Values(from); len(values) > 0 {
+			header.Del(from)
+			header[http.CanonicalHeaderKey(to)] = values
+		}
+	}
+	for name, value := range set {
+		header.Set(name, value)
+	}
+}
+
+// transformWriter transforms the headers of the response with rules when they
+// are written.
+type transformWriter struct {
+	ResponseWriter
+	rules       []*transformRule
+	transformed bool
+}
+
+func (w *transformWriter) transform() {
+	if w.transformed || w.ResponseWriter.Written() {
+		return
+	}
+	w.transformed = true
+	for _, rule := range w.rules {
+		transformHeader(w.Header(), rule.RemoveResponseHeaders, rule.RenameResponseHeaders, rule.SetResponseHeaders)
+	}
+}
+
+func (w *transformWriter) WriteHeaderNow() {
+	w.transform()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *transformWriter) Write(data []byte) (int, error) {
+	w.transform()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *transformWriter) WriteString(s string) (int, error) {
+	w.transform()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *transformWriter) Flush() {
+	w.transform()
+	w.ResponseWriter.Flush()
+}
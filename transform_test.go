@@ -0,0 +1,106 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformRequest(t *testing.T) {
+	router := New()
+	router.Use(func(c *Context) {
+		c.Set("claims", map[string]any{"sub": "u-42", "tier": 3})
+		c.Set(AuthUserKey, "alice")
+	})
+	router.GET("/api/v1/*path", Transform(TransformRule{
+		PathPattern:          "^/api/v1/(.*)$",
+		PathReplacement:      "/legacy/$1.php",
+		RemoveQuery:          []string{"debug"},
+		RenameQuery:          map[string]string{"page": "p"},
+		SetQuery:             map[string]string{"format": "json"},
+		RemoveRequestHeaders: []string{"Authorization"},
+		RenameRequestHeaders: map[string]string{"X-Request-Id": "X-Legacy-Trace"},
+		SetRequestHeaders:    map[string]string{"X-Gateway": "gin"},
+		ClaimsKey:            "claims",
+		ClaimHeaders:         map[string]string{"X-User-Id": "sub", "X-Tier": "tier", "X-Role": "role"},
+		KeyHeaders:           map[string]string{"X-User": AuthUserKey, "X-Tenant": "tenant"},
+	}, TransformRule{
+		Paths:             []string{"/admin/**"},
+		SetRequestHeaders: map[string]string{"X-Admin": "1"},
+	}), func(c *Context) {
+		h := c.Request.Header
+		assert.Equal(t, "/legacy/items/7.php", c.Request.URL.Path)
+		assert.Equal(t, "/legacy/items/7.php?format=json&p=2", c.Request.RequestURI)
+		assert.Equal(t, "2", c.Query("p"))
+		assert.Empty(t, h.Get("Authorization"))
+		assert.Empty(t, h.Get("X-Request-Id"))
+		assert.Equal(t, "abc", h.Get("X-Legacy-Trace"))
+		assert.Equal(t, "gin", h.Get("X-Gateway"))
+		assert.Equal(t, "u-42", h.Get("X-User-Id"))
+		assert.Equal(t, "3", h.Get("X-Tier"))
+		assert.Empty(t, h.Get("X-Role"), "the headers of missing claims are removed")
+		assert.Equal(t, "alice", h.Get("X-User"))
+		assert.Empty(t, h.Get("X-Tenant"))
+		assert.Empty(t, h.Get("X-Admin"))
+		c.Status(http.StatusNoContent)
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/api/v1/items/7?page=2&debug=1",
+		header{"Authorization", "Bearer token"}, header{"X-Request-Id", "abc"}, header{"X-Role", "admin"}, header{"X-Tenant", "other"})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	assert.Panics(t, func() { Transform(TransformRule{PathPattern: "("}) })
+}
+
+func TestTransformResponse(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "legacy/1.0")
+		w.Header().Set("X-Powered-By", "PHP/5.6")
+		w.Header().Set("X-Legacy-Id", "9")
+		w.Header().Set("X-Seen-User", r.Header.Get("X-User-Id"))
+		_, _ = w.Write([]byte(r.URL.RequestURI()))
+	}))
+	defer upstream.Close()
+	target, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+
+	router := New()
+	router.Use(func(c *Context) { c.Set("claims", map[string]string{"sub": "u-1"}) })
+	router.GET("/api/*path", Transform(TransformRule{
+		PathPattern:           "^/api/",
+		PathReplacement:       "/v0/",
+		ClaimsKey:             "claims",
+		ClaimHeaders:          map[string]string{"X-User-Id": "sub"},
+		RemoveResponseHeaders: []string{"Server", "X-Powered-By"},
+		RenameResponseHeaders: map[string]string{"X-Legacy-Id": "X-Id"},
+		SetResponseHeaders:    map[string]string{"X-Frame-Options": "DENY"},
+	}), WrapH(proxy))
+	router.GET("/empty", Transform(TransformRule{SetResponseHeaders: map[string]string{"X-Empty": "1"}}), func(c *Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := CreateTestResponseRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users?id=3", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "/v0/users?id=3", w.Body.String())
+	assert.Empty(t, w.Header().Get("Server"))
+	assert.Empty(t, w.Header().Get("X-Powered-By"))
+	assert.Empty(t, w.Header().Get("X-Legacy-Id"))
+	assert.Equal(t, "9", w.Header().Get("X-Id"))
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "u-1", w.Header().Get("X-Seen-User"))
+
+	empty := PerformRequest(router, http.MethodGet, "/empty")
+	assert.Equal(t, http.StatusNoContent, empty.Code)
+	assert.Equal(t, "1", empty.Header().Get("X-Empty"))
+}
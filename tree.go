@@ -122,6 +122,11 @@ type node struct {
 	children  []*node // child nodes, at most 1 :param style node at the end of the array
 	handlers  HandlersChain
 	fullPath  string
+	meta      map[string]any
+	name      string
+	// global is the number of engine middleware at the head of handlers, see
+	// Engine.UsePostMatch.
+	global int
 }
 
 // Increments priority of the given child and reorders if necessary
@@ -147,17 +152,17 @@ func (n *node) incrementChildPrio(pos int) int {
 	return newPos
 }
 
-// addRoute adds a node with the given handle to the path.
+// addRoute adds a node with the given handle to the path and returns it.
 // Not concurrency-safe!
-func (n *node) addRoute(path string, handlers HandlersChain) {
+func (n *node) addRoute(path string, handlers HandlersChain) *node {
 	fullPath := path
 	n.priority++
 
 	// Empty tree
 	if len(n.path) == 0 && len(n.children) == 0 {
-		n.insertChild(path, fullPath, handlers)
+		leaf := n.insertChild(path, fullPath, handlers)
 		n.nType = root
-		return
+		return leaf
 	}
 
 	parentFullPathIndex := 0
@@ -180,6 +185,9 @@ walk:
 				handlers:  n.handlers,
 				priority:  n.priority - 1,
 				fullPath:  n.fullPath,
+				meta:      n.meta,
+				name:      n.name,
+				global:    n.global,
 			}
 
 			n.children = []*node{&child}
@@ -189,6 +197,9 @@ walk:
 			n.handlers = nil
 			n.wildChild = false
 			n.fullPath = fullPath[:parentFullPathIndex+i]
+			n.meta = nil
+			n.name = ""
+			n.global = 0
 		}
 
 		// Make new node a child of this node
@@ -251,8 +262,7 @@ walk:
 					"'")
 			}
 
-			n.insertChild(path, fullPath, handlers)
-			return
+			return n.insertChild(path, fullPath, handlers)
 		}
 
 		// Otherwise add handle to current node
@@ -261,7 +271,7 @@ walk:
 		}
 		n.handlers = handlers
 		n.fullPath = fullPath
-		return
+		return n
 	}
 }
 
@@ -290,7 +300,8 @@ func findWildcard(path string) (wildcard string, i int, valid bool) {
 	return "", -1, false
 }
 
-func (n *node) insertChild(path string, fullPath string, handlers HandlersChain) {
+// insertChild inserts the nodes of path below n and returns the one holding handlers.
+func (n *node) insertChild(path string, fullPath string, handlers HandlersChain) *node {
 	for {
 		// Find prefix until first wildcard
 		wildcard, i, valid := findWildcard(path)
@@ -342,7 +353,7 @@ func (n *node) insertChild(path string, fullPath string, handlers HandlersChain)
 
 			// Otherwise we're done. Insert the handle in the new leaf
 			n.handlers = handlers
-			return
+			return n
 		}
 
 		// catchAll
@@ -389,13 +400,79 @@ func (n *node) insertChild(path string, fullPath string, handlers HandlersChain)
 		}
 		n.children = []*node{child}
 
-		return
+		return child
 	}
 
 	// If no wildcard was found, simply insert the path and handle
 	n.path = path
 	n.handlers = handlers
 	n.fullPath = fullPath
+	return n
+}
+
+// walk calls f for each node of the tree.
+func (n *node) walk(f func(*node)) {
+	f(n)
+	for _, child := range n.children {
+		child.walk(f)
+	}
+}
+
+// clonePath returns a copy of the tree in which addRoute(path) can be called
+// without modifying n: only the nodes addRoute may visit are copied, the other
+// ones are shared with n.
+func (n *node) clonePath(path string) *node {
+	cp := *n
+	cp.children = make([]*node, len(n.children))
+	copy(cp.children, n.children)
+	i := longestCommonPrefix(path, n.path)
+	if i < len(n.path) || i == len(path) {
+		return &cp
+	}
+	path = path[i:]
+	for j, child := range cp.children {
+		if child.nType != static || child.path == "" || child.path[0] == path[0] {
+			cp.children[j] = child.clonePath(path)
+		}
+	}
+	return &cp
+}
+
+// findRoute returns the node of the route registered with the given full path, or nil.
+func (n *node) findRoute(fullPath string) *node {
+	if n.handlers != nil && n.fullPath == fullPath {
+		return n
+	}
+	for _, child := range n.children {
+		if found := child.findRoute(fullPath); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// addMeta merges meta into the metadata of the route registered with the given
+// full path. The metadata map is replaced, not modified, as it may be shared.
+func (n *node) addMeta(fullPath string, meta map[string]any) {
+	if route := n.findRoute(fullPath); route != nil {
+		route.mergeMeta(meta)
+	}
+}
+
+// mergeMeta merges meta into the metadata of the route node n. The metadata
+// map is replaced, not modified, as it may be shared.
+func (n *node) mergeMeta(meta map[string]any) {
+	if len(meta) == 0 {
+		return
+	}
+	merged := make(map[string]any, len(n.meta)+len(meta))
+	for k, v := range n.meta {
+		merged[k] = v
+	}
+	for k, v := range meta {
+		merged[k] = v
+	}
+	n.meta = merged
 }
 
 // nodeValue holds return values of (*Node).getValue method
@@ -404,6 +481,9 @@ type nodeValue struct {
 	params   *Params
 	tsr      bool
 	fullPath string
+	meta     map[string]any
+	name     string
+	global   int
 }
 
 type skippedNode struct {
@@ -445,6 +525,8 @@ walk: // Outer loop for walking the tree
 									children:  n.children,
 									handlers:  n.handlers,
 									fullPath:  n.fullPath,
+									meta:      n.meta,
+									name:      n.name,
 								},
 								paramsCount: globalParamsCount,
 							}
@@ -531,6 +613,9 @@ walk: // Outer loop for walking the tree
 
 					if value.handlers = n.handlers; value.handlers != nil {
 						value.fullPath = n.fullPath
+						value.meta = n.meta
+						value.name = n.name
+						value.global = n.global
 						return
 					}
 					if len(n.children) == 1 {
@@ -564,6 +649,9 @@ walk: // Outer loop for walking the tree
 
 					value.handlers = n.handlers
 					value.fullPath = n.fullPath
+					value.meta = n.meta
+					value.name = n.name
+					value.global = n.global
 					return
 
 				default:
@@ -595,6 +683,9 @@ walk: // Outer loop for walking the tree
 			// Check if this node has a handle registered.
 			if value.handlers = n.handlers; value.handlers != nil {
 				value.fullPath = n.fullPath
+				value.meta = n.meta
+				value.name = n.name
+				value.global = n.global
 				return
 			}
 
@@ -529,6 +529,79 @@ func TestTreeCatchMaxParams(t *testing.T) {
 	tree.addRoute(route, fakeHandler(route))
 }
 
+func TestTreeAddRouteReturnsLeaf(t *testing.T) {
+	tree := &node{}
+	routes := [...]string{
+		"/users",
+		"/u",
+		"/users/:id",
+		"/users/:id/posts",
+		"/src/*filepath",
+		"/",
+	}
+	for _, route := range routes {
+		n := tree.addRoute(route, fakeHandler(route))
+		if n != tree.findRoute(route) || n.fullPath != route {
+			t.Errorf("addRoute(%q) returned the node of %q", route, n.fullPath)
+		}
+	}
+}
+
+func dumpTree(n *node, b *strings.Builder) {
+	fmt.Fprintf(b, "%q %q %q %d %v %d %v %d(", n.path, n.fullPath, n.indices, n.priority, n.wildChild, n.nType, n.handlers != nil, len(n.children))
+	for _, child := range n.children {
+		dumpTree(child, b)
+	}
+	b.WriteString(")")
+}
+
+func TestTreeClonePath(t *testing.T) {
+	tree := &node{}
+	for _, route := range [...]string{
+		"/",
+		"/cmd/:tool/",
+		"/cmd/whoami",
+		"/src/*filepath",
+		"/search/:query",
+		"/search/google",
+		"/user_:name",
+		"/info/:user/project/:project",
+	} {
+		tree.addRoute(route, fakeHandler(route))
+	}
+	var before strings.Builder
+	dumpTree(tree, &before)
+
+	for _, route := range [...]string{
+		"/cmd/:tool/:sub",
+		"/cmd/whoami/root",
+		"/cmd/:other",
+		"/search/",
+		"/search/gin-gonic",
+		"/src/*other",
+		"/user_:name/about",
+		"/info/:user/project/golang",
+		"/info/:id",
+		"/s",
+		"/:cc",
+		"/doc/go1.html",
+	} {
+		func() {
+			defer func() { _ = recover() }()
+			cp := tree.clonePath(route)
+			cp.addRoute(route, fakeHandler(route))
+			if cp.findRoute(route) == nil {
+				t.Errorf("route %q not added to the copy", route)
+			}
+		}()
+		var after strings.Builder
+		dumpTree(tree, &after)
+		if before.String() != after.String() {
+			t.Fatalf("adding %q to the copy modified the tree", route)
+		}
+	}
+}
+
 func TestTreeDoubleWildcard(t *testing.T) {
 	const panicMsg = "only one wildcard per path segment is allowed"
 
@@ -0,0 +1,248 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const defaultProxyPresetRefresh = 24 * time.Hour
+
+// ProxyPreset is a set of proxies to trust, see Engine.TrustProxies.
+type ProxyPreset struct {
+	// Name identifies the preset in the messages.
+	Name string
+
+	// CIDRs are the IP addresses and CIDRs of the proxies, used until Fetch
+	// succeeds if it is set.
+	CIDRs []string
+
+	// Fetch returns the current CIDRs of the proxies, e.g. the ones published
+	// by a CDN.
+	// Optional. Default value is nil, CIDRs are always used.
+	Fetch func(ctx context.Context) ([]string, error)
+
+	// Refresh is the interval between two calls of Fetch.
+	// Optional. Default value is 24 hours.
+	Refresh time.Duration
+}
+
+// TrustPrivateRanges trusts the proxies of the private and loopback networks,
+// e.g. a load balancer or a sidecar in the same network as the server.
+var TrustPrivateRanges = ProxyPreset{
+	Name: "private ranges",
+	CIDRs: []string{
+		"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "127.0.0.0/8",
+		"fc00::/7", "::1/128",
+	},
+}
+
+// TrustAWSALB trusts an AWS Application Load Balancer, whose nodes connect
+// from the private IPv4 addresses of its VPC. Use a preset with the CIDR of
+// the VPC to trust less, or to add its IPv6 block for a dualstack ALB.
+var TrustAWSALB = ProxyPreset{
+	Name:  "AWS ALB",
+	CIDRs: []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"},
+}
+
+// TrustCloudflare trusts the Cloudflare network, whose ranges are fetched
+// from the lists published by Cloudflare when the server warms up, then every
+// day; the ranges known at the time of the release are used until then.
+// Cloudflare sends the client IP in the CF-Connecting-IP and X-Forwarded-For
+// headers.
+var TrustCloudflare = ProxyPreset{
+	Name: "Cloudflare",
+	CIDRs: []string{
+		"173.245.48.0/20", "103.21.244.0/22", "103.22.200.0/22", "103.31.4.0/22",
+		"141.101.64.0/18", "108.162.192.0/18", "190.93.240.0/20", "188.114.96.0/20",
+		"197.234.240.0/22", "198.41.128.0/17", "162.158.0.0/15", "104.16.0.0/13",
+		"104.24.0.0/14", "172.64.0.0/13", "131.0.72.0/22",
+		"2400:cb00::/32", "2606:4700::/32", "2803:f800::/32", "2405:b500::/32",
+		"2405:8100::/32", "2a06:98c0::/29", "2c0f:f248::/32",
+	},
+	Fetch: func(ctx context.Context) ([]string, error) {
+		return fetchCIDRs(ctx, cloudflareRangesURLs...)
+	},
+}
+
+var cloudflareRangesURLs = []string{"https://www.cloudflare.com/ips-v4", "https://www.cloudflare.com/ips-v6"}
+
+// fetchCIDRs returns the CIDRs listed one per line in the documents at urls.
+func fetchCIDRs(ctx context.Context, urls ...string) ([]string, error) {
+	var cidrs []string
+	for _, url := range urls {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("gin: fetching %s: %s", url, resp.Status)
+		}
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				cidrs = append(cidrs, line)
+			}
+		}
+		resp.Body.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if len(cidrs) == 0 {
+		return nil, fmt.Errorf("gin: no CIDR at %s", strings.Join(urls, ", "))
+	}
+	if _, err := parseCIDRs(cidrs); err != nil {
+		return nil, err
+	}
+	return cidrs, nil
+}
+
+// trustedPresets are the proxies trusted by Engine.TrustProxies.
+type trustedPresets struct {
+	// root is the value stored by TrustProxies, of which this one is a refresh.
+	root    *trustedPresets
+	presets []ProxyPreset
+	// current are the CIDRs of each preset, the fetched ones if any.
+	current [][]string
+	proxies []string
+	cidrs   []*net.IPNet
+}
+
+func newTrustedPresets(presets []ProxyPreset, current [][]string) (*trustedPresets, error) {
+	t := &trustedPresets{presets: presets, current: current}
+	t.root = t
+	for _, cidrs := range current {
+		t.proxies = append(t.proxies, cidrs...)
+	}
+	var err error
+	t.cidrs, err = parseCIDRs(t.proxies)
+	return t, err
+}
+
+// TrustProxies trusts the proxies of presets, instead of the ones set with
+// SetTrustedProxies, e.g. TrustCloudflare, or several ones for a CDN in front
+// of a load balancer:
+//
+//	router.TrustProxies(gin.TrustCloudflare, gin.TrustPrivateRanges)
+//
+// The ranges of the presets with a Fetch function are fetched by the warmup,
+// see Warmup, then refreshed periodically until Shutdown; a failed fetch is
+// logged and the previous ranges kept. Like SetTrustedProxies, it must be
+// called before serving, and a later call of either replaces the trusted
+// proxies.
+func (engine *Engine) TrustProxies(presets ...ProxyPreset) error {
+	current := make([][]string, len(presets))
+	for i, preset := range presets {
+		current[i] = preset.CIDRs
+	}
+	trusted, err := newTrustedPresets(presets, current)
+	if err != nil {
+		return err
+	}
+	engine.presets.Store(trusted)
+
+	for i, preset := range presets {
+		if preset.Fetch == nil {
+			continue
+		}
+		i, preset := i, preset
+		refresh := func(ctx context.Context) {
+			if err := engine.refreshPreset(ctx, trusted, i); err != nil {
+				debugPrintWarning("Trusted proxies %s not refreshed: %v", preset.Name, err)
+			}
+		}
+		engine.Warmup(func(ctx context.Context) error {
+			refresh(ctx)
+			return nil
+		})
+		interval := preset.Refresh
+		if interval <= 0 {
+			interval = defaultProxyPresetRefresh
+		}
+		if err := engine.Schedule("@every "+interval.String(), refresh); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshPreset fetches the CIDRs of the preset i of the presets trusted by
+// TrustProxies, unless they were replaced.
+func (engine *Engine) refreshPreset(ctx context.Context, initial *trustedPresets, i int) error {
+	cidrs, err := initial.presets[i].Fetch(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		old := engine.presets.Load()
+		if old == nil || old.root != initial {
+			return nil
+		}
+		current := append([][]string(nil), old.current...)
+		current[i] = cidrs
+		trusted, err := newTrustedPresets(old.presets, current)
+		if err != nil {
+			return err
+		}
+		trusted.root = initial
+		if engine.presets.CompareAndSwap(old, trusted) {
+			debugPrintInfo("Trusted proxies %s refreshed: %d ranges", initial.presets[i].Name, len(cidrs))
+			return nil
+		}
+	}
+}
+
+// forwardingWarnings are the warnings about the forwarding headers printed by
+// ClientIP, once per engine.
+type forwardingWarnings struct {
+	untrusted atomic.Bool
+	trustAll  atomic.Bool
+}
+
+// checkForwarding warns when a forwarding header of the request is ignored
+// because the remote IP is not a trusted proxy, which is likely a proxy
+// missing from the trusted ones, or when it is honored for a public IP only
+// because all the proxies are trusted, which lets the clients spoof their IP.
+func (engine *Engine) checkForwarding(c *Context, remoteIP net.IP, trusted bool) {
+	warnings := &engine.forwardingWarnings
+	if (trusted && warnings.trustAll.Load()) || (!trusted && warnings.untrusted.Load()) {
+		return
+	}
+	var name string
+	for _, headerName := range engine.RemoteIPHeaders {
+		if c.requestHeader(headerName) != "" {
+			name = headerName
+			break
+		}
+	}
+	if name == "" {
+		return
+	}
+	switch {
+	case !trusted:
+		if !warnings.untrusted.Swap(true) {
+			debugPrintWarning("%s received from %s, which is not a trusted proxy: the header is ignored. "+
+				"If it is a proxy, trust it with SetTrustedProxies or TrustProxies.", name, remoteIP)
+		}
+	case !remoteIP.IsPrivate() && !remoteIP.IsLoopback() && engine.isUnsafeTrustedProxies():
+		if !warnings.trustAll.Swap(true) {
+			debugPrintWarning("%s honored from %s because all proxies are trusted: the clients can spoof their IP. "+
+				"Trust only your proxies with SetTrustedProxies or TrustProxies.", name, remoteIP)
+		}
+	}
+}
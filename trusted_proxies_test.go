@@ -0,0 +1,144 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clientIPFrom(router *Engine, remoteAddr, forwardedFor string) string {
+	req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Body.String()
+}
+
+func TestTrustProxies(t *testing.T) {
+	router := clientIPRouter()
+	require.NoError(t, router.TrustProxies(TrustPrivateRanges))
+
+	assert.Equal(t, "203.0.113.7", clientIPFrom(router, "10.1.2.3:1234", "203.0.113.7"))
+	assert.Equal(t, "203.0.113.7", clientIPFrom(router, "[::1]:1234", "203.0.113.7"))
+	assert.Equal(t, "198.51.100.1", clientIPFrom(router, "198.51.100.1:1234", "203.0.113.7"))
+	assert.Equal(t, TrustPrivateRanges.CIDRs, router.adminConfig().TrustedProxies)
+
+	require.NoError(t, router.TrustProxies(TrustCloudflare, TrustAWSALB))
+	assert.Equal(t, "203.0.113.7", clientIPFrom(router, "172.70.1.1:1234", "203.0.113.7, 10.0.0.2"))
+	assert.Equal(t, "198.51.100.1", clientIPFrom(router, "198.51.100.1:1234", "203.0.113.7, 10.0.0.2"))
+
+	require.NoError(t, router.SetTrustedProxies(nil))
+	assert.Equal(t, "10.1.2.3", clientIPFrom(router, "10.1.2.3:1234", "203.0.113.7"))
+
+	assert.Error(t, router.TrustProxies(ProxyPreset{CIDRs: []string{"invalid"}}))
+}
+
+func TestTrustProxiesFetch(t *testing.T) {
+	router := clientIPRouter()
+	fetches := 0
+	preset := ProxyPreset{
+		Name:  "CDN",
+		CIDRs: []string{"198.51.100.0/24"},
+		Fetch: func(ctx context.Context) ([]string, error) {
+			fetches++
+			if fetches == 2 {
+				return nil, errors.New("unavailable")
+			}
+			return []string{"192.0.2.0/24"}, nil
+		},
+	}
+	require.NoError(t, router.TrustProxies(preset, TrustPrivateRanges))
+	assert.Equal(t, "203.0.113.7", clientIPFrom(router, "198.51.100.1:1234", "203.0.113.7"))
+
+	require.NoError(t, router.RunWarmup(context.Background()))
+	assert.Equal(t, 1, fetches)
+	assert.Equal(t, "198.51.100.1", clientIPFrom(router, "198.51.100.1:1234", "203.0.113.7"))
+	assert.Equal(t, "203.0.113.7", clientIPFrom(router, "192.0.2.1:1234", "203.0.113.7"))
+	assert.Equal(t, "203.0.113.7", clientIPFrom(router, "10.0.0.1:1234", "203.0.113.7"))
+
+	// the previous ranges are kept.
+	initial := router.presets.Load().root
+	assert.EqualError(t, router.refreshPreset(context.Background(), initial, 0), "unavailable")
+	assert.Equal(t, "203.0.113.7", clientIPFrom(router, "192.0.2.1:1234", "203.0.113.7"))
+
+	// the replaced presets are not refreshed.
+	require.NoError(t, router.SetTrustedProxies([]string{"10.0.0.0/8"}))
+	assert.NoError(t, router.refreshPreset(context.Background(), initial, 0))
+	assert.Nil(t, router.presets.Load())
+	assert.Equal(t, "192.0.2.1", clientIPFrom(router, "192.0.2.1:1234", "203.0.113.7"))
+	assert.NoError(t, router.Shutdown(context.Background()))
+}
+
+func TestFetchCIDRs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/ips-v4":
+			fmt.Fprint(w, "173.245.48.0/20\n103.21.244.0/22\n")
+		case "/ips-v6":
+			fmt.Fprint(w, "2400:cb00::/32\n\n")
+		case "/invalid":
+			fmt.Fprint(w, "not a CIDR\n")
+		case "/empty":
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer server.Close()
+
+	defer func(urls []string) { cloudflareRangesURLs = urls }(cloudflareRangesURLs)
+	cloudflareRangesURLs = []string{server.URL + "/ips-v4", server.URL + "/ips-v6"}
+	cidrs, err := TrustCloudflare.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"173.245.48.0/20", "103.21.244.0/22", "2400:cb00::/32"}, cidrs)
+
+	_, err = fetchCIDRs(context.Background(), server.URL+"/missing")
+	assert.ErrorContains(t, err, "404 Not Found")
+	_, err = fetchCIDRs(context.Background(), server.URL+"/invalid")
+	assert.Error(t, err)
+	_, err = fetchCIDRs(context.Background(), server.URL+"/empty")
+	assert.ErrorContains(t, err, "no CIDR")
+}
+
+func TestForwardingWarnings(t *testing.T) {
+	defer SetMode(TestMode)
+	var messages []string
+	DebugLogFunc = func(level LogLevel, format string, values ...any) {
+		if level == LogLevelWarn {
+			messages = append(messages, fmt.Sprintf(format, values...))
+		}
+	}
+	defer func() { DebugLogFunc = nil }()
+	SetMode(DebugMode)
+
+	router := clientIPRouter()
+	messages = nil
+	clientIPFrom(router, "10.0.0.1:1234", "203.0.113.7")
+	clientIPFrom(router, "198.51.100.1:1234", "")
+	assert.Empty(t, messages)
+	clientIPFrom(router, "198.51.100.1:1234", "203.0.113.7")
+	clientIPFrom(router, "198.51.100.2:1234", "203.0.113.8")
+	require.Len(t, messages, 1)
+	assert.Contains(t, messages[0], "X-Forwarded-For honored from 198.51.100.1 because all proxies are trusted")
+
+	messages = nil
+	require.NoError(t, router.TrustProxies(TrustPrivateRanges))
+	clientIPFrom(router, "10.0.0.1:1234", "203.0.113.7")
+	assert.Empty(t, messages)
+	clientIPFrom(router, "198.51.100.1:1234", "203.0.113.7")
+	clientIPFrom(router, "198.51.100.2:1234", "203.0.113.8")
+	require.Len(t, messages, 1)
+	assert.Contains(t, messages[0], "X-Forwarded-For received from 198.51.100.1, which is not a trusted proxy")
+}
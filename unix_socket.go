@@ -0,0 +1,147 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// UnixSocketConfig defines the config for RunUnixWithConfig.
+type UnixSocketConfig struct {
+	// Mode is the file mode of the socket, e.g. 0660 to restrict it to the
+	// owner and the group of a reverse proxy.
+	// Optional. Default value is the mode from the process umask.
+	Mode os.FileMode
+
+	// Owner and Group change the ownership of the socket. They are user and
+	// group names, or numeric ids.
+	// Optional. Default values keep the process owner and group.
+	Owner string
+	Group string
+
+	// RemoveStale removes a socket file left by a process which didn't exit
+	// cleanly. A socket which still accepts connections is never removed.
+	// Optional. Default value is false.
+	RemoveStale bool
+}
+
+// RunUnixWithConfig is RunUnix with a config for the socket file.
+// A file starting with "@" is a socket in the Linux abstract namespace: it has
+// no file, so the file mode, ownership and stale socket config don't apply.
+// Note: this method will block the calling goroutine indefinitely unless an error happens.
+func (engine *Engine) RunUnixWithConfig(file string, config UnixSocketConfig) (err error) {
+	debugPrintInfo("Listening and serving HTTP on unix:/%s", file)
+	defer func() { debugPrintError(err) }()
+
+	if engine.isUnsafeTrustedProxies() {
+		debugPrintWarning("You trusted all proxies, this is NOT safe. We recommend you to set a value.\n" +
+			"Please check https://github.com/gin-gonic/gin/blob/master/docs/doc.md#dont-trust-all-proxies for details.")
+	}
+
+	abstract := strings.HasPrefix(file, "@")
+	if abstract && runtime.GOOS != "linux" && runtime.GOOS != "android" {
+		return fmt.Errorf("gin: abstract unix sockets are not supported on %s", runtime.GOOS)
+	}
+	if !abstract && config.RemoveStale {
+		if err = removeStaleSocket(file); err != nil {
+			return
+		}
+	}
+
+	listener, err := net.Listen("unix", file)
+	if err != nil {
+		return
+	}
+	defer listener.Close()
+	if !abstract {
+		defer os.Remove(file)
+		if err = setupSocketFile(file, config); err != nil {
+			return
+		}
+	}
+
+	if engine.ProxyProtocol {
+		listener = NewProxyProtocolListener(listener)
+	}
+	err = engine.serve(listener, serveHTTP)
+	return
+}
+
+// removeStaleSocket removes file if it is a socket nobody listens on.
+func removeStaleSocket(file string) error {
+	fi, err := os.Lstat(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("gin: %s exists and is not a unix socket", file)
+	}
+	if conn, err := net.Dial("unix", file); err == nil {
+		conn.Close()
+		return fmt.Errorf("gin: unix socket %s is in use", file)
+	}
+	return os.Remove(file)
+}
+
+func setupSocketFile(file string, config UnixSocketConfig) error {
+	if config.Mode != 0 {
+		if err := os.Chmod(file, config.Mode); err != nil {
+			return err
+		}
+	}
+	if config.Owner == "" && config.Group == "" {
+		return nil
+	}
+	uid, gid := -1, -1
+	if config.Owner != "" {
+		id, err := lookupID(config.Owner, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		})
+		if err != nil {
+			return err
+		}
+		uid = id
+	}
+	if config.Group != "" {
+		id, err := lookupID(config.Group, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		})
+		if err != nil {
+			return err
+		}
+		gid = id
+	}
+	return os.Chown(file, uid, gid)
+}
+
+// lookupID returns the numeric id of name, which is either numeric or resolved by lookup.
+func lookupID(name string, lookup func(string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+	id, err := lookup(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(id)
+}
@@ -0,0 +1,103 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package gin
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getUnix(t *testing.T, file string) string {
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", file)
+		},
+	}}
+	resp, err := client.Get("http://unix/example")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func runUnixTestServer(t *testing.T, file string, config UnixSocketConfig) (*Engine, chan error) {
+	router := New()
+	router.GET("/example", func(c *Context) { c.String(http.StatusOK, "it worked") })
+	errc := make(chan error, 1)
+	go func() { errc <- router.RunUnixWithConfig(file, config) }()
+	assert.Eventually(t, func() bool { return router.State().Accepting || len(errc) > 0 }, time.Second, time.Millisecond)
+	return router, errc
+}
+
+func TestRunUnixWithConfigMode(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gin.sock")
+	router, errc := runUnixTestServer(t, file, UnixSocketConfig{
+		Mode:  0o660,
+		Owner: strconv.Itoa(os.Getuid()),
+		Group: strconv.Itoa(os.Getgid()),
+	})
+	defer router.Shutdown(context.Background()) //nolint: errcheck
+
+	fi, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o660), fi.Mode().Perm())
+	assert.Equal(t, "it worked", getUnix(t, file))
+
+	assert.NoError(t, router.Shutdown(context.Background()))
+	assert.Equal(t, http.ErrServerClosed, <-errc)
+	_, err = os.Stat(file)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRunUnixWithConfigRemoveStale(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gin.sock")
+	stale, err := net.Listen("unix", file)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	// in use
+	_, errc := runUnixTestServer(t, file, UnixSocketConfig{RemoveStale: true})
+	assert.ErrorContains(t, <-errc, "is in use")
+
+	// stale
+	stale.Close()
+	_, errc = runUnixTestServer(t, file, UnixSocketConfig{})
+	assert.Error(t, <-errc)
+	router, _ := runUnixTestServer(t, file, UnixSocketConfig{RemoveStale: true})
+	defer router.Shutdown(context.Background()) //nolint: errcheck
+	assert.Equal(t, "it worked", getUnix(t, file))
+}
+
+func TestRunUnixWithConfigNotASocket(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gin.sock")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	_, errc := runUnixTestServer(t, file, UnixSocketConfig{RemoveStale: true})
+	assert.ErrorContains(t, <-errc, "is not a unix socket")
+}
+
+func TestRunUnixAbstractSocket(t *testing.T) {
+	file := "@gin-test-" + strconv.Itoa(os.Getpid())
+	if runtime.GOOS != "linux" {
+		assert.Error(t, New().RunUnix(file))
+		return
+	}
+	router, _ := runUnixTestServer(t, file, UnixSocketConfig{Mode: 0o600, RemoveStale: true})
+	defer router.Shutdown(context.Background()) //nolint: errcheck
+	assert.Equal(t, "it worked", getUnix(t, file))
+}
@@ -0,0 +1,339 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrUploadTooLarge is the reason of the uploads over UploadOptions.MaxSize.
+	ErrUploadTooLarge = errors.New("file too large")
+	// ErrUploadType is the reason of the uploads whose content is not of one of
+	// UploadOptions.AllowedTypes.
+	ErrUploadType = errors.New("file type not allowed")
+	// ErrUploadDimensions is the reason of the images over UploadOptions.MaxWidth
+	// or UploadOptions.MaxHeight, or whose dimensions can not be read.
+	ErrUploadDimensions = errors.New("image dimensions not allowed")
+	// ErrUploadExists is the reason of the uploads whose destination exists.
+	ErrUploadExists = errors.New("file already exists")
+	// ErrUploadRejected is the reason of the uploads rejected by an
+	// UploadInspector, see RejectUpload.
+	ErrUploadRejected = errors.New("file rejected by inspection")
+)
+
+// UploadError is the error of an upload rejected by SaveUploadedFileChecked or
+// by an UploadInspector. Reason is one of the ErrUpload errors, and Detail the
+// explanation of the inspector, e.g. the name of the virus found.
+type UploadError struct {
+	Filename    string
+	ContentType string
+	Reason      error
+	Detail      string
+}
+
+func (e *UploadError) Error() string {
+	if e.Detail != "" {
+		return fmt.Sprintf("upload %q rejected: %v: %s", e.Filename, e.Reason, e.Detail)
+	}
+	return fmt.Sprintf("upload %q rejected: %v", e.Filename, e.Reason)
+}
+
+// Unwrap returns the reason of the rejection.
+func (e *UploadError) Unwrap() error {
+	return e.Reason
+}
+
+// UploadOptions defines the checks of Context.SaveUploadedFileChecked.
+type UploadOptions struct {
+	// AllowedTypes are the accepted media types, sniffed from the content with
+	// http.DetectContentType rather than trusted from the client. A type may end
+	// with "/*", e.g. "image/*".
+	// Optional. Default value accepts any type.
+	AllowedTypes []string
+
+	// MaxSize is the maximum size of the file in bytes.
+	// Optional. Default value is 0: no limit.
+	MaxSize int64
+
+	// MaxWidth and MaxHeight are the maximum dimensions of the images, checked
+	// for the GIF, JPEG and PNG images, and for the formats registered with
+	// image.RegisterFormat. An image whose dimensions can not be read is rejected.
+	// Optional. Default value is 0: no limit.
+	MaxWidth  int
+	MaxHeight int
+
+	// Filename is the name of the saved file.
+	// Optional. Default value is the name sent by the client, sanitized with
+	// SanitizeFilename.
+	Filename string
+
+	// Overwrite replaces an existing file.
+	// Optional. Default value is false: ErrUploadExists is returned.
+	Overwrite bool
+
+	// FileMode is the mode of the saved file.
+	// Optional. Default value is 0640.
+	FileMode os.FileMode
+
+	// Inspector inspects the content of the file before it is stored.
+	// Optional. Default value is Engine.UploadInspector.
+	Inspector UploadInspector
+
+	// Storage stores the file as the blob dir/name, instead of in the directory
+	// dir. FileMode does not apply.
+	// Optional. Default value is Engine.UploadStorage.
+	Storage BlobStorage
+}
+
+// FormFiles returns the files of the multipart form field name, e.g. of an
+// <input type="file" multiple>.
+func (c *Context) FormFiles(name string) ([]*multipart.FileHeader, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+	files := form.File[name]
+	if len(files) == 0 {
+		return nil, http.ErrMissingFile
+	}
+	return files, nil
+}
+
+// SaveUploadedFileChecked saves the uploaded file in the directory dir once it
+// passed the checks of opts, and returns its path, or the name of its blob when
+// saved in a BlobStorage. Unlike SaveUploadedFile, the
+// name of the file can not escape dir, the type is sniffed from the content,
+// and the file is written to a temporary file renamed at the end, so that a
+// rejected or failed upload leaves nothing behind and a reader never sees a
+// partial file. A rejected upload returns an *UploadError, which is also added
+// to the errors of the context as a public error, with the *UploadError as
+// meta, for the error handling middleware to render it.
+func (c *Context) SaveUploadedFileChecked(file *multipart.FileHeader, dir string, opts UploadOptions) (string, error) {
+	path, err := c.saveUploadedFileChecked(file, dir, opts)
+	return path, c.uploadError(err)
+}
+
+// uploadError adds err to the errors of the context as a public error when it
+// is an *UploadError, and returns it.
+func (c *Context) uploadError(err error) error {
+	var uploadErr *UploadError
+	if errors.As(err, &uploadErr) {
+		_ = c.Error(err).SetType(ErrorTypePublic).SetMeta(uploadErr)
+	}
+	return err
+}
+
+func (c *Context) saveUploadedFileChecked(file *multipart.FileHeader, dir string, opts UploadOptions) (string, error) {
+	name := opts.Filename
+	if name == "" {
+		name = SanitizeFilename(file.Filename)
+	}
+	storage := opts.Storage
+	if storage == nil && c.engine != nil {
+		storage = c.engine.UploadStorage
+	}
+	dst := filepath.Join(dir, name)
+	if rel, err := filepath.Rel(dir, dst); err != nil || rel != filepath.Base(dst) {
+		return "", fmt.Errorf("gin: invalid upload file name %q", name)
+	}
+	if storage != nil {
+		dst = path.Join(filepath.ToSlash(dir), name)
+	}
+	reject := func(contentType string, reason error) error {
+		return &UploadError{Filename: file.Filename, ContentType: contentType, Reason: reason}
+	}
+	if opts.MaxSize > 0 && file.Size > opts.MaxSize {
+		return "", reject("", ErrUploadTooLarge)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	var head [512]byte
+	n, err := io.ReadFull(src, head[:])
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	contentType := http.DetectContentType(head[:n])
+	if !uploadTypeAllowed(contentType, opts.AllowedTypes) {
+		return "", reject(contentType, ErrUploadType)
+	}
+	if opts.MaxWidth > 0 || opts.MaxHeight > 0 {
+		config, err := decodeImageConfig(contentType, io.MultiReader(bytes.NewReader(head[:n]), src))
+		if err != nil || opts.MaxWidth > 0 && config.Width > opts.MaxWidth || opts.MaxHeight > 0 && config.Height > opts.MaxHeight {
+			return "", reject(contentType, ErrUploadDimensions)
+		}
+	}
+	if _, err = src.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	inspector := opts.Inspector
+	if inspector == nil && c.engine != nil {
+		inspector = c.engine.UploadInspector
+	}
+	info := UploadInfo{Filename: file.Filename, ContentType: contentType, Size: file.Size}
+
+	if storage != nil {
+		if !opts.Overwrite {
+			if _, err := storage.Stat(c.Request.Context(), dst); err == nil {
+				return "", reject(contentType, ErrUploadExists)
+			}
+		}
+		if err = c.saveUploadBlob(src, storage, dst, info, inspector); err != nil {
+			return "", err
+		}
+		return dst, nil
+	}
+
+	if !opts.Overwrite {
+		// checked again when the file is moved in place.
+		if _, err := os.Lstat(dst); err == nil {
+			return "", reject(contentType, ErrUploadExists)
+		}
+	}
+	if err = os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+	mode := opts.FileMode
+	if mode == 0 {
+		mode = 0o640
+	}
+	if err = c.writeUpload(src, dst, mode, opts.MaxSize, opts.Overwrite, info, inspector); err != nil {
+		if err == ErrUploadTooLarge || err == ErrUploadExists {
+			return "", reject(contentType, err)
+		}
+		return "", err
+	}
+	return dst, nil
+}
+
+// decodeImageConfig decodes the dimensions of an image of the sniffed
+// contentType: GIF, JPEG, PNG or a format registered with image.RegisterFormat.
+func decodeImageConfig(contentType string, r io.Reader) (image.Config, error) {
+	switch contentType {
+	case "image/gif":
+		return gif.DecodeConfig(r)
+	case "image/jpeg":
+		return jpeg.DecodeConfig(r)
+	case "image/png":
+		return png.DecodeConfig(r)
+	}
+	config, _, err := image.DecodeConfig(r)
+	return config, err
+}
+
+// writeUpload writes src to a temporary file in the directory of dst, through
+// inspector if any, and moves it to dst once accepted. Unless overwrite is set,
+// it is linked to dst, which fails with ErrUploadExists when dst was created
+// in the meantime, rather than renamed over it.
+func (c *Context) writeUpload(src io.Reader, dst string, mode os.FileMode, maxSize int64, overwrite bool, info UploadInfo, inspector UploadInspector) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails once renamed
+	if maxSize > 0 {
+		src = io.LimitReader(src, maxSize+1)
+	}
+	var written int64
+	if inspector != nil {
+		written, err = c.inspectUpload(tmp, src, info, inspector)
+	} else {
+		written, err = io.Copy(tmp, src)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if maxSize > 0 && written > maxSize {
+		return ErrUploadTooLarge
+	}
+	if err = os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	if overwrite {
+		return os.Rename(tmp.Name(), dst)
+	}
+	if err = os.Link(tmp.Name(), dst); errors.Is(err, fs.ErrExist) {
+		return ErrUploadExists
+	}
+	return err
+}
+
+// uploadTypeAllowed reports whether the sniffed contentType is one of allowed.
+func uploadTypeAllowed(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range allowed {
+		if prefix, ok := strings.CutSuffix(t, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if strings.EqualFold(t, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// SanitizeFilename returns a safe file name for the name sent by a client: the
+// directories are removed, the characters other than ASCII letters, digits,
+// '.', '-' and '_' are replaced with '_', the leading dots are removed and the
+// name is truncated to 255 bytes. It returns "file" for an empty name.
+func SanitizeFilename(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		case r == utf8.RuneError:
+		default:
+			b.WriteByte('_')
+		}
+	}
+	name = strings.TrimLeft(b.String(), ".")
+	if len(name) > 255 {
+		ext := filepath.Ext(name)
+		if len(ext) > 16 {
+			ext = ""
+		}
+		name = name[:255-len(ext)] + ext
+	}
+	if name == "" {
+		return "file"
+	}
+	return name
+}
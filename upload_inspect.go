@@ -0,0 +1,103 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// UploadInfo describes the uploaded file given to an UploadInspector.
+type UploadInfo struct {
+	// Filename is the name sent by the client.
+	Filename string
+	// ContentType is sniffed from the content by SaveUploadedFileChecked, and
+	// sent by the client to SaveUploadedFile.
+	ContentType string
+	Size        int64
+}
+
+// UploadInspector inspects the content of the uploaded files before they are
+// stored, e.g. with ClamAV or a cloud scanning service, see
+// Engine.UploadInspector and UploadOptions.Inspector.
+//
+// Inspect reads the content from r while it is written to a temporary file, and
+// returns RejectUpload to veto it, or another error when the inspection failed:
+// in both cases the file is not stored. It may return before reading the whole
+// content, the rest of it being written without inspection.
+type UploadInspector interface {
+	Inspect(ctx context.Context, info UploadInfo, r io.Reader) error
+}
+
+// UploadInspectorFunc is an adapter to allow the use of an ordinary function as
+// UploadInspector.
+type UploadInspectorFunc func(ctx context.Context, info UploadInfo, r io.Reader) error
+
+// Inspect calls f(ctx, info, r).
+func (f UploadInspectorFunc) Inspect(ctx context.Context, info UploadInfo, r io.Reader) error {
+	return f(ctx, info, r)
+}
+
+// RejectUpload returns the error of an UploadInspector rejecting a file, an
+// *UploadError whose Reason is ErrUploadRejected, explained by detail.
+func RejectUpload(detail string) error {
+	return &UploadError{Reason: ErrUploadRejected, Detail: detail}
+}
+
+// errInspected closes the pipe to an inspector which returned without reading
+// the whole content.
+var errInspected = errors.New("gin: upload inspected")
+
+// inspectUpload copies src to dst and streams it to inspector, and returns the
+// error of inspector, if any.
+func (c *Context) inspectUpload(dst io.Writer, src io.Reader, info UploadInfo, inspector UploadInspector) (int64, error) {
+	ctx := context.Background()
+	if c.Request != nil {
+		ctx = c.Request.Context()
+	}
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := inspector.Inspect(ctx, info, pr)
+		if err != nil {
+			pr.CloseWithError(err)
+		} else {
+			pr.CloseWithError(errInspected)
+		}
+		done <- err
+	}()
+
+	written, err := io.Copy(io.MultiWriter(dst, inspectWriter{pw}), src)
+	pw.CloseWithError(err)
+	inspectErr := <-done
+	if inspectErr != nil {
+		var uploadErr *UploadError
+		if errors.As(inspectErr, &uploadErr) {
+			if uploadErr.Filename == "" {
+				uploadErr.Filename = info.Filename
+			}
+			if uploadErr.ContentType == "" {
+				uploadErr.ContentType = info.ContentType
+			}
+		}
+		return written, inspectErr
+	}
+	return written, err
+}
+
+// inspectWriter writes to the pipe of an inspector, ignoring its end once the
+// inspector accepted the content.
+type inspectWriter struct {
+	pw *io.PipeWriter
+}
+
+func (w inspectWriter) Write(p []byte) (int, error) {
+	n, err := w.pw.Write(p)
+	if err == errInspected {
+		return len(p), nil
+	}
+	return n, err
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var signatureScanner = UploadInspectorFunc(func(ctx context.Context, info UploadInfo, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(data, []byte("EICAR")) {
+		return RejectUpload("Eicar-Test-Signature")
+	}
+	return nil
+})
+
+func TestUploadInspectorRejects(t *testing.T) {
+	dir := t.TempDir()
+	content := strings.Repeat("x", 1<<20) + "EICAR"
+	c := uploadContext(t, "f", uploadFile{"virus.txt", []byte(content)}, uploadFile{"clean.txt", []byte(strings.Repeat("y", 1<<20))})
+	files, err := c.FormFiles("f")
+	require.NoError(t, err)
+
+	_, err = c.SaveUploadedFileChecked(files[0], dir, UploadOptions{Inspector: signatureScanner})
+	var uploadErr *UploadError
+	require.True(t, errors.As(err, &uploadErr))
+	assert.Equal(t, ErrUploadRejected, uploadErr.Reason)
+	assert.Equal(t, "Eicar-Test-Signature", uploadErr.Detail)
+	assert.Equal(t, "virus.txt", uploadErr.Filename)
+	assert.Equal(t, "text/plain; charset=utf-8", uploadErr.ContentType)
+	assert.Equal(t, `upload "virus.txt" rejected: file rejected by inspection: Eicar-Test-Signature`, err.Error())
+	require.Len(t, c.Errors, 1)
+	assert.True(t, c.Errors[0].IsType(ErrorTypePublic))
+	assert.Equal(t, uploadErr, c.Errors[0].Meta)
+
+	path, err := c.SaveUploadedFileChecked(files[1], dir, UploadOptions{Inspector: signatureScanner})
+	require.NoError(t, err)
+	data, _ := os.ReadFile(path)
+	assert.Len(t, data, 1<<20)
+
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 1)
+}
+
+func TestUploadInspectorPartialRead(t *testing.T) {
+	dir := t.TempDir()
+	content := strings.Repeat("z", 1<<20)
+	c := uploadContext(t, "f", uploadFile{"a.bin", []byte(content)})
+	file, err := c.FormFile("f")
+	require.NoError(t, err)
+
+	var head []byte
+	accept := UploadInspectorFunc(func(ctx context.Context, info UploadInfo, r io.Reader) error {
+		head = make([]byte, 4)
+		_, err := io.ReadFull(r, head)
+		return err
+	})
+	path, err := c.SaveUploadedFileChecked(file, dir, UploadOptions{Inspector: accept})
+	require.NoError(t, err)
+	assert.Equal(t, "zzzz", string(head))
+	data, _ := os.ReadFile(path)
+	assert.Equal(t, content, string(data))
+
+	errScanner := errors.New("scanner unavailable")
+	fail := UploadInspectorFunc(func(ctx context.Context, info UploadInfo, r io.Reader) error {
+		return errScanner
+	})
+	_, err = c.SaveUploadedFileChecked(file, dir, UploadOptions{Inspector: fail, Filename: "b.bin"})
+	assert.Equal(t, errScanner, err)
+	assert.Empty(t, c.Errors)
+	_, err = os.Stat(filepath.Join(dir, "b.bin"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestEngineUploadInspector(t *testing.T) {
+	dir := t.TempDir()
+	c := uploadContext(t, "f", uploadFile{"virus.txt", []byte("EICAR")}, uploadFile{"clean.txt", []byte("ok")})
+	c.engine.UploadInspector = signatureScanner
+	files, err := c.FormFiles("f")
+	require.NoError(t, err)
+
+	err = c.SaveUploadedFile(files[0], filepath.Join(dir, "virus.txt"))
+	assert.ErrorIs(t, err, ErrUploadRejected)
+	assert.Len(t, c.Errors, 1)
+
+	dst := filepath.Join(dir, "sub", "clean.txt")
+	require.NoError(t, c.SaveUploadedFile(files[1], dst))
+	data, _ := os.ReadFile(dst)
+	assert.Equal(t, "ok", string(data))
+
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,156 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type uploadFile struct {
+	name string
+	data []byte
+}
+
+func uploadContext(t *testing.T, field string, files ...uploadFile) *Context {
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+	for _, f := range files {
+		w, err := mw.CreateFormFile(field, f.name)
+		require.NoError(t, err)
+		_, err = w.Write(f.data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", buf)
+	c.Request.Header.Set("Content-Type", mw.FormDataContentType())
+	return c
+}
+
+func pngImage(t *testing.T, width, height int) []byte {
+	buf := new(bytes.Buffer)
+	require.NoError(t, png.Encode(buf, image.NewGray(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestFormFiles(t *testing.T) {
+	c := uploadContext(t, "docs", uploadFile{"a.txt", []byte("a")}, uploadFile{"b.txt", []byte("b")})
+	files, err := c.FormFiles("docs")
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "a.txt", files[0].Filename)
+	assert.Equal(t, "b.txt", files[1].Filename)
+
+	_, err = c.FormFiles("missing")
+	assert.Equal(t, http.ErrMissingFile, err)
+}
+
+func TestSaveUploadedFileChecked(t *testing.T) {
+	dir := t.TempDir()
+	c := uploadContext(t, "avatar", uploadFile{`..\..\evil dir/../me&you.png`, pngImage(t, 10, 20)})
+	file, err := c.FormFile("avatar")
+	require.NoError(t, err)
+
+	opts := UploadOptions{AllowedTypes: []string{"image/*"}, MaxSize: 1 << 20, MaxWidth: 10, MaxHeight: 20}
+	path, err := c.SaveUploadedFileChecked(file, dir, opts)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "me_you.png"), path)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, file.Size, info.Size())
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	_, err = c.SaveUploadedFileChecked(file, dir, opts)
+	assert.ErrorIs(t, err, ErrUploadExists)
+	opts.Overwrite = true
+	_, err = c.SaveUploadedFileChecked(file, dir, opts)
+	assert.NoError(t, err)
+
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 1, "no temporary file is left")
+}
+
+func TestSaveUploadedFileCheckedCreatedMeanwhile(t *testing.T) {
+	dir := t.TempDir()
+	c := uploadContext(t, "doc", uploadFile{"doc.txt", []byte("upload")})
+	file, err := c.FormFile("doc")
+	require.NoError(t, err)
+
+	// the file is created by another request while the upload is inspected.
+	dst := filepath.Join(dir, "doc.txt")
+	inspector := UploadInspectorFunc(func(ctx context.Context, info UploadInfo, r io.Reader) error {
+		_, err := io.Copy(io.Discard, r)
+		require.NoError(t, os.WriteFile(dst, []byte("other"), 0o600))
+		return err
+	})
+	_, err = c.SaveUploadedFileChecked(file, dir, UploadOptions{Inspector: inspector})
+	assert.ErrorIs(t, err, ErrUploadExists)
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "other", string(data))
+
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 1, "no temporary file is left")
+}
+
+func TestSaveUploadedFileCheckedRejects(t *testing.T) {
+	dir := t.TempDir()
+	c := uploadContext(t, "f", uploadFile{"photo.png", []byte("#!/bin/sh\necho pwned\n")}, uploadFile{"big.png", pngImage(t, 300, 10)})
+	files, err := c.FormFiles("f")
+	require.NoError(t, err)
+
+	_, err = c.SaveUploadedFileChecked(files[0], dir, UploadOptions{AllowedTypes: []string{"image/png", "image/jpeg"}})
+	var uploadErr *UploadError
+	require.True(t, errors.As(err, &uploadErr))
+	assert.Equal(t, ErrUploadType, uploadErr.Reason)
+	assert.Equal(t, "photo.png", uploadErr.Filename)
+	assert.Equal(t, "text/plain; charset=utf-8", uploadErr.ContentType)
+	assert.Equal(t, `upload "photo.png" rejected: file type not allowed`, err.Error())
+
+	_, err = c.SaveUploadedFileChecked(files[1], dir, UploadOptions{MaxWidth: 100})
+	assert.ErrorIs(t, err, ErrUploadDimensions)
+
+	_, err = c.SaveUploadedFileChecked(files[0], dir, UploadOptions{MaxWidth: 100})
+	assert.ErrorIs(t, err, ErrUploadDimensions)
+
+	_, err = c.SaveUploadedFileChecked(files[1], dir, UploadOptions{MaxSize: 10})
+	assert.ErrorIs(t, err, ErrUploadTooLarge)
+
+	_, err = c.SaveUploadedFileChecked(files[1], dir, UploadOptions{Filename: "../escape.png"})
+	assert.Error(t, err)
+
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries)
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	for name, want := range map[string]string{
+		"report.pdf":                      "report.pdf",
+		"../../etc/passwd":                "passwd",
+		`C:\Users\me\cv (1).docx`:         "cv__1_.docx",
+		".htaccess":                       "htaccess",
+		"résumé.txt":                      "r_sum_.txt",
+		"..":                              "file",
+		"":                                "file",
+		strings.Repeat("a", 300) + ".jpg": strings.Repeat("a", 251) + ".jpg",
+	} {
+		assert.Equal(t, want, SanitizeFilename(name), name)
+	}
+}
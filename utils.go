@@ -122,6 +122,11 @@ func lastChar(str string) uint8 {
 }
 
 func nameOfFunction(f any) string {
+	if h, ok := f.(HandlerFunc); ok {
+		if nh, ok := lookupNamedHandler(h); ok {
+			return nh.name
+		}
+	}
 	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
 }
 
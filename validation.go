@@ -0,0 +1,21 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import "github.com/gin-gonic/gin/binding"
+
+// ValidationGroups returns a middleware that makes the bindings of the following
+// handlers only validate the fields of the given groups (scenarios), e.g.
+//
+//	router.POST("/users", gin.ValidationGroups("create"), createUser)
+//	router.PUT("/users/:id", gin.ValidationGroups("update"), updateUser)
+//
+// See binding.WithValidationGroups.
+func ValidationGroups(groups ...string) HandlerFunc {
+	return func(c *Context) {
+		c.Request = c.Request.WithContext(binding.WithValidationGroups(c.Request.Context(), groups...))
+		c.Next()
+	}
+}
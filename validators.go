@@ -0,0 +1,129 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// IfNoneMatch sets the ETag response header to etag, quoted if needed, and
+// evaluates the If-None-Match request header against it (RFC 9110 13.1.2).
+// When the representation of the client is current, it answers 304 Not Modified
+// to GET and HEAD requests and 412 Precondition Failed to the others, aborts the
+// chain and returns true, so that the handler can return at once:
+//
+//	if c.IfNoneMatch(article.Version) {
+//		return
+//	}
+//	c.JSON(http.StatusOK, article)
+func (c *Context) IfNoneMatch(etag string) bool {
+	etag = quoteETag(etag)
+	c.Header("ETag", etag)
+	inm := c.requestHeader("If-None-Match")
+	if inm == "" || !etagListMatches(inm, etag) {
+		return false
+	}
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		c.NotModified()
+	} else {
+		c.AbortWithStatus(http.StatusPreconditionFailed)
+	}
+	return true
+}
+
+// IfModifiedSince sets the Last-Modified response header to modtime and evaluates
+// the If-Modified-Since request header against it (RFC 9110 13.1.3). When the
+// representation of the client is current, it answers 304 Not Modified, aborts
+// the chain and returns true. The header only applies to GET and HEAD requests,
+// and is ignored when the request has an If-None-Match header, so IfNoneMatch
+// must be called first when both validators are known.
+func (c *Context) IfModifiedSince(modtime time.Time) bool {
+	if modtime.IsZero() || modtime.Equal(time.Unix(0, 0)) {
+		return false
+	}
+	c.Header("Last-Modified", modtime.UTC().Format(http.TimeFormat))
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+	ims := c.requestHeader("If-Modified-Since")
+	if ims == "" || c.requestHeader("If-None-Match") != "" {
+		return false
+	}
+	t, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	// the header has a precision of one second.
+	if modtime.Truncate(time.Second).After(t) {
+		return false
+	}
+	c.NotModified()
+	return true
+}
+
+// NotModified answers 304 Not Modified and aborts the chain. The headers which
+// describe the content are removed, those which a 304 response must repeat
+// (Cache-Control, ETag, Expires, Vary...) are kept (RFC 9110 15.4.5).
+func (c *Context) NotModified() {
+	h := c.Writer.Header()
+	delete(h, "Content-Type")
+	delete(h, "Content-Length")
+	delete(h, "Content-Encoding")
+	if h.Get("ETag") != "" {
+		delete(h, "Last-Modified")
+	}
+	c.AbortWithStatus(http.StatusNotModified)
+}
+
+// quoteETag returns etag as an entity-tag: strong, weak ("W/" prefix) and
+// already quoted values are kept, other values are quoted.
+func quoteETag(etag string) string {
+	if strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// etagListMatches reports whether the comma-separated list of entity-tags of an
+// If-None-Match header matches etag, with the weak comparison.
+func etagListMatches(list, etag string) bool {
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+	for {
+		list = strings.TrimLeft(list, " \t,")
+		if list == "" {
+			return false
+		}
+		tag, rest := scanETag(list)
+		if tag == "" {
+			return false
+		}
+		if strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+		list = rest
+	}
+}
+
+// scanETag returns the entity-tag at the beginning of s and the rest of s, or
+// an empty tag when s does not begin with a valid entity-tag.
+func scanETag(s string) (tag, rest string) {
+	start := 0
+	if strings.HasPrefix(s, "W/") {
+		start = 2
+	}
+	if len(s[start:]) < 2 || s[start] != '"' {
+		return "", ""
+	}
+	end := strings.IndexByte(s[start+1:], '"')
+	if end < 0 {
+		return "", ""
+	}
+	end += start + 2
+	return s[:end], s[end:]
+}
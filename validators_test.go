@@ -0,0 +1,96 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIfNoneMatch(t *testing.T) {
+	router := New()
+	handler := func(c *Context) {
+		c.Header("Cache-Control", "max-age=60")
+		c.Header("Content-Type", "application/json")
+		if c.IfNoneMatch("v2") {
+			return
+		}
+		c.String(http.StatusOK, "body")
+	}
+	router.GET("/", handler)
+	router.PUT("/", handler)
+
+	tests := []struct {
+		method, inm string
+		code        int
+	}{
+		{http.MethodGet, "", http.StatusOK},
+		{http.MethodGet, `"v1"`, http.StatusOK},
+		{http.MethodGet, `"v2"`, http.StatusNotModified},
+		{http.MethodGet, `W/"v2"`, http.StatusNotModified},
+		{http.MethodGet, `"v1", W/"v2"`, http.StatusNotModified},
+		{http.MethodGet, `"a,b" ,"v2"`, http.StatusNotModified},
+		{http.MethodGet, `*`, http.StatusNotModified},
+		{http.MethodGet, `v2`, http.StatusOK},
+		{http.MethodPut, `*`, http.StatusPreconditionFailed},
+		{http.MethodPut, `"v1"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := PerformRequest(router, tt.method, "/", header{"If-None-Match", tt.inm})
+		assert.Equal(t, tt.code, w.Code, tt.method+" "+tt.inm)
+		assert.Equal(t, `"v2"`, w.Header().Get("ETag"))
+		if tt.code == http.StatusNotModified {
+			assert.Empty(t, w.Body.String())
+			assert.Empty(t, w.Header().Get("Content-Type"))
+			assert.Equal(t, "max-age=60", w.Header().Get("Cache-Control"))
+		}
+	}
+}
+
+func TestIfModifiedSince(t *testing.T) {
+	modtime := time.Date(2023, 5, 1, 10, 0, 0, 500, time.UTC)
+	router := New()
+	router.Any("/", func(c *Context) {
+		if c.IfModifiedSince(modtime) {
+			return
+		}
+		c.String(http.StatusOK, "body")
+	})
+
+	tests := []struct {
+		method string
+		header []header
+		code   int
+	}{
+		{http.MethodGet, nil, http.StatusOK},
+		{http.MethodGet, []header{{"If-Modified-Since", "Mon, 01 May 2023 10:00:00 GMT"}}, http.StatusNotModified},
+		{http.MethodHead, []header{{"If-Modified-Since", "Mon, 01 May 2023 11:00:00 GMT"}}, http.StatusNotModified},
+		{http.MethodGet, []header{{"If-Modified-Since", "Mon, 01 May 2023 09:59:59 GMT"}}, http.StatusOK},
+		{http.MethodGet, []header{{"If-Modified-Since", "invalid"}}, http.StatusOK},
+		{http.MethodGet, []header{{"If-Modified-Since", "Mon, 01 May 2023 10:00:00 GMT"}, {"If-None-Match", `"x"`}}, http.StatusOK},
+		{http.MethodPost, []header{{"If-Modified-Since", "Mon, 01 May 2023 10:00:00 GMT"}}, http.StatusOK},
+	}
+	for i, tt := range tests {
+		w := PerformRequest(router, tt.method, "/", tt.header...)
+		assert.Equal(t, tt.code, w.Code, i)
+		assert.Equal(t, "Mon, 01 May 2023 10:00:00 GMT", w.Header().Get("Last-Modified"))
+	}
+}
+
+func TestNotModifiedDropsLastModifiedWithETag(t *testing.T) {
+	router := New()
+	router.GET("/", func(c *Context) {
+		if c.IfNoneMatch(`W/"1"`) || c.IfModifiedSince(time.Now()) {
+			return
+		}
+	})
+	w := PerformRequest(router, http.MethodGet, "/", header{"If-None-Match", `W/"1"`})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, `W/"1"`, w.Header().Get("ETag"))
+	assert.Empty(t, w.Header().Get("Last-Modified"))
+}
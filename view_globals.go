@@ -0,0 +1,54 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+// ViewGlobals registers functions returning variables merged into the data of
+// every template rendered by Context.HTML, e.g. the current user, the CSRF
+// token or the flash messages, so that the handlers do not pass them each time:
+//
+//	router.ViewGlobals(func(c *gin.Context) map[string]any {
+//		return map[string]any{"User": c.Value("user"), "Flashes": c.Flashes()}
+//	})
+//	router.GET("/", func(c *gin.Context) {
+//		c.HTML(http.StatusOK, "index.tmpl", gin.H{"Title": "Home"})
+//	})
+//
+// The functions are called in order when a template is rendered, each one
+// overriding the variables of the previous ones, and the data given to
+// Context.HTML overrides them all. The data must be nil, a gin.H or a
+// map[string]any to be merged: other types, like structs, are rendered as is.
+// The fragments of Context.HTMLCached do not get the variables, which are
+// usually specific to the request. Like Use, it must be called before serving.
+func (engine *Engine) ViewGlobals(fns ...func(c *Context) map[string]any) {
+	engine.viewGlobals = append(engine.viewGlobals, fns...)
+}
+
+// viewData returns the data of Context.HTML merged with the view globals.
+func (c *Context) viewData(obj any) any {
+	if len(c.engine.viewGlobals) == 0 {
+		return obj
+	}
+	var data map[string]any
+	switch obj := obj.(type) {
+	case nil:
+	case H:
+		data = obj
+	case map[string]any:
+		data = obj
+	default:
+		return obj
+	}
+
+	merged := make(H, len(data))
+	for _, fn := range c.engine.viewGlobals {
+		for key, value := range fn(c) {
+			merged[key] = value
+		}
+	}
+	for key, value := range data {
+		merged[key] = value
+	}
+	return merged
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"html/template"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewGlobals(t *testing.T) {
+	router := New()
+	router.SetHTMLTemplate(template.Must(template.New("").Parse(
+		`{{define "page"}}{{.Title}}|{{.User}}|{{.Token}}{{end}}{{define "struct"}}{{.Title}}{{end}}`)))
+	router.ViewGlobals(func(c *Context) map[string]any {
+		return map[string]any{"User": c.GetString("user"), "Title": "Default", "Token": "t1"}
+	})
+	router.ViewGlobals(func(c *Context) map[string]any {
+		return map[string]any{"Token": "t2"}
+	})
+	router.Use(func(c *Context) { c.Set("user", "ada") })
+	router.GET("/h", func(c *Context) { c.HTML(http.StatusOK, "page", H{"Title": "Home"}) })
+	router.GET("/map", func(c *Context) { c.HTML(http.StatusOK, "page", map[string]any{"Token": "own"}) })
+	router.GET("/nil", func(c *Context) { c.HTML(http.StatusOK, "page", nil) })
+	router.GET("/struct", func(c *Context) {
+		c.HTML(http.StatusOK, "struct", struct{ Title string }{"Struct"})
+	})
+
+	bodies := map[string]string{
+		"/h":      "Home|ada|t2",
+		"/map":    "Default|ada|own",
+		"/nil":    "Default|ada|t2",
+		"/struct": "Struct",
+	}
+	for path, body := range bodies {
+		w := PerformRequest(router, http.MethodGet, path)
+		assert.Equal(t, body, w.Body.String(), path)
+	}
+}
+
+func TestViewGlobalsDataNotModified(t *testing.T) {
+	router := New()
+	router.SetHTMLTemplate(template.Must(template.New("").Parse(`{{define "page"}}{{.A}}{{.B}}{{end}}`)))
+	router.ViewGlobals(func(c *Context) map[string]any { return map[string]any{"B": "b"} })
+	data := H{"A": "a"}
+	router.GET("/", func(c *Context) { c.HTML(http.StatusOK, "page", data) })
+
+	w := PerformRequest(router, http.MethodGet, "/")
+	assert.Equal(t, "ab", w.Body.String())
+	assert.Equal(t, H{"A": "a"}, data)
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// warmup holds the functions registered with Engine.Warmup. next is the index
+// of the first one which has not run yet, and pending the number of functions
+// which have not run yet, for State.
+type warmup struct {
+	mu      sync.Mutex
+	funcs   []func(ctx context.Context) error
+	next    int
+	pending atomic.Int32
+}
+
+// Warmup registers functions which prepare the engine for traffic (filling
+// caches, opening connection pools, compiling templates...). The Run methods
+// run them, in order, before accepting connections, and ServerState.Ready is
+// false until they have run. Their context is done after Engine.WarmupTimeout.
+// A failure makes the Run method return the error, unless
+// Engine.IgnoreWarmupErrors is set.
+//
+//	router.Warmup(loadProductCache, pingDatabase)
+//	log.Fatal(router.Run(":8080"))
+func (engine *Engine) Warmup(funcs ...func(ctx context.Context) error) {
+	w := &engine.warmup
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.funcs = append(w.funcs, funcs...)
+	w.pending.Add(int32(len(funcs)))
+}
+
+// RunWarmup runs the warmup functions which have not run yet, see Warmup. It is
+// called by the Run methods, and must be called before serving with a server
+// configured outside of gin, see TrackServer.
+func (engine *Engine) RunWarmup(ctx context.Context) error {
+	w := &engine.warmup
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.next == len(w.funcs) {
+		return nil
+	}
+	if engine.WarmupTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, engine.WarmupTimeout)
+		defer cancel()
+	}
+	for ; w.next < len(w.funcs); w.next++ {
+		if err := w.funcs[w.next](ctx); err != nil {
+			err = fmt.Errorf("gin: warmup failed: %w", err)
+			if !engine.IgnoreWarmupErrors {
+				return err
+			}
+			fmt.Fprintf(DefaultErrorWriter, "[GIN] %v\n", err)
+		}
+		w.pending.Add(-1)
+	}
+	return nil
+}
+
+// warmedUp reports whether all the warmup functions have run.
+func (engine *Engine) warmedUp() bool {
+	return engine.warmup.pending.Load() == 0
+}
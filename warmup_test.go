@@ -0,0 +1,89 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmupBeforeServing(t *testing.T) {
+	router := New()
+	router.GET("/", func(c *Context) { c.String(http.StatusOK, "ok") })
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var order []string
+	router.Warmup(func(ctx context.Context) error {
+		order = append(order, "first")
+		close(started)
+		<-release
+		return nil
+	}, func(ctx context.Context) error {
+		order = append(order, "second")
+		return nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	runErr := make(chan error, 1)
+	go func() { runErr <- router.RunListener(listener) }()
+
+	<-started
+	assert.Equal(t, ServerState{Phase: ServerStarting}, router.State())
+	close(release)
+	assert.Eventually(t, func() bool { return router.State().Ready }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"first", "second"}, order)
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, router.Shutdown(context.Background()))
+	assert.Equal(t, http.ErrServerClosed, <-runErr)
+	assert.False(t, router.State().Ready)
+}
+
+func TestWarmupFailure(t *testing.T) {
+	errCold := errors.New("cache unavailable")
+	router := New()
+	router.Warmup(func(ctx context.Context) error { return errCold })
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	err = router.RunListener(listener)
+	assert.ErrorIs(t, err, errCold)
+	assert.EqualError(t, err, "gin: warmup failed: cache unavailable")
+	_, err = net.Dial("tcp", listener.Addr().String())
+	assert.Error(t, err)
+	assert.False(t, router.warmedUp())
+}
+
+func TestWarmupTimeoutAndIgnoredErrors(t *testing.T) {
+	var out bytes.Buffer
+	defer func(w io.Writer) { DefaultErrorWriter = w }(DefaultErrorWriter)
+	DefaultErrorWriter = &out
+
+	router := New()
+	router.WarmupTimeout = 10 * time.Millisecond
+	router.IgnoreWarmupErrors = true
+	router.Warmup(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.False(t, router.warmedUp())
+	assert.NoError(t, router.RunWarmup(context.Background()))
+	assert.True(t, router.warmedUp())
+	assert.Equal(t, "[GIN] gin: warmup failed: context deadline exceeded\n", out.String())
+}
@@ -0,0 +1,536 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin/internal/json"
+)
+
+// DeliveryState is the state of a WebhookDelivery.
+type DeliveryState string
+
+const (
+	// DeliveryPending is the state of the deliveries waiting for an attempt.
+	DeliveryPending DeliveryState = "pending"
+	// DeliverySucceeded is the state of the deliveries answered with a 2xx status.
+	DeliverySucceeded DeliveryState = "succeeded"
+	// DeliveryFailed is the state of the deliveries given up after
+	// WebhookConfig.MaxAttempts attempts.
+	DeliveryFailed DeliveryState = "failed"
+)
+
+// WebhookEndpoint is a receiver of the events published by Webhooks.
+type WebhookEndpoint struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Secret signs the deliveries to the endpoint, see Webhooks. The deliveries
+	// are not signed without a secret.
+	Secret string `json:"-"`
+	// Events are the event types sent to the endpoint, all of them when empty.
+	Events []string `json:"events,omitempty"`
+}
+
+func (e WebhookEndpoint) accepts(eventType string) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, t := range e.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is the delivery of an event to an endpoint.
+type WebhookDelivery struct {
+	ID         string             `json:"id"`
+	EndpointID string             `json:"endpoint_id"`
+	EventID    string             `json:"event_id"`
+	EventType  string             `json:"event_type"`
+	Body       stdjson.RawMessage `json:"body"`
+	State      DeliveryState      `json:"state"`
+	Attempts   int                `json:"attempts"`
+	// NextAttempt is the time of the next attempt of a pending delivery.
+	NextAttempt time.Time `json:"next_attempt"`
+	// LastStatus and LastError are the outcome of the last attempt.
+	LastStatus int       `json:"last_status,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WebhookFilter selects the deliveries listed by WebhookStore.List. Its zero
+// fields do not filter.
+type WebhookFilter struct {
+	EndpointID string
+	State      DeliveryState
+	Limit      int
+}
+
+// WebhookStore persists the deliveries, e.g. in a database for them to survive
+// a restart. It must be safe for concurrent use.
+type WebhookStore interface {
+	// Save inserts or updates d.
+	Save(ctx context.Context, d WebhookDelivery) error
+	// Due returns up to limit pending deliveries whose NextAttempt is not after
+	// now. A store shared by several instances must hand a delivery to one of
+	// them only, e.g. by leasing it until the attempt is saved.
+	Due(ctx context.Context, now time.Time, limit int) ([]WebhookDelivery, error)
+	// Get returns the delivery id, reporting false when it does not exist.
+	Get(ctx context.Context, id string) (WebhookDelivery, bool, error)
+	// List returns the deliveries selected by filter, the latest first.
+	List(ctx context.Context, filter WebhookFilter) ([]WebhookDelivery, error)
+}
+
+// WebhookConfig defines the config for Engine.EnableWebhooks.
+type WebhookConfig struct {
+	// Endpoints are the initial endpoints, see Webhooks.AddEndpoint.
+	// Optional.
+	Endpoints []WebhookEndpoint
+
+	// Store persists the deliveries.
+	// Optional. Default value is a NewMemoryWebhookStore.
+	Store WebhookStore
+
+	// Client sends the deliveries.
+	// Optional. Default value is a client with a timeout of 10 seconds.
+	Client *http.Client
+
+	// MaxAttempts is the number of attempts of a delivery before it fails.
+	// Optional. Default value is 8.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry, doubled for each of the next
+	// ones up to MaxBackoff.
+	// Optional. Default values are 10 seconds and 1 hour.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// Interval is the period at which the due deliveries are sent.
+	// Optional. Default value is 1 second.
+	Interval time.Duration
+
+	// Concurrency is the maximum number of deliveries sent at once.
+	// Optional. Default value is 4.
+	Concurrency int
+
+	// OnFailed is called when a delivery fails, e.g. to alert the owner of the
+	// endpoint or to disable it.
+	// Optional.
+	OnFailed func(d WebhookDelivery)
+}
+
+// Webhooks publishes events to webhook endpoints, see Engine.EnableWebhooks.
+type Webhooks struct {
+	engine *Engine
+	config WebhookConfig
+
+	mu        sync.RWMutex
+	endpoints map[string]WebhookEndpoint
+}
+
+// EnableWebhooks starts an outbound webhook dispatcher: Webhooks.Publish stores
+// a delivery of the event for each endpoint, sent by the dispatcher as a JSON
+// POST request:
+//
+//	{"id": "...", "type": "invoice.paid", "created_at": "...", "data": {...}}
+//
+// with the X-Webhook-ID, X-Webhook-Event and X-Webhook-Timestamp headers. When
+// the endpoint has a secret, X-Webhook-Signature is "sha256=" followed by the
+// hex HMAC-SHA256 of the timestamp, a dot and the body. A delivery answered
+// with another status than 2xx, or not answered, is retried with exponential
+// backoff up to WebhookConfig.MaxAttempts times. The dispatcher runs until
+// Engine.Shutdown, like the jobs of Engine.Schedule. The receivers can verify
+// the signature with the webhooks.Gin middleware.
+func (engine *Engine) EnableWebhooks(config WebhookConfig) *Webhooks {
+	if config.Store == nil {
+		config.Store = NewMemoryWebhookStore()
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 8
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = 10 * time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = time.Hour
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 4
+	}
+	w := &Webhooks{engine: engine, config: config, endpoints: make(map[string]WebhookEndpoint)}
+	for _, endpoint := range config.Endpoints {
+		w.AddEndpoint(endpoint)
+	}
+	engine.schedules.start(everySchedule(config.Interval), w.dispatch, ScheduleConfig{Location: time.Local})
+	return w
+}
+
+// AddEndpoint adds or replaces the endpoint with the ID of endpoint.
+func (w *Webhooks) AddEndpoint(endpoint WebhookEndpoint) {
+	assert1(endpoint.ID != "" && endpoint.URL != "", "webhook endpoints require an ID and a URL")
+	w.mu.Lock()
+	w.endpoints[endpoint.ID] = endpoint
+	w.mu.Unlock()
+}
+
+// RemoveEndpoint removes the endpoint id. Its pending deliveries fail.
+func (w *Webhooks) RemoveEndpoint(id string) {
+	w.mu.Lock()
+	delete(w.endpoints, id)
+	w.mu.Unlock()
+}
+
+// Endpoints returns the endpoints, sorted by ID.
+func (w *Webhooks) Endpoints() []WebhookEndpoint {
+	w.mu.RLock()
+	endpoints := make([]WebhookEndpoint, 0, len(w.endpoints))
+	for _, endpoint := range w.endpoints {
+		endpoints = append(endpoints, endpoint)
+	}
+	w.mu.RUnlock()
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].ID < endpoints[j].ID })
+	return endpoints
+}
+
+func (w *Webhooks) endpoint(id string) (WebhookEndpoint, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	endpoint, ok := w.endpoints[id]
+	return endpoint, ok
+}
+
+// Publish stores a delivery of the event of type eventType, whose data is
+// payload encoded in JSON, for each endpoint accepting it, and returns the ID of
+// the event. The deliveries are sent by the dispatcher.
+func (w *Webhooks) Publish(ctx context.Context, eventType string, payload any) (string, error) {
+	eventID := newWebhookID()
+	now := time.Now()
+	body, err := json.Marshal(struct {
+		ID        string    `json:"id"`
+		Type      string    `json:"type"`
+		CreatedAt time.Time `json:"created_at"`
+		Data      any       `json:"data"`
+	}{eventID, eventType, now.UTC(), payload})
+	if err != nil {
+		return "", err
+	}
+	for _, endpoint := range w.Endpoints() {
+		if !endpoint.accepts(eventType) {
+			continue
+		}
+		d := WebhookDelivery{
+			ID:          newWebhookID(),
+			EndpointID:  endpoint.ID,
+			EventID:     eventID,
+			EventType:   eventType,
+			Body:        body,
+			State:       DeliveryPending,
+			NextAttempt: now,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := w.config.Store.Save(ctx, d); err != nil {
+			return eventID, err
+		}
+	}
+	return eventID, nil
+}
+
+// Retry schedules a new series of attempts of the delivery id, e.g. once a
+// failing endpoint is fixed.
+func (w *Webhooks) Retry(ctx context.Context, id string) (WebhookDelivery, error) {
+	d, ok, err := w.config.Store.Get(ctx, id)
+	if err != nil || !ok {
+		return d, err
+	}
+	d.State = DeliveryPending
+	d.Attempts = 0
+	d.NextAttempt = time.Now()
+	d.UpdatedAt = d.NextAttempt
+	return d, w.config.Store.Save(ctx, d)
+}
+
+// dispatch sends the due deliveries.
+func (w *Webhooks) dispatch(ctx context.Context) {
+	due, err := w.config.Store.Due(ctx, time.Now(), 10*w.config.Concurrency)
+	if err != nil {
+		debugPrintWarning("webhooks: %v", err)
+		return
+	}
+	sem := make(chan struct{}, w.config.Concurrency)
+	var wg sync.WaitGroup
+	for _, d := range due {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(d WebhookDelivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			defer recoverJob("webhook delivery")
+			w.deliver(ctx, d)
+		}(d)
+	}
+	wg.Wait()
+}
+
+// deliver makes an attempt of d and saves its outcome.
+func (w *Webhooks) deliver(ctx context.Context, d WebhookDelivery) {
+	d.Attempts++
+	var err error
+	endpoint, ok := w.endpoint(d.EndpointID)
+	if ok {
+		d.LastStatus, err = w.send(ctx, endpoint, d)
+	} else {
+		err = fmt.Errorf("webhooks: unknown endpoint %q", d.EndpointID)
+		d.Attempts = w.config.MaxAttempts
+	}
+	d.UpdatedAt = time.Now()
+	switch {
+	case err == nil:
+		d.State = DeliverySucceeded
+		d.LastError = ""
+	case d.Attempts >= w.config.MaxAttempts:
+		d.State = DeliveryFailed
+		d.LastError = err.Error()
+	default:
+		d.LastError = err.Error()
+		d.NextAttempt = d.UpdatedAt.Add(w.backoff(d.Attempts))
+	}
+	if err := w.config.Store.Save(context.Background(), d); err != nil {
+		debugPrintWarning("webhooks: %v", err)
+	}
+	if d.State == DeliveryFailed && w.config.OnFailed != nil {
+		w.config.OnFailed(d)
+	}
+}
+
+// backoff returns the delay after the attempt number attempts.
+func (w *Webhooks) backoff(attempts int) time.Duration {
+	delay := w.config.Backoff
+	for i := 1; i < attempts && delay < w.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > w.config.MaxBackoff {
+		delay = w.config.MaxBackoff
+	}
+	return delay
+}
+
+func (w *Webhooks) send(ctx context.Context, endpoint WebhookEndpoint, d WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", MIMEJSON)
+	req.Header.Set("X-Webhook-ID", d.ID)
+	req.Header.Set("X-Webhook-Event", d.EventType)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	if endpoint.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(endpoint.Secret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(d.Body)
+		req.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := w.config.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhooks: endpoint answered %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Mount registers the delivery status endpoints under prefix, guarded by
+// middleware (at least one, typically an authentication middleware), for
+// dashboards:
+//
+//	GET  {prefix}/endpoints               the endpoints
+//	GET  {prefix}/deliveries              the deliveries, latest first; ?endpoint=, ?state= and ?limit= (default 100)
+//	GET  {prefix}/deliveries/:id          a delivery
+//	POST {prefix}/deliveries/:id/retry    retries a delivery, see Retry
+func (w *Webhooks) Mount(prefix string, middleware ...HandlerFunc) *RouterGroup {
+	assert1(len(middleware) > 0, "webhook endpoints must be guarded by a middleware")
+	group := w.engine.Group(prefix, middleware...)
+	group.GET("/endpoints", func(c *Context) {
+		c.JSON(http.StatusOK, w.Endpoints())
+	})
+	group.GET("/deliveries", func(c *Context) {
+		filter := WebhookFilter{EndpointID: c.Query("endpoint"), State: DeliveryState(c.Query("state")), Limit: 100}
+		if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit > 0 {
+			filter.Limit = limit
+		}
+		deliveries, err := w.config.Store.List(c, filter)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		c.JSON(http.StatusOK, deliveries)
+	})
+	group.GET("/deliveries/:id", func(c *Context) {
+		d, ok, err := w.config.Store.Get(c, c.Param("id"))
+		w.respondDelivery(c, d, ok, err)
+	})
+	group.POST("/deliveries/:id/retry", func(c *Context) {
+		d, ok, err := w.config.Store.Get(c, c.Param("id"))
+		if err == nil && ok {
+			d, err = w.Retry(c, d.ID)
+		}
+		w.respondDelivery(c, d, ok, err)
+	})
+	return group
+}
+
+func (w *Webhooks) respondDelivery(c *Context, d WebhookDelivery, ok bool, err error) {
+	switch {
+	case err != nil:
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+	case !ok:
+		c.AbortWithStatus(http.StatusNotFound)
+	default:
+		c.JSON(http.StatusOK, d)
+	}
+}
+
+func newWebhookID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id)
+}
+
+const defaultWebhookRetention = 24 * time.Hour
+
+// MemoryWebhookStore is a WebhookStore in memory, which loses the deliveries
+// on restart.
+type MemoryWebhookStore struct {
+	mu         sync.Mutex
+	deliveries map[string]WebhookDelivery
+	leased     map[string]bool
+	retention  time.Duration
+	swept      time.Time
+}
+
+// NewMemoryWebhookStore returns an empty MemoryWebhookStore keeping the
+// succeeded and failed deliveries for 24 hours.
+func NewMemoryWebhookStore() *MemoryWebhookStore {
+	return NewMemoryWebhookStoreWithRetention(defaultWebhookRetention)
+}
+
+// NewMemoryWebhookStoreWithRetention returns an empty MemoryWebhookStore
+// keeping the succeeded and failed deliveries for retention after their last
+// attempt, for the dashboards and the manual retries.
+func NewMemoryWebhookStoreWithRetention(retention time.Duration) *MemoryWebhookStore {
+	if retention <= 0 {
+		retention = defaultWebhookRetention
+	}
+	return &MemoryWebhookStore{
+		deliveries: make(map[string]WebhookDelivery),
+		leased:     make(map[string]bool),
+		retention:  retention,
+	}
+}
+
+// Save implements WebhookStore.
+func (s *MemoryWebhookStore) Save(_ context.Context, d WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(time.Now())
+	s.deliveries[d.ID] = d
+	delete(s.leased, d.ID)
+	return nil
+}
+
+// sweep drops the finished deliveries older than the retention, at most once
+// a minute.
+func (s *MemoryWebhookStore) sweep(now time.Time) {
+	if now.Sub(s.swept) < time.Minute {
+		return
+	}
+	s.swept = now
+	for id, d := range s.deliveries {
+		if d.State != DeliveryPending && now.Sub(d.UpdatedAt) > s.retention {
+			delete(s.deliveries, id)
+		}
+	}
+}
+
+// Due implements WebhookStore. The deliveries returned are leased until saved.
+func (s *MemoryWebhookStore) Due(_ context.Context, now time.Time, limit int) ([]WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []WebhookDelivery
+	for id, d := range s.deliveries {
+		if d.State == DeliveryPending && !d.NextAttempt.After(now) && !s.leased[id] {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttempt.Before(due[j].NextAttempt) })
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	for _, d := range due {
+		s.leased[d.ID] = true
+	}
+	return due, nil
+}
+
+// Get implements WebhookStore.
+func (s *MemoryWebhookStore) Get(_ context.Context, id string) (WebhookDelivery, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.deliveries[id]
+	return d, ok, nil
+}
+
+// List implements WebhookStore.
+func (s *MemoryWebhookStore) List(_ context.Context, filter WebhookFilter) ([]WebhookDelivery, error) {
+	s.mu.Lock()
+	list := make([]WebhookDelivery, 0, len(s.deliveries))
+	for _, d := range s.deliveries {
+		if (filter.EndpointID == "" || d.EndpointID == filter.EndpointID) && (filter.State == "" || d.State == filter.State) {
+			list = append(list, d)
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.After(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	if filter.Limit > 0 && len(list) > filter.Limit {
+		list = list[:filter.Limit]
+	}
+	return list, nil
+}
@@ -0,0 +1,249 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedHook struct {
+	header http.Header
+	body   []byte
+}
+
+func hookReceiver(t *testing.T, failures int32) (*httptest.Server, func() []receivedHook) {
+	var mu sync.Mutex
+	var received []receivedHook
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, receivedHook{r.Header.Clone(), body})
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []receivedHook {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]receivedHook(nil), received...)
+	}
+}
+
+func TestWebhooksPublish(t *testing.T) {
+	srv, received := hookReceiver(t, 0)
+	router := New()
+	hooks := router.EnableWebhooks(WebhookConfig{
+		Endpoints: []WebhookEndpoint{
+			{ID: "billing", URL: srv.URL, Secret: "s3cret", Events: []string{"invoice.paid"}},
+			{ID: "audit", URL: srv.URL + "/audit"},
+		},
+		Interval: 10 * time.Millisecond,
+	})
+	defer func() { assert.NoError(t, router.Shutdown(context.Background())) }()
+
+	eventID, err := hooks.Publish(context.Background(), "invoice.paid", H{"amount": 42})
+	require.NoError(t, err)
+	_, err = hooks.Publish(context.Background(), "user.created", H{"name": "alice"})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return len(received()) == 3 }, time.Second, 5*time.Millisecond)
+
+	var signed []receivedHook
+	for _, hook := range received() {
+		if hook.header.Get("X-Webhook-Signature") != "" {
+			signed = append(signed, hook)
+		}
+	}
+	require.Len(t, signed, 1)
+	hook := signed[0]
+	assert.Equal(t, "invoice.paid", hook.header.Get("X-Webhook-Event"))
+	assert.Equal(t, MIMEJSON, hook.header.Get("Content-Type"))
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(hook.header.Get("X-Webhook-Timestamp") + "."))
+	mac.Write(hook.body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), hook.header.Get("X-Webhook-Signature"))
+
+	var event struct {
+		ID   string         `json:"id"`
+		Type string         `json:"type"`
+		Data map[string]any `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(hook.body, &event))
+	assert.Equal(t, eventID, event.ID)
+	assert.Equal(t, "invoice.paid", event.Type)
+	assert.Equal(t, float64(42), event.Data["amount"])
+}
+
+func TestWebhooksRetries(t *testing.T) {
+	srv, received := hookReceiver(t, 2)
+	store := NewMemoryWebhookStore()
+	router := New()
+	hooks := router.EnableWebhooks(WebhookConfig{
+		Endpoints: []WebhookEndpoint{{ID: "e", URL: srv.URL}},
+		Store:     store,
+		Interval:  5 * time.Millisecond,
+		Backoff:   5 * time.Millisecond,
+	})
+	defer func() { assert.NoError(t, router.Shutdown(context.Background())) }()
+
+	_, err := hooks.Publish(context.Background(), "ping", nil)
+	require.NoError(t, err)
+	var deliveries []WebhookDelivery
+	require.Eventually(t, func() bool {
+		deliveries, err = store.List(context.Background(), WebhookFilter{State: DeliverySucceeded})
+		return err == nil && len(deliveries) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Len(t, received(), 1)
+	d := deliveries[0]
+	assert.Equal(t, 3, d.Attempts)
+	assert.Equal(t, http.StatusOK, d.LastStatus)
+	assert.Empty(t, d.LastError)
+}
+
+func TestWebhooksGiveUp(t *testing.T) {
+	srv, _ := hookReceiver(t, 1000)
+	failed := make(chan WebhookDelivery, 1)
+	router := New()
+	hooks := router.EnableWebhooks(WebhookConfig{
+		Endpoints:   []WebhookEndpoint{{ID: "e", URL: srv.URL}},
+		Interval:    5 * time.Millisecond,
+		Backoff:     time.Millisecond,
+		MaxAttempts: 3,
+		OnFailed:    func(d WebhookDelivery) { failed <- d },
+	})
+	defer func() { assert.NoError(t, router.Shutdown(context.Background())) }()
+	group := hooks.Mount("/webhooks", func(c *Context) {})
+	assert.Equal(t, "/webhooks", group.BasePath())
+
+	_, err := hooks.Publish(context.Background(), "ping", nil)
+	require.NoError(t, err)
+	var d WebhookDelivery
+	select {
+	case d = <-failed:
+	case <-time.After(time.Second):
+		t.Fatal("the delivery did not fail")
+	}
+	assert.Equal(t, DeliveryFailed, d.State)
+	assert.Equal(t, 3, d.Attempts)
+	assert.Equal(t, http.StatusBadGateway, d.LastStatus)
+	assert.Equal(t, "webhooks: endpoint answered 502", d.LastError)
+
+	w := PerformRequest(router, http.MethodGet, "/webhooks/deliveries?state=failed&endpoint=e")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list []WebhookDelivery
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, d.ID, list[0].ID)
+
+	w = PerformRequest(router, http.MethodGet, "/webhooks/deliveries?state=succeeded")
+	assert.Equal(t, "[]", w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/webhooks/deliveries/"+d.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"state":"failed"`)
+
+	w = PerformRequest(router, http.MethodGet, "/webhooks/deliveries/unknown")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = PerformRequest(router, http.MethodGet, "/webhooks/endpoints")
+	assert.Equal(t, `[{"id":"e","url":"`+srv.URL+`"}]`, w.Body.String())
+
+	hooks.RemoveEndpoint("e")
+	w = PerformRequest(router, http.MethodPost, "/webhooks/deliveries/"+d.ID+"/retry")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"state":"pending"`)
+	select {
+	case d = <-failed:
+		assert.Equal(t, `webhooks: unknown endpoint "e"`, d.LastError)
+	case <-time.After(time.Second):
+		t.Fatal("the retried delivery did not fail")
+	}
+}
+
+func TestWebhooksBackoff(t *testing.T) {
+	w := &Webhooks{config: WebhookConfig{Backoff: time.Second, MaxBackoff: 5 * time.Second}}
+	assert.Equal(t, time.Second, w.backoff(1))
+	assert.Equal(t, 2*time.Second, w.backoff(2))
+	assert.Equal(t, 4*time.Second, w.backoff(3))
+	assert.Equal(t, 5*time.Second, w.backoff(4))
+	assert.Equal(t, 5*time.Second, w.backoff(40))
+}
+
+func TestWebhooksMountRequiresMiddleware(t *testing.T) {
+	router := New()
+	hooks := router.EnableWebhooks(WebhookConfig{})
+	defer func() { assert.NoError(t, router.Shutdown(context.Background())) }()
+	assert.Panics(t, func() { hooks.Mount("/webhooks") })
+	assert.Panics(t, func() { hooks.AddEndpoint(WebhookEndpoint{ID: "e"}) })
+}
+
+func TestMemoryWebhookStoreDue(t *testing.T) {
+	s := NewMemoryWebhookStore()
+	ctx := context.Background()
+	now := time.Now()
+	require.NoError(t, s.Save(ctx, WebhookDelivery{ID: "a", State: DeliveryPending, NextAttempt: now.Add(-time.Second)}))
+	require.NoError(t, s.Save(ctx, WebhookDelivery{ID: "b", State: DeliveryPending, NextAttempt: now.Add(-time.Minute)}))
+	require.NoError(t, s.Save(ctx, WebhookDelivery{ID: "c", State: DeliveryPending, NextAttempt: now.Add(time.Minute)}))
+	require.NoError(t, s.Save(ctx, WebhookDelivery{ID: "d", State: DeliverySucceeded}))
+
+	due, err := s.Due(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, "b", due[0].ID)
+	assert.Equal(t, "a", due[1].ID)
+
+	due, _ = s.Due(ctx, now, 10)
+	assert.Empty(t, due, "leased until saved")
+	require.NoError(t, s.Save(ctx, getDelivery(t, s, "a")))
+	due, _ = s.Due(ctx, now, 10)
+	require.Len(t, due, 1)
+	assert.Equal(t, "a", due[0].ID)
+}
+
+func TestMemoryWebhookStoreRetention(t *testing.T) {
+	s := NewMemoryWebhookStoreWithRetention(time.Hour)
+	ctx := context.Background()
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, s.Save(ctx, WebhookDelivery{ID: "a", State: DeliverySucceeded, UpdatedAt: old}))
+	require.NoError(t, s.Save(ctx, WebhookDelivery{ID: "b", State: DeliveryFailed, UpdatedAt: old}))
+	require.NoError(t, s.Save(ctx, WebhookDelivery{ID: "c", State: DeliveryPending, UpdatedAt: old}))
+	require.NoError(t, s.Save(ctx, WebhookDelivery{ID: "d", State: DeliverySucceeded, UpdatedAt: time.Now()}))
+	assert.Len(t, s.deliveries, 4)
+
+	s.swept = time.Time{}
+	require.NoError(t, s.Save(ctx, WebhookDelivery{ID: "e", State: DeliveryPending}))
+	list, err := s.List(ctx, WebhookFilter{})
+	require.NoError(t, err)
+	ids := make([]string, 0, len(list))
+	for _, d := range list {
+		ids = append(ids, d.ID)
+	}
+	assert.ElementsMatch(t, []string{"c", "d", "e"}, ids)
+}
+
+func getDelivery(t *testing.T, s *MemoryWebhookStore, id string) WebhookDelivery {
+	d, ok, err := s.Get(context.Background(), id)
+	require.NoError(t, err)
+	require.True(t, ok)
+	return d
+}
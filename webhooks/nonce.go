@@ -0,0 +1,51 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package webhooks
+
+import (
+	"sync"
+	"time"
+)
+
+// NonceStore records the IDs of the received deliveries, e.g. in Redis with
+// SET NX for the instances of an application to share it. It must be safe for
+// concurrent use.
+type NonceStore interface {
+	// Seen reports whether id was recorded and is not expired yet, and records
+	// it until expiresAt otherwise.
+	Seen(id string, expiresAt time.Time) (bool, error)
+}
+
+// MemoryNonceStore is a NonceStore in memory.
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+	sweep  time.Time
+}
+
+// NewMemoryNonceStore returns an empty MemoryNonceStore.
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{nonces: make(map[string]time.Time)}
+}
+
+// Seen implements NonceStore. The expired IDs are dropped once a minute.
+func (s *MemoryNonceStore) Seen(id string, expiresAt time.Time) (bool, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.After(s.sweep) {
+		for nonce, expiry := range s.nonces {
+			if now.After(expiry) {
+				delete(s.nonces, nonce)
+			}
+		}
+		s.sweep = now.Add(time.Minute)
+	}
+	if expiry, ok := s.nonces[id]; ok && !now.After(expiry) {
+		return true, nil
+	}
+	s.nonces[id] = expiresAt
+	return false, nil
+}
@@ -0,0 +1,305 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package webhooks provides middlewares verifying the signature of the webhook
+// requests of GitHub, Stripe, Slack, gin (see gin.Engine.EnableWebhooks) and of
+// any sender signing with HMAC-SHA256, with timestamp tolerance and replay
+// protection:
+//
+//	router.POST("/hooks/github", webhooks.GitHub(secret), func(c *gin.Context) {
+//		var event PushEvent
+//		if err := c.ShouldBindJSON(&event); err != nil {
+//			...
+//		}
+//	})
+//
+// The body is read once with gin.Context.BodyBytes, so that the handlers can
+// still bind it, or get the raw bytes which were verified.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrMissingSignature is returned when the request is not signed.
+	ErrMissingSignature = errors.New("webhooks: missing signature")
+	// ErrInvalidSignature is returned when no secret matches the signature.
+	ErrInvalidSignature = errors.New("webhooks: invalid signature")
+	// ErrExpired is returned when the signed timestamp is out of the tolerance.
+	ErrExpired = errors.New("webhooks: timestamp out of tolerance")
+	// ErrReplayed is returned when the delivery was already received.
+	ErrReplayed = errors.New("webhooks: replayed delivery")
+)
+
+// Signed is what a Scheme learned of a verified request.
+type Signed struct {
+	// Timestamp is the signed time of the request, zero when the scheme does
+	// not sign one.
+	Timestamp time.Time
+	// ID identifies the delivery for replay protection. It must be made of
+	// signed data, e.g. the signature itself.
+	ID string
+}
+
+// Scheme verifies the signature of a webhook request with one of secrets,
+// which are several during a rotation.
+type Scheme func(header http.Header, body []byte, secrets [][]byte) (Signed, error)
+
+// Config defines the config of the Verify middleware.
+type Config struct {
+	// Scheme verifies the signatures.
+	// Required.
+	Scheme Scheme
+
+	// Secrets are the accepted secrets, the current one and the ones being
+	// rotated out.
+	// Required.
+	Secrets []string
+
+	// Tolerance is the maximum difference between the signed timestamp and
+	// the time the request is received. A negative value disables the check.
+	// Optional. Default value is 5 minutes.
+	Tolerance time.Duration
+
+	// Nonces records the received deliveries to reject the replays.
+	// Optional. Default value does not protect against replays.
+	Nonces NonceStore
+
+	// NonceTTL is how long a delivery is remembered by Nonces. It must be longer
+	// than Tolerance for the signed timestamps to cover the older replays.
+	// Optional. Default value is 24 hours.
+	NonceTTL time.Duration
+
+	// ErrorHandler is called when the verification fails.
+	// Optional. Default value aborts with 401 Unauthorized, 400 Bad Request
+	// when the body can not be read, or 413 Request Entity Too Large.
+	ErrorHandler func(c *gin.Context, err error)
+}
+
+// Verify returns a middleware verifying the signature of the webhook requests
+// with config.
+func Verify(config Config) gin.HandlerFunc {
+	if config.Scheme == nil || len(config.Secrets) == 0 {
+		panic("webhooks: Verify requires a Scheme and a Secret")
+	}
+	secrets := make([][]byte, len(config.Secrets))
+	for i, secret := range config.Secrets {
+		secrets[i] = []byte(secret)
+	}
+	if config.Tolerance == 0 {
+		config.Tolerance = 5 * time.Minute
+	}
+	if config.NonceTTL <= 0 {
+		config.NonceTTL = 24 * time.Hour
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = defaultErrorHandler
+	}
+	return func(c *gin.Context) {
+		if err := verify(c, config, secrets); err != nil {
+			config.ErrorHandler(c, err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func verify(c *gin.Context, config Config, secrets [][]byte) error {
+	body, err := c.BodyBytes()
+	if err != nil {
+		return err
+	}
+	signed, err := config.Scheme(c.Request.Header, body, secrets)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if config.Tolerance > 0 && !signed.Timestamp.IsZero() {
+		if d := now.Sub(signed.Timestamp); d > config.Tolerance || d < -config.Tolerance {
+			return ErrExpired
+		}
+	}
+	if config.Nonces != nil && signed.ID != "" {
+		seen, err := config.Nonces.Seen(signed.ID, now.Add(config.NonceTTL))
+		if err != nil {
+			return err
+		}
+		if seen {
+			return ErrReplayed
+		}
+	}
+	return nil
+}
+
+func defaultErrorHandler(c *gin.Context, err error) {
+	status := http.StatusUnauthorized
+	switch {
+	case errors.Is(err, gin.ErrBodyTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case !errors.Is(err, ErrMissingSignature) && !errors.Is(err, ErrInvalidSignature) &&
+		!errors.Is(err, ErrExpired) && !errors.Is(err, ErrReplayed):
+		status = http.StatusBadRequest
+	}
+	_ = c.AbortWithError(status, err)
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of payload with secret.
+func Sign(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkHex reports whether the hex signature sig is the HMAC-SHA256 of payload
+// with one of secrets, and returns the signature in lowercase to identify the
+// delivery, since hex.DecodeString accepts both cases.
+func checkHex(sig string, payload []byte, secrets [][]byte) (string, bool) {
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return "", false
+	}
+	for _, secret := range secrets {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(payload)
+		if hmac.Equal(got, mac.Sum(nil)) {
+			return hex.EncodeToString(got), true
+		}
+	}
+	return "", false
+}
+
+// HMAC returns a middleware verifying the hex-encoded HMAC-SHA256 of the body,
+// with an optional "sha256=" prefix, sent in the request header, see SchemeHMAC.
+func HMAC(header, secret string) gin.HandlerFunc {
+	return Verify(Config{Scheme: SchemeHMAC(header), Secrets: []string{secret}})
+}
+
+// SchemeHMAC is the Scheme of the hex-encoded HMAC-SHA256 of the body, with an
+// optional "sha256=" prefix, sent in header. The signature is the ID of the
+// delivery.
+func SchemeHMAC(header string) Scheme {
+	return func(h http.Header, body []byte, secrets [][]byte) (Signed, error) {
+		sig := strings.TrimPrefix(h.Get(header), "sha256=")
+		if sig == "" {
+			return Signed{}, ErrMissingSignature
+		}
+		id, ok := checkHex(sig, body, secrets)
+		if !ok {
+			return Signed{}, ErrInvalidSignature
+		}
+		return Signed{ID: id}, nil
+	}
+}
+
+// GitHub returns a middleware verifying the GitHub webhooks, see SchemeGitHub.
+func GitHub(secret string) gin.HandlerFunc {
+	return Verify(Config{Scheme: SchemeGitHub, Secrets: []string{secret}})
+}
+
+// SchemeGitHub is the Scheme of GitHub: the X-Hub-Signature-256 header is the
+// HMAC-SHA256 of the body. GitHub signs neither a timestamp nor the
+// X-GitHub-Delivery header, so the signature is the ID of the delivery: a
+// redelivery of the same payload is rejected as a replay.
+func SchemeGitHub(h http.Header, body []byte, secrets [][]byte) (Signed, error) {
+	return SchemeHMAC("X-Hub-Signature-256")(h, body, secrets)
+}
+
+// Stripe returns a middleware verifying the Stripe webhooks, see SchemeStripe.
+func Stripe(secret string) gin.HandlerFunc {
+	return Verify(Config{Scheme: SchemeStripe, Secrets: []string{secret}})
+}
+
+// SchemeStripe is the Scheme of Stripe: the Stripe-Signature header holds the
+// timestamp t and one or more v1 signatures of "t.body".
+func SchemeStripe(h http.Header, body []byte, secrets [][]byte) (Signed, error) {
+	header := h.Get("Stripe-Signature")
+	if header == "" {
+		return Signed{}, ErrMissingSignature
+	}
+	var ts string
+	var sigs []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			sigs = append(sigs, value)
+		}
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(sigs) == 0 {
+		return Signed{}, ErrMissingSignature
+	}
+	payload := append([]byte(ts+"."), body...)
+	for _, sig := range sigs {
+		if id, ok := checkHex(sig, payload, secrets); ok {
+			return Signed{Timestamp: time.Unix(sec, 0), ID: id}, nil
+		}
+	}
+	return Signed{}, ErrInvalidSignature
+}
+
+// Slack returns a middleware verifying the Slack requests, see SchemeSlack.
+func Slack(signingSecret string) gin.HandlerFunc {
+	return Verify(Config{Scheme: SchemeSlack, Secrets: []string{signingSecret}})
+}
+
+// SchemeSlack is the Scheme of Slack: the X-Slack-Signature header is "v0="
+// followed by the signature of "v0:timestamp:body", the timestamp being sent in
+// X-Slack-Request-Timestamp.
+func SchemeSlack(h http.Header, body []byte, secrets [][]byte) (Signed, error) {
+	sig, ok := strings.CutPrefix(h.Get("X-Slack-Signature"), "v0=")
+	ts := h.Get("X-Slack-Request-Timestamp")
+	if !ok || ts == "" {
+		return Signed{}, ErrMissingSignature
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return Signed{}, ErrMissingSignature
+	}
+	id, ok := checkHex(sig, append([]byte("v0:"+ts+":"), body...), secrets)
+	if !ok {
+		return Signed{}, ErrInvalidSignature
+	}
+	return Signed{Timestamp: time.Unix(sec, 0), ID: id}, nil
+}
+
+// Gin returns a middleware verifying the webhooks sent by gin, see SchemeGin.
+func Gin(secret string) gin.HandlerFunc {
+	return Verify(Config{Scheme: SchemeGin, Secrets: []string{secret}})
+}
+
+// SchemeGin is the Scheme of the webhooks sent by gin.Engine.EnableWebhooks:
+// the X-Webhook-Signature header is "sha256=" followed by the signature of
+// "timestamp.body", the timestamp being sent in X-Webhook-Timestamp. The
+// retries are signed again, so X-Webhook-ID identifies the deliveries which
+// are received more than once.
+func SchemeGin(h http.Header, body []byte, secrets [][]byte) (Signed, error) {
+	sig, ok := strings.CutPrefix(h.Get("X-Webhook-Signature"), "sha256=")
+	ts := h.Get("X-Webhook-Timestamp")
+	if !ok || ts == "" {
+		return Signed{}, ErrMissingSignature
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return Signed{}, ErrMissingSignature
+	}
+	id, ok := checkHex(sig, append([]byte(ts+"."), body...), secrets)
+	if !ok {
+		return Signed{}, ErrInvalidSignature
+	}
+	return Signed{Timestamp: time.Unix(sec, 0), ID: id}, nil
+}
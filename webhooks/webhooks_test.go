@@ -0,0 +1,208 @@
+// Copyright 2023 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package webhooks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+type event struct {
+	Action string `json:"action"`
+}
+
+func newRouter(verify gin.HandlerFunc) *gin.Engine {
+	router := gin.New()
+	router.POST("/hook", verify, func(c *gin.Context) {
+		var e event
+		if err := c.ShouldBindJSON(&e); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		raw, _ := c.BodyBytes()
+		c.String(http.StatusOK, "%s %d", e.Action, len(raw))
+	})
+	return router
+}
+
+func post(router http.Handler, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+const body = `{"action":"opened"}`
+
+func TestGitHub(t *testing.T) {
+	router := newRouter(GitHub("s3cret"))
+
+	w := post(router, body, map[string]string{"X-Hub-Signature-256": "sha256=" + Sign([]byte("s3cret"), []byte(body))})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "opened 19", w.Body.String())
+
+	w = post(router, body, map[string]string{"X-Hub-Signature-256": "sha256=" + Sign([]byte("other"), []byte(body))})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = post(router, body, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestHMACSecretRotation(t *testing.T) {
+	router := newRouter(Verify(Config{Scheme: SchemeHMAC("X-Signature"), Secrets: []string{"new", "old"}}))
+	for _, secret := range []string{"new", "old"} {
+		w := post(router, body, map[string]string{"X-Signature": Sign([]byte(secret), []byte(body))})
+		assert.Equal(t, http.StatusOK, w.Code, secret)
+	}
+	w := post(router, body, map[string]string{"X-Signature": "not hex"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func stripeHeader(secret string, ts time.Time, payload string) string {
+	t := strconv.FormatInt(ts.Unix(), 10)
+	return "t=" + t + ",v1=" + Sign([]byte("bogus"), []byte(payload)) + ",v1=" + Sign([]byte(secret), []byte(t+"."+payload))
+}
+
+func TestStripe(t *testing.T) {
+	router := newRouter(Stripe("whsec"))
+
+	w := post(router, body, map[string]string{"Stripe-Signature": stripeHeader("whsec", time.Now(), body)})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = post(router, body, map[string]string{"Stripe-Signature": stripeHeader("whsec", time.Now().Add(-time.Hour), body)})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = post(router, `{"action":"closed"}`, map[string]string{"Stripe-Signature": stripeHeader("whsec", time.Now(), body)})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = post(router, body, map[string]string{"Stripe-Signature": "v1=abc"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSlack(t *testing.T) {
+	router := newRouter(Slack("slack"))
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+
+	w := post(router, body, map[string]string{
+		"X-Slack-Request-Timestamp": ts,
+		"X-Slack-Signature":         "v0=" + Sign([]byte("slack"), []byte("v0:"+ts+":"+body)),
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = post(router, body, map[string]string{
+		"X-Slack-Request-Timestamp": ts,
+		"X-Slack-Signature":         Sign([]byte("slack"), []byte("v0:"+ts+":"+body)),
+	})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestGin(t *testing.T) {
+	received := make(chan string, 1)
+	receiver := gin.New()
+	receiver.POST("/hook", Gin("s3cret"), func(c *gin.Context) {
+		received <- c.GetHeader("X-Webhook-Event")
+	})
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	sender := gin.New()
+	hooks := sender.EnableWebhooks(gin.WebhookConfig{
+		Endpoints: []gin.WebhookEndpoint{{ID: "e", URL: srv.URL + "/hook", Secret: "s3cret"}},
+		Interval:  10 * time.Millisecond,
+	})
+	defer func() { assert.NoError(t, sender.Shutdown(context.Background())) }()
+	_, err := hooks.Publish(context.Background(), "invoice.paid", gin.H{"amount": 42})
+	assert.NoError(t, err)
+	select {
+	case event := <-received:
+		assert.Equal(t, "invoice.paid", event)
+	case <-time.After(time.Second):
+		t.Fatal("webhook not received")
+	}
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	router := newRouter(Gin("s3cret"))
+	w := post(router, body, map[string]string{
+		"X-Webhook-Timestamp": ts,
+		"X-Webhook-Signature": "sha256=" + Sign([]byte("s3cret"), []byte(ts+"."+body)),
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = post(router, body, map[string]string{
+		"X-Webhook-Timestamp": ts,
+		"X-Webhook-Signature": "sha256=" + Sign([]byte("s3cret"), []byte(body)),
+	})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestReplayProtection(t *testing.T) {
+	var errs []error
+	router := newRouter(Verify(Config{
+		Scheme:  SchemeGitHub,
+		Secrets: []string{"s3cret"},
+		Nonces:  NewMemoryNonceStore(),
+		ErrorHandler: func(c *gin.Context, err error) {
+			errs = append(errs, err)
+			c.AbortWithStatus(http.StatusConflict)
+		},
+	}))
+	sig := Sign([]byte("s3cret"), []byte(body))
+	header := map[string]string{
+		"X-Hub-Signature-256": "sha256=" + sig,
+		"X-GitHub-Delivery":   "72d3162e",
+	}
+
+	assert.Equal(t, http.StatusOK, post(router, body, header).Code)
+	assert.Equal(t, http.StatusConflict, post(router, body, header).Code)
+
+	// the unsigned delivery ID and the case of the signature do not matter.
+	header["X-GitHub-Delivery"] = "72d3162f"
+	assert.Equal(t, http.StatusConflict, post(router, body, header).Code)
+	header["X-Hub-Signature-256"] = "sha256=" + strings.ToUpper(sig)
+	assert.Equal(t, http.StatusConflict, post(router, body, header).Code)
+	assert.Equal(t, []error{ErrReplayed, ErrReplayed, ErrReplayed}, errs)
+
+	other := `{"action":"closed"}`
+	header["X-Hub-Signature-256"] = "sha256=" + Sign([]byte("s3cret"), []byte(other))
+	assert.Equal(t, http.StatusOK, post(router, other, header).Code)
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	router := newRouter(GitHub("s3cret"))
+	router.MaxCachedBodySize = 4
+	w := post(router, body, map[string]string{"X-Hub-Signature-256": "sha256=" + Sign([]byte("s3cret"), []byte(body))})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestVerifyRequiresSecret(t *testing.T) {
+	assert.Panics(t, func() { Verify(Config{Scheme: SchemeGitHub}) })
+}
+
+func TestMemoryNonceStore(t *testing.T) {
+	s := NewMemoryNonceStore()
+	seen, err := s.Seen("a", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.False(t, seen)
+	seen, _ = s.Seen("a", time.Now().Add(time.Hour))
+	assert.True(t, seen)
+
+	_, _ = s.Seen("b", time.Now().Add(-time.Second))
+	seen, _ = s.Seen("b", time.Now().Add(time.Hour))
+	assert.False(t, seen)
+}